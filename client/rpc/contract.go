@@ -12,6 +12,7 @@ import (
 type ContractApi interface {
 	CallOffChainMethod(param api.CallOffChainMethodParam) ([]byte, error)  // Deprecated: Use Query() instead
 	Query(param api.QueryParam) ([]byte, error)  // Executes a synchronous call immediately without sending a transaction to the blockchain
+	CallOffChainBatch(param api.CallOffChainBatchParam) (*api.CallOffChainBatchRes, error)
 	GetCreateContractData(param api.CreateContractDataParam) ([]byte, error)
 	GetContractStorage(addr types.Address, prefix string) (map[string]string, error)
 	GetContractInfo(addr types.Address) (*api.ContractInfo, error)
@@ -40,6 +41,12 @@ func (ci contractApi) Query(param api.QueryParam) (result []byte, err error) {
 	return
 }
 
+func (ci contractApi) CallOffChainBatch(param api.CallOffChainBatchParam) (result *api.CallOffChainBatchRes, err error) {
+	result = &api.CallOffChainBatchRes{}
+	err = ci.cc.Call(&result, "contract_callOffChainBatch", param)
+	return
+}
+
 func (ci contractApi) GetContractStorage(addr types.Address, prefix string) (result map[string]string, err error) {
 	result = make(map[string]string)
 	err = ci.cc.Call(&result, "contract_getContractStorage", addr, prefix)
@@ -13,12 +13,21 @@ import (
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_backup"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_bench"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_db"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_devnet"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_export"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_ledger"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_loadgen"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_loadledger"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_plugin_data"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_probe"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_prune"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_recover"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_replay"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_rpc"
+	"github.com/vitelabs/go-vite/v2/cmd/subcmd_signer"
 	"github.com/vitelabs/go-vite/v2/cmd/subcmd_virtualnode"
 	"github.com/vitelabs/go-vite/v2/cmd/utils"
 	"github.com/vitelabs/go-vite/v2/log15"
@@ -60,6 +69,15 @@ func init() {
 		subcmd_loadledger.LoadLedgerCommand,
 		subcmd_ledger.QueryLedgerCommand,
 		subcmd_virtualnode.VirtualNodeCommand,
+		subcmd_probe.ProbeCommand,
+		subcmd_signer.SignerCommand,
+		subcmd_db.DbCommand,
+		subcmd_bench.BenchCommand,
+		subcmd_prune.PruneCommand,
+		subcmd_backup.BackupCommand,
+		subcmd_devnet.DevnetCommand,
+		subcmd_loadgen.LoadgenCommand,
+		subcmd_replay.ReplayCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -32,9 +32,28 @@ func (maker FullNodeMaker) MakeNode(ctx *cli.Context) (*node.Node, error) {
 		log.Error("Failed to create the node: %v", err)
 		return nil, err
 	}
+	node.SetConfigLoader(func() (*nodeconfig.Config, error) {
+		return maker.reloadNodeConfig(ctx)
+	})
 	return node, nil
 }
 
+// reloadNodeConfig reads the config file again and applies the flags, it is used on SIGHUP
+func (maker FullNodeMaker) reloadNodeConfig(ctx *cli.Context) (*nodeconfig.Config, error) {
+	cfg := nodeconfig.DefaultNodeConfig
+
+	configFile := ctx.GlobalString(utils.ConfigFileFlag.Name)
+	if configFile == "" {
+		configFile = defaultNodeConfigFileName
+	}
+	if err := cfg.ParseFromFile(configFile); err != nil {
+		return nil, err
+	}
+	mappingNodeConfig(ctx, &cfg)
+	overrideNodeConfigs(ctx, &cfg)
+	return &cfg, nil
+}
+
 func (maker FullNodeMaker) MakeNodeConfig(ctx *cli.Context) (*nodeconfig.Config, error) {
 	cfg := nodeconfig.DefaultNodeConfig
 	log.Info(fmt.Sprintf("DefaultNodeconfig: %v", cfg))
@@ -140,6 +159,9 @@ func mappingNodeConfig(ctx *cli.Context, cfg *nodeconfig.Config) {
 	if logLevel := ctx.GlobalString(utils.LogLvlFlag.Name); len(logLevel) > 0 {
 		cfg.LogLevel = logLevel
 	}
+	if logFormat := ctx.GlobalString(utils.LogFormatFlag.Name); len(logFormat) > 0 {
+		cfg.LogFormat = logFormat
+	}
 
 	//VM
 	if ctx.GlobalIsSet(utils.VMTestFlag.Name) {
@@ -238,8 +260,12 @@ func IsExist(f string) bool {
 }
 
 func makeRunLogFile(cfg *nodeconfig.Config) {
-	defaultHandler := common.LogHandler(cfg.RunLogDir(), "", "vite.log", cfg.LogLevel)
-	errorHandler := common.LogHandler(cfg.RunLogDir(), "error", "vite.error.log", log15.LvlError.String())
+	if err := common.SetLogFormat(cfg.LogFormat); err != nil {
+		log.Warn("invalid log format, use logfmt", "err", err)
+		cfg.LogFormat = common.LogFormatLogfmt
+	}
+	defaultHandler := common.ReloadableLogHandler(cfg.RunLogDir(), "", "vite.log", cfg.LogLevel)
+	errorHandler := common.ErrorLogHandler(cfg.RunLogDir(), "error", "vite.error.log")
 
 	log15.Root().SetHandler(log15.MultiHandler(defaultHandler, errorHandler))
 }
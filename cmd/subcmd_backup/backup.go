@@ -0,0 +1,107 @@
+package subcmd_backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/client"
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	chain_backup "github.com/vitelabs/go-vite/v2/ledger/chain/backup"
+)
+
+var (
+	endpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "rpc endpoint of the running node, the admin api should be enabled",
+		Value: "http://127.0.0.1:48132",
+	}
+	dirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "backup directory on the host of the node",
+	}
+	fromFlag = cli.StringFlag{
+		Name:  "from",
+		Usage: "backup directory to restore from",
+	}
+
+	BackupCommand = cli.Command{
+		Name:     "backup",
+		Usage:    "backup and restore the ledger",
+		Category: "LOCAL COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:  "create",
+				Usage: "back up the ledger of the running node, eg: backup create --endpoint http://127.0.0.1:48132 --dir /data/backup",
+				Description: `Take a consistent backup of the block files and the leveldb checkpoints at a flush boundary while the node is running.
+The block files unchanged since the previous backup in the directory are not rewritten.`,
+				Flags:  []cli.Flag{endpointFlag, dirFlag},
+				Action: utils.MigrateFlags(createAction),
+			},
+			{
+				Name:  "restore",
+				Usage: "restore the ledger from a backup, eg: backup restore --from /data/backup",
+				Description: `Verify the integrity of the backup and copy it into the ledger directory of the data dir.
+The node should be stopped and the ledger directory should not exist.`,
+				Flags:  append(utils.ConfigFlags, utils.DataDirFlag, fromFlag),
+				Action: utils.MigrateFlags(restoreAction),
+			},
+		},
+	}
+)
+
+func createAction(ctx *cli.Context) error {
+	dir := ctx.String(dirFlag.Name)
+	if dir == "" {
+		return errors.New("dir not set")
+	}
+
+	conn, err := client.NewRpcClient(ctx.String(endpointFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	result, err := conn.RawCall("admin_backup", dir)
+	if err != nil {
+		return err
+	}
+
+	// the manifest is decoded as a map by the rpc client
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	manifest := &chain_backup.Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return err
+	}
+
+	fmt.Printf("backup at snapshot block %d %s, %d block files, %d dbs\n",
+		manifest.SnapshotHeight, manifest.SnapshotHash, len(manifest.Files), len(manifest.Dbs))
+	return nil
+}
+
+func restoreAction(ctx *cli.Context) error {
+	from := ctx.String(fromFlag.Name)
+	if from == "" {
+		return errors.New("from not set")
+	}
+
+	cfg, err := nodemanager.LocalNodeMaker{}.MakeNodeConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ledgerDir := filepath.Join(cfg.DataDir, "ledger")
+
+	manifest, err := chain_backup.Restore(from, ledgerDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("restored snapshot block %d %s into %s\n", manifest.SnapshotHeight, manifest.SnapshotHash, ledgerDir)
+	return nil
+}
@@ -0,0 +1,85 @@
+package subcmd_bench
+
+import (
+	"fmt"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	chain_bench "github.com/vitelabs/go-vite/v2/ledger/chain/bench"
+)
+
+var (
+	dirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "the directory to run the benchmark in, default is the data directory of the node",
+	}
+	blocksFlag = cli.IntFlag{
+		Name:  "blocks",
+		Usage: "account blocks written to the block db",
+		Value: chain_bench.DefaultConfig("").Blocks,
+	}
+	keysFlag = cli.IntFlag{
+		Name:  "keys",
+		Usage: "keys written to the store and the state db",
+		Value: chain_bench.DefaultConfig("").Keys,
+	}
+	readsFlag = cli.IntFlag{
+		Name:  "reads",
+		Usage: "random reads of every read workload",
+		Value: chain_bench.DefaultConfig("").Reads,
+	}
+	valueSizeFlag = cli.IntFlag{
+		Name:  "valuesize",
+		Usage: "bytes of every value and block data",
+		Value: chain_bench.DefaultConfig("").ValueSize,
+	}
+
+	BenchCommand = cli.Command{
+		Name:     "bench",
+		Usage:    "benchmark the local hardware",
+		Category: "LOCAL COMMANDS",
+		Description: `Benchmark the local hardware before deploying a node, the benchmark files are removed after running.
+The node should be stopped, or the result is disturbed.`,
+		Subcommands: []cli.Command{
+			{
+				Name:  "storage",
+				Usage: "drive synthetic workloads against the block db, the store and the state db, eg: bench storage --dir /data/bench",
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					utils.DataDirFlag,
+					dirFlag,
+					blocksFlag,
+					keysFlag,
+					readsFlag,
+					valueSizeFlag,
+				}...),
+				Action: utils.MigrateFlags(storageAction),
+			},
+		},
+	}
+)
+
+func storageAction(ctx *cli.Context) error {
+	dir := ctx.String(dirFlag.Name)
+	if dir == "" {
+		cfg, err := nodemanager.LocalNodeMaker{}.MakeNodeConfig(ctx)
+		if err != nil {
+			return err
+		}
+		dir = cfg.DataDir
+	}
+
+	cfg := chain_bench.DefaultConfig(dir)
+	cfg.Blocks = ctx.Int(blocksFlag.Name)
+	cfg.Keys = ctx.Int(keysFlag.Name)
+	cfg.Reads = ctx.Int(readsFlag.Name)
+	cfg.ValueSize = ctx.Int(valueSizeFlag.Name)
+
+	fmt.Printf("benchmark storage in %s, %d blocks, %d keys, %d reads, value size %d\n",
+		dir, cfg.Blocks, cfg.Keys, cfg.Reads, cfg.ValueSize)
+
+	return chain_bench.Run(cfg, func(result *chain_bench.Result) {
+		fmt.Println(result)
+	})
+}
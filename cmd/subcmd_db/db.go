@@ -0,0 +1,181 @@
+package subcmd_db
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/opt"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+var (
+	dbNameFlag = cli.StringFlag{
+		Name:  "db",
+		Usage: "the database to inspect: index, state or redo",
+		Value: "index",
+	}
+	prefixFlag = cli.StringFlag{
+		Name:  "prefix",
+		Usage: "list the keys with the hex prefix, eg: 08 for snapshot block heights of the index db",
+	}
+	keyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "dump the value of the hex key",
+	}
+	limitFlag = cli.IntFlag{
+		Name:  "limit",
+		Usage: "max keys to list",
+		Value: 20,
+	}
+	valueFlag = cli.BoolFlag{
+		Name:  "value",
+		Usage: "print the values of the listed keys",
+	}
+	statsFlag = cli.BoolFlag{
+		Name:  "stats",
+		Usage: "show the table and compaction stats of the database",
+	}
+
+	DbCommand = cli.Command{
+		Name:        "db",
+		Usage:       "inspect the local databases",
+		Category:    "LOCAL COMMANDS",
+		Description: `Inspect the databases of the ledger, the node should be stopped.`,
+		Subcommands: []cli.Command{
+			{
+				Name:  "inspect",
+				Usage: "list keys by prefix, dump a key or show stats, eg: db inspect --db state --prefix 03 --limit 10",
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					utils.DataDirFlag,
+					dbNameFlag,
+					prefixFlag,
+					keyFlag,
+					limitFlag,
+					valueFlag,
+					statsFlag,
+				}...),
+				Action: utils.MigrateFlags(inspectAction),
+			},
+		},
+	}
+)
+
+type inspectedDb struct {
+	dir      string
+	describe func(key []byte) string
+}
+
+var inspectedDbs = map[string]inspectedDb{
+	"index": {"index", chain_utils.DescribeIndexKey},
+	"state": {"state", chain_utils.DescribeStateKey},
+	"redo":  {"state_redo", chain_utils.DescribeStateRedoKey},
+}
+
+func inspectAction(ctx *cli.Context) error {
+	target, ok := inspectedDbs[ctx.String(dbNameFlag.Name)]
+	if !ok {
+		return fmt.Errorf("unknown db %q, should be index, state or redo", ctx.String(dbNameFlag.Name))
+	}
+
+	cfg, err := nodemanager.LocalNodeMaker{}.MakeNodeConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(cfg.DataDir, "ledger", target.dir)
+	db, err := leveldb.OpenFile(dir, &opt.Options{
+		ReadOnly:       true,
+		ErrorIfMissing: true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", dir, err)
+	}
+	defer db.Close()
+
+	if ctx.Bool(statsFlag.Name) {
+		return printStats(db, dir)
+	}
+
+	if ctx.IsSet(keyFlag.Name) {
+		key, err := hex.DecodeString(ctx.String(keyFlag.Name))
+		if err != nil {
+			return fmt.Errorf("invalid key: %v", err)
+		}
+		return dumpKey(db, target, key)
+	}
+
+	prefix, err := hex.DecodeString(ctx.String(prefixFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid prefix: %v", err)
+	}
+	return listKeys(db, target, prefix, ctx.Int(limitFlag.Name), ctx.Bool(valueFlag.Name))
+}
+
+func printStats(db *leveldb.DB, dir string) error {
+	stats, err := db.GetProperty("leveldb.stats")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(dir)
+	fmt.Print(stats)
+
+	var st leveldb.DBStats
+	if err = db.Stats(&st); err != nil {
+		return err
+	}
+	var tables int
+	var size int64
+	for level, n := range st.LevelTablesCounts {
+		tables += n
+		size += st.LevelSizes[level]
+	}
+	fmt.Printf("Total tables: %d, size: %.5f MB\n", tables, float64(size)/1048576.0)
+
+	return nil
+}
+
+func dumpKey(db *leveldb.DB, target inspectedDb, key []byte) error {
+	value, err := db.Get(key, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("key:   %s\n", hex.EncodeToString(key))
+	fmt.Printf("       %s\n", target.describe(key))
+	fmt.Printf("value: %s (%d bytes)\n", hex.EncodeToString(value), len(value))
+	return nil
+}
+
+func listKeys(db *leveldb.DB, target inspectedDb, prefix []byte, limit int, withValue bool) error {
+	iter := db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	count := 0
+	for iter.Next() {
+		if limit > 0 && count >= limit {
+			fmt.Printf("... more keys, increase --limit to list them\n")
+			break
+		}
+		count++
+
+		key := iter.Key()
+		fmt.Printf("%s  %s\n", hex.EncodeToString(key), target.describe(key))
+		if withValue {
+			fmt.Printf("    value: %s\n", hex.EncodeToString(iter.Value()))
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%d keys listed\n", count)
+	return nil
+}
@@ -0,0 +1,222 @@
+package subcmd_devnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	chain_genesis "github.com/vitelabs/go-vite/v2/ledger/chain/genesis"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+const (
+	genesisFileName    = "genesis.json"
+	nodeConfigFileName = "node_config.json"
+)
+
+var (
+	dirFlag = cli.StringFlag{
+		Name:  "dir",
+		Usage: "directory of the devnet, the nodes are in dir/node0, dir/node1, ...",
+		Value: "devnet",
+	}
+	nodesFlag = cli.IntFlag{
+		Name:  "nodes",
+		Usage: "count of the nodes",
+		Value: 1,
+	}
+	specFlag = cli.StringFlag{
+		Name:  "spec",
+		Usage: "genesis spec in yaml or json, the nodes are the sbps if the spec has no sbps",
+	}
+	passwordFlag = cli.StringFlag{
+		Name:  "password",
+		Usage: "password of the entropy stores of the nodes",
+		Value: "123456",
+	}
+	netIdFlag = cli.IntFlag{
+		Name:  "netid",
+		Usage: "network id of the devnet",
+		Value: 5,
+	}
+	portFlag = cli.IntFlag{
+		Name:  "port",
+		Usage: "p2p port of node0, node i uses port+2i and port+2i+1 for files",
+		Value: config.DefaultPort,
+	}
+	rpcPortFlag = cli.IntFlag{
+		Name:  "rpcport",
+		Usage: "http rpc port of node0, node i uses rpcport+i",
+		Value: 48132,
+	}
+
+	DevnetCommand = cli.Command{
+		Name:     "devnet",
+		Usage:    "local network for development",
+		Category: "LOCAL COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:  "init",
+				Usage: "generate the genesis, node configs and data dirs of a local network, eg: devnet init --nodes 3 --spec genesis.yaml",
+				Description: `Generate an entropy store and a peer key for every node, build and validate the genesis from the spec,
+and write the node config of every node. Start node i by: gvite --config dir/node<i>/node_config.json`,
+				Flags:  []cli.Flag{dirFlag, nodesFlag, specFlag, passwordFlag, netIdFlag, portFlag, rpcPortFlag},
+				Action: utils.MigrateFlags(initAction),
+			},
+		},
+	}
+)
+
+type devnetNode struct {
+	dir          string
+	address      types.Address
+	entropyStore string
+	peerKey      ed25519.PrivateKey
+	nodeId       ed25519.PublicKey
+	port         int
+}
+
+func initAction(ctx *cli.Context) error {
+	nodeCount := ctx.Int(nodesFlag.Name)
+	if nodeCount <= 0 || nodeCount > 255 {
+		return errors.New("nodes should be in [1, 255]")
+	}
+	dir, err := filepath.Abs(ctx.String(dirFlag.Name))
+	if err != nil {
+		return err
+	}
+	if infos, err := ioutil.ReadDir(dir); err == nil && len(infos) > 0 {
+		return fmt.Errorf("%s is not empty", dir)
+	}
+	password := ctx.String(passwordFlag.Name)
+
+	spec := &chain_genesis.Spec{}
+	if filename := ctx.String(specFlag.Name); filename != "" {
+		if spec, err = chain_genesis.LoadSpec(filename); err != nil {
+			return err
+		}
+	}
+
+	nodes := make([]*devnetNode, 0, nodeCount)
+	for i := 0; i < nodeCount; i++ {
+		node, err := newDevnetNode(filepath.Join(dir, fmt.Sprintf("node%d", i)), password, ctx.Int(portFlag.Name)+2*i)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(spec.Sbps) == 0 {
+		for i, node := range nodes {
+			spec.Sbps = append(spec.Sbps, &chain_genesis.SbpSpec{
+				Name:                  fmt.Sprintf("s%d", i),
+				BlockProducingAddress: node.address.String(),
+			})
+		}
+	}
+	if spec.GenesisAccount == "" {
+		spec.GenesisAccount = nodes[0].address.String()
+	}
+
+	genesis, err := spec.Build()
+	if err != nil {
+		return err
+	}
+	genesisFile := filepath.Join(dir, genesisFileName)
+	if err := writeJson(genesisFile, genesis); err != nil {
+		return err
+	}
+
+	for i, node := range nodes {
+		var staticNodes []string
+		for j, other := range nodes {
+			if j != i {
+				staticNodes = append(staticNodes, fmt.Sprintf("%s@127.0.0.1:%d", other.nodeId.Hex(), other.port))
+			}
+		}
+
+		cfg := map[string]interface{}{
+			"Identity":             fmt.Sprintf("node%d", i),
+			"NetID":                ctx.Int(netIdFlag.Name),
+			"DataDir":              filepath.Join(node.dir, "data"),
+			"KeyStoreDir":          filepath.Join(node.dir, "wallet"),
+			"GenesisFile":          genesisFile,
+			"Single":               nodeCount == 1,
+			"ListenInterface":      "127.0.0.1",
+			"Port":                 node.port,
+			"FilePort":             node.port + 1,
+			"NAT":                  "none",
+			"PrivateKey":           node.peerKey.Hex(),
+			"Discover":             false,
+			"StaticNodes":          staticNodes,
+			"RPCEnabled":           true,
+			"IPCEnabled":           true,
+			"HttpHost":             "127.0.0.1",
+			"HttpPort":             ctx.Int(rpcPortFlag.Name) + i,
+			"PublicModules":        []string{"ledger", "net", "contract", "util", "debug", "sbpstats", "wallet", "tx", "admin"},
+			"Miner":                true,
+			"CoinBase":             "0:" + node.address.String(),
+			"EntropyStorePath":     node.entropyStore,
+			"EntropyStorePassword": password,
+			"LogLevel":             "info",
+			"OpenPlugins":          true,
+			"VmLogAll":             true,
+		}
+		if err := writeJson(filepath.Join(node.dir, nodeConfigFileName), cfg); err != nil {
+			return err
+		}
+		fmt.Printf("node%d: %s, p2p port %d, rpc port %d\n", i, node.address, node.port, ctx.Int(rpcPortFlag.Name)+i)
+	}
+
+	fmt.Printf("devnet of %d nodes is generated in %s\n", nodeCount, dir)
+	return nil
+}
+
+func newDevnetNode(dir string, password string, port int) (*devnetNode, error) {
+	walletDir := filepath.Join(dir, "wallet")
+	for _, d := range []string{walletDir, filepath.Join(dir, "data")} {
+		if err := os.MkdirAll(d, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	manager := wallet.New(&config.Wallet{DataDir: walletDir})
+	_, em, err := manager.NewMnemonicAndEntropyStore(password)
+	if err != nil {
+		return nil, err
+	}
+	if em == nil {
+		return nil, errors.New("generate entropy store failed")
+	}
+
+	nodeId, peerKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &devnetNode{
+		dir:          dir,
+		address:      em.GetPrimaryAddr(),
+		entropyStore: em.GetEntropyStoreFile(),
+		peerKey:      peerKey,
+		nodeId:       nodeId,
+		port:         port,
+	}, nil
+}
+
+func writeJson(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
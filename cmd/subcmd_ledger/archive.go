@@ -0,0 +1,215 @@
+package subcmd_ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_archive "github.com/vitelabs/go-vite/v2/ledger/chain/archive"
+	"github.com/vitelabs/go-vite/v2/ledger/pipeline"
+	"github.com/vitelabs/go-vite/v2/node"
+)
+
+const exportBatchSize = 1000
+
+const (
+	importCheckInterval = time.Second
+	importStallTimeout  = 5 * time.Minute
+)
+
+func exportAction(ctx *cli.Context) error {
+	from, to, file := ctx.Uint64("from"), ctx.Uint64("to"), ctx.String("file")
+	if file == "" {
+		return errors.New("file not set")
+	}
+
+	vite, err := localVite(ctx)
+	if err != nil {
+		return err
+	}
+
+	c := vite.Chain()
+	latest := c.GetLatestSnapshotBlock().Height
+	if from == 0 {
+		from = 1
+	}
+	if to == 0 || to > latest {
+		to = latest
+	}
+	if from > to {
+		return fmt.Errorf("invalid range [%d, %d], latest height is %d", from, to, latest)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = exportChunks(c, f, from, to); err != nil {
+		os.Remove(file)
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+
+	log.Info(fmt.Sprintf("export snapshot chunks [%d, %d] to %s", from, to, file))
+	return nil
+}
+
+// exportChunks streams the chunks [from, to] from the block db to the archive
+func exportChunks(c chain.Chain, w io.Writer, from, to uint64) error {
+	aw, err := chain_archive.NewArchiveWriter(w, from, to)
+	if err != nil {
+		return err
+	}
+
+	// [from-1, to], the first chunk is the snapshot block at from-1
+	next := from
+	if err = c.StreamSubLedger(context.Background(), from-1, to, func(chunk *core.SnapshotChunk) error {
+		if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < from {
+			return nil
+		}
+		if chunk.SnapshotBlock.Height != next {
+			return fmt.Errorf("snapshot block %d is missing in the block db", next)
+		}
+		next++
+		if err := aw.WriteChunk(chunk); err != nil {
+			return err
+		}
+		if next%exportBatchSize == 0 {
+			log.Info(fmt.Sprintf("exported %d - %d", from, next-1))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	if next != to+1 {
+		return fmt.Errorf("snapshot block %d is missing in the block db", next)
+	}
+
+	return aw.Close()
+}
+
+func importAction(ctx *cli.Context) error {
+	file := ctx.String("file")
+	if file == "" {
+		return errors.New("file not set")
+	}
+
+	// verify the whole archive before inserting any block
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	header, err := chain_archive.VerifyArchive(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	node, err := nodemanager.LocalNodeMaker{}.MakeNode(ctx)
+	if err != nil {
+		return err
+	}
+	if err = node.Prepare(); err != nil {
+		return err
+	}
+	return importArchive(node, file, header)
+}
+
+// importArchive inserts the chunks of the archive after the latest snapshot block of the prepared node,
+// the node is started and stopped once the last chunk is inserted
+func importArchive(node *node.Node, file string, header *chain_archive.ArchiveHeader) error {
+	c := node.Vite().Chain()
+	latest := c.GetLatestSnapshotBlock()
+	if header.From > latest.Height+1 {
+		return fmt.Errorf("archive starts at %d, but the latest snapshot block is %d", header.From, latest.Height)
+	}
+	if header.To <= latest.Height {
+		log.Info(fmt.Sprintf("archive [%d, %d] is already in the ledger", header.From, header.To))
+		return nil
+	}
+
+	if err := node.Start(); err != nil {
+		return err
+	}
+	defer node.Stop()
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	ar, err := chain_archive.NewArchiveReader(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	chunkCh := make(chan *core.SnapshotChunk, 1000)
+	readErr := make(chan error, 1)
+	go func() {
+		defer f.Close()
+		defer close(chunkCh)
+		for {
+			chunk, err := ar.ReadChunk()
+			if err == io.EOF {
+				log.Info(fmt.Sprintf("all chunks of %s are read", file))
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- fmt.Errorf("read chunk archive fail: %v", err)
+				return
+			}
+			if chunk.SnapshotBlock.Height <= latest.Height {
+				continue
+			}
+			chunkCh <- chunk
+		}
+	}()
+
+	node.Vite().Pool().AddPipeline(pipeline.NewChunksPipeline(chunkCh))
+	log.Info(fmt.Sprintf("import snapshot chunks [%d, %d] from %s", latest.Height+1, header.To, file))
+	return waitImported(c, header.To, readErr)
+}
+
+// waitImported waits until the snapshot block at height is inserted, it fails if the archive can't be read,
+// or the ledger stops growing after all chunks are read, e.g. a chunk is rejected by the pool
+func waitImported(c chain.Chain, height uint64, readErr <-chan error) error {
+	ticker := time.NewTicker(importCheckInterval)
+	defer ticker.Stop()
+
+	read := false
+	current, lastGrowth := c.GetLatestSnapshotBlock().Height, time.Now()
+	for {
+		select {
+		case err := <-readErr:
+			if err != nil {
+				return err
+			}
+			read = true
+		case <-ticker.C:
+		}
+
+		h := c.GetLatestSnapshotBlock().Height
+		if h >= height {
+			log.Info(fmt.Sprintf("imported snapshot chunks to %d", h))
+			return nil
+		}
+		if h != current {
+			current, lastGrowth = h, time.Now()
+		} else if read && time.Since(lastGrowth) > importStallTimeout {
+			return fmt.Errorf("import stopped at snapshot block %d, the archive ends at %d", h, height)
+		}
+	}
+}
@@ -40,6 +40,69 @@ var (
 				Flags:  utils.ConfigFlags,
 				Action: utils.MigrateFlags(latestSnapshotBlockAction),
 			},
+			{
+				Name:  "export",
+				Usage: "export snapshot chunks to a checksummed archive, eg: export --from 1 --to 1000 --file chunks.bin",
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					cli.Uint64Flag{
+						Name:  "from",
+						Usage: "start snapshot height, default is 1",
+					},
+					cli.Uint64Flag{
+						Name:  "to",
+						Usage: "end snapshot height, default is the latest height",
+					},
+					cli.StringFlag{
+						Name:  "file",
+						Usage: "archive file",
+					}}...),
+				Action: utils.MigrateFlags(exportAction),
+			},
+			{
+				Name:  "import",
+				Usage: "import snapshot chunks from an archive, eg: import --file chunks.bin",
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					cli.StringFlag{
+						Name:  "file",
+						Usage: "archive file",
+					}}...),
+				Action: utils.MigrateFlags(importAction),
+			},
+			{
+				Name:  "verify",
+				Usage: "verify the integrity of the ledger, eg: verify --full",
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					cli.BoolFlag{
+						Name:  "quick",
+						Usage: "check the block hashes, the prev-hash linkage and the index, it is the default mode",
+					},
+					cli.BoolFlag{
+						Name:  "full",
+						Usage: "also read back the blocks by the index and re-derive the storage of sampled contracts",
+					}}...),
+				Action: utils.MigrateFlags(verifyAction),
+			},
+			{
+				Name:  "rebuild-index",
+				Usage: "drop the index db and rebuild it from the block files, an interrupted rebuilding is resumed",
+				Description: `Re-derive the hash -> location, height -> hash and confirmation indexes by reading the block files
+from the genesis. The unconfirmed account blocks are dropped. The node should be stopped.`,
+				Flags:  utils.ConfigFlags,
+				Action: utils.MigrateFlags(rebuildIndexAction),
+			},
+			{
+				Name:  "restore-rollback",
+				Usage: "insert the snapshot blocks deleted by a deep rollback again, eg: restore-rollback --file <recovery file>",
+				Description: `The snapshot blocks deleted by a deep rollback are saved to a recovery file under the rollback_recovery dir
+of the data dir. The latest recovery file is restored if the file is not set, the snapshot blocks replacing
+the recovered ones are rolled back first. The node should be stopped.`,
+				Flags: append(utils.ConfigFlags, []cli.Flag{
+					cli.StringFlag{
+						Name:  "file",
+						Usage: "recovery file, default is the latest",
+					}}...),
+				Action: utils.MigrateFlags(restoreRollbackAction),
+			},
 		},
 	}
 	log = log15.New("module", "ledger")
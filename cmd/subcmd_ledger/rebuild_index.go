@@ -0,0 +1,38 @@
+package subcmd_ledger
+
+import (
+	"fmt"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+// rebuildIndexAction rebuilds the index db from the block db, the chain is not initialized
+// because a corrupted index db may fail the initialization
+func rebuildIndexAction(ctx *cli.Context) error {
+	node, err := nodemanager.LocalNodeMaker{}.MakeNode(ctx)
+	if err != nil {
+		return err
+	}
+	cfg := node.ViteConfig()
+	upgrade.InitUpgradeBox(cfg.Genesis.UpgradeCfg.MakeUpgradeBox())
+
+	c := chain.NewChain(cfg.DataDir, cfg.Chain, cfg.Genesis)
+	err = c.RebuildIndexes(func(p *chain.RebuildIndexProgress) {
+		switch {
+		case p.Finished:
+			fmt.Printf("rebuilt indexes of %d snapshot blocks and %d account blocks\n", p.SnapshotHeight, p.AccountBlocks)
+		case p.Resumed && p.SnapshotHeight > 0:
+			fmt.Printf("resume from snapshot height %d\n", p.SnapshotHeight)
+		case p.SnapshotHeight > 0:
+			fmt.Printf("snapshot height %d, %d account blocks\n", p.SnapshotHeight, p.AccountBlocks)
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("rebuilding indexes stopped, run the command again to resume. Error: %s", err)
+	}
+	return c.Destroy()
+}
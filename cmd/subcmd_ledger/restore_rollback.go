@@ -0,0 +1,99 @@
+package subcmd_ledger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_archive "github.com/vitelabs/go-vite/v2/ledger/chain/archive"
+)
+
+// restoreRollbackAction inserts the snapshot blocks saved before a deep rollback again, the latest recovery file
+// is used if the file isn't set. The snapshot blocks replacing them are rolled back, and saved too.
+func restoreRollbackAction(ctx *cli.Context) error {
+	node, err := nodemanager.LocalNodeMaker{}.MakeNode(ctx)
+	if err != nil {
+		return err
+	}
+
+	file := ctx.String("file")
+	if file == "" {
+		files, err := chain.RollbackRecoveries(node.ViteConfig().DataDir)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return errors.New("no rollback recovery file")
+		}
+		file = files[len(files)-1]
+	}
+
+	header, first, err := readRecovery(file)
+	if err != nil {
+		return err
+	}
+
+	if err = node.Prepare(); err != nil {
+		return err
+	}
+	c := node.Vite().Chain()
+
+	prev, err := c.GetSnapshotHeaderByHeight(header.From - 1)
+	if err != nil {
+		return err
+	}
+	if prev == nil || prev.Hash != first.SnapshotBlock.PrevHash {
+		return fmt.Errorf("the snapshot block %d before %s is not in the ledger", header.From-1, file)
+	}
+
+	latest := c.GetLatestSnapshotBlock()
+	if latest.Height >= header.From {
+		current, err := c.GetSnapshotHeaderByHeight(header.From)
+		if err != nil {
+			return err
+		}
+		if current != nil && current.Hash == first.SnapshotBlock.Hash {
+			return fmt.Errorf("the snapshot block %d of %s is in the ledger, nothing to restore", header.From, file)
+		}
+		if _, err = c.DeleteSnapshotBlocksToHeight(header.From); err != nil {
+			return err
+		}
+		log.Info(fmt.Sprintf("rolled back the snapshot blocks [%d, %d] replacing the recovery", header.From, latest.Height))
+	}
+
+	log.Info(fmt.Sprintf("restore snapshot blocks [%d, %d] from %s", header.From, header.To, file))
+	return importArchive(node, file, header)
+}
+
+// readRecovery verifies the recovery file and returns its first chunk
+func readRecovery(file string) (*chain_archive.ArchiveHeader, *core.SnapshotChunk, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	header, err := chain_archive.VerifyArchive(f)
+	f.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	f, err = os.Open(file)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	ar, err := chain_archive.NewArchiveReader(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	first, err := ar.ReadChunk()
+	if err != nil {
+		return nil, nil, err
+	}
+	return header, first, nil
+}
@@ -0,0 +1,49 @@
+package subcmd_ledger
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+func verifyAction(ctx *cli.Context) error {
+	full := ctx.Bool("full")
+	if full && ctx.Bool("quick") {
+		return errors.New("--quick and --full can't be set at the same time")
+	}
+
+	vite, err := localVite(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := vite.Chain().VerifyLedger(full)
+	if err != nil {
+		return err
+	}
+
+	mode := "quick"
+	if report.Full {
+		mode = "full"
+	}
+	fmt.Printf("verify mode: %s\n", mode)
+	fmt.Printf("snapshot blocks: %d\n", report.SnapshotBlocks)
+	fmt.Printf("account blocks: %d, accounts: %d\n", report.AccountBlocks, report.Accounts)
+	if report.Full {
+		fmt.Printf("sampled contracts: %d\n", report.SampledContracts)
+	}
+
+	if report.IssueCount == 0 {
+		fmt.Println("no inconsistency found")
+		return nil
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Println(issue)
+	}
+	if omitted := report.IssueCount - uint64(len(report.Issues)); omitted > 0 {
+		fmt.Printf("... %d more inconsistencies are omitted\n", omitted)
+	}
+	return fmt.Errorf("%d inconsistencies found", report.IssueCount)
+}
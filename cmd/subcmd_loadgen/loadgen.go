@@ -0,0 +1,420 @@
+package subcmd_loadgen
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/client"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain/test_tools"
+	"github.com/vitelabs/go-vite/v2/pow"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+// counterContractCode deploys a contract increasing the counter in slot 0 on every call
+const counterContractCode = "600a600c600039600a6000f360005460010160005500"
+
+var (
+	endpointFlag = cli.StringFlag{
+		Name:  "endpoint",
+		Usage: "rpc endpoint of the node, the ledger, contract and tx apis should be enabled",
+		Value: "http://127.0.0.1:48132",
+	}
+	entropyStoreFlag = cli.StringFlag{
+		Name:  "entropystore",
+		Usage: "entropy store of the account funding the generated accounts, eg: the one of node0 of a devnet",
+	}
+	passwordFlag = cli.StringFlag{
+		Name:  "password",
+		Usage: "password of the entropy store",
+		Value: "123456",
+	}
+	fundFlag = cli.Int64Flag{
+		Name:  "fund",
+		Usage: "VITE sent to every generated account before the workload",
+		Value: 1000,
+	}
+	stakeFlag = cli.Int64Flag{
+		Name:  "stake",
+		Usage: "VITE staked for the quota of every generated account, the blocks are sent with PoW if it is 0",
+	}
+	seedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "seed of the workload, the same seed generates the same accounts and blocks",
+		Value: 1,
+	}
+	accountsFlag = cli.IntFlag{
+		Name:  "accounts",
+		Usage: "count of the generated accounts",
+		Value: 10,
+	}
+	stepsFlag = cli.IntFlag{
+		Name:  "steps",
+		Usage: "count of the generated account blocks",
+		Value: 1000,
+	}
+	contractFlag = cli.IntFlag{
+		Name:  "contract",
+		Usage: "percent of the sends to the contracts",
+		Value: 20,
+	}
+	deployFlag = cli.IntFlag{
+		Name:  "deploy",
+		Usage: "percent of the contract sends deploying a new contract",
+		Value: 10,
+	}
+	receiveFlag = cli.IntFlag{
+		Name:  "receive",
+		Usage: "percent of the steps receiving an onroad transfer",
+		Value: 50,
+	}
+
+	LoadgenCommand = cli.Command{
+		Name:     "loadgen",
+		Usage:    "send a deterministic workload to a node, eg: loadgen --entropystore devnet/node0/wallet/vite_xxx --seed 1 --steps 1000",
+		Category: "LOCAL COMMANDS",
+		Description: `Generate the accounts and the blocks from the seed, fund the accounts from the entropy store, then send the
+transfers, contract deployments, contract calls and receives to the node. The snapshot blocks are produced by the sbps
+of the network, so the snapshots and the reorgs of the workload are only generated for the unit tests.`,
+		Flags: []cli.Flag{endpointFlag, entropyStoreFlag, passwordFlag, fundFlag, stakeFlag, seedFlag, accountsFlag,
+			stepsFlag, contractFlag, deployFlag, receiveFlag},
+		Action: utils.MigrateFlags(loadgenAction),
+	}
+)
+
+func loadgenAction(ctx *cli.Context) error {
+	scenario, err := test_tools.NewScenario(test_tools.ScenarioConfig{
+		Seed:          ctx.Int64(seedFlag.Name),
+		Accounts:      ctx.Int(accountsFlag.Name),
+		Steps:         ctx.Int(stepsFlag.Name),
+		ContractRatio: ctx.Int(contractFlag.Name),
+		DeployRatio:   ctx.Int(deployFlag.Name),
+		ReceiveRatio:  ctx.Int(receiveFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	funder, err := loadFunder(ctx.String(entropyStoreFlag.Name), ctx.String(passwordFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	rpc, err := client.NewRpcClient(ctx.String(endpointFlag.Name))
+	if err != nil {
+		return err
+	}
+	c, err := client.NewClient(rpc)
+	if err != nil {
+		return err
+	}
+	g := &generator{
+		rpc:   rpc,
+		cli:   c,
+		prevs: make(map[types.Address]*ledger.HashHeight),
+		keys:  make(map[types.Address]ed25519.PrivateKey),
+		sends: make(map[int]types.Hash),
+	}
+	funderAddr := types.PubkeyToAddress(funder.PubByte())
+	g.keys[funderAddr] = funder
+
+	for i := 0; i < scenario.Config.Accounts; i++ {
+		addr, key, err := scenario.Account(i)
+		if err != nil {
+			return err
+		}
+		g.keys[addr] = key
+		g.accounts = append(g.accounts, addr)
+	}
+
+	start := time.Now()
+	if err := g.fund(funderAddr, attov(ctx.Int64(fundFlag.Name)), attov(ctx.Int64(stakeFlag.Name))); err != nil {
+		return err
+	}
+	fmt.Printf("%d accounts are funded in %s\n", len(g.accounts), time.Since(start))
+
+	start = time.Now()
+	for _, op := range scenario.Ops {
+		if err := g.apply(op); err != nil {
+			return fmt.Errorf("op %s failed. Error: %s", op, err)
+		}
+	}
+	elapsed := time.Since(start)
+	fmt.Printf("%d blocks are sent in %s, %.2f blocks/s, %d transfers, %d deployments, %d calls, %d receives\n",
+		len(scenario.Ops), elapsed, float64(len(scenario.Ops))/elapsed.Seconds(),
+		scenario.Count(test_tools.OpTransfer), scenario.Count(test_tools.OpDeploy),
+		scenario.Count(test_tools.OpCall), scenario.Count(test_tools.OpReceive))
+	return nil
+}
+
+func loadFunder(store, password string) (ed25519.PrivateKey, error) {
+	if store == "" {
+		return nil, errors.New("entropy store not set")
+	}
+	absPath, err := filepath.Abs(store)
+	if err != nil {
+		return nil, err
+	}
+	manager := wallet.New(&config.Wallet{DataDir: filepath.Dir(absPath)})
+	if err := manager.AddEntropyStore(absPath); err != nil {
+		return nil, err
+	}
+	if err := manager.Unlock(absPath, password); err != nil {
+		return nil, err
+	}
+	em, err := manager.GetEntropyStoreManager(absPath)
+	if err != nil {
+		return nil, err
+	}
+	_, key, err := em.DeriveForIndexPath(0)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey()
+}
+
+type generator struct {
+	rpc client.RpcClient
+	cli client.Client
+
+	accounts  []types.Address
+	contracts []types.Address
+	prevs     map[types.Address]*ledger.HashHeight
+	keys      map[types.Address]ed25519.PrivateKey
+	sends     map[int]types.Hash // hashes of the transfers by the seqs
+}
+
+func (g *generator) fund(funder types.Address, amount, stake *big.Int) error {
+	var hashes []types.Hash
+	for _, addr := range g.accounts {
+		block, err := g.cli.BuildNormalRequestBlock(client.RequestTxParams{
+			ToAddr:   addr,
+			SelfAddr: funder,
+			Amount:   amount,
+			TokenId:  ledger.ViteTokenId,
+		}, g.prevs[funder])
+		if err != nil {
+			return err
+		}
+		if err := g.send(block); err != nil {
+			return err
+		}
+		hashes = append(hashes, block.Hash)
+
+		if stake.Sign() == 0 {
+			continue
+		}
+		data, err := abi.ABIQuota.PackMethod(abi.MethodNameStakeV3, addr)
+		if err != nil {
+			return err
+		}
+		block, err = g.cli.BuildNormalRequestBlock(client.RequestTxParams{
+			ToAddr:   types.AddressQuota,
+			SelfAddr: funder,
+			Amount:   stake,
+			TokenId:  ledger.ViteTokenId,
+			Data:     data,
+		}, g.prevs[funder])
+		if err != nil {
+			return err
+		}
+		if err := g.send(block); err != nil {
+			return err
+		}
+	}
+
+	for i, addr := range g.accounts {
+		if err := g.receive(addr, hashes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) apply(op *test_tools.Op) error {
+	switch op.Type {
+	case test_tools.OpTransfer:
+		block, err := g.cli.BuildNormalRequestBlock(client.RequestTxParams{
+			ToAddr:   g.accounts[op.To],
+			SelfAddr: g.accounts[op.From],
+			Amount:   op.Amount,
+			TokenId:  ledger.ViteTokenId,
+		}, g.prevs[g.accounts[op.From]])
+		if err != nil {
+			return err
+		}
+		if err := g.send(block); err != nil {
+			return err
+		}
+		g.sends[op.Seq] = block.Hash
+		return nil
+
+	case test_tools.OpDeploy:
+		return g.deploy(g.accounts[op.From])
+
+	case test_tools.OpCall:
+		block, err := g.cli.BuildNormalRequestBlock(client.RequestTxParams{
+			ToAddr:   g.contracts[op.To],
+			SelfAddr: g.accounts[op.From],
+			Amount:   op.Amount,
+			TokenId:  ledger.ViteTokenId,
+			Data:     op.Data,
+		}, g.prevs[g.accounts[op.From]])
+		if err != nil {
+			return err
+		}
+		return g.send(block)
+
+	case test_tools.OpReceive:
+		return g.receive(g.accounts[op.From], g.sends[op.SendSeq])
+	}
+	return nil
+}
+
+func (g *generator) deploy(addr types.Address) error {
+	prev, err := g.prev(addr)
+	if err != nil {
+		return err
+	}
+	data, err := g.rpc.GetCreateContractData(api.CreateContractDataParam{
+		Gid:         types.DELEGATE_GID,
+		ConfirmTime: 1,
+		QuotaRatio:  10,
+		HexCode:     counterContractCode,
+	})
+	if err != nil {
+		return err
+	}
+	amount := "0"
+	fee := attov(10).String()
+	block := &api.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCreate,
+		PrevHash:       prev.Hash,
+		AccountAddress: addr,
+		ToAddress:      util.NewContractAddress(addr, prev.Height+1, prev.Hash),
+		TokenId:        ledger.ViteTokenId,
+		Amount:         &amount,
+		Fee:            &fee,
+		Data:           data,
+		Height:         strconv.FormatUint(prev.Height+1, 10),
+	}
+	if err := g.send(block); err != nil {
+		return err
+	}
+	g.contracts = append(g.contracts, block.ToAddress)
+	return nil
+}
+
+func (g *generator) receive(addr types.Address, sendHash types.Hash) error {
+	block, err := g.cli.BuildResponseBlock(client.ResponseTxParams{
+		SelfAddr:    addr,
+		RequestHash: sendHash,
+	}, g.prevs[addr])
+	if err != nil {
+		return err
+	}
+	return g.send(block)
+}
+
+func (g *generator) prev(addr types.Address) (*ledger.HashHeight, error) {
+	if prev, ok := g.prevs[addr]; ok {
+		return prev, nil
+	}
+	latest, err := g.rpc.GetLatestBlock(addr)
+	if err != nil {
+		return nil, err
+	}
+	prev := &ledger.HashHeight{}
+	if latest != nil && latest.Height != "" {
+		if prev.Height, err = strconv.ParseUint(latest.Height, 10, 64); err != nil {
+			return nil, err
+		}
+		prev.Hash = latest.Hash
+	}
+	return prev, nil
+}
+
+// send computes the PoW if the account has no enough quota, signs and sends the block. The PoW can be calculated
+// once in a snapshot block, so it waits for the next snapshot block and retries.
+func (g *generator) send(block *api.AccountBlock) error {
+	var toAddr *types.Address
+	if block.BlockType != ledger.BlockTypeReceive {
+		toAddr = &block.ToAddress
+	}
+	for {
+		result, err := g.rpc.CalcPoWDifficulty(api.CalcPoWDifficultyParam{
+			SelfAddr:      block.AccountAddress,
+			PrevHash:      block.PrevHash,
+			BlockType:     block.BlockType,
+			ToAddr:        toAddr,
+			Data:          block.Data,
+			UseStakeQuota: true,
+		})
+		if err != nil && err.Error() == util.ErrCalcPoWTwice.Error() {
+			if err := g.waitSnapshot(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if result.Difficulty != "" {
+			difficulty, ok := new(big.Int).SetString(result.Difficulty, 10)
+			if !ok {
+				return fmt.Errorf("invalid difficulty %s", result.Difficulty)
+			}
+			nonce, err := pow.GetPowNonce(difficulty, types.DataHash(append(block.AccountAddress.Bytes(), block.PrevHash.Bytes()...)))
+			if err != nil {
+				return err
+			}
+			block.Difficulty = &result.Difficulty
+			block.Nonce = nonce
+		}
+		break
+	}
+
+	lAb, err := block.RpcToLedgerBlock()
+	if err != nil {
+		return err
+	}
+	block.Hash = lAb.ComputeHash()
+	if err := g.cli.SignDataWithEd25519Key(g.keys[block.AccountAddress], block); err != nil {
+		return err
+	}
+	if err := g.rpc.SendRawTx(block); err != nil {
+		return err
+	}
+	g.prevs[block.AccountAddress] = &ledger.HashHeight{Hash: block.Hash, Height: lAb.Height}
+	return nil
+}
+
+func (g *generator) waitSnapshot() error {
+	height := g.rpc.GetSnapshotChainHeight()
+	for i := 0; i < 600; i++ {
+		time.Sleep(100 * time.Millisecond)
+		if g.rpc.GetSnapshotChainHeight() != height {
+			return nil
+		}
+	}
+	return errors.New("no snapshot block is produced in 1 minute")
+}
+
+func attov(vite int64) *big.Int {
+	amount := big.NewInt(vite)
+	return amount.Mul(amount, util.AttovPerVite)
+}
@@ -0,0 +1,64 @@
+package subcmd_probe
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/common"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	probeReadyFlag = cli.BoolFlag{
+		Name:  "ready",
+		Usage: "Query the readiness instead of the liveness",
+	}
+	probeTimeoutFlag = cli.DurationFlag{
+		Name:  "probeTimeout",
+		Usage: "Timeout of the probe",
+		Value: 5 * time.Second,
+	}
+
+	ProbeCommand = cli.Command{
+		Action:    utils.MigrateFlags(probeAction),
+		Name:      "probe",
+		Usage:     "Query the liveness or readiness of a running node",
+		ArgsUsage: "gvite probe [--ready] [endpoint]",
+		Flags:     []cli.Flag{probeReadyFlag, probeTimeoutFlag},
+		Category:  "REMOTE COMMANDS",
+		Description: `Query /live or /ready of the http endpoint, http://127.0.0.1:48132 by default.
+The command fails if the node is down or not ready, which can be used by exec probes of containers.`,
+	}
+)
+
+func probeAction(ctx *cli.Context) error {
+	endpoint := ctx.Args().First()
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("http://127.0.0.1:%d", common.DefaultHTTPPort)
+	}
+	path := "/live"
+	if ctx.Bool(probeReadyFlag.Name) {
+		path = "/ready"
+	}
+
+	client := &http.Client{Timeout: ctx.Duration(probeTimeoutFlag.Name)}
+	resp, err := client.Get(strings.TrimSuffix(endpoint, "/") + path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	fmt.Println(strings.TrimSpace(string(body)))
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package subcmd_prune
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+var (
+	beforeHeightFlag = cli.Uint64Flag{
+		Name:  "before-height",
+		Usage: "prune the history data older than the snapshot height",
+	}
+	dryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "estimate the size of the history data to prune, nothing is deleted",
+	}
+
+	PruneCommand = cli.Command{
+		Name:     "prune",
+		Usage:    "prune the history data, eg: prune --before-height 100000000 --dry-run",
+		Category: "LOCAL COMMANDS",
+		Description: `Delete the history storage, history balances, redo logs and vm logs older than the snapshot height,
+the block files are kept. The state before the height can't be queried after pruning. The node should be stopped.`,
+		Flags: append(utils.ConfigFlags, []cli.Flag{
+			beforeHeightFlag,
+			dryRunFlag,
+		}...),
+		Action: utils.MigrateFlags(pruneAction),
+	}
+)
+
+func pruneAction(ctx *cli.Context) error {
+	beforeHeight := ctx.Uint64(beforeHeightFlag.Name)
+	if beforeHeight == 0 {
+		return errors.New("before-height not set")
+	}
+	dryRun := ctx.Bool(dryRunFlag.Name)
+
+	node, err := nodemanager.LocalNodeMaker{}.MakeNode(ctx)
+	if err != nil {
+		return err
+	}
+	if err := node.Prepare(); err != nil {
+		return err
+	}
+	c := node.Vite().Chain()
+	defer c.Destroy()
+
+	result, err := c.PruneHistory(beforeHeight, dryRun, func(stat *chain.PruneStat) {
+		fmt.Printf("%s: %d keys, %s\n", stat.Kind, stat.Keys, formatSize(stat.Bytes))
+	})
+	if err != nil {
+		return err
+	}
+
+	action := "pruned"
+	if result.DryRun {
+		action = "to prune (dry run)"
+	}
+	var keys, size uint64
+	for _, stat := range result.Stats() {
+		keys += stat.Keys
+		size += stat.Bytes
+	}
+	fmt.Printf("history data before snapshot height %d %s: %d keys, %s\n", result.BeforeHeight, action, keys, formatSize(size))
+	return nil
+}
+
+func formatSize(size uint64) string {
+	return fmt.Sprintf("%.2f MB", float64(size)/1048576.0)
+}
@@ -0,0 +1,101 @@
+package subcmd_replay
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	chain_replay "github.com/vitelabs/go-vite/v2/ledger/chain/replay"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+)
+
+var (
+	dirFlag = cli.StringFlag{
+		Name:  "replay-dir",
+		Usage: "empty directory of the replayed chain, it is kept after replay. A temporary directory is used and removed if not set",
+	}
+	toHeightFlag = cli.Uint64Flag{
+		Name:  "to-height",
+		Usage: "the last snapshot height to replay, 0 means the latest snapshot block",
+	}
+
+	ReplayCommand = cli.Command{
+		Name:     "replay",
+		Usage:    "re-execute the ledger from the genesis and cross-check the state, eg: replay --to-height 1000000",
+		Category: "LOCAL COMMANDS",
+		Description: `Re-execute every account block from the genesis with the vm against a fresh chain, and compare the
+balances and the contract storage of the touched accounts with the local ledger at every snapshot block.
+The first divergence is reported. The node should be stopped.`,
+		Flags: append(utils.ConfigFlags, []cli.Flag{
+			dirFlag,
+			toHeightFlag,
+		}...),
+		Action: utils.MigrateFlags(replayAction),
+	}
+)
+
+func replayAction(ctx *cli.Context) error {
+	dir := ctx.String(dirFlag.Name)
+	if dir == "" {
+		tempDir, err := ioutil.TempDir("", "gvite_replay")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+		dir = tempDir
+	}
+
+	node, err := nodemanager.LocalNodeMaker{}.MakeNode(ctx)
+	if err != nil {
+		return err
+	}
+	if err := node.Prepare(); err != nil {
+		return err
+	}
+	source := node.Vite().Chain()
+	defer source.Destroy()
+
+	// the vm reads the SBP information of the local ledger
+	cfg := node.Vite().Config()
+	csCfg := consensus.Cfg(false)
+	if csCfg.Algos, err = cfg.Genesis.ConsensusAlgos(); err != nil {
+		return err
+	}
+	cs := node.Vite().Consensus()
+	if err := cs.Init(csCfg); err != nil {
+		return err
+	}
+
+	replayer, err := chain_replay.NewReplayer(source, cs, cfg.Genesis, chain_replay.Config{
+		Dir:      dir,
+		ToHeight: ctx.Uint64(toHeightFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+	defer replayer.Close()
+
+	toHeight := replayer.ToHeight()
+	fmt.Printf("replay snapshot blocks [2, %d] in %s\n", toHeight, dir)
+	start := time.Now()
+	divergence, err := replayer.Run(func(height uint64) {
+		if height%1000 == 0 || height == toHeight {
+			fmt.Printf("replayed snapshot block %d/%d, elapsed %s\n", height, toHeight, time.Since(start).Truncate(time.Second))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if divergence != nil {
+		fmt.Println(divergence)
+		return errors.New("the replayed state is different from the local ledger")
+	}
+	fmt.Printf("the replayed state is the same as the local ledger at snapshot block %d\n", toHeight)
+	return nil
+}
@@ -0,0 +1,255 @@
+package subcmd_signer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/vitelabs/go-vite/v2/cmd/utils"
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/remote"
+)
+
+var (
+	signerEntropyStoreFlag = cli.StringFlag{
+		Name:  "signerEntropyStore",
+		Usage: "Entropy store file which holds the keys",
+	}
+	signerPasswordFileFlag = cli.StringFlag{
+		Name:  "signerPasswordFile",
+		Usage: "File of the password of the entropy store",
+	}
+	signerIndexesFlag = cli.StringFlag{
+		Name:  "signerIndexes",
+		Usage: "Comma separated indexes of the keys to serve, e.g. 0,1",
+		Value: "0",
+	}
+	signerListenFlag = cli.StringFlag{
+		Name:  "signerListen",
+		Usage: "Endpoint of the signer, unix://<path> or http://<host:port>",
+		Value: "unix://" + filepath.Join(os.TempDir(), "vite-signer.sock"),
+	}
+	signerTokenFileFlag = cli.StringFlag{
+		Name:  "signerTokenFile",
+		Usage: "File of the token which the clients must present",
+	}
+	signerAutoApproveFlag = cli.StringFlag{
+		Name:  "signerAutoApprove",
+		Usage: "Comma separated kinds of requests approved without asking: snapshotBlock, accountBlock. Data requests are always asked",
+	}
+	signerStateFileFlag = cli.StringFlag{
+		Name:  "signerStateFile",
+		Usage: "File of the latest signed snapshot blocks which protects against double signing, <signerEntropyStore>.signed.json by default",
+	}
+	signerLatestUpgradeFlag = cli.BoolFlag{
+		Name:  "signerLatestUpgrade",
+		Usage: "Compute the hashes of snapshot blocks by the latest upgrade points instead of mainnet",
+	}
+
+	SignerCommand = cli.Command{
+		Action:    utils.MigrateFlags(signerAction),
+		Name:      "signer",
+		Usage:     "Run a signer daemon which signs blocks for the producer and the wallet",
+		ArgsUsage: "gvite signer --signerEntropyStore <file> --signerPasswordFile <file> --signerTokenFile <file>",
+		Flags: []cli.Flag{
+			signerEntropyStoreFlag,
+			signerPasswordFileFlag,
+			signerIndexesFlag,
+			signerListenFlag,
+			signerTokenFileFlag,
+			signerAutoApproveFlag,
+			signerLatestUpgradeFlag,
+			signerStateFileFlag,
+		},
+		Category: "WALLET COMMANDS",
+		Description: `The signer holds the keys out of the network facing node. A node uses it by RemoteSigner and
+RemoteSignerToken of the node config, or by wallet_addRemoteSigner. Every request contains the full block,
+the requests of kinds not in --signerAutoApprove are printed and approved on the terminal. A data request may be
+the hash of any block, so it's always approved on the terminal. A snapshot block is never signed at or below the
+height of the latest one signed by the same address, the heights are kept in --signerStateFile across restarts.`,
+	}
+)
+
+func signerAction(ctx *cli.Context) error {
+	store := ctx.String(signerEntropyStoreFlag.Name)
+	if store == "" {
+		return errors.New("--signerEntropyStore is required")
+	}
+	password, err := readSecret(ctx.String(signerPasswordFileFlag.Name))
+	if err != nil {
+		return fmt.Errorf("read password: %v", err)
+	}
+	token, err := readSecret(ctx.String(signerTokenFileFlag.Name))
+	if err != nil {
+		return fmt.Errorf("read token: %v", err)
+	}
+
+	if ctx.Bool(signerLatestUpgradeFlag.Name) {
+		upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	} else {
+		upgrade.InitUpgradeBox(upgrade.NewMainnetUpgradeBox())
+	}
+
+	accounts, err := loadAccounts(store, password, ctx.String(signerIndexesFlag.Name))
+	if err != nil {
+		return err
+	}
+	approver, err := newTerminalApprover(ctx.String(signerAutoApproveFlag.Name))
+	if err != nil {
+		return err
+	}
+	server, err := remote.NewServer(token, approver, accounts...)
+	if err != nil {
+		return err
+	}
+	stateFile := ctx.String(signerStateFileFlag.Name)
+	if stateFile == "" {
+		stateFile = store + ".signed.json"
+	}
+	if err := server.SetStateFile(stateFile); err != nil {
+		return err
+	}
+	if err := server.Start(ctx.String(signerListenFlag.Name)); err != nil {
+		return err
+	}
+	defer server.Stop()
+	for _, acct := range accounts {
+		fmt.Printf("serving %s\n", acct.Address())
+	}
+	fmt.Printf("signer is listening on %s\n", ctx.String(signerListenFlag.Name))
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	<-c
+	return nil
+}
+
+func readSecret(filename string) (string, error) {
+	if filename == "" {
+		return "", errors.New("file is required")
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func loadAccounts(store, password, indexes string) ([]interfaces.Account, error) {
+	absPath, err := filepath.Abs(store)
+	if err != nil {
+		return nil, err
+	}
+	manager := wallet.New(&config.Wallet{DataDir: filepath.Dir(absPath)})
+	if err := manager.AddEntropyStore(absPath); err != nil {
+		return nil, err
+	}
+	if err := manager.Unlock(absPath, password); err != nil {
+		return nil, err
+	}
+	em, err := manager.GetEntropyStoreManager(absPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []interfaces.Account
+	for _, s := range strings.Split(indexes, ",") {
+		index, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", s)
+		}
+		_, key, err := em.DeriveForIndexPath(uint32(index))
+		if err != nil {
+			return nil, err
+		}
+		priv, err := key.PrivateKey()
+		if err != nil {
+			return nil, err
+		}
+		acct, err := wallet.NewAccountFromHexKey(priv.Hex())
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acct)
+	}
+	return accounts, nil
+}
+
+// terminalApprover approves the kinds of auto, and asks on the terminal for the others.
+// Data requests are never in auto, a data request may be the hash of any block.
+type terminalApprover struct {
+	auto map[string]bool
+
+	mu     sync.Mutex
+	reader *bufio.Reader
+}
+
+func newTerminalApprover(autoKinds string) (*terminalApprover, error) {
+	a := &terminalApprover{auto: make(map[string]bool), reader: bufio.NewReader(os.Stdin)}
+	for _, kind := range strings.Split(autoKinds, ",") {
+		switch kind = strings.TrimSpace(kind); kind {
+		case "":
+		case remote.KindAccountBlock, remote.KindSnapshotBlock:
+			a.auto[kind] = true
+		case remote.KindData:
+			return nil, errors.New("data requests can't be approved without asking")
+		default:
+			return nil, fmt.Errorf("unknown kind of request %q", kind)
+		}
+	}
+	return a, nil
+}
+
+func (a *terminalApprover) Approve(req *remote.SignRequest) error {
+	if req.Kind != remote.KindData && a.auto[req.Kind] {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	fmt.Printf("\n%s request of %s\n", req.Kind, req.Address)
+	switch req.Kind {
+	case remote.KindAccountBlock:
+		b := req.AccountBlock
+		fmt.Printf("  hash %s height %d type %d\n", b.Hash, b.Height, b.BlockType)
+		if b.IsSendBlock() {
+			fmt.Printf("  to %s amount %s token %s\n", b.ToAddress, b.Amount, b.TokenId)
+		} else {
+			fmt.Printf("  receive %s\n", b.FromBlockHash)
+		}
+		if len(b.Data) > 0 {
+			fmt.Printf("  data %x\n", b.Data)
+		}
+	case remote.KindSnapshotBlock:
+		b := req.SnapshotBlock
+		fmt.Printf("  hash %s height %d time %s accounts %d\n", b.Hash, b.Height, b.Timestamp, len(b.SnapshotContent))
+	default:
+		fmt.Printf("  data %x\n", req.Data)
+		if len(req.Data) == types.HashSize {
+			fmt.Println("  the data may be the hash of a block, approve it only if you know what it is")
+		}
+	}
+	fmt.Print("approve? [y/N] ")
+	answer, err := a.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+		return errors.New("rejected by operator")
+	}
+	return nil
+}
@@ -144,6 +144,10 @@ var (
 		Name:  "loglevel",
 		Usage: "log level (info,eror,warn,dbug)",
 	}
+	LogFormatFlag = cli.StringFlag{
+		Name:  "logformat",
+		Usage: "format of the log files (logfmt,json)",
+	}
 
 	//VM
 	VMTestFlag = cli.BoolFlag{
@@ -301,6 +305,7 @@ var (
 	//Log
 	LogFlags = []cli.Flag{
 		LogLvlFlag,
+		LogFormatFlag,
 	}
 
 	//VM
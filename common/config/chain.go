@@ -1,6 +1,23 @@
 package config
 
-import "github.com/vitelabs/go-vite/v2/common/types"
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// LedgerMode is the history kept by the node
+type LedgerMode string
+
+const (
+	// LedgerModeArchive keeps all the history keys and vm logs, the history is never pruned
+	LedgerModeArchive LedgerMode = "archive"
+	// LedgerModeFull prunes the history older than HistoryRetentionDays, the vm logs are saved by the white list
+	LedgerModeFull LedgerMode = "full"
+	// LedgerModeLight keeps the blocks, which are the headers and the proofs served to light clients, and the latest state.
+	// The history is pruned hourly but for the recent blocks kept for rolling back, the vm logs are not saved.
+	LedgerModeLight LedgerMode = "light"
+)
 
 // chain config
 type Chain struct {
@@ -9,6 +26,27 @@ type Chain struct {
 	LedgerGc       bool   // open or close ledger garbage collector
 	OpenPlugins    bool   // open or close chain plugins. eg, filter account blocks by token.
 
-	VmLogWhiteList []types.Address // contract address white list which save VM logs
-	VmLogAll       bool            // save all VM logs, it will cost more disk space
+	VmLogWhiteList []types.Address // contract address white list which save VM logs, ignored in light mode
+	VmLogAll       bool            // save all VM logs, it will cost more disk space, ignored in light mode
+
+	Mode                 LedgerMode // empty means full
+	HistoryRetentionDays uint64     // the history older than it is pruned at startup and hourly in full mode, 0 means never
+
+	AccountCacheSize int   // size of the account cache of the index db, 0 means the default
+	StateCacheSize   int64 // size of the StateDB cache in bytes, 0 means the default
+
+	StateCacheWarmUp       bool   // preload the hot balances into the StateDB cache before the node starts serving
+	StateCacheWarmUpBlocks uint64 // the accounts in the latest snapshot blocks are hot, 0 means the default
+
+	PoolJournal        bool  // persist the account blocks in the pool and replay them at startup
+	PoolJournalMaxSize int64 // size cap of the pool journal in bytes, 0 means the default
+
+	BlockFileSyncMode     string        // "write", "flush" or "timed", when the block files written are synced, empty means "write"
+	BlockFileSyncInterval time.Duration // the interval of the "timed" sync mode, 0 means the default
+	BlockFilePreallocate  bool          // allocate the disk space of a block file when it's created
+	BlockFileDSync        bool          // open the block files with O_DSYNC, the sync mode is ignored
+
+	CompactAt string // "HH:MM" local time of the daily compaction of the index, state and redo dbs, empty means never
+
+	MaxBatchSize int // max size in bytes of a batch built by the snapshots and written to the index, state and redo dbs, 0 means the default
 }
@@ -0,0 +1,22 @@
+package config
+
+import "github.com/vitelabs/go-vite/v2/common/types"
+
+// DefaultCheckpoints are the checkpoints of the public networks, the key is the hash of the genesis snapshot block.
+// A checkpoint is "hash/height" of an irreversible snapshot block taken from a trusted synced node, a wrong one
+// makes the nodes reject the chain, so only the blocks confirmed by the operators are added here. Only the chunks
+// linked by hash down from the latest checkpoint skip the signature verification in sync.
+var DefaultCheckpoints = map[string][]string{
+	// mainnet
+	"a53774e6b799726a6e978357527d10ffbeb563c584c109b75234999472e4082e": {
+		"a53774e6b799726a6e978357527d10ffbeb563c584c109b75234999472e4082e/1",
+	},
+}
+
+// CheckpointList returns the configured checkpoints, or the default checkpoints of the network of genesis
+func (net *Net) CheckpointList(genesis types.Hash) []string {
+	if len(net.Checkpoints) > 0 {
+		return net.Checkpoints
+	}
+	return DefaultCheckpoints[genesis.String()]
+}
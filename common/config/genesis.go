@@ -18,6 +18,7 @@ type Genesis struct {
 	QuotaInfo             *QuotaContractInfo
 	AccountBalanceMap     map[string]map[string]*big.Int // address - tokenId - balanceAmount
 	DexFundInfo           *DexFundContractInfo
+	ConsensusAlgoMap      map[string]string // election algorithm, gid - algorithm name, the default algorithm is used if absent
 }
 
 func (g *Genesis) UnmarshalJSON(data []byte) error {
@@ -40,6 +41,22 @@ func IsCompleteGenesisConfig(genesisConfig *Genesis) bool {
 	return true
 }
 
+// ConsensusAlgos parses the election algorithm of every consensus group
+func (g *Genesis) ConsensusAlgos() (map[types.Gid]string, error) {
+	if len(g.ConsensusAlgoMap) == 0 {
+		return nil, nil
+	}
+	result := make(map[types.Gid]string, len(g.ConsensusAlgoMap))
+	for gidStr, name := range g.ConsensusAlgoMap {
+		gid, err := types.HexToGid(gidStr)
+		if err != nil {
+			return nil, err
+		}
+		result[gid] = name
+	}
+	return result, nil
+}
+
 type ForkPoint struct {
 	Height  uint64
 	Version uint32
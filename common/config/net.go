@@ -21,6 +21,8 @@ const (
 
 	DefaultDiscover = true
 
+	DefaultNAT = "any"
+
 	DefaultMaxPeers        = 60
 	DefaultMaxInboundRatio = 2
 	DefaultMinPeers        = 5
@@ -31,6 +33,13 @@ const (
 
 	DefaultForwardStrategy = "cross"
 	DefaultAccessControl   = "any"
+
+	TransportTCP  = "tcp"
+	TransportQUIC = "quic"
+
+	EncryptionNone      = "none"
+	EncryptionPreferred = "preferred"
+	EncryptionRequired  = "required"
 )
 
 type Net struct {
@@ -48,11 +57,24 @@ type Net struct {
 
 	FilePort int
 
+	// Transport is "tcp" or "quic", default "tcp". QUIC listens on udp port Port+1 besides tcp,
+	// and dials by QUIC first, the existing handshake is kept.
+	Transport string
+
+	// Encryption is "none", "preferred" or "required", default "preferred". The TCP connections are upgraded to TLS
+	// after the handshake if both sides support it, the TLS session is bound to the node keys. Peers without the
+	// encryption are disconnected if it is "required". QUIC connections are always encrypted and bound to the node ids.
+	Encryption string
+
 	// PublicAddress is the network address can be access by other nodes, usually is the public Internet address
 	PublicAddress string
 
 	FilePublicAddress string
 
+	// NAT maps the ports on the router and discovers the external IP, it is "none", "any", "upnp", "pmp",
+	// "pmp:<gateway>" or "extip:<ip>". The external IP is advertised if PublicAddress is not set.
+	NAT string
+
 	// DataDir is the directory to storing p2p data, if is null-string, will use memory as database
 	DataDir string
 
@@ -66,12 +88,18 @@ type Net struct {
 	// but not create a TCP connection to BootNodes directly
 	BootNodes []string
 
-	// BootSeeds are the address where can query BootNodes, is a more flexible option than BootNodes
+	// BootSeeds are the address where can query BootNodes, is a more flexible option than BootNodes.
+	// HTTP seeds are queried by POST, DNS seeds are "dns://<domain>" of TXT lists or "vtree://<key>@<domain>" of
+	// signed trees, they are resolved again every 30 minutes.
 	BootSeeds []string
 
 	// StaticNodes will be connect directly
 	StaticNodes []string
 
+	// TrustedNodes are static nodes beyond the peer limits, they are never demoted or banned by the scores,
+	// for the interconnects of SBP clusters
+	TrustedNodes []string
+
 	MaxPeers int
 
 	MaxInboundRatio int
@@ -84,6 +112,13 @@ type Net struct {
 	// this value is for defend DDOS attack, default 10
 	MaxPendingPeers int
 
+	// UploadLimit and DownloadLimit are the rates in bytes per second of all connections, PeerUploadLimit and
+	// PeerDownloadLimit are the rates of each connection, sync chunks included. Zero is unlimited.
+	UploadLimit       int
+	DownloadLimit     int
+	PeerUploadLimit   int
+	PeerDownloadLimit int
+
 	ForwardStrategy string
 
 	AccessControl   string
@@ -93,6 +128,10 @@ type Net struct {
 	BlackBlockHashList []string
 	WhiteBlockList     []string
 
+	// Checkpoints are the trusted snapshot blocks like "hash/height", the syncer and verifier reject the chains
+	// contradicting them. DefaultCheckpoints of the network are used if it is empty.
+	Checkpoints []string
+
 	MineKey ed25519.PrivateKey
 }
 
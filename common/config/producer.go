@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 )
@@ -13,8 +14,48 @@ type Producer struct {
 	Coinbase         string `json:"Coinbase"`
 	EntropyStorePath string `json:"EntropyStorePath"`
 
+	// Ledger signs by the Ledger device of LedgerDevicePath instead of the entropy store,
+	// the index of Coinbase is the index of the key on device.
+	Ledger           bool   `json:"Ledger"`
+	LedgerDevicePath string `json:"LedgerDevicePath"`
+
+	// RemoteSigner is the endpoint of the signer daemon which holds the key of Coinbase,
+	// e.g. unix:///var/run/vite-signer.sock, the key is not loaded into the node if set.
+	RemoteSigner        string        `json:"RemoteSigner"`
+	RemoteSignerToken   string        `json:"RemoteSignerToken"`
+	RemoteSignerTimeout time.Duration `json:"RemoteSignerTimeout"`
+
 	ExternalMiner bool `json:"externalMiner"`
 
+	// AutoMine drives the external miner automatically in dev mode
+	AutoMine         bool          `json:"autoMine"`
+	AutoMineInterval time.Duration `json:"autoMineInterval"`
+
+	// ConsensusReplayWindow is the lookback window of consensus events replayed to late subscribers
+	ConsensusReplayWindow time.Duration `json:"consensusReplayWindow"`
+
+	// OnRoadOrderPolicy is the order in which onroad blocks of callers are received by contracts,
+	// one of "default", "difficulty", "quota", "age" and "fee", blocks of OnRoadPriorityCallers are always received first.
+	OnRoadOrderPolicy     string          `json:"onRoadOrderPolicy"`
+	OnRoadPriorityCallers []types.Address `json:"onRoadPriorityCallers"`
+
+	// Standby makes the node the hot-standby of the primary producer of Coinbase, it listens on HeartbeatListen
+	// and takes over after StandbyMissedSlots slots without heartbeats. The primary sends heartbeats to HeartbeatAddrs.
+	// Both nodes must lock each slot in SlotLockDir before signing, e.g. a directory on a shared filesystem.
+	Standby            bool     `json:"standby"`
+	HeartbeatListen    string   `json:"heartbeatListen"`
+	HeartbeatAddrs     []string `json:"heartbeatAddrs"`
+	StandbyMissedSlots int      `json:"standbyMissedSlots"`
+	SlotLockDir        string   `json:"slotLockDir"`
+
+	// The snapshot slot is skipped if the node is HealthMaxSyncLag heights behind the sync peer, has less than
+	// HealthMinPeers peers, the flusher is not healthy with HealthCheckFlusher, or the clock drifts more than
+	// HealthMaxClockSkew. 0 or false disables the check.
+	HealthMaxSyncLag   uint64        `json:"healthMaxSyncLag"`
+	HealthMinPeers     int           `json:"healthMinPeers"`
+	HealthCheckFlusher bool          `json:"healthCheckFlusher"`
+	HealthMaxClockSkew time.Duration `json:"healthMaxClockSkew"`
+
 	coinbase types.Address
 	index    uint32
 }
@@ -26,6 +67,11 @@ func (cfg *Producer) IsMine() bool {
 	return cfg.Producer && cfg.Coinbase != ""
 }
 
+// HealthEnabled returns true if any precondition is checked before signing a snapshot block
+func (cfg *Producer) HealthEnabled() bool {
+	return cfg.HealthMaxSyncLag > 0 || cfg.HealthMinPeers > 0 || cfg.HealthCheckFlusher || cfg.HealthMaxClockSkew > 0
+}
+
 func (cfg Producer) GetCoinbase() types.Address {
 	return cfg.coinbase
 }
@@ -0,0 +1,28 @@
+package config
+
+import "github.com/vitelabs/go-vite/v2/common/types"
+
+// Reloadable is the part of the node config which can be changed without restart
+type Reloadable struct {
+	LogLevel string
+
+	// limits of the http and websocket endpoints, nil means unlimited
+	RPCLimit *RPCLimit
+
+	MaxPeers        int
+	MinPeers        int
+	MaxInboundRatio int
+
+	VmLogWhiteList []types.Address
+
+	// size of the account cache of the index db, 0 means unchanged
+	AccountCacheSize int
+
+	// size of the StateDB cache in bytes, 0 means unchanged
+	StateCacheSize int64
+}
+
+// ConfigWatcher is implemented by the subsystems which apply the reloaded config
+type ConfigWatcher interface {
+	ReloadConfig(cfg *Reloadable) error
+}
@@ -0,0 +1,20 @@
+package config
+
+// RPCLimit configures the rate limits of the public RPC endpoints.
+// Rates are requests per second, zero means unlimited.
+type RPCLimit struct {
+	IPRate        float64 `json:"IPRate"`
+	IPBurst       int     `json:"IPBurst"`
+	MaxConcurrent int     `json:"MaxConcurrent"` // concurrent requests of the server, zero means unlimited
+
+	RequireAPIKey bool                  `json:"RequireAPIKey"` // reject requests without a known api key
+	APIKeys       map[string]*RPCAPIKey `json:"APIKeys"`
+}
+
+// RPCAPIKey is the limit of an api key, requests with a known key are not limited by IPRate
+type RPCAPIKey struct {
+	Rate  float64 `json:"Rate"`
+	Burst int     `json:"Burst"`
+	// Methods is the allowlist of the key, like "ledger_getAccountBlockByHash" or "ledger_*", empty means all
+	Methods []string `json:"Methods"`
+}
@@ -3,4 +3,13 @@ package config
 type Wallet struct {
 	DataDir        string
 	MaxSearchIndex uint32
+
+	// key derivation of the new entropy store files, KDF is "scrypt" or "argon2id", zero values mean the standard parameters
+	KDF           string
+	ScryptN       int
+	ScryptR       int
+	ScryptP       int
+	Argon2Time    uint32
+	Argon2Memory  uint32 // in KiB
+	Argon2Threads uint8
 }
@@ -1032,11 +1032,18 @@ type DBStats struct {
 	BlockCacheSize    int
 	OpenedTablesCount int
 
+	BlockCacheHits   uint64
+	BlockCacheMisses uint64
+	FilterChecks     uint64
+	FilterUseful     uint64
+
+	Levels            []int
 	LevelSizes        []int64
 	LevelTablesCounts []int
 	LevelRead         []int64
 	LevelWrite        []int64
 	LevelDurations    []time.Duration
+	LevelCompactions  []int64
 }
 
 // Stats populates s with database statistics.
@@ -1062,11 +1069,19 @@ func (db *DB) Stats(s *DBStats) error {
 	s.AliveIterators = atomic.LoadInt32(&db.aliveIters)
 	s.AliveSnapshots = atomic.LoadInt32(&db.aliveSnaps)
 
+	tstats := db.s.tops.stats
+	s.BlockCacheHits = atomic.LoadUint64(&tstats.BlockCacheHits)
+	s.BlockCacheMisses = atomic.LoadUint64(&tstats.BlockCacheMisses)
+	s.FilterChecks = atomic.LoadUint64(&tstats.FilterChecks)
+	s.FilterUseful = atomic.LoadUint64(&tstats.FilterUseful)
+
+	s.Levels = s.Levels[:0]
 	s.LevelDurations = s.LevelDurations[:0]
 	s.LevelRead = s.LevelRead[:0]
 	s.LevelWrite = s.LevelWrite[:0]
 	s.LevelSizes = s.LevelSizes[:0]
 	s.LevelTablesCounts = s.LevelTablesCounts[:0]
+	s.LevelCompactions = s.LevelCompactions[:0]
 
 	v := db.s.version()
 	defer v.release()
@@ -1076,11 +1091,13 @@ func (db *DB) Stats(s *DBStats) error {
 		if len(tables) == 0 && duration == 0 {
 			continue
 		}
+		s.Levels = append(s.Levels, level)
 		s.LevelDurations = append(s.LevelDurations, duration)
 		s.LevelRead = append(s.LevelRead, read)
 		s.LevelWrite = append(s.LevelWrite, write)
 		s.LevelSizes = append(s.LevelSizes, tables.size())
 		s.LevelTablesCounts = append(s.LevelTablesCounts, len(tables))
+		s.LevelCompactions = append(s.LevelCompactions, db.compStats.getCount(level))
 	}
 
 	return nil
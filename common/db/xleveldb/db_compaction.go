@@ -23,6 +23,7 @@ type cStat struct {
 	duration time.Duration
 	read     int64
 	write    int64
+	count    int64
 }
 
 func (p *cStat) add(n *cStatStaging) {
@@ -73,6 +74,27 @@ func (p *cStats) addStat(level int, n *cStatStaging) {
 	p.lk.Unlock()
 }
 
+// addCount counts a compaction into the level
+func (p *cStats) addCount(level int) {
+	p.lk.Lock()
+	if level >= len(p.stats) {
+		newStats := make([]cStat, level+1)
+		copy(newStats, p.stats)
+		p.stats = newStats
+	}
+	p.stats[level].count++
+	p.lk.Unlock()
+}
+
+func (p *cStats) getCount(level int) int64 {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+	if level < len(p.stats) {
+		return p.stats[level].count
+	}
+	return 0
+}
+
 func (p *cStats) getStat(level int) (duration time.Duration, read, write int64) {
 	p.lk.Lock()
 	defer p.lk.Unlock()
@@ -328,6 +350,7 @@ func (db *DB) memCompaction() {
 		stats.write += r.size
 	}
 	db.compStats.addStat(flushLevel, stats)
+	db.compStats.addCount(flushLevel)
 
 	// Drop frozen memdb.
 	db.dropFrozenMem()
@@ -588,6 +611,7 @@ func (db *DB) tableCompaction(c *compaction, noTrivial bool) {
 	for i := range stats {
 		db.compStats.addStat(c.sourceLevel+1, &stats[i])
 	}
+	db.compStats.addCount(c.sourceLevel + 1)
 }
 
 func (db *DB) tableRangeCompaction(level int, umin, umax []byte) error {
@@ -230,6 +230,7 @@ func (tr *Transaction) Commit() error {
 
 		// Update compaction stats. This is safe as long as we hold compCommitLk.
 		tr.db.compStats.addStat(0, &tr.stats)
+		tr.db.compStats.addCount(0)
 
 		// Trigger table auto-compaction.
 		tr.db.compTrigger(tr.db.tcompCmdC)
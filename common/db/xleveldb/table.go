@@ -295,6 +295,7 @@ type tOps struct {
 	cache  *cache.Cache
 	bcache *cache.Cache
 	bpool  *util.BufferPool
+	stats  *table.Stats
 }
 
 // Creates an empty table and returns table writer.
@@ -362,6 +363,7 @@ func (t *tOps) open(f *tFile) (ch *cache.Handle, err error) {
 			r.Close()
 			return 0, nil
 		}
+		tr.SetStats(t.stats)
 		return 1, tr
 
 	})
@@ -464,6 +466,7 @@ func newTableOps(s *session) *tOps {
 		cache:  cache.NewCache(cacher),
 		bcache: bcache,
 		bpool:  bpool,
+		stats:  &table.Stats{},
 	}
 }
 
@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/golang/snappy"
 
@@ -525,6 +526,44 @@ type Reader struct {
 	metaBH, indexBH, filterBH blockHandle
 	indexBlock                *block
 	filterBlock               *filterBlock
+
+	stats *Stats
+}
+
+// Stats counts the block cache and the filter lookups of the readers sharing it
+type Stats struct {
+	BlockCacheHits   uint64
+	BlockCacheMisses uint64
+
+	// FilterChecks is the lookups checked against the filter, FilterUseful is the lookups ruled out by the filter
+	FilterChecks uint64
+	FilterUseful uint64
+}
+
+// SetStats sets the stats counted by the reader
+func (r *Reader) SetStats(stats *Stats) {
+	r.stats = stats
+}
+
+func (r *Reader) countCache(hit bool) {
+	if r.stats == nil {
+		return
+	}
+	if hit {
+		atomic.AddUint64(&r.stats.BlockCacheHits, 1)
+	} else {
+		atomic.AddUint64(&r.stats.BlockCacheMisses, 1)
+	}
+}
+
+func (r *Reader) countFilter(useful bool) {
+	if r.stats == nil {
+		return
+	}
+	atomic.AddUint64(&r.stats.FilterChecks, 1)
+	if useful {
+		atomic.AddUint64(&r.stats.FilterUseful, 1)
+	}
 }
 
 func (r *Reader) blockKind(bh blockHandle) string {
@@ -621,8 +660,10 @@ func (r *Reader) readBlockCached(bh blockHandle, verifyChecksum, fillCache bool)
 			err error
 			ch  *cache.Handle
 		)
+		hit := true
 		if fillCache {
 			ch = r.cache.Get(bh.offset, func() (size int, value cache.Value) {
+				hit = false
 				var b *block
 				b, err = r.readBlock(bh, verifyChecksum)
 				if err != nil {
@@ -632,7 +673,9 @@ func (r *Reader) readBlockCached(bh blockHandle, verifyChecksum, fillCache bool)
 			})
 		} else {
 			ch = r.cache.Get(bh.offset, nil)
+			hit = ch != nil
 		}
+		r.countCache(hit)
 		if ch != nil {
 			b, ok := ch.Value().(*block)
 			if !ok {
@@ -848,7 +891,9 @@ func (r *Reader) find(key []byte, filtered bool, ro *opt.ReadOptions, noValue bo
 	if filtered && r.filter != nil {
 		filterBlock, frel, ferr := r.getFilterBlock(true)
 		if ferr == nil {
-			if !filterBlock.contains(r.filter, dataBH.offset, key) {
+			contains := filterBlock.contains(r.filter, dataBH.offset, key)
+			r.countFilter(!contains)
+			if !contains {
 				frel.Release()
 				return nil, nil, ErrNotFound
 			}
@@ -12,7 +12,14 @@ const (
 	DefaultHTTPPort = 48132       // Default TCP port for the HTTP RPC server
 	DefaultWSHost   = "localhost" // Default host interface for the websocket RPC server
 	DefaultWSPort   = 31420       // Default TCP port for the websocket RPC server
+	DefaultGRPCHost = "localhost" // Default host interface for the gRPC server
+	DefaultGRPCPort = 48134       // Default TCP port for the gRPC server
 	DefaultP2PPort  = 8483
+
+	DefaultPrometheusHost = "localhost" // Default host interface for the prometheus metrics server
+	DefaultPrometheusPort = 48135       // Default TCP port for the prometheus metrics server
+	DefaultDebugHost      = "localhost" // Default host interface for the debug server
+	DefaultDebugPort      = 48136       // Default TCP port for the debug server
 )
 
 // DefaultDataDir is  $HOME/viteisbest/
@@ -1,8 +1,12 @@
 package common
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 
@@ -20,6 +24,7 @@ func makeDefaultLogger(absFilePath string) io.Writer {
 	}
 }
 
+// LogHandler writes the records of lvl to the file, the levels set by SetModuleLogLevel override lvl
 func LogHandler(path, subDir, filename, lvl string) log15.Handler {
 	logLevel, err := log15.LvlFromString(lvl)
 	if err != nil {
@@ -27,5 +32,143 @@ func LogHandler(path, subDir, filename, lvl string) log15.Handler {
 	}
 	absFilename := filepath.Join(path, subDir, filename)
 	out := makeDefaultLogger(absFilename)
-	return log15.LvlFilterHandler(logLevel, log15.StreamHandler(out, log15.LogfmtFormat()))
+	return log15.FilterHandler(func(r *log15.Record) bool {
+		if lvl, ok := moduleLogLevel(r); ok {
+			return r.Lvl <= lvl
+		}
+		return r.Lvl <= logLevel
+	}, log15.StreamHandler(out, currentLogFormat()))
+}
+
+// ErrorLogHandler writes the errors to the file, it is not affected by the module levels
+func ErrorLogHandler(path, subDir, filename string) log15.Handler {
+	absFilename := filepath.Join(path, subDir, filename)
+	out := makeDefaultLogger(absFilename)
+	return log15.LvlFilterHandler(log15.LvlError, log15.StreamHandler(out, currentLogFormat()))
+}
+
+// the level of reloadable log handlers
+var logLevel = int32(log15.LvlInfo)
+
+// SetLogLevel changes the level of all reloadable log handlers
+func SetLogLevel(lvl string) error {
+	logLvl, err := log15.LvlFromString(lvl)
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&logLevel, int32(logLvl))
+	return nil
+}
+
+// LogLevel returns the level of the reloadable log handlers
+func LogLevel() string {
+	return log15.Lvl(atomic.LoadInt32(&logLevel)).String()
+}
+
+// ReloadableLogHandler is like LogHandler, but the level is shared by all reloadable handlers and can be changed by SetLogLevel
+func ReloadableLogHandler(path, subDir, filename, lvl string) log15.Handler {
+	if err := SetLogLevel(lvl); err != nil {
+		_ = SetLogLevel(log15.LvlInfo.String())
+	}
+	absFilename := filepath.Join(path, subDir, filename)
+	out := makeDefaultLogger(absFilename)
+	return log15.FilterHandler(func(r *log15.Record) bool {
+		if lvl, ok := moduleLogLevel(r); ok {
+			return r.Lvl <= lvl
+		}
+		return r.Lvl <= log15.Lvl(atomic.LoadInt32(&logLevel))
+	}, log15.StreamHandler(out, currentLogFormat()))
+}
+
+// the levels of the modules, the module of a record is the value of "module" in its context
+var (
+	moduleLevelsMu sync.RWMutex
+	moduleLevels   = make(map[string]log15.Lvl)
+	// count of moduleLevels, the records are not inspected if it is 0
+	moduleLevelCount int32
+)
+
+// SetModuleLogLevel overrides the level of the log handlers for the records of the module,
+// the override is removed if lvl is empty
+func SetModuleLogLevel(module, lvl string) error {
+	if module == "" {
+		return errors.New("module is empty")
+	}
+	moduleLevelsMu.Lock()
+	defer moduleLevelsMu.Unlock()
+	if lvl == "" {
+		delete(moduleLevels, module)
+	} else {
+		logLvl, err := log15.LvlFromString(lvl)
+		if err != nil {
+			return err
+		}
+		moduleLevels[module] = logLvl
+	}
+	atomic.StoreInt32(&moduleLevelCount, int32(len(moduleLevels)))
+	return nil
+}
+
+// ModuleLogLevels returns the levels set by SetModuleLogLevel
+func ModuleLogLevels() map[string]string {
+	moduleLevelsMu.RLock()
+	defer moduleLevelsMu.RUnlock()
+	levels := make(map[string]string, len(moduleLevels))
+	for module, lvl := range moduleLevels {
+		levels[module] = lvl.String()
+	}
+	return levels
+}
+
+func moduleLogLevel(r *log15.Record) (log15.Lvl, bool) {
+	if atomic.LoadInt32(&moduleLevelCount) == 0 {
+		return 0, false
+	}
+	for i := 0; i+1 < len(r.Ctx); i += 2 {
+		if key, ok := r.Ctx[i].(string); !ok || key != "module" {
+			continue
+		}
+		module, ok := r.Ctx[i+1].(string)
+		if !ok {
+			return 0, false
+		}
+		moduleLevelsMu.RLock()
+		lvl, ok := moduleLevels[module]
+		moduleLevelsMu.RUnlock()
+		return lvl, ok
+	}
+	return 0, false
+}
+
+const (
+	LogFormatLogfmt = "logfmt"
+	LogFormatJson   = "json"
+)
+
+// the format of the log handlers, log15.LogfmtFormat if not set
+var logFormat atomic.Value
+
+// formats of different types can't be stored in an atomic.Value
+type logFormatHolder struct {
+	format log15.Format
+}
+
+// SetLogFormat sets the format of the log handlers created after, logfmt or json
+func SetLogFormat(format string) error {
+	switch format {
+	case "", LogFormatLogfmt:
+		logFormat.Store(logFormatHolder{log15.LogfmtFormat()})
+	case LogFormatJson:
+		logFormat.Store(logFormatHolder{log15.JsonFormat()})
+	default:
+		return fmt.Errorf("unknown log format %s, logfmt or json", format)
+	}
+	return nil
+}
+
+func currentLogFormat() log15.Format {
+	if holder, ok := logFormat.Load().(logFormatHolder); ok {
+		return holder.format
+	}
+	return log15.LogfmtFormat()
 }
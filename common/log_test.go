@@ -0,0 +1,70 @@
+package common
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+func TestModuleLogLevel(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetLogFormat(LogFormatLogfmt)
+
+	if err := SetLogFormat("xml"); err == nil {
+		t.Fatal("unknown format should fail")
+	}
+	if err := SetLogFormat(LogFormatJson); err != nil {
+		t.Fatal(err)
+	}
+
+	h := LogHandler(dir, "", "test.log", "info")
+	blockLog := log15.New("module", "blockDB")
+	blockLog.SetHandler(h)
+	poolLog := log15.New("module", "pool")
+	poolLog.SetHandler(h)
+
+	blockLog.Debug("block debug 1")
+	if err := SetModuleLogLevel("blockDB", "dbug"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetModuleLogLevel("blockDB", "")
+	if levels := ModuleLogLevels(); levels["blockDB"] != "dbug" {
+		t.Fatalf("unexpected levels %v", levels)
+	}
+	blockLog.Debug("block debug 2")
+	poolLog.Debug("pool debug")
+	if err := SetModuleLogLevel("blockDB", "eror"); err != nil {
+		t.Fatal(err)
+	}
+	blockLog.Info("block info")
+	poolLog.Info("pool info")
+
+	if err := SetModuleLogLevel("blockDB", "verbose"); err == nil {
+		t.Fatal("unknown level should fail")
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		record := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("%s is not json: %s", line, err)
+		}
+		msgs = append(msgs, record["msg"].(string))
+	}
+	if strings.Join(msgs, ",") != "block debug 2,pool info" {
+		t.Fatalf("unexpected records %v", msgs)
+	}
+}
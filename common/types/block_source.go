@@ -11,4 +11,5 @@ const (
 	QueryChain                  = 41
 	RemoteSync                  = 50
 	RemoteCache                 = 60
+	LocalJournal                = 70
 )
@@ -58,6 +58,7 @@ type Handshake struct {
 	Key                  []byte   `protobuf:"bytes,10,opt,name=Key,proto3" json:"Key,omitempty"`
 	Token                []byte   `protobuf:"bytes,11,opt,name=Token,proto3" json:"Token,omitempty"`
 	PublicAddress        []byte   `protobuf:"bytes,12,opt,name=PublicAddress,proto3" json:"PublicAddress,omitempty"`
+	Features             uint64   `protobuf:"varint,13,opt,name=Features,proto3" json:"Features,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -172,6 +173,13 @@ func (m *Handshake) GetPublicAddress() []byte {
 	return nil
 }
 
+func (m *Handshake) GetFeatures() uint64 {
+	if m != nil {
+		return m.Features
+	}
+	return 0
+}
+
 type SyncConnHandshake struct {
 	ID                   []byte   `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Timestamp            int64    `protobuf:"varint,2,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
@@ -240,6 +248,7 @@ type ChunkRequest struct {
 	To                   uint64   `protobuf:"varint,2,opt,name=To,proto3" json:"To,omitempty"`
 	PrevHash             []byte   `protobuf:"bytes,3,opt,name=PrevHash,proto3" json:"PrevHash,omitempty"`
 	EndHash              []byte   `protobuf:"bytes,4,opt,name=EndHash,proto3" json:"EndHash,omitempty"`
+	Offset               uint64   `protobuf:"varint,5,opt,name=Offset,proto3" json:"Offset,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -298,12 +307,20 @@ func (m *ChunkRequest) GetEndHash() []byte {
 	return nil
 }
 
+func (m *ChunkRequest) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
 type ChunkResponse struct {
 	From                 uint64   `protobuf:"varint,1,opt,name=From,proto3" json:"From,omitempty"`
 	To                   uint64   `protobuf:"varint,2,opt,name=To,proto3" json:"To,omitempty"`
 	PrevHash             []byte   `protobuf:"bytes,3,opt,name=PrevHash,proto3" json:"PrevHash,omitempty"`
 	EndHash              []byte   `protobuf:"bytes,4,opt,name=EndHash,proto3" json:"EndHash,omitempty"`
 	Size                 uint64   `protobuf:"varint,5,opt,name=Size,proto3" json:"Size,omitempty"`
+	Offset               uint64   `protobuf:"varint,6,opt,name=Offset,proto3" json:"Offset,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -369,6 +386,13 @@ func (m *ChunkResponse) GetSize() uint64 {
 	return 0
 }
 
+func (m *ChunkResponse) GetOffset() uint64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
 type State struct {
 	Peers                []*State_Peer `protobuf:"bytes,1,rep,name=Peers,proto3" json:"Peers,omitempty"`
 	Patch                bool          `protobuf:"varint,2,opt,name=Patch,proto3" json:"Patch,omitempty"`
@@ -1092,55 +1116,56 @@ func init() {
 func init() { proto.RegisterFile("vitepb/message.proto", fileDescriptor_2a6a8486deb9ab39) }
 
 var fileDescriptor_2a6a8486deb9ab39 = []byte{
-	// 788 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4b, 0x6f, 0x1a, 0x57,
-	0x14, 0xee, 0xbc, 0x30, 0x1c, 0x03, 0xc5, 0x57, 0xb4, 0x1d, 0xd1, 0x2e, 0xd0, 0xa8, 0xaa, 0x50,
-	0x5b, 0xe3, 0xca, 0xdd, 0x74, 0xd3, 0x56, 0xd8, 0x8e, 0x8d, 0x15, 0x8b, 0x90, 0x0b, 0xca, 0xd6,
-	0x1a, 0x86, 0x23, 0x33, 0xc2, 0xcc, 0x90, 0xb9, 0x17, 0x5b, 0x8e, 0x94, 0x5d, 0x56, 0xf9, 0x3b,
-	0xf9, 0x83, 0xd1, 0x7d, 0xcc, 0x0b, 0x43, 0x94, 0x4d, 0x76, 0xe7, 0x35, 0xe7, 0x3b, 0xdf, 0xb9,
-	0x1f, 0x07, 0x68, 0x3f, 0x84, 0x1c, 0xd7, 0xb3, 0x93, 0x15, 0x32, 0xe6, 0xdf, 0x61, 0x7f, 0x9d,
-	0xc4, 0x3c, 0x26, 0x15, 0x15, 0xed, 0x74, 0x74, 0xd6, 0x0f, 0x82, 0x78, 0x13, 0xf1, 0xdb, 0xd9,
-	0x7d, 0x1c, 0x2c, 0x55, 0x4d, 0xe7, 0x67, 0x9d, 0x63, 0x91, 0xbf, 0x66, 0x8b, 0xb8, 0x94, 0xf4,
-	0x3e, 0x99, 0x50, 0x1b, 0xfa, 0xd1, 0x9c, 0x2d, 0xfc, 0x25, 0x12, 0x17, 0x0e, 0xde, 0x60, 0xc2,
-	0xc2, 0x38, 0x72, 0x8d, 0xae, 0xd1, 0xb3, 0x68, 0xea, 0x92, 0x36, 0x38, 0x23, 0xe4, 0xd7, 0x73,
-	0xd7, 0x94, 0x71, 0xe5, 0x10, 0x02, 0xf6, 0xc8, 0x5f, 0xa1, 0x6b, 0x75, 0x8d, 0x5e, 0x8d, 0x4a,
-	0x9b, 0x34, 0xc1, 0xbc, 0xbe, 0x70, 0xed, 0xae, 0xd1, 0xab, 0x53, 0xf3, 0xfa, 0x82, 0xfc, 0x02,
-	0xb5, 0x69, 0xb8, 0x42, 0xc6, 0xfd, 0xd5, 0xda, 0x75, 0xe4, 0xd7, 0x79, 0x40, 0x20, 0x5e, 0x61,
-	0x84, 0x2c, 0x64, 0x6e, 0x45, 0x7e, 0x92, 0xba, 0xe4, 0x47, 0xa8, 0x0c, 0x31, 0xbc, 0x5b, 0x70,
-	0xf7, 0xa0, 0x6b, 0xf4, 0x6c, 0xaa, 0x3d, 0x81, 0x39, 0x44, 0x7f, 0xee, 0x56, 0x65, 0xb9, 0xb4,
-	0x49, 0x17, 0x0e, 0x2f, 0xc3, 0x7b, 0x1c, 0xcc, 0xe7, 0x09, 0x32, 0xe6, 0xd6, 0x64, 0xaa, 0x18,
-	0x22, 0x2d, 0xb0, 0x5e, 0xe2, 0x93, 0x0b, 0x32, 0x23, 0x4c, 0xc1, 0x68, 0x1a, 0x2f, 0x31, 0x72,
-	0x0f, 0x65, 0x4c, 0x39, 0xe4, 0x57, 0x68, 0x8c, 0x37, 0xb3, 0xfb, 0x30, 0x48, 0x7b, 0xd5, 0x65,
-	0xb6, 0x1c, 0xf4, 0x42, 0x38, 0x9a, 0x3c, 0x45, 0xc1, 0x79, 0x1c, 0x45, 0xf9, 0xf2, 0x14, 0x71,
-	0x63, 0x37, 0x71, 0x73, 0x9b, 0xb8, 0x1e, 0xc8, 0xda, 0x31, 0x90, 0x5d, 0x18, 0xc8, 0x5b, 0x40,
-	0xfd, 0x7c, 0xb1, 0x89, 0x96, 0x14, 0xdf, 0x6e, 0x90, 0x49, 0xfa, 0x97, 0x49, 0xbc, 0x92, 0x38,
-	0x36, 0x95, 0xb6, 0x40, 0x9e, 0xc6, 0x12, 0xc2, 0xa6, 0xe6, 0x34, 0x26, 0x1d, 0xa8, 0x8e, 0x13,
-	0x7c, 0x18, 0xfa, 0x6c, 0xa1, 0x01, 0x32, 0x5f, 0x2c, 0xfc, 0x45, 0x34, 0x97, 0x29, 0x85, 0x93,
-	0xba, 0xde, 0x7b, 0x68, 0x68, 0x24, 0xb6, 0x8e, 0x23, 0x86, 0xdf, 0x0e, 0x4a, 0x74, 0x9e, 0x84,
-	0xef, 0x50, 0xca, 0xc1, 0xa6, 0xd2, 0xf6, 0x3e, 0x9a, 0xe0, 0x4c, 0xb8, 0xcf, 0x91, 0xf4, 0xc0,
-	0x19, 0x23, 0x26, 0xcc, 0x35, 0xba, 0x56, 0xef, 0xf0, 0x94, 0xf4, 0x95, 0x80, 0xfb, 0x32, 0xdb,
-	0x17, 0x29, 0xaa, 0x0a, 0xc4, 0xca, 0xc6, 0x3e, 0x0f, 0x16, 0x72, 0xa0, 0x2a, 0x55, 0x4e, 0xa6,
-	0x10, 0xab, 0xa0, 0x90, 0x5c, 0x4d, 0x76, 0x49, 0x4d, 0xa5, 0x47, 0x82, 0xad, 0x47, 0xea, 0x0c,
-	0xc1, 0x16, 0x40, 0xcf, 0x9e, 0xf6, 0x2f, 0xa8, 0x88, 0x61, 0x36, 0x4c, 0x62, 0x34, 0x4f, 0xdd,
-	0xe7, 0x23, 0xaa, 0x3c, 0xd5, 0x75, 0xde, 0x31, 0x40, 0x1e, 0x25, 0x0d, 0xa8, 0x09, 0xed, 0x60,
-	0xc0, 0x71, 0xde, 0xfa, 0x8e, 0xb4, 0xa0, 0x7e, 0x11, 0xb2, 0x20, 0x8b, 0x18, 0xde, 0x3f, 0x00,
-	0x62, 0x51, 0x05, 0xc9, 0x8b, 0x2d, 0x1a, 0x9a, 0x90, 0x58, 0x61, 0x4e, 0xc8, 0x2c, 0x12, 0xf2,
-	0x5e, 0xc1, 0xf7, 0xf9, 0x97, 0xe3, 0x38, 0x8c, 0xb8, 0xdc, 0xa7, 0x30, 0xe4, 0xf7, 0x85, 0x7d,
-	0xe6, 0x75, 0x54, 0x15, 0x64, 0xef, 0x62, 0x16, 0xde, 0x65, 0x00, 0xcd, 0xbc, 0xf0, 0x26, 0x64,
-	0x9c, 0x9c, 0x40, 0x45, 0x96, 0xa7, 0x0f, 0xf4, 0xd3, 0xf3, 0x86, 0x32, 0x4f, 0x75, 0x99, 0x77,
-	0x0b, 0x47, 0x57, 0xc8, 0xb7, 0xba, 0xfc, 0x96, 0xa9, 0xcb, 0xda, 0x33, 0x94, 0x52, 0x9c, 0x98,
-	0x89, 0xe3, 0x3a, 0x9b, 0x89, 0xe3, 0x5a, 0xab, 0xd0, 0x4a, 0x55, 0xe8, 0x2d, 0x25, 0xc0, 0x44,
-	0x1f, 0xb8, 0x33, 0x71, 0xdf, 0x58, 0x01, 0xc0, 0xf8, 0x22, 0x40, 0x1b, 0x9c, 0x73, 0x71, 0x34,
-	0x35, 0x82, 0x72, 0x84, 0x78, 0x2f, 0xe3, 0xe4, 0xd1, 0x4f, 0x94, 0x8e, 0xaa, 0x34, 0x75, 0xbd,
-	0xff, 0xa1, 0xb9, 0x85, 0x74, 0x0c, 0x15, 0x65, 0x69, 0x32, 0x3f, 0x64, 0x72, 0x28, 0xd6, 0x51,
-	0x5d, 0xe4, 0x7d, 0x30, 0xa0, 0x75, 0x85, 0x7c, 0xa0, 0x6e, 0xb5, 0xee, 0xe1, 0xc2, 0x41, 0x7a,
-	0x72, 0xd4, 0x33, 0xa7, 0x6e, 0xc6, 0xc3, 0xfc, 0x5a, 0x1e, 0xd6, 0x1e, 0x1e, 0x76, 0x99, 0xc7,
-	0xbf, 0xd0, 0x28, 0x8f, 0xf0, 0xe7, 0x16, 0x8d, 0x76, 0x0a, 0x55, 0x2c, 0xcb, 0x58, 0xbc, 0x86,
-	0xd6, 0x08, 0x1f, 0x4b, 0x0c, 0xc9, 0x1f, 0xe0, 0x48, 0x43, 0xef, 0x7c, 0xcf, 0x1e, 0x54, 0x8d,
-	0xb8, 0x80, 0xd3, 0xe9, 0x8d, 0xa4, 0xe5, 0x50, 0x61, 0x0a, 0xed, 0x8e, 0xf0, 0xb1, 0x88, 0x46,
-	0x7e, 0x2f, 0x77, 0xdc, 0x3d, 0xd2, 0xde, 0x86, 0xff, 0x41, 0x7b, 0xab, 0xe1, 0xd9, 0x13, 0x47,
-	0x79, 0x37, 0xf2, 0xae, 0xf5, 0xfd, 0xdf, 0x0f, 0xc0, 0x99, 0x26, 0x7e, 0x80, 0x3b, 0x7f, 0x81,
-	0x04, 0xec, 0xb1, 0xcf, 0xc5, 0xed, 0xb1, 0x44, 0x4c, 0xd8, 0x69, 0x0b, 0xf1, 0x02, 0x0d, 0xd9,
-	0x62, 0x56, 0x91, 0xff, 0xb3, 0x7f, 0x7f, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x61, 0x22, 0x50, 0xa0,
-	0xc0, 0x07, 0x00, 0x00,
+	// 816 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x4b, 0x8f, 0x22, 0x55,
+	0x14, 0xb6, 0x9e, 0x0d, 0xa7, 0x01, 0x99, 0x1b, 0xd4, 0x1b, 0x74, 0x41, 0x2a, 0xc6, 0x10, 0x75,
+	0x18, 0x33, 0x6e, 0xdc, 0xa8, 0x61, 0xba, 0xa5, 0xe9, 0x38, 0x61, 0xf0, 0x42, 0xdc, 0x4e, 0x8a,
+	0xe2, 0xcc, 0x50, 0xa1, 0xa9, 0xc2, 0xba, 0x97, 0xe9, 0xb4, 0x2b, 0x17, 0xae, 0xdc, 0xfb, 0xbb,
+	0xfc, 0x4b, 0xe6, 0x3e, 0xea, 0x45, 0x83, 0x71, 0x33, 0xbb, 0xf3, 0xba, 0xe7, 0xfb, 0xce, 0xa3,
+	0x4e, 0x41, 0xef, 0x5d, 0x2c, 0x70, 0xbf, 0x7a, 0xb6, 0x43, 0xce, 0xc3, 0xb7, 0x38, 0xda, 0x67,
+	0xa9, 0x48, 0x89, 0xaf, 0xad, 0xfd, 0xbe, 0xf1, 0x86, 0x51, 0x94, 0x1e, 0x12, 0xf1, 0x7a, 0x75,
+	0x97, 0x46, 0x5b, 0x1d, 0xd3, 0xff, 0xd4, 0xf8, 0x78, 0x12, 0xee, 0xf9, 0x26, 0xad, 0x39, 0x83,
+	0x7f, 0x6c, 0x68, 0x4e, 0xc3, 0x64, 0xcd, 0x37, 0xe1, 0x16, 0x09, 0x85, 0x8b, 0x5f, 0x31, 0xe3,
+	0x71, 0x9a, 0x50, 0x6b, 0x60, 0x0d, 0x1d, 0x96, 0xab, 0xa4, 0x07, 0xde, 0x0c, 0xc5, 0xed, 0x9a,
+	0xda, 0xca, 0xae, 0x15, 0x42, 0xc0, 0x9d, 0x85, 0x3b, 0xa4, 0xce, 0xc0, 0x1a, 0x36, 0x99, 0x92,
+	0x49, 0x07, 0xec, 0xdb, 0x6b, 0xea, 0x0e, 0xac, 0x61, 0x8b, 0xd9, 0xb7, 0xd7, 0xe4, 0x33, 0x68,
+	0x2e, 0xe3, 0x1d, 0x72, 0x11, 0xee, 0xf6, 0xd4, 0x53, 0xaf, 0x4b, 0x83, 0x44, 0xbc, 0xc1, 0x04,
+	0x79, 0xcc, 0xa9, 0xaf, 0x9e, 0xe4, 0x2a, 0xf9, 0x18, 0xfc, 0x29, 0xc6, 0x6f, 0x37, 0x82, 0x5e,
+	0x0c, 0xac, 0xa1, 0xcb, 0x8c, 0x26, 0x31, 0xa7, 0x18, 0xae, 0x69, 0x43, 0x85, 0x2b, 0x99, 0x0c,
+	0xe0, 0x72, 0x12, 0xdf, 0xe1, 0x78, 0xbd, 0xce, 0x90, 0x73, 0xda, 0x54, 0xae, 0xaa, 0x89, 0x74,
+	0xc1, 0xf9, 0x19, 0x1f, 0x28, 0x28, 0x8f, 0x14, 0x65, 0x45, 0xcb, 0x74, 0x8b, 0x09, 0xbd, 0x54,
+	0x36, 0xad, 0x90, 0xcf, 0xa1, 0x3d, 0x3f, 0xac, 0xee, 0xe2, 0x28, 0xcf, 0xd5, 0x52, 0xde, 0xba,
+	0x91, 0xf4, 0xa1, 0x31, 0xc1, 0x50, 0x1c, 0x32, 0xe4, 0xb4, 0xad, 0xd8, 0x15, 0x7a, 0x10, 0xc3,
+	0x93, 0xc5, 0x43, 0x12, 0x5d, 0xa5, 0x49, 0x52, 0x36, 0x56, 0x37, 0xc5, 0x3a, 0xdd, 0x14, 0xfb,
+	0xb8, 0x29, 0x86, 0xac, 0x73, 0x82, 0xac, 0x5b, 0x21, 0x1b, 0xfc, 0x61, 0x41, 0xeb, 0x6a, 0x73,
+	0x48, 0xb6, 0x0c, 0x7f, 0x3b, 0x20, 0x57, 0xbd, 0x99, 0x64, 0xe9, 0x4e, 0x01, 0xb9, 0x4c, 0xc9,
+	0x12, 0x7a, 0x99, 0x2a, 0x0c, 0x97, 0xd9, 0xcb, 0x54, 0x72, 0x9f, 0x67, 0xf8, 0x6e, 0x1a, 0xf2,
+	0x8d, 0x41, 0x28, 0x74, 0x39, 0x8d, 0x9f, 0x92, 0xb5, 0x72, 0x69, 0xa0, 0x5c, 0x95, 0xd3, 0x78,
+	0xf5, 0xe6, 0x0d, 0x47, 0xa1, 0x46, 0xe8, 0x32, 0xa3, 0x05, 0x7f, 0x5b, 0xd0, 0x36, 0x14, 0xf8,
+	0x3e, 0x4d, 0x38, 0xbe, 0x47, 0x0e, 0x04, 0xdc, 0x45, 0xfc, 0x3b, 0x1a, 0x06, 0x4a, 0xae, 0xf0,
+	0xf2, 0x6b, 0xbc, 0xfe, 0xb2, 0xc1, 0x5b, 0x88, 0x50, 0x20, 0x19, 0x82, 0x37, 0x47, 0xcc, 0x38,
+	0xb5, 0x06, 0xce, 0xf0, 0xf2, 0x39, 0x19, 0xe9, 0xcf, 0x61, 0xa4, 0xbc, 0x23, 0xe9, 0x62, 0x3a,
+	0x40, 0x36, 0x79, 0x1e, 0x8a, 0x68, 0xa3, 0x88, 0x36, 0x98, 0x56, 0x8a, 0x7d, 0x73, 0x2a, 0xfb,
+	0x56, 0xee, 0xa6, 0x5b, 0xdb, 0xcd, 0xda, 0x58, 0xe1, 0x68, 0xac, 0xfd, 0x29, 0xb8, 0x12, 0xe8,
+	0xd1, 0x32, 0x7c, 0x03, 0xbe, 0x24, 0x73, 0xe0, 0x0a, 0xa3, 0xf3, 0x9c, 0x3e, 0xa6, 0xa8, 0xfd,
+	0xcc, 0xc4, 0x05, 0x4f, 0x01, 0x4a, 0x2b, 0x69, 0x43, 0x53, 0x6e, 0x1b, 0x46, 0x02, 0xd7, 0xdd,
+	0x0f, 0x48, 0x17, 0x5a, 0xd7, 0x31, 0x8f, 0x0a, 0x8b, 0x15, 0x7c, 0x07, 0x20, 0x1b, 0x58, 0xf9,
+	0x80, 0x64, 0x77, 0x2d, 0x53, 0x90, 0x19, 0xaf, 0x29, 0xc8, 0xae, 0x16, 0x14, 0xbc, 0x82, 0x0f,
+	0xcb, 0x97, 0xf3, 0x34, 0x4e, 0x84, 0xea, 0xa7, 0x14, 0xd4, 0xfb, 0x4a, 0x3f, 0xcb, 0x38, 0xa6,
+	0x03, 0x8a, 0x79, 0xd9, 0xe5, 0xbc, 0x82, 0x31, 0x74, 0xca, 0xc0, 0x97, 0x31, 0x17, 0xe4, 0x19,
+	0xf8, 0x2a, 0x3c, 0x1f, 0xd0, 0x27, 0x8f, 0x13, 0x2a, 0x3f, 0x33, 0x61, 0xc1, 0x6b, 0x78, 0x72,
+	0x83, 0xe2, 0x28, 0xcb, 0x17, 0xc5, 0xd6, 0x39, 0x67, 0x48, 0xe9, 0x4d, 0x94, 0x9c, 0x04, 0xee,
+	0x0b, 0x4e, 0x02, 0xf7, 0x66, 0x3b, 0x9d, 0x7c, 0x3b, 0x83, 0xad, 0x02, 0x58, 0x98, 0x73, 0xf9,
+	0x42, 0x5e, 0x4b, 0x5e, 0x01, 0xb0, 0xfe, 0x13, 0xa0, 0x07, 0xde, 0x95, 0x3c, 0xc1, 0x06, 0x41,
+	0x2b, 0x72, 0xa9, 0x27, 0x69, 0x76, 0x1f, 0x66, 0x7a, 0x8f, 0x1a, 0x2c, 0x57, 0x83, 0x1f, 0xa1,
+	0x73, 0x84, 0xf4, 0x14, 0x7c, 0x2d, 0x99, 0x62, 0x3e, 0x2a, 0xd6, 0xa1, 0x1a, 0xc7, 0x4c, 0x50,
+	0xf0, 0xa7, 0x05, 0xdd, 0x1b, 0x14, 0x63, 0x7d, 0xf9, 0x4d, 0x0e, 0x0a, 0x17, 0xf9, 0x01, 0xd3,
+	0x63, 0xce, 0xd5, 0xa2, 0x0e, 0xfb, 0xff, 0xd6, 0xe1, 0x9c, 0xa9, 0xc3, 0xad, 0xd7, 0xf1, 0x3d,
+	0xb4, 0xeb, 0x14, 0xbe, 0x3e, 0x2a, 0xa3, 0x97, 0x43, 0x55, 0xc3, 0x8a, 0x2a, 0x7e, 0x81, 0xee,
+	0x0c, 0xef, 0x6b, 0x15, 0x92, 0xaf, 0xc0, 0x53, 0x82, 0xe9, 0xf9, 0x99, 0x3e, 0xe8, 0x18, 0x79,
+	0x33, 0x97, 0xcb, 0x97, 0xaa, 0x2c, 0x8f, 0x49, 0x51, 0xee, 0xee, 0x0c, 0xef, 0xab, 0x68, 0xe4,
+	0xcb, 0x7a, 0xc6, 0xd3, 0x94, 0xce, 0x26, 0xfc, 0x01, 0x7a, 0x47, 0x09, 0x5f, 0x3c, 0x08, 0x54,
+	0x77, 0xa3, 0xcc, 0xda, 0x3a, 0xff, 0x7e, 0x0c, 0xde, 0x32, 0x0b, 0x23, 0x3c, 0xf9, 0x05, 0x12,
+	0x70, 0xe7, 0xa1, 0x90, 0xb7, 0xc7, 0x91, 0x36, 0x29, 0xe7, 0x29, 0xe4, 0x04, 0xda, 0x2a, 0xc5,
+	0xca, 0x57, 0x7f, 0xed, 0x6f, 0xff, 0x1d, 0x00, 0xa2, 0x87, 0x02, 0xe6, 0x0e, 0x08, 0x00, 0x00,
 }
@@ -0,0 +1,53 @@
+// Package bls verifies the BLS12-381 signatures of the ethereum beacon chain, in the proof of possession scheme
+// of the IETF BLS signature draft, with the public keys in G1 and the signatures in G2.
+package bls
+
+import (
+	kbls "github.com/kilic/bls12-381"
+)
+
+const (
+	PublicKeySize = 48
+	SignatureSize = 96
+)
+
+// dst is the domain separation tag of hashing a message to G2 in the proof of possession scheme
+var dst = []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_")
+
+// FastAggregateVerify reports whether the signature is the aggregate signature of the message by all the public keys.
+// The public keys and the signature are compressed, the points must be in the prime order subgroups and the public
+// keys must not be the identity.
+func FastAggregateVerify(publicKeys [][]byte, message, signature []byte) bool {
+	if len(publicKeys) == 0 || len(signature) != SignatureSize {
+		return false
+	}
+
+	g1 := kbls.NewG1()
+	aggregate := g1.Zero()
+	for _, publicKey := range publicKeys {
+		if len(publicKey) != PublicKeySize {
+			return false
+		}
+		p, err := g1.FromCompressed(publicKey)
+		if err != nil || g1.IsZero(p) {
+			return false
+		}
+		g1.Add(aggregate, aggregate, p)
+	}
+
+	g2 := kbls.NewG2()
+	sig, err := g2.FromCompressed(signature)
+	if err != nil || g2.IsZero(sig) {
+		return false
+	}
+	h, err := g2.HashToCurve(message, dst)
+	if err != nil {
+		return false
+	}
+
+	// e(pk, H(m)) == e(G1, sig)
+	engine := kbls.NewEngine()
+	engine.AddPair(aggregate, h)
+	engine.AddPairInv(&kbls.G1One, sig)
+	return engine.Check()
+}
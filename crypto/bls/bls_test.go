@@ -0,0 +1,68 @@
+package bls
+
+import (
+	"encoding/hex"
+	"testing"
+
+	kbls "github.com/kilic/bls12-381"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// the sign case of the ethereum consensus spec tests, the message is 32 zero bytes
+func TestFastAggregateVerify_SpecCase(t *testing.T) {
+	publicKey := mustDecode(t, "a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20fd6e10c1b77654d067c0618f6e5a7f79a")
+	signature := mustDecode(t, "b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55")
+	message := make([]byte, 32)
+
+	if !FastAggregateVerify([][]byte{publicKey}, message, signature) {
+		t.Fatal("valid signature rejected")
+	}
+	message[0] = 1
+	if FastAggregateVerify([][]byte{publicKey}, message, signature) {
+		t.Fatal("signature of another message accepted")
+	}
+}
+
+func TestFastAggregateVerify(t *testing.T) {
+	message := []byte("message")
+	g1, g2 := kbls.NewG1(), kbls.NewG2()
+	h, err := g2.HashToCurve(message, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var publicKeys [][]byte
+	aggregate := g2.Zero()
+	for i := 1; i <= 4; i++ {
+		sk := kbls.NewFr().FromBytes([]byte{byte(i)})
+		publicKeys = append(publicKeys, g1.ToCompressed(g1.MulScalar(g1.New(), &kbls.G1One, sk)))
+		g2.Add(aggregate, aggregate, g2.MulScalar(g2.New(), h, sk))
+	}
+	signature := g2.ToCompressed(aggregate)
+
+	if !FastAggregateVerify(publicKeys, message, signature) {
+		t.Fatal("valid aggregate signature rejected")
+	}
+	if FastAggregateVerify(publicKeys[:3], message, signature) {
+		t.Fatal("aggregate signature accepted without a signer")
+	}
+	if FastAggregateVerify(nil, message, signature) {
+		t.Fatal("signature accepted without public keys")
+	}
+	if FastAggregateVerify(append(publicKeys, g1.ToCompressed(g1.Zero())), message, signature) {
+		t.Fatal("identity public key accepted")
+	}
+	if FastAggregateVerify(publicKeys, message, g2.ToCompressed(g2.Zero())) {
+		t.Fatal("identity signature accepted")
+	}
+	if FastAggregateVerify(publicKeys, message, signature[:SignatureSize-1]) {
+		t.Fatal("short signature accepted")
+	}
+}
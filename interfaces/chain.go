@@ -99,17 +99,45 @@ type ChunkReader interface {
 	Verify()
 }
 
+type ChunkWriter interface {
+	io.WriteCloser
+	// Suspend the writing and keep the data written, the chunk can be resumed by SyncCache.ResumeWriter, even after restart
+	Suspend() error
+}
+
 type SyncCache interface {
-	NewWriter(segment Segment, size int64) (io.WriteCloser, error)
+	NewWriter(segment Segment, size int64) (ChunkWriter, error)
+	// ResumeWriter continues the suspended chunk downloaded from source at offset, offset 0 means writing from scratch
+	ResumeWriter(segment Segment, size int64, offset int64, source string) (ChunkWriter, error)
+	// Progress returns the bytes of the suspended chunk downloaded from source, 0 if the chunk can't be resumed
+	Progress(segment Segment, source string) int64
 	Chunks() SegmentList
 	NewReader(segment Segment) (ChunkReader, error)
 	Delete(seg Segment) error
 	Close() error
 }
 
+// DBStatus is the status of a cache or a db, the leveldb fields are only set for the leveldb of a store
 type DBStatus struct {
 	Name   string
 	Count  uint64
 	Size   uint64
 	Status string
+
+	Levels             []LevelStatus `json:",omitempty"`
+	Compactions        int64         `json:",omitempty"`
+	BlockCacheHitRatio float64       `json:",omitempty"`
+	FilterUsefulRatio  float64       `json:",omitempty"` // the ratio of the lookups ruled out by the bloom filters
+	OpenedFiles        int           `json:",omitempty"`
+}
+
+// LevelStatus is the status of a level of leveldb, the compactions are counted since the db is opened
+type LevelStatus struct {
+	Level             int
+	Tables            int
+	Size              int64
+	Compactions       int64
+	CompactionRead    int64
+	CompactionWrite   int64
+	CompactionSeconds float64
 }
@@ -2,6 +2,8 @@ package core
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/golang/protobuf/proto"
@@ -389,8 +391,14 @@ func (ab *AccountBlock) DeProto(pb *vitepb.AccountBlock) error {
 	ab.Nonce = pb.Nonce
 
 	// 17
+	if len(pb.SendBlockList) > 0 && ab.IsSendBlock() {
+		return errors.New("send block has send block list")
+	}
 	ab.SendBlockList = make([]*AccountBlock, 0, len(pb.SendBlockList))
 	for _, pbSendBlock := range pb.SendBlockList {
+		if !IsSendBlock(byte(pbSendBlock.BlockType)) {
+			return fmt.Errorf("block type %d in send block list is not a send block type", pbSendBlock.BlockType)
+		}
 		sendBlock := &AccountBlock{}
 		if err := sendBlock.DeProto(pbSendBlock); err != nil {
 			return err
@@ -411,8 +419,7 @@ func (ab *AccountBlock) Deserialize(buf []byte) error {
 	if err := proto.Unmarshal(buf, pb); err != nil {
 		return err
 	}
-	ab.DeProto(pb)
-	return nil
+	return ab.DeProto(pb)
 }
 
 func (ab *AccountBlock) IsSendBlock() bool {
@@ -236,3 +236,22 @@ func BenchmarkAccountBlock_VerifySignature(b *testing.B) {
 		}
 	}
 }
+
+func TestAccountBlock_DeserializeMalformed(t *testing.T) {
+	// a send block without the to address, it panicked in ComputeHash
+	if err := (&AccountBlock{}).Deserialize([]byte("\b\x01")); err == nil {
+		t.Fatal("the send block without the address should fail")
+	}
+
+	block := createBlock()
+	block.BlockType = BlockTypeReceive
+	block.SendBlockList = []*AccountBlock{createBlock()}
+	block.SendBlockList[0].BlockType = BlockTypeReceive
+	buf, err := block.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := (&AccountBlock{}).Deserialize(buf); err == nil {
+		t.Fatal("the receive block in the send block list should fail")
+	}
+}
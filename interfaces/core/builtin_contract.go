@@ -0,0 +1,115 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+)
+
+// forkedBuiltinContract is a built-in contract added after the genesis by a fork or a plugin,
+// it's in use from the snapshot height which active returns true for.
+type forkedBuiltinContract struct {
+	addr types.Address
+	// the contract receives with its own quota, like the dex fund contract
+	useQuota    bool
+	sendConfirm bool
+	active      func(sbHeight uint64) bool
+}
+
+var forkedBuiltinContracts = []*forkedBuiltinContract{
+	{addr: types.AddressNameService, active: upgrade.IsVersionXUpgrade},
+	{addr: types.AddressBatchTransfer, active: upgrade.IsVersionXUpgrade},
+	{addr: types.AddressHeaderRelay, active: upgrade.IsVersionXUpgrade},
+}
+
+// the built-in contracts added by plugins, in the order they are added
+var pluginBuiltinContracts []*forkedBuiltinContract
+
+// AddPluginBuiltinContract adds a built-in contract of a plugin in use from the activation height, or replaces the one
+// added before at the address. It is not safe for concurrent use and is supposed to be called in init, before the node
+// started.
+func AddPluginBuiltinContract(addr types.Address, useQuota, sendConfirm bool, activationHeight uint64) error {
+	if types.IsBuiltinContractAddrInUse(addr) {
+		return fmt.Errorf("built-in contract %s already exists", addr)
+	}
+	for _, c := range forkedBuiltinContracts {
+		if c.addr == addr {
+			return fmt.Errorf("built-in contract %s already exists", addr)
+		}
+	}
+	c := &forkedBuiltinContract{
+		addr:        addr,
+		useQuota:    useQuota,
+		sendConfirm: sendConfirm,
+		active: func(sbHeight uint64) bool {
+			return sbHeight >= activationHeight
+		},
+	}
+	for i, p := range pluginBuiltinContracts {
+		if p.addr == addr {
+			pluginBuiltinContracts[i] = c
+			return nil
+		}
+	}
+	pluginBuiltinContracts = append(pluginBuiltinContracts, c)
+	return nil
+}
+
+func getForkedBuiltinContract(addr types.Address) *forkedBuiltinContract {
+	for _, list := range [][]*forkedBuiltinContract{forkedBuiltinContracts, pluginBuiltinContracts} {
+		for _, c := range list {
+			if c.addr == addr {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// IsForkedBuiltinContractAddr returns whether addr is a built-in contract added after the genesis, at any height
+func IsForkedBuiltinContractAddr(addr types.Address) bool {
+	return getForkedBuiltinContract(addr) != nil
+}
+
+// IsBuiltinContractAddrInUse returns whether addr is a built-in contract in use at the snapshot height
+func IsBuiltinContractAddrInUse(addr types.Address, sbHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUse(addr) {
+		return true
+	}
+	c := getForkedBuiltinContract(addr)
+	return c != nil && c.active(sbHeight)
+}
+
+// IsBuiltinContractAddrInUseWithoutQuota returns whether addr is a built-in contract in use at the snapshot height,
+// which receives without quota
+func IsBuiltinContractAddrInUseWithoutQuota(addr types.Address, sbHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUseWithoutQuota(addr) {
+		return true
+	}
+	c := getForkedBuiltinContract(addr)
+	return c != nil && !c.useQuota && c.active(sbHeight)
+}
+
+// IsBuiltinContractAddrInUseWithSendConfirm returns whether addr is a built-in contract in use at the snapshot height,
+// whose send blocks must be confirmed before received
+func IsBuiltinContractAddrInUseWithSendConfirm(addr types.Address, sbHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUseWithSendConfirm(addr) {
+		return true
+	}
+	c := getForkedBuiltinContract(addr)
+	return c != nil && c.sendConfirm && c.active(sbHeight)
+}
+
+// GetBuiltinContracts returns the built-in contracts in use at the snapshot height
+func GetBuiltinContracts(sbHeight uint64) []types.Address {
+	contracts := append([]types.Address(nil), types.BuiltinContracts...)
+	for _, list := range [][]*forkedBuiltinContract{forkedBuiltinContracts, pluginBuiltinContracts} {
+		for _, c := range list {
+			if c.active(sbHeight) {
+				contracts = append(contracts, c.addr)
+			}
+		}
+	}
+	return contracts
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+)
+
+func TestBuiltinContractsByHeight(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	box := upgrade.NewEmptyUpgradeBox()
+	for version := uint32(1); version <= 10; version++ {
+		box.AddPoint(version, 1)
+	}
+	upgrade.InitUpgradeBox(box.AddPoint(11, 100))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	forked := []types.Address{types.AddressNameService, types.AddressBatchTransfer, types.AddressHeaderRelay}
+	for _, addr := range forked {
+		assert.False(t, IsBuiltinContractAddrInUse(addr, 99))
+		assert.False(t, IsBuiltinContractAddrInUseWithoutQuota(addr, 99))
+		assert.Nil(t, GetBuiltinContractMeta(addr, 99))
+
+		assert.True(t, IsBuiltinContractAddrInUse(addr, 100))
+		assert.True(t, IsBuiltinContractAddrInUseWithoutQuota(addr, 100))
+		assert.False(t, IsBuiltinContractAddrInUseWithSendConfirm(addr, 100))
+		if meta := GetBuiltinContractMeta(addr, 100); assert.NotNil(t, meta) {
+			assert.Equal(t, types.DELEGATE_GID, meta.Gid)
+			assert.Equal(t, uint8(0), meta.SendConfirmedTimes)
+		}
+	}
+	assert.Equal(t, types.BuiltinContracts, GetBuiltinContracts(99))
+	assert.Equal(t, append(append([]types.Address(nil), types.BuiltinContracts...), forked...), GetBuiltinContracts(100))
+
+	// the contracts in use since the genesis
+	assert.True(t, IsBuiltinContractAddrInUse(types.AddressQuota, 1))
+	assert.True(t, IsBuiltinContractAddrInUseWithSendConfirm(types.AddressQuota, 1))
+	assert.False(t, IsBuiltinContractAddrInUseWithoutQuota(types.AddressDexFund, 1))
+
+	assert.Error(t, AddPluginBuiltinContract(types.AddressQuota, false, false, 1))
+	assert.Error(t, AddPluginBuiltinContract(types.AddressNameService, false, false, 1))
+}
+
+func TestGetContractMetaAt(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	box := upgrade.NewEmptyUpgradeBox()
+	for version := uint32(1); version <= 10; version++ {
+		box.AddPoint(version, 1)
+	}
+	upgrade.InitUpgradeBox(box.AddPoint(11, 100))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	// the chain reads the meta by its latest snapshot block, which is after the fork
+	contract := types.AddressNameService
+	contract[0] = 1
+	latest := func(addr types.Address) (*ContractMeta, error) {
+		if meta := GetBuiltinContractMeta(addr, 200); meta != nil {
+			return meta, nil
+		}
+		if addr == contract {
+			return &ContractMeta{Gid: types.DELEGATE_GID, SendConfirmedTimes: 2}, nil
+		}
+		return nil, nil
+	}
+
+	meta, err := GetContractMetaAt(types.AddressNameService, 99, latest)
+	assert.NoError(t, err)
+	assert.Nil(t, meta)
+	meta, err = GetContractMetaAt(types.AddressNameService, 100, latest)
+	assert.NoError(t, err)
+	assert.NotNil(t, meta)
+
+	meta, err = GetContractMetaAt(contract, 99, latest)
+	assert.NoError(t, err)
+	if assert.NotNil(t, meta) {
+		assert.Equal(t, uint8(2), meta.SendConfirmedTimes)
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// Checkpoints are the snapshot blocks trusted by the node, sorted by height from low to high.
+// The chains contradicting a checkpoint are rejected, and the blocks beneath the latest checkpoint
+// are trusted by their hash, the signatures are not verified again.
+type Checkpoints []*HashHeight
+
+// ParseCheckpoints parses the checkpoints like "hash/height"
+func ParseCheckpoints(list []string) (Checkpoints, error) {
+	cps := make(Checkpoints, 0, len(list))
+	heights := make(map[uint64]struct{}, len(list))
+	for _, str := range list {
+		strs := strings.Split(str, "/")
+		if len(strs) != 2 {
+			return nil, fmt.Errorf("invalid checkpoint %q, should be hash/height", str)
+		}
+		hash, err := types.HexToHash(strs[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid checkpoint %q: %v", str, err)
+		}
+		height, err := strconv.ParseUint(strs[1], 10, 64)
+		if err != nil || height == 0 {
+			return nil, fmt.Errorf("invalid checkpoint %q: wrong height", str)
+		}
+		if _, ok := heights[height]; ok {
+			return nil, fmt.Errorf("duplicate checkpoint at height %d", height)
+		}
+		heights[height] = struct{}{}
+
+		cps = append(cps, &HashHeight{
+			Height: height,
+			Hash:   hash,
+		})
+	}
+
+	sort.Slice(cps, func(i, j int) bool {
+		return cps[i].Height < cps[j].Height
+	})
+
+	return cps, nil
+}
+
+// Latest returns the highest checkpoint, nil if there is no checkpoint
+func (cps Checkpoints) Latest() *HashHeight {
+	if len(cps) == 0 {
+		return nil
+	}
+	return cps[len(cps)-1]
+}
+
+// Get returns the checkpoint at height, nil if there is no checkpoint at height
+func (cps Checkpoints) Get(height uint64) *HashHeight {
+	i := sort.Search(len(cps), func(i int) bool {
+		return cps[i].Height >= height
+	})
+	if i < len(cps) && cps[i].Height == height {
+		return cps[i]
+	}
+	return nil
+}
+
+// Check returns an error if the block at height is not the checkpoint
+func (cps Checkpoints) Check(height uint64, hash types.Hash) error {
+	if cp := cps.Get(height); cp != nil && cp.Hash != hash {
+		return fmt.Errorf("snapshot block %s/%d contradicts checkpoint %s/%d", hash, height, cp.Hash, cp.Height)
+	}
+	return nil
+}
+
+// Beneath returns true if height is not higher than the latest checkpoint
+func (cps Checkpoints) Beneath(height uint64) bool {
+	latest := cps.Latest()
+	return latest != nil && height <= latest.Height
+}
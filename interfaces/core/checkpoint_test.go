@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+func TestParseCheckpoints(t *testing.T) {
+	hash1, hash2 := types.DataHash([]byte{1}), types.DataHash([]byte{2})
+
+	cps, err := ParseCheckpoints([]string{
+		hash2.String() + "/200",
+		hash1.String() + "/100",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cps) != 2 || cps[0].Height != 100 || cps[1].Hash != hash2 {
+		t.Fatalf("checkpoints should be sorted from low to high: %v %v", cps[0], cps[1])
+	}
+	if latest := cps.Latest(); latest.Height != 200 {
+		t.Fatalf("latest checkpoint should be 200, got %d", latest.Height)
+	}
+
+	if err = cps.Check(100, hash1); err != nil {
+		t.Error(err)
+	}
+	if err = cps.Check(100, hash2); err == nil {
+		t.Error("block contradicting checkpoint should be rejected")
+	}
+	if err = cps.Check(150, hash2); err != nil {
+		t.Error(err)
+	}
+
+	if !cps.Beneath(200) || cps.Beneath(201) {
+		t.Error("wrong beneath")
+	}
+
+	for _, list := range [][]string{
+		{hash1.String()},
+		{hash1.String() + "/0"},
+		{"xx/100"},
+		{hash1.String() + "/100", hash2.String() + "/100"},
+	} {
+		if _, err = ParseCheckpoints(list); err == nil {
+			t.Errorf("%v should be invalid", list)
+		}
+	}
+
+	var empty Checkpoints
+	if empty.Latest() != nil || empty.Beneath(1) || empty.Check(1, hash1) != nil {
+		t.Error("empty checkpoints should accept any block")
+	}
+}
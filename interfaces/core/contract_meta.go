@@ -55,14 +55,30 @@ func (cm *ContractMeta) Deserialize(buf []byte) error {
 	return nil
 }
 
-func GetBuiltinContractMeta(addr types.Address) *ContractMeta {
-	if types.IsBuiltinContractAddrInUseWithSendConfirm(addr) {
+// GetBuiltinContractMeta returns the meta of the built-in contract in use at the snapshot height, nil if addr isn't one
+func GetBuiltinContractMeta(addr types.Address, sbHeight uint64) *ContractMeta {
+	if IsBuiltinContractAddrInUseWithSendConfirm(addr, sbHeight) {
 		return &ContractMeta{types.DELEGATE_GID, 1, types.Hash{}, getBuiltinContractQuotaRatio(addr), 0}
-	} else if types.IsBuiltinContractAddrInUse(addr) {
+	} else if IsBuiltinContractAddrInUse(addr, sbHeight) {
 		return &ContractMeta{types.DELEGATE_GID, 0, types.Hash{}, getBuiltinContractQuotaRatio(addr), 0}
 	}
 	return nil
 }
+
+// GetContractMetaAt returns the meta of the contract at the snapshot height, the built-in contracts are looked up by
+// the height and the other contracts are read by get. The meta used to execute or verify a block must be looked up
+// by the snapshot block it refers to, not by the latest one of the node.
+func GetContractMetaAt(addr types.Address, sbHeight uint64, get func(addr types.Address) (*ContractMeta, error)) (*ContractMeta, error) {
+	if meta := GetBuiltinContractMeta(addr, sbHeight); meta != nil {
+		return meta, nil
+	}
+	if IsForkedBuiltinContractAddr(addr) {
+		// not in use at the snapshot height
+		return nil, nil
+	}
+	return get(addr)
+}
+
 func getBuiltinContractQuotaRatio(addr types.Address) uint8 {
 	// TODO use special quota ratio for dex contracts
 	return 10
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/vitelabs/go-vite/v2/common/helper"
+)
+
+var (
+	// revertErrorSelector is the selector of Error(string)
+	revertErrorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+	// revertPanicSelector is the selector of Panic(uint256)
+	revertPanicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+)
+
+// ExecutionError is the reason why a receive block of a contract failed. It is kept by the node which executes
+// the block and is not a part of the block, only the failed status is.
+type ExecutionError struct {
+	Error string
+	// RevertData is the data of the REVERT instruction
+	RevertData []byte
+}
+
+func (e *ExecutionError) Serialize() []byte {
+	buf := make([]byte, 2, 2+len(e.Error)+len(e.RevertData))
+	binary.BigEndian.PutUint16(buf, uint16(len(e.Error)))
+	buf = append(buf, e.Error...)
+	return append(buf, e.RevertData...)
+}
+
+func (e *ExecutionError) Deserialize(buf []byte) error {
+	if len(buf) < 2 {
+		return errors.New("execution error is too short")
+	}
+	size := int(binary.BigEndian.Uint16(buf))
+	if len(buf) < 2+size {
+		return errors.New("execution error is too short")
+	}
+	e.Error = string(buf[2 : 2+size])
+	e.RevertData = nil
+	if len(buf) > 2+size {
+		e.RevertData = append([]byte(nil), buf[2+size:]...)
+	}
+	return nil
+}
+
+// Reason decodes the revert data of Error(string), empty if the revert data is not of Error(string)
+func (e *ExecutionError) Reason() string {
+	data := e.RevertData
+	if len(data) < 4+64 || !bytes.Equal(data[:4], revertErrorSelector) {
+		return ""
+	}
+	data = data[4:]
+	offset, overflow := helper.BigUint64(new(big.Int).SetBytes(data[:32]))
+	if overflow || offset+32 > uint64(len(data)) {
+		return ""
+	}
+	size, overflow := helper.BigUint64(new(big.Int).SetBytes(data[offset : offset+32]))
+	if overflow || offset+32+size > uint64(len(data)) {
+		return ""
+	}
+	reason := data[offset+32 : offset+32+size]
+	if !utf8.Valid(reason) {
+		return ""
+	}
+	return string(reason)
+}
+
+// PanicCode decodes the revert data of Panic(uint256), nil if the revert data is not of Panic(uint256)
+func (e *ExecutionError) PanicCode() *big.Int {
+	if len(e.RevertData) != 4+32 || !bytes.Equal(e.RevertData[:4], revertPanicSelector) {
+		return nil
+	}
+	return new(big.Int).SetBytes(e.RevertData[4:])
+}
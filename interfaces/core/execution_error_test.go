@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestExecutionError_Serialize(t *testing.T) {
+	// Error("insufficient balance")
+	revertData, _ := hex.DecodeString("08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000014" +
+		"696e73756666696369656e742062616c616e6365000000000000000000000000")
+	e := &ExecutionError{Error: "execution reverted", RevertData: revertData}
+
+	e2 := &ExecutionError{}
+	if err := e2.Deserialize(e.Serialize()); err != nil {
+		t.Fatal(err)
+	}
+	if e2.Error != e.Error || !bytes.Equal(e2.RevertData, e.RevertData) {
+		t.Fatalf("deserialized %+v, expected %+v", e2, e)
+	}
+	if reason := e2.Reason(); reason != "insufficient balance" {
+		t.Fatalf("reason is %q", reason)
+	}
+	if e2.PanicCode() != nil {
+		t.Fatal("panic code should be nil")
+	}
+
+	// Panic(0x11)
+	panicData, _ := hex.DecodeString("4e487b71" +
+		"0000000000000000000000000000000000000000000000000000000000000011")
+	e3 := &ExecutionError{Error: "execution reverted", RevertData: panicData}
+	if code := e3.PanicCode(); code == nil || code.Uint64() != 0x11 {
+		t.Fatalf("panic code is %v", code)
+	}
+	if e3.Reason() != "" {
+		t.Fatal("reason should be empty")
+	}
+
+	e4 := &ExecutionError{}
+	if err := e4.Deserialize((&ExecutionError{Error: "out of quota"}).Serialize()); err != nil {
+		t.Fatal(err)
+	}
+	if e4.Error != "out of quota" || e4.RevertData != nil {
+		t.Fatalf("deserialized %+v", e4)
+	}
+	if err := e4.Deserialize([]byte{0, 5, 'a'}); err == nil {
+		t.Fatal("expected error of a short buffer")
+	}
+}
@@ -0,0 +1,30 @@
+// +build gofuzz
+
+package core
+
+// FuzzAccountBlock is the go-fuzz entry of AccountBlock.Deserialize, the decoded block should be hashed and
+// serialized again without panic.
+func FuzzAccountBlock(data []byte) int {
+	ab := &AccountBlock{}
+	if err := ab.Deserialize(data); err != nil {
+		return 0
+	}
+	ab.ComputeHash()
+	if _, err := ab.Serialize(); err != nil {
+		panic(err)
+	}
+	return 1
+}
+
+// FuzzSnapshotBlock is the go-fuzz entry of SnapshotBlock.Deserialize
+func FuzzSnapshotBlock(data []byte) int {
+	sb := &SnapshotBlock{}
+	if err := sb.Deserialize(data); err != nil {
+		return 0
+	}
+	sb.ComputeHash()
+	if _, err := sb.Serialize(); err != nil {
+		panic(err)
+	}
+	return 1
+}
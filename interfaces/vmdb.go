@@ -35,6 +35,11 @@ type VmDb interface {
 
 	Reset()
 
+	// SetExecutionError keeps the reason why the receive block failed, it is set after Reset
+	SetExecutionError(err *core.ExecutionError)
+
+	GetUnsavedExecutionError() *core.ExecutionError
+
 	// Release memory used in runtime.
 	Finish()
 
@@ -81,6 +86,9 @@ type VmDb interface {
 
 	GetContractMetaInSnapshot(contractAddress types.Address, snapshotBlock *core.SnapshotBlock) (meta *core.ContractMeta, err error)
 
+	// GetContractMetaByAddress returns the meta of the contract at the address, the contracts not confirmed are included
+	GetContractMetaByAddress(contractAddress types.Address) (*core.ContractMeta, error)
+
 	SetContractCode(code []byte)
 
 	GetContractCode() ([]byte, error)
@@ -3,6 +3,7 @@ package interfaces
 import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
 )
 
 // SignFunc is the function type defining the callback when a block requires a
@@ -17,3 +18,30 @@ type Account interface {
 	Sign(msg []byte) (signData []byte, pub ed25519.PublicKey, err error)
 	Verify(pub ed25519.PublicKey, message, signdata []byte) error
 }
+
+// BlockSigner is implemented by the accounts which need the full block to sign,
+// e.g. a remote signer which shows the block for approval.
+type BlockSigner interface {
+	SignAccountBlock(block *core.AccountBlock) (signData []byte, pub ed25519.PublicKey, err error)
+	SignSnapshotBlock(block *core.SnapshotBlock) (signData []byte, pub ed25519.PublicKey, err error)
+}
+
+// SignAccountBlock signs the hash of the block by acct, and sets the signature and the public key of the block
+func SignAccountBlock(acct Account, block *core.AccountBlock) (err error) {
+	if signer, ok := acct.(BlockSigner); ok {
+		block.Signature, block.PublicKey, err = signer.SignAccountBlock(block)
+	} else {
+		block.Signature, block.PublicKey, err = acct.Sign(block.Hash.Bytes())
+	}
+	return err
+}
+
+// SignSnapshotBlock signs the hash of the block by acct, and sets the signature and the public key of the block
+func SignSnapshotBlock(acct Account, block *core.SnapshotBlock) (err error) {
+	if signer, ok := acct.(BlockSigner); ok {
+		block.Signature, block.PublicKey, err = signer.SignSnapshotBlock(block)
+	} else {
+		block.Signature, block.PublicKey, err = acct.Sign(block.Hash.Bytes())
+	}
+	return err
+}
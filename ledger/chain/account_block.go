@@ -23,7 +23,7 @@ func (c *chain) IsAccountBlockExisted(hash types.Hash) (bool, error) {
 	// query index
 	ok, err := c.indexDB.IsAccountBlockExisted(&hash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.IsAccountBlockExisted failed, error is %s, hash is %s", err, hash)
+		cErr := fmt.Errorf("c.indexDB.IsAccountBlockExisted failed, error is %w, hash is %s", err, hash)
 		return false, cErr
 	}
 
@@ -39,8 +39,8 @@ func (c *chain) GetAccountBlockByHeight(addr types.Address, height uint64) (*led
 	// query location
 	location, err := c.indexDB.GetAccountBlockLocation(&addr, height)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocation failed, error is %s, address is %s, height is %d",
-			err.Error(), addr, height)
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocation failed, error is %w, address is %s, height is %d",
+			err, addr, height)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlockByHeight")
 		return nil, err
 	}
@@ -53,8 +53,8 @@ func (c *chain) GetAccountBlockByHeight(addr types.Address, height uint64) (*led
 	block, err := c.blockDB.GetAccountBlock(location)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, address is %s, height is %d, location is %+v. Error: %s,  ",
-			addr, height, location, err.Error())
+		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, address is %s, height is %d, location is %+v. Error: %w,  ",
+			addr, height, location, err)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlockByHeight")
 		return nil, err
 	}
@@ -71,8 +71,8 @@ func (c *chain) GetAccountBlockHashByHeight(addr types.Address, height uint64) (
 	// query location
 	hash, _, err := c.indexDB.GetAccountBlockLocationByHeight(&addr, height)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockHashByHeight failed, error is %s, address is %s, height is %d",
-			err.Error(), addr, height)
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockHashByHeight failed, error is %w, address is %s, height is %d",
+			err, addr, height)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlockHashByHeight")
 		return nil, err
 	}
@@ -89,8 +89,8 @@ func (c *chain) GetCompleteBlockByHash(blockHash types.Hash) (*ledger.AccountBlo
 	// query location
 	location, err := c.indexDB.GetAccountBlockLocationByHash(&blockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocation failed, hash is %s. Error: %s",
-			blockHash, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocation failed, hash is %s. Error: %w",
+			blockHash, err)
 		c.log.Error(cErr.Error(), "method", "GetCompleteBlockByHash")
 		return nil, cErr
 	}
@@ -103,8 +103,8 @@ func (c *chain) GetCompleteBlockByHash(blockHash types.Hash) (*ledger.AccountBlo
 	block, err := c.blockDB.GetAccountBlock(location)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, hash is %s, location is %+v. Error: %s",
-			blockHash, location, err.Error())
+		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, hash is %s, location is %+v. Error: %w",
+			blockHash, location, err)
 		c.log.Error(cErr.Error(), "method", "GetCompleteBlockByHash")
 		return nil, cErr
 	}
@@ -129,8 +129,8 @@ func (c *chain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBloc
 func (c *chain) GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountBlock, error) {
 	receiveBlockHash, err := c.indexDB.GetReceivedBySend(&sendBlockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetReceivedBySend failed, hash is %s. Error: %s",
-			err.Error(), sendBlockHash)
+		cErr := fmt.Errorf("c.indexDB.GetReceivedBySend failed, hash is %w. Error: %s",
+			err, sendBlockHash)
 		c.log.Error(cErr.Error(), "method", "GetReceiveAbBySendAb")
 		return nil, cErr
 	}
@@ -152,8 +152,8 @@ func (c *chain) GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountB
 func (c *chain) IsReceived(sendBlockHash types.Hash) (bool, error) {
 	result, err := c.indexDB.IsReceived(&sendBlockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.IsReceived failed, error is %s,  hash is %s",
-			err.Error(), sendBlockHash)
+		cErr := fmt.Errorf("c.indexDB.IsReceived failed, error is %w,  hash is %s",
+			err, sendBlockHash)
 		c.log.Error(cErr.Error(), "method", "IsReceived")
 		return false, err
 	}
@@ -168,8 +168,8 @@ func (c *chain) GetAccountBlocks(blockHash types.Hash, count uint64) ([]*ledger.
 	addr, locations, heightRange, err := c.indexDB.GetAccountBlockLocationList(&blockHash, count)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationList failed, error is %s,  hash is %s, count is %d",
-			err.Error(), blockHash, count)
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationList failed, error is %w,  hash is %s, count is %d",
+			err, blockHash, count)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlocks")
 		return nil, err
 	}
@@ -188,8 +188,8 @@ func (c *chain) GetAccountBlocksByHeight(addr types.Address, height uint64, coun
 	locations, heightRange, err := c.indexDB.GetAccountBlockLocationListByHeight(addr, height, count)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationList failed, Addr is %s,  height is %d, count is %d.Error: %s",
-			addr, height, count, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationList failed, Addr is %s,  height is %d, count is %d.Error: %w",
+			addr, height, count, err)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlocksByHeight")
 		return nil, err
 	}
@@ -209,8 +209,8 @@ func (c *chain) GetAccountBlocksByRange(addr types.Address, start uint64, end ui
 	locations, heightRange, err := c.indexDB.GetAccountBlockLocationListByRange(addr, start, end)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationListByRange failed, Addr is %s,  start is %d, end is %d.Error: %s",
-			addr, start, end, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationListByRange failed, Addr is %s,  start is %d, end is %d.Error: %w",
+			addr, start, end, err)
 		c.log.Error(cErr.Error(), "method", "GetAccountBlockLocationListByRange")
 		return nil, err
 	}
@@ -225,19 +225,31 @@ func (c *chain) GetAccountBlocksByRange(addr types.Address, start uint64, end ui
 func (c *chain) GetCallDepth(sendBlockHash types.Hash) (uint16, error) {
 	callDepth, err := c.stateDB.GetCallDepth(&sendBlockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetCallDepth failed, sendBlockHash is %s. Error: %s",
-			sendBlockHash, err.Error())
+		cErr := fmt.Errorf("c.stateDB.GetCallDepth failed, sendBlockHash is %s. Error: %w",
+			sendBlockHash, err)
 		c.log.Error(cErr.Error(), "method", "GetConfirmedTimes")
 		return 0, cErr
 	}
 	return callDepth, nil
 }
 
+// get the reason why the receive block failed, nil if the block didn't fail or it was not executed by this node
+func (c *chain) GetExecutionError(blockHash types.Hash) (*ledger.ExecutionError, error) {
+	executionError, err := c.stateDB.GetExecutionError(&blockHash)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.GetExecutionError failed, blockHash is %s. Error: %w",
+			blockHash, err)
+		c.log.Error(cErr.Error(), "method", "GetExecutionError")
+		return nil, cErr
+	}
+	return executionError, nil
+}
+
 func (c *chain) GetConfirmedTimes(blockHash types.Hash) (uint64, error) {
 	confirmHeight, err := c.indexDB.GetConfirmHeightByHash(&blockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetConfirmHeightByHash failed, blockHash is %s. Error: %s",
-			blockHash, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetConfirmHeightByHash failed, blockHash is %s. Error: %w",
+			blockHash, err)
 		c.log.Error(cErr.Error(), "method", "GetConfirmedTimes")
 		return 0, cErr
 	}
@@ -258,8 +270,8 @@ func (c *chain) IsSeedConfirmedNTimes(blockHash types.Hash, n uint64) (bool, err
 
 	confirmedHeight, err := c.indexDB.GetConfirmHeightByHash(&blockHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetConfirmHeightByHash failed, blockHash is %s. Error: %s",
-			blockHash, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetConfirmHeightByHash failed, blockHash is %s. Error: %w",
+			blockHash, err)
 		c.log.Error(cErr.Error(), "method", "IsSeedConfirmedNTimes")
 		return false, cErr
 	}
@@ -273,8 +285,8 @@ func (c *chain) IsSeedConfirmedNTimes(blockHash types.Hash, n uint64) (bool, err
 	for h := confirmedHeight; seedCount < n; h++ {
 		snapshotBlock, err := c.GetSnapshotBlockByHeight(h)
 		if err != nil {
-			cErr := fmt.Errorf("c.GetSnapshotBlockByHeight failed, height is %d. Error: %s",
-				h, err.Error())
+			cErr := fmt.Errorf("c.GetSnapshotBlockByHeight failed, height is %d. Error: %w",
+				h, err)
 			c.log.Error(cErr.Error(), "method", "IsSeedConfirmedNTimes")
 			return false, cErr
 		}
@@ -296,8 +308,8 @@ func (c *chain) GetLatestAccountBlock(addr types.Address) (*ledger.AccountBlock,
 
 	height, location, err := c.indexDB.GetLatestAccountBlock(&addr)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetLatestAccountBlock failed, Addr is %s. Error: %s",
-			addr, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetLatestAccountBlock failed, Addr is %s. Error: %w",
+			addr, err)
 		c.log.Error(cErr.Error(), "method", "GetLatestAccountBlock")
 		return nil, cErr
 	}
@@ -313,8 +325,8 @@ func (c *chain) GetLatestAccountBlock(addr types.Address) (*ledger.AccountBlock,
 	block, err := c.blockDB.GetAccountBlock(location)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, address is %s, height is %d, location is %+v. Error: %s, ",
-			addr, height, location, err.Error())
+		cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, address is %s, height is %d, location is %+v. Error: %w, ",
+			addr, height, location, err)
 		c.log.Error(cErr.Error(), "method", "GetLatestAccountBlock")
 		return nil, err
 	}
@@ -329,8 +341,8 @@ func (c *chain) GetLatestAccountHeight(addr types.Address) (uint64, error) {
 
 	height, _, err := c.indexDB.GetLatestAccountBlock(&addr)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetLatestAccountBlock failed, Addr is %s. Error: %s",
-			addr, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetLatestAccountBlock failed, Addr is %s. Error: %w",
+			addr, err)
 		c.log.Error(cErr.Error(), "method", "GetLatestAccountBlock")
 		return 0, cErr
 	}
@@ -355,8 +367,8 @@ func (c *chain) getAccountBlocks(addr types.Address, locations []*chain_file_man
 			var err error
 			block, err = c.blockDB.GetAccountBlock(locations[index])
 			if err != nil {
-				cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, locations is %+v. Error: %s",
-					locations[index], err.Error())
+				cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, locations is %+v. Error: %w",
+					locations[index], err)
 				c.log.Error(cErr.Error(), "method", "getAccountBlocks")
 				return nil, cErr
 			}
@@ -0,0 +1,275 @@
+package chain_archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// chunk archive is a portable file of continuous snapshot chunks:
+//
+//	magic | version | from | to | record... | 0 | sha256
+//	record: size uint32 | payload | crc32(payload) uint32
+//	payload: account block count uint32 | (size uint32 | account block)... | snapshot block
+//
+// the sha256 is computed over all bytes before it.
+var archiveMagic = []byte("VITECHUNKS")
+
+const archiveVersion = 1
+
+const maxArchiveRecordSize = 256 * 1024 * 1024
+
+var ErrArchiveChecksum = errors.New("chunk archive checksum mismatch")
+
+// ArchiveHeader describes the snapshot height range of the archive
+type ArchiveHeader struct {
+	Version uint32
+	From    uint64
+	To      uint64
+}
+
+// ArchiveWriter writes snapshot chunks to a chunk archive
+type ArchiveWriter struct {
+	w    *bufio.Writer
+	sum  hash.Hash
+	next uint64
+	to   uint64
+}
+
+func NewArchiveWriter(w io.Writer, from, to uint64) (*ArchiveWriter, error) {
+	if from == 0 || from > to {
+		return nil, fmt.Errorf("invalid archive range [%d, %d]", from, to)
+	}
+	aw := &ArchiveWriter{
+		w:    bufio.NewWriter(w),
+		sum:  sha256.New(),
+		next: from,
+		to:   to,
+	}
+
+	header := make([]byte, 0, len(archiveMagic)+20)
+	header = append(header, archiveMagic...)
+	header = appendUint32(header, archiveVersion)
+	header = appendUint64(header, from)
+	header = appendUint64(header, to)
+	if err := aw.write(header); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+// WriteChunk writes the chunk, chunks must be written in height order without gap
+func (aw *ArchiveWriter) WriteChunk(chunk *core.SnapshotChunk) error {
+	if chunk.SnapshotBlock == nil {
+		return errors.New("chunk without snapshot block")
+	}
+	if chunk.SnapshotBlock.Height != aw.next || aw.next > aw.to {
+		return fmt.Errorf("unexpected chunk %d, expected %d", chunk.SnapshotBlock.Height, aw.next)
+	}
+
+	payload := appendUint32(nil, uint32(len(chunk.AccountBlocks)))
+	for _, ab := range chunk.AccountBlocks {
+		buf, err := ab.Serialize()
+		if err != nil {
+			return err
+		}
+		payload = appendUint32(payload, uint32(len(buf)))
+		payload = append(payload, buf...)
+	}
+	buf, err := chunk.SnapshotBlock.Serialize()
+	if err != nil {
+		return err
+	}
+	payload = append(payload, buf...)
+
+	record := make([]byte, 0, len(payload)+8)
+	record = appendUint32(record, uint32(len(payload)))
+	record = append(record, payload...)
+	record = appendUint32(record, crc32.ChecksumIEEE(payload))
+	if err = aw.write(record); err != nil {
+		return err
+	}
+
+	aw.next++
+	return nil
+}
+
+// Close writes the end of the archive and the checksum, it doesn't close the underlying writer
+func (aw *ArchiveWriter) Close() error {
+	if aw.next != aw.to+1 {
+		return fmt.Errorf("archive is incomplete, next chunk is %d, expected to end at %d", aw.next, aw.to)
+	}
+	if err := aw.write(appendUint32(nil, 0)); err != nil {
+		return err
+	}
+	if _, err := aw.w.Write(aw.sum.Sum(nil)); err != nil {
+		return err
+	}
+	return aw.w.Flush()
+}
+
+func (aw *ArchiveWriter) write(buf []byte) error {
+	aw.sum.Write(buf)
+	_, err := aw.w.Write(buf)
+	return err
+}
+
+// ArchiveReader reads snapshot chunks from a chunk archive
+type ArchiveReader struct {
+	Header ArchiveHeader
+
+	r   *bufio.Reader
+	sum hash.Hash
+	end bool
+}
+
+func NewArchiveReader(r io.Reader) (*ArchiveReader, error) {
+	ar := &ArchiveReader{
+		r:   bufio.NewReader(r),
+		sum: sha256.New(),
+	}
+
+	header := make([]byte, len(archiveMagic)+20)
+	if err := ar.read(header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(archiveMagic)], archiveMagic) {
+		return nil, errors.New("not a chunk archive")
+	}
+	header = header[len(archiveMagic):]
+	ar.Header.Version = binary.BigEndian.Uint32(header)
+	ar.Header.From = binary.BigEndian.Uint64(header[4:])
+	ar.Header.To = binary.BigEndian.Uint64(header[12:])
+	if ar.Header.Version != archiveVersion {
+		return nil, fmt.Errorf("unsupported chunk archive version %d", ar.Header.Version)
+	}
+
+	return ar, nil
+}
+
+// ReadChunk returns the next chunk, io.EOF is returned after the last chunk if the checksum matches
+func (ar *ArchiveReader) ReadChunk() (*core.SnapshotChunk, error) {
+	if ar.end {
+		return nil, io.EOF
+	}
+
+	sizeBuf := make([]byte, 4)
+	if err := ar.read(sizeBuf); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(sizeBuf)
+	if size == 0 {
+		ar.end = true
+		expected := ar.sum.Sum(nil)
+		actual := make([]byte, sha256.Size)
+		if _, err := io.ReadFull(ar.r, actual); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(expected, actual) {
+			return nil, ErrArchiveChecksum
+		}
+		return nil, io.EOF
+	}
+	if size > maxArchiveRecordSize {
+		return nil, fmt.Errorf("chunk record is too large: %d", size)
+	}
+
+	payload := make([]byte, size+4)
+	if err := ar.read(payload); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(payload[:size]) != binary.BigEndian.Uint32(payload[size:]) {
+		return nil, ErrArchiveChecksum
+	}
+
+	return decodeArchiveChunk(payload[:size])
+}
+
+func (ar *ArchiveReader) read(buf []byte) error {
+	if _, err := io.ReadFull(ar.r, buf); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	ar.sum.Write(buf)
+	return nil
+}
+
+func decodeArchiveChunk(payload []byte) (*core.SnapshotChunk, error) {
+	if len(payload) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	count := binary.BigEndian.Uint32(payload)
+	payload = payload[4:]
+
+	chunk := &core.SnapshotChunk{}
+	for i := uint32(0); i < count; i++ {
+		if len(payload) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		size := binary.BigEndian.Uint32(payload)
+		payload = payload[4:]
+		if uint32(len(payload)) < size {
+			return nil, io.ErrUnexpectedEOF
+		}
+		ab := &core.AccountBlock{}
+		if err := ab.Deserialize(payload[:size]); err != nil {
+			return nil, err
+		}
+		chunk.AccountBlocks = append(chunk.AccountBlocks, ab)
+		payload = payload[size:]
+	}
+
+	chunk.SnapshotBlock = &core.SnapshotBlock{}
+	if err := chunk.SnapshotBlock.Deserialize(payload); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// VerifyArchive reads through the archive and checks the checksums and the height range
+func VerifyArchive(r io.Reader) (*ArchiveHeader, error) {
+	ar, err := NewArchiveReader(r)
+	if err != nil {
+		return nil, err
+	}
+	next := ar.Header.From
+	for {
+		chunk, err := ar.ReadChunk()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if chunk.SnapshotBlock.Height != next {
+			return nil, fmt.Errorf("unexpected chunk %d, expected %d", chunk.SnapshotBlock.Height, next)
+		}
+		next++
+	}
+	if next != ar.Header.To+1 {
+		return nil, fmt.Errorf("archive is incomplete, ends at %d, expected %d", next-1, ar.Header.To)
+	}
+	return &ar.Header, nil
+}
+
+func appendUint32(buf []byte, n uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], n)
+	return append(buf, b[:]...)
+}
+
+func appendUint64(buf []byte, n uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n)
+	return append(buf, b[:]...)
+}
@@ -0,0 +1,79 @@
+package chain_archive
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func writeTestArchive(t *testing.T, from, to uint64) []byte {
+	var buf bytes.Buffer
+	aw, err := NewArchiveWriter(&buf, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for h := from; h <= to; h++ {
+		var block core.SnapshotBlock
+		block.Mock(h)
+		if err = aw.WriteChunk(&core.SnapshotChunk{SnapshotBlock: &block}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = aw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchive(t *testing.T) {
+	data := writeTestArchive(t, 10, 20)
+
+	header, err := VerifyArchive(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.From != 10 || header.To != 20 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	ar, err := NewArchiveReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for h := uint64(10); h <= 20; h++ {
+		chunk, err := ar.ReadChunk()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunk.SnapshotBlock.Height != h {
+			t.Fatalf("expected chunk %d, got %d", h, chunk.SnapshotBlock.Height)
+		}
+	}
+	if _, err = ar.ReadChunk(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// corrupt the checksum at the end
+	data[len(data)-1] ^= 0xff
+	if _, err = VerifyArchive(bytes.NewReader(data)); err != ErrArchiveChecksum {
+		t.Fatalf("expected checksum error, got %v", err)
+	}
+}
+
+func TestArchiveWriter_Gap(t *testing.T) {
+	var buf bytes.Buffer
+	aw, err := NewArchiveWriter(&buf, 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var block core.SnapshotBlock
+	block.Mock(2)
+	if err = aw.WriteChunk(&core.SnapshotChunk{SnapshotBlock: &block}); err == nil {
+		t.Fatal("expected error for chunk gap")
+	}
+	if err = aw.Close(); err == nil {
+		t.Fatal("expected error for incomplete archive")
+	}
+}
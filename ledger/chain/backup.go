@@ -0,0 +1,167 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_backup "github.com/vitelabs/go-vite/v2/ledger/chain/backup"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+type backupSnapshot struct {
+	name        string
+	newIterator func() chain_backup.Iterator
+	release     func()
+}
+
+// Backup takes a consistent backup of the ledger into dir while the chain is running.
+// The leveldb snapshots and the end of the block files are taken at a flush boundary, then they are copied
+// without locking the chain. The block files unchanged since the previous backup in dir are not rewritten.
+func (c *chain) Backup(dir string) (*chain_backup.Manifest, error) {
+	c.backupMu.Lock()
+	defer c.backupMu.Unlock()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	prev, err := chain_backup.ReadManifest(dir)
+	if err != nil {
+		c.log.Warn(fmt.Sprintf("read the previous backup failed, the backup is rewritten. Error: %s", err), "method", "Backup")
+		prev = nil
+	}
+	if err := chain_backup.RemoveManifest(dir); err != nil {
+		return nil, err
+	}
+
+	var location *chain_file_manager.Location
+	var height uint64
+	var hash types.Hash
+	var snapshots []backupSnapshot
+	defer func() {
+		for _, item := range snapshots {
+			item.release()
+		}
+	}()
+
+	if err := c.flusher.Checkpoint(func() error {
+		location = c.blockDB.FlushedLocation()
+
+		for _, item := range c.backupStores() {
+			snapshot, err := item.store.DiskSnapshot()
+			if err != nil {
+				return fmt.Errorf("get snapshot of %s failed. Error: %w", item.name, err)
+			}
+			snapshots = append(snapshots, backupSnapshot{
+				name: item.name,
+				newIterator: func() chain_backup.Iterator {
+					return snapshot.NewIterator(nil, nil)
+				},
+				release: snapshot.Release,
+			})
+
+			if item.store == c.indexDB.Store() {
+				if height, hash, err = latestSnapshotBlockAt(snapshot); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the meta db is written directly, not by the flusher
+		metaSnapshot, err := c.metaDB.GetSnapshot()
+		if err != nil {
+			return fmt.Errorf("get snapshot of chain_meta failed. Error: %w", err)
+		}
+		snapshots = append(snapshots, backupSnapshot{
+			name: "chain_meta",
+			newIterator: func() chain_backup.Iterator {
+				return metaSnapshot.NewIterator(nil, nil)
+			},
+			release: metaSnapshot.Release,
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	manifest := &chain_backup.Manifest{
+		SnapshotHeight: height,
+		SnapshotHash:   hash,
+		Time:           time.Now(),
+		BlockFileId:    location.FileId,
+		BlockOffset:    location.Offset,
+	}
+
+	c.log.Info(fmt.Sprintf("start backup at snapshot block %d %s", manifest.SnapshotHeight, manifest.SnapshotHash), "method", "Backup")
+
+	if manifest.Files, err = chain_backup.CopyBlockFiles(c.blockDB.Dir(), dir, location, prev); err != nil {
+		return nil, err
+	}
+
+	for _, item := range snapshots {
+		db, err := chain_backup.CopyDb(item.newIterator(), dir, item.name)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Dbs = append(manifest.Dbs, db)
+	}
+
+	// the block files may be truncated by rolling back while copying
+	latestHash, _, err := c.indexDB.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if latestHash == nil || *latestHash != hash {
+		return nil, fmt.Errorf("snapshot block %d is rolled back while backing up", manifest.SnapshotHeight)
+	}
+
+	if err := chain_backup.WriteManifest(dir, manifest); err != nil {
+		return nil, err
+	}
+
+	c.log.Info(fmt.Sprintf("finish backup at snapshot block %d, %d block files, %d dbs", manifest.SnapshotHeight, len(manifest.Files), len(manifest.Dbs)), "method", "Backup")
+	return manifest, nil
+}
+
+type backupStore struct {
+	name  string
+	store *chain_db.Store
+}
+
+// backupStores returns the stores to back up, the names are the directories in the chain dir
+func (c *chain) backupStores() []backupStore {
+	stores := []backupStore{
+		{"index", c.indexDB.Store()},
+		{"state", c.stateDB.Store()},
+		{"state_redo", c.stateDB.RedoStore()},
+	}
+	if c.chainCfg.OpenPlugins {
+		stores = append(stores, backupStore{"plugins", c.plugins.Store()})
+	}
+	return stores
+}
+
+// latestSnapshotBlockAt returns the latest snapshot block in the snapshot of the index db
+func latestSnapshotBlockAt(indexSnapshot *leveldb.Snapshot) (uint64, types.Hash, error) {
+	iter := indexSnapshot.NewIterator(util.BytesPrefix([]byte{chain_utils.SnapshotBlockHeightKeyPrefix}), nil)
+	defer iter.Release()
+
+	if !iter.Last() {
+		if err := iter.Error(); err != nil {
+			return 0, types.Hash{}, err
+		}
+		return 0, types.Hash{}, errors.New("no snapshot block is flushed")
+	}
+
+	hash, err := types.BytesToHash(iter.Value()[:types.HashSize])
+	if err != nil {
+		return 0, types.Hash{}, err
+	}
+	return chain_utils.BytesToUint64(iter.Key()[1:]), hash, nil
+}
@@ -0,0 +1,290 @@
+package chain_backup
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+const (
+	// ManifestFileName is written last, a backup without the manifest is incomplete
+	ManifestFileName = "manifest.json"
+
+	manifestVersion = 1
+
+	// BlocksDirName is the directory of the block files, same as the block db
+	BlocksDirName = "blocks"
+
+	copyBatchSize = 10000
+)
+
+// File is a block file in the backup
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	Sha256 string `json:"sha256"`
+}
+
+// Db is a leveldb checkpoint in the backup, the hash is computed over the keys and values in order
+type Db struct {
+	Name   string `json:"name"`
+	Keys   uint64 `json:"keys"`
+	Sha256 string `json:"sha256"`
+}
+
+// Manifest describes a backup, the data is consistent at the snapshot block
+type Manifest struct {
+	Version        int        `json:"version"`
+	SnapshotHeight uint64     `json:"snapshotHeight"`
+	SnapshotHash   types.Hash `json:"snapshotHash"`
+	Time           time.Time  `json:"time"`
+
+	BlockFileId uint64 `json:"blockFileId"`
+	BlockOffset int64  `json:"blockOffset"`
+
+	Files []*File `json:"files"`
+	Dbs   []*Db   `json:"dbs"`
+}
+
+// ReadManifest reads the manifest in dir, it returns nil if dir has no manifest
+func ReadManifest(dir string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path.Join(dir, ManifestFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parse %s failed. Error: %s", ManifestFileName, err)
+	}
+	if manifest.Version != manifestVersion {
+		return nil, fmt.Errorf("unsupported backup version %d", manifest.Version)
+	}
+	return manifest, nil
+}
+
+// RemoveManifest marks the backup in dir incomplete before it is updated
+func RemoveManifest(dir string) error {
+	if err := os.Remove(path.Join(dir, ManifestFileName)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// WriteManifest writes the manifest in dir
+func WriteManifest(dir string, manifest *Manifest) error {
+	manifest.Version = manifestVersion
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFileName := path.Join(dir, ManifestFileName+".tmp")
+	if err := ioutil.WriteFile(tmpFileName, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpFileName, path.Join(dir, ManifestFileName))
+}
+
+// CopyBlockFiles copies the block files in blocksDir into dir until location, the block files after location
+// are not flushed. The files with the same size and hash as the previous backup are not rewritten.
+func CopyBlockFiles(blocksDir string, dir string, location *chain_file_manager.Location, prev *Manifest) ([]*File, error) {
+	targetDir := path.Join(dir, BlocksDirName)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return nil, err
+	}
+
+	prevFiles := make(map[string]*File)
+	if prev != nil {
+		for _, file := range prev.Files {
+			prevFiles[file.Name] = file
+		}
+	}
+
+	var files []*File
+	for fileId := uint64(1); fileId <= location.FileId; fileId++ {
+		name := chain_file_manager.FileName(fileId)
+		size := int64(-1)
+		if fileId == location.FileId {
+			size = location.Offset
+		}
+
+		file, err := copyBlockFile(path.Join(blocksDir, name), path.Join(targetDir, name), size, prevFiles[name])
+		if err != nil {
+			return nil, fmt.Errorf("copy block file %s failed. Error: %s", name, err)
+		}
+		file.Name = name
+		files = append(files, file)
+	}
+
+	// the block files of the previous backup which are rolled back
+	for name := range prevFiles {
+		exists := false
+		for _, file := range files {
+			if file.Name == name {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			if err := os.Remove(path.Join(targetDir, name)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// copyBlockFile copies the first size bytes of the source, size is -1 means the whole file
+func copyBlockFile(source, target string, size int64, prev *File) (*File, error) {
+	src, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	newReader := func() io.Reader {
+		if size < 0 {
+			return src
+		}
+		return io.LimitReader(src, size)
+	}
+
+	if prev != nil {
+		if stat, err := os.Stat(target); err == nil && stat.Size() == prev.Size {
+			// the unchanged file is not rewritten
+			file, err := hashReader(newReader())
+			if err != nil {
+				return nil, err
+			}
+			if file.Size == prev.Size && file.Sha256 == prev.Sha256 {
+				return file, nil
+			}
+			if _, err := src.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	dst, err := os.Create(target)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	written, err := io.Copy(io.MultiWriter(dst, h), newReader())
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size >= 0 && written != size {
+		return nil, fmt.Errorf("%d bytes copied, %d bytes expected", written, size)
+	}
+
+	return &File{Size: written, Sha256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+func hashReader(reader io.Reader) (*File, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, reader)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Size: size, Sha256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Iterator is the iterator of a leveldb snapshot
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
+}
+
+// CopyDb copies the keys and values of the snapshot iterator into a new leveldb in dir/name
+func CopyDb(iter Iterator, dir string, name string) (*Db, error) {
+	dbDir := path.Join(dir, name)
+	if err := os.RemoveAll(dbDir); err != nil {
+		return nil, err
+	}
+
+	target, err := leveldb.OpenFile(dbDir, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := copyIterator(iter, target)
+	iter.Release()
+
+	if closeErr := target.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("copy db %s failed. Error: %s", name, err)
+	}
+
+	db.Name = name
+	return db, nil
+}
+
+func copyIterator(iter Iterator, target *leveldb.DB) (*Db, error) {
+	db := &Db{}
+	h := sha256.New()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		hashKeyValue(h, iter.Key(), iter.Value())
+		batch.Put(iter.Key(), iter.Value())
+		db.Keys++
+
+		if batch.Len() >= copyBatchSize {
+			if err := target.Write(batch, nil); err != nil {
+				return nil, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	if batch.Len() > 0 {
+		if err := target.Write(batch, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	db.Sha256 = hex.EncodeToString(h.Sum(nil))
+	return db, nil
+}
+
+func hashKeyValue(h hash.Hash, key, value []byte) {
+	var size [8]byte
+	binary.BigEndian.PutUint32(size[:4], uint32(len(key)))
+	binary.BigEndian.PutUint32(size[4:], uint32(len(value)))
+	h.Write(size[:])
+	h.Write(key)
+	h.Write(value)
+}
@@ -0,0 +1,132 @@
+package chain_backup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	tempDir, err := ioutil.TempDir("", "chain_backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	chainDir := path.Join(tempDir, "ledger")
+	backupDir := path.Join(tempDir, "backup")
+
+	// two block files, the tail of the last one is not flushed
+	blocksDir := path.Join(chainDir, BlocksDirName)
+	if err := os.MkdirAll(blocksDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	f1 := bytes.Repeat([]byte{1}, 1024)
+	f2 := bytes.Repeat([]byte{2}, 512)
+	if err := ioutil.WriteFile(path.Join(blocksDir, "f1"), f1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(blocksDir, "f2"), f2, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := leveldb.OpenFile(path.Join(chainDir, "index"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := byte(0); i < 100; i++ {
+		if err := db.Put([]byte{i}, []byte{i, i}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup := func(prev *Manifest) *Manifest {
+		manifest := &Manifest{SnapshotHeight: 10, BlockFileId: 2, BlockOffset: 256}
+		if manifest.Files, err = CopyBlockFiles(blocksDir, backupDir, chain_file_manager.NewLocation(2, 256), prev); err != nil {
+			t.Fatal(err)
+		}
+
+		snapshot, err := db.GetSnapshot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer snapshot.Release()
+		dbManifest, err := CopyDb(snapshot.NewIterator(nil, nil), backupDir, "index")
+		if err != nil {
+			t.Fatal(err)
+		}
+		manifest.Dbs = append(manifest.Dbs, dbManifest)
+
+		if err := WriteManifest(backupDir, manifest); err != nil {
+			t.Fatal(err)
+		}
+		return manifest
+	}
+
+	manifest := backup(nil)
+	if len(manifest.Files) != 2 || manifest.Files[0].Size != 1024 || manifest.Files[1].Size != 256 {
+		t.Fatalf("unexpected files %+v %+v", manifest.Files[0], manifest.Files[1])
+	}
+	if manifest.Dbs[0].Keys != 100 {
+		t.Fatalf("%d keys copied, 100 keys expected", manifest.Dbs[0].Keys)
+	}
+
+	// the unchanged backup has the same hashes
+	prev, err := ReadManifest(backupDir)
+	if err != nil || prev == nil {
+		t.Fatal(prev, err)
+	}
+	manifest = backup(prev)
+	for i, file := range manifest.Files {
+		if *file != *prev.Files[i] {
+			t.Fatalf("file %s changed, %+v, previous is %+v", file.Name, file, prev.Files[i])
+		}
+	}
+	if *manifest.Dbs[0] != *prev.Dbs[0] {
+		t.Fatalf("db changed, %+v, previous is %+v", manifest.Dbs[0], prev.Dbs[0])
+	}
+
+	// restore into a new dir
+	restoreDir := path.Join(tempDir, "restore")
+	if _, err := Restore(backupDir, restoreDir); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ioutil.ReadFile(path.Join(restoreDir, BlocksDirName, "f2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, f2[:256]) {
+		t.Fatal("the restored block file is not the flushed part")
+	}
+	restoredDb, err := leveldb.OpenFile(path.Join(restoreDir, "index"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := restoredDb.Get([]byte{99}, nil)
+	restoredDb.Close()
+	if err != nil || !bytes.Equal(value, []byte{99, 99}) {
+		t.Fatal(value, err)
+	}
+
+	// the restored dir is not empty
+	if _, err := Restore(backupDir, restoreDir); err == nil {
+		t.Fatal("restore into a non-empty dir should fail")
+	}
+
+	// corrupted block file
+	if err := ioutil.WriteFile(path.Join(backupDir, BlocksDirName, "f1"), f2, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(backupDir, manifest); err == nil {
+		t.Fatal("the corrupted backup should fail the verification")
+	}
+	if _, err := Restore(backupDir, path.Join(tempDir, "restore2")); err == nil {
+		t.Fatal("restore from the corrupted backup should fail")
+	}
+}
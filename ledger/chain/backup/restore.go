@@ -0,0 +1,141 @@
+package chain_backup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/opt"
+)
+
+// Verify checks the block files and the leveldb checkpoints in dir against the manifest
+func Verify(dir string, manifest *Manifest) error {
+	for _, file := range manifest.Files {
+		fd, err := os.Open(path.Join(dir, BlocksDirName, file.Name))
+		if err != nil {
+			return err
+		}
+		actual, err := hashReader(fd)
+		fd.Close()
+		if err != nil {
+			return err
+		}
+		if actual.Size != file.Size || actual.Sha256 != file.Sha256 {
+			return fmt.Errorf("block file %s is corrupted, size is %d, sha256 is %s, expected size is %d, sha256 is %s",
+				file.Name, actual.Size, actual.Sha256, file.Size, file.Sha256)
+		}
+	}
+
+	for _, db := range manifest.Dbs {
+		actual, err := hashDb(path.Join(dir, db.Name))
+		if err != nil {
+			return fmt.Errorf("read db %s failed. Error: %s", db.Name, err)
+		}
+		if actual.Keys != db.Keys || actual.Sha256 != db.Sha256 {
+			return fmt.Errorf("db %s is corrupted, %d keys, sha256 is %s, expected %d keys, sha256 is %s",
+				db.Name, actual.Keys, actual.Sha256, db.Keys, db.Sha256)
+		}
+	}
+	return nil
+}
+
+func hashDb(dbDir string) (*Db, error) {
+	if _, err := os.Stat(dbDir); err != nil {
+		return nil, err
+	}
+	db, err := leveldb.OpenFile(dbDir, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+
+	h := sha256.New()
+	result := &Db{}
+	for iter.Next() {
+		hashKeyValue(h, iter.Key(), iter.Value())
+		result.Keys++
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	result.Sha256 = hex.EncodeToString(h.Sum(nil))
+	return result, nil
+}
+
+// Restore verifies the backup in dir and copies it into chainDir, chainDir should not exist or be empty
+func Restore(dir string, chainDir string) (*Manifest, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s not found in %s, the backup is incomplete", ManifestFileName, dir)
+	}
+
+	if err := Verify(dir, manifest); err != nil {
+		return manifest, err
+	}
+
+	if infos, err := ioutil.ReadDir(chainDir); err == nil && len(infos) > 0 {
+		return manifest, fmt.Errorf("%s is not empty", chainDir)
+	} else if err != nil && !os.IsNotExist(err) {
+		return manifest, err
+	}
+
+	for _, file := range manifest.Files {
+		if err := copyFile(path.Join(dir, BlocksDirName, file.Name), path.Join(chainDir, BlocksDirName, file.Name)); err != nil {
+			return manifest, err
+		}
+	}
+
+	for _, db := range manifest.Dbs {
+		infos, err := ioutil.ReadDir(path.Join(dir, db.Name))
+		if err != nil {
+			return manifest, err
+		}
+		for _, info := range infos {
+			if info.IsDir() || info.Name() == "LOCK" {
+				continue
+			}
+			if err := copyFile(path.Join(dir, db.Name, info.Name()), path.Join(chainDir, db.Name, info.Name())); err != nil {
+				return manifest, err
+			}
+		}
+	}
+
+	return manifest, nil
+}
+
+func copyFile(source, target string) error {
+	if err := os.MkdirAll(path.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	src, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,408 @@
+package chain_bench
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mrand "math/rand"
+	"os"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
+	chain_state "github.com/vitelabs/go-vite/v2/ledger/chain/state"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+// Config is the size of the synthetic workloads
+type Config struct {
+	// the benchmark files are created in Dir and removed after the benchmark
+	Dir string
+
+	// account blocks written to BlockDB, BlocksPerSnapshot blocks are written in one snapshot chunk
+	Blocks            int
+	BlocksPerSnapshot int
+
+	// keys written to chain_db.Store and StateDB, KeysPerFlush keys are written between two flushes
+	Keys         int
+	KeysPerFlush int
+
+	// random reads of every read workload
+	Reads int
+
+	ValueSize int
+}
+
+// DefaultConfig writes about 100k blocks and 200k keys, it takes a few minutes on a SSD
+func DefaultConfig(dir string) Config {
+	return Config{
+		Dir:               dir,
+		Blocks:            100000,
+		BlocksPerSnapshot: 100,
+		Keys:              200000,
+		KeysPerFlush:      2000,
+		Reads:             20000,
+		ValueSize:         128,
+	}
+}
+
+// Result is the latency distribution of one workload
+type Result struct {
+	Name     string
+	Ops      int
+	Duration time.Duration
+
+	P50 time.Duration
+	P90 time.Duration
+	P99 time.Duration
+	Max time.Duration
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%-24s ops: %-8d total: %-12s p50: %-12s p90: %-12s p99: %-12s max: %s",
+		r.Name, r.Ops, r.Duration, r.P50, r.P90, r.P99, r.Max)
+}
+
+type recorder struct {
+	name      string
+	latencies []time.Duration
+}
+
+func newRecorder(name string, size int) *recorder {
+	return &recorder{
+		name:      name,
+		latencies: make([]time.Duration, 0, size),
+	}
+}
+
+func (r *recorder) since(start time.Time) {
+	r.latencies = append(r.latencies, time.Since(start))
+}
+
+func (r *recorder) result() *Result {
+	result := &Result{
+		Name: r.name,
+		Ops:  len(r.latencies),
+	}
+	if len(r.latencies) == 0 {
+		return result
+	}
+
+	for _, latency := range r.latencies {
+		result.Duration += latency
+	}
+
+	sorted := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	percentile := func(p int) time.Duration {
+		return sorted[(len(sorted)-1)*p/100]
+	}
+	result.P50, result.P90, result.P99, result.Max = percentile(50), percentile(90), percentile(99), sorted[len(sorted)-1]
+	return result
+}
+
+// Run drives the workloads of BlockDB, chain_db.Store and StateDB in cfg.Dir one by one
+func Run(cfg Config, onResult func(result *Result)) error {
+	if cfg.BlocksPerSnapshot <= 0 || cfg.KeysPerFlush <= 0 || cfg.ValueSize <= 0 {
+		return fmt.Errorf("invalid config %+v", cfg)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp(cfg.Dir, "bench")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	for _, run := range []func(cfg Config, dir string) ([]*Result, error){
+		runBlockDB,
+		runStore,
+		runStateDB,
+	} {
+		results, err := run(cfg, dir)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			onResult(result)
+		}
+	}
+	return nil
+}
+
+func newFlusher(dir string, storeList ...chain_flusher.Storage) (*chain_flusher.Flusher, error) {
+	return chain_flusher.NewFlusher(storeList, &sync.RWMutex{}, dir)
+}
+
+// runBlockDB writes snapshot chunks of synthetic account blocks sequentially, flushes them like the chain flusher,
+// then reads the account blocks by random locations
+func runBlockDB(cfg Config, dir string) ([]*Result, error) {
+	dir = path.Join(dir, "blocks")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	bDB, err := chain_block.NewBlockDB(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer bDB.Close()
+
+	flusher, err := newFlusher(dir, bDB)
+	if err != nil {
+		return nil, err
+	}
+	defer flusher.Close()
+
+	chunks := (cfg.Blocks + cfg.BlocksPerSnapshot - 1) / cfg.BlocksPerSnapshot
+	write := newRecorder("blockdb write chunk", chunks)
+	flush := newRecorder("blockdb flush", chunks)
+	read := newRecorder("blockdb random read", cfg.Reads)
+
+	locations := make([]*chain_file_manager.Location, 0, cfg.Blocks)
+	prevSbHash := types.Hash{}
+	for height := uint64(1); len(locations) < cfg.Blocks; height++ {
+		chunk := &ledger.SnapshotChunk{
+			SnapshotBlock: &ledger.SnapshotBlock{
+				Hash:            randomHash(),
+				PrevHash:        prevSbHash,
+				Height:          height,
+				Timestamp:       &time.Time{},
+				SnapshotContent: ledger.SnapshotContent{},
+			},
+		}
+		for i := 0; i < cfg.BlocksPerSnapshot && len(locations)+i < cfg.Blocks; i++ {
+			chunk.AccountBlocks = append(chunk.AccountBlocks, randomAccountBlock(cfg.ValueSize))
+		}
+		prevSbHash = chunk.SnapshotBlock.Hash
+
+		start := time.Now()
+		abLocations, _, err := bDB.Write(chunk)
+		if err != nil {
+			return nil, err
+		}
+		write.since(start)
+
+		for _, location := range abLocations {
+			locations = append(locations, location)
+		}
+
+		start = time.Now()
+		flusher.Flush()
+		flush.since(start)
+	}
+
+	for i := 0; i < cfg.Reads && len(locations) > 0; i++ {
+		location := locations[mrand.Intn(len(locations))]
+
+		start := time.Now()
+		if _, err := bDB.GetAccountBlock(location); err != nil {
+			return nil, err
+		}
+		read.since(start)
+	}
+
+	return []*Result{write.result(), flush.result(), read.result()}, nil
+}
+
+// runStore writes the keys in batches sequentially, flushes every batch, then gets the keys randomly
+func runStore(cfg Config, dir string) ([]*Result, error) {
+	dir = path.Join(dir, "store")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	store, err := chain_db.NewStore(path.Join(dir, "db"), "benchStore")
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	flusher, err := newFlusher(dir, store)
+	if err != nil {
+		return nil, err
+	}
+	defer flusher.Close()
+
+	batches := (cfg.Keys + cfg.KeysPerFlush - 1) / cfg.KeysPerFlush
+	write := newRecorder("store write batch", batches)
+	flush := newRecorder("store flush", batches)
+	read := newRecorder("store random read", cfg.Reads)
+
+	keys := make([][]byte, 0, cfg.Keys)
+	for len(keys) < cfg.Keys {
+		batch := store.NewBatch()
+		for i := 0; i < cfg.KeysPerFlush && len(keys) < cfg.Keys; i++ {
+			key := randomBytes(types.HashSize)
+			batch.Put(key, randomBytes(cfg.ValueSize))
+			keys = append(keys, key)
+		}
+
+		start := time.Now()
+		store.WriteDirectly(batch)
+		write.since(start)
+
+		start = time.Now()
+		flusher.Flush()
+		flush.since(start)
+	}
+
+	for i := 0; i < cfg.Reads && len(keys) > 0; i++ {
+		key := keys[mrand.Intn(len(keys))]
+
+		start := time.Now()
+		if _, err := store.Get(key); err != nil {
+			return nil, err
+		}
+		read.since(start)
+	}
+
+	return []*Result{write.result(), flush.result(), read.result()}, nil
+}
+
+// runStateDB writes the contract storage, the history storage and the balances in the StateDB key layout,
+// every batch is a snapshot height, then reads them randomly by the StateDB queries
+func runStateDB(cfg Config, dir string) ([]*Result, error) {
+	dir = path.Join(dir, "state")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	store, err := chain_db.NewStore(path.Join(dir, "state"), "benchStateDb")
+	if err != nil {
+		return nil, err
+	}
+	redoStore, err := chain_db.NewStore(path.Join(dir, "state_redo"), "benchStateDbRedo")
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	stateDB, err := chain_state.NewStateDBWithStore(benchChain{}, &config.Chain{}, store, redoStore)
+	if err != nil {
+		store.Close()
+		redoStore.Close()
+		return nil, err
+	}
+	defer stateDB.Close()
+
+	flusher, err := newFlusher(dir, store)
+	if err != nil {
+		return nil, err
+	}
+	defer flusher.Close()
+
+	type stateKey struct {
+		addr   types.Address
+		key    []byte
+		height uint64
+	}
+
+	batches := (cfg.Keys + cfg.KeysPerFlush - 1) / cfg.KeysPerFlush
+	write := newRecorder("statedb write snapshot", batches)
+	flush := newRecorder("statedb flush", batches)
+	readStorage := newRecorder("statedb storage read", cfg.Reads)
+	readBalance := newRecorder("statedb balance read", cfg.Reads)
+	readHistory := newRecorder("statedb history read", cfg.Reads)
+
+	// a few contracts have most of the storage, like the chain
+	contracts := make([]types.Address, 64)
+	for i := range contracts {
+		contracts[i] = randomAddress(true)
+	}
+
+	keys := make([]stateKey, 0, cfg.Keys)
+	for height := uint64(1); len(keys) < cfg.Keys; height++ {
+		batch := store.NewBatch()
+		for i := 0; i < cfg.KeysPerFlush && len(keys) < cfg.Keys; i++ {
+			sk := stateKey{
+				addr:   contracts[mrand.Intn(len(contracts))],
+				key:    randomBytes(types.HashSize),
+				height: height,
+			}
+			value := randomBytes(cfg.ValueSize)
+
+			batch.Put(chain_utils.CreateStorageValueKey(&sk.addr, sk.key).Bytes(), value)
+			batch.Put(chain_utils.CreateHistoryStorageValueKey(&sk.addr, sk.key, height).Bytes(), value)
+			batch.Put(chain_utils.CreateBalanceKey(sk.addr, ledger.ViteTokenId).Bytes(), big.NewInt(int64(height)).Bytes())
+			keys = append(keys, sk)
+		}
+
+		start := time.Now()
+		store.WriteDirectly(batch)
+		write.since(start)
+
+		start = time.Now()
+		flusher.Flush()
+		flush.since(start)
+	}
+
+	for i := 0; i < cfg.Reads && len(keys) > 0; i++ {
+		sk := keys[mrand.Intn(len(keys))]
+
+		start := time.Now()
+		if _, err := stateDB.GetStorageValue(&sk.addr, sk.key); err != nil {
+			return nil, err
+		}
+		readStorage.since(start)
+
+		start = time.Now()
+		if _, err := stateDB.GetBalance(sk.addr, ledger.ViteTokenId); err != nil {
+			return nil, err
+		}
+		readBalance.since(start)
+
+		start = time.Now()
+		if _, err := stateDB.GetSnapshotValue(sk.height, sk.addr, sk.key); err != nil {
+			return nil, err
+		}
+		readHistory.since(start)
+	}
+
+	return []*Result{write.result(), flush.result(), readStorage.result(), readBalance.result(), readHistory.result()}, nil
+}
+
+func randomBytes(size int) []byte {
+	b := make([]byte, size)
+	rand.Read(b)
+	return b
+}
+
+func randomHash() types.Hash {
+	hash, _ := types.BytesToHash(randomBytes(types.HashSize))
+	return hash
+}
+
+func randomAddress(isContract bool) types.Address {
+	if isContract {
+		return types.CreateContractAddress(randomBytes(types.HashSize))
+	}
+	return types.PubkeyToAddress(randomBytes(32))
+}
+
+func randomAccountBlock(dataSize int) *ledger.AccountBlock {
+	return &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           randomHash(),
+		Height:         1,
+		AccountAddress: randomAddress(false),
+		ToAddress:      randomAddress(false),
+		Amount:         big.NewInt(1),
+		TokenId:        ledger.ViteTokenId,
+		Data:           randomBytes(dataSize),
+		PublicKey:      randomBytes(32),
+		Signature:      randomBytes(64),
+	}
+}
@@ -0,0 +1,44 @@
+package chain_bench
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := os.MkdirTemp("", "chain_bench")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := Config{
+		Dir:               dir,
+		Blocks:            250,
+		BlocksPerSnapshot: 100,
+		Keys:              500,
+		KeysPerFlush:      200,
+		Reads:             100,
+		ValueSize:         64,
+	}
+
+	var results []*Result
+	if err := Run(cfg, func(result *Result) {
+		results = append(results, result)
+		t.Log(result)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 11 {
+		t.Fatalf("expected 11 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Ops == 0 || result.P50 > result.P99 || result.P99 > result.Max {
+			t.Errorf("wrong result %s", result)
+		}
+	}
+	if results[0].Ops != 3 || results[2].Ops != 100 {
+		t.Errorf("wrong ops, %s, %s", results[0], results[2])
+	}
+}
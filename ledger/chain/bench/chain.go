@@ -0,0 +1,51 @@
+package chain_bench
+
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// benchChain is the empty chain of the StateDB benchmark, the queries of StateDB used by the benchmark
+// don't depend on the chain
+type benchChain struct{}
+
+func (benchChain) IterateAccounts(iterateFunc func(addr types.Address, accountId uint64, err error) bool) {
+}
+
+func (benchChain) QueryLatestSnapshotBlock() (*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func (benchChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return nil
+}
+
+func (benchChain) GetSnapshotHeightByHash(hash types.Hash) (uint64, error) {
+	return 0, nil
+}
+
+func (benchChain) GetUnconfirmedBlocks(addr types.Address) []*ledger.AccountBlock {
+	return nil
+}
+
+func (benchChain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+
+func (benchChain) GetSnapshotHeaderBeforeTime(timestamp *time.Time) (*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func (benchChain) GetSnapshotHeadersAfterOrEqualTime(endHashHeight *ledger.HashHeight, startTime *time.Time, producer *types.Address) ([]*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func (benchChain) GetSnapshotHeaderByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func (benchChain) StopWrite() {}
+
+func (benchChain) RecoverWrite() {}
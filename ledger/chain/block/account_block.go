@@ -1,8 +1,6 @@
 package chain_block
 
 import (
-	"fmt"
-
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
 )
@@ -16,7 +14,7 @@ func (bDB *BlockDB) GetAccountBlock(location *chain_file_manager.Location) (*led
 
 	ab := &ledger.AccountBlock{}
 	if err := ab.Deserialize(buf); err != nil {
-		return nil, fmt.Errorf("ab.Deserialize failed, [Error] %s", err.Error())
+		return nil, errCorrupted(location, err)
 	}
 
 	return ab, nil
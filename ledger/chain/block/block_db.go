@@ -1,27 +1,31 @@
 package chain_block
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
 	"path"
 	"sync"
-
-	"github.com/golang/snappy"
-	"github.com/pkg/errors"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
 	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
 )
 
 var (
 	FixFileSize = int64(10 * 1024 * 1024)
 )
 
+var latencyMetric = monitor.GetOrRegisterHistogramVec("vite_blockdb_latency_seconds",
+	"latency of BlockDB operations in seconds", nil, "op")
+
 // BlockDB append all blocks to file
 type BlockDB struct {
 	fm *chain_file_manager.FileManager
@@ -46,9 +50,14 @@ func NewBlockDB(chainDir string) (*BlockDB, error) {
 
 // NewBlockDB instance for BlocksDB
 func NewBlockDBFixedSize(chainDir string, fileSize int64) (*BlockDB, error) {
+	return NewBlockDBWithOptions(chainDir, fileSize, chain_file_manager.Options{})
+}
+
+// NewBlockDBWithOptions instance for BlocksDB, the options set how the block files are synced and created
+func NewBlockDBWithOptions(chainDir string, fileSize int64, options chain_file_manager.Options) (*BlockDB, error) {
 	id, _ := types.BytesToHash(crypto.Hash256([]byte("blockDb")))
 
-	fm, err := chain_file_manager.NewFileManager(path.Join(chainDir, "blocks"), fileSize, 10)
+	fm, err := chain_file_manager.NewFileManagerWithOptions(path.Join(chainDir, "blocks"), fileSize, 10, options)
 	if err != nil {
 		return nil, err
 	}
@@ -67,10 +76,25 @@ func (bDB *BlockDB) FileSize() int64 {
 	return bDB.fileSize
 }
 
+// Dir returns the directory of the block files
+func (bDB *BlockDB) Dir() string {
+	return bDB.fm.Dir()
+}
+
+// FlushedLocation returns the end of the block files flushed to disk
+func (bDB *BlockDB) FlushedLocation() *chain_file_manager.Location {
+	return bDB.fm.NextFlushStartLocation()
+}
+
+// LatestLocation returns the end of the block files, including the data not flushed
+func (bDB *BlockDB) LatestLocation() *chain_file_manager.Location {
+	return bDB.fm.LatestLocation()
+}
+
 // Close close db
 func (bDB *BlockDB) Close() error {
 	if err := bDB.fm.Close(); err != nil {
-		return fmt.Errorf("bDB.fm.Close failed, error is %s", err)
+		return fmt.Errorf("bDB.fm.Close failed, error is %w", err)
 	}
 
 	bDB.fm = nil
@@ -78,17 +102,18 @@ func (bDB *BlockDB) Close() error {
 }
 
 func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_manager.Location, *chain_file_manager.Location, error) {
+	defer latencyMetric.With("write").ObserveSince(time.Now())
 
 	accountBlocksLocation := make(map[types.Hash]*chain_file_manager.Location)
 
 	for _, accountBlock := range ss.AccountBlocks {
 		buf, err := accountBlock.Serialize()
 		if err != nil {
-			return nil, nil, fmt.Errorf("ss.AccountBlocks.Serialize failed, error is %s, accountBlock is %+v", err.Error(), accountBlock)
+			return nil, nil, fmt.Errorf("ss.AccountBlocks.Serialize failed, error is %w, accountBlock is %+v", err, accountBlock)
 		}
 
 		if location, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, BlockTypeAccountBlock, buf)); err != nil {
-			return nil, nil, fmt.Errorf("bDB.fm.Write failed, error is %s, accountBlock is %+v", err.Error(), accountBlock)
+			return nil, nil, fmt.Errorf("bDB.fm.Write failed, error is %w, accountBlock is %+v", err, accountBlock)
 		} else {
 			accountBlocksLocation[accountBlock.Hash] = location
 		}
@@ -96,7 +121,7 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_
 
 	buf, err := ss.SnapshotBlock.Serialize()
 	if err != nil {
-		return nil, nil, fmt.Errorf("ss.SnapshotBlock.Serialize failed, error is %s, snapshotBlock is %+v", err.Error(), ss.SnapshotBlock)
+		return nil, nil, fmt.Errorf("ss.SnapshotBlock.Serialize failed, error is %w, snapshotBlock is %+v", err, ss.SnapshotBlock)
 	}
 
 	snapshotBlockLocation, err := bDB.fm.Write(makeWriteBytes(bDB.snappyWriteBuffer, BlockTypeSnapshotBlock, buf))
@@ -104,12 +129,16 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) (map[types.Hash]*chain_file_
 	//bDB.log.Info(fmt.Sprintf("sb %s %d %d", ss.SnapshotBlock.Hash, ss.SnapshotBlock.Height, data), "method", "Write")
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("bDB.fm.Write failed, error is %s, snapshotBlock is %+v", err.Error(), ss.SnapshotBlock)
+		return nil, nil, fmt.Errorf("bDB.fm.Write failed, error is %w, snapshotBlock is %+v", err, ss.SnapshotBlock)
 	}
 	return accountBlocksLocation, snapshotBlockLocation, nil
 }
 
 func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error) {
+	defer latencyMetric.With("read").ObserveSince(time.Now())
+	if err := bDB.checkLocation(location); err != nil {
+		return nil, err
+	}
 	buf, _, err := bDB.fm.Read(location)
 	if err != nil {
 		return nil, err
@@ -118,9 +147,9 @@ func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error)
 		return nil, nil
 	}
 
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	sBuf, err := decodeSnappy(nil, buf[1:])
 	if err != nil {
-		return nil, err
+		return nil, errCorrupted(location, err)
 	}
 	return sBuf, nil
 }
@@ -130,6 +159,9 @@ func (bDB *BlockDB) ReadRaw(startLocation *chain_file_manager.Location, buf []by
 }
 
 func (bDB *BlockDB) ReadUnitBytes(location *chain_file_manager.Location) ([]byte, *chain_file_manager.Location, error) {
+	if err := bDB.checkLocation(location); err != nil {
+		return nil, nil, err
+	}
 	buf, nextLocation, err := bDB.fm.Read(location)
 	if err != nil {
 		return nil, nil, err
@@ -137,14 +169,18 @@ func (bDB *BlockDB) ReadUnitBytes(location *chain_file_manager.Location) ([]byte
 	if len(buf) <= 0 {
 		return nil, nextLocation, nil
 	}
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	sBuf, err := decodeSnappy(nil, buf[1:])
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, errCorrupted(location, err)
 	}
 	return sBuf, nextLocation, err
 }
 
 func (bDB *BlockDB) ReadUnit(location *chain_file_manager.Location) (*ledger.SnapshotBlock, *ledger.AccountBlock, *chain_file_manager.Location, error) {
+	defer latencyMetric.With("read_unit").ObserveSince(time.Now())
+	if err := bDB.checkLocation(location); err != nil {
+		return nil, nil, nil, err
+	}
 	buf, nextLocation, err := bDB.fm.Read(location)
 	if err != nil {
 		return nil, nil, nil, err
@@ -152,25 +188,11 @@ func (bDB *BlockDB) ReadUnit(location *chain_file_manager.Location) (*ledger.Sna
 	if len(buf) <= 0 {
 		return nil, nil, nextLocation, nil
 	}
-	sBuf, err := snappy.Decode(nil, buf[1:])
+	sb, ab, err := DecodeUnit(nil, buf[0], buf[1:])
 	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	if buf[0] == BlockTypeSnapshotBlock {
-		sb := &ledger.SnapshotBlock{}
-		if err := sb.Deserialize(sBuf); err != nil {
-			return nil, nil, nil, err
-		}
-		return sb, nil, nextLocation, nil
-	} else if buf[0] == BlockTypeAccountBlock {
-		ab := &ledger.AccountBlock{}
-		if err := ab.Deserialize(sBuf); err != nil {
-			return nil, nil, nil, err
-		}
-		return nil, ab, nextLocation, nil
+		return nil, nil, nil, errCorrupted(location, err)
 	}
-	return nil, nil, nextLocation, nil
+	return sb, ab, nextLocation, nil
 }
 
 func (bDB *BlockDB) ReadChunk(location *chain_file_manager.Location) (*ledger.SnapshotChunk, *chain_file_manager.Location, error) {
@@ -195,10 +217,24 @@ func (bDB *BlockDB) ReadChunk(location *chain_file_manager.Location) (*ledger.Sn
 		}
 		break
 	}
-	return nil, nil, errors.New("not a chunk")
+	return nil, nil, fmt.Errorf("%w: not a chunk at %s", chain_errors.ErrDBCorrupted, location)
 }
 
 func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLocation *chain_file_manager.Location) ([]*ledger.SnapshotChunk, error) {
+	var segList []*ledger.SnapshotChunk
+	if err := bDB.ReadRangeContext(context.Background(), startLocation, endLocation, func(seg *ledger.SnapshotChunk) error {
+		segList = append(segList, seg)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return segList, nil
+}
+
+// ReadRangeContext is ReadRange streaming the chunks to fn, the reading of the files stops soon after ctx is done or fn
+// returns an error, and the error is returned
+func (bDB *BlockDB) ReadRangeContext(ctx context.Context, startLocation *chain_file_manager.Location, endLocation *chain_file_manager.Location,
+	fn func(seg *ledger.SnapshotChunk) error) error {
 	bfp := newBlockFileParser()
 
 	endLocation = bDB.maxLocation(endLocation)
@@ -210,7 +246,7 @@ func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLoc
 		if endLocation != nil {
 			buf, _, err := bDB.fm.Read(endLocation)
 
-			if len(buf) >= 0 {
+			if len(buf) > 0 {
 				bufSizeBytes := make([]byte, 4)
 				binary.BigEndian.PutUint32(bufSizeBytes, uint32(len(buf)))
 				bfp.Write(bufSizeBytes)
@@ -226,49 +262,56 @@ func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLoc
 		bfp.Close()
 	}()
 
-	var segList []*ledger.SnapshotChunk
 	var seg *ledger.SnapshotChunk
 
 	var snappyReadBuffer = make([]byte, 0, 8*1024) // 8kb
 	iterator := bfp.Iterator()
 
-	for buf := range iterator {
+	for {
+		var buf *byteBuffer
+		var ok bool
+		select {
+		case <-ctx.Done():
+			bfp.Abort()
+			return ctx.Err()
+		case buf, ok = <-iterator:
+		}
+		if !ok {
+			break
+		}
+
 		if seg == nil {
 			seg = &ledger.SnapshotChunk{}
 		}
 
-		sBuf, err := snappy.Decode(snappyReadBuffer, buf.Buffer)
+		sb, ab, err := DecodeUnit(snappyReadBuffer, buf.BlockType, buf.Buffer)
 		if err != nil {
-			return nil, err
+			// let the reading goroutine finish
+			bfp.Abort()
+			return fmt.Errorf("%w: %s", chain_errors.ErrDBCorrupted, err)
 		}
 
-		if buf.BlockType == BlockTypeSnapshotBlock {
-
-			sb := &ledger.SnapshotBlock{}
-			if err := sb.Deserialize(sBuf); err != nil {
-				return nil, err
-			}
+		if sb != nil {
 			seg.SnapshotBlock = sb
-			segList = append(segList, seg)
-			seg = nil
-		} else if buf.BlockType == BlockTypeAccountBlock {
-			ab := &ledger.AccountBlock{}
-			if err := ab.Deserialize(sBuf); err != nil {
-				return nil, err
+			if err := fn(seg); err != nil {
+				bfp.Abort()
+				return err
 			}
+			seg = nil
+		} else if ab != nil {
 			seg.AccountBlocks = append(seg.AccountBlocks, ab)
 		}
 	}
 
 	if err := bfp.Error(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if seg != nil {
-		segList = append(segList, seg)
+		return fn(seg)
 	}
 
-	return segList, nil
+	return nil
 }
 
 func (bDB *BlockDB) GetNextLocation(location *chain_file_manager.Location) (*chain_file_manager.Location, error) {
@@ -303,26 +346,19 @@ func (bDB *BlockDB) PrepareRollback(location *chain_file_manager.Location) ([]*l
 			seg = &ledger.SnapshotChunk{}
 		}
 
-		sBuf, err := snappy.Decode(snappyReadBuffer, buf.Buffer)
+		sb, ab, err := DecodeUnit(snappyReadBuffer, buf.BlockType, buf.Buffer)
 		if err != nil {
-			return nil, err
+			// let the reading goroutine finish
+			for range iterator {
+			}
+			return nil, fmt.Errorf("%w: %s", chain_errors.ErrDBCorrupted, err)
 		}
 
-		if buf.BlockType == BlockTypeSnapshotBlock {
-
-			sb := &ledger.SnapshotBlock{}
-			if err := sb.Deserialize(sBuf); err != nil {
-				return nil, err
-			}
+		if sb != nil {
 			seg.SnapshotBlock = sb
 			segList = append(segList, seg)
 			seg = nil
-		} else if buf.BlockType == BlockTypeAccountBlock {
-
-			ab := &ledger.AccountBlock{}
-			if err := ab.Deserialize(sBuf); err != nil {
-				return nil, err
-			}
+		} else if ab != nil {
 			seg.AccountBlocks = append(seg.AccountBlocks, ab)
 		}
 	}
@@ -360,3 +396,16 @@ func (bDB *BlockDB) maxLocation(location *chain_file_manager.Location) *chain_fi
 	}
 	return location
 }
+
+// checkLocation returns ErrLocationOutOfRange if the location is beyond the latest location
+func (bDB *BlockDB) checkLocation(location *chain_file_manager.Location) error {
+	if latestLocation := bDB.fm.LatestLocation(); latestLocation != nil && location.Compare(latestLocation) > 0 {
+		return fmt.Errorf("%w: location is %s, latest location is %s", chain_errors.ErrLocationOutOfRange, location, latestLocation)
+	}
+	return nil
+}
+
+// errCorrupted wraps the error of decoding the unit at the location
+func errCorrupted(location *chain_file_manager.Location, err error) error {
+	return fmt.Errorf("%w: decode the unit at %s failed. Error: %s", chain_errors.ErrDBCorrupted, location, err)
+}
@@ -2,8 +2,15 @@ package chain_block
 
 import (
 	"encoding/binary"
+	"fmt"
+	"sync"
 
+	"github.com/golang/snappy"
 	"github.com/pkg/errors"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
 )
 
 const (
@@ -12,8 +19,48 @@ const (
 	BlockTypeSnapshotBlock = byte(2)
 )
 
+// MaxUnitSize is the max size of a unit in the block files, before and after the snappy decoding
+const MaxUnitSize = chain_file_manager.MaxBufSize
+
 var ClosedErr = errors.New("blockFileParser is closed")
 
+// DecodeUnit decodes a unit of the block files, the unit of the unknown block type is skipped.
+// The units are read from the local block files and the chunks downloaded from the peers.
+func DecodeUnit(dst []byte, blockType byte, buf []byte) (*ledger.SnapshotBlock, *ledger.AccountBlock, error) {
+	sBuf, err := decodeSnappy(dst, buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch blockType {
+	case BlockTypeSnapshotBlock:
+		sb := &ledger.SnapshotBlock{}
+		if err := sb.Deserialize(sBuf); err != nil {
+			return nil, nil, err
+		}
+		return sb, nil, nil
+	case BlockTypeAccountBlock:
+		ab := &ledger.AccountBlock{}
+		if err := ab.Deserialize(sBuf); err != nil {
+			return nil, nil, err
+		}
+		return nil, ab, nil
+	}
+	return nil, nil, nil
+}
+
+// decodeSnappy checks the decoded length before allocating the buffer
+func decodeSnappy(dst []byte, buf []byte) ([]byte, error) {
+	decodedLen, err := snappy.DecodedLen(buf)
+	if err != nil {
+		return nil, err
+	}
+	if decodedLen > MaxUnitSize {
+		return nil, fmt.Errorf("decoded length %d exceeds %d", decodedLen, MaxUnitSize)
+	}
+	return snappy.Decode(dst, buf)
+}
+
 type byteBuffer struct {
 	BlockType byte
 	Buffer    []byte
@@ -33,6 +80,9 @@ type blockFileParser struct {
 
 	bytesBuffer chan *byteBuffer
 
+	stop     chan struct{}
+	stopOnce sync.Once
+
 	closed bool
 	err    error
 }
@@ -41,12 +91,30 @@ func newBlockFileParser() *blockFileParser {
 	bp := &blockFileParser{
 		blockSizeBuffer: make([]byte, 4),
 		bytesBuffer:     make(chan *byteBuffer, 1000),
+		stop:            make(chan struct{}),
 		closed:          false,
 	}
 
 	return bp
 }
 
+// Abort is called by the reader of the iterator which stops reading, the writing returns ClosedErr instead of
+// blocking on the full iterator, so the writing goroutine finishes
+func (bfp *blockFileParser) Abort() {
+	bfp.stopOnce.Do(func() {
+		close(bfp.stop)
+	})
+}
+
+func (bfp *blockFileParser) send(buf *byteBuffer) error {
+	select {
+	case bfp.bytesBuffer <- buf:
+		return nil
+	case <-bfp.stop:
+		return ClosedErr
+	}
+}
+
 func (bfp *blockFileParser) Close() error {
 	if bfp.closed {
 		return ClosedErr
@@ -90,7 +158,13 @@ func (bfp *blockFileParser) Write(buf []byte) error {
 			bfp.blockSizeBufferPointer += readNumbers
 
 			if bfp.blockSizeBufferPointer >= 4 {
-				bfp.blockSize = int64(binary.BigEndian.Uint32(bfp.blockSizeBuffer) - 1)
+				size := binary.BigEndian.Uint32(bfp.blockSizeBuffer)
+				if size == 0 || size > MaxUnitSize {
+					err := fmt.Errorf("%w: invalid unit size %d", chain_errors.ErrDBCorrupted, size)
+					bfp.WriteError(err)
+					return err
+				}
+				bfp.blockSize = int64(size - 1)
 			}
 		} else if bfp.blockType == BlockTypeUnknown {
 
@@ -110,18 +184,22 @@ func (bfp *blockFileParser) Write(buf []byte) error {
 				bfp.blockBufferPointer += int64(restLen)
 			} else {
 				nextPointer := readPointer + readNumbers
+				var err error
 				if len(bfp.blockBuffer) <= 0 {
-					bfp.bytesBuffer <- &byteBuffer{
+					err = bfp.send(&byteBuffer{
 						BlockType: bfp.blockType,
 						Buffer:    buf[readPointer:nextPointer],
 						Size:      bfp.blockSize + 5,
-					}
+					})
 				} else {
-					bfp.bytesBuffer <- &byteBuffer{
+					err = bfp.send(&byteBuffer{
 						BlockType: bfp.blockType,
 						Buffer:    append(bfp.blockBuffer, buf[readPointer:nextPointer]...),
 						Size:      bfp.blockSize + 5,
-					}
+					})
+				}
+				if err != nil {
+					return err
 				}
 
 				readPointer = nextPointer
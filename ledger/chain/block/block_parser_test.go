@@ -0,0 +1,33 @@
+package chain_block
+
+import (
+	"testing"
+)
+
+func TestBlockFileParser_InvalidSize(t *testing.T) {
+	for _, data := range [][]byte{
+		{0, 0, 0, 0, BlockTypeAccountBlock},
+		{0xff, 0xff, 0xff, 0xff, BlockTypeAccountBlock},
+	} {
+		bfp := newBlockFileParser()
+		if err := bfp.Write(data); err == nil {
+			t.Fatalf("%v should fail", data)
+		}
+		// the parser is closed
+		for range bfp.Iterator() {
+		}
+		if bfp.Error() == nil {
+			t.Fatalf("error of %v is not set", data)
+		}
+	}
+}
+
+func TestDecodeUnit(t *testing.T) {
+	// the snappy header claims 4GB
+	if _, _, err := DecodeUnit(nil, BlockTypeSnapshotBlock, []byte{0xff, 0xff, 0xff, 0xff, 0x0f}); err == nil {
+		t.Fatal("the huge decoded length should fail")
+	}
+	if sb, ab, err := DecodeUnit(nil, BlockTypeUnknown, []byte{0}); err != nil || sb != nil || ab != nil {
+		t.Fatal("the unit of the unknown block type should be skipped", err)
+	}
+}
@@ -0,0 +1,54 @@
+// +build gofuzz
+
+package chain_block
+
+import (
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// FuzzUnit is the go-fuzz entry of a unit of the block files, the first byte is the block type
+func FuzzUnit(data []byte) int {
+	if len(data) <= 0 {
+		return 0
+	}
+	sb, ab, err := DecodeUnit(nil, data[0], data[1:])
+	if err != nil || (sb == nil && ab == nil) {
+		return 0
+	}
+	return 1
+}
+
+// FuzzChunk is the go-fuzz entry of the framing of the block files read by ReadChunk and ReadRange, the data is
+// written to the parser in two parts to cover the units across the files.
+func FuzzChunk(data []byte) int {
+	bfp := newBlockFileParser()
+	go func() {
+		half := len(data) / 2
+		if bfp.Write(data[:half]) == nil && bfp.Write(data[half:]) == nil {
+			bfp.Close()
+		}
+	}()
+
+	var chunks []*ledger.SnapshotChunk
+	chunk := &ledger.SnapshotChunk{}
+	iterator := bfp.Iterator()
+	for buf := range iterator {
+		sb, ab, err := DecodeUnit(nil, buf.BlockType, buf.Buffer)
+		if err != nil {
+			for range iterator {
+			}
+			return 0
+		}
+		if sb != nil {
+			chunk.SnapshotBlock = sb
+			chunks = append(chunks, chunk)
+			chunk = &ledger.SnapshotChunk{}
+		} else if ab != nil {
+			chunk.AccountBlocks = append(chunk.AccountBlocks, ab)
+		}
+	}
+	if bfp.Error() != nil || len(chunks) == 0 {
+		return 0
+	}
+	return 1
+}
@@ -1,8 +1,6 @@
 package chain_block
 
 import (
-	"fmt"
-
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
 )
@@ -18,7 +16,7 @@ func (bDB *BlockDB) GetSnapshotBlock(location *chain_file_manager.Location) (*le
 	}
 	sb := &ledger.SnapshotBlock{}
 	if err := sb.Deserialize(buf); err != nil {
-		return nil, fmt.Errorf("sb.Deserialize failed, Error: %s", err.Error())
+		return nil, errCorrupted(location, err)
 	}
 
 	return sb, nil
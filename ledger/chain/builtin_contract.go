@@ -93,6 +93,17 @@ func (c *chain) GetStakeBeneficialAmount(addr types.Address) (*big.Int, error) {
 	return abi.GetStakeBeneficialAmount(sd, addr)
 }
 
+func (c *chain) GetStakeBeneficialAmountInSnapshot(addr types.Address, snapshotHash types.Hash) (*big.Int, error) {
+	sd, err := c.stateDB.NewStorageDatabase(snapshotHash, types.AddressQuota)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.NewStorageDatabase failed, snapshotHash is %s. Error: %w", snapshotHash, err)
+		c.log.Error(cErr.Error(), "method", "GetStakeBeneficialAmountInSnapshot")
+		return nil, cErr
+	}
+
+	return abi.GetStakeBeneficialAmount(sd, addr)
+}
+
 // total
 func (c *chain) GetStakeQuota(addr types.Address) (*big.Int, *types.Quota, error) {
 
@@ -125,7 +136,7 @@ func (c *chain) GetStakeQuotas(addrList []types.Address) (map[types.Address]*typ
 	for _, addr := range addrList {
 		amount, err := abi.GetStakeBeneficialAmount(sd, addr)
 		if err != nil {
-			cErr := fmt.Errorf("abi.GetStakeBeneficialAmount failed, Addr is %s. Error: %s", addr, err)
+			cErr := fmt.Errorf("abi.GetStakeBeneficialAmount failed, Addr is %s. Error: %w", addr, err)
 			c.log.Error(cErr.Error(), "method", "GetStakeQuotas")
 			return nil, err
 		}
@@ -133,7 +144,7 @@ func (c *chain) GetStakeQuotas(addrList []types.Address) (map[types.Address]*typ
 		vmDb := vm_db.NewVmDbByAddr(c, &addr)
 		quota, err := quota.GetQuota(vmDb, addr, amount, sb.Height)
 		if err != nil {
-			cErr := fmt.Errorf("quota.GetQuota failed. Error: %s", err)
+			cErr := fmt.Errorf("quota.GetQuota failed. Error: %w", err)
 			c.log.Error(cErr.Error(), "method", "GetStakeQuotas")
 			return nil, err
 		}
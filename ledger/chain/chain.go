@@ -17,6 +17,8 @@ import (
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
 	chain_cache "github.com/vitelabs/go-vite/v2/ledger/chain/cache"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
 	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
 	chain_genesis "github.com/vitelabs/go-vite/v2/ledger/chain/genesis"
 	chain_index "github.com/vitelabs/go-vite/v2/ledger/chain/index"
@@ -24,6 +26,7 @@ import (
 	chain_state "github.com/vitelabs/go-vite/v2/ledger/chain/state"
 	"github.com/vitelabs/go-vite/v2/ledger/chain/sync_cache"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
 )
 
 const (
@@ -31,6 +34,14 @@ const (
 	start = 1
 )
 
+const storageMetricsCollector = "chain_storage"
+
+// the number of the latest snapshot blocks whose accounts are preloaded into the state db cache
+const defaultWarmUpBlocks = 3600
+
+// the batches of a flush larger than it are written to leveldb in sub-batches, busy heights can build batches of tens of MB
+const defaultMaxBatchSize = 8 * 1024 * 1024
+
 type chain struct {
 	genesisCfg *config.Genesis
 	chainCfg   *config.Chain
@@ -45,7 +56,8 @@ type chain struct {
 
 	log log15.Logger
 
-	em *eventManager
+	em  *eventManager
+	bus *eventBus
 	// memory cache for query
 	cache *chain_cache.Cache
 
@@ -64,9 +76,18 @@ type chain struct {
 
 	flushMu sync.RWMutex
 
+	// one backup at a time
+	backupMu sync.Mutex
+
 	plugins *chain_plugins.Plugins
 
 	status uint32
+
+	// the history before it is pruned
+	prunedHeight uint64
+
+	compactor       *compactor
+	retentionPruner *retentionPruner
 }
 
 /*
@@ -87,9 +108,14 @@ func NewChain(dir string, chainCfg *config.Chain, genesisCfg *config.Genesis) *c
 		log: log15.New("module", "chain"),
 
 		chainCfg: chainCfg,
+
+		compactor:       &compactor{},
+		retentionPruner: &retentionPruner{},
 	}
 
 	c.em = newEventManager(c)
+	c.bus = newEventBus()
+	c.em.Register(c.bus)
 	// risk
 	chain_genesis.UpdateDexFundOwner(genesisCfg)
 	c.genesisAccountBlocks = chain_genesis.NewGenesisAccountBlocks(genesisCfg)
@@ -115,6 +141,18 @@ func (c *chain) Init() error {
 		return err
 	}
 
+	// the index db is incomplete until the rebuilding is finished
+	if checkpoint, err := c.queryRebuildIndexCheckpoint(); err != nil {
+		return err
+	} else if checkpoint != nil {
+		return fmt.Errorf("rebuilding indexes stopped at snapshot height %d, run `gvite ledger rebuild-index` to resume", checkpoint.SnapshotHeight)
+	}
+
+	// check the ledger mode
+	if err := c.checkMode(); err != nil {
+		return err
+	}
+
 	// check ledger
 	status, err := c.checkAndInitData()
 	if err != nil {
@@ -132,16 +170,49 @@ func (c *chain) Init() error {
 		return err
 	}
 
+	// complete the rollback interrupted by a crash
+	if err := c.completeRollback(); err != nil {
+		cErr := fmt.Errorf("c.completeRollback failed. Error: %w", err)
+		c.log.Error(cErr.Error(), "method", "Init")
+		return cErr
+	}
+
 	// check fork points and rollback
 	if err := c.checkForkPoints(); err != nil {
 		return err
 	}
 
+	// warm up the state db cache
+	if c.chainCfg.StateCacheWarmUp {
+		if err := c.warmUpStateCache(); err != nil {
+			cErr := fmt.Errorf("c.warmUpStateCache failed. Error: %w", err)
+			c.log.Error(cErr.Error(), "method", "Init")
+			return cErr
+		}
+	}
+
 	// reconstruct the plugins
 	/*	if c.chainCfg.OpenPlugins {
 			c.plugins.BuildPluginsDb(c.flusher)
 		}
 	*/
+
+	// backfill the plugins enabled for the first time
+	if c.chainCfg.OpenPlugins {
+		if err := c.plugins.Backfill(); err != nil {
+			cErr := fmt.Errorf("c.plugins.Backfill failed. Error: %w", err)
+			c.log.Error(cErr.Error(), "method", "Init")
+			return cErr
+		}
+	}
+
+	// prune the history out of the retention
+	if err := c.pruneByRetention(); err != nil {
+		cErr := fmt.Errorf("c.pruneByRetention failed. Error: %w", err)
+		c.log.Error(cErr.Error(), "method", "Init")
+		return cErr
+	}
+
 	c.log.Info("Complete initialization", "method", "Init")
 
 	return nil
@@ -155,14 +226,44 @@ func (c *chain) Start() error {
 	c.flusher.Start()
 	c.log.Info("Start flusher", "method", "Start")
 
+	if err := c.startCompactSchedule(); err != nil {
+		return err
+	}
+	c.startRetentionPrune()
+
+	monitor.RegisterCollector(storageMetricsCollector, c.reportStorageMetrics)
+	return nil
+}
+
+// ReloadConfig applies the vm log white list and the cache sizes
+func (c *chain) ReloadConfig(cfg *config.Reloadable) error {
+	c.stateDB.SetVmLogWhiteList(cfg.VmLogWhiteList)
+	if cfg.AccountCacheSize > 0 {
+		c.indexDB.ResizeAccountCache(cfg.AccountCacheSize)
+	}
+	if cfg.StateCacheSize > 0 {
+		c.stateDB.ResizeCache(cfg.StateCacheSize)
+	}
 	return nil
 }
 
+func (c *chain) reportStorageMetrics() {
+	c.indexDB.Store().ReportMetrics()
+	c.stateDB.Store().ReportMetrics()
+	c.stateDB.RedoStore().ReportMetrics()
+	c.stateDB.ReportCacheMetrics()
+}
+
 func (c *chain) Stop() error {
 	if !atomic.CompareAndSwapUint32(&c.status, start, stop) {
 		return nil
 	}
 
+	monitor.UnregisterCollector(storageMetricsCollector)
+
+	c.stopRetentionPrune()
+	c.stopCompactSchedule()
+
 	c.flusher.Stop()
 
 	c.log.Info("Stop flusher", "method", "Stop")
@@ -176,7 +277,7 @@ func (c *chain) Destroy() error {
 	c.log.Info("Close cache", "method", "Close")
 
 	if err := c.stateDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.stateDB.Close failed, error is %s", err)
+		cErr := fmt.Errorf("c.stateDB.Close failed, error is %w", err)
 		c.log.Error(cErr.Error(), "method", "Close")
 		return cErr
 	}
@@ -184,25 +285,35 @@ func (c *chain) Destroy() error {
 	c.log.Info("Close stateDB", "method", "Close")
 
 	if err := c.indexDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.indexDB.Close failed, error is %s", err)
+		cErr := fmt.Errorf("c.indexDB.Close failed, error is %w", err)
 		c.log.Error(cErr.Error(), "method", "Close")
 		return cErr
 	}
 	c.log.Info("Close indexDB", "method", "Close")
 
 	if err := c.blockDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.blockDB.Close failed, error is %s", err)
+		cErr := fmt.Errorf("c.blockDB.Close failed, error is %w", err)
 		c.log.Error(cErr.Error(), "method", "Close")
 		return cErr
 	}
 	c.log.Info("Close blockDB", "method", "Close")
 
-	if err := c.syncCache.Close(); err != nil {
-		cErr := fmt.Errorf("c.syncCache.Close failed, error is %s", err)
+	// the sync cache is not opened if the chain is not initialized, e.g. after RebuildIndexes
+	if c.syncCache != nil {
+		if err := c.syncCache.Close(); err != nil {
+			cErr := fmt.Errorf("c.syncCache.Close failed, error is %w", err)
+			c.log.Error(cErr.Error(), "method", "Close")
+			return cErr
+		}
+		c.log.Info("Close syncCache", "method", "Close")
+	}
+
+	if err := c.metaDB.Close(); err != nil {
+		cErr := fmt.Errorf("c.metaDB.Close failed, error is %w", err)
 		c.log.Error(cErr.Error(), "method", "Close")
 		return cErr
 	}
-	c.log.Info("Close syncCache", "method", "Close")
+	c.log.Info("Close metaDB", "method", "Close")
 
 	c.flusher = nil
 	c.cache = nil
@@ -210,6 +321,7 @@ func (c *chain) Destroy() error {
 	c.indexDB = nil
 	c.blockDB = nil
 	c.syncCache = nil
+	c.metaDB = nil
 
 	c.log.Info("Complete destruction", "method", "Close")
 
@@ -254,16 +366,24 @@ func (c *chain) newDbAndRecover() error {
 		c.log.Error(fmt.Sprintf("chain_index.NewIndexDB failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
 		return err
 	}
+	if c.chainCfg.AccountCacheSize > 0 {
+		c.indexDB.ResizeAccountCache(c.chainCfg.AccountCacheSize)
+	}
 
 	// new block db
-	if c.blockDB, err = chain_block.NewBlockDB(c.chainDir); err != nil {
+	if c.blockDB, err = chain_block.NewBlockDBWithOptions(c.chainDir, chain_block.FixFileSize, chain_file_manager.Options{
+		SyncMode:     chain_file_manager.SyncMode(c.chainCfg.BlockFileSyncMode),
+		SyncInterval: c.chainCfg.BlockFileSyncInterval,
+		Preallocate:  c.chainCfg.BlockFilePreallocate,
+		DSync:        c.chainCfg.BlockFileDSync,
+	}); err != nil {
 		c.log.Error(fmt.Sprintf("chain_block.NewBlockDB failed, error is %s, chainDir is %s", err, c.chainDir), "method", "newDbAndRecover")
 		return err
 	}
 
 	// new state db
 	if c.stateDB, err = chain_state.NewStateDB(c, c.chainCfg, c.chainDir); err != nil {
-		cErr := fmt.Errorf("chain_cache.NewStateDB failed, error is %s", err)
+		cErr := fmt.Errorf("chain_cache.NewStateDB failed, error is %w", err)
 
 		c.log.Error(cErr.Error(), "method", "newDbAndRecover")
 		return err
@@ -273,34 +393,43 @@ func (c *chain) newDbAndRecover() error {
 	if c.chainCfg.OpenPlugins {
 		var err error
 		if c.plugins, err = chain_plugins.NewPlugins(c.chainDir, c); err != nil {
-			cErr := fmt.Errorf("chain_plugins.NewPlugins failed. Error: %s", err)
+			cErr := fmt.Errorf("chain_plugins.NewPlugins failed. Error: %w", err)
 			c.log.Error(cErr.Error(), "method", "newDbAndRecover")
 			return cErr
 		}
 		c.Register(c.plugins)
 	}
 
+	// split the oversized batches of the flushes
+	maxBatchSize := c.chainCfg.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultMaxBatchSize
+	}
+	for _, store := range []*chain_db.Store{c.stateDB.Store(), c.stateDB.RedoStore(), c.indexDB.Store()} {
+		store.SetMaxBatchSize(maxBatchSize)
+	}
+
 	// new flusher
 	stores := []chain_flusher.Storage{c.blockDB, c.stateDB.Store(), c.stateDB.RedoStore(), c.indexDB.Store()}
 	if c.chainCfg.OpenPlugins {
 		stores = append(stores, c.plugins.Store())
 	}
 	if c.flusher, err = chain_flusher.NewFlusher(stores, &c.flushMu, c.chainDir); err != nil {
-		cErr := fmt.Errorf("chain_flusher.NewFlusher failed. Error: %s", err)
+		cErr := fmt.Errorf("chain_flusher.NewFlusher failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "newDbAndRecover")
 		return cErr
 	}
 
 	// flusher check and recover
 	if err := c.flusher.Recover(); err != nil {
-		cErr := fmt.Errorf("c.flusher.Recover failed. Error: %s", err)
+		cErr := fmt.Errorf("c.flusher.Recover failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "newDbAndRecover")
 		return cErr
 	}
 
 	// new cache
 	if c.cache, err = chain_cache.NewCache(c); err != nil {
-		cErr := fmt.Errorf("chain_cache.NewCache failed, error is %s", err)
+		cErr := fmt.Errorf("chain_cache.NewCache failed, error is %w", err)
 
 		c.log.Error(cErr.Error(), "method", "checkAndInitData")
 		return cErr
@@ -313,7 +442,7 @@ func (c *chain) checkAndInitData() (byte, error) {
 	// check ledger
 	status, err := chain_genesis.CheckLedger(c, c.genesisSnapshotBlock, c.genesisAccountBlocks)
 	if err != nil {
-		cErr := fmt.Errorf("chain_genesis.CheckLedger failed, error is %s, chainDir is %s", err, c.chainDir)
+		cErr := fmt.Errorf("chain_genesis.CheckLedger failed, error is %w, chainDir is %s", err, c.chainDir)
 
 		c.log.Error(cErr.Error(), "method", "checkAndInitData")
 		return status, err
@@ -325,7 +454,7 @@ func (c *chain) checkAndInitData() (byte, error) {
 
 	if status == chain_genesis.LedgerEmpty {
 		if err = chain_genesis.InitLedger(c, c.genesisSnapshotBlock, c.genesisAccountBlocks); err != nil {
-			cErr := fmt.Errorf("chain_genesis.InitLedger failed, error is %s", err)
+			cErr := fmt.Errorf("chain_genesis.InitLedger failed, error is %w", err)
 			c.log.Error(cErr.Error(), "method", "checkAndInitData")
 			return chain_genesis.LedgerInvalid, err
 		}
@@ -367,25 +496,72 @@ func (c *chain) checkForkPoints() error {
 	return nil
 }
 
+// warmUpStateCache preloads the state db cache with the accounts in the latest snapshot blocks
+func (c *chain) warmUpStateCache() error {
+	count := c.chainCfg.StateCacheWarmUpBlocks
+	if count <= 0 {
+		count = defaultWarmUpBlocks
+	}
+	latest := c.GetLatestSnapshotBlock()
+	if count > latest.Height {
+		count = latest.Height
+	}
+
+	var hotAddrs []types.Address
+	addrSet := make(map[types.Address]struct{})
+	for height := latest.Height; count > 0; {
+		batch := uint64(100)
+		if batch > count {
+			batch = count
+		}
+		blocks, err := c.GetSnapshotBlocksByHeight(height, false, batch)
+		if err != nil {
+			return err
+		}
+		if len(blocks) <= 0 {
+			break
+		}
+		lowest := height
+		for _, block := range blocks {
+			for addr := range block.SnapshotContent {
+				if _, ok := addrSet[addr]; !ok {
+					addrSet[addr] = struct{}{}
+					hotAddrs = append(hotAddrs, addr)
+				}
+			}
+			if block.Height < lowest {
+				lowest = block.Height
+			}
+		}
+		count -= uint64(len(blocks))
+		if lowest <= 1 {
+			break
+		}
+		height = lowest - 1
+	}
+
+	return c.stateDB.WarmUp(hotAddrs)
+}
+
 func (c *chain) initCache() error {
 
 	// init cache
 	if err := c.cache.Init(); err != nil {
-		cErr := fmt.Errorf("c.cache.Init failed. Error: %s", err)
+		cErr := fmt.Errorf("c.cache.Init failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "initCache")
 		return cErr
 	}
 
 	// init state db cache
 	if err := c.stateDB.Init(); err != nil {
-		cErr := fmt.Errorf("c.stateDB.Init failed. Error: %s", err)
+		cErr := fmt.Errorf("c.stateDB.Init failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "initCache")
 		return cErr
 	}
 
 	// init index db cache
 	if err := c.indexDB.Init(c); err != nil {
-		cErr := fmt.Errorf("c.indexDB.Init failed. Error: %s", err)
+		cErr := fmt.Errorf("c.indexDB.Init failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "initCache")
 		return cErr
 	}
@@ -394,7 +570,7 @@ func (c *chain) initCache() error {
 	var err error
 	c.syncCache, err = sync_cache.NewSyncCache(path.Join(c.chainDir, "sync_cache"))
 	if err != nil {
-		cErr := fmt.Errorf("sync_cache.NewSyncCache failed. Error: %s", err)
+		cErr := fmt.Errorf("sync_cache.NewSyncCache failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "initCache")
 		return cErr
 	}
@@ -406,7 +582,7 @@ func (c *chain) closeAndCleanData() error {
 	var err error
 	// close blockDB
 	if err = c.blockDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.blockDB.Close failed. Error: %s", err)
+		cErr := fmt.Errorf("c.blockDB.Close failed. Error: %w", err)
 
 		c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 		return err
@@ -414,7 +590,7 @@ func (c *chain) closeAndCleanData() error {
 
 	// close indexDB
 	if err = c.indexDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.indexDB.Close failed. Error: %s", err)
+		cErr := fmt.Errorf("c.indexDB.Close failed. Error: %w", err)
 
 		c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 		return err
@@ -422,7 +598,7 @@ func (c *chain) closeAndCleanData() error {
 
 	// close stateDB
 	if err = c.stateDB.Close(); err != nil {
-		cErr := fmt.Errorf("c.stateDB.Close failed. Error: %s", err)
+		cErr := fmt.Errorf("c.stateDB.Close failed. Error: %w", err)
 
 		c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 		return err
@@ -430,7 +606,7 @@ func (c *chain) closeAndCleanData() error {
 
 	// close flusher
 	if err = c.flusher.Close(); err != nil {
-		cErr := fmt.Errorf("c.flusher.Close failed. Error: %s", err)
+		cErr := fmt.Errorf("c.flusher.Close failed. Error: %w", err)
 
 		c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 		return err
@@ -439,7 +615,7 @@ func (c *chain) closeAndCleanData() error {
 	// close plugins
 	if c.chainCfg.OpenPlugins {
 		if err = c.plugins.Close(); err != nil {
-			cErr := fmt.Errorf("c.plugins.Close failed. Error: %s", err)
+			cErr := fmt.Errorf("c.plugins.Close failed. Error: %w", err)
 
 			c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 			return err
@@ -448,7 +624,7 @@ func (c *chain) closeAndCleanData() error {
 
 	// clean all data
 	if err = c.cleanAllData(); err != nil {
-		cErr := fmt.Errorf("c.cleanAllData failed. Error: %s", err)
+		cErr := fmt.Errorf("c.cleanAllData failed. Error: %w", err)
 
 		c.log.Error(cErr.Error(), "method", "closeAndCleanData")
 		return err
@@ -297,6 +297,59 @@ func TestCheckHash(t *testing.T) {
 	}
 }
 
+func TestVerifyLedger(t *testing.T) {
+	chainInstance, _, _ := SetUp(10, 100, 5)
+	defer TearDown(chainInstance)
+
+	for _, full := range []bool{false, true} {
+		report, err := chainInstance.VerifyLedger(full)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if report.IssueCount > 0 {
+			t.Fatalf("full: %t, %d issues: %v", full, report.IssueCount, report.Issues)
+		}
+		if report.SnapshotBlocks != chainInstance.GetLatestSnapshotBlock().Height || report.AccountBlocks == 0 {
+			t.Fatalf("full: %t, wrong report %+v", full, report)
+		}
+	}
+}
+
+func TestRebuildIndexes(t *testing.T) {
+	chainInstance, _, _ := SetUp(10, 100, 5)
+	latest := chainInstance.GetLatestSnapshotBlock()
+	TearDown(chainInstance)
+
+	rebuilt := NewChain(chainInstance.dataDir, &config.Chain{}, chainInstance.genesisCfg)
+	var last *RebuildIndexProgress
+	if err := rebuilt.RebuildIndexes(func(p *RebuildIndexProgress) {
+		last = p
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if last == nil || !last.Finished || last.SnapshotHeight != latest.Height {
+		t.Fatalf("wrong progress %+v, latest height is %d", last, latest.Height)
+	}
+	rebuilt.Destroy()
+
+	chainInstance, err := NewChainInstance(chainInstance.dataDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer TearDown(chainInstance)
+
+	if chainInstance.GetLatestSnapshotBlock().Hash != latest.Hash {
+		t.Fatalf("latest snapshot block is %s, expected %s", chainInstance.GetLatestSnapshotBlock().Hash, latest.Hash)
+	}
+	report, err := chainInstance.VerifyLedger(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.IssueCount > 0 {
+		t.Fatalf("%d issues: %v", report.IssueCount, report.Issues)
+	}
+}
+
 func TestCheckHash2(t *testing.T) {
 	chainInstance, _, _ := SetUp(0, 0, 0)
 	hash, err := types.HexToHash("3cc090aaaa241b3ff480cd461a1fb220fd429717855b5c990d1cb34dd1cef6c1")
@@ -79,7 +79,7 @@ func (c *chain) CheckRecentBlocks() error {
 	c.log.Info(fmt.Sprintf("latest snapshot block is %d, %s", latestSb.Height, latestSb.Hash), "method", "checkRecentBlocks")
 	sbList, err := c.GetSnapshotBlocks(latestSb.Hash, false, 100)
 	if err != nil {
-		cErr := fmt.Errorf("c.GetSnapshotBlocks failed. Error: %s", err)
+		cErr := fmt.Errorf("c.GetSnapshotBlocks failed. Error: %w", err)
 		return cErr
 	}
 
@@ -99,7 +99,7 @@ func (c *chain) CheckRecentBlocks() error {
 
 			block, err := c.GetAccountBlockByHash(hashHeight.Hash)
 			if err != nil {
-				return fmt.Errorf("c.GetAccountBlockByHash failed, addr is %s, hash is %s. Error: %s", addr, hashHeight.Hash, err)
+				return fmt.Errorf("c.GetAccountBlockByHash failed, addr is %s, hash is %s. Error: %w", addr, hashHeight.Hash, err)
 			}
 
 			for {
@@ -113,7 +113,7 @@ func (c *chain) CheckRecentBlocks() error {
 				if !ok {
 					latestBlock, err := c.GetLatestAccountBlock(addr)
 					if err != nil {
-						return fmt.Errorf("c.GetLatestAccountBlock failed, addr is %s. Error: %s", addr, err)
+						return fmt.Errorf("c.GetLatestAccountBlock failed, addr is %s. Error: %w", addr, err)
 					}
 
 					if latestBlock == nil {
@@ -137,7 +137,7 @@ func (c *chain) CheckRecentBlocks() error {
 
 				confirmedSb, err := c.GetConfirmSnapshotBlockByAbHash(block.PrevHash)
 				if err != nil {
-					return fmt.Errorf("GetConfirmSnapshotBlockByAbHash failed, addr is %s, prevHash is %s. Error: %s", addr, block.PrevHash, err)
+					return fmt.Errorf("GetConfirmSnapshotBlockByAbHash failed, addr is %s, prevHash is %s. Error: %w", addr, block.PrevHash, err)
 				}
 
 				if confirmedSb == nil {
@@ -149,7 +149,7 @@ func (c *chain) CheckRecentBlocks() error {
 
 				prevBlock, err := c.GetAccountBlockByHash(block.PrevHash)
 				if err != nil {
-					return fmt.Errorf("get prev account block failed, addr is %s, prevHash is %s. Error: %s", addr, block.PrevHash, err)
+					return fmt.Errorf("get prev account block failed, addr is %s, prevHash is %s. Error: %w", addr, block.PrevHash, err)
 				}
 				if prevBlock == nil {
 					return fmt.Errorf("get prev account block is nil, addr is %s, prevHash is %s. Error: %s", addr, block.PrevHash, err)
@@ -222,12 +222,12 @@ func (c *chain) CheckHash() error {
 		key := iter.Key()
 		hash, err := types.BytesToHash(key[1:])
 		if err != nil {
-			return fmt.Errorf("BytesToHash failed, key is %d. Error: %s", key, err)
+			return fmt.Errorf("BytesToHash failed, key is %d. Error: %w", key, err)
 		}
 
 		block, err := c.GetAccountBlockByHash(hash)
 		if err != nil {
-			return fmt.Errorf("c.GetAccountBlockByHash failed, hash is %s. Error: %s", hash, err)
+			return fmt.Errorf("c.GetAccountBlockByHash failed, hash is %s. Error: %w", hash, err)
 		}
 
 		if block == nil {
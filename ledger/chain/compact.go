@@ -0,0 +1,204 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+// every db is compacted in ranges split by the first byte of the keys, so the progress can be reported
+const compactRangesPerDb = 16
+
+// CompactDbNames are the dbs which can be compacted
+var CompactDbNames = []string{"index", "state", "redo"}
+
+// CompactProgress is the progress of the compaction of the dbs
+type CompactProgress struct {
+	Running   bool      `json:"running"`
+	Scheduled bool      `json:"scheduled"` // started by the daily schedule
+	Dbs       []string  `json:"dbs"`
+	Db        string    `json:"db"` // the db being compacted
+	Done      int       `json:"done"`
+	Total     int       `json:"total"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type compactor struct {
+	mu       sync.Mutex
+	progress CompactProgress
+
+	terminal chan struct{}
+	wg       sync.WaitGroup
+}
+
+func (c *chain) compactStore(name string) *chain_db.Store {
+	switch name {
+	case "index":
+		return c.indexDB.Store()
+	case "state":
+		return c.stateDB.Store()
+	case "redo":
+		return c.stateDB.RedoStore()
+	}
+	return nil
+}
+
+// CompactDbs starts to compact the dbs in the background, all of them are compacted if dbs is empty.
+// The read amplification after the heavy deletions, e.g. rolling back or pruning, is relieved without restarting.
+func (c *chain) CompactDbs(dbs []string) error {
+	return c.compactDbs(dbs, false)
+}
+
+// CompactProgress returns the progress of the running or the last compaction
+func (c *chain) CompactProgress() CompactProgress {
+	c.compactor.mu.Lock()
+	defer c.compactor.mu.Unlock()
+
+	progress := c.compactor.progress
+	progress.Dbs = append([]string(nil), progress.Dbs...)
+	return progress
+}
+
+func (c *chain) compactDbs(dbs []string, scheduled bool) error {
+	if len(dbs) == 0 {
+		dbs = CompactDbNames
+	}
+	for _, name := range dbs {
+		if c.compactStore(name) == nil {
+			return fmt.Errorf("unknown db %s, the dbs are %v", name, CompactDbNames)
+		}
+	}
+
+	cp := c.compactor
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.progress.Running {
+		return errors.New("the compaction is running")
+	}
+	cp.progress = CompactProgress{
+		Running:   true,
+		Scheduled: scheduled,
+		Dbs:       append([]string(nil), dbs...),
+		Total:     len(dbs) * compactRangesPerDb,
+		StartTime: time.Now(),
+	}
+
+	go c.compact(dbs)
+	return nil
+}
+
+func (c *chain) compact(dbs []string) {
+	cp := c.compactor
+	c.log.Info(fmt.Sprintf("start to compact %v", dbs), "method", "compact")
+
+	var err error
+	for _, name := range dbs {
+		cp.mu.Lock()
+		cp.progress.Db = name
+		cp.mu.Unlock()
+
+		store := c.compactStore(name)
+		for i := 0; i < compactRangesPerDb; i++ {
+			if err = store.CompactRange(compactRange(i)); err != nil {
+				err = fmt.Errorf("compact %s failed. Error: %w", name, err)
+				break
+			}
+			cp.mu.Lock()
+			cp.progress.Done++
+			cp.mu.Unlock()
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.progress.Running = false
+	cp.progress.Db = ""
+	cp.progress.EndTime = time.Now()
+	if err != nil {
+		cp.progress.Error = err.Error()
+		c.log.Error(err.Error(), "method", "compact")
+		return
+	}
+	c.log.Info(fmt.Sprintf("compact %v finished, elapsed %s", dbs, cp.progress.EndTime.Sub(cp.progress.StartTime)), "method", "compact")
+}
+
+// compactRange returns the i-th range of the keys split by the first byte, the first range starts from the empty key
+// and the last range has no limit
+func compactRange(i int) util.Range {
+	step := 256 / compactRangesPerDb
+	r := util.Range{}
+	if i > 0 {
+		r.Start = []byte{byte(i * step)}
+	}
+	if i < compactRangesPerDb-1 {
+		r.Limit = []byte{byte((i + 1) * step)}
+	}
+	return r
+}
+
+// parseCompactAt parses the "HH:MM" of the daily compaction
+func parseCompactAt(at string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid compaction time %q, it should be HH:MM", at)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// nextCompactTime returns the next time of the daily compaction after now, in the location of now
+func nextCompactTime(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// startCompactSchedule compacts all the dbs at CompactAt every day, it's an off-peak time chosen by the operator
+func (c *chain) startCompactSchedule() error {
+	if c.chainCfg.CompactAt == "" {
+		return nil
+	}
+	hour, minute, err := parseCompactAt(c.chainCfg.CompactAt)
+	if err != nil {
+		return err
+	}
+
+	cp := c.compactor
+	cp.terminal = make(chan struct{})
+	cp.wg.Add(1)
+	go func() {
+		defer cp.wg.Done()
+		for {
+			timer := time.NewTimer(time.Until(nextCompactTime(time.Now(), hour, minute)))
+			select {
+			case <-cp.terminal:
+				timer.Stop()
+				return
+			case <-timer.C:
+				if err := c.compactDbs(nil, true); err != nil {
+					c.log.Warn(fmt.Sprintf("scheduled compaction is skipped. Error: %s", err), "method", "startCompactSchedule")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *chain) stopCompactSchedule() {
+	cp := c.compactor
+	if cp.terminal != nil {
+		close(cp.terminal)
+		cp.wg.Wait()
+		cp.terminal = nil
+	}
+}
@@ -0,0 +1,40 @@
+package chain
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestCompactRange(t *testing.T) {
+	var prevLimit []byte
+	for i := 0; i < compactRangesPerDb; i++ {
+		r := compactRange(i)
+		if !bytes.Equal(r.Start, prevLimit) {
+			t.Fatalf("range %d starts from %v, expected %v", i, r.Start, prevLimit)
+		}
+		prevLimit = r.Limit
+	}
+	if prevLimit != nil {
+		t.Fatal("the last range has a limit")
+	}
+}
+
+func TestNextCompactTime(t *testing.T) {
+	hour, minute, err := parseCompactAt("03:30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := parseCompactAt("25:00"); err == nil {
+		t.Fatal("expected invalid time error")
+	}
+
+	now := time.Date(2020, 1, 1, 2, 0, 0, 0, time.UTC)
+	if next := nextCompactTime(now, hour, minute); !next.Equal(time.Date(2020, 1, 1, 3, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next time %s", next)
+	}
+	now = time.Date(2020, 1, 1, 3, 30, 0, 0, time.UTC)
+	if next := nextCompactTime(now, hour, minute); !next.Equal(time.Date(2020, 1, 2, 3, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected next time %s", next)
+	}
+}
@@ -26,19 +26,20 @@ func (store *Store) Prepare() {
 
 	store.flushingBatch = store.snapshotBatch
 
-	store.snapshotBatch = store.getNewBatch()
+	store.snapshotBatch = store.newSplitBatch()
 }
 
 // assume lock write when cancel prepare
 func (store *Store) CancelPrepare() {
 	currentSnapshotBatch := store.snapshotBatch
 
-	store.snapshotBatch = store.getNewBatch()
+	store.snapshotBatch = store.newSplitBatch()
 
-	store.snapshotBatch.Append(store.flushingBatch)
+	store.snapshotBatch.appendSplit(store.flushingBatch)
 
-	store.snapshotBatch.Append(currentSnapshotBatch)
+	store.snapshotBatch.appendSplit(currentSnapshotBatch)
 
+	currentSnapshotBatch.Reset()
 	store.releaseFlushingBatch()
 }
 
@@ -46,9 +47,19 @@ func (store *Store) RedoLog() ([]byte, error) {
 	return store.flushingBatch.Dump(), nil
 }
 
+// Commit writes the sub-batches of the flushing batch in order. The writes are not atomic, if the flush is interrupted
+// after some sub-batches are written, the flusher patches the redo log at startup, it's the dump of all the sub-batches,
+// and the whole batch is written again. The puts and deletes replayed in order are idempotent, so the keys end up the
+// same as the batch written atomically.
 func (store *Store) Commit() error {
-	if err := store.db.Write(store.flushingBatch, nil); err != nil {
-		return err
+	batches := store.flushingBatch.batches
+	for _, batch := range batches {
+		if err := store.writeBatch(batch); err != nil {
+			return err
+		}
+	}
+	if len(batches) > 1 {
+		splitBatchMetric.With(store.name).Add(float64(len(batches)))
 	}
 	return nil
 }
@@ -60,11 +71,7 @@ func (store *Store) PatchRedoLog(redoLog []byte) error {
 		return err
 	}
 
-	if err := store.db.Write(batch, nil); err != nil {
-		return err
-	}
-
-	return nil
+	return store.writeBatch(batch)
 }
 
 // assume lock write when call after commit
@@ -96,6 +103,75 @@ func (store *Store) AfterRecover() {
 	}
 }
 
+// SetMaxBatchSize sets the max size of a batch built by the snapshots and written to leveldb, 0 means no limit
+func (store *Store) SetMaxBatchSize(size int) {
+	store.maxBatchSize = size
+	store.snapshotBatch.maxSize = size
+}
+
+// writeBatch writes the batch to leveldb, the batch larger than maxBatchSize is split into sub-batches written in order,
+// it is a batch appended to the snapshot batch larger than maxBatchSize, or the redo log patched at startup.
+// The writes are not atomic, they are recovered by the redo log the same as Commit.
+func (store *Store) writeBatch(batch *leveldb.Batch) error {
+	if store.maxBatchSize <= 0 || batch.Size() <= store.maxBatchSize {
+		return store.db.Write(batch, nil)
+	}
+
+	splitter := &batchSplitter{
+		store: store,
+		batch: store.getNewBatch(),
+	}
+	defer batchPool.Put(splitter.batch)
+
+	batch.Replay(splitter)
+	splitter.write()
+
+	if splitter.err != nil {
+		return splitter.err
+	}
+	splitBatchMetric.With(store.name).Add(float64(splitter.count))
+	return nil
+}
+
+// batchSplitter replays a batch into sub-batches no larger than the maxBatchSize of the store
+type batchSplitter struct {
+	store *Store
+	batch *leveldb.Batch
+	count int
+	err   error
+}
+
+func (s *batchSplitter) Put(key, value []byte) {
+	if s.err != nil {
+		return
+	}
+	s.batch.Put(key, value)
+	if s.batch.Size() >= s.store.maxBatchSize {
+		s.write()
+	}
+}
+
+func (s *batchSplitter) Delete(key []byte) {
+	if s.err != nil {
+		return
+	}
+	s.batch.Delete(key)
+	if s.batch.Size() >= s.store.maxBatchSize {
+		s.write()
+	}
+}
+
+func (s *batchSplitter) write() {
+	if s.err != nil || s.batch.Len() <= 0 {
+		return
+	}
+	if s.err = s.store.db.Write(s.batch, nil); s.err != nil {
+		return
+	}
+	s.count++
+	s.batch.Reset()
+}
+
 func (store *Store) getNewBatch() *leveldb.Batch {
 	batch := batchPool.Get().(*leveldb.Batch)
 	batch.Reset()
@@ -103,6 +179,6 @@ func (store *Store) getNewBatch() *leveldb.Batch {
 }
 
 func (store *Store) releaseFlushingBatch() {
-	batchPool.Put(store.flushingBatch)
+	store.flushingBatch.Reset()
 	store.flushingBatch = nil
 }
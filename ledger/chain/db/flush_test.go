@@ -234,7 +234,12 @@ func newChecker(batch *leveldb.Batch) *checker {
 	return c
 }
 
-func (c *checker) CheckBatch(b *leveldb.Batch) {
+// replayer is a leveldb batch or a split batch
+type replayer interface {
+	Replay(r leveldb.BatchReplay) error
+}
+
+func (c *checker) CheckBatch(b replayer) {
 	data := c.batchToData(b)
 	if len(data) != len(c.data) {
 		panic("err")
@@ -251,7 +256,7 @@ func (c *checker) CheckBatch(b *leveldb.Batch) {
 
 }
 
-func (c *checker) batchToData(b *leveldb.Batch) [][3][]byte {
+func (c *checker) batchToData(b replayer) [][3][]byte {
 	b.Replay(c)
 	return c.fetchData()
 }
@@ -269,3 +274,139 @@ func (c *checker) Put(key []byte, value []byte) {
 func (c *checker) Delete(key []byte) {
 	c.tmpData = append(c.tmpData, [3][]byte{key, nil, {deleteFlag}})
 }
+
+func TestCommitSplitBatch(t *testing.T) {
+	store, tempDir := newStore(t.Name(), true)
+	defer clearStore(tempDir)
+	defer store.Close()
+
+	store.SetMaxBatchSize(1024)
+
+	batch := store.NewBatch()
+	for i := 0; i < 1000; i++ {
+		batch.Put([]byte(fmt.Sprintf("key%d", i)), bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	// the deletes and the puts of a key are kept in order across the sub-batches
+	for i := 0; i < 1000; i += 2 {
+		batch.Delete([]byte(fmt.Sprintf("key%d", i)))
+	}
+	batch.Put([]byte("key0"), []byte("value0"))
+	store.WriteDirectly(batch)
+
+	store.Prepare()
+	assert.True(t, store.flushingBatch.Size() > 1024)
+	splits := splitBatchMetric.With(store.name).Value()
+	assert.NoError(t, store.Commit())
+	assert.True(t, splitBatchMetric.With(store.name).Value()-splits > 1)
+	store.AfterCommit()
+
+	for i := 0; i < 1000; i++ {
+		value, err := store.Get([]byte(fmt.Sprintf("key%d", i)))
+		assert.NoError(t, err)
+		switch {
+		case i == 0:
+			assert.Equal(t, []byte("value0"), value)
+		case i%2 == 0:
+			assert.Nil(t, value)
+		default:
+			assert.Equal(t, bytes.Repeat([]byte{byte(i)}, 32), value)
+		}
+	}
+}
+
+func TestSplitBatch(t *testing.T) {
+	store, tempDir := newStore(t.Name(), true)
+	defer clearStore(tempDir)
+	defer store.Close()
+
+	store.SetMaxBatchSize(1024)
+
+	var all []*leveldb.Batch
+	for i := 0; i < 100; i++ {
+		batch := store.NewBatch()
+		batch.Put([]byte(fmt.Sprintf("key%d", i)), bytes.Repeat([]byte{byte(i)}, 32))
+		store.WriteDirectly(batch)
+		all = append(all, batch)
+	}
+	// a batch larger than the max size is kept whole
+	large := store.NewBatch()
+	for i := 0; i < 100; i++ {
+		large.Put([]byte(fmt.Sprintf("large%d", i)), bytes.Repeat([]byte{byte(i)}, 32))
+	}
+	store.WriteDirectly(large)
+	all = append(all, large)
+
+	sb := store.snapshotBatch
+	assert.True(t, len(sb.batches) > 1)
+	for _, batch := range sb.batches[:len(sb.batches)-1] {
+		assert.True(t, batch.Size() <= 1024)
+	}
+	assert.Equal(t, large.Len(), sb.batches[len(sb.batches)-1].Len())
+
+	merged := store.NewBatch()
+	for _, batch := range all {
+		merged.Append(batch)
+	}
+	c := newChecker(merged)
+	c.CheckBatch(sb)
+
+	// the dump is loaded as one batch
+	loaded := store.NewBatch()
+	assert.NoError(t, loaded.Load(append([]byte{}, sb.Dump()...)))
+	c.CheckBatch(loaded)
+}
+
+// the sub-batches are written one by one, the flush interrupted after some of them is recovered by the redo log
+func TestRecoverPartialCommit(t *testing.T) {
+	store, tempDir := newStore(t.Name(), true)
+	defer clearStore(tempDir)
+	defer store.Close()
+
+	store.SetMaxBatchSize(1024)
+
+	for i := 0; i < 100; i++ {
+		batch := store.NewBatch()
+		batch.Put([]byte(fmt.Sprintf("key%d", i)), bytes.Repeat([]byte{byte(i)}, 32))
+		store.WriteDirectly(batch)
+	}
+	// the keys put by the first sub-batch are deleted and put again by the later ones
+	batch := store.NewBatch()
+	for i := 0; i < 100; i += 2 {
+		batch.Delete([]byte(fmt.Sprintf("key%d", i)))
+	}
+	batch.Put([]byte("key0"), []byte("value0"))
+	store.WriteDirectly(batch)
+
+	store.Prepare()
+	log, err := store.RedoLog()
+	assert.NoError(t, err)
+	log = append([]byte{}, log...)
+
+	// interrupted after the first sub-batch
+	assert.True(t, len(store.flushingBatch.batches) > 2)
+	assert.NoError(t, store.writeBatch(store.flushingBatch.batches[0]))
+	value, err := store.db.Get([]byte("key0"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, bytes.Repeat([]byte{0}, 32), value)
+
+	// restarted
+	store.flushingBatch.Reset()
+	store.flushingBatch = nil
+	store.BeforeRecover(log)
+	assert.NoError(t, store.PatchRedoLog(log))
+	store.AfterRecover()
+
+	for i := 0; i < 100; i++ {
+		value, err := store.db.Get([]byte(fmt.Sprintf("key%d", i)), nil)
+		switch {
+		case i == 0:
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("value0"), value)
+		case i%2 == 0:
+			assert.Equal(t, leveldb.ErrNotFound, err)
+		default:
+			assert.NoError(t, err)
+			assert.Equal(t, bytes.Repeat([]byte{byte(i)}, 32), value)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package chain_db
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+var (
+	leveldbSizeMetric = monitor.GetOrRegisterGaugeVec("vite_leveldb_size_bytes",
+		"size of the tables of leveldb", "db")
+	leveldbTablesMetric = monitor.GetOrRegisterGaugeVec("vite_leveldb_tables",
+		"number of the tables of leveldb", "db")
+	leveldbCompactionSecondsMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_compaction_seconds_total",
+		"time spent in the compactions of leveldb", "db")
+	leveldbCompactionBytesMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_compaction_bytes_total",
+		"bytes read and written by the compactions of leveldb", "db", "op")
+	leveldbWriteDelayMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_write_delay_total",
+		"number of writes delayed by compactions of leveldb", "db")
+	leveldbWriteDelaySecondsMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_write_delay_seconds_total",
+		"time of writes delayed by compactions of leveldb", "db")
+	leveldbIOBytesMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_io_bytes_total",
+		"bytes read and written by leveldb", "db", "op")
+	leveldbCompactionsMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_compactions_total",
+		"number of the compactions of leveldb", "db")
+	leveldbBlockCacheMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_block_cache_total",
+		"lookups of the block cache of leveldb, partitioned by hit or miss", "db", "result")
+	leveldbFilterMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_filter_total",
+		"lookups checked against the bloom filters of leveldb, partitioned by whether the filter ruled out the key", "db", "result")
+	leveldbOpenedTablesMetric = monitor.GetOrRegisterGaugeVec("vite_leveldb_opened_tables",
+		"number of the table files opened by leveldb", "db")
+	splitBatchMetric = monitor.GetOrRegisterCounterVec("vite_leveldb_split_batches_total",
+		"number of the sub-batches written for the oversized batches", "db")
+)
+
+func (store *Store) Stats(s *leveldb.DBStats) error {
+	return store.db.Stats(s)
+}
+
+// ReportMetrics updates the leveldb metrics of the store, counters are cumulated since the db is opened
+func (store *Store) ReportMetrics() {
+	var s leveldb.DBStats
+	if err := store.Stats(&s); err != nil {
+		return
+	}
+
+	var size, tables, read, write, compactions int64
+	var duration float64
+	for i := range s.LevelSizes {
+		size += s.LevelSizes[i]
+		tables += int64(s.LevelTablesCounts[i])
+		read += s.LevelRead[i]
+		write += s.LevelWrite[i]
+		duration += s.LevelDurations[i].Seconds()
+		compactions += s.LevelCompactions[i]
+	}
+
+	name := store.name
+	leveldbSizeMetric.With(name).Set(float64(size))
+	leveldbTablesMetric.With(name).Set(float64(tables))
+	leveldbCompactionSecondsMetric.With(name).Set(duration)
+	leveldbCompactionBytesMetric.With(name, "read").Set(float64(read))
+	leveldbCompactionBytesMetric.With(name, "write").Set(float64(write))
+	leveldbWriteDelayMetric.With(name).Set(float64(s.WriteDelayCount))
+	leveldbWriteDelaySecondsMetric.With(name).Set(s.WriteDelayDuration.Seconds())
+	leveldbIOBytesMetric.With(name, "read").Set(float64(s.IORead))
+	leveldbIOBytesMetric.With(name, "write").Set(float64(s.IOWrite))
+	leveldbCompactionsMetric.With(name).Set(float64(compactions))
+	leveldbBlockCacheMetric.With(name, "hit").Set(float64(s.BlockCacheHits))
+	leveldbBlockCacheMetric.With(name, "miss").Set(float64(s.BlockCacheMisses))
+	leveldbFilterMetric.With(name, "useful").Set(float64(s.FilterUseful))
+	leveldbFilterMetric.With(name, "useless").Set(float64(s.FilterChecks - s.FilterUseful))
+	leveldbOpenedTablesMetric.With(name).Set(float64(s.OpenedTablesCount))
+}
+
+// levelDBStatus returns the level sizes, the compactions, the hit ratios and the opened files in the stats
+func levelDBStatus(s *leveldb.DBStats) interfaces.DBStatus {
+	status := interfaces.DBStatus{
+		Levels:      make([]interfaces.LevelStatus, 0, len(s.Levels)),
+		OpenedFiles: s.OpenedTablesCount,
+	}
+	for i, level := range s.Levels {
+		status.Count += uint64(s.LevelTablesCounts[i])
+		status.Size += uint64(s.LevelSizes[i])
+		status.Compactions += s.LevelCompactions[i]
+		status.Levels = append(status.Levels, interfaces.LevelStatus{
+			Level:             level,
+			Tables:            s.LevelTablesCounts[i],
+			Size:              s.LevelSizes[i],
+			Compactions:       s.LevelCompactions[i],
+			CompactionRead:    s.LevelRead[i],
+			CompactionWrite:   s.LevelWrite[i],
+			CompactionSeconds: s.LevelDurations[i].Seconds(),
+		})
+	}
+	if lookups := s.BlockCacheHits + s.BlockCacheMisses; lookups > 0 {
+		status.BlockCacheHitRatio = float64(s.BlockCacheHits) / float64(lookups)
+	}
+	if s.FilterChecks > 0 {
+		status.FilterUsefulRatio = float64(s.FilterUseful) / float64(s.FilterChecks)
+	}
+	return status
+}
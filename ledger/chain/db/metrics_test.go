@@ -0,0 +1,72 @@
+package chain_db
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/filter"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/opt"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/crypto"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+func TestStore_GetStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store_status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.OpenFile(dir, &opt.Options{Filter: filter.NewBloomFilter(10)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := NewStoreWithDb(dir, "statusTest", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the keys are hashed, the bloom filter can't rule out the sequential keys well
+	key := func(i uint64) []byte {
+		return crypto.Hash256(chain_utils.Uint64ToBytes(i))
+	}
+	batch := store.NewBatch()
+	for i := uint64(0); i < 1000; i++ {
+		batch.Put(key(i*2), chain_utils.Uint64ToBytes(i))
+	}
+	store.WriteDirectly(batch)
+	flushToDisk(store)
+	if err := store.CompactRange(util.Range{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the keys are read twice, the odd keys are ruled out by the bloom filter
+	for round := 0; round < 2; round++ {
+		for i := uint64(0); i < 100; i++ {
+			if _, err := db.Get(key(i), nil); err != nil && err != leveldb.ErrNotFound {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	status := store.GetStatus()[1]
+	if status.Count == 0 || status.Size == 0 || len(status.Levels) == 0 {
+		t.Fatalf("no tables in status %+v", status)
+	}
+	if status.Compactions == 0 {
+		t.Fatal("no compactions counted")
+	}
+	if status.BlockCacheHitRatio <= 0 || status.BlockCacheHitRatio >= 1 {
+		t.Fatalf("unexpected block cache hit ratio %f", status.BlockCacheHitRatio)
+	}
+	if status.FilterUsefulRatio < 0.4 {
+		t.Fatalf("unexpected filter useful ratio %f", status.FilterUsefulRatio)
+	}
+	if status.OpenedFiles == 0 {
+		t.Fatal("no opened files")
+	}
+}
@@ -0,0 +1,97 @@
+package chain_db
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+)
+
+// splitBatch is the snapshot batch built as ordered sub-batches, a sub-batch is started before appending
+// would make the last one larger than maxSize, so a busy snapshot doesn't grow one huge buffer.
+// A batch appended larger than maxSize is kept whole, it is split by writeBatch when it is written.
+type splitBatch struct {
+	maxSize int // 0 means no limit
+	batches []*leveldb.Batch
+}
+
+func (store *Store) newSplitBatch() *splitBatch {
+	return &splitBatch{
+		maxSize: store.maxBatchSize,
+	}
+}
+
+// Append appends the records of p in order
+func (b *splitBatch) Append(p *leveldb.Batch) {
+	if p.Len() <= 0 {
+		return
+	}
+	var last *leveldb.Batch
+	if n := len(b.batches); n > 0 {
+		last = b.batches[n-1]
+	}
+	if last == nil || (b.maxSize > 0 && last.Len() > 0 && last.Size()+p.Size() > b.maxSize) {
+		last = batchPool.Get().(*leveldb.Batch)
+		last.Reset()
+		b.batches = append(b.batches, last)
+	}
+	last.Append(p)
+}
+
+// appendSplit appends the sub-batches of p in order
+func (b *splitBatch) appendSplit(p *splitBatch) {
+	for _, batch := range p.batches {
+		b.Append(batch)
+	}
+}
+
+func (b *splitBatch) Replay(r leveldb.BatchReplay) error {
+	for _, batch := range b.batches {
+		if err := batch.Replay(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *splitBatch) Len() int {
+	n := 0
+	for _, batch := range b.batches {
+		n += batch.Len()
+	}
+	return n
+}
+
+func (b *splitBatch) Size() int {
+	n := 0
+	for _, batch := range b.batches {
+		n += batch.Size()
+	}
+	return n
+}
+
+// Dump dumps the sub-batches as one batch, it can be loaded into a leveldb.Batch.
+// The records of a batch dump have no header, so the dumps are concatenated.
+func (b *splitBatch) Dump() []byte {
+	switch len(b.batches) {
+	case 0:
+		return nil
+	case 1:
+		return b.batches[0].Dump()
+	}
+
+	n := 0
+	for _, batch := range b.batches {
+		n += len(batch.Dump())
+	}
+	data := make([]byte, 0, n)
+	for _, batch := range b.batches {
+		data = append(data, batch.Dump()...)
+	}
+	return data
+}
+
+// Reset puts the sub-batches back to the pool
+func (b *splitBatch) Reset() {
+	for _, batch := range b.batches {
+		batchPool.Put(batch)
+	}
+	b.batches = nil
+}
@@ -22,14 +22,16 @@ type Store struct {
 	memDbMu sync.RWMutex
 	memDb   *db.MemDB
 
-	snapshotBatch *leveldb.Batch
-	flushingBatch *leveldb.Batch
+	snapshotBatch *splitBatch
+	flushingBatch *splitBatch
 
 	unconfirmedBatchs *UnconfirmedBatchs
 
 	dbDir string
 	db    *leveldb.DB
 
+	maxBatchSize int
+
 	afterRecoverFuncs []func()
 }
 
@@ -58,7 +60,7 @@ func NewStoreWithDb(dataDir string, name string, diskStore *leveldb.DB) (*Store,
 		db:    diskStore,
 	}
 
-	store.snapshotBatch = store.getNewBatch()
+	store.snapshotBatch = store.newSplitBatch()
 
 	return store, nil
 }
@@ -112,6 +114,11 @@ func (store *Store) NewIterator(slice *util.Range) interfaces.StorageIterator {
 	return store.db.NewIterator2(slice, nil, mdb, seq)
 }
 
+// DiskSnapshot returns the snapshot of the data flushed to disk, the data in memory is excluded
+func (store *Store) DiskSnapshot() (*leveldb.Snapshot, error) {
+	return store.db.GetSnapshot()
+}
+
 func (store *Store) Close() error {
 	store.memDb = nil
 	return store.db.Close()
@@ -152,7 +159,7 @@ func (store *Store) GetStatus() []interfaces.DBStatus {
 	}
 	if store.flushingBatch != nil {
 		count += store.flushingBatch.Len()
-		size += store.snapshotBatch.Size()
+		size += store.flushingBatch.Size()
 	}
 
 	s := &leveldb.DBStats{}
@@ -163,17 +170,16 @@ func (store *Store) GetStatus() []interfaces.DBStatus {
 		status = []byte("Error:" + err.Error())
 	}
 
+	levelDBStatus := levelDBStatus(s)
+	levelDBStatus.Name = "levelDB"
+	levelDBStatus.Status = string(status)
+
 	return []interfaces.DBStatus{{
 		Name:   "mem",
 		Count:  uint64(count),
 		Size:   uint64(size),
 		Status: "",
-	}, {
-		Name:   "levelDB",
-		Count:  0,
-		Size:   0,
-		Status: string(status),
-	}}
+	}, levelDBStatus}
 }
 
 func (store *Store) getSnapshotMemDb() (*memdb.DB, uint64) {
@@ -27,6 +27,18 @@ func (store *Store) WriteAccountBlockByHash(batch *leveldb.Batch, blockHash type
 	store.unconfirmedBatchs.Put(blockHash, batch)
 }
 
+// PatchAccountBlockByHash appends the batch to the unconfirmed batch of the account block
+func (store *Store) PatchAccountBlockByHash(batch *leveldb.Batch, blockHash types.Hash) {
+	// write store.memDb
+	store.putMemDb(batch)
+
+	if unconfirmed, ok := store.unconfirmedBatchs.Get(blockHash); ok {
+		unconfirmed.Append(batch)
+		return
+	}
+	store.unconfirmedBatchs.Put(blockHash, batch)
+}
+
 // snapshot
 func (store *Store) WriteSnapshot(snapshotBatch *leveldb.Batch, accountBlocks []*ledger.AccountBlock) {
 
@@ -9,16 +9,24 @@ import (
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 )
 
+// the snapshot blocks are deleted in batches, because can't delete too much data at once
+const rollbackBatchSize = uint64(120)
+
 func (c *chain) DeleteSnapshotBlocks(toHash types.Hash) ([]*ledger.SnapshotChunk, error) {
 	height, err := c.indexDB.GetSnapshotBlockHeight(&toHash)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockHeight failed, snapshotHash is %s. Error: %s", toHash, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockHeight failed, snapshotHash is %s. Error: %w", toHash, err)
+		c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocks")
+		return nil, cErr
+	}
+	if height <= 0 {
+		cErr := fmt.Errorf("%w: snapshotHash is %s", ErrBlockNotFound, toHash)
 		c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocks")
 		return nil, cErr
 	}
 	if height <= 1 {
-		cErr := fmt.Errorf("height <= 1, snapshotHash is %s. Error: %s", toHash, err.Error())
+		cErr := fmt.Errorf("height <= 1, snapshotHash is %s", toHash)
 		c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocks")
 		return nil, cErr
 	}
@@ -36,7 +44,74 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 		return nil, cErr
 	}
 
-	deleteAtOnce := uint64(120)
+	// the redo logs and the history before the pruned height are deleted, the state can't be rolled back to there
+	if prunedHeight := c.HistoryPrunedHeight(); toHeight < prunedHeight {
+		cErr := fmt.Errorf("%w: toHeight is %d, the history before %d is pruned", ErrRollbackTooDeep, toHeight, prunedHeight)
+		c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocksToHeight")
+		return nil, cErr
+	}
+
+	// save the blocks of a deep rollback, so they can be restored if the rollback is a mistake
+	if latestHeight-toHeight+1 > recoveryRollbackDepth {
+		file, err := c.saveRollbackRecovery(toHeight, latestHeight)
+		if err != nil {
+			cErr := fmt.Errorf("c.saveRollbackRecovery failed, snapshot blocks [%d, %d]. Error: %w", toHeight, latestHeight, err)
+			c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocksToHeight")
+			return nil, cErr
+		}
+		c.log.Info(fmt.Sprintf("save snapshot blocks [%d, %d] to %s before rolling back", toHeight, latestHeight, file), "method", "DeleteSnapshotBlocksToHeight")
+	}
+
+	return c.deleteSnapshotBlocksWithJournal(latestHeight, toHeight)
+}
+
+// deleteSnapshotBlocksWithJournal records the rollback in the journal if it's deleted in more than one batch,
+// the journal is kept if the rollback fails and is completed at startup.
+func (c *chain) deleteSnapshotBlocksWithJournal(latestHeight, toHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	if latestHeight-toHeight+1 <= rollbackBatchSize {
+		return c.deleteSnapshotBlocksInBatches(toHeight)
+	}
+
+	tmpLocation, err := c.indexDB.GetSnapshotBlockLocation(toHeight - 1)
+	if err != nil {
+		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %w", toHeight-1, err)
+		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksWithJournal")
+		return nil, cErr
+	}
+	location, err := c.blockDB.GetNextLocation(tmpLocation)
+	if err != nil || location == nil {
+		cErr := fmt.Errorf("c.blockDB.GetNextLocation failed, height is %d. Error: %v", toHeight-1, err)
+		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksWithJournal")
+		return nil, cErr
+	}
+
+	if err := c.writeRollbackJournal(&rollbackJournal{
+		FromHeight: latestHeight,
+		ToHeight:   toHeight,
+		Location:   *location,
+	}); err != nil {
+		cErr := fmt.Errorf("c.writeRollbackJournal failed, toHeight is %d. Error: %w", toHeight, err)
+		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksWithJournal")
+		return nil, cErr
+	}
+
+	chunks, err := c.deleteSnapshotBlocksInBatches(toHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.removeRollbackJournal(); err != nil {
+		cErr := fmt.Errorf("c.removeRollbackJournal failed. Error: %w", err)
+		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksWithJournal")
+		return nil, cErr
+	}
+	return chunks, nil
+}
+
+// deleteSnapshotBlocksInBatches deletes the snapshot blocks from the latest to toHeight, every batch is flushed atomically
+func (c *chain) deleteSnapshotBlocksInBatches(toHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	latestHeight := c.GetLatestSnapshotBlock().Height
+
 	// init target height
 	targetHeight := latestHeight + 1
 
@@ -44,8 +119,8 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 
 	for targetHeight > toHeight {
 		// compute middle height to delete, because can't delete too much data at once
-		if targetHeight > deleteAtOnce {
-			targetHeight -= deleteAtOnce
+		if targetHeight > rollbackBatchSize {
+			targetHeight -= rollbackBatchSize
 			if targetHeight < toHeight {
 				targetHeight = toHeight
 			}
@@ -56,8 +131,8 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 		// delete to middle height
 		chunksDeleted, err := c.deleteSnapshotBlocksToHeight(targetHeight)
 		if err != nil {
-			cErr := fmt.Errorf("c.deleteSnapshotBlocksToHeight failed, targetHeight is %d. Error: %s", targetHeight, err.Error())
-			c.log.Error(cErr.Error(), "method", "DeleteSnapshotBlocksToHeight")
+			cErr := fmt.Errorf("c.deleteSnapshotBlocksToHeight failed, targetHeight is %d. Error: %w", targetHeight, err)
+			c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksInBatches")
 			return nil, cErr
 		}
 
@@ -90,21 +165,21 @@ func (c *chain) deleteSnapshotBlocksToHeight(toHeight uint64) (chunks []*ledger.
 
 	tmpLocation, err := c.indexDB.GetSnapshotBlockLocation(toHeight - 1)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %s", toHeight-1, err.Error())
+		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %w", toHeight-1, err)
 		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 		return nil, cErr
 	}
 
 	location, err := c.blockDB.GetNextLocation(tmpLocation)
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.GetNextLocation failed. Error: %s", err.Error())
+		cErr := fmt.Errorf("c.blockDB.GetNextLocation failed. Error: %w", err)
 		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 		return nil, cErr
 	}
 
 	if location == nil {
-		cErr := fmt.Errorf("location is nil, toHeight is %d",
-			toHeight)
+		cErr := fmt.Errorf("%w: location is nil, toHeight is %d",
+			ErrLocationOutOfRange, toHeight)
 		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 
 		return nil, cErr
@@ -114,7 +189,7 @@ func (c *chain) deleteSnapshotBlocksToHeight(toHeight uint64) (chunks []*ledger.
 	snapshotChunks, err := c.blockDB.PrepareRollback(location)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.PrepareRollback failed, location is %d. Error: %s,", location, err.Error())
+		cErr := fmt.Errorf("c.blockDB.PrepareRollback failed, location is %d. Error: %w,", location, err)
 		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 	if len(snapshotChunks) <= 0 {
@@ -125,7 +200,7 @@ func (c *chain) deleteSnapshotBlocksToHeight(toHeight uint64) (chunks []*ledger.
 	hasStorageRedoLog, err := c.stateDB.Redo().HasRedo(toHeight)
 
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.Redo().HasRedo() failed, toHeight is %d. Error: %s", toHeight, err.Error())
+		cErr := fmt.Errorf("c.stateDB.Redo().HasRedo() failed, toHeight is %d. Error: %w", toHeight, err)
 		c.log.Error(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
@@ -174,36 +249,36 @@ func (c *chain) deleteSnapshotBlocksToHeight(toHeight uint64) (chunks []*ledger.
 	//}
 
 	if err := c.em.TriggerDeleteSbs(prepareDeleteSbsEvent, realChunksToDelete); err != nil {
-		cErr := fmt.Errorf("c.em.Trigger(prepareDeleteSbsEvent) failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.em.Trigger(prepareDeleteSbsEvent) failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
 	// rollback block db
 	if err := c.blockDB.Rollback(location); err != nil {
-		cErr := fmt.Errorf("c.blockDB.Rollback(location) failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.blockDB.Rollback(location) failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
 	// rollback index db
 	if err := c.indexDB.RollbackSnapshotBlocks(snapshotChunks, newUnconfirmedBlocks); err != nil {
-		cErr := fmt.Errorf("c.indexDB.RollbackSnapshotBlocks failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.indexDB.RollbackSnapshotBlocks failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
 	// rollback cache
 	if err := c.cache.RollbackSnapshotBlocks(snapshotChunks, newUnconfirmedBlocks); err != nil {
-		cErr := fmt.Errorf("c.cache.RollbackSnapshotBlocks failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.cache.RollbackSnapshotBlocks failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
 	// rollback state db
 	if err := c.stateDB.RollbackSnapshotBlocks(snapshotChunks, newUnconfirmedBlocks); err != nil {
-		cErr := fmt.Errorf("c.stateDB.RollbackSnapshotBlocks failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.stateDB.RollbackSnapshotBlocks failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
 	if err := c.em.TriggerDeleteSbs(deleteSbsEvent, realChunksToDelete); err != nil {
-		cErr := fmt.Errorf("c.em.Trigger(deleteSbsEvent) failed, error is %s", err.Error())
+		cErr := fmt.Errorf("c.em.Trigger(deleteSbsEvent) failed, error is %w", err)
 		common.Crit(cErr.Error(), "method", "deleteSnapshotBlocksToHeight")
 	}
 
@@ -285,19 +360,19 @@ func (c *chain) deleteAccountBlocks(blocks []*ledger.AccountBlock) error {
 
 	// rollback index db
 	if err := c.indexDB.RollbackAccountBlocks(blocks); err != nil {
-		cErr := fmt.Errorf("c.indexDB.RollbackAccountBlocks failed. Error: %s", err.Error())
+		cErr := fmt.Errorf("c.indexDB.RollbackAccountBlocks failed. Error: %w", err)
 		common.Crit(cErr.Error(), "method", "deleteAccountBlocks")
 	}
 
 	// rollback cache
 	if err := c.cache.RollbackAccountBlocks(blocks); err != nil {
-		cErr := fmt.Errorf("c.cache.RollbackAccountBlocks failed. Error: %s", err.Error())
+		cErr := fmt.Errorf("c.cache.RollbackAccountBlocks failed. Error: %w", err)
 		common.Crit(cErr.Error(), "method", "deleteAccountBlocks")
 	}
 
 	// rollback state db
 	if err := c.stateDB.RollbackAccountBlocks(blocks); err != nil {
-		cErr := fmt.Errorf("c.stateDB.RollbackAccountBlocks failed. Error: %s", err.Error())
+		cErr := fmt.Errorf("c.stateDB.RollbackAccountBlocks failed. Error: %w", err)
 		common.Crit(cErr.Error(), "method", "deleteAccountBlocks")
 	}
 
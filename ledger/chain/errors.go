@@ -0,0 +1,13 @@
+package chain
+
+import (
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
+)
+
+// the errors returned by the chain wrap these errors, check them by errors.Is
+var (
+	ErrBlockNotFound      = chain_errors.ErrBlockNotFound
+	ErrLocationOutOfRange = chain_errors.ErrLocationOutOfRange
+	ErrDBCorrupted        = chain_errors.ErrDBCorrupted
+	ErrRollbackTooDeep    = chain_errors.ErrRollbackTooDeep
+)
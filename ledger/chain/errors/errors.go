@@ -0,0 +1,19 @@
+// Package chain_errors defines the errors shared by the packages of the ledger.
+// The errors returned by the chain wrap them, so check them by errors.Is instead of matching the messages.
+package chain_errors
+
+import "errors"
+
+var (
+	// ErrBlockNotFound is returned when a block expected in the ledger doesn't exist
+	ErrBlockNotFound = errors.New("block not found")
+
+	// ErrLocationOutOfRange is returned when reading the block files beyond the latest location
+	ErrLocationOutOfRange = errors.New("location out of range")
+
+	// ErrDBCorrupted is returned when the data read from the block files or the dbs can't be decoded or verified
+	ErrDBCorrupted = errors.New("db is corrupted")
+
+	// ErrRollbackTooDeep is returned when rolling back below the height whose history is kept
+	ErrRollbackTooDeep = errors.New("rollback too deep")
+)
@@ -0,0 +1,141 @@
+package chain
+
+import (
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// ChainEventType is the type of the chain event
+type ChainEventType byte
+
+const (
+	// AccountBlocksInserted is emitted after the account blocks are inserted, they are unconfirmed
+	AccountBlocksInserted ChainEventType = iota + 1
+	// SnapshotBlocksInserted is emitted after the snapshot blocks are inserted with the account blocks confirmed
+	SnapshotBlocksInserted
+	// AccountBlocksRolledBack is emitted after the unconfirmed account blocks are removed
+	AccountBlocksRolledBack
+	// ChunksRolledBack is emitted after the snapshot blocks are removed with the account blocks confirmed by them
+	ChunksRolledBack
+)
+
+var chainEventTypeStrings = []string{
+	"unknown",
+	"accountBlocksInserted",
+	"snapshotBlocksInserted",
+	"accountBlocksRolledBack",
+	"chunksRolledBack",
+}
+
+func (t ChainEventType) String() string {
+	if int(t) < len(chainEventTypeStrings) {
+		return chainEventTypeStrings[t]
+	}
+	return chainEventTypeStrings[0]
+}
+
+// ChainEvent is emitted after the chain is changed
+type ChainEvent struct {
+	Type ChainEventType
+
+	// AccountBlocks are the account blocks of AccountBlocksInserted and AccountBlocksRolledBack
+	AccountBlocks []*ledger.AccountBlock
+
+	// Chunks are the snapshot blocks and the account blocks of SnapshotBlocksInserted and ChunksRolledBack,
+	// the snapshot block of the last chunk may be nil if the unconfirmed account blocks are removed with it,
+	// and the account blocks kept unconfirmed after rolling back are not in the chunks
+	Chunks []*ledger.SnapshotChunk
+}
+
+// ChainEventCallback is called synchronously when the chain is changed, the callback must not block
+// and must not write the chain
+type ChainEventCallback func(event ChainEvent)
+
+// eventBus emits the typed events to the subscribers, it is the event listener of the chain
+type eventBus struct {
+	mu        sync.RWMutex
+	subs      map[int]ChainEventCallback
+	currentId int
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{
+		subs: make(map[int]ChainEventCallback),
+	}
+}
+
+func (bus *eventBus) subscribe(fn ChainEventCallback) (subId int) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.currentId++
+	bus.subs[bus.currentId] = fn
+	return bus.currentId
+}
+
+func (bus *eventBus) unsubscribe(subId int) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	delete(bus.subs, subId)
+}
+
+func (bus *eventBus) emit(event ChainEvent) {
+	bus.mu.RLock()
+	defer bus.mu.RUnlock()
+
+	for _, fn := range bus.subs {
+		fn(event)
+	}
+}
+
+func (bus *eventBus) PrepareInsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	return nil
+}
+
+func (bus *eventBus) InsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	accountBlocks := make([]*ledger.AccountBlock, len(blocks))
+	for i, b := range blocks {
+		accountBlocks[i] = b.AccountBlock
+	}
+	bus.emit(ChainEvent{Type: AccountBlocksInserted, AccountBlocks: accountBlocks})
+	return nil
+}
+
+func (bus *eventBus) PrepareInsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	return nil
+}
+
+func (bus *eventBus) InsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	bus.emit(ChainEvent{Type: SnapshotBlocksInserted, Chunks: chunks})
+	return nil
+}
+
+func (bus *eventBus) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+func (bus *eventBus) DeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	bus.emit(ChainEvent{Type: AccountBlocksRolledBack, AccountBlocks: blocks})
+	return nil
+}
+
+func (bus *eventBus) PrepareDeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	return nil
+}
+
+func (bus *eventBus) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	bus.emit(ChainEvent{Type: ChunksRolledBack, Chunks: chunks})
+	return nil
+}
+
+// SubscribeChainEvents subscribes the typed events of inserting and rolling back blocks
+func (c *chain) SubscribeChainEvents(fn ChainEventCallback) (subId int) {
+	return c.bus.subscribe(fn)
+}
+
+func (c *chain) UnsubscribeChainEvents(subId int) {
+	c.bus.unsubscribe(subId)
+}
@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func TestEventBus(t *testing.T) {
+	em := newEventManager(nil)
+	bus := newEventBus()
+	em.Register(bus)
+
+	var events []ChainEvent
+	subId := bus.subscribe(func(event ChainEvent) {
+		events = append(events, event)
+	})
+
+	ab := &ledger.AccountBlock{Height: 2}
+	if err := em.TriggerInsertAbs(insertAbsEvent, []*interfaces.VmAccountBlock{{AccountBlock: ab}}); err != nil {
+		t.Fatal(err)
+	}
+	chunks := []*ledger.SnapshotChunk{
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 10}, AccountBlocks: []*ledger.AccountBlock{ab}},
+		{AccountBlocks: []*ledger.AccountBlock{{Height: 3}}},
+	}
+	if err := em.TriggerDeleteSbs(deleteSbsEvent, chunks); err != nil {
+		t.Fatal(err)
+	}
+	// not emitted before the blocks are removed
+	if err := em.TriggerDeleteAbs(prepareDeleteAbsEvent, []*ledger.AccountBlock{ab}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if e := events[0]; e.Type != AccountBlocksInserted || len(e.AccountBlocks) != 1 || e.AccountBlocks[0] != ab {
+		t.Errorf("unexpected event %s", e.Type)
+	}
+	if e := events[1]; e.Type != ChunksRolledBack || len(e.Chunks) != 2 || e.Chunks[1].AccountBlocks[0].Height != 3 {
+		t.Errorf("unexpected event %s", e.Type)
+	}
+
+	bus.unsubscribe(subId)
+	if err := em.TriggerInsertSbs(InsertSbsEvent, chunks); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Errorf("unexpected event after unsubscribed")
+	}
+}
+
+func TestEventManager_UnRegister(t *testing.T) {
+	em := newEventManager(nil)
+	bus1, bus2 := newEventBus(), newEventBus()
+	em.Register(bus1)
+	em.Register(bus2)
+
+	em.UnRegister(bus2)
+	if len(em.listenerList) != 1 || em.listenerList[0] != bus1 {
+		t.Fatal("unexpected listener removed")
+	}
+}
@@ -161,8 +161,8 @@ func (em *eventManager) UnRegister(listener interfaces.EventListener) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
-	for index, listener := range em.listenerList {
-		if listener == listener {
+	for index, l := range em.listenerList {
+		if l == listener {
 			em.listenerList = append(em.listenerList[:index], em.listenerList[index+1:]...)
 			break
 		}
@@ -119,8 +119,10 @@ func (fd *fileDescription) Flush(startOffset int64, buf []byte) (int, error) {
 		return n, err
 	}
 
-	if err := file.Sync(); err != nil {
-		return n, err
+	if fm := fd.fdSet.fileManager; fm.syncWrite() {
+		if err := fm.syncFile(file); err != nil {
+			return n, err
+		}
 	}
 	return n, nil
 }
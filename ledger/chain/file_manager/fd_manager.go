@@ -12,8 +12,11 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/vitelabs/go-vite/v2/common/fileutils"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
 )
 
+const filenamePrefix = "f"
+
 type fileCacheItem struct {
 	Buffer []byte
 
@@ -43,6 +46,8 @@ type fdManager struct {
 
 	changeFdMu sync.RWMutex
 
+	seals *sealManifest
+
 	fileManager *FileManager
 }
 
@@ -53,8 +58,8 @@ func newFdManager(fileManager *FileManager, dirName string, fileSize int, cacheL
 	fdSet := &fdManager{
 		dirName:            dirName,
 		fileManager:        fileManager,
-		filenamePrefix:     "f",
-		filenamePrefixSize: 1,
+		filenamePrefix:     filenamePrefix,
+		filenamePrefixSize: len(filenamePrefix),
 
 		fileCache:       list.New(),
 		fileCacheLength: cacheLength,
@@ -70,6 +75,11 @@ func newFdManager(fileManager *FileManager, dirName string, fileSize int, cacheL
 		return nil, fmt.Errorf("fileutils.OpenOrCreateFd failed, error is %s, dirName is %s", err, dirName)
 	}
 
+	fdSet.seals, err = loadSealManifest(dirName, int64(fileSize))
+	if err != nil {
+		return nil, fmt.Errorf("loadSealManifest failed. Error: %s", err)
+	}
+
 	location, err := fdSet.loadLatestLocation()
 	if err != nil {
 		return nil, fmt.Errorf("fdSet.loadLatestFileId failed. Error: %s", err)
@@ -115,7 +125,25 @@ func (fdSet *fdManager) GetFd(fileId uint64) (*fileDescription, error) {
 
 	return NewFdByFile(fd), nil
 }
+
+// onDisk returns true if the file is only on disk, which is neither in the write cache nor after the latest file
+func (fdSet *fdManager) onDisk(fileId uint64) bool {
+	fdSet.changeFdMu.RLock()
+	defer fdSet.changeFdMu.RUnlock()
+
+	if fileId > fdSet.latestFileId() {
+		return false
+	}
+	if _, ok := fdSet.fileFdCache[fileId]; ok {
+		return false
+	}
+	return fdSet.getCacheItem(fileId) == nil
+}
+
 func (fdSet *fdManager) GetTmpFlushFd(fileId uint64) (*fileDescription, error) {
+	if err := fdSet.seals.unseal(fileId); err != nil {
+		return nil, err
+	}
 	file, err := fdSet.getFileFd(fileId)
 	if err != nil {
 		return nil, err
@@ -183,6 +211,12 @@ func (fdSet *fdManager) DeleteTo(location *Location) error {
 }
 
 func (fdSet *fdManager) DiskDelete(highLocation *Location, lowLocation *Location) error {
+	for i := highLocation.FileId; i >= lowLocation.FileId; i-- {
+		if err := fdSet.seals.unseal(i); err != nil {
+			return err
+		}
+	}
+
 	for i := highLocation.FileId; i > lowLocation.FileId; i-- {
 		if err := os.Remove(fdSet.fileIdToAbsoluteFilename(i)); err != nil && !os.IsNotExist(err) {
 			return err
@@ -241,6 +275,13 @@ func (fdSet *fdManager) Close() error {
 
 // tools
 func (fdSet *fdManager) resetWriteFd(location *Location) error {
+	// the file is written again
+	if location.Offset < fdSet.fileSize {
+		if err := fdSet.seals.unseal(location.FileId); err != nil {
+			return err
+		}
+	}
+
 	if fdSet.writeFd != nil {
 		cacheItem := fdSet.writeFd.cacheItem
 
@@ -431,26 +472,48 @@ func (fdSet *fdManager) getCacheItem(fileId uint64) *fileCacheItem {
 func (fdSet *fdManager) getFileFd(fileId uint64) (*os.File, error) {
 	absoluteFilename := fdSet.fileIdToAbsoluteFilename(fileId)
 
-	file, oErr := os.OpenFile(absoluteFilename, os.O_RDWR, 0666)
+	// the sealed file is read-only
+	sealed := fdSet.seals.isSealed(fileId)
+	flag := fdSet.fileManager.options.writeFlag()
+	if sealed {
+		flag = os.O_RDONLY
+	}
+
+	file, oErr := os.OpenFile(absoluteFilename, flag, 0666)
 	if oErr != nil {
 		if os.IsNotExist(oErr) {
+			if sealed {
+				return nil, fmt.Errorf("%w: sealed file %s is missing", chain_errors.ErrDBCorrupted, absoluteFilename)
+			}
 			return nil, nil
 		}
 		return nil, fmt.Errorf("error is %s, fileId is %d, absoluteFilename is %s",
 			oErr.Error(), fileId, absoluteFilename)
 	}
+
+	if err := fdSet.seals.verify(fileId, file); err != nil {
+		file.Close()
+		return nil, err
+	}
 	return file, oErr
 }
 
 func (fdSet *fdManager) createNewFile(fileId uint64) (*os.File, error) {
 	absoluteFilename := fdSet.fileIdToAbsoluteFilename(fileId)
 
-	file, cErr := os.Create(absoluteFilename)
+	file, cErr := os.OpenFile(absoluteFilename, fdSet.fileManager.options.writeFlag()|os.O_CREATE|os.O_TRUNC, 0666)
 
 	if cErr != nil {
 		return nil, errors.New("Create fileReader failed, error is " + cErr.Error())
 	}
 
+	if fdSet.fileManager.options.Preallocate {
+		if err := preallocate(file, fdSet.fileSize); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("preallocate failed, fileId is %d. Error: %s", fileId, err)
+		}
+	}
+
 	return file, nil
 }
 
@@ -4,27 +4,49 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/interfaces"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
 	"github.com/vitelabs/go-vite/v2/log15"
 )
 
+// MaxBufSize is the max size of a buf read by Read, the size prefix of a broken file shouldn't cause a huge allocation
+const MaxBufSize = 32 * 1024 * 1024
+
 type FileManager struct {
 	fileSize int64
 
 	fdSet                  *fdManager
+	prefetcher             *prefetcher
 	nextFlushStartLocation *Location
 	prevFlushLocation      *Location
 
+	options      Options
+	syncMu       sync.Mutex
+	unsynced     map[uint64]struct{}
+	lastSyncTime time.Time
+
 	fSyncWg sync.WaitGroup
 	log     log15.Logger
 }
 
 func NewFileManager(dirName string, fileSize int64, cacheCount int) (*FileManager, error) {
+	return NewFileManagerWithOptions(dirName, fileSize, cacheCount, Options{})
+}
+
+func NewFileManagerWithOptions(dirName string, fileSize int64, cacheCount int, options Options) (*FileManager, error) {
+	if err := options.check(); err != nil {
+		return nil, err
+	}
 	fm := &FileManager{
-		fileSize: fileSize,
-		log:      log15.New("module", "fileManager"),
+		fileSize:     fileSize,
+		options:      options,
+		unsynced:     make(map[uint64]struct{}),
+		lastSyncTime: time.Now(),
+		log:          log15.New("module", "fileManager"),
 	}
 
 	fdSet, err := newFdManager(fm, dirName, int(fileSize), cacheCount)
@@ -33,9 +55,17 @@ func NewFileManager(dirName string, fileSize int64, cacheCount int) (*FileManage
 	}
 
 	fm.fdSet = fdSet
+	fm.prefetcher = newPrefetcher(fm)
 	fm.nextFlushStartLocation = fm.fdSet.LatestLocation()
 	fm.prevFlushLocation = fm.nextFlushStartLocation
 
+	// the file before the latest may be filled up without being sealed, if the node stopped while flushing
+	if latestFileId := fm.nextFlushStartLocation.FileId; latestFileId > 1 {
+		if err := fdSet.seals.seal(latestFileId - 1); err != nil {
+			fm.log.Warn(fmt.Sprintf("seal file %d failed. Error: %s", latestFileId-1, err), "method", "NewFileManager")
+		}
+	}
+
 	return fm, nil
 }
 
@@ -50,6 +80,16 @@ func (fm *FileManager) SetNextFlushStartLocation(location *Location) {
 	fm.nextFlushStartLocation = NewLocation(location.FileId, location.Offset)
 }
 
+// Dir returns the directory of the files
+func (fm *FileManager) Dir() string {
+	return fm.fdSet.dirName
+}
+
+// FileName returns the name of the file in the directory
+func FileName(fileId uint64) string {
+	return filenamePrefix + strconv.FormatUint(fileId, 10)
+}
+
 func (fm *FileManager) LatestLocation() *Location {
 	return fm.fdSet.LatestLocation()
 }
@@ -81,6 +121,7 @@ func (fm *FileManager) DeleteTo(location *Location) error {
 	if err := fm.fdSet.DeleteTo(location); err != nil {
 		return err
 	}
+	fm.prefetcher.invalidate(location.FileId)
 
 	if location.Compare(fm.nextFlushStartLocation) < 0 {
 		fm.nextFlushStartLocation = location
@@ -92,6 +133,8 @@ func (fm *FileManager) DeleteTo(location *Location) error {
 }
 
 func (fm *FileManager) Flush(startLocation *Location, targetLocation *Location, buf []byte) error {
+	fm.prefetcher.invalidate(startLocation.FileId)
+
 	// flush
 	flushLocation := NewLocation(startLocation.FileId, startLocation.Offset)
 
@@ -127,8 +170,17 @@ func (fm *FileManager) Flush(startLocation *Location, targetLocation *Location,
 
 		flushOffset := flushLocation.Offset + int64(n)
 		bufStart += int64(n)
+		fm.written(flushLocation.FileId)
 
 		if flushOffset >= fm.fileSize {
+			// the file is filled up
+			if err := fm.syncFilled(flushLocation.FileId); err != nil {
+				return err
+			}
+			if err := fm.fdSet.seals.seal(flushLocation.FileId); err != nil {
+				return fmt.Errorf("seal file failed, fileId is %d. Error: %s", flushLocation.FileId, err)
+			}
+
 			flushLocation.FileId += 1
 			flushLocation.Offset = 0
 		} else {
@@ -139,6 +191,10 @@ func (fm *FileManager) Flush(startLocation *Location, targetLocation *Location,
 	// FOR DEBUG
 	//fm.log.Info(fmt.Sprintf("file manager flush, start location is %+v, target location is %+v, buf size is %d", startLocation, targetLocation, len(buf)), "method", "Flush")
 
+	if err := fm.syncWritten(false); err != nil {
+		return err
+	}
+
 	if fm.prevFlushLocation.Compare(targetLocation) > 0 {
 		// Disk delete
 		if err := fm.fdSet.DiskDelete(fm.prevFlushLocation, targetLocation); err != nil {
@@ -172,6 +228,9 @@ func (fm *FileManager) Read(location *Location) ([]byte, *Location, error) {
 	}
 
 	bufSize := binary.BigEndian.Uint32(bufSizeBytes)
+	if bufSize > MaxBufSize {
+		return nil, nextLocation, fmt.Errorf("%w: buf size %d exceeds %d, location is %+v", chain_errors.ErrDBCorrupted, bufSize, MaxBufSize, location)
+	}
 
 	buf := make([]byte, bufSize)
 
@@ -193,16 +252,20 @@ func (fm *FileManager) ReadRaw(startLocation *Location, buf []byte) (*Location,
 			readSize = freeSize
 		}
 
-		fd, err := fm.fdSet.GetFd(currentLocation.FileId)
-		if err != nil {
-			return currentLocation, 0, err
-		}
-		if fd == nil {
-			return currentLocation, i, io.EOF
-		}
+		readN, ok := fm.prefetcher.readAt(currentLocation.FileId, buf[i:i+readSize], currentLocation.Offset)
+		var rErr error
+		if !ok {
+			fd, err := fm.fdSet.GetFd(currentLocation.FileId)
+			if err != nil {
+				return currentLocation, 0, err
+			}
+			if fd == nil {
+				return currentLocation, i, io.EOF
+			}
 
-		readN, rErr := fd.ReadAt(buf[i:i+readSize], currentLocation.Offset)
-		fd.Close()
+			readN, rErr = fd.ReadAt(buf[i:i+readSize], currentLocation.Offset)
+			fd.Close()
+		}
 
 		i += readN
 
@@ -219,6 +282,8 @@ func (fm *FileManager) ReadRaw(startLocation *Location, buf []byte) (*Location,
 		}
 
 	}
+
+	fm.prefetcher.access(startLocation, currentLocation)
 	return currentLocation, i, nil
 }
 
@@ -266,6 +331,12 @@ func (fm *FileManager) SetLog(h log15.Handler) {
 }
 
 func (fm *FileManager) Close() error {
+	fm.prefetcher.close()
+
+	if err := fm.syncWritten(true); err != nil {
+		fm.log.Error(fmt.Sprintf("fm.syncWritten failed. Error: %s", err), "method", "Close")
+	}
+
 	if err := fm.fdSet.Close(); err != nil {
 		return nil
 	}
@@ -274,11 +345,17 @@ func (fm *FileManager) Close() error {
 }
 
 func (fm *FileManager) GetCacheStatusList() []interfaces.DBStatus {
+	prefetchCount, prefetchSize, hits, misses := fm.prefetcher.status()
 	return []interfaces.DBStatus{{
 		Name:   "blockDB.fm.cache",
 		Count:  uint64(len(fm.fdSet.fileFdCache)),
 		Size:   uint64(int64(len(fm.fdSet.fileFdCache)) * fm.fileSize),
 		Status: "",
+	}, {
+		Name:   "blockDB.fm.prefetch",
+		Count:  uint64(prefetchCount),
+		Size:   uint64(prefetchSize),
+		Status: fmt.Sprintf("hits: %d, misses: %d", hits, misses),
 	}}
 }
 
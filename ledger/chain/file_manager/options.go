@@ -0,0 +1,132 @@
+package chain_file_manager
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+var syncDurationMetric = monitor.GetOrRegisterHistogramVec("vite_chain_block_file_sync_duration_seconds",
+	"duration of syncing a block file to disk, partitioned by sync mode", nil, "mode")
+
+// SyncMode is when the files written by a flush are synced to disk. The redo log of the flusher is cleaned after the
+// flush, so the data not synced yet can be lost if the machine loses power, but not if only the node crashes.
+type SyncMode string
+
+const (
+	// SyncEveryWrite syncs a file as soon as it's written by a flush, it's the default
+	SyncEveryWrite SyncMode = "write"
+	// SyncEveryFlush syncs the files written by a flush once at the end of the flush
+	SyncEveryFlush SyncMode = "flush"
+	// SyncTimed syncs the files written at the first flush after SyncInterval or on close, the filled files are
+	// still synced before being sealed
+	SyncTimed SyncMode = "timed"
+)
+
+const defaultSyncInterval = time.Second
+
+// Options of the file manager, the zero value syncs every write
+type Options struct {
+	SyncMode     SyncMode      // empty means SyncEveryWrite
+	SyncInterval time.Duration // the interval of SyncTimed, 0 means the default
+
+	// Preallocate allocates the disk space of a file when it's created, the size of the file is not changed
+	Preallocate bool
+
+	// DSync opens the files written with O_DSYNC, every write is synced by the os and SyncMode is ignored
+	DSync bool
+}
+
+func (o *Options) check() error {
+	switch o.SyncMode {
+	case "":
+		o.SyncMode = SyncEveryWrite
+	case SyncEveryWrite, SyncEveryFlush:
+	case SyncTimed:
+		if o.SyncInterval <= 0 {
+			o.SyncInterval = defaultSyncInterval
+		}
+	default:
+		return fmt.Errorf("unknown sync mode: %s", o.SyncMode)
+	}
+	return nil
+}
+
+func (o *Options) writeFlag() int {
+	if o.DSync {
+		return os.O_RDWR | dsyncFlag
+	}
+	return os.O_RDWR
+}
+
+// syncWrite returns true if a file is synced as soon as it's written
+func (fm *FileManager) syncWrite() bool {
+	return !fm.options.DSync && fm.options.SyncMode == SyncEveryWrite
+}
+
+func (fm *FileManager) syncFile(file *os.File) error {
+	defer syncDurationMetric.With(string(fm.options.SyncMode)).ObserveSince(time.Now())
+	return file.Sync()
+}
+
+// written records the file written by a flush, it's synced later if it's not synced as soon as it's written
+func (fm *FileManager) written(fileId uint64) {
+	if fm.options.DSync || fm.options.SyncMode == SyncEveryWrite {
+		return
+	}
+
+	fm.syncMu.Lock()
+	defer fm.syncMu.Unlock()
+	fm.unsynced[fileId] = struct{}{}
+}
+
+// syncWritten syncs the files written by the flushes according to the sync mode, all of them are synced if force is true
+func (fm *FileManager) syncWritten(force bool) error {
+	fm.syncMu.Lock()
+	defer fm.syncMu.Unlock()
+
+	if len(fm.unsynced) == 0 {
+		return nil
+	}
+	if !force && fm.options.SyncMode == SyncTimed && time.Since(fm.lastSyncTime) < fm.options.SyncInterval {
+		return nil
+	}
+
+	for fileId := range fm.unsynced {
+		if err := fm.syncFileId(fileId); err != nil {
+			return err
+		}
+	}
+	fm.lastSyncTime = time.Now()
+	return nil
+}
+
+// syncFilled syncs the file filled up before it's sealed
+func (fm *FileManager) syncFilled(fileId uint64) error {
+	fm.syncMu.Lock()
+	defer fm.syncMu.Unlock()
+
+	if _, ok := fm.unsynced[fileId]; !ok {
+		return nil
+	}
+	return fm.syncFileId(fileId)
+}
+
+// syncFileId assumes fm.syncMu is locked
+func (fm *FileManager) syncFileId(fileId uint64) error {
+	file, err := fm.fdSet.getFileFd(fileId)
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		err = fm.syncFile(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("sync file failed, fileId is %d. Error: %s", fileId, err)
+		}
+	}
+	delete(fm.unsynced, fileId)
+	return nil
+}
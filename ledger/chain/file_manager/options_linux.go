@@ -0,0 +1,23 @@
+//go:build linux
+// +build linux
+
+package chain_file_manager
+
+import (
+	"os"
+	"syscall"
+)
+
+const dsyncFlag = syscall.O_DSYNC
+
+// fallocate mode keeping the size of the file, so the latest location loaded from the size is not changed
+const fallocKeepSize = 0x01
+
+func preallocate(file *os.File, size int64) error {
+	err := syscall.Fallocate(int(file.Fd()), fallocKeepSize, 0, size)
+	if err == syscall.EOPNOTSUPP || err == syscall.ENOSYS {
+		// not supported by the file system
+		return nil
+	}
+	return err
+}
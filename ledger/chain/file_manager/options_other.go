@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package chain_file_manager
+
+import (
+	"os"
+)
+
+const dsyncFlag = os.O_SYNC
+
+// preallocate is only supported on linux
+func preallocate(file *os.File, size int64) error {
+	return nil
+}
@@ -0,0 +1,64 @@
+package chain_file_manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	if _, err := NewFileManagerWithOptions(os.TempDir(), 1024, 1, Options{SyncMode: "never"}); err == nil {
+		t.Fatal("expected unknown sync mode error")
+	}
+
+	for _, options := range []Options{
+		{},
+		{SyncMode: SyncEveryFlush, Preallocate: true},
+		{SyncMode: SyncTimed, SyncInterval: time.Hour},
+		{DSync: true},
+	} {
+		dir, err := ioutil.TempDir("", "file_manager_options")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		const fileSize = 1024
+		fm, err := NewFileManagerWithOptions(dir, fileSize, 2, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data := make([]byte, fileSize+100)
+		for i := range data {
+			data[i] = byte(i)
+		}
+		if _, err := fm.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := fm.Flush(NewLocation(1, 0), fm.LatestLocation(), data); err != nil {
+			t.Fatal(err)
+		}
+
+		// the filled file is synced before sealed, the timed sync waits for the interval
+		fm.syncMu.Lock()
+		_, filledUnsynced := fm.unsynced[1]
+		_, latestUnsynced := fm.unsynced[2]
+		fm.syncMu.Unlock()
+		if filledUnsynced || latestUnsynced != (options.SyncMode == SyncTimed) {
+			t.Fatalf("%+v: unexpected unsynced files %v", options, fm.unsynced)
+		}
+
+		// the size of the preallocated file is not changed
+		if info, err := os.Stat(path.Join(dir, FileName(2))); err != nil || info.Size() != 100 {
+			t.Fatalf("%+v: unexpected size of the latest file", options)
+		}
+
+		fm.Close()
+		if len(fm.unsynced) != 0 {
+			t.Fatalf("%+v: files are not synced on close", options)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package chain_file_manager
+
+import (
+	"io"
+	"sync"
+)
+
+const (
+	prefetchStreamCount = 8 // the sequential readers tracked at the same time, such as sync requests and export jobs
+	prefetchTrigger     = 4 // the sequential reads of a stream before the files are read ahead
+	prefetchFileCount   = 3 // the whole files kept in memory by the prefetcher
+)
+
+// prefetchStream is a sequential reader, next is the location where the next read is expected to start
+type prefetchStream struct {
+	next     Location
+	hits     int
+	lastUsed uint64
+}
+
+// prefetcher detects the sequential reads of the files on disk and reads the whole files into memory
+// ahead of the consumer, so the sequential reads are served without opening and reading the files again and again.
+// The files in the write cache of fdManager are not prefetched.
+type prefetcher struct {
+	fm *FileManager
+
+	mu      sync.Mutex
+	clock   uint64
+	streams []*prefetchStream
+
+	generation uint64 // increased when the files are changed, the files loaded before are dropped
+	files      map[uint64][]byte
+	fileOrder  []uint64
+	loading    map[uint64]struct{}
+
+	hits   uint64
+	misses uint64
+
+	closed bool
+	wg     sync.WaitGroup
+}
+
+func newPrefetcher(fm *FileManager) *prefetcher {
+	return &prefetcher{
+		fm:      fm,
+		files:   make(map[uint64][]byte, prefetchFileCount),
+		loading: make(map[uint64]struct{}),
+	}
+}
+
+// readAt copies the prefetched data of the file to buf, ok is false if the data is not prefetched
+func (pf *prefetcher) readAt(fileId uint64, buf []byte, offset int64) (int, bool) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	data, ok := pf.files[fileId]
+	if !ok || offset+int64(len(buf)) > int64(len(data)) {
+		pf.misses++
+		return 0, false
+	}
+	pf.hits++
+	return copy(buf, data[offset:]), true
+}
+
+// access records a read from start to end, the file of end and the next file are read ahead
+// once the reads of a stream are sequential
+func (pf *prefetcher) access(start *Location, end *Location) {
+	pf.mu.Lock()
+
+	pf.clock++
+	var stream *prefetchStream
+	for _, s := range pf.streams {
+		if s.next.Compare(start) == 0 {
+			stream = s
+			stream.hits++
+			break
+		}
+	}
+	if stream == nil {
+		stream = pf.newStream()
+	}
+	stream.next = *end
+	stream.lastUsed = pf.clock
+
+	if pf.closed || stream.hits < prefetchTrigger {
+		pf.mu.Unlock()
+		return
+	}
+
+	generation := pf.generation
+	current, next := pf.shouldLoad(end.FileId), pf.shouldLoad(end.FileId+1)
+	if next {
+		pf.wg.Add(1)
+	}
+	pf.mu.Unlock()
+
+	// the consumer reads the current file immediately, and the next file later
+	if current {
+		pf.load(end.FileId, generation)
+	}
+	if next {
+		go func() {
+			defer pf.wg.Done()
+			pf.load(end.FileId+1, generation)
+		}()
+	}
+}
+
+// close stops reading ahead and waits for the files being loaded
+func (pf *prefetcher) close() {
+	pf.mu.Lock()
+	pf.closed = true
+	pf.mu.Unlock()
+
+	pf.wg.Wait()
+}
+
+// invalidate drops the prefetched files from fromFileId, it is called when the files on disk are changed
+func (pf *prefetcher) invalidate(fromFileId uint64) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	changed := false
+	for fileId := range pf.loading {
+		if fileId >= fromFileId {
+			changed = true
+		}
+	}
+
+	fileOrder := pf.fileOrder[:0]
+	for _, fileId := range pf.fileOrder {
+		if fileId >= fromFileId {
+			delete(pf.files, fileId)
+			changed = true
+		} else {
+			fileOrder = append(fileOrder, fileId)
+		}
+	}
+	pf.fileOrder = fileOrder
+
+	if changed {
+		// the files being loaded may be read before the change
+		pf.generation++
+	}
+}
+
+func (pf *prefetcher) status() (count int, size int64, hits uint64, misses uint64) {
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	for _, data := range pf.files {
+		size += int64(len(data))
+	}
+	return len(pf.files), size, pf.hits, pf.misses
+}
+
+func (pf *prefetcher) newStream() *prefetchStream {
+	if len(pf.streams) < prefetchStreamCount {
+		stream := &prefetchStream{}
+		pf.streams = append(pf.streams, stream)
+		return stream
+	}
+
+	// replace the least recently used stream
+	stream := pf.streams[0]
+	for _, s := range pf.streams[1:] {
+		if s.lastUsed < stream.lastUsed {
+			stream = s
+		}
+	}
+	stream.hits = 0
+	return stream
+}
+
+// shouldLoad marks the file as loading if it is on disk, and is neither prefetched nor being loaded
+func (pf *prefetcher) shouldLoad(fileId uint64) bool {
+	if !pf.fm.fdSet.onDisk(fileId) {
+		return false
+	}
+	if _, ok := pf.files[fileId]; ok {
+		return false
+	}
+	if _, ok := pf.loading[fileId]; ok {
+		return false
+	}
+	pf.loading[fileId] = struct{}{}
+	return true
+}
+
+func (pf *prefetcher) load(fileId uint64, generation uint64) {
+	data := pf.readFile(fileId)
+
+	pf.mu.Lock()
+	defer pf.mu.Unlock()
+
+	delete(pf.loading, fileId)
+	if data == nil || generation != pf.generation {
+		return
+	}
+
+	if len(pf.fileOrder) >= prefetchFileCount {
+		delete(pf.files, pf.fileOrder[0])
+		pf.fileOrder = pf.fileOrder[1:]
+	}
+	pf.files[fileId] = data
+	pf.fileOrder = append(pf.fileOrder, fileId)
+}
+
+// readFile reads the whole file on disk, nil is returned if the file is in the write cache or not existed
+func (pf *prefetcher) readFile(fileId uint64) []byte {
+	fd, err := pf.fm.fdSet.GetFd(fileId)
+	if err != nil || fd == nil {
+		return nil
+	}
+	defer fd.Close()
+
+	if fd.fileReader == nil {
+		return nil
+	}
+
+	buf := make([]byte, pf.fm.fileSize)
+	n, err := fd.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		pf.fm.log.Warn("prefetch file failed", "fileId", fileId, "err", err, "method", "prefetch")
+		return nil
+	}
+	return buf[:n]
+}
@@ -0,0 +1,65 @@
+package chain_file_manager
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+)
+
+func TestPrefetcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "prefetcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const fileSize = 1024
+	data := make([]byte, 5*fileSize+100)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	for fileId := 1; fileId <= 6; fileId++ {
+		end := fileId * fileSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := ioutil.WriteFile(path.Join(dir, "f"+strconv.Itoa(fileId)), data[(fileId-1)*fileSize:end], 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fm, err := NewFileManager(dir, fileSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fm.Close()
+
+	var result []byte
+	location := NewLocation(1, 0)
+	for len(result) < len(data) {
+		buf := make([]byte, 100)
+		next, n, err := fm.ReadRaw(location, buf)
+		result = append(result, buf[:n]...)
+		if err != nil {
+			break
+		}
+		location = next
+	}
+	if !bytes.Equal(result, data) {
+		t.Fatal("unexpected data")
+	}
+
+	_, _, hits, _ := fm.prefetcher.status()
+	if hits == 0 {
+		t.Fatal("sequential reads are not prefetched")
+	}
+
+	// the prefetched files are dropped after changed
+	fm.prefetcher.invalidate(3)
+	if _, ok := fm.prefetcher.readAt(4, make([]byte, 1), 0); ok {
+		t.Fatal("changed file is prefetched")
+	}
+}
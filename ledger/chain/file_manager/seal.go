@@ -0,0 +1,182 @@
+package chain_file_manager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
+)
+
+// SealManifestName is the manifest of the sealed files in the directory, the name doesn't start with the filename prefix
+const SealManifestName = "seals.json"
+
+const (
+	sealedFileMode   = 0444
+	unsealedFileMode = 0644
+)
+
+// sealManifest keeps the sha256 of the files filled up. A sealed file is read-only, and verified on the first read
+// after restart, so a historical file truncated or changed outside the node is found instead of being read silently.
+type sealManifest struct {
+	mu sync.Mutex
+
+	dirName  string
+	fileSize int64
+
+	digests  map[uint64]string
+	verified map[uint64]bool
+}
+
+type sealManifestData struct {
+	Files map[uint64]string `json:"files"`
+}
+
+func loadSealManifest(dirName string, fileSize int64) (*sealManifest, error) {
+	sm := &sealManifest{
+		dirName:  dirName,
+		fileSize: fileSize,
+		digests:  make(map[uint64]string),
+		verified: make(map[uint64]bool),
+	}
+
+	data, err := ioutil.ReadFile(path.Join(dirName, SealManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return sm, nil
+		}
+		return nil, err
+	}
+	manifest := &sealManifestData{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parse %s failed. Error: %s", SealManifestName, err)
+	}
+	if manifest.Files != nil {
+		sm.digests = manifest.Files
+	}
+	return sm, nil
+}
+
+func (sm *sealManifest) isSealed(fileId uint64) bool {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	_, ok := sm.digests[fileId]
+	return ok
+}
+
+// seal computes the digest of the file filled up and sets it read-only
+func (sm *sealManifest) seal(fileId uint64) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.digests[fileId]; ok {
+		return nil
+	}
+
+	filename := sm.filename(fileId)
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	digest, err := sm.digest(file)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("seal %s failed. Error: %s", filename, err)
+	}
+
+	if err := os.Chmod(filename, sealedFileMode); err != nil {
+		return err
+	}
+	sm.digests[fileId] = digest
+	sm.verified[fileId] = true
+	return sm.save()
+}
+
+// unseal sets the file writable again before it's rolled back
+func (sm *sealManifest) unseal(fileId uint64) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if _, ok := sm.digests[fileId]; !ok {
+		return nil
+	}
+
+	if err := os.Chmod(sm.filename(fileId), unsealedFileMode); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	delete(sm.digests, fileId)
+	delete(sm.verified, fileId)
+	return sm.save()
+}
+
+// verify checks the digest of the sealed file on the first read, the unsealed files are not checked
+func (sm *sealManifest) verify(fileId uint64, file *os.File) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	expected, ok := sm.digests[fileId]
+	if !ok || sm.verified[fileId] {
+		return nil
+	}
+
+	digest, err := sm.digest(file)
+	if err != nil {
+		return fmt.Errorf("%w: sealed file %s is broken. Error: %s", chain_errors.ErrDBCorrupted, sm.filename(fileId), err)
+	}
+	if digest != expected {
+		return fmt.Errorf("%w: sealed file %s is changed, sha256 is %s, expected %s", chain_errors.ErrDBCorrupted, sm.filename(fileId), digest, expected)
+	}
+	sm.verified[fileId] = true
+	return nil
+}
+
+func (sm *sealManifest) digest(file *os.File) (string, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, io.NewSectionReader(file, 0, sm.fileSize+1))
+	if err != nil {
+		return "", err
+	}
+	if n != sm.fileSize {
+		return "", fmt.Errorf("size is %d, expected %d", n, sm.fileSize)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (sm *sealManifest) save() error {
+	data, err := json.Marshal(&sealManifestData{Files: sm.digests})
+	if err != nil {
+		return err
+	}
+
+	manifestFile := path.Join(sm.dirName, SealManifestName)
+	tmpFile := manifestFile + ".tmp"
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile, manifestFile)
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+	}
+	return err
+}
+
+func (sm *sealManifest) filename(fileId uint64) string {
+	return path.Join(sm.dirName, FileName(fileId))
+}
@@ -0,0 +1,76 @@
+package chain_file_manager
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+)
+
+func TestSealManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "seal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const fileSize = 1024
+	for fileId := 1; fileId <= 3; fileId++ {
+		size := fileSize
+		if fileId == 3 {
+			size = 100
+		}
+		if err := ioutil.WriteFile(path.Join(dir, "f"+strconv.Itoa(fileId)), make([]byte, size), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the file before the latest is sealed at startup
+	fm, err := NewFileManager(dir, fileSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fm.fdSet.seals.isSealed(2) || fm.fdSet.seals.isSealed(3) {
+		t.Fatal("unexpected sealed files")
+	}
+	if err := fm.fdSet.seals.seal(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := fm.fdSet.seals.seal(3); err == nil {
+		t.Fatal("the file not filled up is sealed")
+	}
+	if info, err := os.Stat(path.Join(dir, "f1")); err != nil || info.Mode().Perm() != sealedFileMode {
+		t.Fatal("the sealed file is not read-only")
+	}
+	fm.Close()
+
+	// change the sealed file
+	f1 := path.Join(dir, "f1")
+	if err := os.Chmod(f1, unsealedFileMode); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(f1, append(make([]byte, fileSize-1), 1), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	fm, err = NewFileManager(dir, fileSize, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fm.Close()
+	if _, _, err := fm.ReadRaw(NewLocation(2, 0), make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := fm.ReadRaw(NewLocation(1, 0), make([]byte, 10)); err == nil {
+		t.Fatal("the changed file is read")
+	}
+
+	// the unsealed file isn't verified
+	if err := fm.fdSet.seals.unseal(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := fm.ReadRaw(NewLocation(1, 0), make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -14,8 +14,12 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
 )
 
+var flushDurationMetric = monitor.GetOrRegisterHistogramVec("vite_chain_flush_duration_seconds",
+	"duration of flushing the chain to disk, partitioned by result", nil, "result")
+
 const (
 	stop    = 0
 	start   = 1
@@ -63,6 +67,8 @@ type Flusher struct {
 
 	terminal      chan struct{}
 	flusherStatus int32
+
+	recovering int32
 }
 
 func NewFlusher(storeList []Storage, flushMu *sync.RWMutex, chainDir string) (*Flusher, error) {
@@ -149,6 +155,17 @@ func (flusher *Flusher) Stop() {
 	flusher.wg.Wait()
 }
 
+// Running returns false if the flusher is stopped or aborted
+func (flusher *Flusher) Running() bool {
+	return atomic.LoadInt32(&flusher.flusherStatus) == start
+}
+
+// Recovering returns true if the last flush is not committed as it's prepared, the redo log is replayed or the
+// flush is retried later, the data written since is not on disk yet
+func (flusher *Flusher) Recovering() bool {
+	return atomic.LoadInt32(&flusher.recovering) == 1
+}
+
 // force to flush synchronously
 func (flusher *Flusher) Flush() {
 	flusher.flush()
@@ -202,10 +219,39 @@ func (flusher *Flusher) loopFlush() {
 	}()
 }
 
+// Checkpoint flushes the stores and calls f at the flush boundary, the data on disk is consistent while f is running.
+// The writing of the chain is locked and no flush is committed until f returns, f should return quickly.
+func (flusher *Flusher) Checkpoint(f func() error) error {
+	flusher.flushingMu.Lock()
+	defer flusher.flushingMu.Unlock()
+
+	flusher.flushLocked()
+
+	flusher.mu.Lock()
+	defer flusher.mu.Unlock()
+
+	return f()
+}
+
 func (flusher *Flusher) flush() {
 	flusher.flushingMu.Lock()
 	defer flusher.flushingMu.Unlock()
 
+	flusher.flushLocked()
+}
+
+// flushLocked assumes flusher.flushingMu is locked
+func (flusher *Flusher) flushLocked() {
+	result := "failed"
+	defer func(start time.Time) {
+		flushDurationMetric.With(result).ObserveSince(start)
+		if result == "ok" {
+			atomic.StoreInt32(&flusher.recovering, 0)
+		} else {
+			atomic.StoreInt32(&flusher.recovering, 1)
+		}
+	}(time.Now())
+
 	// prepare, lock write
 	//flusher.log.Info("start prepare")
 	if err := flusher.prepare(); err != nil {
@@ -253,6 +299,12 @@ func (flusher *Flusher) flush() {
 
 	// clean redo log
 	flusher.cleanRedoLog()
+
+	if err != nil {
+		result = "redo"
+	} else {
+		result = "ok"
+	}
 }
 
 func (flusher *Flusher) commitRedo() error {
@@ -0,0 +1,421 @@
+package chain_genesis
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+)
+
+const (
+	defaultSbpExpirationHeight   = 7776000
+	defaultStakeExpirationHeight = 1
+)
+
+var (
+	attov = new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+
+	// 1,000,000,000 VITE
+	defaultTotalSupply = new(big.Int).Mul(big.NewInt(1e9), attov)
+	// 500,000 VITE
+	defaultSbpStakeAmount = new(big.Int).Mul(big.NewInt(5e5), attov)
+)
+
+// Spec describes a custom genesis, the absent fields are filled with the defaults of a local network.
+// The amounts are decimal strings in the smallest unit.
+type Spec struct {
+	// owner of the consensus groups, the rest of the total supply goes to the genesis account
+	GenesisAccount string         `yaml:"genesisAccount" json:"genesisAccount"`
+	Upgrade        *UpgradeSpec   `yaml:"upgrade" json:"upgrade"`
+	Token          *TokenSpec     `yaml:"token" json:"token"`
+	Consensus      *ConsensusSpec `yaml:"consensus" json:"consensus"`
+	Sbps           []*SbpSpec     `yaml:"sbps" json:"sbps"`
+	Accounts       []*AccountSpec `yaml:"accounts" json:"accounts"`
+	Stakes         []*StakeSpec   `yaml:"stakes" json:"stakes"`
+}
+
+// UpgradeSpec is the fork heights, level is "latest", "mainnet" or "custom", the forks are used by "custom"
+type UpgradeSpec struct {
+	Level string      `yaml:"level" json:"level"`
+	Forks []*ForkSpec `yaml:"forks" json:"forks"`
+}
+
+type ForkSpec struct {
+	Name    string `yaml:"name" json:"name"`
+	Height  uint64 `yaml:"height" json:"height"`
+	Version uint32 `yaml:"version" json:"version"`
+}
+
+// TokenSpec is the native token, the token id is always tti_5649544520544f4b454e6e40
+type TokenSpec struct {
+	Name        string `yaml:"name" json:"name"`
+	Symbol      string `yaml:"symbol" json:"symbol"`
+	Decimals    uint8  `yaml:"decimals" json:"decimals"`
+	TotalSupply string `yaml:"totalSupply" json:"totalSupply"`
+}
+
+type ConsensusSpec struct {
+	Snapshot *GroupSpec `yaml:"snapshot" json:"snapshot"`
+	Delegate *GroupSpec `yaml:"delegate" json:"delegate"`
+
+	// the condition to register an sbp
+	SbpStakeAmount string `yaml:"sbpStakeAmount" json:"sbpStakeAmount"`
+	SbpStakeHeight uint64 `yaml:"sbpStakeHeight" json:"sbpStakeHeight"`
+}
+
+// GroupSpec is the parameters of a consensus group, the zero values are the defaults
+type GroupSpec struct {
+	NodeCount  uint8  `yaml:"nodeCount" json:"nodeCount"`
+	Interval   int64  `yaml:"interval" json:"interval"`
+	PerCount   int64  `yaml:"perCount" json:"perCount"`
+	RandCount  uint8  `yaml:"randCount" json:"randCount"`
+	RandRank   uint8  `yaml:"randRank" json:"randRank"`
+	Repeat     uint16 `yaml:"repeat" json:"repeat"`
+	CheckLevel uint8  `yaml:"checkLevel" json:"checkLevel"`
+}
+
+// SbpSpec is an initial sbp of the snapshot consensus group, the stake is locked in the governance contract
+type SbpSpec struct {
+	Name                  string `yaml:"name" json:"name"`
+	BlockProducingAddress string `yaml:"blockProducingAddress" json:"blockProducingAddress"`
+	StakeAddress          string `yaml:"stakeAddress" json:"stakeAddress"`
+	Amount                string `yaml:"amount" json:"amount"`
+}
+
+type AccountSpec struct {
+	Address string `yaml:"address" json:"address"`
+	Balance string `yaml:"balance" json:"balance"`
+}
+
+// StakeSpec is a stake for quota, the amount is locked in the quota contract
+type StakeSpec struct {
+	Address          string `yaml:"address" json:"address"`
+	Beneficiary      string `yaml:"beneficiary" json:"beneficiary"`
+	Amount           string `yaml:"amount" json:"amount"`
+	ExpirationHeight uint64 `yaml:"expirationHeight" json:"expirationHeight"`
+}
+
+// LoadSpec reads the spec from a yaml or json file, the format is decided by the extension
+func LoadSpec(filename string) (*Spec, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		err = json.Unmarshal(data, spec)
+	default:
+		err = yaml.UnmarshalStrict(data, spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse genesis spec %s failed. Error: %s", filename, err)
+	}
+	return spec, nil
+}
+
+// Build makes the genesis config of the spec and validates it
+func (s *Spec) Build() (*config.Genesis, error) {
+	if len(s.Sbps) == 0 {
+		return nil, errors.New("at least one sbp is required")
+	}
+	genesisAccount, err := types.HexToAddress(s.GenesisAccount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis account. Error: %s", err)
+	}
+
+	g := &config.Genesis{
+		GenesisAccountAddress: &genesisAccount,
+		UpgradeCfg:            s.buildUpgrade(),
+		AccountBalanceMap:     make(map[string]map[string]*big.Int),
+	}
+
+	token := s.Token
+	if token == nil {
+		token = &TokenSpec{}
+	}
+	totalSupply, err := parseAmount(token.TotalSupply, defaultTotalSupply)
+	if err != nil {
+		return nil, fmt.Errorf("invalid total supply. Error: %s", err)
+	}
+	g.AssetInfo, err = buildAssetInfo(token, totalSupply)
+	if err != nil {
+		return nil, err
+	}
+
+	consensus := s.Consensus
+	if consensus == nil {
+		consensus = &ConsensusSpec{}
+	}
+	sbpStakeAmount, err := parseAmount(consensus.SbpStakeAmount, defaultSbpStakeAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sbp stake amount. Error: %s", err)
+	}
+
+	g.GovernanceInfo = &config.GovernanceContractInfo{
+		ConsensusGroupInfoMap: make(map[string]*config.ConsensusGroupInfo),
+		RegistrationInfoMap:   make(map[string]map[string]*config.RegistrationInfo),
+	}
+
+	nodeCount := uint8(len(s.Sbps))
+	snapshotGroup := &GroupSpec{NodeCount: nodeCount, Interval: 1, PerCount: 3, RandRank: 100, Repeat: 1, CheckLevel: 0}
+	delegateGroup := &GroupSpec{NodeCount: nodeCount, Interval: 3, PerCount: 1, RandRank: 100, Repeat: 48, CheckLevel: 1}
+	for _, item := range []struct {
+		gid      types.Gid
+		defaults *GroupSpec
+		spec     *GroupSpec
+	}{
+		{types.SNAPSHOT_GID, snapshotGroup, consensus.Snapshot},
+		{types.DELEGATE_GID, delegateGroup, consensus.Delegate},
+	} {
+		g.GovernanceInfo.ConsensusGroupInfoMap[item.gid.String()] = buildGroup(item.defaults, item.spec, genesisAccount, sbpStakeAmount, consensus.SbpStakeHeight)
+	}
+
+	locked := make(map[types.Address]*big.Int)
+	lock := func(addr types.Address, amount *big.Int) {
+		if locked[addr] == nil {
+			locked[addr] = new(big.Int)
+		}
+		locked[addr].Add(locked[addr], amount)
+	}
+
+	registrations := make(map[string]*config.RegistrationInfo)
+	for _, sbp := range s.Sbps {
+		if sbp.Name == "" {
+			return nil, errors.New("sbp name is empty")
+		}
+		if _, ok := registrations[sbp.Name]; ok {
+			return nil, fmt.Errorf("duplicate sbp name %s", sbp.Name)
+		}
+		producer, err := types.HexToAddress(sbp.BlockProducingAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block producing address of sbp %s. Error: %s", sbp.Name, err)
+		}
+		stakeAddr := producer
+		if sbp.StakeAddress != "" {
+			if stakeAddr, err = types.HexToAddress(sbp.StakeAddress); err != nil {
+				return nil, fmt.Errorf("invalid stake address of sbp %s. Error: %s", sbp.Name, err)
+			}
+		}
+		amount, err := parseAmount(sbp.Amount, sbpStakeAmount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount of sbp %s. Error: %s", sbp.Name, err)
+		}
+
+		registrations[sbp.Name] = &config.RegistrationInfo{
+			BlockProducingAddress: &producer,
+			StakeAddress:          &stakeAddr,
+			Amount:                amount,
+			ExpirationHeight:      defaultSbpExpirationHeight,
+			RewardTime:            1,
+			HistoryAddressList:    []types.Address{producer},
+		}
+		lock(types.AddressGovernance, amount)
+	}
+	g.GovernanceInfo.RegistrationInfoMap[types.SNAPSHOT_GID.String()] = registrations
+
+	if len(s.Stakes) > 0 {
+		g.QuotaInfo = &config.QuotaContractInfo{
+			StakeInfoMap:       make(map[string][]*config.StakeInfo),
+			StakeBeneficialMap: make(map[string]*big.Int),
+		}
+	}
+	for _, stake := range s.Stakes {
+		addr, err := types.HexToAddress(stake.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stake address. Error: %s", err)
+		}
+		beneficiary := addr
+		if stake.Beneficiary != "" {
+			if beneficiary, err = types.HexToAddress(stake.Beneficiary); err != nil {
+				return nil, fmt.Errorf("invalid stake beneficiary. Error: %s", err)
+			}
+		}
+		amount, err := parseAmount(stake.Amount, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stake amount of %s. Error: %s", addr, err)
+		}
+		expirationHeight := stake.ExpirationHeight
+		if expirationHeight == 0 {
+			expirationHeight = defaultStakeExpirationHeight
+		}
+
+		g.QuotaInfo.StakeInfoMap[addr.String()] = append(g.QuotaInfo.StakeInfoMap[addr.String()], &config.StakeInfo{
+			Amount:           amount,
+			ExpirationHeight: expirationHeight,
+			Beneficiary:      &beneficiary,
+		})
+		if beneficial, ok := g.QuotaInfo.StakeBeneficialMap[beneficiary.String()]; ok {
+			beneficial.Add(beneficial, amount)
+		} else {
+			g.QuotaInfo.StakeBeneficialMap[beneficiary.String()] = new(big.Int).Set(amount)
+		}
+		lock(types.AddressQuota, amount)
+	}
+
+	// the locked amounts are the balances of the contracts, the rest of the supply goes to the genesis account
+	rest := new(big.Int).Set(totalSupply)
+	addBalance := func(addr types.Address, amount *big.Int) {
+		balances, ok := g.AccountBalanceMap[addr.String()]
+		if !ok {
+			balances = make(map[string]*big.Int)
+			g.AccountBalanceMap[addr.String()] = balances
+		}
+		if balance, ok := balances[ledger.ViteTokenId.String()]; ok {
+			balance.Add(balance, amount)
+		} else {
+			balances[ledger.ViteTokenId.String()] = new(big.Int).Set(amount)
+		}
+		rest.Sub(rest, amount)
+	}
+	for _, addr := range []types.Address{types.AddressGovernance, types.AddressQuota} {
+		if amount, ok := locked[addr]; ok {
+			addBalance(addr, amount)
+		}
+	}
+	for _, account := range s.Accounts {
+		addr, err := types.HexToAddress(account.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account address. Error: %s", err)
+		}
+		balance, err := parseAmount(account.Balance, nil)
+		if err != nil {
+			return nil, fmt.Errorf("invalid balance of %s. Error: %s", addr, err)
+		}
+		addBalance(addr, balance)
+	}
+	if rest.Sign() < 0 {
+		return nil, fmt.Errorf("the balances exceed the total supply %s", totalSupply)
+	}
+	if rest.Sign() > 0 {
+		addBalance(genesisAccount, rest)
+	}
+
+	if err := Validate(g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *Spec) buildUpgrade() *config.Upgrade {
+	if s.Upgrade == nil || s.Upgrade.Level == "" {
+		return &config.Upgrade{Level: "latest"}
+	}
+	cfg := &config.Upgrade{Level: s.Upgrade.Level}
+	if len(s.Upgrade.Forks) > 0 {
+		cfg.Points = make(map[string]*upgrade.UpgradePoint, len(s.Upgrade.Forks))
+		for _, fork := range s.Upgrade.Forks {
+			cfg.Points[fork.Name] = &upgrade.UpgradePoint{Name: fork.Name, Height: fork.Height, Version: fork.Version}
+		}
+	}
+	return cfg
+}
+
+func buildAssetInfo(token *TokenSpec, totalSupply *big.Int) (*config.AssetContractInfo, error) {
+	name, symbol, decimals := token.Name, token.Symbol, token.Decimals
+	if name == "" {
+		name = "VITE"
+	}
+	if symbol == "" {
+		symbol = "VITE"
+	}
+	if token.Decimals == 0 && token.TotalSupply == "" {
+		decimals = 18
+	}
+
+	topics, data, err := abi.ABIAsset.PackEvent("issue", ledger.ViteTokenId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config.AssetContractInfo{
+		TokenInfoMap: map[string]*config.TokenInfo{
+			ledger.ViteTokenId.String(): {
+				TokenName:    name,
+				TokenSymbol:  symbol,
+				TotalSupply:  totalSupply,
+				Decimals:     decimals,
+				Owner:        types.AddressGovernance,
+				MaxSupply:    new(big.Int).Set(helper.Tt256m1),
+				IsReIssuable: true,
+			},
+		},
+		LogList: []*config.GenesisVmLog{{Data: hex.EncodeToString(data), Topics: topics}},
+	}, nil
+}
+
+func buildGroup(defaults *GroupSpec, spec *GroupSpec, owner types.Address, stakeAmount *big.Int, stakeHeight uint64) *config.ConsensusGroupInfo {
+	group := *defaults
+	if spec != nil {
+		if spec.NodeCount > 0 {
+			group.NodeCount = spec.NodeCount
+		}
+		if spec.Interval > 0 {
+			group.Interval = spec.Interval
+		}
+		if spec.PerCount > 0 {
+			group.PerCount = spec.PerCount
+		}
+		if spec.RandRank > 0 {
+			group.RandRank = spec.RandRank
+		}
+		if spec.Repeat > 0 {
+			group.Repeat = spec.Repeat
+		}
+		group.RandCount = spec.RandCount
+		group.CheckLevel = spec.CheckLevel
+	}
+	if stakeHeight == 0 {
+		stakeHeight = 1
+	}
+
+	return &config.ConsensusGroupInfo{
+		NodeCount:           group.NodeCount,
+		Interval:            group.Interval,
+		PerCount:            group.PerCount,
+		RandCount:           group.RandCount,
+		RandRank:            group.RandRank,
+		Repeat:              group.Repeat,
+		CheckLevel:          group.CheckLevel,
+		CountingTokenId:     ledger.ViteTokenId,
+		RegisterConditionId: 1,
+		RegisterConditionParam: config.RegisterConditionParam{
+			StakeAmount: new(big.Int).Set(stakeAmount),
+			StakeToken:  ledger.ViteTokenId,
+			StakeHeight: stakeHeight,
+		},
+		VoteConditionId:  1,
+		Owner:            owner,
+		StakeAmount:      big.NewInt(0),
+		ExpirationHeight: 1,
+	}
+}
+
+// parseAmount parses a decimal amount, the empty string is the default, nil default means the amount is required
+func parseAmount(s string, defaultAmount *big.Int) (*big.Int, error) {
+	if s == "" {
+		if defaultAmount == nil {
+			return nil, errors.New("amount is empty")
+		}
+		return new(big.Int).Set(defaultAmount), nil
+	}
+	amount, ok := new(big.Int).SetString(s, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %s", s)
+	}
+	return amount, nil
+}
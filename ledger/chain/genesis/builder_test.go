@@ -0,0 +1,85 @@
+package chain_genesis
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+const testSpec = `
+genesisAccount: vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a
+upgrade:
+  level: custom
+  forks:
+    - {name: SeedFork, height: 1, version: 1}
+    - {name: DexFork, height: 100, version: 2}
+consensus:
+  snapshot: {interval: 2}
+sbps:
+  - name: s1
+    blockProducingAddress: vite_360232b0378111b122685a15e612143dc9a89cfa7e803f4b5a
+  - name: s2
+    blockProducingAddress: vite_ce18b99b46c70c8e6bf34177d0c5db956a8c3ea7040a1c1e25
+accounts:
+  - address: vite_56fd05b23ff26cd7b0a40957fb77bde60c9fd6ebc35f809c23
+    balance: "1000000000000000000000"
+stakes:
+  - address: vite_56fd05b23ff26cd7b0a40957fb77bde60c9fd6ebc35f809c23
+    amount: "1000000000000000000000"
+`
+
+func TestSpec_Build(t *testing.T) {
+	dir, err := ioutil.TempDir("", "genesis_spec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "genesis.yaml")
+	if err := ioutil.WriteFile(filename, []byte(testSpec), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := LoadSpec(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := spec.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group := g.GovernanceInfo.ConsensusGroupInfoMap[types.SNAPSHOT_GID.String()]
+	if group.NodeCount != 2 || group.Interval != 2 || group.PerCount != 3 {
+		t.Fatalf("unexpected snapshot group %+v", group)
+	}
+	if len(g.GovernanceInfo.RegistrationInfoMap[types.SNAPSHOT_GID.String()]) != 2 {
+		t.Fatal("sbps are not registered")
+	}
+	if balance := balanceOf(g, types.AddressGovernance, ledger.ViteTokenId); balance.Cmp(defaultSbpStakeAmount) <= 0 {
+		t.Fatalf("balance of the governance contract is %s", balance)
+	}
+
+	// the balances are changed
+	genesisBalance := g.AccountBalanceMap[g.GenesisAccountAddress.String()][ledger.ViteTokenId.String()]
+	genesisBalance.Add(genesisBalance, defaultSbpStakeAmount)
+	if err := Validate(g); err == nil {
+		t.Fatal("the balances exceed the total supply")
+	}
+
+	spec.Sbps[1].BlockProducingAddress = spec.Sbps[0].BlockProducingAddress
+	if _, err := spec.Build(); err == nil {
+		t.Fatal("the sbps have the same block producing address")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(config.MainnetGenesis()); err != nil {
+		t.Fatal(err)
+	}
+}
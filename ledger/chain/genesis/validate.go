@@ -0,0 +1,195 @@
+package chain_genesis
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// Validate checks the genesis config before it is used to init a ledger: the fork heights, the consensus groups,
+// the sbps, the token supplies and the balances locked in the governance and quota contracts.
+func Validate(g *config.Genesis) error {
+	if !config.IsCompleteGenesisConfig(g) {
+		return errors.New("genesis is not complete, the consensus groups, sbps, tokens and balances are required")
+	}
+	if g.GenesisAccountAddress == nil {
+		return errors.New("genesis account is empty")
+	}
+
+	if err := validateUpgrade(g.UpgradeCfg); err != nil {
+		return err
+	}
+
+	groups, err := validateGovernance(g.GovernanceInfo)
+	if err != nil {
+		return err
+	}
+
+	supplies := make(map[types.TokenTypeId]*big.Int)
+	for tokenIdStr, token := range g.AssetInfo.TokenInfoMap {
+		tokenId, err := types.HexToTokenTypeId(tokenIdStr)
+		if err != nil {
+			return fmt.Errorf("invalid token id %s. Error: %s", tokenIdStr, err)
+		}
+		if token.TotalSupply == nil || token.TotalSupply.Sign() <= 0 {
+			return fmt.Errorf("total supply of token %s should be positive", tokenIdStr)
+		}
+		if token.IsReIssuable && (token.MaxSupply == nil || token.MaxSupply.Cmp(token.TotalSupply) < 0) {
+			return fmt.Errorf("max supply of token %s is less than the total supply", tokenIdStr)
+		}
+		supplies[tokenId] = new(big.Int)
+	}
+
+	for addrStr, balances := range g.AccountBalanceMap {
+		if _, err := types.HexToAddress(addrStr); err != nil {
+			return fmt.Errorf("invalid address %s. Error: %s", addrStr, err)
+		}
+		for tokenIdStr, balance := range balances {
+			tokenId, err := types.HexToTokenTypeId(tokenIdStr)
+			if err != nil {
+				return fmt.Errorf("invalid token id %s of %s. Error: %s", tokenIdStr, addrStr, err)
+			}
+			supply, ok := supplies[tokenId]
+			if !ok {
+				return fmt.Errorf("token %s of %s is not issued", tokenIdStr, addrStr)
+			}
+			if balance == nil || balance.Sign() < 0 {
+				return fmt.Errorf("balance of %s is negative", addrStr)
+			}
+			supply.Add(supply, balance)
+		}
+	}
+	for tokenIdStr, token := range g.AssetInfo.TokenInfoMap {
+		tokenId, _ := types.HexToTokenTypeId(tokenIdStr)
+		if supplies[tokenId].Cmp(token.TotalSupply) != 0 {
+			return fmt.Errorf("sum of the balances of token %s is %s, total supply is %s", tokenIdStr, supplies[tokenId], token.TotalSupply)
+		}
+	}
+
+	// the stakes of the sbps are locked in the governance contract
+	locked := make(map[types.TokenTypeId]*big.Int)
+	for gid, registrations := range g.GovernanceInfo.RegistrationInfoMap {
+		stakeToken := groups[gid].RegisterConditionParam.StakeToken
+		if locked[stakeToken] == nil {
+			locked[stakeToken] = new(big.Int)
+		}
+		for _, registration := range registrations {
+			locked[stakeToken].Add(locked[stakeToken], registration.Amount)
+		}
+	}
+	for tokenId, amount := range locked {
+		if balance := balanceOf(g, types.AddressGovernance, tokenId); balance.Cmp(amount) != 0 {
+			return fmt.Errorf("balance of the governance contract is %s, the stakes of the sbps are %s", balance, amount)
+		}
+	}
+
+	return validateQuota(g)
+}
+
+func validateUpgrade(cfg *config.Upgrade) (err error) {
+	if cfg == nil {
+		return errors.New("upgrade config is empty")
+	}
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("invalid upgrade config. Error: %v", e)
+		}
+	}()
+	cfg.MakeUpgradeBox()
+	return nil
+}
+
+func validateGovernance(info *config.GovernanceContractInfo) (map[string]*config.ConsensusGroupInfo, error) {
+	if _, ok := info.ConsensusGroupInfoMap[types.SNAPSHOT_GID.String()]; !ok {
+		return nil, errors.New("snapshot consensus group is absent")
+	}
+	for gidStr, group := range info.ConsensusGroupInfoMap {
+		if _, err := types.HexToGid(gidStr); err != nil {
+			return nil, fmt.Errorf("invalid gid %s. Error: %s", gidStr, err)
+		}
+		if group.NodeCount == 0 || group.Interval <= 0 || group.PerCount <= 0 || group.Repeat == 0 {
+			return nil, fmt.Errorf("consensus group %s: node count, interval, per count and repeat should be positive", gidStr)
+		}
+		if group.RandCount > group.NodeCount {
+			return nil, fmt.Errorf("consensus group %s: rand count %d is greater than node count %d", gidStr, group.RandCount, group.NodeCount)
+		}
+		if group.RegisterConditionParam.StakeAmount == nil {
+			return nil, fmt.Errorf("consensus group %s: register stake amount is empty", gidStr)
+		}
+	}
+
+	if len(info.RegistrationInfoMap[types.SNAPSHOT_GID.String()]) == 0 {
+		return nil, errors.New("no sbp is registered in the snapshot consensus group")
+	}
+	for gidStr, registrations := range info.RegistrationInfoMap {
+		group, ok := info.ConsensusGroupInfoMap[gidStr]
+		if !ok {
+			return nil, fmt.Errorf("sbps are registered in the absent consensus group %s", gidStr)
+		}
+		producers := make(map[types.Address]string)
+		for name, registration := range registrations {
+			if registration.BlockProducingAddress == nil || registration.StakeAddress == nil {
+				return nil, fmt.Errorf("sbp %s: block producing address and stake address are required", name)
+			}
+			if other, ok := producers[*registration.BlockProducingAddress]; ok {
+				return nil, fmt.Errorf("sbp %s and %s have the same block producing address %s", name, other, registration.BlockProducingAddress)
+			}
+			producers[*registration.BlockProducingAddress] = name
+			if registration.Amount == nil || registration.Amount.Cmp(group.RegisterConditionParam.StakeAmount) < 0 {
+				return nil, fmt.Errorf("sbp %s: stake amount is less than %s", name, group.RegisterConditionParam.StakeAmount)
+			}
+		}
+	}
+	return info.ConsensusGroupInfoMap, nil
+}
+
+// validateQuota checks the stakes for quota are locked in the quota contract and the beneficial amounts are the sums
+func validateQuota(g *config.Genesis) error {
+	if g.QuotaInfo == nil {
+		return nil
+	}
+
+	total := new(big.Int)
+	beneficial := make(map[types.Address]*big.Int)
+	for addrStr, stakes := range g.QuotaInfo.StakeInfoMap {
+		for _, stake := range stakes {
+			if stake.Amount == nil || stake.Amount.Sign() <= 0 || stake.Beneficiary == nil {
+				return fmt.Errorf("invalid stake of %s", addrStr)
+			}
+			total.Add(total, stake.Amount)
+			if beneficial[*stake.Beneficiary] == nil {
+				beneficial[*stake.Beneficiary] = new(big.Int)
+			}
+			beneficial[*stake.Beneficiary].Add(beneficial[*stake.Beneficiary], stake.Amount)
+		}
+	}
+
+	if len(beneficial) != len(g.QuotaInfo.StakeBeneficialMap) {
+		return errors.New("the stake beneficiaries don't match the stakes")
+	}
+	for addrStr, amount := range g.QuotaInfo.StakeBeneficialMap {
+		addr, err := types.HexToAddress(addrStr)
+		if err != nil {
+			return fmt.Errorf("invalid beneficiary %s. Error: %s", addrStr, err)
+		}
+		if beneficial[addr] == nil || amount == nil || beneficial[addr].Cmp(amount) != 0 {
+			return fmt.Errorf("beneficial amount of %s doesn't match the stakes", addrStr)
+		}
+	}
+
+	if balance := balanceOf(g, types.AddressQuota, ledger.ViteTokenId); balance.Cmp(total) != 0 {
+		return fmt.Errorf("balance of the quota contract is %s, the stakes are %s", balance, total)
+	}
+	return nil
+}
+
+func balanceOf(g *config.Genesis, addr types.Address, tokenId types.TokenTypeId) *big.Int {
+	if balance := g.AccountBalanceMap[addr.String()][tokenId.String()]; balance != nil {
+		return balance
+	}
+	return big.NewInt(0)
+}
@@ -35,6 +35,11 @@ func (iDB *IndexDB) newCache() error {
 	return nil
 }
 
+// ResizeAccountCache changes the size of the account cache, the least recently used accounts are evicted
+func (iDB *IndexDB) ResizeAccountCache(size int) {
+	iDB.accountCache.Resize(size)
+}
+
 func (iDB *IndexDB) initCache(c Chain) error {
 	var returnErr error
 	c.IterateContracts(func(addr types.Address, meta *ledger.ContractMeta, err error) bool {
@@ -100,6 +100,8 @@ func (iDB *IndexDB) GetStatus() []interfaces.DBStatus {
 	}
 
 	statusList := iDB.store.GetStatus()
+	levelDBStatus := statusList[1]
+	levelDBStatus.Name = "indexDB.store.levelDB"
 
 	return []interfaces.DBStatus{{
 		Name:   "indexDB.cache",
@@ -121,10 +123,5 @@ func (iDB *IndexDB) GetStatus() []interfaces.DBStatus {
 		Count:  uint64(statusList[0].Count),
 		Size:   uint64(statusList[0].Size),
 		Status: statusList[0].Status,
-	}, {
-		Name:   "indexDB.store.levelDB",
-		Count:  uint64(statusList[1].Count),
-		Size:   uint64(statusList[1].Size),
-		Status: statusList[1].Status,
-	}}
+	}, levelDBStatus}
 }
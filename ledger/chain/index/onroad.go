@@ -116,6 +116,33 @@ func (iDB *IndexDB) GetOnRoadHashList(addr types.Address, pageNum, pageSize int)
 	return hashList, nil
 }
 
+// GetOnRoadHashListAfter returns at most size hashes of the onroad blocks of addr sorted by hash,
+// starting after the hash if it's not nil. Unlike the page of GetOnRoadHashList, the start is not shifted
+// by the onroad blocks received before it.
+func (iDB *IndexDB) GetOnRoadHashListAfter(addr types.Address, after *types.Hash, size int) ([]types.Hash, error) {
+	hashList := make([]types.Hash, 0, size)
+	rng := util.BytesPrefix(append([]byte{chain_utils.OnRoadKeyPrefix}, addr.Bytes()...))
+	if after != nil {
+		rng.Start = append(chain_utils.CreateOnRoadKey(addr, *after).Bytes(), 0)
+	}
+	iter := iDB.store.NewIterator(rng)
+	defer iter.Release()
+
+	for len(hashList) < size && iter.Next() {
+		key := iter.Key()
+		blockHash, err := types.BytesToHash(key[len(key)-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		hashList = append(hashList, blockHash)
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return hashList, nil
+}
+
 func (iDB *IndexDB) insertOnRoad(batch interfaces.Batch, toAddr types.Address, blockHash types.Hash) {
 	batch.Put(chain_utils.CreateOnRoadKey(toAddr, blockHash).Bytes(), []byte{})
 
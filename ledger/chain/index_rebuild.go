@@ -0,0 +1,163 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+	chain_index "github.com/vitelabs/go-vite/v2/ledger/chain/index"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+// the index db is flushed and the checkpoint is saved every rebuildIndexCheckpointInterval snapshot blocks
+const rebuildIndexCheckpointInterval = 10000
+
+// RebuildIndexProgress is reported at every checkpoint of RebuildIndexes
+type RebuildIndexProgress struct {
+	SnapshotHeight uint64
+	AccountBlocks  uint64
+	Location       chain_file_manager.Location
+	Resumed        bool // the rebuilding is resumed from a checkpoint of the previous run
+	Finished       bool
+}
+
+// RebuildIndexes drops the index db and re-derives the hash -> location, height -> hash and confirmation indexes
+// by streaming the block db from the genesis. The progress is saved at the checkpoints, an interrupted rebuilding
+// is resumed by calling RebuildIndexes again, and Init fails until the rebuilding is finished.
+// It should be called on a chain which is not initialized, the unconfirmed account blocks are dropped.
+func (c *chain) RebuildIndexes(progress func(p *RebuildIndexProgress)) error {
+	if c.indexDB != nil {
+		return errors.New("the chain is initialized, the indexes should be rebuilt before Init")
+	}
+	if progress == nil {
+		progress = func(*RebuildIndexProgress) {}
+	}
+	if err := c.newDbAndRecover(); err != nil {
+		return err
+	}
+
+	p, err := c.queryRebuildIndexCheckpoint()
+	if err != nil {
+		return err
+	}
+	if p != nil {
+		p.Resumed = true
+		c.log.Info(fmt.Sprintf("resume rebuilding indexes from snapshot height %d, location %s", p.SnapshotHeight, p.Location), "method", "RebuildIndexes")
+	} else {
+		if err := c.dropIndexDB(); err != nil {
+			return err
+		}
+		p = &RebuildIndexProgress{Location: *chain_file_manager.NewLocation(1, 0)}
+		if err := c.writeRebuildIndexCheckpoint(p); err != nil {
+			return err
+		}
+	}
+	progress(p)
+
+	end := c.blockDB.LatestLocation()
+	start := p.Location
+	location := &start
+	var blocks []*ledger.AccountBlock
+	abLocations := make(map[types.Hash]*chain_file_manager.Location)
+
+	for location.Compare(end) < 0 {
+		sb, ab, next, err := c.blockDB.ReadUnit(location)
+		if err != nil {
+			return fmt.Errorf("read block db at %s failed. Error: %w", location, err)
+		}
+
+		if ab != nil {
+			if err := c.indexDB.InsertAccountBlock(ab); err != nil {
+				return fmt.Errorf("index account block %s failed. Error: %w", ab.Hash, err)
+			}
+			blocks = append(blocks, ab)
+			abLocations[ab.Hash] = location
+		} else if sb != nil {
+			if sb.Height != p.SnapshotHeight+1 {
+				return fmt.Errorf("snapshot block %d at %s, expected height is %d", sb.Height, location, p.SnapshotHeight+1)
+			}
+			c.indexDB.InsertSnapshotBlock(sb, blocks, location, abLocations)
+
+			p.SnapshotHeight = sb.Height
+			p.AccountBlocks += uint64(len(blocks))
+			p.Location = *next
+			blocks = nil
+			abLocations = make(map[types.Hash]*chain_file_manager.Location)
+
+			if p.SnapshotHeight%rebuildIndexCheckpointInterval == 0 {
+				if err := c.checkpointRebuildIndex(p); err != nil {
+					return err
+				}
+				progress(p)
+			}
+		}
+		location = next
+	}
+	if len(blocks) > 0 {
+		return fmt.Errorf("%d account blocks at the end of the block db are not confirmed by a snapshot block", len(blocks))
+	}
+
+	if err := c.checkpointRebuildIndex(p); err != nil {
+		return err
+	}
+	if err := c.metaDB.Delete([]byte{RebuildIndexKey}, nil); err != nil {
+		return err
+	}
+	p.Finished = true
+	progress(p)
+
+	c.log.Info(fmt.Sprintf("rebuilt indexes of %d snapshot blocks and %d account blocks", p.SnapshotHeight, p.AccountBlocks), "method", "RebuildIndexes")
+	return nil
+}
+
+// dropIndexDB removes the index db and opens an empty one
+func (c *chain) dropIndexDB() error {
+	if err := c.indexDB.CleanAllData(); err != nil {
+		return err
+	}
+	indexDB, err := chain_index.NewIndexDB(c.chainDir)
+	if err != nil {
+		return err
+	}
+	c.indexDB = indexDB
+	c.flusher.ReplaceStore(indexDB.Store().Id(), indexDB.Store())
+	return nil
+}
+
+// checkpointRebuildIndex flushes the index db and saves the checkpoint at the flush boundary
+func (c *chain) checkpointRebuildIndex(p *RebuildIndexProgress) error {
+	return c.flusher.Checkpoint(func() error {
+		return c.writeRebuildIndexCheckpoint(p)
+	})
+}
+
+func (c *chain) writeRebuildIndexCheckpoint(p *RebuildIndexProgress) error {
+	value := make([]byte, 0, 28)
+	value = append(value, chain_utils.Uint64ToBytes(p.SnapshotHeight)...)
+	value = append(value, chain_utils.Uint64ToBytes(p.AccountBlocks)...)
+	value = append(value, chain_utils.SerializeLocation(&p.Location)...)
+	return c.metaDB.Put([]byte{RebuildIndexKey}, value, nil)
+}
+
+// queryRebuildIndexCheckpoint returns nil if no rebuilding is in progress
+func (c *chain) queryRebuildIndexCheckpoint() (*RebuildIndexProgress, error) {
+	value, err := c.metaDB.Get([]byte{RebuildIndexKey}, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(value) != 28 {
+		return nil, fmt.Errorf("invalid checkpoint of rebuilding indexes, length is %d", len(value))
+	}
+	return &RebuildIndexProgress{
+		SnapshotHeight: chain_utils.BytesToUint64(value[:8]),
+		AccountBlocks:  chain_utils.BytesToUint64(value[8:16]),
+		Location:       *chain_utils.DeserializeLocation(value[16:]),
+	}, nil
+}
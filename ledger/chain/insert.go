@@ -28,13 +28,13 @@ func (c *chain) InsertAccountBlock(vmAccountBlock *interfaces.VmAccountBlock) er
 
 	// write index database
 	if err := c.indexDB.InsertAccountBlock(accountBlock); err != nil {
-		cErr := fmt.Errorf("c.indexDB.InsertAccountBlockAndSnapshot failed, error is %s, blockHash is %s", err.Error(), accountBlock.Hash)
+		cErr := fmt.Errorf("c.indexDB.InsertAccountBlockAndSnapshot failed, error is %w, blockHash is %s", err, accountBlock.Hash)
 		common.Crit(cErr.Error(), "method", "InsertAccountBlockAndSnapshot")
 	}
 
 	// write state db
 	if err := c.stateDB.Write(vmAccountBlock); err != nil {
-		cErr := fmt.Errorf("c.stateDB.WriteAccountBlock failed, error is %s, blockHash is %s", err.Error(), accountBlock.Hash)
+		cErr := fmt.Errorf("c.stateDB.WriteAccountBlock failed, error is %w, blockHash is %s", err, accountBlock.Hash)
 		common.Crit(cErr.Error(), "method", "InsertAccountBlockAndSnapshot")
 	}
 
@@ -122,7 +122,7 @@ func (c *chain) insertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock) error {
 	abLocationMap, snapshotBlockLocation, err := c.blockDB.Write(chunks[0])
 
 	if err != nil {
-		cErr := fmt.Errorf("c.blockDB.WriteAccountBlock failed, snapshotBlock is %+v. Error: %s", snapshotBlock, err.Error())
+		cErr := fmt.Errorf("c.blockDB.WriteAccountBlock failed, snapshotBlock is %+v. Error: %w", snapshotBlock, err)
 		common.Crit(cErr.Error(), "method", "InsertSnapshotBlock")
 	}
 
@@ -1,14 +1,17 @@
 package chain
 
 import (
+	"context"
 	"math/big"
 	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
 
+	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_backup "github.com/vitelabs/go-vite/v2/ledger/chain/backup"
 	chain_block "github.com/vitelabs/go-vite/v2/ledger/chain/block"
 	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
 	chain_index "github.com/vitelabs/go-vite/v2/ledger/chain/index"
@@ -42,6 +45,9 @@ type Chain interface {
 	Register(listener interfaces.EventListener)
 	UnRegister(listener interfaces.EventListener)
 
+	SubscribeChainEvents(fn ChainEventCallback) (subId int)
+	UnsubscribeChainEvents(subId int)
+
 	/*
 	 *	C(Create)
 	 */
@@ -96,9 +102,18 @@ type Chain interface {
 	// [start,end]
 	GetAccountBlocksByRange(addr types.Address, start uint64, end uint64) ([]*ledger.AccountBlock, error)
 
+	// the blocks are streamed to fn until ctx is done or fn returns an error
+	StreamAccountBlocks(ctx context.Context, blockHash types.Hash, count uint64, fn func(block *ledger.AccountBlock) error) error
+
+	StreamAccountBlocksByHeight(ctx context.Context, addr types.Address, height uint64, count uint64, fn func(block *ledger.AccountBlock) error) error
+
+	StreamAccountBlocksByRange(ctx context.Context, addr types.Address, start uint64, end uint64, fn func(block *ledger.AccountBlock) error) error
+
 	// get call depth
 	GetCallDepth(sendBlock types.Hash) (uint16, error)
 
+	GetExecutionError(blockHash types.Hash) (*ledger.ExecutionError, error)
+
 	// judge the account block is confirmed by the N or more than N snapshot blocks with seed
 	IsSeedConfirmedNTimes(blockHash types.Hash, n uint64) (bool, error)
 
@@ -152,6 +167,11 @@ type Chain interface {
 	// contains the snapshot block that has the blockHash
 	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error)
 
+	// the blocks are streamed to fn until ctx is done or fn returns an error
+	StreamSnapshotBlocks(ctx context.Context, blockHash types.Hash, higher bool, count uint64, fn func(block *ledger.SnapshotBlock) error) error
+
+	StreamSnapshotBlocksByHeight(ctx context.Context, height uint64, higher bool, count uint64, fn func(block *ledger.SnapshotBlock) error) error
+
 	GetConfirmSnapshotHeaderByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error)
 
 	GetConfirmSnapshotBlockByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error)
@@ -174,6 +194,9 @@ type Chain interface {
 
 	GetSubLedgerAfterHeight(height uint64) ([]*ledger.SnapshotChunk, error)
 
+	// the chunks are streamed to fn until ctx is done or fn returns an error
+	StreamSubLedger(ctx context.Context, startHeight, endHeight uint64, fn func(chunk *ledger.SnapshotChunk) error) error
+
 	// ====== Query unconfirmed pool ======
 	GetAllUnconfirmedBlocks() []*ledger.AccountBlock
 
@@ -219,6 +242,14 @@ type Chain interface {
 
 	GetValue(address types.Address, key []byte) ([]byte, error)
 
+	// get the storage confirmed by the snapshot block of snapshotHeight
+	GetSnapshotValue(snapshotHeight uint64, address types.Address, key []byte) ([]byte, error)
+
+	GetSnapshotStorageIterator(snapshotHeight uint64, address types.Address, prefix []byte) (interfaces.StorageIterator, error)
+
+	// get the storage view at snapshotHeight, the deleted keys are skipped, Key() returns the storage key
+	GetStorageIteratorAt(snapshotHeight uint64, address types.Address, prefix []byte) interfaces.StorageIterator
+
 	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 
 	GetVMLogListByAddress(address types.Address, start uint64, end uint64, id *types.Hash) (ledger.VmLogList, error)
@@ -237,6 +268,8 @@ type Chain interface {
 
 	GetStakeBeneficialAmount(addr types.Address) (*big.Int, error)
 
+	GetStakeBeneficialAmountInSnapshot(addr types.Address, snapshotHash types.Hash) (*big.Int, error)
+
 	// total
 	GetStakeQuota(addr types.Address) (*big.Int, *types.Quota, error)
 
@@ -267,6 +300,9 @@ type Chain interface {
 
 	GetOnRoadBlocksByAddr(addr types.Address, pageNum, pageSize int) ([]*ledger.AccountBlock, error)
 
+	// GetOnRoadBlocksByAddrAfter returns at most size onroad blocks of addr sorted by hash, starting after the hash if it's not nil
+	GetOnRoadBlocksByAddrAfter(addr types.Address, after *types.Hash, size int) ([]*ledger.AccountBlock, error)
+
 	LoadAllOnRoad() (map[types.Address][]types.Hash, error)
 
 	GetAccountOnRoadInfo(addr types.Address) (*ledger.AccountInfo, error)
@@ -305,5 +341,23 @@ type Chain interface {
 
 	CheckOnRoad() error
 
+	VerifyLedger(full bool) (*LedgerReport, error)
+
+	RebuildIndexes(progress func(p *RebuildIndexProgress)) error
+
+	PruneHistory(beforeHeight uint64, dryRun bool, progress func(stat *PruneStat)) (*PruneResult, error)
+
+	Mode() config.LedgerMode
+
+	HistoryPrunedHeight() uint64
+
+	CheckHistoryHeight(height uint64) error
+
+	CompactDbs(dbs []string) error
+
+	CompactProgress() CompactProgress
+
+	Backup(dir string) (*chain_backup.Manifest, error)
+
 	GetStatus() []interfaces.DBStatus
 }
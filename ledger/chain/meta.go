@@ -8,6 +8,15 @@ import (
 
 const (
 	GenesisKey = byte(0)
+
+	// RebuildIndexKey is the checkpoint of the index rebuilding, it exists until the rebuilding is finished
+	RebuildIndexKey = byte(1)
+
+	// PrunedHeightKey is the height before which the history is pruned
+	PrunedHeightKey = byte(2)
+
+	// RollbackJournalKey is the target of the rollback in progress, it exists until the rollback is finished
+	RollbackJournalKey = byte(3)
 )
 
 func (c *chain) WriteGenesisCheckSum(hash types.Hash) error {
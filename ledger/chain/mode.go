@@ -0,0 +1,203 @@
+package chain
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+const (
+	// a snapshot block is produced every second
+	snapshotBlocksPerDay = 24 * 3600
+
+	// the history out of the retention is pruned at this interval in full and light mode
+	retentionPruneInterval = time.Hour
+)
+
+type retentionPruner struct {
+	terminal chan struct{}
+	wg       sync.WaitGroup
+}
+
+// Mode returns the ledger mode of the chain, full if it's not set
+func (c *chain) Mode() config.LedgerMode {
+	if c.chainCfg.Mode == "" {
+		return config.LedgerModeFull
+	}
+	return c.chainCfg.Mode
+}
+
+// checkMode checks that the ledger can run in the mode, it's called after the dbs are opened
+func (c *chain) checkMode() error {
+	prunedHeight, err := c.queryPrunedHeight()
+	if err != nil {
+		return err
+	}
+	atomic.StoreUint64(&c.prunedHeight, prunedHeight)
+
+	switch mode := c.Mode(); mode {
+	case config.LedgerModeArchive:
+		if prunedHeight > 0 {
+			return fmt.Errorf("the history before snapshot height %d is pruned, the ledger can't run in archive mode", prunedHeight)
+		}
+	case config.LedgerModeFull:
+	case config.LedgerModeLight:
+		if c.chainCfg.VmLogAll || len(c.chainCfg.VmLogWhiteList) > 0 {
+			c.log.Warn("no vm log is saved in light mode, vmLogAll and vmLogWhiteList are ignored", "method", "checkMode")
+		}
+	default:
+		return fmt.Errorf("unknown ledger mode: %s", mode)
+	}
+	return nil
+}
+
+// HistoryPrunedHeight returns the height before which the history is pruned, 0 if the history is not pruned
+func (c *chain) HistoryPrunedHeight() uint64 {
+	return atomic.LoadUint64(&c.prunedHeight)
+}
+
+// CheckHistoryHeight returns an error if the state confirmed by the snapshot block at height is pruned
+func (c *chain) CheckHistoryHeight(height uint64) error {
+	if prunedHeight := c.HistoryPrunedHeight(); height < prunedHeight {
+		return fmt.Errorf("the history before snapshot height %d is pruned in %s mode, query snapshot height %d on an archive node",
+			prunedHeight, c.Mode(), height)
+	}
+	return nil
+}
+
+// checkHistoryHash is CheckHistoryHeight of the snapshot block of hash, the height is looked up only if the history is pruned
+func (c *chain) checkHistoryHash(hash types.Hash) error {
+	if c.HistoryPrunedHeight() == 0 {
+		return nil
+	}
+	height, err := c.GetSnapshotHeightByHash(hash)
+	if err != nil {
+		return err
+	}
+	if height == 0 {
+		// unknown snapshot block, it's reported by the reader
+		return nil
+	}
+	return c.CheckHistoryHeight(height)
+}
+
+// retentionDays returns the days of the history kept, false if the history is not pruned by retention.
+// Light mode keeps none but the recent blocks for rolling back.
+func (c *chain) retentionDays() (uint64, bool) {
+	switch c.Mode() {
+	case config.LedgerModeFull:
+		days := c.chainCfg.HistoryRetentionDays
+		return days, days > 0
+	case config.LedgerModeLight:
+		return 0, true
+	}
+	return 0, false
+}
+
+// pruneByRetention prunes the history older than HistoryRetentionDays in full mode, and all but the recent
+// history in light mode
+func (c *chain) pruneByRetention() error {
+	days, ok := c.retentionDays()
+	if !ok {
+		return nil
+	}
+	beforeHeight := retentionPruneHeight(c.GetLatestSnapshotBlock().Height, c.HistoryPrunedHeight(), days)
+	if beforeHeight == 0 {
+		return nil
+	}
+
+	c.log.Info(fmt.Sprintf("prune the history before snapshot height %d, %d days are kept", beforeHeight, days), "method", "pruneByRetention")
+	result, err := c.PruneHistory(beforeHeight, false, nil)
+	if err != nil {
+		return err
+	}
+	for _, stat := range result.Stats() {
+		c.log.Info(fmt.Sprintf("pruned %s: %d keys, %d bytes", stat.Kind, stat.Keys, stat.Bytes), "method", "pruneByRetention")
+	}
+	return nil
+}
+
+// retentionPruneHeight returns the height before which the history is out of the retention of days,
+// 0 if nothing is to be pruned
+func retentionPruneHeight(latestHeight, prunedHeight, days uint64) uint64 {
+	retain := days * snapshotBlocksPerDay
+	if retain < pruneRetainHeight {
+		retain = pruneRetainHeight
+	}
+	if latestHeight <= retain+1 {
+		return 0
+	}
+	beforeHeight := latestHeight - retain
+	if beforeHeight <= prunedHeight {
+		return 0
+	}
+	return beforeHeight
+}
+
+// startRetentionPrune prunes the history out of the retention every retentionPruneInterval while the chain runs,
+// the history is pruned at startup by Init too
+func (c *chain) startRetentionPrune() {
+	if _, ok := c.retentionDays(); !ok {
+		return
+	}
+
+	rp := c.retentionPruner
+	rp.terminal = make(chan struct{})
+	rp.wg.Add(1)
+	go func() {
+		defer rp.wg.Done()
+		ticker := time.NewTicker(retentionPruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-rp.terminal:
+				return
+			case <-ticker.C:
+				if err := c.pruneByRetention(); err != nil {
+					c.log.Warn(fmt.Sprintf("retention pruning is skipped. Error: %s", err), "method", "startRetentionPrune")
+				}
+			}
+		}
+	}()
+}
+
+func (c *chain) stopRetentionPrune() {
+	rp := c.retentionPruner
+	if rp.terminal != nil {
+		close(rp.terminal)
+		rp.wg.Wait()
+		rp.terminal = nil
+	}
+}
+
+func (c *chain) writePrunedHeight(height uint64) error {
+	if height <= c.HistoryPrunedHeight() {
+		return nil
+	}
+	if err := c.metaDB.Put([]byte{PrunedHeightKey}, chain_utils.Uint64ToBytes(height), nil); err != nil {
+		return err
+	}
+	atomic.StoreUint64(&c.prunedHeight, height)
+	return nil
+}
+
+func (c *chain) queryPrunedHeight() (uint64, error) {
+	value, err := c.metaDB.Get([]byte{PrunedHeightKey}, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(value) != 8 {
+		return 0, fmt.Errorf("invalid pruned height, length is %d", len(value))
+	}
+	return chain_utils.BytesToUint64(value), nil
+}
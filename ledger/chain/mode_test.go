@@ -0,0 +1,77 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+func TestChain_CheckHistoryHeight(t *testing.T) {
+	c := &chain{chainCfg: &config.Chain{}}
+	if c.Mode() != config.LedgerModeFull {
+		t.Fatalf("expected full mode, got %s", c.Mode())
+	}
+	if err := c.CheckHistoryHeight(1); err != nil {
+		t.Fatal(err)
+	}
+
+	c.prunedHeight = 100
+	if err := c.CheckHistoryHeight(99); err == nil {
+		t.Fatal("expected pruned error")
+	}
+	if err := c.CheckHistoryHeight(100); err != nil {
+		t.Fatal(err)
+	}
+
+	// the readers of the history refuse the pruned heights
+	if _, err := c.GetSnapshotValue(99, types.Address{}, nil); err == nil {
+		t.Fatal("expected pruned error of GetSnapshotValue")
+	}
+	if _, err := c.GetSnapshotStorageIterator(99, types.Address{}, nil); err == nil {
+		t.Fatal("expected pruned error of GetSnapshotStorageIterator")
+	}
+	iter := c.GetStorageIteratorAt(99, types.Address{}, nil)
+	defer iter.Release()
+	if iter.Next() || iter.Error() == nil {
+		t.Fatal("expected pruned error of GetStorageIteratorAt")
+	}
+}
+
+func TestChain_RetentionDays(t *testing.T) {
+	for _, c := range []struct {
+		mode config.LedgerMode
+		days uint64
+		ok   bool
+	}{
+		{config.LedgerModeArchive, 0, false},
+		{config.LedgerModeFull, 0, false},
+		{config.LedgerModeFull, 7, true},
+		{config.LedgerModeLight, 0, true},
+	} {
+		ch := &chain{chainCfg: &config.Chain{Mode: c.mode, HistoryRetentionDays: 7}}
+		if c.mode == config.LedgerModeFull && !c.ok {
+			ch.chainCfg.HistoryRetentionDays = 0
+		}
+		if days, ok := ch.retentionDays(); days != c.days || ok != c.ok {
+			t.Errorf("mode %s: expected %d %v, got %d %v", c.mode, c.days, c.ok, days, ok)
+		}
+	}
+}
+
+func TestRetentionPruneHeight(t *testing.T) {
+	for _, c := range []struct {
+		latest, pruned, days, before uint64
+	}{
+		{snapshotBlocksPerDay, 0, 1, 0},
+		{snapshotBlocksPerDay + 100, 0, 1, 100},
+		{snapshotBlocksPerDay + 100, 100, 1, 0},
+		{snapshotBlocksPerDay + 200, 100, 1, 200},
+		// the recent blocks kept for rolling back
+		{pruneRetainHeight + 100, 0, 0, 100},
+	} {
+		if before := retentionPruneHeight(c.latest, c.pruned, c.days); before != c.before {
+			t.Errorf("latest %d pruned %d days %d: expected %d, got %d", c.latest, c.pruned, c.days, c.before, before)
+		}
+	}
+}
@@ -19,7 +19,7 @@ func (c *chain) LoadOnRoad(gid types.Gid) (map[types.Address]map[types.Address][
 
 	onRoadData, err := c.indexDB.Load(addrList)
 	if err != nil {
-		cErr := fmt.Errorf("c.indexDB.Load failed, addrList is %+v。 Error: %s", addrList, err)
+		cErr := fmt.Errorf("c.indexDB.Load failed, addrList is %+v。 Error: %w", addrList, err)
 		c.log.Error(cErr.Error(), "method", "LoadOnRoad")
 		return nil, cErr
 	}
@@ -31,12 +31,26 @@ func (c *chain) LoadOnRoad(gid types.Gid) (map[types.Address]map[types.Address][
 func (c *chain) GetOnRoadBlocksByAddr(addr types.Address, pageNum, pageSize int) ([]*ledger.AccountBlock, error) {
 	hashList, err := c.indexDB.GetOnRoadHashList(addr, pageNum, pageSize)
 	if err != nil {
-		cErr := fmt.Errorf("c.GetOnRoadBlocksByAddr failed, error is %s, address is %s, pageNum is %d, countPerPage is %d",
+		cErr := fmt.Errorf("c.GetOnRoadBlocksByAddr failed, error is %w, address is %s, pageNum is %d, countPerPage is %d",
 			err, addr, pageNum, pageSize)
 		c.log.Error(cErr.Error(), "method", "GetOnRoadBlocksByAddr")
 		return nil, cErr
 	}
+	return c.getOnRoadBlocks(addr, hashList, "GetOnRoadBlocksByAddr")
+}
+
+func (c *chain) GetOnRoadBlocksByAddrAfter(addr types.Address, after *types.Hash, size int) ([]*ledger.AccountBlock, error) {
+	hashList, err := c.indexDB.GetOnRoadHashListAfter(addr, after, size)
+	if err != nil {
+		cErr := fmt.Errorf("c.GetOnRoadBlocksByAddrAfter failed, error is %w, address is %s, after is %v, size is %d",
+			err, addr, after, size)
+		c.log.Error(cErr.Error(), "method", "GetOnRoadBlocksByAddrAfter")
+		return nil, cErr
+	}
+	return c.getOnRoadBlocks(addr, hashList, "GetOnRoadBlocksByAddrAfter")
+}
 
+func (c *chain) getOnRoadBlocks(addr types.Address, hashList []types.Hash, method string) ([]*ledger.AccountBlock, error) {
 	blockList := make([]*ledger.AccountBlock, len(hashList))
 	count := 0
 
@@ -47,7 +61,7 @@ func (c *chain) GetOnRoadBlocksByAddr(addr types.Address, pageNum, pageSize int)
 		}
 		if b == nil {
 			c.DeleteOnRoad(addr, v)
-			c.log.Error(fmt.Sprintf("block is not exit, hash %s. fix onroad, hash %s is deleted", v, v), "method", "GetOnRoadBlocksByAddr")
+			c.log.Error(fmt.Sprintf("block is not exit, hash %s. fix onroad, hash %s is deleted", v, v), "method", method)
 			continue
 		}
 		blockList[count] = b
@@ -0,0 +1,168 @@
+package chain_plugins
+
+import (
+	"errors"
+	"fmt"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+)
+
+// Backfill enables the plugins registered since the last start, the plugins requesting backfill replay the
+// existing blocks, and the data of the plugins no longer registered are removed.
+// A backfill interrupted is resumed from the last height flushed.
+func (p *Plugins) Backfill() error {
+	p.StopWrite()
+	defer p.StartWrite()
+
+	statuses, err := p.loadStatuses()
+	if err != nil {
+		return err
+	}
+
+	batch := p.store.NewBatch()
+
+	for name, status := range statuses {
+		if _, ok := p.plugins[name]; ok {
+			continue
+		}
+		p.log.Info(fmt.Sprintf("remove data of plugin %s", name), "method", "Backfill")
+		p.deletePrefixes(batch, status.keyPrefixes)
+		batch.Delete(CreatePluginStatusKey(name))
+		delete(statuses, name)
+	}
+
+	for name := range p.plugins {
+		if _, ok := statuses[name]; ok {
+			continue
+		}
+		def := p.defs[name]
+		status := &pluginStatus{state: pluginEnabled, keyPrefixes: def.KeyPrefixes}
+		if def.Backfill {
+			// the stale data of the plugin enabled before
+			p.deletePrefixes(batch, def.KeyPrefixes)
+			status.state = pluginBackfilling
+		}
+		batch.Put(CreatePluginStatusKey(name), status.serialize())
+		statuses[name] = status
+	}
+
+	if batch.Len() > 0 {
+		p.store.WriteDirectly(batch)
+		p.chain.Flusher().Flush()
+	}
+
+	for name, status := range statuses {
+		if status.state != pluginBackfilling {
+			continue
+		}
+		if err := p.backfill(name, status); err != nil {
+			return fmt.Errorf("backfill plugin %s failed, error is %s", name, err)
+		}
+	}
+	return nil
+}
+
+func (p *Plugins) loadStatuses() (map[string]*pluginStatus, error) {
+	iter := p.store.NewIterator(util.BytesPrefix([]byte{PluginStatusKeyPrefix}))
+	defer iter.Release()
+
+	statuses := make(map[string]*pluginStatus)
+	for iter.Next() {
+		status := &pluginStatus{}
+		if err := status.deserialize(iter.Value()); err != nil {
+			return nil, err
+		}
+		statuses[string(iter.Key()[1:])] = status
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (p *Plugins) deletePrefixes(batch *leveldb.Batch, prefixes []byte) {
+	for _, prefix := range prefixes {
+		iter := p.store.NewIterator(util.BytesPrefix([]byte{prefix}))
+		for iter.Next() {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+		iter.Release()
+	}
+}
+
+// backfill replays the blocks through the plugin, the status is flushed with the data of every round
+func (p *Plugins) backfill(name string, status *pluginStatus) error {
+	plugin := p.plugins[name]
+	flusher := p.chain.Flusher()
+
+	latestSnapshot := p.chain.GetLatestSnapshotBlock()
+	if latestSnapshot == nil {
+		return errors.New("GetLatestSnapshotBlock fail")
+	}
+
+	p.log.Info(fmt.Sprintf("backfill plugin %s from %d to %d", name, status.height, latestSnapshot.Height), "method", "backfill")
+
+	h := status.height
+	for h < latestSnapshot.Height {
+		targetH := h + roundSize
+		if targetH > latestSnapshot.Height {
+			targetH = latestSnapshot.Height
+		}
+
+		chunks, err := p.chain.GetSubLedger(h, targetH)
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock != nil &&
+				chunk.SnapshotBlock.Height == h {
+				continue
+			}
+
+			// the plugin may read the data of the previous blocks
+			for _, ab := range chunk.AccountBlocks {
+				batch := p.store.NewBatch()
+				if err := plugin.InsertAccountBlock(batch, ab); err != nil {
+					return err
+				}
+				p.store.WriteDirectly(batch)
+			}
+
+			batch := p.store.NewBatch()
+			if err := plugin.InsertSnapshotBlock(batch, chunk.SnapshotBlock, chunk.AccountBlocks); err != nil {
+				return err
+			}
+			p.store.WriteDirectly(batch)
+		}
+
+		status.height = targetH
+		batch := p.store.NewBatch()
+		batch.Put(CreatePluginStatusKey(name), status.serialize())
+		p.store.WriteDirectly(batch)
+
+		// flush to disk
+		flusher.Flush()
+
+		h = targetH
+	}
+
+	// the unconfirmed blocks are written with the batches of other plugins, so they can be snapshotted or rolled back
+	for _, ab := range p.chain.GetAllUnconfirmedBlocks() {
+		batch := p.store.NewBatch()
+		if err := plugin.InsertAccountBlock(batch, ab); err != nil {
+			return err
+		}
+		p.store.PatchAccountBlockByHash(batch, ab.Hash)
+	}
+
+	status.state = pluginEnabled
+	batch := p.store.NewBatch()
+	batch.Put(CreatePluginStatusKey(name), status.serialize())
+	p.store.WriteDirectly(batch)
+	flusher.Flush()
+
+	p.log.Info(fmt.Sprintf("backfill plugin %s done", name), "method", "backfill")
+	return nil
+}
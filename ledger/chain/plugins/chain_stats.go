@@ -0,0 +1,513 @@
+package chain_plugins
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sort"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+const secondsPerDay = 24 * 3600
+
+// sub keys of ChainStatsKeyPrefix
+const (
+	chainStatsHeightKey  = byte(0) // [height] -> stats of the snapshot block
+	chainStatsDayKey     = byte(1) // [day] -> stats of the day
+	chainStatsAddressKey = byte(2) // [day][address] -> transactions of the address in the day
+)
+
+// Volume is the amount of the token transferred
+type Volume struct {
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
+
+// SnapshotStats is the statistics of the account blocks confirmed by a snapshot block
+type SnapshotStats struct {
+	Day                 uint64
+	Transactions        uint64
+	ContractDeployments uint64
+	Volumes             []Volume
+	Addresses           []AddressCount // transactions of the addresses, for rolling back the active addresses
+}
+
+// AddressCount is the transactions of the address
+type AddressCount struct {
+	Address      types.Address
+	Transactions uint64
+}
+
+// DayStats is the statistics of the account blocks confirmed in a UTC day
+type DayStats struct {
+	Day                 uint64 // days since 1970-01-01
+	StartHeight         uint64
+	EndHeight           uint64
+	Transactions        uint64
+	ContractDeployments uint64
+	ActiveAddresses     uint64
+	Volumes             []Volume
+}
+
+// ChainStats maintains the transactions, active addresses, contract deployments and transfer volumes
+// incrementally by days and snapshot heights
+type ChainStats struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+func newChainStats(store *chain_db.Store, chain Chain) Plugin {
+	return &ChainStats{
+		store: store,
+		chain: chain,
+	}
+}
+
+func (cs *ChainStats) SetStore(store *chain_db.Store) {
+	cs.store = store
+}
+
+func (cs *ChainStats) InsertAccountBlock(*leveldb.Batch, *ledger.AccountBlock) error {
+	return nil
+}
+
+// InsertSnapshotBlock counts the account blocks when they are confirmed
+func (cs *ChainStats) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	var day uint64
+	if snapshotBlock.Timestamp != nil {
+		day = uint64(snapshotBlock.Timestamp.Unix() / secondsPerDay)
+	}
+
+	s := &SnapshotStats{Day: day}
+	volumes := make(map[types.TokenTypeId]*big.Int)
+	addresses := make(map[types.Address]uint64)
+	for _, block := range confirmedBlocks {
+		s.Transactions++
+		addresses[block.AccountAddress]++
+
+		if block.BlockType == ledger.BlockTypeSendCreate {
+			s.ContractDeployments++
+		}
+		if block.IsSendBlock() {
+			addVolume(volumes, block.TokenId, block.Amount)
+		}
+		for _, sendBlock := range block.SendBlockList {
+			addVolume(volumes, sendBlock.TokenId, sendBlock.Amount)
+		}
+	}
+	s.Volumes = sortVolumes(volumes)
+
+	ds, err := cs.getDayStats(day)
+	if err != nil {
+		return err
+	}
+	if ds == nil {
+		ds = &DayStats{Day: day, StartHeight: snapshotBlock.Height}
+	}
+	ds.EndHeight = snapshotBlock.Height
+	ds.Transactions += s.Transactions
+	ds.ContractDeployments += s.ContractDeployments
+	ds.Volumes = mergeVolumes(ds.Volumes, s.Volumes, false)
+
+	for addr, n := range addresses {
+		s.Addresses = append(s.Addresses, AddressCount{Address: addr, Transactions: n})
+
+		count, err := cs.getAddressCount(day, addr)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			ds.ActiveAddresses++
+		}
+		batch.Put(createChainStatsAddressKey(day, addr), encodeUint64(count+n))
+	}
+
+	batch.Put(createChainStatsHeightKey(snapshotBlock.Height), s.serialize())
+	batch.Put(createChainStatsDayKey(day), ds.serialize())
+	return nil
+}
+
+func (cs *ChainStats) DeleteAccountBlocks(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// DeleteSnapshotBlocks reverts the statistics of the snapshot blocks, by the statistics saved when they were inserted
+func (cs *ChainStats) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	days := make(map[uint64]*DayStats)
+	addresses := make(map[uint64]map[types.Address]uint64)
+
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock == nil {
+			continue
+		}
+		height := chunk.SnapshotBlock.Height
+
+		s, err := cs.GetSnapshotStats(height)
+		if err != nil {
+			return err
+		}
+		if s == nil {
+			continue
+		}
+
+		ds, ok := days[s.Day]
+		if !ok {
+			if ds, err = cs.getDayStats(s.Day); err != nil {
+				return err
+			}
+			if ds == nil {
+				return fmt.Errorf("stats of day %d is not existed", s.Day)
+			}
+			days[s.Day] = ds
+			addresses[s.Day] = make(map[types.Address]uint64)
+		}
+
+		ds.Transactions -= s.Transactions
+		ds.ContractDeployments -= s.ContractDeployments
+		ds.Volumes = mergeVolumes(ds.Volumes, s.Volumes, true)
+		if ds.EndHeight >= height {
+			ds.EndHeight = height - 1
+		}
+
+		for _, ac := range s.Addresses {
+			count, ok := addresses[s.Day][ac.Address]
+			if !ok {
+				if count, err = cs.getAddressCount(s.Day, ac.Address); err != nil {
+					return err
+				}
+			}
+			if count < ac.Transactions {
+				count = ac.Transactions
+			}
+			addresses[s.Day][ac.Address] = count - ac.Transactions
+		}
+
+		batch.Delete(createChainStatsHeightKey(height))
+	}
+
+	for day, ds := range days {
+		for addr, count := range addresses[day] {
+			if count > 0 {
+				batch.Put(createChainStatsAddressKey(day, addr), encodeUint64(count))
+				continue
+			}
+			batch.Delete(createChainStatsAddressKey(day, addr))
+			if ds.ActiveAddresses > 0 {
+				ds.ActiveAddresses--
+			}
+		}
+
+		if ds.EndHeight < ds.StartHeight {
+			batch.Delete(createChainStatsDayKey(day))
+		} else {
+			batch.Put(createChainStatsDayKey(day), ds.serialize())
+		}
+	}
+	return nil
+}
+
+func (cs *ChainStats) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// GetSnapshotStats returns the statistics of the snapshot block, nil if not existed
+func (cs *ChainStats) GetSnapshotStats(height uint64) (*SnapshotStats, error) {
+	value, err := cs.store.Get(createChainStatsHeightKey(height))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	s := &SnapshotStats{}
+	if err := s.deserialize(value); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RangeStats is the statistics of the snapshot blocks in a height range
+type RangeStats struct {
+	StartHeight         uint64
+	EndHeight           uint64
+	Transactions        uint64
+	ContractDeployments uint64
+	ActiveAddresses     uint64
+	Volumes             []Volume
+}
+
+// GetRangeStats sums the statistics of the snapshot blocks in [startHeight, endHeight]
+func (cs *ChainStats) GetRangeStats(startHeight, endHeight uint64) (*RangeStats, error) {
+	iter := cs.store.NewIterator(&util.Range{Start: createChainStatsHeightKey(startHeight), Limit: createChainStatsHeightKey(endHeight + 1)})
+	defer iter.Release()
+
+	result := &RangeStats{StartHeight: startHeight, EndHeight: endHeight}
+	addresses := make(map[types.Address]struct{})
+	for iter.Next() {
+		s := &SnapshotStats{}
+		if err := s.deserialize(iter.Value()); err != nil {
+			return nil, err
+		}
+		result.Transactions += s.Transactions
+		result.ContractDeployments += s.ContractDeployments
+		result.Volumes = mergeVolumes(result.Volumes, s.Volumes, false)
+		for _, ac := range s.Addresses {
+			addresses[ac.Address] = struct{}{}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	result.ActiveAddresses = uint64(len(addresses))
+	return result, nil
+}
+
+// GetDayStats returns the statistics of the days in [startDay, endDay], the days without blocks are omitted
+func (cs *ChainStats) GetDayStats(startDay, endDay uint64) ([]*DayStats, error) {
+	iter := cs.store.NewIterator(&util.Range{Start: createChainStatsDayKey(startDay), Limit: createChainStatsDayKey(endDay + 1)})
+	defer iter.Release()
+
+	var list []*DayStats
+	for iter.Next() {
+		ds := &DayStats{}
+		if err := ds.deserialize(iter.Value()); err != nil {
+			return nil, err
+		}
+		list = append(list, ds)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (cs *ChainStats) getDayStats(day uint64) (*DayStats, error) {
+	value, err := cs.store.Get(createChainStatsDayKey(day))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	ds := &DayStats{}
+	if err := ds.deserialize(value); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+func (cs *ChainStats) getAddressCount(day uint64, addr types.Address) (uint64, error) {
+	value, err := cs.store.Get(createChainStatsAddressKey(day, addr))
+	if err != nil || len(value) < 8 {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func addVolume(volumes map[types.TokenTypeId]*big.Int, tokenId types.TokenTypeId, amount *big.Int) {
+	if amount == nil || amount.Sign() <= 0 {
+		return
+	}
+	if v, ok := volumes[tokenId]; ok {
+		v.Add(v, amount)
+		return
+	}
+	volumes[tokenId] = new(big.Int).Set(amount)
+}
+
+func sortVolumes(volumes map[types.TokenTypeId]*big.Int) []Volume {
+	list := make([]Volume, 0, len(volumes))
+	for tokenId, amount := range volumes {
+		list = append(list, Volume{TokenId: tokenId, Amount: amount})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return string(list[i].TokenId.Bytes()) < string(list[j].TokenId.Bytes())
+	})
+	return list
+}
+
+// mergeVolumes adds or subtracts the volumes, the tokens with zero volume are removed
+func mergeVolumes(volumes, delta []Volume, sub bool) []Volume {
+	m := make(map[types.TokenTypeId]*big.Int, len(volumes))
+	for _, v := range volumes {
+		m[v.TokenId] = new(big.Int).Set(v.Amount)
+	}
+	for _, v := range delta {
+		amount, ok := m[v.TokenId]
+		if !ok {
+			amount = new(big.Int)
+			m[v.TokenId] = amount
+		}
+		if sub {
+			amount.Sub(amount, v.Amount)
+		} else {
+			amount.Add(amount, v.Amount)
+		}
+		if amount.Sign() <= 0 {
+			delete(m, v.TokenId)
+		}
+	}
+	return sortVolumes(m)
+}
+
+func createChainStatsHeightKey(height uint64) []byte {
+	key := make([]byte, 0, 2+8)
+	key = append(key, ChainStatsKeyPrefix, chainStatsHeightKey)
+	return append(key, encodeUint64(height)...)
+}
+
+func createChainStatsDayKey(day uint64) []byte {
+	key := make([]byte, 0, 2+8)
+	key = append(key, ChainStatsKeyPrefix, chainStatsDayKey)
+	return append(key, encodeUint64(day)...)
+}
+
+func createChainStatsAddressKey(day uint64, addr types.Address) []byte {
+	key := make([]byte, 0, 2+8+types.AddressSize)
+	key = append(key, ChainStatsKeyPrefix, chainStatsAddressKey)
+	key = append(key, encodeUint64(day)...)
+	return append(key, addr.Bytes()...)
+}
+
+func encodeUint64(n uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, n)
+	return buf
+}
+
+// statsEncoder writes the fields in order, uint64 in 8 bytes, bytes prefixed by the length in 2 bytes
+type statsEncoder struct {
+	buf []byte
+}
+
+func (e *statsEncoder) uint64(n uint64) {
+	e.buf = append(e.buf, encodeUint64(n)...)
+}
+
+func (e *statsEncoder) bytes(b []byte) {
+	e.buf = append(e.buf, byte(len(b)>>8), byte(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+func (e *statsEncoder) volumes(volumes []Volume) {
+	e.uint64(uint64(len(volumes)))
+	for _, v := range volumes {
+		e.bytes(v.TokenId.Bytes())
+		e.bytes(v.Amount.Bytes())
+	}
+}
+
+type statsDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *statsDecoder) uint64() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) < 8 {
+		d.err = fmt.Errorf("invalid stats, %d bytes left", len(d.buf))
+		return 0
+	}
+	n := binary.BigEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return n
+}
+
+func (d *statsDecoder) bytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < 2 {
+		d.err = fmt.Errorf("invalid stats, %d bytes left", len(d.buf))
+		return nil
+	}
+	n := int(d.buf[0])<<8 | int(d.buf[1])
+	if len(d.buf) < 2+n {
+		d.err = fmt.Errorf("invalid stats, %d bytes left, expected %d", len(d.buf)-2, n)
+		return nil
+	}
+	b := d.buf[2 : 2+n]
+	d.buf = d.buf[2+n:]
+	return b
+}
+
+func (d *statsDecoder) volumes() []Volume {
+	n := d.uint64()
+	if d.err != nil || n > uint64(len(d.buf)) {
+		return nil
+	}
+	volumes := make([]Volume, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		tokenId, err := types.BytesToTokenTypeId(d.bytes())
+		if err != nil && d.err == nil {
+			d.err = err
+		}
+		volumes = append(volumes, Volume{TokenId: tokenId, Amount: new(big.Int).SetBytes(d.bytes())})
+	}
+	return volumes
+}
+
+func (s *SnapshotStats) serialize() []byte {
+	e := &statsEncoder{}
+	e.uint64(s.Day)
+	e.uint64(s.Transactions)
+	e.uint64(s.ContractDeployments)
+	e.volumes(s.Volumes)
+	e.uint64(uint64(len(s.Addresses)))
+	for _, ac := range s.Addresses {
+		e.buf = append(e.buf, ac.Address.Bytes()...)
+		e.uint64(ac.Transactions)
+	}
+	return e.buf
+}
+
+func (s *SnapshotStats) deserialize(buf []byte) error {
+	d := &statsDecoder{buf: buf}
+	s.Day = d.uint64()
+	s.Transactions = d.uint64()
+	s.ContractDeployments = d.uint64()
+	s.Volumes = d.volumes()
+	n := d.uint64()
+	if d.err != nil {
+		return d.err
+	}
+	const size = types.AddressSize + 8
+	if uint64(len(d.buf)) != n*size {
+		return fmt.Errorf("invalid stats, %d bytes of %d addresses", len(d.buf), n)
+	}
+	s.Addresses = make([]AddressCount, n)
+	for i := range s.Addresses {
+		b := d.buf[i*size : (i+1)*size]
+		addr, err := types.BytesToAddress(b[:types.AddressSize])
+		if err != nil {
+			return err
+		}
+		s.Addresses[i] = AddressCount{Address: addr, Transactions: binary.BigEndian.Uint64(b[types.AddressSize:])}
+	}
+	return nil
+}
+
+func (ds *DayStats) serialize() []byte {
+	e := &statsEncoder{}
+	e.uint64(ds.Day)
+	e.uint64(ds.StartHeight)
+	e.uint64(ds.EndHeight)
+	e.uint64(ds.Transactions)
+	e.uint64(ds.ContractDeployments)
+	e.uint64(ds.ActiveAddresses)
+	e.volumes(ds.Volumes)
+	return e.buf
+}
+
+func (ds *DayStats) deserialize(buf []byte) error {
+	d := &statsDecoder{buf: buf}
+	ds.Day = d.uint64()
+	ds.StartHeight = d.uint64()
+	ds.EndHeight = d.uint64()
+	ds.Transactions = d.uint64()
+	ds.ContractDeployments = d.uint64()
+	ds.ActiveAddresses = d.uint64()
+	ds.Volumes = d.volumes()
+	return d.err
+}
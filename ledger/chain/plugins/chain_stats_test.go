@@ -0,0 +1,102 @@
+package chain_plugins
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+func TestChainStats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chain_stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	cs := newChainStats(store, nil).(*ChainStats)
+
+	day := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	nextDay := day.Add(24 * time.Hour)
+	insert := func(height uint64, timestamp time.Time, blocks ...*ledger.AccountBlock) {
+		batch := store.NewBatch()
+		if err := cs.InsertSnapshotBlock(batch, &ledger.SnapshotBlock{Height: height, Timestamp: &timestamp}, blocks); err != nil {
+			t.Fatal(err)
+		}
+		store.WriteDirectly(batch)
+	}
+
+	send := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: types.AddressQuota, TokenId: ledger.ViteTokenId, Amount: big.NewInt(100)}
+	create := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCreate, AccountAddress: types.AddressQuota, TokenId: ledger.ViteTokenId, Amount: big.NewInt(0)}
+	receive := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: types.AddressAsset,
+		SendBlockList:  []*ledger.AccountBlock{{TokenId: ledger.VCPTokenId, Amount: big.NewInt(5)}},
+	}
+
+	insert(1, day, send, create)
+	insert(2, day, receive, send)
+	insert(3, nextDay, send)
+
+	days, err := cs.GetDayStats(0, 1<<40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("expected 2 days, got %d", len(days))
+	}
+	if ds := days[0]; ds.Transactions != 4 || ds.ContractDeployments != 1 || ds.ActiveAddresses != 2 ||
+		ds.StartHeight != 1 || ds.EndHeight != 2 || len(ds.Volumes) != 2 {
+		t.Fatalf("unexpected stats %+v", ds)
+	}
+	for _, v := range days[0].Volumes {
+		if (v.TokenId == ledger.ViteTokenId && v.Amount.Int64() != 200) || (v.TokenId == ledger.VCPTokenId && v.Amount.Int64() != 5) {
+			t.Errorf("unexpected volume %s %s", v.TokenId, v.Amount)
+		}
+	}
+
+	rs, err := cs.GetRangeStats(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.Transactions != 3 || rs.ActiveAddresses != 2 || rs.ContractDeployments != 0 {
+		t.Fatalf("unexpected range stats %+v", rs)
+	}
+
+	// roll back to height 1, the account blocks of height 2 are kept unconfirmed
+	batch := store.NewBatch()
+	if err := cs.DeleteSnapshotBlocks(batch, []*ledger.SnapshotChunk{
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 2}},
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 3}, AccountBlocks: []*ledger.AccountBlock{send}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	store.WriteDirectly(batch)
+
+	if days, err = cs.GetDayStats(0, 1<<40); err != nil {
+		t.Fatal(err)
+	}
+	if len(days) != 1 {
+		t.Fatalf("expected 1 day, got %d", len(days))
+	}
+	if ds := days[0]; ds.Transactions != 2 || ds.ContractDeployments != 1 || ds.ActiveAddresses != 1 ||
+		ds.EndHeight != 1 || len(ds.Volumes) != 1 || ds.Volumes[0].Amount.Int64() != 100 {
+		t.Fatalf("unexpected stats after rollback %+v", ds)
+	}
+
+	// confirmed again
+	insert(2, day, receive, send)
+	if days, _ = cs.GetDayStats(0, 1<<40); len(days) != 1 || days[0].Transactions != 4 || days[0].ActiveAddresses != 2 {
+		t.Fatalf("unexpected stats after confirmed again %+v", days[0])
+	}
+}
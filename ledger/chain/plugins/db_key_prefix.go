@@ -6,6 +6,13 @@ const (
 	OnRoadInfoKeyPrefix = byte(1)
 
 	DiffTokenHash = byte(2)
+
+	ChainStatsKeyPrefix = byte(3)
+
+	DexTradesKeyPrefix = byte(4)
+
+	// PluginStatusKeyPrefix is reserved for the status of the plugins, not available to the plugins
+	PluginStatusKeyPrefix = byte(255)
 )
 
 func CreateOnRoadInfoKey(addr *types.Address, tId *types.TokenTypeId) []byte {
@@ -22,3 +29,10 @@ func CreateOnRoadInfoPrefixKey(addr *types.Address) []byte {
 	key = append(key, addr.Bytes()...)
 	return key
 }
+
+func CreatePluginStatusKey(name string) []byte {
+	key := make([]byte, 0, 1+len(name))
+	key = append(key, PluginStatusKeyPrefix)
+	key = append(key, name...)
+	return key
+}
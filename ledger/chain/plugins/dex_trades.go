@@ -0,0 +1,336 @@
+package chain_plugins
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+	dexproto "github.com/vitelabs/go-vite/v2/vm/contracts/dex/proto"
+)
+
+// sub keys of DexTradesKeyPrefix
+const (
+	dexTradesHeightKey  = byte(0) // [height] -> keys written for the snapshot block, for rolling back
+	dexTradesOrderKey   = byte(1) // [orderId] -> address of the order
+	dexTradesMarketKey  = byte(2) // [marketId][height][index] -> trade
+	dexTradesAddressKey = byte(3) // [address][height][index] -> trade, of both the taker and the maker
+)
+
+var (
+	dexNewOrderTopic = (&dex.NewOrderEvent{}).GetTopicId()
+	dexTxTopic       = (&dex.TransactionEvent{}).GetTopicId()
+)
+
+// DexTradePosition is the position of a trade in the index, trades are ordered by the position
+type DexTradePosition struct {
+	Height uint64 // height of the snapshot block confirming the trade
+	Index  uint32 // index of the trade in the snapshot block
+}
+
+// DexTrade is a trade of the dex with the addresses of the orders
+type DexTrade struct {
+	DexTradePosition
+	Timestamp    int64 // timestamp of the snapshot block in seconds
+	MarketId     int32
+	TakerAddress types.Address
+	MakerAddress types.Address
+	Transaction  *dexproto.Transaction
+}
+
+// DexTrades indexes the trades of the dex by the markets and the addresses from the events of the trade contract,
+// so the trade history is queried without scanning the contract storage
+type DexTrades struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+func newDexTrades(store *chain_db.Store, chain Chain) Plugin {
+	return &DexTrades{
+		store: store,
+		chain: chain,
+	}
+}
+
+func (dt *DexTrades) SetStore(store *chain_db.Store) {
+	dt.store = store
+}
+
+func (dt *DexTrades) InsertAccountBlock(*leveldb.Batch, *ledger.AccountBlock) error {
+	return nil
+}
+
+// InsertSnapshotBlock indexes the trades of the confirmed blocks of the trade contract
+func (dt *DexTrades) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	var timestamp int64
+	if snapshotBlock.Timestamp != nil {
+		timestamp = snapshotBlock.Timestamp.Unix()
+	}
+
+	var keys [][]byte
+	orders := make(map[string]types.Address)
+	index := uint32(0)
+	for _, block := range confirmedBlocks {
+		if block.AccountAddress != types.AddressDexTrade || block.LogHash == nil {
+			continue
+		}
+		logList, err := dt.chain.GetVmLogList(block.LogHash)
+		if err != nil {
+			return err
+		}
+		for _, log := range logList {
+			if len(log.Topics) == 0 {
+				continue
+			}
+			switch log.Topics[0] {
+			case dexNewOrderTopic:
+				event := &dex.NewOrderEvent{}
+				if err := event.FromBytes(log.Data); err != nil {
+					return err
+				}
+				if event.Order == nil {
+					continue
+				}
+				addr, err := types.BytesToAddress(event.Order.Address)
+				if err != nil {
+					return err
+				}
+				orders[string(event.Order.Id)] = addr
+				key := createDexTradesOrderKey(event.Order.Id)
+				batch.Put(key, addr.Bytes())
+				keys = append(keys, key)
+
+			case dexTxTopic:
+				event := &dex.TransactionEvent{}
+				if err := event.FromBytes(log.Data); err != nil {
+					return err
+				}
+				trade := &DexTrade{
+					DexTradePosition: DexTradePosition{Height: snapshotBlock.Height, Index: index},
+					Timestamp:        timestamp,
+					Transaction:      &event.Transaction,
+				}
+				index++
+				if trade.MarketId, _, _, _, err = dex.DeComposeOrderId(trade.Transaction.TakerId); err != nil {
+					return err
+				}
+				// the address is unknown if the order is created before the logs are kept, only the market index is written
+				takerOk, makerOk := false, false
+				if trade.TakerAddress, takerOk, err = dt.orderAddress(orders, trade.Transaction.TakerId); err != nil {
+					return err
+				}
+				if trade.MakerAddress, makerOk, err = dt.orderAddress(orders, trade.Transaction.MakerId); err != nil {
+					return err
+				}
+
+				value, err := trade.serialize()
+				if err != nil {
+					return err
+				}
+				tradeKeys := [][]byte{createDexTradesMarketKey(trade.MarketId, trade.DexTradePosition)}
+				if takerOk {
+					tradeKeys = append(tradeKeys, createDexTradesAddressKey(trade.TakerAddress, trade.DexTradePosition))
+				}
+				if makerOk && (!takerOk || trade.MakerAddress != trade.TakerAddress) {
+					tradeKeys = append(tradeKeys, createDexTradesAddressKey(trade.MakerAddress, trade.DexTradePosition))
+				}
+				for _, key := range tradeKeys {
+					batch.Put(key, value)
+				}
+				keys = append(keys, tradeKeys...)
+			}
+		}
+	}
+
+	if len(keys) > 0 {
+		e := &statsEncoder{}
+		e.uint64(uint64(len(keys)))
+		for _, key := range keys {
+			e.bytes(key)
+		}
+		batch.Put(createDexTradesHeightKey(snapshotBlock.Height), e.buf)
+	}
+	return nil
+}
+
+func (dt *DexTrades) DeleteAccountBlocks(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// DeleteSnapshotBlocks removes the keys written for the snapshot blocks
+func (dt *DexTrades) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock == nil {
+			continue
+		}
+		heightKey := createDexTradesHeightKey(chunk.SnapshotBlock.Height)
+		value, err := dt.store.Get(heightKey)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			continue
+		}
+		d := &statsDecoder{buf: value}
+		n := d.uint64()
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			key := d.bytes()
+			if d.err == nil {
+				batch.Delete(append([]byte(nil), key...))
+			}
+		}
+		if d.err != nil {
+			return d.err
+		}
+		batch.Delete(heightKey)
+	}
+	return nil
+}
+
+func (dt *DexTrades) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// GetMarketTrades returns at most limit trades of the market in the snapshot heights [fromHeight, toHeight], latest first.
+// If before is not nil, only the trades before it are returned, it is the position of the last trade of the previous page.
+func (dt *DexTrades) GetMarketTrades(marketId int32, fromHeight, toHeight uint64, before *DexTradePosition, limit int) ([]*DexTrade, error) {
+	prefix := createDexTradesMarketPrefix(marketId)
+	return dt.getTrades(prefix, fromHeight, toHeight, before, limit)
+}
+
+// GetAddressTrades returns at most limit trades of the orders of addr, like GetMarketTrades
+func (dt *DexTrades) GetAddressTrades(addr types.Address, fromHeight, toHeight uint64, before *DexTradePosition, limit int) ([]*DexTrade, error) {
+	prefix := createDexTradesAddressPrefix(addr)
+	return dt.getTrades(prefix, fromHeight, toHeight, before, limit)
+}
+
+func (dt *DexTrades) getTrades(prefix []byte, fromHeight, toHeight uint64, before *DexTradePosition, limit int) ([]*DexTrade, error) {
+	if fromHeight > toHeight || limit <= 0 {
+		return nil, nil
+	}
+	start := append(append([]byte(nil), prefix...), encodeUint64(fromHeight)...)
+	var end []byte
+	if before != nil && before.Height <= toHeight {
+		end = append(append([]byte(nil), prefix...), encodeDexTradePosition(*before)...)
+	} else if toHeight == ^uint64(0) {
+		end = util.BytesPrefix(prefix).Limit
+	} else {
+		end = append(append([]byte(nil), prefix...), encodeUint64(toHeight+1)...)
+	}
+
+	iter := dt.store.NewIterator(&util.Range{Start: start, Limit: end})
+	defer iter.Release()
+
+	var list []*DexTrade
+	for ok := iter.Last(); ok && len(list) < limit; ok = iter.Prev() {
+		key := iter.Key()
+		if len(key) < len(prefix)+12 {
+			return nil, fmt.Errorf("invalid dex trade key %x", key)
+		}
+		trade := &DexTrade{}
+		if err := trade.deserialize(iter.Value()); err != nil {
+			return nil, err
+		}
+		pos := key[len(key)-12:]
+		trade.Height = binary.BigEndian.Uint64(pos[:8])
+		trade.Index = binary.BigEndian.Uint32(pos[8:])
+		list = append(list, trade)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// orderAddress returns the address of the order created in the same snapshot block or indexed before
+func (dt *DexTrades) orderAddress(orders map[string]types.Address, orderId []byte) (types.Address, bool, error) {
+	if addr, ok := orders[string(orderId)]; ok {
+		return addr, true, nil
+	}
+	value, err := dt.store.Get(createDexTradesOrderKey(orderId))
+	if err != nil || value == nil {
+		return types.Address{}, false, err
+	}
+	addr, err := types.BytesToAddress(value)
+	return addr, err == nil, err
+}
+
+func (t *DexTrade) serialize() ([]byte, error) {
+	data, err := proto.Marshal(t.Transaction)
+	if err != nil {
+		return nil, err
+	}
+	e := &statsEncoder{}
+	e.uint64(uint64(t.Timestamp))
+	e.uint64(uint64(uint32(t.MarketId)))
+	e.bytes(t.TakerAddress.Bytes())
+	e.bytes(t.MakerAddress.Bytes())
+	e.bytes(data)
+	return e.buf, nil
+}
+
+func (t *DexTrade) deserialize(buf []byte) error {
+	d := &statsDecoder{buf: buf}
+	t.Timestamp = int64(d.uint64())
+	t.MarketId = int32(uint32(d.uint64()))
+	taker := d.bytes()
+	maker := d.bytes()
+	data := d.bytes()
+	if d.err != nil {
+		return d.err
+	}
+	var err error
+	if t.TakerAddress, err = types.BytesToAddress(taker); err != nil {
+		return err
+	}
+	if t.MakerAddress, err = types.BytesToAddress(maker); err != nil {
+		return err
+	}
+	t.Transaction = &dexproto.Transaction{}
+	return proto.Unmarshal(data, t.Transaction)
+}
+
+func encodeDexTradePosition(pos DexTradePosition) []byte {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf, pos.Height)
+	binary.BigEndian.PutUint32(buf[8:], pos.Index)
+	return buf
+}
+
+func createDexTradesHeightKey(height uint64) []byte {
+	key := make([]byte, 0, 2+8)
+	key = append(key, DexTradesKeyPrefix, dexTradesHeightKey)
+	return append(key, encodeUint64(height)...)
+}
+
+func createDexTradesOrderKey(orderId []byte) []byte {
+	key := make([]byte, 0, 2+len(orderId))
+	key = append(key, DexTradesKeyPrefix, dexTradesOrderKey)
+	return append(key, orderId...)
+}
+
+func createDexTradesMarketPrefix(marketId int32) []byte {
+	key := make([]byte, 2, 2+4)
+	key[0], key[1] = DexTradesKeyPrefix, dexTradesMarketKey
+	return append(key, byte(marketId>>24), byte(marketId>>16), byte(marketId>>8), byte(marketId))
+}
+
+func createDexTradesMarketKey(marketId int32, pos DexTradePosition) []byte {
+	return append(createDexTradesMarketPrefix(marketId), encodeDexTradePosition(pos)...)
+}
+
+func createDexTradesAddressPrefix(addr types.Address) []byte {
+	key := make([]byte, 0, 2+types.AddressSize+12)
+	key = append(key, DexTradesKeyPrefix, dexTradesAddressKey)
+	return append(key, addr.Bytes()...)
+}
+
+func createDexTradesAddressKey(addr types.Address, pos DexTradePosition) []byte {
+	return append(createDexTradesAddressPrefix(addr), encodeDexTradePosition(pos)...)
+}
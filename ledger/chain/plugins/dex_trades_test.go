@@ -0,0 +1,124 @@
+package chain_plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	dexproto "github.com/vitelabs/go-vite/v2/vm/contracts/dex/proto"
+)
+
+type logChain struct {
+	Chain
+	logs map[types.Hash]ledger.VmLogList
+}
+
+func (c *logChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	return c.logs[*logListHash], nil
+}
+
+func TestDexTrades(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dex_trades")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	chain := &logChain{logs: make(map[types.Hash]ledger.VmLogList)}
+	dt := newDexTrades(store, chain).(*DexTrades)
+
+	alice, _, _ := types.CreateAddress()
+	bob, _, _ := types.CreateAddress()
+	orderId := func(marketId int32, serial byte) []byte {
+		id := make([]byte, 22)
+		id[2] = byte(marketId)
+		id[21] = serial
+		return id
+	}
+	newOrder := func(id []byte, addr types.Address) *ledger.VmLog {
+		data, _ := proto.Marshal(&dexproto.NewOrderInfo{Order: &dexproto.Order{Id: id, Address: addr.Bytes()}})
+		return &ledger.VmLog{Topics: []types.Hash{dexNewOrderTopic}, Data: data}
+	}
+	trade := func(takerId, makerId []byte) *ledger.VmLog {
+		data, _ := proto.Marshal(&dexproto.Transaction{TakerId: takerId, MakerId: makerId, Quantity: []byte{1}})
+		return &ledger.VmLog{Topics: []types.Hash{dexTxTopic}, Data: data}
+	}
+	insert := func(height uint64, logs ...*ledger.VmLog) {
+		logHash := types.DataHash([]byte{byte(height)})
+		chain.logs[logHash] = logs
+		block := &ledger.AccountBlock{AccountAddress: types.AddressDexTrade, LogHash: &logHash}
+		timestamp := time.Unix(int64(height), 0)
+		batch := store.NewBatch()
+		if err := dt.InsertSnapshotBlock(batch, &ledger.SnapshotBlock{Height: height, Timestamp: &timestamp}, []*ledger.AccountBlock{block}); err != nil {
+			t.Fatal(err)
+		}
+		store.WriteDirectly(batch)
+	}
+
+	// alice's maker order is matched by bob's taker orders
+	insert(1, newOrder(orderId(1, 1), alice))
+	insert(2, newOrder(orderId(1, 2), bob), trade(orderId(1, 2), orderId(1, 1)))
+	insert(3, newOrder(orderId(1, 3), bob), trade(orderId(1, 3), orderId(1, 1)), trade(orderId(1, 3), orderId(1, 1)))
+	insert(4, newOrder(orderId(2, 4), bob))
+
+	trades, err := dt.GetMarketTrades(1, 0, ^uint64(0), nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 3 || trades[0].Height != 3 || trades[0].Index != 1 || trades[2].Height != 2 {
+		t.Fatalf("unexpected trades %+v", trades)
+	}
+	if tr := trades[0]; tr.MarketId != 1 || tr.TakerAddress != bob || tr.MakerAddress != alice || tr.Timestamp != 3 {
+		t.Fatalf("unexpected trade %+v", tr)
+	}
+
+	// paged by the position of the last trade
+	page, err := dt.GetMarketTrades(1, 0, ^uint64(0), nil, 2)
+	if err != nil || len(page) != 2 {
+		t.Fatalf("unexpected page %v %v", page, err)
+	}
+	page, err = dt.GetMarketTrades(1, 0, ^uint64(0), &page[1].DexTradePosition, 2)
+	if err != nil || len(page) != 1 || page[0].Height != 2 {
+		t.Fatalf("unexpected page %v %v", page, err)
+	}
+
+	if trades, _ := dt.GetMarketTrades(1, 2, 2, nil, 10); len(trades) != 1 {
+		t.Fatalf("expected 1 trade at height 2, got %d", len(trades))
+	}
+	if trades, _ := dt.GetMarketTrades(2, 0, ^uint64(0), nil, 10); len(trades) != 0 {
+		t.Fatalf("expected no trades of market 2, got %d", len(trades))
+	}
+	for _, addr := range []types.Address{alice, bob} {
+		if trades, _ := dt.GetAddressTrades(addr, 0, ^uint64(0), nil, 10); len(trades) != 3 {
+			t.Fatalf("expected 3 trades of %s, got %d", addr, len(trades))
+		}
+	}
+
+	// roll back the latest two snapshot blocks
+	batch := store.NewBatch()
+	if err := dt.DeleteSnapshotBlocks(batch, []*ledger.SnapshotChunk{
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 3}},
+		{SnapshotBlock: &ledger.SnapshotBlock{Height: 4}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	store.WriteDirectly(batch)
+
+	if trades, _ := dt.GetAddressTrades(alice, 0, ^uint64(0), nil, 10); len(trades) != 1 {
+		t.Fatalf("expected 1 trade after rolling back, got %d", len(trades))
+	}
+	if value, _ := store.Get(createDexTradesOrderKey(orderId(1, 3))); value != nil {
+		t.Fatal("the order of the rolled back block should be deleted")
+	}
+}
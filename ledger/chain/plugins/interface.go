@@ -15,6 +15,7 @@ type Chain interface {
 	GetSubLedgerAfterHeight(height uint64) ([]*ledger.SnapshotChunk, error)
 	GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error)
 	GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error)
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 
 	IsAccountBlockExisted(hash types.Hash) (bool, error)
 	IsGenesisAccountBlock(hash types.Hash) bool
@@ -28,6 +28,7 @@ type Plugins struct {
 	chain   Chain
 	store   *chain_db.Store
 	plugins map[string]Plugin
+	defs    map[string]Definition
 
 	writeStatus uint32
 	mu          sync.RWMutex
@@ -43,9 +44,11 @@ func NewPlugins(chainDir string, chain Chain) (*Plugins, error) {
 		return nil, err
 	}
 
-	plugins := map[string]Plugin{
-		"filterToken": newFilterToken(store, chain),
-		"onRoadInfo":  newOnRoadInfo(store, chain),
+	plugins := make(map[string]Plugin)
+	defs := make(map[string]Definition)
+	for _, def := range Definitions() {
+		plugins[def.Name] = def.New(store, chain)
+		defs[def.Name] = def
 	}
 
 	return &Plugins{
@@ -53,6 +56,7 @@ func NewPlugins(chainDir string, chain Chain) (*Plugins, error) {
 		chain:       chain,
 		store:       store,
 		plugins:     plugins,
+		defs:        defs,
 		writeStatus: start,
 		log:         log15.New("module", "chain_plugins"),
 	}, nil
@@ -167,6 +171,15 @@ func (p *Plugins) RebuildData() error {
 		h = targetH
 	}
 
+	// all plugins are rebuilt
+	batch := p.store.NewBatch()
+	for name := range p.plugins {
+		status := &pluginStatus{state: pluginEnabled, height: latestSnapshot.Height, keyPrefixes: p.defs[name].KeyPrefixes}
+		batch.Put(CreatePluginStatusKey(name), status.serialize())
+	}
+	p.store.WriteDirectly(batch)
+	flusher.Flush()
+
 	// success
 	p.log.Info("Succeed rebuild plugin data")
 	return nil
@@ -0,0 +1,177 @@
+package chain_plugins
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_flusher "github.com/vitelabs/go-vite/v2/ledger/chain/flusher"
+)
+
+const counterKeyPrefix = byte(100)
+
+type mockChain struct {
+	Chain
+	flusher *chain_flusher.Flusher
+	height  uint64
+}
+
+func (c *mockChain) Flusher() *chain_flusher.Flusher {
+	return c.flusher
+}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return &ledger.SnapshotBlock{Height: c.height}
+}
+
+func (c *mockChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	var chunks []*ledger.SnapshotChunk
+	for h := startHeight; h <= endHeight; h++ {
+		if h == 0 {
+			continue
+		}
+		chunks = append(chunks, &ledger.SnapshotChunk{SnapshotBlock: &ledger.SnapshotBlock{Height: h}})
+	}
+	return chunks, nil
+}
+
+func (c *mockChain) GetAllUnconfirmedBlocks() []*ledger.AccountBlock {
+	return nil
+}
+
+// counter writes a key of every snapshot block
+type counter struct {
+	inserted int
+}
+
+func (c *counter) SetStore(store *chain_db.Store) {}
+
+func (c *counter) InsertAccountBlock(*leveldb.Batch, *ledger.AccountBlock) error {
+	return nil
+}
+
+func (c *counter) InsertSnapshotBlock(batch *leveldb.Batch, block *ledger.SnapshotBlock, _ []*ledger.AccountBlock) error {
+	c.inserted++
+	key := make([]byte, 9)
+	key[0] = counterKeyPrefix
+	binary.BigEndian.PutUint64(key[1:], block.Height)
+	batch.Put(key, []byte{1})
+	return nil
+}
+
+func (c *counter) DeleteAccountBlocks(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+func (c *counter) DeleteSnapshotBlocks(*leveldb.Batch, []*ledger.SnapshotChunk) error {
+	return nil
+}
+
+func (c *counter) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+func openPlugins(t *testing.T, dir string, chain *mockChain) *Plugins {
+	p, err := NewPlugins(dir, chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain.flusher, err = chain_flusher.NewFlusher([]chain_flusher.Storage{p.Store()}, &sync.RWMutex{}, dir); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func countKeys(p *Plugins, prefix byte) int {
+	batch := p.store.NewBatch()
+	p.deletePrefixes(batch, []byte{prefix})
+	return batch.Len()
+}
+
+func TestRegister(t *testing.T) {
+	newCounter := func(*chain_db.Store, Chain) Plugin { return &counter{} }
+
+	if err := Register(Definition{Name: "filterToken", KeyPrefixes: []byte{100}, New: newCounter}); err == nil {
+		t.Error("expected error of duplicated name")
+	}
+	if err := Register(Definition{Name: "counter", KeyPrefixes: []byte{DiffTokenHash}, New: newCounter}); err == nil {
+		t.Error("expected error of duplicated key prefix")
+	}
+	if err := Register(Definition{Name: "counter", KeyPrefixes: []byte{PluginStatusKeyPrefix}, New: newCounter}); err == nil {
+		t.Error("expected error of reserved key prefix")
+	}
+	if err := Register(Definition{Name: "counter", New: newCounter}); err == nil {
+		t.Error("expected error of no key prefixes")
+	}
+}
+
+func TestPlugins_Backfill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &counter{}
+	if err = Register(Definition{
+		Name:        "counter",
+		KeyPrefixes: []byte{counterKeyPrefix},
+		New:         func(*chain_db.Store, Chain) Plugin { return c },
+		Backfill:    true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	unregister := func() {
+		registryMu.Lock()
+		delete(registry, "counter")
+		registryMu.Unlock()
+	}
+	defer unregister()
+
+	chain := &mockChain{height: 25}
+	p := openPlugins(t, dir, chain)
+	if err = p.Backfill(); err != nil {
+		t.Fatal(err)
+	}
+	if c.inserted != 25 || countKeys(p, counterKeyPrefix) != 25 {
+		t.Fatalf("expected 25 snapshot blocks backfilled, got %d", c.inserted)
+	}
+	statuses, err := p.loadStatuses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := statuses["counter"]; s == nil || s.state != pluginEnabled || s.height != 25 {
+		t.Fatalf("unexpected status %+v", s)
+	}
+	if s := statuses["onRoadInfo"]; s == nil || s.state != pluginEnabled {
+		t.Fatalf("unexpected status of builtin plugin %+v", s)
+	}
+
+	// enabled already
+	if err = p.Backfill(); err != nil {
+		t.Fatal(err)
+	}
+	if c.inserted != 25 {
+		t.Fatalf("unexpected backfill again, got %d", c.inserted)
+	}
+	_ = p.Close()
+
+	// the data are removed after the plugin is unregistered
+	unregister()
+	p = openPlugins(t, dir, chain)
+	defer p.Close()
+	if err = p.Backfill(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countKeys(p, counterKeyPrefix); n != 0 {
+		t.Fatalf("expected data removed, got %d keys", n)
+	}
+	if statuses, _ = p.loadStatuses(); statuses["counter"] != nil {
+		t.Fatal("expected status removed")
+	}
+}
@@ -0,0 +1,141 @@
+package chain_plugins
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+)
+
+// Definition describes a plugin, external code registers it to index the chain without forking the chain package
+type Definition struct {
+	// Name is the unique name of the plugin, the plugin is returned by Plugins.GetPlugin(Name)
+	Name string
+
+	// KeyPrefixes are the first bytes of all keys written by the plugin, a prefix belongs to only one plugin
+	KeyPrefixes []byte
+
+	// New creates the plugin, InsertAccountBlock/InsertSnapshotBlock are called when blocks are inserted,
+	// DeleteAccountBlocks/DeleteSnapshotBlocks/RemoveNewUnconfirmed are called when blocks are rolled back
+	New func(store *chain_db.Store, chain Chain) Plugin
+
+	// Backfill replays the existing blocks through the plugin when it is enabled for the first time
+	Backfill bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Definition)
+)
+
+func init() {
+	mustRegister(Definition{
+		Name:        "filterToken",
+		KeyPrefixes: []byte{DiffTokenHash},
+		New:         newFilterToken,
+	})
+	mustRegister(Definition{
+		Name:        "onRoadInfo",
+		KeyPrefixes: []byte{OnRoadInfoKeyPrefix},
+		New:         newOnRoadInfo,
+	})
+	mustRegister(Definition{
+		Name:        "chainStats",
+		KeyPrefixes: []byte{ChainStatsKeyPrefix},
+		New:         newChainStats,
+		Backfill:    true,
+	})
+	mustRegister(Definition{
+		Name:        "dexTrades",
+		KeyPrefixes: []byte{DexTradesKeyPrefix},
+		New:         newDexTrades,
+		Backfill:    true,
+	})
+}
+
+// Register registers the plugin, it must be called before the chain is initialized
+func Register(def Definition) error {
+	if def.Name == "" {
+		return errors.New("plugin name is empty")
+	}
+	if def.New == nil {
+		return fmt.Errorf("plugin %s has no constructor", def.Name)
+	}
+	if len(def.KeyPrefixes) == 0 {
+		return fmt.Errorf("plugin %s has no key prefixes", def.Name)
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[def.Name]; ok {
+		return fmt.Errorf("plugin %s is registered", def.Name)
+	}
+
+	for _, prefix := range def.KeyPrefixes {
+		if prefix == PluginStatusKeyPrefix {
+			return fmt.Errorf("key prefix %d of plugin %s is reserved", prefix, def.Name)
+		}
+		for _, other := range registry {
+			for _, prefix2 := range other.KeyPrefixes {
+				if prefix == prefix2 {
+					return fmt.Errorf("key prefix %d of plugin %s is used by plugin %s", prefix, def.Name, other.Name)
+				}
+			}
+		}
+	}
+
+	registry[def.Name] = def
+	return nil
+}
+
+func mustRegister(def Definition) {
+	if err := Register(def); err != nil {
+		panic(err)
+	}
+}
+
+// Definitions returns the registered plugins
+func Definitions() []Definition {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	defs := make([]Definition, 0, len(registry))
+	for _, def := range registry {
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+const (
+	pluginBackfilling = byte(0)
+	pluginEnabled     = byte(1)
+)
+
+// pluginStatus is persisted for every enabled plugin, so the plugins enabled for the first time are backfilled,
+// and the data of the plugins no longer registered are removed
+type pluginStatus struct {
+	state       byte
+	height      uint64 // the snapshot height backfilled
+	keyPrefixes []byte
+}
+
+func (s *pluginStatus) serialize() []byte {
+	buf := make([]byte, 9+len(s.keyPrefixes))
+	buf[0] = s.state
+	binary.BigEndian.PutUint64(buf[1:9], s.height)
+	copy(buf[9:], s.keyPrefixes)
+	return buf
+}
+
+func (s *pluginStatus) deserialize(buf []byte) error {
+	if len(buf) < 9 {
+		return fmt.Errorf("invalid plugin status length %d", len(buf))
+	}
+	s.state = buf[0]
+	s.height = binary.BigEndian.Uint64(buf[1:9])
+	s.keyPrefixes = append([]byte(nil), buf[9:]...)
+	return nil
+}
@@ -0,0 +1,288 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+const (
+	// the history of the recent snapshot blocks is kept for rolling back, same as the retain height of the redo log
+	pruneRetainHeight = 1200
+
+	pruneBatchSize = 10000
+
+	// snapshot chunks read from the block db at a time when pruning the vm logs
+	pruneChunkBatchSize = 1000
+)
+
+// PruneStat is the keys and bytes pruned of one kind of history data
+type PruneStat struct {
+	Kind  string
+	Keys  uint64
+	Bytes uint64
+}
+
+// PruneResult is the result of PruneHistory
+type PruneResult struct {
+	BeforeHeight uint64
+	DryRun       bool
+
+	HistoryStorage *PruneStat
+	HistoryBalance *PruneStat
+	RedoLog        *PruneStat
+	VmLog          *PruneStat
+}
+
+func (r *PruneResult) Stats() []*PruneStat {
+	return []*PruneStat{r.HistoryStorage, r.HistoryBalance, r.RedoLog, r.VmLog}
+}
+
+type pruner struct {
+	store    *chain_db.Store
+	stat     *PruneStat
+	dryRun   bool
+	progress func(stat *PruneStat)
+	flush    func()
+
+	batch *leveldb.Batch
+}
+
+func (p *pruner) delete(key, value []byte) {
+	p.stat.Keys++
+	p.stat.Bytes += uint64(len(key) + len(value))
+	if p.dryRun {
+		if p.stat.Keys%pruneBatchSize == 0 {
+			p.progress(p.stat)
+		}
+		return
+	}
+
+	if p.batch == nil {
+		p.batch = p.store.NewBatch()
+	}
+	p.batch.Delete(key)
+	if p.batch.Len() >= pruneBatchSize {
+		p.commit()
+	}
+}
+
+func (p *pruner) commit() {
+	if p.batch != nil && p.batch.Len() > 0 {
+		p.store.WriteDirectly(p.batch)
+		p.flush()
+		p.batch = nil
+	}
+	p.progress(p.stat)
+}
+
+// PruneHistory deletes the history storage, the history balances, the redo logs and the vm logs older than beforeHeight,
+// the block files are kept. The values of the history keys at beforeHeight are kept, so the state at and after
+// beforeHeight can still be queried, the state before beforeHeight can't be queried after pruning.
+// If dryRun is true, nothing is deleted and the result is the size estimate.
+func (c *chain) PruneHistory(beforeHeight uint64, dryRun bool, progress func(stat *PruneStat)) (*PruneResult, error) {
+	if c.Mode() == config.LedgerModeArchive {
+		return nil, errors.New("the history is kept in archive mode")
+	}
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	if beforeHeight <= 1 {
+		return nil, errors.New("before height should be greater than 1")
+	}
+	if latestHeight < pruneRetainHeight || beforeHeight > latestHeight-pruneRetainHeight {
+		return nil, fmt.Errorf("before height %d is too high, the latest %d snapshot blocks should be kept, latest height is %d",
+			beforeHeight, pruneRetainHeight, latestHeight)
+	}
+	if progress == nil {
+		progress = func(*PruneStat) {}
+	}
+
+	result := &PruneResult{
+		BeforeHeight:   beforeHeight,
+		DryRun:         dryRun,
+		HistoryStorage: &PruneStat{Kind: "history storage"},
+		HistoryBalance: &PruneStat{Kind: "history balance"},
+		RedoLog:        &PruneStat{Kind: "redo log"},
+		VmLog:          &PruneStat{Kind: "vm log"},
+	}
+
+	if !dryRun {
+		// the state before beforeHeight can't be queried from now on, it's marked before the keys are deleted so
+		// the queries don't read the history being pruned while the chain runs
+		if err := c.writePrunedHeight(beforeHeight); err != nil {
+			return result, err
+		}
+	}
+
+	newPruner := func(store *chain_db.Store, stat *PruneStat) *pruner {
+		return &pruner{
+			store:    store,
+			stat:     stat,
+			dryRun:   dryRun,
+			progress: progress,
+			flush:    c.flusher.Flush,
+		}
+	}
+
+	stateStore := c.stateDB.Store()
+	for _, item := range []struct {
+		prefix byte
+		stat   *PruneStat
+	}{
+		{chain_utils.StorageHistoryKeyPrefix, result.HistoryStorage},
+		{chain_utils.BalanceHistoryKeyPrefix, result.HistoryBalance},
+	} {
+		if err := pruneHistoryKeys(newPruner(stateStore, item.stat), item.prefix, beforeHeight); err != nil {
+			return result, err
+		}
+	}
+
+	if err := pruneRedoLogs(newPruner(c.stateDB.RedoStore(), result.RedoLog), beforeHeight); err != nil {
+		return result, err
+	}
+
+	if err := c.pruneVmLogs(newPruner(stateStore, result.VmLog), beforeHeight); err != nil {
+		return result, err
+	}
+
+	if !dryRun {
+		// the disk space is released after compaction
+		for _, prefix := range []byte{chain_utils.StorageHistoryKeyPrefix, chain_utils.BalanceHistoryKeyPrefix, chain_utils.VmLogListKeyPrefix} {
+			if err := stateStore.CompactRange(*util.BytesPrefix([]byte{prefix})); err != nil {
+				return result, err
+			}
+		}
+		if err := c.stateDB.RedoStore().CompactRange(*util.BytesPrefix([]byte{chain_utils.SnapshotKeyPrefix})); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// pruneHistoryKeys deletes the history keys below beforeHeight, the history keys are sorted by [key][height].
+// For every key the latest history below beforeHeight is kept unless there is a history at beforeHeight,
+// it is the value of the key at beforeHeight.
+func pruneHistoryKeys(p *pruner, prefix byte, beforeHeight uint64) error {
+	iter := p.store.NewIterator(util.BytesPrefix([]byte{prefix}))
+	defer iter.Release()
+
+	var group, pendingKey, pendingValue []byte
+
+	// the history of a deleted key is empty, it can be deleted too if it is the oldest history
+	flushPending := func() {
+		if pendingKey != nil && len(pendingValue) == 0 {
+			p.delete(pendingKey, pendingValue)
+		}
+		pendingKey, pendingValue = nil, nil
+	}
+
+	for iter.Next() {
+		key := iter.Key()
+		if len(key) <= 8 {
+			continue
+		}
+		height := chain_utils.BytesToUint64(key[len(key)-8:])
+
+		if !bytes.Equal(key[:len(key)-8], group) {
+			flushPending()
+			group = append(group[:0], key[:len(key)-8]...)
+		}
+
+		switch {
+		case height < beforeHeight:
+			if pendingKey != nil {
+				p.delete(pendingKey, pendingValue)
+			}
+			pendingKey = append([]byte(nil), key...)
+			pendingValue = append([]byte(nil), iter.Value()...)
+		case height == beforeHeight:
+			if pendingKey != nil {
+				p.delete(pendingKey, pendingValue)
+			}
+			pendingKey, pendingValue = nil, nil
+		}
+	}
+	flushPending()
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	p.commit()
+	return nil
+}
+
+func pruneRedoLogs(p *pruner, beforeHeight uint64) error {
+	iter := p.store.NewIterator(&util.Range{
+		Start: chain_utils.CreateRedoSnapshot(0).Bytes(),
+		Limit: chain_utils.CreateRedoSnapshot(beforeHeight).Bytes(),
+	})
+	defer iter.Release()
+
+	for iter.Next() {
+		p.delete(append([]byte(nil), iter.Key()...), iter.Value())
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	p.commit()
+	return nil
+}
+
+// pruneVmLogs walks the account blocks snapshotted below beforeHeight and deletes their vm logs.
+// Before the seed upgrade the hash of the vm logs doesn't contain the address and the prev hash,
+// the same vm logs of the later blocks share the key, so they are pruned only if beforeHeight is after the upgrade.
+func (c *chain) pruneVmLogs(p *pruner, beforeHeight uint64) error {
+	if !upgrade.IsSeedUpgrade(beforeHeight) {
+		return nil
+	}
+
+	for start := uint64(1); start < beforeHeight; start += pruneChunkBatchSize {
+		end := start + pruneChunkBatchSize - 1
+		if end >= beforeHeight {
+			end = beforeHeight - 1
+		}
+
+		// [start-1, end], the first chunk is the snapshot block at start-1
+		chunks, err := c.GetSubLedger(start-1, end)
+		if err != nil {
+			return err
+		}
+
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < start {
+				continue
+			}
+			for _, block := range chunk.AccountBlocks {
+				if err := pruneVmLog(p, block); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	p.commit()
+	return nil
+}
+
+func pruneVmLog(p *pruner, block *ledger.AccountBlock) error {
+	if block.LogHash == nil {
+		return nil
+	}
+
+	key := chain_utils.CreateVmLogListKey(block.LogHash).Bytes()
+	value, err := p.store.Get(key)
+	if err != nil {
+		return err
+	}
+	if len(value) > 0 {
+		p.delete(key, value)
+	}
+	return nil
+}
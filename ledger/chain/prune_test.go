@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+func TestPruneHistoryKeys(t *testing.T) {
+	dir, err := os.MkdirTemp("", "prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(path.Join(dir, "state"), "prune")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	addr := types.AddressQuota
+	history := map[string][]uint64{
+		"a": {1, 3, 5, 12}, // 5 is the value at 10
+		"b": {2, 10, 11},   // 10 is the value at 10
+		"c": {4, 6},        // 6 is the value at 10
+		"d": {15},          // nothing to prune
+		"e": {1, 7},        // 7 is deleted at 7, the key doesn't exist at 10
+	}
+	batch := store.NewBatch()
+	for key, heights := range history {
+		for _, height := range heights {
+			value := []byte{byte(height)}
+			if key == "e" && height == 7 {
+				value = nil
+			}
+			batch.Put(chain_utils.CreateHistoryStorageValueKey(&addr, []byte(key), height).Bytes(), value)
+		}
+	}
+	store.WriteDirectly(batch)
+
+	stat := &PruneStat{}
+	p := &pruner{
+		store:    store,
+		stat:     stat,
+		progress: func(*PruneStat) {},
+		flush:    func() {},
+	}
+	if err := pruneHistoryKeys(p, chain_utils.StorageHistoryKeyPrefix, 10); err != nil {
+		t.Fatal(err)
+	}
+	if stat.Keys != 6 {
+		t.Errorf("expected 6 keys pruned, got %d", stat.Keys)
+	}
+
+	remained := make(map[string][]uint64)
+	iter := store.NewIterator(util.BytesPrefix([]byte{chain_utils.StorageHistoryKeyPrefix}))
+	for iter.Next() {
+		key := iter.Key()
+		size := key[1+types.AddressSize+types.HashSize]
+		storageKey := string(key[1+types.AddressSize : 1+types.AddressSize+int(size)])
+		remained[storageKey] = append(remained[storageKey], chain_utils.BytesToUint64(key[len(key)-8:]))
+	}
+	iter.Release()
+
+	expected := map[string][]uint64{
+		"a": {5, 12},
+		"b": {10, 11},
+		"c": {6},
+		"d": {15},
+	}
+	if len(remained) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, remained)
+	}
+	for key, heights := range expected {
+		if len(remained[key]) != len(heights) {
+			t.Fatalf("key %s: expected %v, got %v", key, heights, remained[key])
+		}
+		for i := range heights {
+			if remained[key][i] != heights[i] {
+				t.Fatalf("key %s: expected %v, got %v", key, heights, remained[key])
+			}
+		}
+	}
+}
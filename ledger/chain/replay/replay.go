@@ -0,0 +1,367 @@
+package chain_replay
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+)
+
+// heights of the snapshot chunks read from the source chain at a time
+const readBatch = 100
+
+// DivergenceKind is the kind of the difference between the replayed chain and the source chain
+type DivergenceKind string
+
+const (
+	DivergenceBlock   DivergenceKind = "block"   // the re-executed account block is different from the source block
+	DivergenceBalance DivergenceKind = "balance" // the balance of a token is different at the snapshot
+	DivergenceStorage DivergenceKind = "storage" // the contract storage is different at the snapshot
+)
+
+// Divergence is the first difference found by the replay
+type Divergence struct {
+	Kind           DivergenceKind
+	SnapshotHeight uint64 // the snapshot confirming the block, or the snapshot where the state is compared
+	Address        types.Address
+	BlockHash      *types.Hash // the block of a DivergenceBlock
+	Detail         string
+}
+
+func (d *Divergence) String() string {
+	if d.BlockHash != nil {
+		return fmt.Sprintf("%s divergence at snapshot %d, address %s, block %s: %s", d.Kind, d.SnapshotHeight, d.Address, d.BlockHash, d.Detail)
+	}
+	return fmt.Sprintf("%s divergence at snapshot %d, address %s: %s", d.Kind, d.SnapshotHeight, d.Address, d.Detail)
+}
+
+// Config is the configuration of a replay
+type Config struct {
+	// Dir is the data dir of the replayed chain, it should be empty
+	Dir string
+	// ToHeight is the last snapshot height to replay, 0 means the latest snapshot block of the source chain
+	ToHeight uint64
+}
+
+// Replayer re-executes the account blocks of the source chain from the genesis with the vm, inserts the results
+// into a fresh chain, and compares the balances and the storage of the touched accounts with the source chain
+// at every snapshot block.
+type Replayer struct {
+	source    chain.Chain
+	target    chain.Chain
+	consensus chain.Consensus
+	toHeight  uint64
+}
+
+// NewReplayer creates the fresh chain in cfg.Dir with the genesis of the source chain. The consensus reads the SBP
+// information of the source chain for the vm.
+func NewReplayer(source chain.Chain, consensus chain.Consensus, genesis *config.Genesis, cfg Config) (*Replayer, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("the dir of the replayed chain is not set")
+	}
+	latestHeight := source.GetLatestSnapshotBlock().Height
+	toHeight := cfg.ToHeight
+	if toHeight == 0 || toHeight > latestHeight {
+		toHeight = latestHeight
+	}
+
+	target := chain.NewChain(cfg.Dir, &config.Chain{}, genesis)
+	if err := target.Init(); err != nil {
+		return nil, err
+	}
+	if target.GetGenesisSnapshotBlock().Hash != source.GetGenesisSnapshotBlock().Hash {
+		target.Destroy()
+		return nil, errors.New("the genesis of the replayed chain is different from the source chain")
+	}
+	if target.GetLatestSnapshotBlock().Height != 1 {
+		target.Destroy()
+		return nil, fmt.Errorf("the replayed chain in %s is not empty", cfg.Dir)
+	}
+	if err := target.Start(); err != nil {
+		target.Destroy()
+		return nil, err
+	}
+	target.SetConsensus(consensus)
+
+	return &Replayer{
+		source:    source,
+		target:    target,
+		consensus: consensus,
+		toHeight:  toHeight,
+	}, nil
+}
+
+// ToHeight returns the last snapshot height to replay
+func (r *Replayer) ToHeight() uint64 {
+	return r.toHeight
+}
+
+// Close stops the replayed chain, the data dir is kept
+func (r *Replayer) Close() error {
+	if err := r.target.Stop(); err != nil {
+		return err
+	}
+	return r.target.Destroy()
+}
+
+// Run replays the snapshot chunks after the genesis to the last height, progress is called after every snapshot
+// block is replayed and checked. It returns the first divergence, nil if the replayed state is the same as
+// the source chain.
+func (r *Replayer) Run(progress func(height uint64)) (*Divergence, error) {
+	for start := uint64(1); start < r.toHeight; start += readBatch {
+		end := start + readBatch
+		if end > r.toHeight {
+			end = r.toHeight
+		}
+
+		// the first chunk only has the snapshot block of start, which is replayed already
+		chunks, err := r.source.GetSubLedger(start, end)
+		if err != nil {
+			return nil, err
+		}
+		if len(chunks) != int(end-start)+1 {
+			return nil, fmt.Errorf("read %d snapshot chunks in [%d, %d] of the source chain", len(chunks), start, end)
+		}
+
+		for _, chunk := range chunks[1:] {
+			divergence, err := r.replayChunk(chunk)
+			if err != nil || divergence != nil {
+				return divergence, err
+			}
+			if progress != nil {
+				progress(chunk.SnapshotBlock.Height)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *Replayer) replayChunk(chunk *ledger.SnapshotChunk) (*Divergence, error) {
+	sb := chunk.SnapshotBlock
+	if sb == nil {
+		return nil, errors.New("the snapshot chunk has no snapshot block")
+	}
+
+	touched := make(map[types.Address]struct{})
+	for _, block := range chunk.AccountBlocks {
+		divergence, err := r.replayBlock(sb.Height, block)
+		if err != nil || divergence != nil {
+			return divergence, err
+		}
+		touched[block.AccountAddress] = struct{}{}
+	}
+
+	invalidBlocks, err := r.target.InsertSnapshotBlock(sb)
+	if err != nil {
+		return nil, fmt.Errorf("insert snapshot block %d %s failed: %s", sb.Height, sb.Hash, err)
+	}
+	if len(invalidBlocks) > 0 {
+		return nil, fmt.Errorf("%d replayed account blocks are not confirmed by snapshot block %d %s", len(invalidBlocks), sb.Height, sb.Hash)
+	}
+
+	addrList := make([]types.Address, 0, len(touched))
+	for addr := range touched {
+		addrList = append(addrList, addr)
+	}
+	sort.Slice(addrList, func(i, j int) bool {
+		return bytes.Compare(addrList[i].Bytes(), addrList[j].Bytes()) < 0
+	})
+
+	if divergence, err := r.checkBalances(sb, addrList); err != nil || divergence != nil {
+		return divergence, err
+	}
+	for _, addr := range addrList {
+		if !types.IsContractAddr(addr) {
+			continue
+		}
+		expected := r.source.GetStorageIteratorAt(sb.Height, addr, nil)
+		actual := r.target.GetStorageIteratorAt(sb.Height, addr, nil)
+		divergence, err := compareStorage(sb.Height, addr, expected, actual)
+		expected.Release()
+		actual.Release()
+		if err != nil || divergence != nil {
+			return divergence, err
+		}
+	}
+	return nil, nil
+}
+
+// replayBlock re-executes the block on the latest snapshot block of the replayed chain, as the verifier does
+func (r *Replayer) replayBlock(snapshotHeight uint64, block *ledger.AccountBlock) (*Divergence, error) {
+	newDivergence := func(format string, args ...interface{}) *Divergence {
+		hash := block.Hash
+		return &Divergence{
+			Kind:           DivergenceBlock,
+			SnapshotHeight: snapshotHeight,
+			Address:        block.AccountAddress,
+			BlockHash:      &hash,
+			Detail:         fmt.Sprintf(format, args...),
+		}
+	}
+
+	var fromBlock *ledger.AccountBlock
+	if block.IsReceiveBlock() {
+		var err error
+		fromBlock, err = r.target.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if fromBlock == nil {
+			return newDivergence("the send block %s is not replayed", block.FromBlockHash), nil
+		}
+	}
+
+	latestSb := r.target.GetLatestSnapshotBlock()
+	gen, err := generator.NewGenerator(r.target, r.consensus, block.AccountAddress, &latestSb.Hash, &block.PrevHash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := gen.GenerateWithBlock(block, fromBlock)
+	if err != nil {
+		return newDivergence("generate failed: %s", err), nil
+	}
+	if result.VMBlock == nil {
+		return newDivergence("vm failed: %v", result.Err), nil
+	}
+	if field := diffBlock(block, result.VMBlock.AccountBlock); field != "" {
+		return newDivergence("%s is different, replayed block %s", field, result.VMBlock.AccountBlock.Hash), nil
+	}
+
+	if err := r.target.InsertAccountBlock(result.VMBlock); err != nil {
+		return nil, fmt.Errorf("insert account block %s failed: %s", block.Hash, err)
+	}
+	return nil, nil
+}
+
+// checkBalances compares the confirmed balances of the tokens held by the accounts in either chain
+func (r *Replayer) checkBalances(sb *ledger.SnapshotBlock, addrList []types.Address) (*Divergence, error) {
+	tokens := make(map[types.TokenTypeId]struct{})
+	for _, c := range []chain.Chain{r.source, r.target} {
+		for _, addr := range addrList {
+			balanceMap, err := c.GetBalanceMap(addr)
+			if err != nil {
+				return nil, err
+			}
+			for tokenId := range balanceMap {
+				tokens[tokenId] = struct{}{}
+			}
+		}
+	}
+
+	tokenList := make([]types.TokenTypeId, 0, len(tokens))
+	for tokenId := range tokens {
+		tokenList = append(tokenList, tokenId)
+	}
+	sort.Slice(tokenList, func(i, j int) bool {
+		return bytes.Compare(tokenList[i].Bytes(), tokenList[j].Bytes()) < 0
+	})
+
+	for _, tokenId := range tokenList {
+		expected, err := r.source.GetConfirmedBalanceList(addrList, tokenId, sb.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("get the balances of the source chain at snapshot %d failed: %s", sb.Height, err)
+		}
+		actual, err := r.target.GetConfirmedBalanceList(addrList, tokenId, sb.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("get the balances of the replayed chain at snapshot %d failed: %s", sb.Height, err)
+		}
+		for _, addr := range addrList {
+			if e, a := balanceOf(expected, addr), balanceOf(actual, addr); e.Cmp(a) != 0 {
+				return &Divergence{
+					Kind:           DivergenceBalance,
+					SnapshotHeight: sb.Height,
+					Address:        addr,
+					Detail:         fmt.Sprintf("token %s, expected %s, replayed %s", tokenId, e, a),
+				}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func balanceOf(balanceMap map[types.Address]*big.Int, addr types.Address) *big.Int {
+	if balance := balanceMap[addr]; balance != nil {
+		return balance
+	}
+	return big.NewInt(0)
+}
+
+// compareStorage compares the key-values of the storage iterators in order
+func compareStorage(snapshotHeight uint64, addr types.Address, expected, actual interfaces.StorageIterator) (*Divergence, error) {
+	newDivergence := func(format string, args ...interface{}) *Divergence {
+		return &Divergence{
+			Kind:           DivergenceStorage,
+			SnapshotHeight: snapshotHeight,
+			Address:        addr,
+			Detail:         fmt.Sprintf(format, args...),
+		}
+	}
+
+	for {
+		eOk, aOk := expected.Next(), actual.Next()
+		if !eOk || !aOk {
+			if err := expected.Error(); err != nil {
+				return nil, err
+			}
+			if err := actual.Error(); err != nil {
+				return nil, err
+			}
+		}
+		switch {
+		case !eOk && !aOk:
+			return nil, nil
+		case !aOk:
+			return newDivergence("key %x is missing in the replayed storage", expected.Key()), nil
+		case !eOk:
+			return newDivergence("key %x is not in the source storage", actual.Key()), nil
+		}
+
+		if !bytes.Equal(expected.Key(), actual.Key()) {
+			if bytes.Compare(expected.Key(), actual.Key()) < 0 {
+				return newDivergence("key %x is missing in the replayed storage", expected.Key()), nil
+			}
+			return newDivergence("key %x is not in the source storage", actual.Key()), nil
+		}
+		if !bytes.Equal(expected.Value(), actual.Value()) {
+			return newDivergence("key %x, expected %x, replayed %x", expected.Key(), expected.Value(), actual.Value()), nil
+		}
+	}
+}
+
+// diffBlock returns the first different field of the re-executed block, "" if the blocks are the same
+func diffBlock(origBlock, genBlock *ledger.AccountBlock) string {
+	if origBlock.Hash == genBlock.Hash {
+		return ""
+	}
+	switch {
+	case origBlock.BlockType != genBlock.BlockType:
+		return "BlockType"
+	case origBlock.Height != genBlock.Height:
+		return "Height"
+	case origBlock.PrevHash != genBlock.PrevHash:
+		return "PrevHash"
+	case origBlock.FromBlockHash != genBlock.FromBlockHash:
+		return "FromBlockHash"
+	case !bytes.Equal(origBlock.Data, genBlock.Data):
+		return "Data"
+	case (origBlock.LogHash == nil) != (genBlock.LogHash == nil),
+		origBlock.LogHash != nil && *origBlock.LogHash != *genBlock.LogHash:
+		return "LogHash"
+	case len(origBlock.SendBlockList) != len(genBlock.SendBlockList):
+		return "SendBlockList len"
+	}
+	for i, sendBlock := range origBlock.SendBlockList {
+		if sendBlock.Hash != genBlock.SendBlockList[i].Hash {
+			return fmt.Sprintf("SendBlockList[%d] Hash", i)
+		}
+	}
+	return "Hash"
+}
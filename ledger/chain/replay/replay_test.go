@@ -0,0 +1,102 @@
+package chain_replay
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// sliceIterator iterates the sorted key-values
+type sliceIterator struct {
+	kvs   [][2]string
+	index int
+}
+
+func newSliceIterator(kvs ...[2]string) *sliceIterator {
+	return &sliceIterator{kvs: kvs, index: -1}
+}
+
+func (iter *sliceIterator) Last() bool {
+	iter.index = len(iter.kvs) - 1
+	return iter.index >= 0
+}
+
+func (iter *sliceIterator) Prev() bool {
+	if iter.index >= 0 {
+		iter.index--
+	}
+	return iter.index >= 0
+}
+
+func (iter *sliceIterator) Seek(key []byte) bool {
+	for iter.index = 0; iter.index < len(iter.kvs); iter.index++ {
+		if iter.kvs[iter.index][0] >= string(key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (iter *sliceIterator) Next() bool {
+	if iter.index < len(iter.kvs) {
+		iter.index++
+	}
+	return iter.index < len(iter.kvs)
+}
+
+func (iter *sliceIterator) Key() []byte   { return []byte(iter.kvs[iter.index][0]) }
+func (iter *sliceIterator) Value() []byte { return []byte(iter.kvs[iter.index][1]) }
+func (iter *sliceIterator) Error() error  { return nil }
+func (iter *sliceIterator) Release()      {}
+
+func TestCompareStorage(t *testing.T) {
+	addr := types.AddressGovernance
+	cases := []struct {
+		expected, actual [][2]string
+		diverged         bool
+	}{
+		{nil, nil, false},
+		{[][2]string{{"a", "1"}, {"b", "2"}}, [][2]string{{"a", "1"}, {"b", "2"}}, false},
+		{[][2]string{{"a", "1"}, {"b", "2"}}, [][2]string{{"a", "1"}}, true},
+		{[][2]string{{"a", "1"}}, [][2]string{{"a", "1"}, {"b", "2"}}, true},
+		{[][2]string{{"a", "1"}, {"c", "3"}}, [][2]string{{"a", "1"}, {"b", "3"}}, true},
+		{[][2]string{{"a", "1"}, {"b", "2"}}, [][2]string{{"a", "1"}, {"b", "3"}}, true},
+	}
+	for i, c := range cases {
+		divergence, err := compareStorage(10, addr, newSliceIterator(c.expected...), newSliceIterator(c.actual...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (divergence != nil) != c.diverged {
+			t.Fatalf("case %d: divergence %v, expected %v", i, divergence, c.diverged)
+		}
+		if divergence != nil && (divergence.Kind != DivergenceStorage || divergence.SnapshotHeight != 10 || divergence.Address != addr) {
+			t.Fatalf("case %d: wrong divergence %s", i, divergence)
+		}
+	}
+}
+
+func TestDiffBlock(t *testing.T) {
+	orig := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Height: 2, Data: []byte{1}}
+	orig.Hash = orig.ComputeHash()
+
+	gen := *orig
+	if field := diffBlock(orig, &gen); field != "" {
+		t.Fatalf("the same block differs in %s", field)
+	}
+
+	gen.Data = []byte{2}
+	gen.Hash = gen.ComputeHash()
+	if field := diffBlock(orig, &gen); field != "Data" {
+		t.Fatalf("expected Data, got %s", field)
+	}
+
+	logHash := types.DataHash([]byte{1})
+	gen = *orig
+	gen.LogHash = &logHash
+	gen.Hash = gen.ComputeHash()
+	if field := diffBlock(orig, &gen); field != "LogHash" {
+		t.Fatalf("expected LogHash, got %s", field)
+	}
+}
@@ -0,0 +1,93 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+// rollbackJournal is the target of a rollback deleting the snapshot blocks in more than one batch. Every batch is
+// committed to the block db, the index db and the state db atomically by the flusher, but a crash between the batches
+// leaves the chain at a height between FromHeight and ToHeight, so the rollback is completed at startup.
+type rollbackJournal struct {
+	FromHeight uint64 // the latest height before rolling back
+	ToHeight   uint64 // the snapshot blocks from ToHeight are deleted
+
+	// the latest location of the block db after rolling back
+	Location chain_file_manager.Location
+}
+
+const rollbackJournalSize = 28
+
+func (j *rollbackJournal) Serialize() []byte {
+	value := make([]byte, 0, rollbackJournalSize)
+	value = append(value, chain_utils.Uint64ToBytes(j.FromHeight)...)
+	value = append(value, chain_utils.Uint64ToBytes(j.ToHeight)...)
+	return append(value, chain_utils.SerializeLocation(&j.Location)...)
+}
+
+func (j *rollbackJournal) Deserialize(value []byte) error {
+	if len(value) != rollbackJournalSize {
+		return fmt.Errorf("invalid rollback journal, length is %d", len(value))
+	}
+	j.FromHeight = chain_utils.BytesToUint64(value[:8])
+	j.ToHeight = chain_utils.BytesToUint64(value[8:16])
+	j.Location = *chain_utils.DeserializeLocation(value[16:])
+	return nil
+}
+
+// writeRollbackJournal saves the journal at the flush boundary, the stores are at the same height on disk
+func (c *chain) writeRollbackJournal(j *rollbackJournal) error {
+	return c.flusher.Checkpoint(func() error {
+		return c.metaDB.Put([]byte{RollbackJournalKey}, j.Serialize(), nil)
+	})
+}
+
+func (c *chain) removeRollbackJournal() error {
+	return c.metaDB.Delete([]byte{RollbackJournalKey}, nil)
+}
+
+// queryRollbackJournal returns nil if no rollback is in progress
+func (c *chain) queryRollbackJournal() (*rollbackJournal, error) {
+	value, err := c.metaDB.Get([]byte{RollbackJournalKey}, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	j := &rollbackJournal{}
+	if err := j.Deserialize(value); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// completeRollback finishes the rollback interrupted by a crash, and checks that the block db is at the location
+// recorded in the journal. It's called after the cache is initialized.
+func (c *chain) completeRollback() error {
+	j, err := c.queryRollbackJournal()
+	if err != nil || j == nil {
+		return err
+	}
+
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	if latestHeight >= j.ToHeight {
+		c.log.Warn(fmt.Sprintf("rolling back from %d to %d was interrupted at %d, complete it", j.FromHeight, j.ToHeight-1, latestHeight),
+			"method", "completeRollback")
+		if _, err := c.deleteSnapshotBlocksInBatches(j.ToHeight); err != nil {
+			return err
+		}
+	}
+
+	if location := c.blockDB.LatestLocation(); location.Compare(&j.Location) != 0 {
+		return fmt.Errorf("the block db is at %s after rolling back to %d, expected %s", location, j.ToHeight-1, &j.Location)
+	}
+	if latest := c.GetLatestSnapshotBlock().Height; latest != j.ToHeight-1 {
+		return fmt.Errorf("the latest snapshot block is %d after rolling back, expected %d", latest, j.ToHeight-1)
+	}
+	return c.removeRollbackJournal()
+}
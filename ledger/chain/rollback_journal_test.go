@@ -0,0 +1,31 @@
+package chain
+
+import (
+	"testing"
+
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+func TestRollbackJournal_Serialize(t *testing.T) {
+	j := &rollbackJournal{
+		FromHeight: 1000,
+		ToHeight:   500,
+		Location:   *chain_file_manager.NewLocation(3, 1024),
+	}
+	value := j.Serialize()
+	if len(value) != rollbackJournalSize {
+		t.Fatalf("expected %d bytes, got %d", rollbackJournalSize, len(value))
+	}
+
+	j2 := &rollbackJournal{}
+	if err := j2.Deserialize(value); err != nil {
+		t.Fatal(err)
+	}
+	if j2.FromHeight != j.FromHeight || j2.ToHeight != j.ToHeight || j2.Location.Compare(&j.Location) != 0 {
+		t.Fatalf("expected %+v, got %+v", j, j2)
+	}
+
+	if err := j2.Deserialize(value[:20]); err == nil {
+		t.Fatal("expected invalid length error")
+	}
+}
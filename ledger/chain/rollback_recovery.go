@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	chain_archive "github.com/vitelabs/go-vite/v2/ledger/chain/archive"
+)
+
+const (
+	// the snapshot blocks deleted by a rollback deeper than it are saved to a recovery file before deleting
+	recoveryRollbackDepth = 120
+
+	// the oldest recovery files are removed if there are more
+	maxRollbackRecoveries = 5
+
+	recoveryBatchSize = 1000
+
+	rollbackRecoveryDirName = "rollback_recovery"
+)
+
+// RollbackRecoveryDir is the dir of the recovery files of the rollbacks, under the data dir
+func RollbackRecoveryDir(dataDir string) string {
+	return filepath.Join(dataDir, rollbackRecoveryDirName)
+}
+
+// RollbackRecoveries returns the recovery files of the rollbacks, the latest is the last
+func RollbackRecoveries(dataDir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(RollbackRecoveryDir(dataDir), "*.chunks"))
+	if err != nil {
+		return nil, err
+	}
+	// the names start with the unix time
+	sort.Strings(files)
+	return files, nil
+}
+
+// saveRollbackRecovery writes the snapshot chunks [fromHeight, toHeight] to be deleted by a rollback to a chunk archive,
+// they can be inserted again by `gvite ledger restore-rollback` if the rollback is a mistake.
+func (c *chain) saveRollbackRecovery(fromHeight, toHeight uint64) (string, error) {
+	dir := RollbackRecoveryDir(c.dataDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%d_%d_%d.chunks", time.Now().Unix(), fromHeight, toHeight)
+	file := filepath.Join(dir, name)
+	tmpFile := file + ".tmp"
+
+	f, err := os.Create(tmpFile)
+	if err != nil {
+		return "", err
+	}
+	err = c.writeRollbackRecovery(f, fromHeight, toHeight)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile, file)
+	}
+	if err != nil {
+		os.Remove(tmpFile)
+		return "", err
+	}
+
+	c.removeOldRollbackRecoveries()
+	return file, nil
+}
+
+func (c *chain) writeRollbackRecovery(f *os.File, fromHeight, toHeight uint64) error {
+	aw, err := chain_archive.NewArchiveWriter(f, fromHeight, toHeight)
+	if err != nil {
+		return err
+	}
+	for start := fromHeight; start <= toHeight; start += recoveryBatchSize {
+		end := start + recoveryBatchSize - 1
+		if end > toHeight {
+			end = toHeight
+		}
+		// the first chunk is the snapshot block at start-1
+		chunks, err := c.GetSubLedger(start-1, end)
+		if err != nil {
+			return err
+		}
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < start {
+				continue
+			}
+			if err := aw.WriteChunk(chunk); err != nil {
+				return err
+			}
+		}
+	}
+	return aw.Close()
+}
+
+func (c *chain) removeOldRollbackRecoveries() {
+	files, err := RollbackRecoveries(c.dataDir)
+	if err != nil {
+		c.log.Error(fmt.Sprintf("list rollback recoveries failed. Error: %s", err), "method", "removeOldRollbackRecoveries")
+		return
+	}
+	for len(files) > maxRollbackRecoveries {
+		if err := os.Remove(files[0]); err != nil {
+			c.log.Error(fmt.Sprintf("remove rollback recovery %s failed. Error: %s", files[0], err), "method", "removeOldRollbackRecoveries")
+		}
+		files = files[1:]
+	}
+}
@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+func TestRollbackRecoveries(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "rollback_recovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	c := &chain{dataDir: dataDir, log: log15.New("module", "chain")}
+	if err := os.MkdirAll(RollbackRecoveryDir(dataDir), 0700); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < maxRollbackRecoveries+2; i++ {
+		name := fmt.Sprintf("%d_%d_%d.chunks", 1600000000+i, 100, 300)
+		if err := ioutil.WriteFile(filepath.Join(RollbackRecoveryDir(dataDir), name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.removeOldRollbackRecoveries()
+
+	files, err := RollbackRecoveries(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != maxRollbackRecoveries {
+		t.Fatalf("expected %d files, got %d", maxRollbackRecoveries, len(files))
+	}
+	if filepath.Base(files[len(files)-1]) != fmt.Sprintf("%d_100_300.chunks", 1600000000+maxRollbackRecoveries+1) {
+		t.Fatalf("the latest file should be the last, got %s", files[len(files)-1])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/iterator"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
@@ -13,7 +14,7 @@ import (
 func (c *chain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
 	result, err := c.stateDB.GetBalance(addr, tokenId)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetBalance failed, Addr is %s, tokenId is %s. Error: %s", addr, tokenId, err)
+		cErr := fmt.Errorf("c.stateDB.GetBalance failed, Addr is %s, tokenId is %s. Error: %w", addr, tokenId, err)
 		c.log.Error(cErr.Error(), "method", "GetBalance")
 		return nil, cErr
 	}
@@ -22,7 +23,7 @@ func (c *chain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.
 func (c *chain) GetBalanceMap(addr types.Address) (map[types.TokenTypeId]*big.Int, error) {
 	result, err := c.stateDB.GetBalanceMap(addr)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetBalanceMap failed, Addr is %s. Error: %s,", addr, err)
+		cErr := fmt.Errorf("c.stateDB.GetBalanceMap failed, Addr is %s. Error: %w,", addr, err)
 		c.log.Error(cErr.Error(), "method", "GetBalance")
 		return nil, cErr
 	}
@@ -31,6 +32,10 @@ func (c *chain) GetBalanceMap(addr types.Address) (map[types.TokenTypeId]*big.In
 
 // get confirmed snapshot Balance, if history is too old, failed
 func (c *chain) GetConfirmedBalanceList(addrList []types.Address, tokenId types.TokenTypeId, sbHash types.Hash) (map[types.Address]*big.Int, error) {
+	if err := c.checkHistoryHash(sbHash); err != nil {
+		return nil, err
+	}
+
 	balanceMap := make(map[types.Address]*big.Int, len(addrList))
 
 	if err := c.stateDB.GetSnapshotBalanceList(balanceMap, sbHash, addrList, tokenId); err != nil {
@@ -45,20 +50,30 @@ func (c *chain) GetConfirmedBalanceList(addrList []types.Address, tokenId types.
 func (c *chain) GetContractCode(contractAddress types.Address) ([]byte, error) {
 	code, err := c.stateDB.GetCode(contractAddress)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetCode failed, error is %s, Addr is %s", err, contractAddress)
+		cErr := fmt.Errorf("c.stateDB.GetCode failed, error is %w, Addr is %s", err, contractAddress)
 		c.log.Error(cErr.Error(), "method", "GetBalance")
 		return nil, cErr
 	}
 	return code, nil
 }
 
+// latestSnapshotHeight returns the height of the latest snapshot block, 0 before the genesis is inserted
+func (c *chain) latestSnapshotHeight() uint64 {
+	if sb := c.GetLatestSnapshotBlock(); sb != nil {
+		return sb.Height
+	}
+	return 0
+}
+
+// GetContractMeta returns the meta of the contract, the built-in contracts are looked up by the latest snapshot block,
+// the meta used to execute a block is looked up by its snapshot block, see ledger.GetContractMetaAt
 func (c *chain) GetContractMeta(contractAddress types.Address) (*ledger.ContractMeta, error) {
-	if meta := ledger.GetBuiltinContractMeta(contractAddress); meta != nil {
+	if meta := ledger.GetBuiltinContractMeta(contractAddress, c.latestSnapshotHeight()); meta != nil {
 		return meta, nil
 	}
 	meta, err := c.stateDB.GetContractMeta(contractAddress)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetContractMeta failed, error is %s, Addr is %s", err, contractAddress)
+		cErr := fmt.Errorf("c.stateDB.GetContractMeta failed, error is %w, Addr is %s", err, contractAddress)
 		c.log.Error(cErr.Error(), "method", "GetBalance")
 		return nil, cErr
 	}
@@ -66,13 +81,13 @@ func (c *chain) GetContractMeta(contractAddress types.Address) (*ledger.Contract
 }
 
 func (c *chain) GetContractMetaInSnapshot(contractAddress types.Address, snapshotHeight uint64) (*ledger.ContractMeta, error) {
-	if meta := ledger.GetBuiltinContractMeta(contractAddress); meta != nil {
+	if meta := ledger.GetBuiltinContractMeta(contractAddress, snapshotHeight); meta != nil {
 		return meta, nil
 	}
 
 	meta, err := c.stateDB.GetContractMeta(contractAddress)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetContractMeta failed, error is %s, Addr is %s", err, contractAddress)
+		cErr := fmt.Errorf("c.stateDB.GetContractMeta failed, error is %w, Addr is %s", err, contractAddress)
 		c.log.Error(cErr.Error(), "method", "GetBalance")
 		return nil, cErr
 	}
@@ -97,12 +112,12 @@ func (c *chain) GetContractMetaInSnapshot(contractAddress types.Address, snapsho
 func (c *chain) GetContractList(gid types.Gid) ([]types.Address, error) {
 	addrList, err := c.stateDB.GetContractList(&gid)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetContractList failed, gid is %s. Error: %s", gid, err)
+		cErr := fmt.Errorf("c.stateDB.GetContractList failed, gid is %s. Error: %w", gid, err)
 		c.log.Error(cErr.Error(), "method", "GetContractList")
 		return nil, cErr
 	}
 	if util.IsDelegateGid(gid) {
-		addrList = append(addrList, types.BuiltinContracts...)
+		addrList = append(addrList, ledger.GetBuiltinContracts(c.latestSnapshotHeight())...)
 	}
 	return addrList, nil
 }
@@ -114,7 +129,7 @@ func (c *chain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 
 	logList, err := c.stateDB.GetVmLogList(logListHash)
 	if err != nil {
-		cErr := fmt.Errorf("c.stateDB.GetVmLogList failed, error is %s, logListHash is %s", err, logListHash)
+		cErr := fmt.Errorf("c.stateDB.GetVmLogList failed, error is %w, logListHash is %s", err, logListHash)
 		c.log.Error(cErr.Error(), "method", "GetVmLogList")
 		return nil, cErr
 	}
@@ -150,7 +165,7 @@ func (c *chain) GetVMLogListByAddress(address types.Address, start uint64, end u
 func (c *chain) GetQuotaUnused(address types.Address) (uint64, error) {
 	_, quotaInfo, err := c.GetStakeQuota(address)
 	if err != nil {
-		cErr := fmt.Errorf("c.GetStakeQuota failed, address is %s. Error: %s", address, err)
+		cErr := fmt.Errorf("c.GetStakeQuota failed, address is %s. Error: %w", address, err)
 		c.log.Error(cErr.Error(), "method", "GetQuotaUnused")
 		return 0, cErr
 	}
@@ -172,6 +187,41 @@ func (c *chain) GetStorageIterator(address types.Address, prefix []byte) (interf
 	return ss, nil
 }
 
+func (c *chain) GetSnapshotStorageIterator(snapshotHeight uint64, address types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	if err := c.CheckHistoryHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+	ss, err := c.stateDB.NewSnapshotStorageIteratorByHeight(snapshotHeight, address, prefix)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.NewSnapshotStorageIteratorByHeight failed, snapshotHeight is %d, address is %s. Error: %w", snapshotHeight, address, err)
+		c.log.Error(cErr.Error(), "method", "GetSnapshotStorageIterator")
+		return nil, cErr
+	}
+	return ss, nil
+}
+
+// GetStorageIteratorAt returns the iterator of the storage at the snapshot height, the error of a pruned height is
+// returned by the Error of the iterator
+func (c *chain) GetStorageIteratorAt(snapshotHeight uint64, address types.Address, prefix []byte) interfaces.StorageIterator {
+	if err := c.CheckHistoryHeight(snapshotHeight); err != nil {
+		return iterator.NewEmptyIterator(err)
+	}
+	return c.stateDB.NewStorageIteratorAt(address, prefix, snapshotHeight)
+}
+
+func (c *chain) GetSnapshotValue(snapshotHeight uint64, address types.Address, key []byte) ([]byte, error) {
+	if err := c.CheckHistoryHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+	value, err := c.stateDB.GetSnapshotValue(snapshotHeight, address, key)
+	if err != nil {
+		cErr := fmt.Errorf("c.stateDB.GetSnapshotValue failed, snapshotHeight is %d, address is %s. Error: %w", snapshotHeight, address, err)
+		c.log.Error(cErr.Error(), "method", "GetSnapshotValue")
+		return nil, cErr
+	}
+	return value, nil
+}
+
 func (c *chain) GetValue(address types.Address, key []byte) ([]byte, error) {
 	value, err := c.stateDB.GetStorageValue(&address, key)
 	if err != nil {
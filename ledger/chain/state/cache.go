@@ -1,8 +1,9 @@
 package chain_state
 
 import (
-	"github.com/patrickmn/go-cache"
+	"time"
 
+	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/ledger/chain/utils"
@@ -15,13 +16,7 @@ const (
 )
 
 func (sDB *StateDB) newCache() error {
-	var err error
-
-	sDB.cache = cache.New(cache.NoExpiration, cache.NoExpiration)
-
-	if err != nil {
-		return err
-	}
+	sDB.cache = newSizedLRU(sDB.chainCfg.StateCacheSize)
 	return nil
 }
 
@@ -52,7 +47,7 @@ func (sDB *StateDB) initSnapshotValueCache() error {
 		iter := sDB.NewStorageIterator(contractAddr, nil)
 		for iter.Next() {
 			//fmt.Printf("init set snapshot value cache: %d, %d\n", []byte(snapshotValuePrefix+addrStr+string(key)), sDB.copyValue(value))
-			sDB.cache.Set(snapshotValuePrefix+string(append(contractAddr.Bytes(), iter.Key()...)), sDB.copyValue(iter.Value()), cache.NoExpiration)
+			sDB.cache.Set(snapshotValuePrefix+string(append(contractAddr.Bytes(), iter.Key()...)), sDB.copyValue(iter.Value()))
 		}
 
 		returnErr := iter.Error()
@@ -72,7 +67,7 @@ func (sDB *StateDB) initContractMetaCache() error {
 	defer iter.Release()
 
 	for iter.Next() {
-		sDB.cache.Set(contractAddrPrefix+string(iter.Key()), sDB.copyValue(iter.Value()), cache.NoExpiration)
+		sDB.cache.Set(contractAddrPrefix+string(iter.Key()), sDB.copyValue(iter.Value()))
 	}
 	if err := iter.Error(); err != nil {
 		return err
@@ -80,21 +75,70 @@ func (sDB *StateDB) initContractMetaCache() error {
 	return nil
 }
 
+// WarmUp preloads the balances of the built-in contracts, the contracts in the vm log white list and the hot addresses,
+// the contract metas and the storage of the built-in contracts are loaded by Init. It stops when half of the cache is used,
+// so the entries loaded by Init are not evicted.
+func (sDB *StateDB) WarmUp(hotAddrs []types.Address) error {
+	addrSet := make(map[types.Address]struct{})
+	var addrList []types.Address
+	add := func(addr types.Address) {
+		if _, ok := addrSet[addr]; !ok {
+			addrSet[addr] = struct{}{}
+			addrList = append(addrList, addr)
+		}
+	}
+	for _, addr := range types.BuiltinContracts {
+		add(addr)
+	}
+	sDB.vmLogWhiteListMu.RLock()
+	for addr := range sDB.vmLogWhiteListSet {
+		add(addr)
+	}
+	sDB.vmLogWhiteListMu.RUnlock()
+	for _, addr := range hotAddrs {
+		add(addr)
+	}
+
+	budget := sDB.cache.Capacity() / 2
+	start := time.Now()
+	lastLog := start
+	sDB.log.Info("Begin warming up the cache", "addresses", len(addrList), "method", "WarmUp")
+
+	for i, addr := range addrList {
+		if sDB.cache.Size() >= budget {
+			sDB.log.Info("The cache is full, stop warming up", "warmed", i, "addresses", len(addrList), "method", "WarmUp")
+			break
+		}
+
+		iter := sDB.store.NewIterator(util.BytesPrefix(chain_utils.CreateBalanceKeyPrefix(addr)))
+		for iter.Next() {
+			sDB.cache.Set(balancePrefix+string(iter.Key()), sDB.copyValue(iter.Value()))
+		}
+		err := iter.Error()
+		iter.Release()
+		if err != nil && err != leveldb.ErrNotFound {
+			return err
+		}
+
+		if time.Since(lastLog) >= 5*time.Second {
+			lastLog = time.Now()
+			sDB.log.Info("Warming up the cache", "warmed", i+1, "addresses", len(addrList), "cacheSize", sDB.cache.Size(), "method", "WarmUp")
+		}
+	}
+
+	sDB.log.Info("Complete warming up the cache", "entries", sDB.cache.Len(), "cacheSize", sDB.cache.Size(),
+		"elapsed", time.Since(start), "method", "WarmUp")
+	return nil
+}
+
 // with cache
 func (sDB *StateDB) getValue(key []byte, cachePrefix string) ([]byte, error) {
-	ok := false
-	var value interface{}
 	if sDB.useCache {
-		value, ok = sDB.cache.Get(cachePrefix + string(key))
-	}
-	if !ok {
-		var err error
-		value, err = sDB.store.Get(key)
-		if err != nil {
-			return nil, err
+		if value, ok := sDB.cache.Get(cachePrefix + string(key)); ok {
+			return value, nil
 		}
 	}
-	return value.([]byte), nil
+	return sDB.store.Get(key)
 }
 
 func (sDB *StateDB) getValueInCache(key []byte, cachePrefix string) ([]byte, error) {
@@ -103,9 +147,26 @@ func (sDB *StateDB) getValueInCache(key []byte, cachePrefix string) ([]byte, err
 	}
 	value, ok := sDB.cache.Get(cachePrefix + string(key))
 	if !ok {
-		return nil, nil
+		// the cache is loaded completely by initCache, a miss means the key doesn't exist until some entries are evicted
+		if sDB.cache.Complete(cachePrefix) {
+			return nil, nil
+		}
+		return sDB.store.Get(key)
+	}
+	return value, nil
+}
+
+// ResizeCache changes the capacity of the cache in bytes, 0 means the default
+func (sDB *StateDB) ResizeCache(capacity int64) {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
 	}
-	return value.([]byte), nil
+	sDB.cache.Resize(capacity)
+}
+
+// ReportCacheMetrics updates the metrics of the cache
+func (sDB *StateDB) ReportCacheMetrics() {
+	sDB.cache.reportMetrics()
 }
 
 func (sDB *StateDB) parseStorageKey(key []byte) []byte {
@@ -29,7 +29,7 @@ func (sDB *StateDB) RollbackSnapshotBlocks(deletedSnapshotSegments []*ledger.Sna
 	newUnconfirmedLog, hasRedo, err := sDB.redo.QueryLog(latestSnapshotBlock.Height + 1)
 
 	if err != nil {
-		return fmt.Errorf("1. sDB.redo.QueryLog failed, height is %d. Error: %s", latestSnapshotBlock.Height+1, err.Error())
+		return fmt.Errorf("1. sDB.redo.QueryLog failed, height is %d. Error: %w", latestSnapshotBlock.Height+1, err)
 	}
 
 	snapshotHeight := latestSnapshotBlock.Height
@@ -55,7 +55,7 @@ func (sDB *StateDB) RollbackSnapshotBlocks(deletedSnapshotSegments []*ledger.Sna
 			} else {
 				currentSnapshotLog, _, err = sDB.redo.QueryLog(snapshotHeight)
 				if err != nil {
-					return fmt.Errorf("2. sDB.redo.QueryLog failed, height is %d. Error: %s", snapshotHeight, err.Error())
+					return fmt.Errorf("2. sDB.redo.QueryLog failed, height is %d. Error: %w", snapshotHeight, err)
 				}
 			}
 
@@ -84,7 +84,7 @@ func (sDB *StateDB) RollbackSnapshotBlocks(deletedSnapshotSegments []*ledger.Sna
 			// get old unconfirmed Log
 			if index == len(deletedSnapshotSegments)-1 && seg.SnapshotBlock == nil {
 				if oldUnconfirmedLog, _, err = sDB.redo.QueryLog(latestSnapshotBlock.Height + uint64(len(deletedSnapshotSegments))); err != nil {
-					return fmt.Errorf("3. sDB.redo.QueryLog failed, height is %d. Error: %s", latestSnapshotBlock.Height+uint64(len(deletedSnapshotSegments)), err.Error())
+					return fmt.Errorf("3. sDB.redo.QueryLog failed, height is %d. Error: %w", latestSnapshotBlock.Height+uint64(len(deletedSnapshotSegments)), err)
 				}
 			}
 
@@ -290,6 +290,11 @@ func (sDB *StateDB) rollbackByRedo(batch *leveldb.Batch, snapshotBlock *ledger.S
 				batch.Delete(chain_utils.CreateCallDepthKey(sendBlockHash).Bytes())
 
 			}
+
+			// delete execution error
+			for receiveHash := range redoLog.ExecutionError {
+				batch.Delete(chain_utils.CreateExecutionErrorKey(receiveHash).Bytes())
+			}
 		}
 
 		if len(keySet) > 0 {
@@ -416,6 +421,11 @@ func (sDB *StateDB) rollbackAccountBlock(batch *leveldb.Batch, accountBlock *led
 		batch.Delete(chain_utils.CreateVmLogListKey(accountBlock.LogHash).Bytes())
 	}
 
+	// delete execution error
+	if accountBlock.IsReceiveBlock() {
+		batch.Delete(chain_utils.CreateExecutionErrorKey(accountBlock.Hash).Bytes())
+	}
+
 	// delete call depth && contract meta
 	for _, sendBlock := range accountBlock.SendBlockList {
 		batch.Delete(chain_utils.CreateCallDepthKey(sendBlock.Hash).Bytes())
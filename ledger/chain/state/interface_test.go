@@ -1132,6 +1132,21 @@ func (mr *MockStateDBInterfaceMockRecorder) GetCallDepth(sendBlockHash interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCallDepth", reflect.TypeOf((*MockStateDBInterface)(nil).GetCallDepth), sendBlockHash)
 }
 
+// GetExecutionError mocks base method
+func (m *MockStateDBInterface) GetExecutionError(blockHash *types.Hash) (*ledger.ExecutionError, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExecutionError", blockHash)
+	ret0, _ := ret[0].(*ledger.ExecutionError)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExecutionError indicates an expected call of GetExecutionError
+func (mr *MockStateDBInterfaceMockRecorder) GetExecutionError(blockHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExecutionError", reflect.TypeOf((*MockStateDBInterface)(nil).GetExecutionError), blockHash)
+}
+
 // GetSnapshotBalanceList mocks base method
 func (m *MockStateDBInterface) GetSnapshotBalanceList(balanceMap map[types.Address]*big.Int, snapshotBlockHash types.Hash, addrList []types.Address, tokenId types.TokenTypeId) error {
 	m.ctrl.T.Helper()
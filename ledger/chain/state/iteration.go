@@ -25,7 +25,7 @@ func (sDB *StateDB) NewSnapshotStorageIteratorByHeight(snapshotHeight uint64, ad
 func (sDB *StateDB) NewSnapshotStorageIterator(snapshotHash types.Hash, addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
 	height, err := sDB.chain.GetSnapshotHeightByHash(snapshotHash)
 	if err != nil {
-		sErr := fmt.Errorf("sDB.chain.GetSnapshotHeightByHash failed, hash is %s. Error: %s", snapshotHash, err)
+		sErr := fmt.Errorf("sDB.chain.GetSnapshotHeightByHash failed, hash is %s. Error: %w", snapshotHash, err)
 		return nil, sErr
 	}
 
@@ -256,3 +256,213 @@ func (sIterator *snapshotStorageIterator) setLastKey(key []byte) {
 	// copy is important
 	copy(sIterator.lastKey, key)
 }
+
+// NewStorageIteratorAt returns the iterator of the contract storage at the snapshot height, the keys are filtered by the prefix.
+// For every storage key, the history values are merged into the latest value before or equal to the snapshot height,
+// the keys not existed or deleted at the height are skipped. Key() returns the storage key, use Seek(startKey) to paginate.
+func (sDB *StateDB) NewStorageIteratorAt(addr types.Address, prefix []byte, snapshotHeight uint64) interfaces.StorageIterator {
+	rangePrefix := chain_utils.CreateHistoryStorageValueKeyPrefix(&addr, prefix)
+	return &historyStorageIterator{
+		iter:           sDB.store.NewIterator(util.BytesPrefix(rangePrefix)),
+		addr:           addr,
+		rangePrefix:    rangePrefix,
+		snapshotHeight: snapshotHeight,
+	}
+}
+
+// GetStorageAt returns at most count key-values of the contract storage at the snapshot height from startKey,
+// the keys are filtered by the prefix. nextKey is the start key of the next page, nil if there is no more.
+func (sDB *StateDB) GetStorageAt(addr types.Address, prefix []byte, snapshotHeight uint64, startKey []byte, count int) (keys [][]byte, values [][]byte, nextKey []byte, err error) {
+	iter := sDB.NewStorageIteratorAt(addr, prefix, snapshotHeight)
+	defer iter.Release()
+
+	var ok bool
+	if len(startKey) > 0 {
+		ok = iter.Seek(startKey)
+	} else {
+		ok = iter.Next()
+	}
+	for ; ok; ok = iter.Next() {
+		if len(keys) >= count {
+			nextKey = append([]byte(nil), iter.Key()...)
+			break
+		}
+		keys = append(keys, append([]byte(nil), iter.Key()...))
+		values = append(values, append([]byte(nil), iter.Value()...))
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, nil, nil, err
+	}
+	return keys, values, nextKey, nil
+}
+
+// historyStorageIterator walks the history storage keys, which are sorted by [storage key][height],
+// and stops at the storage keys which have a value at the snapshot height
+type historyStorageIterator struct {
+	iter           interfaces.StorageIterator
+	addr           types.Address
+	rangePrefix    []byte
+	snapshotHeight uint64
+
+	started bool
+	ok      bool
+	group   []byte // the history key without the height of the current position
+	value   []byte
+}
+
+func (hIter *historyStorageIterator) Last() bool {
+	hIter.started = true
+	if !hIter.iter.Last() {
+		return hIter.stop()
+	}
+	return hIter.prevGroup()
+}
+
+func (hIter *historyStorageIterator) Prev() bool {
+	if !hIter.started {
+		return hIter.Last()
+	}
+	if !hIter.ok {
+		return false
+	}
+	// the first history of the current storage key, then the last history of the previous storage key
+	if !hIter.iter.Seek(hIter.historyKey(0)) || !hIter.iter.Prev() {
+		return hIter.stop()
+	}
+	return hIter.prevGroup()
+}
+
+func (hIter *historyStorageIterator) Seek(key []byte) bool {
+	hIter.started = true
+	if !hIter.iter.Seek(chain_utils.CreateHistoryStorageValueKey(&hIter.addr, key, 0).Bytes()) {
+		return hIter.stop()
+	}
+	return hIter.nextGroup()
+}
+
+func (hIter *historyStorageIterator) Next() bool {
+	var ok bool
+	if !hIter.started {
+		hIter.started = true
+		ok = hIter.iter.Seek(hIter.rangePrefix)
+	} else if hIter.ok {
+		// no history is at the max height, so it is the first history of the next storage key
+		ok = hIter.iter.Seek(hIter.historyKey(helper.MaxUint64))
+	}
+	if !ok {
+		return hIter.stop()
+	}
+	return hIter.nextGroup()
+}
+
+func (hIter *historyStorageIterator) Key() []byte {
+	if !hIter.ok {
+		return nil
+	}
+	keySize := hIter.group[1+types.AddressSize+types.HashSize]
+	return hIter.group[1+types.AddressSize : 1+types.AddressSize+int(keySize)]
+}
+
+func (hIter *historyStorageIterator) Value() []byte {
+	if !hIter.ok {
+		return nil
+	}
+	return hIter.value
+}
+
+func (hIter *historyStorageIterator) Error() error {
+	err := hIter.iter.Error()
+	if err != leveldb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func (hIter *historyStorageIterator) Release() {
+	hIter.iter.Release()
+}
+
+// nextGroup is called when the underlying iterator is at the first history of a storage key,
+// it moves forward until a storage key has a value at the snapshot height
+func (hIter *historyStorageIterator) nextGroup() bool {
+	for {
+		key := hIter.iter.Key()
+		group := append([]byte(nil), key[:len(key)-8]...)
+
+		var value []byte
+		found, ok := false, true
+		for ok {
+			key = hIter.iter.Key()
+			if !bytes.Equal(key[:len(key)-8], group) {
+				break
+			}
+			if binary.BigEndian.Uint64(key[len(key)-8:]) > hIter.snapshotHeight {
+				// skip the later histories of the storage key
+				hIter.group = group
+				ok = hIter.iter.Seek(hIter.historyKey(helper.MaxUint64))
+				break
+			}
+			value = append(value[:0], hIter.iter.Value()...)
+			found = true
+			ok = hIter.iter.Next()
+		}
+
+		if found && len(value) > 0 {
+			hIter.group, hIter.value, hIter.ok = group, value, true
+			return true
+		}
+		if !ok {
+			return hIter.stop()
+		}
+	}
+}
+
+// prevGroup is called when the underlying iterator is at the last history of a storage key,
+// it moves backward until a storage key has a value at the snapshot height
+func (hIter *historyStorageIterator) prevGroup() bool {
+	for {
+		key := hIter.iter.Key()
+		group := append([]byte(nil), key[:len(key)-8]...)
+
+		var value []byte
+		found, ok := false, true
+		for ok {
+			key = hIter.iter.Key()
+			if !bytes.Equal(key[:len(key)-8], group) {
+				break
+			}
+			if binary.BigEndian.Uint64(key[len(key)-8:]) <= hIter.snapshotHeight {
+				value = append(value, hIter.iter.Value()...)
+				found = true
+				// skip the earlier histories of the storage key
+				hIter.group = group
+				if ok = hIter.iter.Seek(hIter.historyKey(0)); ok {
+					ok = hIter.iter.Prev()
+				}
+				break
+			}
+			ok = hIter.iter.Prev()
+		}
+
+		if found && len(value) > 0 {
+			hIter.group, hIter.value, hIter.ok = group, value, true
+			return true
+		}
+		if !ok {
+			return hIter.stop()
+		}
+	}
+}
+
+func (hIter *historyStorageIterator) historyKey(height uint64) []byte {
+	key := make([]byte, len(hIter.group)+8)
+	copy(key, hIter.group)
+	binary.BigEndian.PutUint64(key[len(hIter.group):], height)
+	return key
+}
+
+func (hIter *historyStorageIterator) stop() bool {
+	hIter.ok = false
+	hIter.group, hIter.value = nil, nil
+	return false
+}
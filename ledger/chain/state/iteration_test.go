@@ -0,0 +1,93 @@
+package chain_state
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+func TestStateDB_NewStorageIteratorAt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_iteration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	sDB := &StateDB{store: store}
+
+	addr := types.AddressAsset
+	other := types.AddressQuota
+	batch := store.NewBatch()
+	put := func(addr types.Address, key string, height uint64, value string) {
+		batch.Put(chain_utils.CreateHistoryStorageValueKey(&addr, []byte(key), height).Bytes(), []byte(value))
+	}
+	put(addr, "a1", 2, "a1-2")
+	put(addr, "a1", 5, "a1-5")
+	put(addr, "a2", 6, "a2-6")
+	put(addr, "a3", 1, "a3-1")
+	put(addr, "a3", 4, "") // deleted
+	put(addr, "b1", 3, "b1-3")
+	put(other, "a0", 1, "other")
+	store.WriteDirectly(batch)
+
+	collect := func(prefix string, height uint64) []string {
+		iter := sDB.NewStorageIteratorAt(addr, []byte(prefix), height)
+		defer iter.Release()
+		var kvs []string
+		for iter.Next() {
+			kvs = append(kvs, string(iter.Key())+"="+string(iter.Value()))
+		}
+		if err := iter.Error(); err != nil {
+			t.Fatal(err)
+		}
+		return kvs
+	}
+	equal := func(got []string, expected ...string) {
+		t.Helper()
+		if len(got) != len(expected) {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+		for i := range got {
+			if got[i] != expected[i] {
+				t.Fatalf("expected %v, got %v", expected, got)
+			}
+		}
+	}
+
+	equal(collect("", 3), "a1=a1-2", "a3=a3-1", "b1=b1-3")
+	equal(collect("a", 4), "a1=a1-2")
+	equal(collect("a", 6), "a1=a1-5", "a2=a2-6")
+	equal(collect("b", 2))
+
+	iter := sDB.NewStorageIteratorAt(addr, nil, 6)
+	var backward []string
+	for ok := iter.Last(); ok; ok = iter.Prev() {
+		backward = append(backward, string(iter.Key()))
+	}
+	iter.Release()
+	equal(backward, "b1", "a2", "a1")
+
+	keys, values, nextKey, err := sDB.GetStorageAt(addr, nil, 6, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || string(keys[1]) != "a2" || string(values[1]) != "a2-6" || string(nextKey) != "b1" {
+		t.Fatalf("unexpected page %q %q %q", keys, values, nextKey)
+	}
+	keys, _, nextKey, err = sDB.GetStorageAt(addr, nil, 6, nextKey, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || string(keys[0]) != "b1" || nextKey != nil {
+		t.Fatalf("unexpected page %q %q", keys, nextKey)
+	}
+}
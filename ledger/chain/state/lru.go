@@ -0,0 +1,210 @@
+package chain_state
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+// DefaultCacheSize is the default size of the StateDB cache in bytes
+const DefaultCacheSize = 256 * 1024 * 1024
+
+// the memory used by an entry besides the key and the value, for the list element and the map entry
+const lruEntryOverhead = 96
+
+var (
+	cacheRequestsMetric = monitor.GetOrRegisterCounterVec("vite_statedb_cache_requests_total",
+		"requests of the StateDB cache", "result")
+	cacheBytesMetric = monitor.GetOrRegisterGauge("vite_statedb_cache_bytes",
+		"estimated memory of the StateDB cache in bytes")
+	cacheEntriesMetric = monitor.GetOrRegisterGauge("vite_statedb_cache_entries",
+		"entries of the StateDB cache")
+	cacheEvictionsMetric = monitor.GetOrRegisterCounterVec("vite_statedb_cache_evictions_total",
+		"entries evicted from the StateDB cache")
+)
+
+// stateCache is the cache of StateDB, a miss doesn't mean the key is not in the store
+type stateCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+	Delete(key string)
+	Purge()
+
+	// Complete returns false if some entries of the keys with the prefix are evicted or not cached
+	Complete(prefix string) bool
+
+	Len() int
+	Size() int64
+	Capacity() int64
+	Resize(capacity int64)
+
+	reportMetrics()
+}
+
+// sizedLRU is a stateCache bounded by the estimated memory of the keys and values,
+// the least recently used entries are evicted when it is full
+type sizedLRU struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	ll       *list.List
+	items    map[string]*list.Element
+
+	// the first bytes of the keys which are evicted or not cached
+	incomplete [256]bool
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+type lruEntry struct {
+	key   string
+	value []byte
+}
+
+func entrySize(key string, value []byte) int64 {
+	return int64(len(key)+len(value)) + lruEntryOverhead
+}
+
+func newSizedLRU(capacity int64) *sizedLRU {
+	if capacity <= 0 {
+		capacity = DefaultCacheSize
+	}
+	return &sizedLRU{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *sizedLRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if ok {
+		c.ll.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&c.hits, 1)
+	return elem.Value.(*lruEntry).value, true
+}
+
+// Set adds or replaces the entry, the value is not copied. The entry larger than the capacity is not cached.
+func (c *sizedLRU) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+	size := entrySize(key, value)
+	if size > c.capacity {
+		c.markIncomplete(key)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value})
+	c.size += size
+	c.evict()
+}
+
+func (c *sizedLRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *sizedLRU) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+	c.size = 0
+	for i := range c.incomplete {
+		c.incomplete[i] = true
+	}
+}
+
+func (c *sizedLRU) Complete(prefix string) bool {
+	if len(prefix) == 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.incomplete[prefix[0]]
+}
+
+func (c *sizedLRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *sizedLRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *sizedLRU) Capacity() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capacity
+}
+
+// Resize changes the capacity, the least recently used entries are evicted if the cache is larger than it
+func (c *sizedLRU) Resize(capacity int64) {
+	if capacity <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = capacity
+	c.evict()
+}
+
+func (c *sizedLRU) evict() {
+	for c.size > c.capacity {
+		elem := c.ll.Back()
+		if elem == nil {
+			return
+		}
+		c.markIncomplete(elem.Value.(*lruEntry).key)
+		c.removeElement(elem)
+		c.evictions++
+	}
+}
+
+func (c *sizedLRU) markIncomplete(key string) {
+	if len(key) > 0 {
+		c.incomplete[key[0]] = true
+	}
+}
+
+func (c *sizedLRU) removeElement(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	c.ll.Remove(elem)
+	delete(c.items, entry.key)
+	c.size -= entrySize(entry.key, entry.value)
+}
+
+// reportMetrics sets the metrics of the cache, the counters are the totals since the cache is created
+func (c *sizedLRU) reportMetrics() {
+	c.mu.Lock()
+	size, entries, evictions := c.size, c.ll.Len(), c.evictions
+	c.mu.Unlock()
+
+	cacheRequestsMetric.With("hit").Set(float64(atomic.LoadUint64(&c.hits)))
+	cacheRequestsMetric.With("miss").Set(float64(atomic.LoadUint64(&c.misses)))
+	cacheEvictionsMetric.With().Set(float64(evictions))
+	cacheBytesMetric.Set(float64(size))
+	cacheEntriesMetric.Set(float64(entries))
+}
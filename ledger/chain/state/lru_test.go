@@ -0,0 +1,99 @@
+package chain_state
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+func TestSizedLRU(t *testing.T) {
+	value := make([]byte, 100)
+	c := newSizedLRU(3 * entrySize("a1", value))
+
+	c.Set("a1", value)
+	c.Set("a2", value)
+	c.Set("b1", value)
+	assert.Equal(t, 3, c.Len())
+	assert.True(t, c.Complete("a"))
+
+	// a1 is the most recently used, a2 is evicted
+	_, ok := c.Get("a1")
+	assert.True(t, ok)
+	c.Set("b2", value)
+	_, ok = c.Get("a2")
+	assert.False(t, ok)
+	assert.False(t, c.Complete("a"))
+	assert.True(t, c.Complete("b"))
+	assert.Equal(t, 3*entrySize("a1", value), c.Size())
+
+	// replace
+	c.Set("b1", []byte{1})
+	v, ok := c.Get("b1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte{1}, v)
+	assert.Equal(t, 2*entrySize("a1", value)+entrySize("b1", []byte{1}), c.Size())
+
+	c.Delete("b1")
+	_, ok = c.Get("b1")
+	assert.False(t, ok)
+	assert.True(t, c.Complete("b"))
+
+	// too large to cache
+	c.Set("c1", make([]byte, 1000))
+	assert.Equal(t, 2, c.Len())
+	assert.False(t, c.Complete("c"))
+
+	c.Resize(entrySize("a1", value))
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, uint64(2), c.evictions)
+	assert.Equal(t, uint64(2), c.hits)
+	assert.Equal(t, uint64(2), c.misses)
+
+	c.Purge()
+	assert.Equal(t, 0, c.Len())
+	assert.Equal(t, int64(0), c.Size())
+	assert.False(t, c.Complete("b"))
+}
+
+func TestStateDB_WarmUp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_warm_up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(dir, "stateDb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	hot, _ := types.HexToAddress("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	cold, _ := types.HexToAddress("vite_2ad1b8f936f015fc80a2a5857dffb84b39f7675ab69ae31fc8")
+	batch := store.NewBatch()
+	batch.Put(chain_utils.CreateBalanceKey(hot, ledger.ViteTokenId).Bytes(), []byte{1})
+	batch.Put(chain_utils.CreateBalanceKey(cold, ledger.ViteTokenId).Bytes(), []byte{2})
+	store.WriteDirectly(batch)
+
+	sDB := &StateDB{
+		store:             store,
+		cache:             newSizedLRU(0),
+		log:               log15.New("module", "stateDB"),
+		vmLogWhiteListSet: parseVmLogWhiteList(nil),
+	}
+	assert.NoError(t, sDB.WarmUp([]types.Address{hot}))
+
+	value, ok := sDB.cache.Get(balancePrefix + string(chain_utils.CreateBalanceKey(hot, ledger.ViteTokenId).Bytes()))
+	assert.True(t, ok)
+	assert.Equal(t, []byte{1}, value)
+	_, ok = sDB.cache.Get(balancePrefix + string(chain_utils.CreateBalanceKey(cold, ledger.ViteTokenId).Bytes()))
+	assert.False(t, ok)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	chain_db "github.com/vitelabs/go-vite/v2/ledger/chain/db"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
 	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
 	"github.com/vitelabs/go-vite/v2/log15"
 )
@@ -23,6 +24,9 @@ type LogItem struct {
 	VmLogList    map[types.Hash][]byte
 	CallDepth    map[types.Hash]uint16
 	Height       uint64 // account block height
+
+	// ExecutionError is the reason why the receive block failed, keyed by the receive block hash
+	ExecutionError map[types.Hash][]byte
 }
 
 type SnapshotLog map[types.Address][]LogItem
@@ -33,7 +37,7 @@ func (sl *SnapshotLog) Serialize() ([]byte, error) {
 
 	err := enc.Encode(sl)
 	if err != nil {
-		return nil, fmt.Errorf("enc.Encode: %+v. Error: %s", sl, err.Error())
+		return nil, fmt.Errorf("enc.Encode: %+v. Error: %w", sl, err)
 	}
 
 	return valueBuffer.Bytes(), nil
@@ -48,7 +52,7 @@ func (sl *SnapshotLog) Deserialize(buf []byte) error {
 	dec := gob.NewDecoder(&valueBuffer)
 
 	if err := dec.Decode(sl); err != nil && err != io.EOF {
-		return fmt.Errorf("dec.Decode failed, buffer is %+v. Error: %s", buf, err)
+		return fmt.Errorf("%w: dec.Decode failed, buffer is %+v. Error: %s", chain_errors.ErrDBCorrupted, buf, err)
 	}
 	return nil
 }
@@ -87,7 +91,7 @@ func NewStorageRedoWithStore(chain Chain, store *chain_db.Store) (*Redo, error)
 	store.RegisterAfterRecover(func() {
 		redo.log.Info("after recover, redo.initCache()")
 		if err := redo.initCache(); err != nil {
-			panic(fmt.Errorf("after recover, redo.initCache failed, Error: %s", err.Error()))
+			panic(fmt.Errorf("after recover, redo.initCache failed, Error: %w", err))
 		}
 	})
 	return redo, nil
@@ -215,7 +219,7 @@ func (redo *Redo) QueryLog(snapshotHeight uint64) (SnapshotLog, bool, error) {
 
 	if len(value) >= 0 {
 		if err := snapshotLog.Deserialize(value); err != nil {
-			return nil, true, fmt.Errorf("dec.Decode failed, value is %+v. Error: %s", value, err)
+			return nil, true, fmt.Errorf("dec.Decode failed, value is %+v. Error: %w", value, err)
 		}
 	}
 
@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"math/big"
 	"path"
+	"sync"
 	"sync/atomic"
 
-	"github.com/patrickmn/go-cache"
-
 	"github.com/vitelabs/go-vite/v2/common/config"
 	leveldb "github.com/vitelabs/go-vite/v2/common/db/xleveldb"
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
@@ -30,12 +29,16 @@ type StateDB struct {
 	chainCfg *config.Chain
 
 	// contract address white list which save VM logs
+	vmLogWhiteListMu  sync.RWMutex
 	vmLogWhiteListSet map[types.Address]struct{}
 	// save all VM logs
 	vmLogAll bool
 
+	// no vm log is saved in light mode
+	vmLogNone bool
+
 	store *chain_db.Store
-	cache *cache.Cache
+	cache stateCache
 
 	log log15.Logger
 
@@ -72,7 +75,8 @@ func NewStateDBWithStore(chain Chain, chainCfg *config.Chain, store *chain_db.St
 		chain:               chain,
 		chainCfg:            chainCfg,
 		vmLogWhiteListSet:   parseVmLogWhiteList(chainCfg.VmLogWhiteList),
-		vmLogAll:            chainCfg.VmLogAll,
+		vmLogAll:            chainCfg.VmLogAll || chainCfg.Mode == config.LedgerModeArchive,
+		vmLogNone:           chainCfg.Mode == config.LedgerModeLight,
 		log:                 log15.New("module", "stateDB"),
 		store:               store,
 		useCache:            false,
@@ -100,7 +104,7 @@ func (sDB *StateDB) Init() error {
 }
 
 func (sDB *StateDB) Close() error {
-	sDB.cache.Flush()
+	sDB.cache.Purge()
 
 	sDB.cache = nil
 
@@ -193,30 +197,30 @@ func (sDB *StateDB) GetContractMeta(addr types.Address) (*ledger.ContractMeta, e
 }
 
 func (sDB *StateDB) IterateContracts(iterateFunc func(addr types.Address, meta *ledger.ContractMeta, err error) bool) {
-	items := sDB.cache.Items()
-
-	prefix := contractAddrPrefix[0]
-	for keyStr, item := range items {
+	// the cache may not hold all contracts, iterate the store
+	iter := sDB.store.NewIterator(util.BytesPrefix([]byte{chain_utils.ContractMetaKeyPrefix}))
+	defer iter.Release()
 
-		if keyStr[0] == prefix {
-			key := []byte(keyStr)
-			addr, err := types.BytesToAddress(key[2:])
-			if err != nil {
-				iterateFunc(types.Address{}, nil, err)
-				return
-			}
+	for iter.Next() {
+		addr, err := types.BytesToAddress(iter.Key()[1:])
+		if err != nil {
+			iterateFunc(types.Address{}, nil, err)
+			return
+		}
 
-			meta := &ledger.ContractMeta{}
-			if err := meta.Deserialize(item.Object.([]byte)); err != nil {
-				iterateFunc(types.Address{}, nil, err)
-				return
-			}
+		meta := &ledger.ContractMeta{}
+		if err := meta.Deserialize(iter.Value()); err != nil {
+			iterateFunc(types.Address{}, nil, err)
+			return
+		}
 
-			if !iterateFunc(addr, meta, nil) {
-				return
-			}
+		if !iterateFunc(addr, meta, nil) {
+			return
 		}
 	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		iterateFunc(types.Address{}, nil, err)
+	}
 }
 
 func (sDB *StateDB) HasContractMeta(addr types.Address) (bool, error) {
@@ -275,6 +279,23 @@ func (sDB *StateDB) GetCallDepth(sendBlockHash *types.Hash) (uint16, error) {
 	return binary.BigEndian.Uint16(value), nil
 }
 
+func (sDB *StateDB) GetExecutionError(blockHash *types.Hash) (*ledger.ExecutionError, error) {
+	value, err := sDB.store.Get(chain_utils.CreateExecutionErrorKey(*blockHash).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) <= 0 {
+		return nil, nil
+	}
+
+	executionError := &ledger.ExecutionError{}
+	if err := executionError.Deserialize(value); err != nil {
+		return nil, err
+	}
+	return executionError, nil
+}
+
 func (sDB *StateDB) GetSnapshotBalanceList(balanceMap map[types.Address]*big.Int, snapshotBlockHash types.Hash, addrList []types.Address, tokenId types.TokenTypeId) error {
 	// if consensusCacheLevel is ConsensusReadCache and tokenId is vite token id
 	if sDB.consensusCacheLevel == ConsensusReadCache &&
@@ -311,7 +332,12 @@ func (sDB *StateDB) GetSnapshotBalanceList(balanceMap map[types.Address]*big.Int
 func (sDB *StateDB) GetSnapshotValue(snapshotBlockHeight uint64, addr types.Address, key []byte) ([]byte, error) {
 
 	if sDB.useCache && sDB.shouldCacheContractData(addr) && snapshotBlockHeight == sDB.chain.GetLatestSnapshotBlock().Height {
-		return sDB.getValueInCache(append(addr.Bytes(), key...), snapshotValuePrefix)
+		if value, ok := sDB.cache.Get(snapshotValuePrefix + string(addr.Bytes()) + string(key)); ok {
+			return value, nil
+		}
+		if sDB.cache.Complete(snapshotValuePrefix) {
+			return nil, nil
+		}
 	}
 
 	startHistoryStorageKey := chain_utils.CreateHistoryStorageValueKey(&addr, key, 0)
@@ -349,22 +375,21 @@ func (sDB *StateDB) Redo() RedoInterface {
 
 func (sDB *StateDB) GetStatus() []interfaces.DBStatus {
 	statusList := sDB.store.GetStatus()
+	levelDBStatus := statusList[1]
+	levelDBStatus.Name = "stateDB.store.levelDB"
+	redoStatus := sDB.RedoStore().GetStatus()[1]
+	redoStatus.Name = "stateDB.redo.levelDB"
 	return []interfaces.DBStatus{{
 		Name:   "stateDB.cache",
-		Count:  uint64(sDB.cache.ItemCount()),
-		Size:   uint64(sDB.cache.ItemCount() * 65),
+		Count:  uint64(sDB.cache.Len()),
+		Size:   uint64(sDB.cache.Size()),
 		Status: "",
 	}, {
 		Name:   "stateDB.store.mem",
 		Count:  uint64(statusList[0].Count),
 		Size:   uint64(statusList[0].Size),
 		Status: statusList[0].Status,
-	}, {
-		Name:   "stateDB.store.levelDB",
-		Count:  uint64(statusList[1].Count),
-		Size:   uint64(statusList[1].Size),
-		Status: statusList[1].Status,
-	}}
+	}, levelDBStatus, redoStatus}
 }
 
 func (sDB *StateDB) shouldCacheContractData(addr types.Address) bool {
@@ -419,6 +444,14 @@ func (sDB *StateDB) getSnapshotBalanceList(balanceMap map[types.Address]*big.Int
 	return nil
 }
 
+// SetVmLogWhiteList replaces the contract address white list which save VM logs
+func (sDB *StateDB) SetVmLogWhiteList(list []types.Address) {
+	set := parseVmLogWhiteList(list)
+	sDB.vmLogWhiteListMu.Lock()
+	defer sDB.vmLogWhiteListMu.Unlock()
+	sDB.vmLogWhiteListSet = set
+}
+
 func parseVmLogWhiteList(list []types.Address) map[types.Address]struct{} {
 	set := make(map[types.Address]struct{}, len(list))
 	for _, item := range list {
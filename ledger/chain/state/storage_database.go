@@ -6,6 +6,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_errors "github.com/vitelabs/go-vite/v2/ledger/chain/errors"
 )
 
 func (sDB *StateDB) NewStorageDatabase(snapshotHash types.Hash, addr types.Address) (StorageDatabaseInterface, error) {
@@ -14,7 +15,7 @@ func (sDB *StateDB) NewStorageDatabase(snapshotHash types.Hash, addr types.Addre
 		return nil, err
 	}
 	if snapshotHeight <= 0 {
-		return nil, fmt.Errorf("snapshot hash %s is not existed", snapshotHash)
+		return nil, fmt.Errorf("%w: snapshot hash %s is not existed", chain_errors.ErrBlockNotFound, snapshotHash)
 	}
 
 	return NewStorageDatabase(sDB, ledger.HashHeight{
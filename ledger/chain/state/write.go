@@ -4,8 +4,6 @@ import (
 	"encoding/binary"
 	"math/big"
 
-	"github.com/patrickmn/go-cache"
-
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces"
@@ -113,6 +111,13 @@ func (sDB *StateDB) Write(block *interfaces.VmAccountBlock) error {
 
 	}
 
+	// write execution error
+	if executionError := vmDb.GetUnsavedExecutionError(); executionError != nil && accountBlock.IsReceiveBlock() {
+		executionErrorBytes := executionError.Serialize()
+		batch.Put(chain_utils.CreateExecutionErrorKey(accountBlock.Hash).Bytes(), executionErrorBytes)
+		redoLog.ExecutionError = map[types.Hash][]byte{accountBlock.Hash: executionErrorBytes}
+	}
+
 	// add storage redo log
 	redoLog.Height = accountBlock.Height
 
@@ -183,6 +188,11 @@ func (sDB *StateDB) WriteByRedo(blockHash types.Hash, addr types.Address, redoLo
 		batch.Put(chain_utils.CreateCallDepthKey(sendHash).Bytes(), callDepthBytes)
 
 	}
+
+	// write execution error
+	for receiveHash, executionErrorBytes := range redoLog.ExecutionError {
+		batch.Put(chain_utils.CreateExecutionErrorKey(receiveHash).Bytes(), executionErrorBytes)
+	}
 	sDB.store.WriteAccountBlockByHash(batch, blockHash)
 }
 
@@ -255,13 +265,13 @@ func (sDB *StateDB) InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock, con
 func (sDB *StateDB) writeContractMeta(batch interfaces.Batch, key, value []byte) {
 	batch.Put(key, value)
 
-	sDB.cache.Set(contractAddrPrefix+string(key), sDB.copyValue(value), cache.NoExpiration)
+	sDB.cache.Set(contractAddrPrefix+string(key), sDB.copyValue(value))
 }
 
 func (sDB *StateDB) writeBalance(batch interfaces.Batch, key, value []byte) {
 	batch.Put(key, value)
 
-	sDB.cache.Set(balancePrefix+string(key), sDB.copyValue(value), cache.NoExpiration)
+	sDB.cache.Set(balancePrefix+string(key), sDB.copyValue(value))
 }
 
 func (sDB *StateDB) writeHistoryKey(batch interfaces.Batch, key, value []byte) {
@@ -275,16 +285,22 @@ func (sDB *StateDB) writeHistoryKey(batch interfaces.Batch, key, value []byte) {
 		panic(err)
 	}
 	if sDB.shouldCacheContractData(addr) {
-		sDB.cache.Set(snapshotValuePrefix+string(addrBytes)+string(sDB.parseStorageKey(key)), sDB.copyValue(value), cache.NoExpiration)
+		sDB.cache.Set(snapshotValuePrefix+string(addrBytes)+string(sDB.parseStorageKey(key)), sDB.copyValue(value))
 	}
 }
 
 func (sDB *StateDB) canWriteVmLog(addr types.Address) bool {
+	if sDB.vmLogNone {
+		return false
+	}
+
 	// save all vm log when sDB.vmLogAll is true
 	if sDB.vmLogAll {
 		return true
 	}
 
+	sDB.vmLogWhiteListMu.RLock()
+	defer sDB.vmLogWhiteListMu.RUnlock()
 	_, ok := sDB.vmLogWhiteListSet[addr]
 	return ok
 }
@@ -0,0 +1,183 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_file_manager "github.com/vitelabs/go-vite/v2/ledger/chain/file_manager"
+)
+
+// The Stream* methods are the variants of the Get* methods which stream the blocks to fn in the same order instead of
+// returning them at once. The reading stops as soon as ctx is done or fn returns an error, and the error is returned,
+// so a canceled request doesn't keep reading and decoding the blocks.
+
+// StreamAccountBlocks is GetAccountBlocks streaming the blocks, high to low
+func (c *chain) StreamAccountBlocks(ctx context.Context, blockHash types.Hash, count uint64, fn func(block *ledger.AccountBlock) error) error {
+	if count <= 0 {
+		return nil
+	}
+	addr, locations, heightRange, err := c.indexDB.GetAccountBlockLocationList(&blockHash, count)
+	if err != nil {
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationList failed, hash is %s, count is %d. Error: %w",
+			blockHash, count, err)
+		c.log.Error(cErr.Error(), "method", "StreamAccountBlocks")
+		return cErr
+	}
+	if len(locations) <= 0 {
+		return nil
+	}
+	return c.streamAccountBlocks(ctx, *addr, locations, heightRange, fn)
+}
+
+// StreamAccountBlocksByHeight is GetAccountBlocksByHeight streaming the blocks, high to low
+func (c *chain) StreamAccountBlocksByHeight(ctx context.Context, addr types.Address, height uint64, count uint64, fn func(block *ledger.AccountBlock) error) error {
+	if count <= 0 {
+		return nil
+	}
+	locations, heightRange, err := c.indexDB.GetAccountBlockLocationListByHeight(addr, height, count)
+	if err != nil {
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationListByHeight failed, Addr is %s, height is %d, count is %d. Error: %w",
+			addr, height, count, err)
+		c.log.Error(cErr.Error(), "method", "StreamAccountBlocksByHeight")
+		return cErr
+	}
+	if len(locations) <= 0 {
+		return nil
+	}
+	return c.streamAccountBlocks(ctx, addr, locations, heightRange, fn)
+}
+
+// StreamAccountBlocksByRange is GetAccountBlocksByRange streaming the blocks in [start, end], high to low
+func (c *chain) StreamAccountBlocksByRange(ctx context.Context, addr types.Address, start uint64, end uint64, fn func(block *ledger.AccountBlock) error) error {
+	if start > end {
+		return nil
+	}
+	locations, heightRange, err := c.indexDB.GetAccountBlockLocationListByRange(addr, start, end)
+	if err != nil {
+		cErr := fmt.Errorf("c.indexDB.GetAccountBlockLocationListByRange failed, Addr is %s, start is %d, end is %d. Error: %w",
+			addr, start, end, err)
+		c.log.Error(cErr.Error(), "method", "StreamAccountBlocksByRange")
+		return cErr
+	}
+	if len(locations) <= 0 {
+		return nil
+	}
+	return c.streamAccountBlocks(ctx, addr, locations, heightRange, fn)
+}
+
+// StreamSnapshotBlocks is GetSnapshotBlocks streaming the blocks, low to high if higher is true
+func (c *chain) StreamSnapshotBlocks(ctx context.Context, blockHash types.Hash, higher bool, count uint64, fn func(block *ledger.SnapshotBlock) error) error {
+	return c.streamSnapshotBlockList(ctx, func() ([]*chain_file_manager.Location, [2]uint64, error) {
+		return c.indexDB.GetSnapshotBlockLocationList(&blockHash, higher, count)
+	}, higher, fn)
+}
+
+// StreamSnapshotBlocksByHeight is GetSnapshotBlocksByHeight streaming the blocks, low to high if higher is true
+func (c *chain) StreamSnapshotBlocksByHeight(ctx context.Context, height uint64, higher bool, count uint64, fn func(block *ledger.SnapshotBlock) error) error {
+	return c.streamSnapshotBlockList(ctx, func() ([]*chain_file_manager.Location, [2]uint64, error) {
+		return c.indexDB.GetSnapshotBlockLocationListByHeight(height, higher, count)
+	}, higher, fn)
+}
+
+// StreamSubLedger is GetSubLedger streaming the chunks, low to high
+func (c *chain) StreamSubLedger(ctx context.Context, startHeight, endHeight uint64, fn func(chunk *ledger.SnapshotChunk) error) error {
+	latestSb, err := c.QueryLatestSnapshotBlock()
+	if err != nil {
+		cErr := fmt.Errorf("c.QueryLatestSnapshotBlock failed. Error: %w", err)
+		c.log.Error(cErr.Error(), "method", "StreamSubLedger")
+		return cErr
+	}
+	if endHeight > latestSb.Height {
+		endHeight = latestSb.Height
+	}
+
+	startLocation := chain_file_manager.NewLocation(1, 0)
+	if startHeight > 0 {
+		if startLocation, err = c.indexDB.GetSnapshotBlockLocation(startHeight); err != nil {
+			cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %w",
+				startHeight, err)
+			c.log.Error(cErr.Error(), "method", "StreamSubLedger")
+			return cErr
+		}
+		if startLocation == nil {
+			return nil
+		}
+	}
+
+	endLocation, err := c.indexDB.GetSnapshotBlockLocation(endHeight)
+	if err != nil {
+		cErr := fmt.Errorf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %w",
+			endHeight, err)
+		c.log.Error(cErr.Error(), "method", "StreamSubLedger")
+		return cErr
+	}
+	if endLocation == nil {
+		return nil
+	}
+
+	return c.blockDB.ReadRangeContext(ctx, startLocation, endLocation, fn)
+}
+
+// streamAccountBlocks streams the blocks of the locations, it stops if a block is rolled back while reading
+func (c *chain) streamAccountBlocks(ctx context.Context, addr types.Address, locations []*chain_file_manager.Location, heightRange [2]uint64,
+	fn func(block *ledger.AccountBlock) error) error {
+	for index, height := 0, heightRange[1]; height >= heightRange[0] && height > 0; index, height = index+1, height-1 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		block := c.cache.GetAccountBlockByHeight(addr, height)
+		if block == nil {
+			if len(locations) <= index || locations[index] == nil {
+				return nil
+			}
+			var err error
+			block, err = c.blockDB.GetAccountBlock(locations[index])
+			if err != nil {
+				cErr := fmt.Errorf("c.blockDB.GetAccountBlock failed, locations is %+v. Error: %w",
+					locations[index], err)
+				c.log.Error(cErr.Error(), "method", "streamAccountBlocks")
+				return cErr
+			}
+		}
+
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *chain) streamSnapshotBlockList(ctx context.Context, getList getSnapshotListFunc, higher bool, fn func(block *ledger.SnapshotBlock) error) error {
+	locations, heightRange, err := getList()
+	if err != nil {
+		return err
+	}
+
+	// the locations are high to low
+	endHeight := heightRange[0]
+	for i := range locations {
+		index := i
+		if higher {
+			index = len(locations) - 1 - i
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		block := c.cache.GetSnapshotBlockByHeight(endHeight - uint64(index))
+		if block == nil {
+			if block, err = c.blockDB.GetSnapshotBlock(locations[index]); err != nil {
+				return err
+			}
+		}
+
+		if err := fn(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package sync_cache
 
 import (
 	"encoding/binary"
+	"fmt"
 
 	"github.com/golang/protobuf/proto"
 
@@ -12,6 +13,7 @@ import (
 )
 
 var dbItemPrefix = []byte("c:")
+var dbProgressPrefix = []byte("p:")
 
 type cacheItem struct {
 	interfaces.Segment
@@ -19,18 +21,47 @@ type cacheItem struct {
 	verified bool
 	filename string
 	size     int64
+
+	// progress of the chunk not written done, stored with dbProgressPrefix
+	written int64  // the bytes synced to file
+	source  string // the chunk is downloaded from, only resumed from the same source
+	writing bool   // a writer is open
 }
 
 func (c *cacheItem) dbKey() (key []byte) {
+	return c.makeKey(dbItemPrefix)
+}
+
+func (c *cacheItem) progressKey() (key []byte) {
+	return c.makeKey(dbProgressPrefix)
+}
+
+func (c *cacheItem) makeKey(prefix []byte) (key []byte) {
 	key = make([]byte, 18)
 
-	copy(key, dbItemPrefix)
+	copy(key, prefix)
 	binary.BigEndian.PutUint64(key[2:], c.From)
 	binary.BigEndian.PutUint64(key[10:], c.To)
 
 	return key
 }
 
+func (c *cacheItem) serializeProgress() []byte {
+	buf := make([]byte, 8+len(c.source))
+	binary.BigEndian.PutUint64(buf, uint64(c.written))
+	copy(buf[8:], c.source)
+	return buf
+}
+
+func (c *cacheItem) deserializeProgress(data []byte) error {
+	if len(data) < 8 {
+		return fmt.Errorf("invalid progress length %d", len(data))
+	}
+	c.written = int64(binary.BigEndian.Uint64(data))
+	c.source = string(data[8:])
+	return nil
+}
+
 func (c *cacheItem) Serialize() ([]byte, error) {
 	pb := &vitepb.CacheItem{
 		From:     c.From,
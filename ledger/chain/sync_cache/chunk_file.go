@@ -0,0 +1,72 @@
+package sync_cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// a chunk file is [header][data], the header is reserved when the file is created,
+// and filled in when the chunk is written done:
+// magic(4) | data size(8) | blake2b-256 hash of data(32)
+const chunkHeaderSize = 4 + 8 + types.HashSize
+
+var chunkMagic = []byte("vsc1")
+
+type chunkHeader struct {
+	size int64
+	hash types.Hash
+}
+
+func (h *chunkHeader) serialize() []byte {
+	buf := make([]byte, chunkHeaderSize)
+	copy(buf, chunkMagic)
+	binary.BigEndian.PutUint64(buf[4:12], uint64(h.size))
+	copy(buf[12:], h.hash.Bytes())
+	return buf
+}
+
+func (h *chunkHeader) deserialize(buf []byte) (err error) {
+	if len(buf) != chunkHeaderSize || !bytes.Equal(buf[:4], chunkMagic) {
+		return fmt.Errorf("invalid chunk header")
+	}
+	h.size = int64(binary.BigEndian.Uint64(buf[4:12]))
+	h.hash, err = types.BytesToHash(buf[12:])
+	return
+}
+
+// verifyChunkFile checks the data of the chunk file is the same as the header, the truncated and broken files are rejected
+func verifyChunkFile(file *os.File) error {
+	st, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkHeaderSize)
+	if _, err = file.ReadAt(buf, 0); err != nil {
+		return fmt.Errorf("failed to read chunk header: %v", err)
+	}
+	header := &chunkHeader{}
+	if err = header.deserialize(buf); err != nil {
+		return err
+	}
+	if size := st.Size() - chunkHeaderSize; size != header.size {
+		return fmt.Errorf("chunk data is %d bytes, expected %d bytes", size, header.size)
+	}
+
+	hasher, _ := blake2b.New256(nil)
+	if _, err = io.Copy(hasher, io.NewSectionReader(file, chunkHeaderSize, header.size)); err != nil {
+		return fmt.Errorf("failed to read chunk data: %v", err)
+	}
+	if !bytes.Equal(hasher.Sum(nil), header.hash.Bytes()) {
+		return fmt.Errorf("chunk data hash is %x, expected %s", hasher.Sum(nil), header.hash)
+	}
+
+	return nil
+}
@@ -35,6 +35,15 @@ func NewReader(cache *syncCache, item *cacheItem) (*Reader, error) {
 		return nil, fmt.Errorf("failed to open cache %d-%d %s-%s: %v", item.From, item.To, item.PrevHash, item.Hash, err)
 	}
 
+	// the chunk is checked before read, the broken chunk should be deleted and downloaded again
+	if err = verifyChunkFile(file); err == nil {
+		_, err = file.Seek(chunkHeaderSize, io.SeekStart)
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("failed to verify cache %d-%d %s-%s: %v", item.From, item.To, item.PrevHash, item.Hash, err)
+	}
+
 	r := &Reader{
 		cache:      cache,
 		file:       file,
@@ -70,13 +79,16 @@ func (reader *Reader) Read() (ab *ledger.AccountBlock, sb *ledger.SnapshotBlock,
 	}
 
 	size := binary.BigEndian.Uint32(buf)
-	if cap(reader.readBuffer) < int(size) {
-		reader.readBuffer = make([]byte, size)
-	}
-
 	if size == 0 {
 		return nil, nil, errors.New("0 size")
 	}
+	if size > chain_block.MaxUnitSize {
+		return nil, nil, fmt.Errorf("size %d exceeds %d", size, chain_block.MaxUnitSize)
+	}
+
+	if cap(reader.readBuffer) < int(size) {
+		reader.readBuffer = make([]byte, size)
+	}
 
 	buf = reader.readBuffer[:size]
 	if _, err = fd.Read(buf); err != nil {
@@ -95,27 +107,12 @@ func (reader *Reader) Read() (ab *ledger.AccountBlock, sb *ledger.SnapshotBlock,
 	if err != nil {
 		return
 	}
-	if cap(reader.decodeBuffer) < decodeLen {
+	if decodeLen <= chain_block.MaxUnitSize && cap(reader.decodeBuffer) < decodeLen {
 		reader.decodeBuffer = make([]byte, decodeLen)
 	}
 
-	sBuf, err := snappy.Decode(reader.decodeBuffer, buf[1:])
-	if err != nil {
-		return
-	}
-
-	switch code {
-	case chain_block.BlockTypeAccountBlock:
-		ab = &ledger.AccountBlock{}
-		if err = ab.Deserialize(sBuf); err != nil {
-			return
-		}
-		return
-	case chain_block.BlockTypeSnapshotBlock:
-		sb = &ledger.SnapshotBlock{}
-		if err = sb.Deserialize(sBuf); err != nil {
-			return
-		}
+	sb, ab, err = chain_block.DecodeUnit(reader.decodeBuffer, code, buf[1:])
+	if err != nil || sb != nil || ab != nil {
 		return
 	}
 
@@ -92,13 +92,20 @@ func (cache *syncCache) loadCaches() (err error) {
 
 	var broken bool
 	for i, item := range cache.caches {
-		if _, err = os.Stat(item.filename); err != nil || false == item.done {
+		if item.done {
+			_, err = os.Stat(item.filename)
+		} else {
+			// the chunk not written done is kept if it can be resumed
+			err = cache.loadProgress(item)
+		}
+
+		if err != nil {
 			broken = true
 			cache.log.Warn(fmt.Sprintf("failed to read cache file %s info: %v", item.filename, err))
 			cache.caches[i] = nil
 			cache.cleanItem(item)
 		} else {
-			keepFiles[item.filename] = struct{}{}
+			keepFiles[path.Base(item.filename)] = struct{}{}
 		}
 	}
 
@@ -197,12 +204,70 @@ func (cache *syncCache) NewReader(segment interfaces.Segment) (interfaces.ChunkR
 
 // NewWriter will add a temp chunk to chunk list, and create a file bind to the temp chunk, return the file to write.
 // the temp chunk will not add to index db until writer is done.
-func (cache *syncCache) NewWriter(segment interfaces.Segment, size int64) (w io.WriteCloser, err error) {
+func (cache *syncCache) NewWriter(segment interfaces.Segment, size int64) (w interfaces.ChunkWriter, err error) {
+	return cache.newWriter(segment, size, "")
+}
+
+// ResumeWriter returns the writer to continue the chunk suspended at offset, offset 0 means writing the chunk from scratch.
+// The chunk is resumed only if it is downloaded from the same source and has the same size.
+func (cache *syncCache) ResumeWriter(segment interfaces.Segment, size int64, offset int64, source string) (w interfaces.ChunkWriter, err error) {
+	if offset == 0 {
+		return cache.newWriter(segment, size, source)
+	}
+
+	cache.mu.Lock()
+	item := cache.findSuspended(segment)
+	if item == nil || item.size != size || item.source != source || item.written < offset {
+		cache.mu.Unlock()
+		return nil, fmt.Errorf("failed to resume cache %d-%d at %d: not suspended", segment.From, segment.To, offset)
+	}
+	item.writing = true
+	cache.mu.Unlock()
+
+	file, err := os.OpenFile(item.filename, os.O_RDWR, 0666)
+	if err == nil {
+		if err = file.Truncate(chunkHeaderSize + offset); err == nil {
+			_, err = file.Seek(0, io.SeekEnd)
+		}
+	}
+	if err != nil {
+		cache.deleteItem(item)
+		return nil, fmt.Errorf("failed to resume cache file %s: %v", item.filename, err)
+	}
+
+	wr := newWriter(cache, item, file)
+	// the hash of the chunk covers the data written before
+	if _, err = io.Copy(wr.hasher, io.NewSectionReader(file, chunkHeaderSize, offset)); err != nil {
+		_ = file.Close()
+		cache.deleteItem(item)
+		return nil, fmt.Errorf("failed to read cache file %s: %v", item.filename, err)
+	}
+	wr.written, wr.checkpoint = offset, offset
+
+	return wr, nil
+}
+
+// Progress returns the bytes of the suspended chunk downloaded from source, 0 if the chunk can't be resumed
+func (cache *syncCache) Progress(segment interfaces.Segment, source string) int64 {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+
+	if item := cache.findSuspended(segment); item != nil && item.source == source {
+		return item.written
+	}
+	return 0
+}
+
+func (cache *syncCache) newWriter(segment interfaces.Segment, size int64, source string) (w interfaces.ChunkWriter, err error) {
 	cache.mu.Lock()
 
+	// the suspended chunks overlapped will not be resumed
+	stale := cache.removeSuspended(segment)
+
 	index, ok := cache.checkOverlap(segment)
 	if ok {
 		cache.mu.Unlock()
+		cache.cleanItems(stale)
 		return nil, fmt.Errorf("failed to cache %d-%d: overlapped", segment.From, segment.To)
 	}
 
@@ -212,6 +277,8 @@ func (cache *syncCache) NewWriter(segment interfaces.Segment, size int64) (w io.
 		verified: false,
 		filename: "",
 		size:     size,
+		source:   source,
+		writing:  true,
 	}
 
 	cache.caches = append(cache.caches, nil)
@@ -219,6 +286,7 @@ func (cache *syncCache) NewWriter(segment interfaces.Segment, size int64) (w io.
 	cache.caches[index] = item
 
 	cache.mu.Unlock()
+	cache.cleanItems(stale)
 
 	file, err := cache.createNewFile(item)
 	if err != nil {
@@ -228,15 +296,89 @@ func (cache *syncCache) NewWriter(segment interfaces.Segment, size int64) (w io.
 
 	item.filename = file.Name()
 
-	w = &writer{
-		cache: cache,
-		item:  item,
-		fd:    file,
+	// reserve the header, which is written when done
+	if _, err = file.Write(make([]byte, chunkHeaderSize)); err != nil {
+		_ = file.Close()
+		cache.deleteItem(item)
+		return nil, fmt.Errorf("failed to write cache file %s: %v", item.filename, err)
 	}
 
+	w = newWriter(cache, item, file)
+
 	return
 }
 
+// findSuspended returns the chunk not written done and not being written, should be called with cache.mu
+func (cache *syncCache) findSuspended(segment interfaces.Segment) *cacheItem {
+	for _, item := range cache.caches {
+		if !item.done && !item.writing && item.Equal(segment) {
+			return item
+		}
+	}
+	return nil
+}
+
+// removeSuspended removes the suspended chunks overlapped with the segment from the chunk list, should be called with cache.mu
+func (cache *syncCache) removeSuspended(segment interfaces.Segment) (removed []*cacheItem) {
+	var j int
+	for _, item := range cache.caches {
+		if !item.done && !item.writing && item.From <= segment.To && item.To >= segment.From {
+			removed = append(removed, item)
+		} else {
+			cache.caches[j] = item
+			j++
+		}
+	}
+	cache.caches = cache.caches[:j]
+	return
+}
+
+func (cache *syncCache) cleanItems(items []*cacheItem) {
+	for _, item := range items {
+		cache.cleanItem(item)
+	}
+}
+
+// saveProgress stores the chunk not written done and its progress, so it can be resumed after restart
+func (cache *syncCache) saveProgress(item *cacheItem) (err error) {
+	if err = cache.updateIndex(item); err != nil {
+		return
+	}
+
+	err = cache.indexDB.Put(item.progressKey(), item.serializeProgress(), nil)
+	if err != nil {
+		cache.log.Warn(fmt.Sprintf("failed to store progress of item %d-%d: %v", item.From, item.To, err))
+	}
+	return
+}
+
+func (cache *syncCache) deleteProgress(item *cacheItem) {
+	if err := cache.indexDB.Delete(item.progressKey(), nil); err != nil {
+		cache.log.Warn(fmt.Sprintf("failed to delete progress of item %d-%d: %v", item.From, item.To, err))
+	}
+}
+
+// loadProgress reads the progress of the chunk not written done, the data after the progress is dropped
+func (cache *syncCache) loadProgress(item *cacheItem) error {
+	data, err := cache.indexDB.Get(item.progressKey(), nil)
+	if err != nil {
+		return fmt.Errorf("no progress: %v", err)
+	}
+	if err = item.deserializeProgress(data); err != nil {
+		return err
+	}
+
+	st, err := os.Stat(item.filename)
+	if err != nil {
+		return err
+	}
+	if st.Size() < chunkHeaderSize+item.written {
+		return fmt.Errorf("file is %d bytes, progress is %d bytes", st.Size(), item.written)
+	}
+
+	return os.Truncate(item.filename, chunkHeaderSize+item.written)
+}
+
 func (cache *syncCache) createNewFile(item *cacheItem) (file *os.File, err error) {
 	filename := cache.toCacheFileName(item.Segment)
 	file, err = os.Create(filename)
@@ -310,6 +452,7 @@ func (cache *syncCache) cleanItem(item *cacheItem) {
 	if err != nil {
 		cache.log.Warn(fmt.Sprintf("failed to delete item %d-%d from db: %v", item.From, item.To, err))
 	}
+	cache.deleteProgress(item)
 
 	if item.filename != "" {
 		err = os.Remove(item.filename)
@@ -404,3 +404,138 @@ func TestSyncCache_NewWriter(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSyncCache_Resume(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Dir(os.Args[0]))
+	if err != nil {
+		panic(err)
+	}
+
+	dir = path.Join(dir, "sync_cache")
+	err = os.RemoveAll(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	cache, err := NewSyncCache(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	seg := interfaces.Segment{
+		From:     1,
+		To:       100,
+		Hash:     types.Hash{1},
+		PrevHash: types.Hash{100},
+	}
+	w, err := cache.ResumeWriter(seg, 10, 0, "peer1")
+	if err != nil {
+		panic(err)
+	}
+	if _, err = w.Write([]byte("hello")); err != nil {
+		panic(err)
+	}
+	if err = w.Suspend(); err != nil {
+		panic(err)
+	}
+
+	// resume after restart
+	if err = cache.Close(); err != nil {
+		panic(err)
+	}
+	cache, err = NewSyncCache(dir)
+	if err != nil {
+		panic(err)
+	}
+	if len(cache.Chunks()) != 0 {
+		t.Error("suspended chunk should not in chunk list")
+	}
+	if n := cache.Progress(seg, "peer2"); n != 0 {
+		t.Errorf("should not resume from other source: %d", n)
+	}
+	offset := cache.Progress(seg, "peer1")
+	if offset != 5 {
+		t.Fatalf("progress should be 5: %d", offset)
+	}
+
+	w, err = cache.ResumeWriter(seg, 10, offset, "peer1")
+	if err != nil {
+		panic(err)
+	}
+	if _, err = w.Write([]byte("world")); err != nil {
+		panic(err)
+	}
+	if err = w.Close(); err != nil {
+		panic(err)
+	}
+
+	reader, err := cache.NewReader(seg)
+	if err != nil {
+		panic(err)
+	}
+	data := make([]byte, 20)
+	n, _ := reader.(*Reader).file.Read(data)
+	if string(data[:n]) != "helloworld" {
+		t.Errorf("unexpected data: %s", data[:n])
+	}
+	_ = reader.Close()
+
+	// the suspended chunk overlapped is dropped
+	seg2 := interfaces.Segment{From: 101, To: 200, Hash: types.Hash{2}, PrevHash: types.Hash{1}}
+	w, err = cache.NewWriter(seg2, 10)
+	if err != nil {
+		panic(err)
+	}
+	if err = w.Suspend(); err != nil {
+		panic(err)
+	}
+	if _, err = cache.NewWriter(interfaces.Segment{From: 101, To: 150}, 10); err != nil {
+		t.Errorf("suspended chunk should be dropped: %v", err)
+	}
+	if _, err = cache.ResumeWriter(seg2, 10, 0, ""); err == nil {
+		t.Error("should be overlapped")
+	}
+}
+
+func TestSyncCache_VerifyChunk(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Dir(os.Args[0]))
+	if err != nil {
+		panic(err)
+	}
+
+	dir = path.Join(dir, "sync_cache")
+	err = os.RemoveAll(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	cache, err := NewSyncCache(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	seg := interfaces.Segment{
+		From:     1,
+		To:       100,
+		Hash:     types.Hash{1},
+		PrevHash: types.Hash{100},
+	}
+	w, err := cache.NewWriter(seg, 10)
+	if err != nil {
+		panic(err)
+	}
+	if _, err = w.Write([]byte("helloworld")); err != nil {
+		panic(err)
+	}
+	if err = w.Close(); err != nil {
+		panic(err)
+	}
+
+	item, _ := cache.findSeg(seg)
+	if err = os.Truncate(item.filename, chunkHeaderSize+5); err != nil {
+		panic(err)
+	}
+	if _, err = cache.NewReader(seg); err == nil {
+		t.Error("truncated chunk should not be read")
+	}
+}
@@ -1,31 +1,102 @@
 package sync_cache
 
 import (
+	"fmt"
+	"hash"
 	"os"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
 )
 
+// the written data is synced and the progress is stored every checkpointSize bytes,
+// so the chunk can be resumed from the last checkpoint after restart
+const checkpointSize = 4 * 1024 * 1024
+
 type writer struct {
-	cache *syncCache
-	item  *cacheItem
-	fd    *os.File
+	cache  *syncCache
+	item   *cacheItem
+	fd     *os.File
+	hasher hash.Hash
+
+	written    int64
+	checkpoint int64
+}
+
+func newWriter(cache *syncCache, item *cacheItem, fd *os.File) *writer {
+	hasher, _ := blake2b.New256(nil)
+	return &writer{
+		cache:  cache,
+		item:   item,
+		fd:     fd,
+		hasher: hasher,
+	}
 }
 
 func (w *writer) Write(p []byte) (n int, err error) {
-	return w.fd.Write(p)
+	n, err = w.fd.Write(p)
+	w.hasher.Write(p[:n])
+	w.written += int64(n)
+
+	if err == nil && w.written-w.checkpoint >= checkpointSize {
+		err = w.saveProgress()
+	}
+	return
+}
+
+// Suspend closes the file and keeps the written data, the chunk can be resumed by ResumeWriter
+func (w *writer) Suspend() (err error) {
+	if err = w.saveProgress(); err != nil {
+		_ = w.fd.Close()
+		w.cache.deleteItem(w.item)
+		return
+	}
+
+	w.cache.mu.Lock()
+	w.item.writing = false
+	w.cache.mu.Unlock()
+
+	return w.fd.Close()
 }
 
 func (w *writer) Close() (err error) {
+	header := &chunkHeader{
+		size: w.written,
+	}
+	header.hash, _ = types.BytesToHash(w.hasher.Sum(nil))
+
+	if _, err = w.fd.WriteAt(header.serialize(), 0); err == nil {
+		err = w.fd.Sync()
+	}
+
 	// close file
-	err = w.fd.Close()
+	if cErr := w.fd.Close(); err == nil {
+		err = cErr
+	}
 	if err != nil {
 		w.cache.deleteItem(w.item)
 		return
 	}
 
+	w.cache.mu.Lock()
 	w.item.done = true
+	w.item.writing = false
+	w.cache.mu.Unlock()
 
 	// add item to index db
 	err = w.cache.updateIndex(w.item)
+	w.cache.deleteProgress(w.item)
 
 	return
 }
+
+func (w *writer) saveProgress() error {
+	if err := w.fd.Sync(); err != nil {
+		return fmt.Errorf("failed to sync cache file %s: %v", w.item.filename, err)
+	}
+
+	w.checkpoint = w.written
+	w.item.written = w.written
+	return w.cache.saveProgress(w.item)
+}
@@ -50,7 +50,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if tmpFromLocation == nil {
-		return nil, fmt.Errorf("from location %d is not existed", tmpFromLocation)
+		return nil, fmt.Errorf("%w: from location %d is not existed", ErrLocationOutOfRange, tmpFromLocation)
 	}
 
 	fromLocation, err := chain.blockDB.GetNextLocation(tmpFromLocation)
@@ -58,7 +58,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if fromLocation == nil {
-		return nil, fmt.Errorf("block %d is not existed", from)
+		return nil, fmt.Errorf("%w: block %d is not existed", ErrBlockNotFound, from)
 	}
 
 	tmpToLocation, err := chain.indexDB.GetSnapshotBlockLocation(to)
@@ -66,7 +66,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if tmpToLocation == nil {
-		return nil, fmt.Errorf("block %d is not existed", to)
+		return nil, fmt.Errorf("%w: block %d is not existed", ErrBlockNotFound, to)
 	}
 
 	toLocation, err := chain.blockDB.GetNextLocation(tmpToLocation)
@@ -74,7 +74,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if toLocation == nil {
-		return nil, fmt.Errorf("next location %d is not existed", toLocation)
+		return nil, fmt.Errorf("%w: next location %d is not existed", ErrLocationOutOfRange, toLocation)
 	}
 
 	fromPrevSnapshotBlock, err := chain.GetSnapshotHeaderByHeight(from - 1)
@@ -82,7 +82,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if fromPrevSnapshotBlock == nil {
-		return nil, fmt.Errorf("fromPrevSnapshotBlock is nil, from is %d", from)
+		return nil, fmt.Errorf("%w: fromPrevSnapshotBlock is nil, from is %d", ErrBlockNotFound, from)
 	}
 
 	toSnapshotBlock, err := chain.GetSnapshotHeaderByHeight(to)
@@ -90,7 +90,7 @@ func newLedgerReader(chain *chain, from, to uint64) (interfaces.LedgerReader, er
 		return nil, err
 	}
 	if fromPrevSnapshotBlock == nil {
-		return nil, fmt.Errorf("toSnapshotBlock is nil, to is %d", to)
+		return nil, fmt.Errorf("%w: toSnapshotBlock is nil, to is %d", ErrBlockNotFound, to)
 	}
 
 	return &ledgerReader{
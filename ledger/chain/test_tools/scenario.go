@@ -0,0 +1,215 @@
+package test_tools
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+// OpType is the type of an operation of a scenario
+type OpType uint8
+
+const (
+	OpTransfer OpType = iota + 1 // send from an account to another account
+	OpDeploy                     // send from an account to create a contract
+	OpCall                       // send from an account to call a contract
+	OpReceive                    // an account receives an onroad send of a transfer
+	OpSnapshot                   // snapshot all the unconfirmed account blocks
+	OpReorg                      // roll back the latest snapshot blocks and the account blocks after them
+)
+
+func (t OpType) String() string {
+	switch t {
+	case OpTransfer:
+		return "transfer"
+	case OpDeploy:
+		return "deploy"
+	case OpCall:
+		return "call"
+	case OpReceive:
+		return "receive"
+	case OpSnapshot:
+		return "snapshot"
+	case OpReorg:
+		return "reorg"
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(t))
+}
+
+// Op is an operation of a scenario. The accounts and the contracts are referred by indexes, the contract index is
+// the order of the deploy op in the ops not rolled back.
+type Op struct {
+	Seq  int
+	Type OpType
+
+	From    int      // account of a send or a receive
+	To      int      // account of a transfer, contract of a call
+	Amount  *big.Int // amount of a transfer or a call
+	Data    []byte   // data of a call
+	SendSeq int      // seq of the transfer received by a receive
+
+	Depth int // snapshot blocks rolled back by a reorg
+}
+
+func (op *Op) String() string {
+	switch op.Type {
+	case OpTransfer:
+		return fmt.Sprintf("%d %s %d -> %d %s", op.Seq, op.Type, op.From, op.To, op.Amount)
+	case OpDeploy:
+		return fmt.Sprintf("%d %s %d", op.Seq, op.Type, op.From)
+	case OpCall:
+		return fmt.Sprintf("%d %s %d -> contract %d %s", op.Seq, op.Type, op.From, op.To, op.Amount)
+	case OpReceive:
+		return fmt.Sprintf("%d %s %d <- %d", op.Seq, op.Type, op.From, op.SendSeq)
+	case OpReorg:
+		return fmt.Sprintf("%d %s %d", op.Seq, op.Type, op.Depth)
+	}
+	return fmt.Sprintf("%d %s", op.Seq, op.Type)
+}
+
+// ScenarioConfig is the configuration of a workload. The same config always generates the same ops.
+type ScenarioConfig struct {
+	Seed     int64
+	Accounts int // count of the accounts
+	Steps    int // count of the account block ops
+
+	ContractRatio int // percent of the sends to the contracts
+	DeployRatio   int // percent of the contract sends deploying a new contract
+	ReceiveRatio  int // percent of the steps receiving an onroad transfer if the account has one
+
+	SnapshotInterval int // account block ops between two snapshots, no snapshot if it is 0
+	ReorgRatio       int // percent of the snapshots followed by a reorg
+	MaxReorgDepth    int // max snapshot blocks rolled back by a reorg
+}
+
+func (cfg *ScenarioConfig) check() error {
+	if cfg.Accounts <= 0 {
+		return errors.New("accounts should be positive")
+	}
+	if cfg.Steps < 0 || cfg.SnapshotInterval < 0 || cfg.MaxReorgDepth < 0 {
+		return errors.New("steps, snapshot interval and max reorg depth should not be negative")
+	}
+	for _, ratio := range []int{cfg.ContractRatio, cfg.DeployRatio, cfg.ReceiveRatio, cfg.ReorgRatio} {
+		if ratio < 0 || ratio > 100 {
+			return fmt.Errorf("ratio %d is not in [0, 100]", ratio)
+		}
+	}
+	if cfg.ReorgRatio > 0 && (cfg.SnapshotInterval == 0 || cfg.MaxReorgDepth == 0) {
+		return errors.New("reorg needs snapshot interval and max reorg depth")
+	}
+	return nil
+}
+
+// Scenario is a workload generated deterministically from the seed. The appliers should snapshot all the unconfirmed
+// account blocks on OpSnapshot, then a reorg of depth n rolls back to the n-th latest snapshot, and the ops after it
+// are not referred by the later ops.
+type Scenario struct {
+	Config ScenarioConfig
+	Ops    []*Op
+}
+
+// scenarioState is the view of the generator, it is saved at every snapshot to be recovered by a reorg
+type scenarioState struct {
+	onroad    [][]int // seqs of the unreceived transfers of every account
+	contracts int
+}
+
+func (s *scenarioState) copy() *scenarioState {
+	c := &scenarioState{
+		onroad:    make([][]int, len(s.onroad)),
+		contracts: s.contracts,
+	}
+	for i, seqs := range s.onroad {
+		c.onroad[i] = append([]int(nil), seqs...)
+	}
+	return c
+}
+
+func NewScenario(cfg ScenarioConfig) (*Scenario, error) {
+	if err := cfg.check(); err != nil {
+		return nil, err
+	}
+
+	r := rand.New(rand.NewSource(cfg.Seed))
+	s := &Scenario{Config: cfg}
+	state := &scenarioState{onroad: make([][]int, cfg.Accounts)}
+	// the states at the snapshots, the first one is the initial state
+	checkpoints := []*scenarioState{state.copy()}
+
+	add := func(op *Op) {
+		op.Seq = len(s.Ops)
+		s.Ops = append(s.Ops, op)
+	}
+
+	for step := 1; step <= cfg.Steps; step++ {
+		from := r.Intn(cfg.Accounts)
+		switch {
+		case len(state.onroad[from]) > 0 && r.Intn(100) < cfg.ReceiveRatio:
+			add(&Op{Type: OpReceive, From: from, SendSeq: state.onroad[from][0]})
+			state.onroad[from] = state.onroad[from][1:]
+
+		case r.Intn(100) < cfg.ContractRatio:
+			if state.contracts == 0 || r.Intn(100) < cfg.DeployRatio {
+				add(&Op{Type: OpDeploy, From: from})
+				state.contracts++
+				break
+			}
+			data := make([]byte, 4)
+			binary.BigEndian.PutUint32(data, r.Uint32())
+			add(&Op{Type: OpCall, From: from, To: r.Intn(state.contracts), Amount: randomAmount(r), Data: data})
+
+		default:
+			to := r.Intn(cfg.Accounts)
+			add(&Op{Type: OpTransfer, From: from, To: to, Amount: randomAmount(r)})
+			state.onroad[to] = append(state.onroad[to], len(s.Ops)-1)
+		}
+
+		if cfg.SnapshotInterval == 0 || step%cfg.SnapshotInterval != 0 {
+			continue
+		}
+		add(&Op{Type: OpSnapshot})
+		checkpoints = append(checkpoints, state.copy())
+
+		if r.Intn(100) >= cfg.ReorgRatio {
+			continue
+		}
+		depth := 1 + r.Intn(cfg.MaxReorgDepth)
+		if depth > len(checkpoints)-1 {
+			depth = len(checkpoints) - 1
+		}
+		add(&Op{Type: OpReorg, Depth: depth})
+		checkpoints = checkpoints[:len(checkpoints)-depth]
+		state = checkpoints[len(checkpoints)-1].copy()
+	}
+	return s, nil
+}
+
+// Count returns the count of the ops of the type
+func (s *Scenario) Count(t OpType) int {
+	count := 0
+	for _, op := range s.Ops {
+		if op.Type == t {
+			count++
+		}
+	}
+	return count
+}
+
+// Account returns the address and the private key of the i-th account, the keys are derived from the seed
+func (s *Scenario) Account(i int) (types.Address, ed25519.PrivateKey, error) {
+	buf := make([]byte, 16)
+	binary.BigEndian.PutUint64(buf[:8], uint64(s.Config.Seed))
+	binary.BigEndian.PutUint64(buf[8:], uint64(i))
+	return types.CreateAddressWithDeterministic([32]byte(types.DataHash(buf)))
+}
+
+// randomAmount returns an amount in [1e15, 1e17]
+func randomAmount(r *rand.Rand) *big.Int {
+	amount := big.NewInt(int64(1 + r.Intn(100)))
+	return amount.Mul(amount, big.NewInt(1e15))
+}
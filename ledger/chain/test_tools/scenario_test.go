@@ -0,0 +1,116 @@
+package test_tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testScenarioConfig = ScenarioConfig{
+	Seed:             7,
+	Accounts:         10,
+	Steps:            2000,
+	ContractRatio:    20,
+	DeployRatio:      10,
+	ReceiveRatio:     60,
+	SnapshotInterval: 10,
+	ReorgRatio:       10,
+	MaxReorgDepth:    3,
+}
+
+func TestNewScenario(t *testing.T) {
+	s1, err := NewScenario(testScenarioConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s2, _ := NewScenario(testScenarioConfig)
+	if !reflect.DeepEqual(s1.Ops, s2.Ops) {
+		t.Fatal("the ops of the same seed are different")
+	}
+
+	cfg := testScenarioConfig
+	cfg.Seed++
+	s3, _ := NewScenario(cfg)
+	if reflect.DeepEqual(s1.Ops, s3.Ops) {
+		t.Fatal("the ops of the different seeds are the same")
+	}
+
+	for _, opType := range []OpType{OpTransfer, OpDeploy, OpCall, OpReceive, OpSnapshot, OpReorg} {
+		if s1.Count(opType) == 0 {
+			t.Fatalf("no %s op is generated", opType)
+		}
+	}
+	if s1.Count(OpSnapshot) != cfg.Steps/cfg.SnapshotInterval {
+		t.Fatalf("%d snapshots, %d expected", s1.Count(OpSnapshot), cfg.Steps/cfg.SnapshotInterval)
+	}
+
+	addr1, _, _ := s1.Account(1)
+	addr2, _, _ := s2.Account(1)
+	addr3, _, _ := s3.Account(1)
+	if addr1 != addr2 || addr1 == addr3 {
+		t.Fatal("the accounts are not derived from the seed")
+	}
+
+	checkScenario(t, s1)
+}
+
+// checkScenario replays the ops and checks the receives and the calls only refer to the ops not rolled back
+func checkScenario(t *testing.T, s *Scenario) {
+	type replayed struct {
+		received  map[int]bool
+		transfers map[int]*Op
+		contracts int
+	}
+	copyReplayed := func(r *replayed) *replayed {
+		c := &replayed{received: make(map[int]bool), transfers: make(map[int]*Op), contracts: r.contracts}
+		for k, v := range r.received {
+			c.received[k] = v
+		}
+		for k, v := range r.transfers {
+			c.transfers[k] = v
+		}
+		return c
+	}
+
+	current := &replayed{received: make(map[int]bool), transfers: make(map[int]*Op)}
+	snapshots := []*replayed{copyReplayed(current)}
+	for _, op := range s.Ops {
+		switch op.Type {
+		case OpTransfer:
+			current.transfers[op.Seq] = op
+		case OpDeploy:
+			current.contracts++
+		case OpCall:
+			if op.To >= current.contracts {
+				t.Fatalf("%s calls an absent contract", op)
+			}
+		case OpReceive:
+			send, ok := current.transfers[op.SendSeq]
+			if !ok || send.To != op.From || current.received[op.SendSeq] {
+				t.Fatalf("%s receives an invalid send", op)
+			}
+			current.received[op.SendSeq] = true
+		case OpSnapshot:
+			snapshots = append(snapshots, copyReplayed(current))
+		case OpReorg:
+			if op.Depth <= 0 || op.Depth >= len(snapshots) {
+				t.Fatalf("%s rolls back %d snapshots", op, len(snapshots)-1)
+			}
+			snapshots = snapshots[:len(snapshots)-op.Depth]
+			current = copyReplayed(snapshots[len(snapshots)-1])
+		}
+	}
+}
+
+func TestScenarioConfig_check(t *testing.T) {
+	cfg := testScenarioConfig
+	cfg.SnapshotInterval = 0
+	if _, err := NewScenario(cfg); err == nil {
+		t.Fatal("reorg without snapshots should fail")
+	}
+
+	cfg = testScenarioConfig
+	cfg.ReceiveRatio = 101
+	if _, err := NewScenario(cfg); err == nil {
+		t.Fatal("ratio greater than 100 should fail")
+	}
+}
@@ -138,7 +138,7 @@ func (c *chain) filterUnconfirmedBlocks(snapshotBlock *ledger.SnapshotBlock, che
 				c.log.Error(fmt.Sprintf("quota.CalcBlockQuotaUsed failed when filterUnconfirmedBlocks. Error: %s", err), "method", "filterInvalidUnconfirmedBlocks")
 				valid = false
 			} else if enough, err := c.checkQuota(quotaUnusedCache, quotaUsedCache, block, snapshotBlock.Height); err != nil {
-				cErr := fmt.Errorf("c.checkQuota failed, block is %+v. Error: %s", block, err)
+				cErr := fmt.Errorf("c.checkQuota failed, block is %+v. Error: %w", block, err)
 				c.log.Error(cErr.Error(), "method", "filterInvalidUnconfirmedBlocks")
 				valid = false
 			} else if !enough {
@@ -0,0 +1,131 @@
+package chain_utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+type keyField struct {
+	name   string
+	size   int
+	format func(b []byte) string
+}
+
+type keySchema struct {
+	name   string
+	fields []keyField
+}
+
+var (
+	addressField = keyField{"address", types.AddressSize, func(b []byte) string {
+		addr, _ := types.BytesToAddress(b)
+		return addr.String()
+	}}
+	hashField = keyField{"hash", types.HashSize, func(b []byte) string {
+		hash, _ := types.BytesToHash(b)
+		return hash.String()
+	}}
+	heightField = keyField{"height", types.HeightSize, func(b []byte) string {
+		return strconv.FormatUint(BytesToUint64(b), 10)
+	}}
+	accountIdField = keyField{"accountId", types.AccountIdSize, func(b []byte) string {
+		return strconv.FormatUint(BytesToUint64(b), 10)
+	}}
+	tokenIdField = keyField{"tokenId", types.TokenTypeIdSize, func(b []byte) string {
+		tokenId, _ := types.BytesToTokenTypeId(b)
+		return tokenId.String()
+	}}
+	gidField = keyField{"gid", types.GidSize, func(b []byte) string {
+		gid, _ := types.BytesToGid(b)
+		return gid.String()
+	}}
+	// the storage key is padded to types.HashSize, the last byte is the length of the key
+	storageKeyField = keyField{"key", types.HashSize + 1, func(b []byte) string {
+		n := int(b[types.HashSize])
+		if n > types.HashSize {
+			return hex.EncodeToString(b)
+		}
+		return hex.EncodeToString(b[:n])
+	}}
+)
+
+// the key schemas of the index db, state db and state redo db, the key is the prefix
+var (
+	indexKeySchemas = map[byte]keySchema{
+		AccountBlockHashKeyPrefix:    {"AccountBlockHash", []keyField{hashField}},
+		AccountBlockHeightKeyPrefix:  {"AccountBlockHeight", []keyField{addressField, heightField}},
+		ReceiveKeyPrefix:             {"Receive", []keyField{hashField}},
+		ConfirmHeightKeyPrefix:       {"ConfirmHeight", []keyField{addressField, heightField}},
+		OnRoadKeyPrefix:              {"OnRoad", []keyField{addressField, hashField}},
+		SnapshotBlockHashKeyPrefix:   {"SnapshotBlockHash", []keyField{hashField}},
+		SnapshotBlockHeightKeyPrefix: {"SnapshotBlockHeight", []keyField{heightField}},
+		AccountAddressKeyPrefix:      {"AccountAddress", []keyField{addressField}},
+		AccountIdKeyPrefix:           {"AccountId", []keyField{accountIdField}},
+	}
+
+	stateKeySchemas = map[byte]keySchema{
+		StorageKeyPrefix:        {"Storage", []keyField{addressField, storageKeyField}},
+		StorageHistoryKeyPrefix: {"StorageHistory", []keyField{addressField, storageKeyField, heightField}},
+		BalanceKeyPrefix:        {"Balance", []keyField{addressField, tokenIdField}},
+		BalanceHistoryKeyPrefix: {"BalanceHistory", []keyField{addressField, tokenIdField, heightField}},
+		CodeKeyPrefix:           {"Code", []keyField{addressField}},
+		ContractMetaKeyPrefix:   {"ContractMeta", []keyField{addressField}},
+		GidContractKeyPrefix:    {"GidContract", []keyField{gidField, addressField}},
+		VmLogListKeyPrefix:      {"VmLogList", []keyField{hashField}},
+		CallDepthKeyPrefix:      {"CallDepth", []keyField{hashField}},
+		ExecutionErrorKeyPrefix: {"ExecutionError", []keyField{hashField}},
+	}
+
+	stateRedoKeySchemas = map[byte]keySchema{
+		SnapshotKeyPrefix: {"Snapshot", []keyField{heightField}},
+	}
+)
+
+// DescribeIndexKey decodes the key of the index db into human-readable form
+func DescribeIndexKey(key []byte) string {
+	return describeKey(indexKeySchemas, key)
+}
+
+// DescribeStateKey decodes the key of the state db into human-readable form
+func DescribeStateKey(key []byte) string {
+	return describeKey(stateKeySchemas, key)
+}
+
+// DescribeStateRedoKey decodes the key of the state redo db into human-readable form
+func DescribeStateRedoKey(key []byte) string {
+	return describeKey(stateRedoKeySchemas, key)
+}
+
+// describeKey returns like "Balance address=vite_xxx tokenId=tti_xxx", the unknown key is returned in hex
+func describeKey(schemas map[byte]keySchema, key []byte) string {
+	if len(key) == 0 {
+		return ""
+	}
+
+	schema, ok := schemas[key[0]]
+	if !ok {
+		return "Unknown " + hex.EncodeToString(key)
+	}
+
+	size := 1
+	for _, f := range schema.fields {
+		size += f.size
+	}
+	if len(key) != size {
+		return fmt.Sprintf("%s(malformed, %d bytes) %s", schema.name, len(key), hex.EncodeToString(key))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(schema.name)
+	offset := 1
+	for _, f := range schema.fields {
+		sb.WriteString(" " + f.name + "=" + f.format(key[offset:offset+f.size]))
+		offset += f.size
+	}
+
+	return sb.String()
+}
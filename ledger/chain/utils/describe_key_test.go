@@ -0,0 +1,35 @@
+package chain_utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+func TestDescribeKey(t *testing.T) {
+	addr := types.AddressQuota
+	hash := types.DataHash([]byte{1})
+
+	for _, c := range []struct {
+		desc string
+		want string
+	}{
+		{DescribeIndexKey(CreateAccountBlockHeightKey(&addr, 12).Bytes()), "AccountBlockHeight address=" + addr.String() + " height=12"},
+		{DescribeIndexKey(CreateOnRoadKey(addr, hash).Bytes()), "OnRoad address=" + addr.String() + " hash=" + hash.String()},
+		{DescribeIndexKey(CreateAccountIdKey(8).Bytes()), "AccountId accountId=8"},
+		{DescribeStateKey(CreateStorageValueKey(&addr, []byte{0xab, 0xcd}).Bytes()), "Storage address=" + addr.String() + " key=abcd"},
+		{DescribeStateKey(CreateHistoryStorageValueKey(&addr, []byte{0xab}, 3).Bytes()), "StorageHistory address=" + addr.String() + " key=ab height=3"},
+		{DescribeStateKey(CreateGidContractKey(types.DELEGATE_GID, &addr).Bytes()), "GidContract gid=" + types.DELEGATE_GID.String() + " address=" + addr.String()},
+		{DescribeStateRedoKey(CreateRedoSnapshot(100).Bytes()), "Snapshot height=100"},
+		{DescribeStateRedoKey([]byte{0xff, 1}), "Unknown ff01"},
+	} {
+		if c.desc != c.want {
+			t.Errorf("expected %q, got %q", c.want, c.desc)
+		}
+	}
+
+	if desc := DescribeIndexKey([]byte{SnapshotBlockHeightKeyPrefix, 1}); !strings.HasPrefix(desc, "SnapshotBlockHeight(malformed") {
+		t.Errorf("malformed key should be reported: %s", desc)
+	}
+}
@@ -190,6 +190,13 @@ func CreateCallDepthKey(blockHash types.Hash) CallDepthKey {
 	return key
 }
 
+func CreateExecutionErrorKey(blockHash types.Hash) ExecutionErrorKey {
+	key := ExecutionErrorKey{}
+	key[0] = ExecutionErrorKeyPrefix
+	key.HashRefill(blockHash)
+	return key
+}
+
 // ====== state redo ======
 
 func CreateRedoSnapshot(snapshotHeight uint64) SnapshotKey {
@@ -266,3 +266,18 @@ func (key CallDepthKey) String() string {
 func (key *CallDepthKey) HashRefill(hash types.Hash) {
 	copy(key[1:1+types.HashSize], hash.Bytes())
 }
+
+// -------------------------------
+type ExecutionErrorKey [1 + types.HashSize]byte
+
+func (key ExecutionErrorKey) Bytes() []byte {
+	return key[:]
+}
+
+func (key ExecutionErrorKey) String() string {
+	return string(key[:])
+}
+
+func (key *ExecutionErrorKey) HashRefill(hash types.Hash) {
+	copy(key[1:1+types.HashSize], hash.Bytes())
+}
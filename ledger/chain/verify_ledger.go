@@ -0,0 +1,275 @@
+package chain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	chain_utils "github.com/vitelabs/go-vite/v2/ledger/chain/utils"
+)
+
+const (
+	verifyLedgerBatchSize = 1000
+
+	// only the first maxLedgerIssues issues are kept in the report, the rest are counted
+	maxLedgerIssues = 1000
+
+	// in full mode, one of every contractSampleInterval contracts is re-derived
+	contractSampleInterval = 10
+)
+
+// LedgerReport is the result of VerifyLedger
+type LedgerReport struct {
+	Full bool
+
+	SnapshotBlocks   uint64
+	AccountBlocks    uint64
+	Accounts         uint64
+	SampledContracts uint64
+
+	IssueCount uint64
+	Issues     []string
+}
+
+func (r *LedgerReport) addIssue(format string, args ...interface{}) {
+	r.IssueCount++
+	if len(r.Issues) < maxLedgerIssues {
+		r.Issues = append(r.Issues, fmt.Sprintf(format, args...))
+	}
+}
+
+// VerifyLedger walks the block db from the genesis, recomputes the block hashes, checks the prev-hash linkage
+// of the snapshot chain and each account chain, and cross-checks the locations in the index db.
+// In full mode the blocks are read back by the index locations, and the storage of the sampled contracts
+// is re-derived from the history storage of the latest snapshot block.
+// The inconsistencies are collected in the report, the returned error means the verification can't go on.
+func (c *chain) VerifyLedger(full bool) (*LedgerReport, error) {
+	report := &LedgerReport{Full: full}
+
+	latestHeight := c.GetLatestSnapshotBlock().Height
+
+	var prevSb *ledger.SnapshotBlock
+	accountTails := make(map[types.Address]ledger.HashHeight)
+
+	for start := uint64(1); start <= latestHeight; start += verifyLedgerBatchSize {
+		end := start + verifyLedgerBatchSize - 1
+		if end > latestHeight {
+			end = latestHeight
+		}
+
+		// [start-1, end], the first chunk is the snapshot block at start-1
+		chunks, err := c.GetSubLedger(start-1, end)
+		if err != nil {
+			return report, err
+		}
+
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < start {
+				continue
+			}
+
+			for _, ab := range chunk.AccountBlocks {
+				c.verifyAccountBlock(report, ab, accountTails, full)
+			}
+
+			c.verifySnapshotBlock(report, chunk.SnapshotBlock, prevSb, full)
+			prevSb = chunk.SnapshotBlock
+		}
+
+		c.log.Info(fmt.Sprintf("verified snapshot blocks [%d, %d], %d issues", start, end, report.IssueCount), "method", "VerifyLedger")
+	}
+
+	if prevSb == nil || prevSb.Height != latestHeight {
+		report.addIssue("the block db ends before the latest snapshot block %d", latestHeight)
+	}
+
+	report.Accounts = uint64(len(accountTails))
+
+	if full {
+		if err := c.verifySampledContracts(report, latestHeight); err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func (c *chain) verifySnapshotBlock(report *LedgerReport, sb *ledger.SnapshotBlock, prevSb *ledger.SnapshotBlock, full bool) {
+	report.SnapshotBlocks++
+
+	if computed := sb.ComputeHash(); computed != sb.Hash {
+		report.addIssue("snapshot block %d: hash is %s, computed hash is %s", sb.Height, sb.Hash, computed)
+	}
+
+	if prevSb == nil {
+		if sb.Height != 1 || !sb.PrevHash.IsZero() {
+			report.addIssue("snapshot block %d: the first block in the block db should be the genesis", sb.Height)
+		}
+	} else if sb.Height != prevSb.Height+1 || sb.PrevHash != prevSb.Hash {
+		report.addIssue("snapshot block %d: prev block is %s/%d, prev hash is %s/%d",
+			sb.Height, prevSb.Hash, prevSb.Height, sb.PrevHash, sb.Height-1)
+	}
+
+	hash, location, err := c.indexDB.GetSnapshotBlockByHeight(sb.Height)
+	if err != nil {
+		report.addIssue("snapshot block %d: query index failed. Error: %s", sb.Height, err)
+		return
+	}
+	if hash == nil || location == nil {
+		report.addIssue("snapshot block %d: not indexed by height", sb.Height)
+		return
+	}
+	if *hash != sb.Hash {
+		report.addIssue("snapshot block %d: hash is %s, indexed hash is %s", sb.Height, sb.Hash, hash)
+	}
+
+	height, err := c.indexDB.GetSnapshotBlockHeight(&sb.Hash)
+	if err != nil {
+		report.addIssue("snapshot block %d: query index failed. Error: %s", sb.Height, err)
+	} else if height != sb.Height {
+		report.addIssue("snapshot block %s: height is %d, indexed height is %d", sb.Hash, sb.Height, height)
+	}
+
+	if !full {
+		return
+	}
+
+	stored, err := c.blockDB.GetSnapshotHeader(location)
+	if err != nil {
+		report.addIssue("snapshot block %d: read block db at %+v failed. Error: %s", sb.Height, location, err)
+	} else if stored == nil || stored.Hash != sb.Hash {
+		report.addIssue("snapshot block %d: the indexed location %+v points to another block", sb.Height, location)
+	}
+}
+
+func (c *chain) verifyAccountBlock(report *LedgerReport, ab *ledger.AccountBlock, accountTails map[types.Address]ledger.HashHeight, full bool) {
+	report.AccountBlocks++
+
+	if computed := ab.ComputeHash(); computed != ab.Hash {
+		report.addIssue("account block %s %d: hash is %s, computed hash is %s", ab.AccountAddress, ab.Height, ab.Hash, computed)
+	}
+
+	tail, ok := accountTails[ab.AccountAddress]
+	if !ok {
+		if ab.Height != 1 || !ab.PrevHash.IsZero() {
+			report.addIssue("account block %s %d: the first block of the account should be at height 1", ab.AccountAddress, ab.Height)
+		}
+	} else if ab.Height != tail.Height+1 || ab.PrevHash != tail.Hash {
+		report.addIssue("account block %s %d: prev block is %s/%d, prev hash is %s",
+			ab.AccountAddress, ab.Height, tail.Hash, tail.Height, ab.PrevHash)
+	}
+	accountTails[ab.AccountAddress] = ledger.HashHeight{Hash: ab.Hash, Height: ab.Height}
+
+	addr, height, err := c.indexDB.GetAddrHeightByHash(&ab.Hash)
+	if err != nil {
+		report.addIssue("account block %s: query index failed. Error: %s", ab.Hash, err)
+	} else if addr == nil || *addr != ab.AccountAddress || height != ab.Height {
+		report.addIssue("account block %s: %s %d is not indexed by hash", ab.Hash, ab.AccountAddress, ab.Height)
+	}
+
+	hash, location, err := c.indexDB.GetAccountBlockLocationByHeight(&ab.AccountAddress, ab.Height)
+	if err != nil {
+		report.addIssue("account block %s %d: query index failed. Error: %s", ab.AccountAddress, ab.Height, err)
+		return
+	}
+	if hash == nil || location == nil {
+		report.addIssue("account block %s %d: not indexed by height", ab.AccountAddress, ab.Height)
+		return
+	}
+	if *hash != ab.Hash {
+		report.addIssue("account block %s %d: hash is %s, indexed hash is %s", ab.AccountAddress, ab.Height, ab.Hash, hash)
+	}
+
+	if !full {
+		return
+	}
+
+	stored, err := c.blockDB.GetAccountBlock(location)
+	if err != nil {
+		report.addIssue("account block %s %d: read block db at %+v failed. Error: %s", ab.AccountAddress, ab.Height, location, err)
+	} else if stored == nil || stored.Hash != ab.Hash {
+		report.addIssue("account block %s %d: the indexed location %+v points to another block", ab.AccountAddress, ab.Height, location)
+	}
+}
+
+// verifySampledContracts re-derives the storage of the built-in contracts and the sampled contracts from the history storage
+// at the latest snapshot block and compares it with the latest storage.
+// The contracts with unconfirmed blocks are skipped, their latest storage is ahead of the snapshot.
+func (c *chain) verifySampledContracts(report *LedgerReport, latestHeight uint64) error {
+	// the built-in contracts are always verified, the meta of them is not stored.
+	// the contract meta cache may be incomplete, iterate the store in order
+	contracts := ledger.GetBuiltinContracts(latestHeight)
+	iter := c.stateDB.Store().NewIterator(util.BytesPrefix([]byte{chain_utils.ContractMetaKeyPrefix}))
+	for index := 0; iter.Next(); index++ {
+		if index%contractSampleInterval != 0 {
+			continue
+		}
+		addr, err := types.BytesToAddress(iter.Key()[1:])
+		if err != nil {
+			iter.Release()
+			return err
+		}
+		contracts = append(contracts, addr)
+	}
+	err := iter.Error()
+	iter.Release()
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range contracts {
+		if len(c.GetUnconfirmedBlocks(addr)) > 0 {
+			continue
+		}
+		report.SampledContracts++
+
+		derived, err := c.snapshotStorage(addr, latestHeight)
+		if err != nil {
+			return err
+		}
+		latest, err := c.latestStorage(addr)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range latest {
+			derivedValue, ok := derived[key]
+			if !ok {
+				report.addIssue("contract %s: storage key %x is missing in history", addr, key)
+			} else if !bytes.Equal(derivedValue, value) {
+				report.addIssue("contract %s: storage key %x is %x, value in history is %x", addr, key, value, derivedValue)
+			}
+		}
+		for key := range derived {
+			if _, ok := latest[key]; !ok {
+				report.addIssue("contract %s: storage key %x is missing in latest storage", addr, key)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *chain) latestStorage(addr types.Address) (map[string][]byte, error) {
+	iter := c.stateDB.NewStorageIterator(addr, nil)
+	defer iter.Release()
+
+	storage := make(map[string][]byte)
+	for iter.Next() {
+		storage[string(iter.Key())] = append([]byte(nil), iter.Value()...)
+	}
+	return storage, iter.Error()
+}
+
+func (c *chain) snapshotStorage(addr types.Address, snapshotHeight uint64) (map[string][]byte, error) {
+	iter := c.stateDB.NewStorageIteratorAt(addr, nil, snapshotHeight)
+	defer iter.Release()
+
+	storage := make(map[string][]byte)
+	for iter.Next() {
+		storage[string(iter.Key())] = append([]byte(nil), iter.Value()...)
+	}
+	return storage, iter.Error()
+}
@@ -3,14 +3,22 @@ package consensus
 import (
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/vitelabs/go-vite/v2/common/types"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus/cdb"
 )
 
+const maxPlansRounds = 48
+
+const maxUpcomingSlots = 1000
+
 // APISnapshot is the interface that can query snapshot consensus info.
 type APISnapshot struct {
 	snapshot *snapshotCs
+	dpos     *dposReader
+	liveness *livenessTracker
 }
 
 // ReadVoteMap query the vote result by time.
@@ -46,3 +54,97 @@ func (api APISnapshot) ReadByIndex(gid types.Gid, index uint64) ([]*Event, uint6
 	}
 	return result, uint64(eResult.Index), nil
 }
+
+// ReadPlansByIndex query the producer schedule for every round in [startIndex, endIndex].
+// Rounds in the future are calculated by the latest vote result.
+func (api APISnapshot) ReadPlansByIndex(gid types.Gid, startIndex, endIndex uint64) ([]*RoundPlans, error) {
+	if startIndex > endIndex {
+		return nil, errors.New("start index must not be greater than end index")
+	}
+	if endIndex-startIndex >= maxPlansRounds {
+		return nil, errors.Errorf("max rounds is %d", maxPlansRounds)
+	}
+	reader, err := api.dpos.getDposConsensus(gid)
+	if err != nil {
+		return nil, err
+	}
+	return readPlansByIndex(reader, gid, startIndex, endIndex)
+}
+
+func readPlansByIndex(reader DposReader, gid types.Gid, startIndex, endIndex uint64) ([]*RoundPlans, error) {
+	var result []*RoundPlans
+	for i := startIndex; i <= endIndex; i++ {
+		eResult, err := reader.ElectionIndex(i)
+		if err != nil {
+			return nil, err
+		}
+		voteTime := reader.GenProofTime(i)
+		round := &RoundPlans{
+			Gid:   gid,
+			Index: eResult.Index,
+			Stime: eResult.STime,
+			Etime: eResult.ETime,
+		}
+		for _, p := range eResult.Plans {
+			e := newConsensusEvent(eResult, p, gid, voteTime)
+			round.Plans = append(round.Plans, &e)
+		}
+		result = append(result, round)
+	}
+	return result, nil
+}
+
+// ReadProducerLiveness query produced and missed slots of every SBP in [startIndex, endIndex].
+// Only the rounds observed by this node since it started are available.
+func (api *APISnapshot) ReadProducerLiveness(startIndex, endIndex uint64) ([]*ProducerLiveness, error) {
+	if startIndex > endIndex {
+		return nil, errors.New("start index must not be greater than end index")
+	}
+	return api.liveness.read(startIndex, endIndex), nil
+}
+
+// ReadUpcomingProducers query the producer of the slot in flight at t and the producers of the next k slots.
+// The rounds in the future are calculated by the latest vote result.
+func (api *APISnapshot) ReadUpcomingProducers(gid types.Gid, t time.Time, k int) (*Event, []*Event, error) {
+	if k < 0 || k > maxUpcomingSlots {
+		return nil, nil, errors.Errorf("slots must be in [0, %d]", maxUpcomingSlots)
+	}
+	reader, err := api.dpos.getDposConsensus(gid)
+	if err != nil {
+		return nil, nil, err
+	}
+	return readUpcomingProducers(reader, gid, t, k)
+}
+
+func readUpcomingProducers(reader DposReader, gid types.Gid, t time.Time, k int) (*Event, []*Event, error) {
+	var current *Event
+	next := make([]*Event, 0, k)
+	for index := reader.Time2Index(t); ; index++ {
+		eResult, err := reader.ElectionIndex(index)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(eResult.Plans) == 0 {
+			return nil, nil, errors.Errorf("no plans in round %d", index)
+		}
+		voteTime := reader.GenProofTime(index)
+		for _, p := range eResult.Plans {
+			if !p.ETime.After(t) {
+				continue
+			}
+			e := newConsensusEvent(eResult, p, gid, voteTime)
+			if current == nil && !p.STime.After(t) {
+				current = &e
+				continue
+			}
+			if len(next) >= k {
+				break
+			}
+			next = append(next, &e)
+		}
+		if len(next) >= k {
+			break
+		}
+	}
+	return current, next, nil
+}
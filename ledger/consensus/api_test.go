@@ -0,0 +1,111 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
+)
+
+type scheduleReader struct {
+	DposReader
+	info    *core.GroupInfo
+	members []types.Address
+}
+
+func (r *scheduleReader) ElectionIndex(index uint64) (*electionResult, error) {
+	return genElectionResult(r.info, index, r.members), nil
+}
+
+func (r *scheduleReader) Time2Index(t time.Time) uint64 {
+	return r.info.Time2Index(t)
+}
+
+func (r *scheduleReader) GenProofTime(index uint64) time.Time {
+	sTime, _ := r.info.Index2Time(index)
+	return sTime
+}
+
+func TestReadPlansByIndex(t *testing.T) {
+	info := core.NewGroupInfo(simpleGenesis, types.ConsensusGroupInfo{
+		Gid:             types.SNAPSHOT_GID,
+		NodeCount:       2,
+		Interval:        1,
+		PerCount:        3,
+		Repeat:          1,
+		RandCount:       0,
+		CountingTokenId: ledger.ViteTokenId,
+	})
+	members := []types.Address{types.AddressGovernance, types.AddressQuota}
+	reader := &scheduleReader{info: info, members: members}
+
+	rounds, err := readPlansByIndex(reader, types.SNAPSHOT_GID, 2, 4)
+	assert.NoError(t, err)
+	if assert.Len(t, rounds, 3) {
+		for i, round := range rounds {
+			index := uint64(2 + i)
+			sTime, eTime := info.Index2Time(index)
+			assert.Equal(t, index, round.Index)
+			assert.Equal(t, types.SNAPSHOT_GID, round.Gid)
+			assert.Equal(t, sTime, round.Stime)
+			assert.Equal(t, eTime, round.Etime)
+
+			// every member produces PerCount slots in turn
+			if assert.Len(t, round.Plans, 6) {
+				for j, e := range round.Plans {
+					assert.Equal(t, members[j/3], e.Address)
+					assert.Equal(t, sTime.Add(time.Duration(j)*time.Second), e.Stime)
+					assert.Equal(t, sTime, e.PeriodStime)
+				}
+			}
+		}
+	}
+
+	api := &APISnapshot{}
+	_, err = api.ReadPlansByIndex(types.SNAPSHOT_GID, 4, 2)
+	assert.Error(t, err)
+	_, err = api.ReadPlansByIndex(types.SNAPSHOT_GID, 0, maxPlansRounds)
+	assert.Error(t, err)
+}
+
+func TestReadUpcomingProducers(t *testing.T) {
+	info := core.NewGroupInfo(simpleGenesis, types.ConsensusGroupInfo{
+		Gid:             types.SNAPSHOT_GID,
+		NodeCount:       2,
+		Interval:        1,
+		PerCount:        3,
+		Repeat:          1,
+		RandCount:       0,
+		CountingTokenId: ledger.ViteTokenId,
+	})
+	members := []types.Address{types.AddressGovernance, types.AddressQuota}
+	reader := &scheduleReader{info: info, members: members}
+
+	// the 2nd slot of the 2nd producer in round 0
+	now := simpleGenesis.Add(4*time.Second + 500*time.Millisecond)
+	current, next, err := readUpcomingProducers(reader, types.SNAPSHOT_GID, now, 5)
+	assert.NoError(t, err)
+	if assert.NotNil(t, current) {
+		assert.Equal(t, members[1], current.Address)
+		assert.Equal(t, simpleGenesis.Add(4*time.Second), current.Stime)
+	}
+
+	// the 3rd slot in round 0, then the slots of round 1
+	if assert.Len(t, next, 5) {
+		for i, e := range next {
+			assert.Equal(t, simpleGenesis.Add(time.Duration(5+i)*time.Second), e.Stime)
+			assert.Equal(t, e.Stime.Add(time.Second), e.Etime)
+		}
+		assert.Equal(t, uint64(0), info.Time2Index(next[0].Stime))
+		assert.Equal(t, uint64(1), info.Time2Index(next[1].Stime))
+	}
+
+	current, next, err = readUpcomingProducers(reader, types.SNAPSHOT_GID, now, 0)
+	assert.NoError(t, err)
+	assert.NotNil(t, current)
+	assert.Empty(t, next)
+}
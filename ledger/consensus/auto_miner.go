@@ -0,0 +1,111 @@
+package consensus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// the snapshot timestamp is in seconds, two snapshot blocks can't be mined in the same second
+const autoMineMinGap = time.Second
+
+const autoMinePollInterval = time.Millisecond * 200
+
+// AutoMiner drives the puppet subscriber for single node dev mode.
+// A snapshot block is mined every interval, or instantly when there are pending blocks.
+type AutoMiner struct {
+	common.LifecycleStatus
+
+	sub      Subscriber
+	addr     types.Address
+	interval time.Duration
+	pending  func() bool
+
+	lastMine time.Time
+
+	log log15.Logger
+
+	wg       sync.WaitGroup
+	ctx      context.Context
+	cancelFn context.CancelFunc
+}
+
+// NewAutoMiner creates an auto miner, the subscriber must be a puppet subscriber.
+// Zero interval means mining only when pending returns true.
+func NewAutoMiner(sub Subscriber, addr types.Address, interval time.Duration, pending func() bool) (*AutoMiner, error) {
+	if cs, ok := sub.(*consensus); ok {
+		sub = cs.Subscriber
+	}
+	if _, ok := sub.(*subscriber_puppet); !ok {
+		return nil, errors.New("auto miner requires puppet consensus")
+	}
+	if interval > 0 && interval < autoMineMinGap {
+		return nil, errors.Errorf("auto mine interval must not be less than %s", autoMineMinGap)
+	}
+	return &AutoMiner{
+		sub:      sub,
+		addr:     addr,
+		interval: interval,
+		pending:  pending,
+		log:      log15.New("module", "consensus/auto_miner"),
+	}, nil
+}
+
+func (m *AutoMiner) Start() {
+	m.PreStart()
+	defer m.PostStart()
+	m.ctx, m.cancelFn = context.WithCancel(context.Background())
+	m.wg.Add(1)
+	common.Go(func() {
+		defer m.wg.Done()
+		m.loop(m.ctx)
+	})
+	m.log.Info("started.", "addr", m.addr, "interval", m.interval)
+}
+
+func (m *AutoMiner) Stop() {
+	m.PreStop()
+	defer m.PostStop()
+	m.cancelFn()
+	m.wg.Wait()
+	m.log.Info("stopped.")
+}
+
+func (m *AutoMiner) loop(ctx context.Context) {
+	ticker := time.NewTicker(autoMinePollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if m.shouldMine(now) {
+				m.mine(now)
+			}
+		}
+	}
+}
+
+func (m *AutoMiner) shouldMine(now time.Time) bool {
+	elapsed := now.Sub(m.lastMine)
+	if elapsed < autoMineMinGap {
+		return false
+	}
+	if m.interval > 0 && elapsed >= m.interval {
+		return true
+	}
+	return m.pending != nil && m.pending()
+}
+
+func (m *AutoMiner) mine(now time.Time) {
+	m.lastMine = now
+	if err := m.sub.TriggerMineEvent(m.addr); err != nil {
+		m.log.Error("trigger mine event fail.", "err", err)
+	}
+}
@@ -69,6 +69,9 @@ type chainRw struct {
 
 	log      log15.Logger
 	snapshot *snapshotCs
+
+	// election algorithm name for every consensus group, configured from genesis
+	algos map[types.Gid]string
 }
 
 func newChainRw(rw Chain, log log15.Logger, rollbackLock lock.ChainRollback) *chainRw {
@@ -198,6 +201,14 @@ func (cRw *chainRw) GetSeedsBeforeHashH(hash types.Hash) uint64 {
 	return cRw.rw.GetRandomSeed(hash, 25)
 }
 
+func (cRw *chainRw) newAlgo(info *core.GroupInfo) core.Algo {
+	algo, err := core.NewAlgoByName(cRw.algos[info.Gid], info)
+	if err != nil {
+		panic(err)
+	}
+	return algo
+}
+
 func (cRw *chainRw) CalVotes(info *core.GroupInfo, hashH ledger.HashHeight) ([]*core.Vote, error) {
 	return core.CalVotes(info.ConsensusGroupInfo, hashH.Hash, cRw.rw)
 }
@@ -1,7 +1,20 @@
 package consensus
 
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
 type ConsensusCfg struct {
 	EnablePuppet bool
+
+	// ReplayWindow is the lookback window of missed events replayed
+	// to a subscriber when it is (re)attached, zero disables replay.
+	ReplayWindow time.Duration
+
+	// Algos is the election algorithm name for consensus groups, the default algorithm is used if absent.
+	Algos map[types.Gid]string
 }
 
 func DefaultCfg() *ConsensusCfg {
@@ -33,6 +33,8 @@ type Event struct {
 	VoteTime    time.Time // voteTime
 	PeriodStime time.Time // start time for period
 	PeriodEtime time.Time // end time for period
+
+	Replayed bool // the event is replayed for a slot in the past
 }
 
 // ProducersEvent describes all SBP in one period
@@ -42,12 +44,22 @@ type ProducersEvent struct {
 	Gid   types.Gid
 }
 
+// RoundPlans describes the producer schedule of one round
+type RoundPlans struct {
+	Gid   types.Gid
+	Index uint64
+	Stime time.Time
+	Etime time.Time
+	Plans []*Event
+}
+
 // Subscriber provide an interface to consensus event
 type Subscriber interface {
 	Subscribe(gid types.Gid, id string, addr *types.Address, fn func(Event))
 	UnSubscribe(gid types.Gid, id string)
 	SubscribeProducers(gid types.Gid, id string, fn func(event ProducersEvent))
 	TriggerMineEvent(addr types.Address) error
+	TriggerContractMineEvent(gid types.Gid, addr types.Address) error
 }
 
 // Reader can read consensus result
@@ -62,6 +74,9 @@ type APIReader interface {
 	ReadVoteMap(t time.Time) ([]*VoteDetails, *ledger.HashHeight, error)
 	ReadSuccessRate(start, end uint64) ([]map[types.Address]*cdb.Content, error)
 	ReadByIndex(gid types.Gid, index uint64) ([]*Event, uint64, error)
+	ReadPlansByIndex(gid types.Gid, startIndex, endIndex uint64) ([]*RoundPlans, error)
+	ReadProducerLiveness(startIndex, endIndex uint64) ([]*ProducerLiveness, error)
+	ReadUpcomingProducers(gid types.Gid, t time.Time, k int) (*Event, []*Event, error)
 }
 
 // Life define the life cycle for consensus component
@@ -100,6 +115,7 @@ type consensus struct {
 	contracts *contractsCs
 
 	dposWrapper *dposReader
+	liveness    *livenessTracker
 
 	api APIReader
 
@@ -26,7 +26,7 @@ func newContractDposCs(info *core.GroupInfo, rw *chainRw, log log15.Logger) *con
 	cs := &contractDposCs{}
 	cs.rw = rw
 	cs.GroupInfo = *info
-	cs.algo = core.NewAlgo(info)
+	cs.algo = rw.newAlgo(info)
 	cs.log = log.New("gid", fmt.Sprintf("contract-%s", info.Gid.String()))
 	return cs
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
 )
 
 func (cs *consensus) VerifySnapshotProducer(header *ledger.SnapshotBlock) (bool, error) {
@@ -110,14 +111,26 @@ func (cs *consensus) Init(cfg *ConsensusCfg) error {
 		cfg = DefaultCfg()
 	}
 	cs.ConsensusCfg = cfg
+	for gid, name := range cfg.Algos {
+		if err := core.CheckAlgo(name); err != nil {
+			return errors.Wrapf(err, "consensus group[%s]", gid)
+		}
+	}
+	cs.rw.algos = cfg.Algos
 
 	snapshot := newSnapshotCs(cs.rw, cs.mLog)
 	cs.snapshot = snapshot
 	cs.rw.init(snapshot)
 
 	cs.tg = newTrigger(cs.rollback)
+	base, ok := cs.Subscriber.(*consensusSubscriber)
+	if !ok {
+		panic("err sub type")
+	}
 	if cfg.EnablePuppet {
-		sub := newSubscriberPuppet(cs.Subscriber, cs.snapshot)
+		sub := newSubscriberPuppet(cs.Subscriber, cs.snapshot, func(gid types.Gid) (DposReader, error) {
+			return cs.dposWrapper.getDposConsensus(gid)
+		})
 		cs.Subscriber = sub
 		cs.subscribeTrigger = sub
 	}
@@ -129,7 +142,11 @@ func (cs *consensus) Init(cfg *ConsensusCfg) error {
 		panic(err)
 	}
 	cs.dposWrapper = &dposReader{cs.snapshot, cs.contracts, cs.mLog}
-	cs.api = &APISnapshot{snapshot: snapshot}
+	if cfg.ReplayWindow > 0 {
+		base.enableReplay(cfg.ReplayWindow, cs.dposWrapper.getDposConsensus, cs.rollback, cs.mLog)
+	}
+	cs.liveness = newLivenessTracker(cs.rw, cs.snapshot, cs.mLog)
+	cs.api = &APISnapshot{snapshot: snapshot, dpos: cs.dposWrapper, liveness: cs.liveness}
 	return nil
 }
 
@@ -156,6 +173,10 @@ func (cs *consensus) Start() {
 		cs.tg.update(cs.ctx, types.DELEGATE_GID, reader, cs.subscribeTrigger)
 	})
 
+	cs.Subscriber.Subscribe(types.SNAPSHOT_GID, livenessSubscribeId, nil, func(e Event) {
+		cs.liveness.onEvent(cs.ctx, e)
+	})
+
 	cs.rw.Start()
 	//cs.rw.rw.Register(cs)
 }
@@ -164,6 +185,7 @@ func (cs *consensus) Stop() {
 	cs.PreStop()
 	defer cs.PostStop()
 	//cs.rw.rw.UnRegister(cs)
+	cs.Subscriber.UnSubscribe(types.SNAPSHOT_GID, livenessSubscribeId)
 	cs.rw.Stop()
 	cs.cancelFn()
 	close(cs.closed)
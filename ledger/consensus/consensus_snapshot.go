@@ -242,7 +242,7 @@ func newSnapshotCs(rw *chainRw, log log15.Logger) *snapshotCs {
 	if err != nil {
 		panic(err)
 	}
-	cs.algo = core.NewAlgo(info)
+	cs.algo = rw.newAlgo(info)
 	cs.GroupInfo = *info
 	return cs
 }
@@ -301,7 +301,7 @@ func (snapshot *snapshotCs) calVotes(proofBlock *ledger.SnapshotBlock, index uin
 	for _, v := range votes {
 		all += fmt.Sprintf("[%s-%s]", v.Name, v.Balance.String())
 	}
-	snapshot.log.Info(fmt.Sprintf("[%d][%d]pre success rate log: %+v, %s, seed:%d", hashH.Height, index, successRate, all, seed))
+	snapshot.log.Debug(fmt.Sprintf("[%d][%d]pre success rate log: %+v, %s, seed:%d", hashH.Height, index, successRate, all, seed))
 
 	context := core.NewVoteAlgoContext(votes, &hashH, successRate, seed)
 	// filter size of members
@@ -317,7 +317,7 @@ func (snapshot *snapshotCs) calVotes(proofBlock *ledger.SnapshotBlock, index uin
 			result += fmt.Sprintf("[%s],", v.Name)
 		}
 	}
-	snapshot.log.Info(result)
+	snapshot.log.Debug(result)
 	address := core.ConvertVoteToAddress(finalVotes)
 
 	// update cache
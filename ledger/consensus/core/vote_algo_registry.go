@@ -0,0 +1,139 @@
+package core
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// AlgoFactory creates the election algorithm for a consensus group
+type AlgoFactory func(info *GroupInfo) Algo
+
+const (
+	// DefaultAlgoName is the seed-based dpos election used by the mainnet
+	DefaultAlgoName = "dpos"
+	// RoundRobinAlgoName elects the first members ordered by name, without randomization, for PoA deployments
+	RoundRobinAlgoName = "roundrobin"
+	// StakeAlgoName elects the members with the most votes, without randomization
+	StakeAlgoName = "stake"
+)
+
+var (
+	algoFactories   = make(map[string]AlgoFactory)
+	algoFactoriesMu sync.RWMutex
+)
+
+func init() {
+	mustRegisterAlgo(DefaultAlgoName, func(info *GroupInfo) Algo {
+		return NewAlgo(info)
+	})
+	mustRegisterAlgo(RoundRobinAlgoName, func(info *GroupInfo) Algo {
+		return &roundRobinAlgo{info: info}
+	})
+	mustRegisterAlgo(StakeAlgoName, func(info *GroupInfo) Algo {
+		return &stakeAlgo{info: info}
+	})
+}
+
+func mustRegisterAlgo(name string, factory AlgoFactory) {
+	if err := RegisterAlgo(name, factory); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterAlgo registers an election algorithm, it must be called before consensus is initialized.
+func RegisterAlgo(name string, factory AlgoFactory) error {
+	if name == "" || factory == nil {
+		return errors.New("algo name and factory must not be empty")
+	}
+	algoFactoriesMu.Lock()
+	defer algoFactoriesMu.Unlock()
+	if _, ok := algoFactories[name]; ok {
+		return errors.Errorf("algo[%s] already registered", name)
+	}
+	algoFactories[name] = factory
+	return nil
+}
+
+// CheckAlgo checks whether the algorithm is registered, empty name means the default one.
+func CheckAlgo(name string) error {
+	if name == "" {
+		return nil
+	}
+	algoFactoriesMu.RLock()
+	defer algoFactoriesMu.RUnlock()
+	if _, ok := algoFactories[name]; !ok {
+		return errors.Errorf("algo[%s] not registered", name)
+	}
+	return nil
+}
+
+// NewAlgoByName creates the registered algorithm for the group, empty name means the default one.
+func NewAlgoByName(name string, info *GroupInfo) (Algo, error) {
+	if name == "" {
+		name = DefaultAlgoName
+	}
+	algoFactoriesMu.RLock()
+	factory, ok := algoFactories[name]
+	algoFactoriesMu.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("algo[%s] not registered", name)
+	}
+	return factory(info), nil
+}
+
+// roundRobinAlgo elects the first NodeCount members ordered by name,
+// the members produce blocks in the same order every round.
+type roundRobinAlgo struct {
+	info *GroupInfo
+}
+
+func (self *roundRobinAlgo) ShuffleVotes(votes []*Vote, hashH *ledger.HashHeight, info *SeedInfo) []*Vote {
+	return votes
+}
+
+func (self *roundRobinAlgo) FilterVotes(context *VoteAlgoContext) []*Vote {
+	groupA, groupB := self.FilterSimple(context.votes)
+	context.sbps = mergeGroup(groupA, groupB)
+	return groupA
+}
+
+func (self *roundRobinAlgo) FilterSimple(votes []*Vote) ([]*Vote, []*Vote) {
+	sorted := make([]*Vote, len(votes))
+	copy(sorted, votes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	if len(sorted) <= int(self.info.NodeCount) {
+		return sorted, nil
+	}
+	return sorted[:self.info.NodeCount], sorted[self.info.NodeCount:]
+}
+
+// stakeAlgo elects the top NodeCount members by votes, ordered by votes.
+type stakeAlgo struct {
+	info *GroupInfo
+}
+
+func (self *stakeAlgo) ShuffleVotes(votes []*Vote, hashH *ledger.HashHeight, info *SeedInfo) []*Vote {
+	return votes
+}
+
+func (self *stakeAlgo) FilterVotes(context *VoteAlgoContext) []*Vote {
+	groupA, groupB := self.FilterSimple(context.votes)
+	context.sbps = mergeGroup(groupA, groupB)
+	return groupA
+}
+
+func (self *stakeAlgo) FilterSimple(votes []*Vote) ([]*Vote, []*Vote) {
+	sorted := make([]*Vote, len(votes))
+	copy(sorted, votes)
+	sort.Sort(ByBalance(sorted))
+	if len(sorted) <= int(self.info.NodeCount) {
+		return sorted, nil
+	}
+	return sorted[:self.info.NodeCount], sorted[self.info.NodeCount:]
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func genRegistryTestVotes(n int) []*Vote {
+	var votes []*Vote
+	for i := 0; i < n; i++ {
+		addr, _, _ := types.CreateAddress()
+		votes = append(votes, &Vote{Name: "s" + strconv.Itoa(n-i), Addr: addr, Balance: big.NewInt(int64(i))})
+	}
+	return votes
+}
+
+func TestNewAlgoByName(t *testing.T) {
+	info := NewGroupInfo(time.Unix(1541640427, 0), types.ConsensusGroupInfo{
+		Gid:       types.SNAPSHOT_GID,
+		NodeCount: 3,
+		Interval:  1,
+		PerCount:  3,
+		RandCount: 1,
+		RandRank:  5,
+	})
+
+	defaultAlgo, err := NewAlgoByName("", info)
+	assert.NoError(t, err)
+	assert.IsType(t, &algo{}, defaultAlgo)
+
+	_, err = NewAlgoByName("unknown", info)
+	assert.Error(t, err)
+	assert.Error(t, CheckAlgo("unknown"))
+	assert.NoError(t, CheckAlgo(""))
+	assert.Error(t, RegisterAlgo(DefaultAlgoName, func(info *GroupInfo) Algo { return NewAlgo(info) }))
+
+	hashH := &ledger.HashHeight{Height: 10}
+
+	rr, err := NewAlgoByName(RoundRobinAlgoName, info)
+	assert.NoError(t, err)
+	votes := genRegistryTestVotes(5)
+	result := rr.ShuffleVotes(rr.FilterVotes(NewVoteAlgoContext(votes, hashH, nil, NewSeedInfo(0))), hashH, NewSeedInfo(0))
+	assert.Equal(t, []string{"s1", "s2", "s3"}, names(result))
+
+	stake, err := NewAlgoByName(StakeAlgoName, info)
+	assert.NoError(t, err)
+	result = stake.ShuffleVotes(stake.FilterVotes(NewVoteAlgoContext(votes, hashH, nil, NewSeedInfo(0))), hashH, NewSeedInfo(0))
+	assert.Equal(t, []string{"s1", "s2", "s3"}, names(result))
+	assert.Equal(t, int64(4), result[0].Balance.Int64())
+}
+
+func names(votes []*Vote) []string {
+	var result []string
+	for _, v := range votes {
+		result = append(result, v.Name)
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package consensus
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+// keep one day of rounds for snapshot consensus group
+const maxLivenessRounds = 1152
+
+// the block of a slot may arrive a little later than the end of the slot
+const livenessCheckDelay = time.Second * 2
+
+const livenessSubscribeId = "consensus_liveness"
+
+var (
+	livenessSlotsMetric = monitor.GetOrRegisterCounterVec("vite_consensus_producer_slots_total",
+		"snapshot slots observed by this node, partitioned by producer and result", "address", "result")
+	slotsMetric = monitor.GetOrRegisterCounterVec("vite_consensus_slots_total",
+		"snapshot slots observed by this node, partitioned by hit or miss", "result")
+	livenessIndexMetric = monitor.GetOrRegisterGauge("vite_consensus_liveness_index",
+		"latest round index checked by the producer liveness tracker")
+)
+
+// ProducerLiveness counts the produced and missed slots of a producer in one round
+type ProducerLiveness struct {
+	Index    uint64
+	Address  types.Address
+	Produced uint64
+	Missed   uint64
+}
+
+// livenessTracker checks every snapshot slot after it ends, and records whether the producer produced the block.
+type livenessTracker struct {
+	rw       *chainRw
+	snapshot DposReader
+
+	mu     sync.RWMutex
+	rounds map[uint64]map[types.Address]*ProducerLiveness
+
+	log log15.Logger
+}
+
+func newLivenessTracker(rw *chainRw, snapshot DposReader, log log15.Logger) *livenessTracker {
+	return &livenessTracker{
+		rw:       rw,
+		snapshot: snapshot,
+		rounds:   make(map[uint64]map[types.Address]*ProducerLiveness),
+		log:      log.New("sub", "liveness"),
+	}
+}
+
+func (tracker *livenessTracker) onEvent(ctx context.Context, e Event) {
+	common.Go(func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(e.Etime.Add(livenessCheckDelay))):
+		}
+		produced, err := tracker.check(e)
+		if err != nil {
+			tracker.log.Error("check slot fail.", "addr", e.Address, "stime", e.Stime, "err", err)
+			return
+		}
+		tracker.record(tracker.snapshot.Time2Index(e.Stime), e.Address, produced)
+	})
+}
+
+func (tracker *livenessTracker) check(e Event) (bool, error) {
+	block, err := tracker.rw.rw.GetSnapshotHeaderBeforeTime(&e.Etime)
+	if err != nil {
+		return false, err
+	}
+	if block == nil || block.Timestamp.Before(e.Stime) {
+		return false, nil
+	}
+	return block.Producer() == e.Address, nil
+}
+
+func (tracker *livenessTracker) record(index uint64, addr types.Address, produced bool) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	round, ok := tracker.rounds[index]
+	if !ok {
+		round = make(map[types.Address]*ProducerLiveness)
+		tracker.rounds[index] = round
+		if index >= maxLivenessRounds {
+			for k := range tracker.rounds {
+				if k <= index-maxLivenessRounds {
+					delete(tracker.rounds, k)
+				}
+			}
+		}
+		if float64(index) > livenessIndexMetric.Value() {
+			livenessIndexMetric.Set(float64(index))
+		}
+	}
+	l, ok := round[addr]
+	if !ok {
+		l = &ProducerLiveness{Index: index, Address: addr}
+		round[addr] = l
+	}
+	if produced {
+		l.Produced++
+		livenessSlotsMetric.With(addr.String(), "produced").Add(1)
+		slotsMetric.With("hit").Add(1)
+	} else {
+		l.Missed++
+		livenessSlotsMetric.With(addr.String(), "missed").Add(1)
+		slotsMetric.With("miss").Add(1)
+	}
+}
+
+// read returns the liveness of all producers in [startIndex, endIndex], sorted by index and address
+func (tracker *livenessTracker) read(startIndex, endIndex uint64) []*ProducerLiveness {
+	tracker.mu.RLock()
+	defer tracker.mu.RUnlock()
+
+	var result []*ProducerLiveness
+	for index, round := range tracker.rounds {
+		if index < startIndex || index > endIndex {
+			continue
+		}
+		for _, l := range round {
+			tmp := *l
+			result = append(result, &tmp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Index != result[j].Index {
+			return result[i].Index < result[j].Index
+		}
+		return result[i].Address.String() < result[j].Address.String()
+	})
+	return result
+}
@@ -2,9 +2,14 @@ package consensus
 
 import (
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/pool/lock"
+	"github.com/vitelabs/go-vite/v2/log15"
 )
 
 type subscribeTrigger interface {
@@ -15,6 +20,12 @@ type subscribeTrigger interface {
 type consensusSubscriber struct {
 	firstSub  *sync.Map
 	secondSub *sync.Map
+
+	// replay missed events when a subscription is (re)attached
+	replayWindow time.Duration
+	replayReader func(gid types.Gid) (DposReader, error)
+	rollback     lock.ChainRollback
+	log          log15.Logger
 }
 
 func newConsensusSubscriber() *consensusSubscriber {
@@ -30,9 +41,56 @@ func (cs *consensusSubscriber) selected(gid types.Gid) *sync.Map {
 	return nil
 }
 
+func (cs *consensusSubscriber) enableReplay(window time.Duration, reader func(gid types.Gid) (DposReader, error), rollback lock.ChainRollback, log log15.Logger) {
+	cs.replayWindow = window
+	cs.replayReader = reader
+	cs.rollback = rollback
+	cs.log = log
+}
+
 func (cs *consensusSubscriber) Subscribe(gid types.Gid, id string, addr *types.Address, fn func(Event)) {
 	sub := cs.selected(gid)
-	sub.Store(id, &subscribeEvent{addr: addr, fn: fn, gid: gid})
+	e := &subscribeEvent{addr: addr, fn: fn, gid: gid}
+	sub.Store(id, e)
+
+	if cs.replayWindow > 0 && cs.replayReader != nil {
+		now := time.Now()
+		common.Go(func() {
+			cs.replay(e, now)
+		})
+	}
+}
+
+// replay triggers events for the slots in [now-replayWindow, now) which the subscriber missed.
+func (cs *consensusSubscriber) replay(e *subscribeEvent, now time.Time) {
+	reader, err := cs.replayReader(e.gid)
+	if err != nil {
+		cs.log.Error("can't get dpos reader for replay", "gid", e.gid, "err", err)
+		return
+	}
+	since := now.Add(-cs.replayWindow)
+	endIndex := reader.Time2Index(now)
+	for i := reader.Time2Index(since); i <= endIndex; i++ {
+		cs.rollback.RLockRollback()
+		result, err := reader.ElectionIndex(i)
+		cs.rollback.RUnLockRollback()
+		if err != nil {
+			cs.log.Error("can't get election result for replay", "gid", e.gid, "index", i, "err", err)
+			return
+		}
+		voteTime := reader.GenProofTime(i)
+		for _, p := range result.Plans {
+			if p.STime.Before(since) || !p.STime.Before(now) {
+				continue
+			}
+			if e.addr != nil && p.Member != *e.addr {
+				continue
+			}
+			event := newConsensusEvent(result, p, e.gid, voteTime)
+			event.Replayed = true
+			e.fn(event)
+		}
+	}
 }
 func (cs *consensusSubscriber) UnSubscribe(gid types.Gid, id string) {
 	cs.selected(gid).Delete(id)
@@ -66,3 +124,7 @@ func (cs consensusSubscriber) triggerProducerEvent(gid types.Gid, fn func(*produ
 func (cs consensusSubscriber) TriggerMineEvent(addr types.Address) error {
 	return errors.New("not supported")
 }
+
+func (cs consensusSubscriber) TriggerContractMineEvent(gid types.Gid, addr types.Address) error {
+	return errors.New("not supported")
+}
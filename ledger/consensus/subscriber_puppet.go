@@ -3,6 +3,8 @@ package consensus
 import (
 	"time"
 
+	"github.com/pkg/errors"
+
 	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/common/types"
 )
@@ -11,14 +13,17 @@ type subscriber_puppet struct {
 	*consensusSubscriber
 
 	snapshot DposReader
+	// contracts loads the reader of delegated contract groups
+	contracts func(gid types.Gid) (DposReader, error)
 }
 
-func newSubscriberPuppet(sub interface{}, snapshot DposReader) *subscriber_puppet {
+func newSubscriberPuppet(sub interface{}, snapshot DposReader, contracts func(gid types.Gid) (DposReader, error)) *subscriber_puppet {
 	switch v := sub.(type) {
 	case *consensusSubscriber:
 		return &subscriber_puppet{
 			consensusSubscriber: v,
 			snapshot:            snapshot,
+			contracts:           contracts,
 		}
 	}
 	panic("err sub type")
@@ -56,3 +61,43 @@ func (cs subscriber_puppet) TriggerMineEvent(addr types.Address) error {
 	})
 	return nil
 }
+
+// TriggerContractMineEvent synthesizes a mine event for the delegated contract group,
+// the slot is aligned to the interval of the group and the vote time is the proof time of current period.
+func (cs subscriber_puppet) TriggerContractMineEvent(gid types.Gid, addr types.Address) error {
+	if gid == types.SNAPSHOT_GID {
+		return errors.New("snapshot gid is not a contract group")
+	}
+	reader, err := cs.contracts(gid)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	index := reader.Time2Index(now)
+	periodStartTime, periodEndTime := reader.Index2Time(index)
+	interval := time.Duration(reader.GetInfo().Interval) * time.Second
+	sTime := periodStartTime.Add(now.Sub(periodStartTime) / interval * interval)
+	eTime := sTime.Add(interval)
+	voteTime := reader.GenProofTime(index)
+
+	// all contract groups are subscribed by delegate gid
+	cs.consensusSubscriber.triggerEvent(types.DELEGATE_GID, func(e *subscribeEvent) {
+		if e.addr != nil && *e.addr != addr {
+			return
+		}
+		common.Go(func() {
+			event := Event{
+				Gid:         gid,
+				Address:     addr,
+				Stime:       sTime,
+				Etime:       eTime,
+				Timestamp:   sTime,
+				VoteTime:    voteTime,
+				PeriodStime: periodStartTime,
+				PeriodEtime: periodEndTime,
+			}
+			e.fn(event)
+		})
+	})
+	return nil
+}
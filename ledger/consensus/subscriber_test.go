@@ -0,0 +1,180 @@
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
+	"github.com/vitelabs/go-vite/v2/ledger/pool/lock"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+type fixedDposReader struct {
+	*core.GroupInfo
+	members []types.Address
+}
+
+func (r *fixedDposReader) ElectionIndex(index uint64) (*electionResult, error) {
+	return genElectionResult(r.GroupInfo, index, r.members), nil
+}
+
+func (r *fixedDposReader) GetInfo() *core.GroupInfo {
+	return r.GroupInfo
+}
+
+func (r *fixedDposReader) GenProofTime(index uint64) time.Time {
+	stime, _ := r.Index2Time(index)
+	return stime
+}
+
+func (r *fixedDposReader) VerifyProducer(address types.Address, t time.Time) (bool, error) {
+	return true, nil
+}
+
+func newFixedDposReader(t *testing.T, genesis time.Time, n int) *fixedDposReader {
+	var members []types.Address
+	for i := 0; i < n; i++ {
+		addr, _, err := types.CreateAddress()
+		assert.NoError(t, err)
+		members = append(members, addr)
+	}
+	info := core.NewGroupInfo(genesis, types.ConsensusGroupInfo{
+		Gid:       types.SNAPSHOT_GID,
+		NodeCount: uint8(n),
+		Interval:  1,
+		PerCount:  3,
+		Repeat:    1,
+	})
+	return &fixedDposReader{GroupInfo: info, members: members}
+}
+
+func TestConsensusSubscriber_Replay(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	reader := newFixedDposReader(t, now.Add(-time.Hour), 2)
+
+	sub := newConsensusSubscriber()
+	sub.enableReplay(time.Second*30, func(gid types.Gid) (DposReader, error) {
+		return reader, nil
+	}, &lock.EasyImpl{}, log15.New())
+
+	var events []Event
+	e := &subscribeEvent{addr: &reader.members[0], gid: types.SNAPSHOT_GID, fn: func(e Event) {
+		events = append(events, e)
+	}}
+	sub.replay(e, now)
+
+	// two members with 3 blocks each, half of the 30 slots are for members[0]
+	assert.Equal(t, 15, len(events))
+	for _, v := range events {
+		assert.True(t, v.Replayed)
+		assert.Equal(t, reader.members[0], v.Address)
+		assert.True(t, v.Stime.Before(now))
+		assert.False(t, v.Stime.Before(now.Add(-time.Second*30)))
+	}
+}
+
+func TestSubscriberPuppet_TriggerContractMineEvent(t *testing.T) {
+	reader := newFixedDposReader(t, time.Now().Add(-time.Hour), 2)
+	gid := types.DataToGid([]byte("contract"))
+
+	puppet := newSubscriberPuppet(newConsensusSubscriber(), reader, func(g types.Gid) (DposReader, error) {
+		assert.Equal(t, gid, g)
+		return reader, nil
+	})
+	assert.Error(t, puppet.TriggerContractMineEvent(types.SNAPSHOT_GID, reader.members[0]))
+
+	ch := make(chan Event, 1)
+	puppet.Subscribe(types.DELEGATE_GID, "contract", &reader.members[0], func(e Event) {
+		ch <- e
+	})
+	puppet.Subscribe(types.DELEGATE_GID, "other", &reader.members[1], func(e Event) {
+		t.Error("unexpected event for other address")
+	})
+	assert.NoError(t, puppet.TriggerContractMineEvent(gid, reader.members[0]))
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, gid, e.Gid)
+		assert.Equal(t, reader.members[0], e.Address)
+		assert.Equal(t, time.Second, e.Etime.Sub(e.Stime))
+		assert.False(t, e.Stime.Before(e.PeriodStime))
+		assert.True(t, e.Etime.Before(e.PeriodEtime) || e.Etime.Equal(e.PeriodEtime))
+	case <-time.After(time.Second):
+		t.Fatal("no event triggered")
+	}
+}
+
+func TestAutoMiner(t *testing.T) {
+	reader := newFixedDposReader(t, time.Now().Add(-time.Hour), 1)
+
+	_, err := NewAutoMiner(newConsensusSubscriber(), reader.members[0], 0, nil)
+	assert.Error(t, err)
+
+	puppet := newSubscriberPuppet(newConsensusSubscriber(), reader, nil)
+	_, err = NewAutoMiner(puppet, reader.members[0], time.Millisecond, nil)
+	assert.Error(t, err)
+
+	ch := make(chan Event, 10)
+	puppet.Subscribe(types.SNAPSHOT_GID, "snapshot", &reader.members[0], func(e Event) {
+		ch <- e
+	})
+
+	pending := true
+	miner, err := NewAutoMiner(puppet, reader.members[0], 0, func() bool {
+		return pending
+	})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	assert.True(t, miner.shouldMine(now))
+	miner.mine(now)
+	// rate limited by the min gap
+	assert.False(t, miner.shouldMine(now.Add(time.Millisecond*500)))
+	assert.True(t, miner.shouldMine(now.Add(time.Second)))
+	pending = false
+	assert.False(t, miner.shouldMine(now.Add(time.Second)))
+
+	select {
+	case e := <-ch:
+		assert.Equal(t, reader.members[0], e.Address)
+	case <-time.After(time.Second):
+		t.Fatal("no event triggered")
+	}
+
+	miner.interval = time.Second * 3
+	assert.False(t, miner.shouldMine(now.Add(time.Second*2)))
+	assert.True(t, miner.shouldMine(now.Add(time.Second*3)))
+}
+
+func TestLivenessTracker_Record(t *testing.T) {
+	reader := newFixedDposReader(t, time.Now().Add(-time.Hour), 2)
+	tracker := newLivenessTracker(nil, reader, log15.New())
+
+	a0, a1 := reader.members[0], reader.members[1]
+	tracker.record(1, a0, true)
+	tracker.record(1, a0, false)
+	tracker.record(1, a1, true)
+	tracker.record(2, a1, false)
+
+	result := tracker.read(1, 1)
+	assert.Equal(t, 2, len(result))
+	for _, v := range result {
+		assert.Equal(t, uint64(1), v.Index)
+		if v.Address == a0 {
+			assert.Equal(t, uint64(1), v.Produced)
+			assert.Equal(t, uint64(1), v.Missed)
+		} else {
+			assert.Equal(t, uint64(1), v.Produced)
+			assert.Equal(t, uint64(0), v.Missed)
+		}
+	}
+	assert.Equal(t, 3, len(tracker.read(0, 10)))
+
+	// old rounds are dropped
+	tracker.record(maxLivenessRounds+1, a0, true)
+	assert.Equal(t, 0, len(tracker.read(1, 1)))
+	assert.Equal(t, 1, len(tracker.read(2, 2)))
+}
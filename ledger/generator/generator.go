@@ -60,6 +60,16 @@ func NewGenerator(chain vm_db.Chain, consensus Consensus, addr types.Address, la
 	return gen, nil
 }
 
+// NewGeneratorWithTracer is NewGenerator whose vm executes the contract code with the tracer.
+func NewGeneratorWithTracer(chain vm_db.Chain, consensus Consensus, addr types.Address, latestSnapshotBlockHash, prevBlockHash *types.Hash, tracer vm.Tracer) (interfaces.Generator, error) {
+	gen, err := NewGenerator(chain, consensus, addr, latestSnapshotBlockHash, prevBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	gen.(*generator).vm.SetTracer(tracer)
+	return gen, nil
+}
+
 // GenerateWithBlock implements the method to generate a transaction with VM execution results
 // from a block which contains the complete transaction info.
 func (gen *generator) GenerateWithBlock(block *ledger.AccountBlock, fromBlock *ledger.AccountBlock) (*interfaces.GenResult, error) {
@@ -88,7 +98,16 @@ func (gen *generator) GenerateWithMessage(message *interfaces.IncomingMessage, p
 			return nil, errors.New("generate recvBlock failed, cause failed to find its sendBlock")
 		}
 	}
-	return gen.generateBlock(block, fromBlock, producer, signFunc)
+	result, err := gen.generateBlock(block, fromBlock, producer, signFunc)
+	if err != nil {
+		return nil, err
+	}
+	// the next transaction of the account is likely to use the same difficulty
+	if message.Difficulty != nil && result.VMBlock != nil && result.Err == nil {
+		next := result.VMBlock.AccountBlock
+		pow.Precompute(message.Difficulty, types.DataHash(append(next.AccountAddress.Bytes(), next.Hash.Bytes()...)))
+	}
+	return result, nil
 }
 
 // GenerateWithOnRoad implements the method to generate a transaction with VM execution results
@@ -128,11 +147,19 @@ func (gen *generator) generateBlock(block *ledger.AccountBlock, fromBlock *ledge
 			return nil, fmt.Errorf("vmDb's latestSnapshotBlock is nil")
 		}
 
-		limitSb, err := gen.chain.GetSnapshotBlockByContractMeta(block.AccountAddress, fromBlock.Hash)
-		if err != nil {
-			return nil, fmt.Errorf("GetSnapshotBlockByContractMeta failed", "err", err)
+		// the meta of a built-in contract not in use at the snapshot block is not read by the latest snapshot block
+		inUse := !ledger.IsForkedBuiltinContractAddr(block.AccountAddress) ||
+			ledger.IsBuiltinContractAddrInUse(block.AccountAddress, latestSb.Height)
+
+		var limitSb *ledger.SnapshotBlock
+		if inUse {
+			var err error
+			limitSb, err = gen.chain.GetSnapshotBlockByContractMeta(block.AccountAddress, fromBlock.Hash)
+			if err != nil {
+				return nil, fmt.Errorf("GetSnapshotBlockByContractMeta failed", "err", err)
+			}
 		}
-		if upgrade.IsSeedUpgrade(latestSb.Height) {
+		if inUse && upgrade.IsSeedUpgrade(latestSb.Height) {
 			limitSeedSb, err := gen.chain.GetSeedConfirmedSnapshotBlock(block.AccountAddress, fromBlock.Hash)
 			if err != nil {
 				return nil, fmt.Errorf("GetSeedConfirmedSnapshotBlock failed", "err", err)
@@ -226,7 +253,7 @@ func (gen *generator) packReceiveBlockWithSend(sendBlock *ledger.AccountBlock, d
 	recvBlock.Height = preHeight + 1
 
 	if difficulty != nil {
-		nonce, err := pow.GetPowNonce(difficulty, types.DataHash(append(sendBlock.ToAddress.Bytes(), prevHash.Bytes()...)))
+		nonce, err := pow.DefaultProvider().Nonce(difficulty, types.DataHash(append(sendBlock.ToAddress.Bytes(), prevHash.Bytes()...)))
 		if err != nil {
 			return nil, err
 		}
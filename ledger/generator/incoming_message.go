@@ -114,7 +114,7 @@ func IncomingMessageToBlock(vmDb interfaces.VmDb, im *interfaces.IncomingMessage
 	}
 	// Difficulty,Nonce
 	if im.Difficulty != nil {
-		nonce, err := pow.GetPowNonce(im.Difficulty, types.DataHash(append(block.AccountAddress.Bytes(), block.PrevHash.Bytes()...)))
+		nonce, err := pow.DefaultProvider().Nonce(im.Difficulty, types.DataHash(append(block.AccountAddress.Bytes(), block.PrevHash.Bytes()...)))
 		if err != nil {
 			return nil, err
 		}
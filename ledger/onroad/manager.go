@@ -45,6 +45,8 @@ type Manager struct {
 
 	lastProducerAccEvent *producerevent.AccountStartEvent
 
+	orderPolicy OrderPolicy
+
 	log log15.Logger
 }
 
@@ -62,6 +64,25 @@ func NewManager(net netReader, pool pool, producer producer, consensus generator
 	return m
 }
 
+// SetOrderPolicy sets the policy of the order in which contract workers receive the onroad blocks of callers,
+// it must be called before the workers start.
+func (manager *Manager) SetOrderPolicy(name string, priorityCallers []types.Address) error {
+	policy, err := NewOrderPolicy(name, priorityCallers, manager.callerQuota)
+	if err != nil {
+		return err
+	}
+	manager.orderPolicy = policy
+	return nil
+}
+
+func (manager *Manager) callerQuota(addr types.Address) uint64 {
+	_, quota, err := manager.Chain().GetStakeQuota(addr)
+	if err != nil || quota == nil {
+		return 0
+	}
+	return quota.Current()
+}
+
 // Init is used to load all onroad into pool cache,
 // for super node generating new contract receive block
 // and for verifier module verifying the sequence of contract receive.
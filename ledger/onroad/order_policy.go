@@ -0,0 +1,123 @@
+package onroad
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// names of the order policies
+const (
+	OrderPolicyDefault    = "default"
+	OrderPolicyDifficulty = "difficulty"
+	OrderPolicyQuota      = "quota"
+	OrderPolicyAge        = "age"
+	OrderPolicyFee        = "fee"
+)
+
+// PendingBlock is the front onroad block of a caller waiting to be received by the contract.
+type PendingBlock struct {
+	*ledger.AccountBlock
+	// AddTime is the time the block is pulled into the pending cache of the contract worker
+	AddTime time.Time
+}
+
+// OrderPolicy decides which of the front onroad blocks of the callers of a contract is received first.
+type OrderPolicy interface {
+	// Less reports whether a is received before b.
+	Less(a, b *PendingBlock) bool
+}
+
+// NewOrderPolicy creates the policy by name, blocks of priorityCallers are always received first,
+// quota returns the stake quota of a caller for the quota policy.
+func NewOrderPolicy(name string, priorityCallers []types.Address, quota func(addr types.Address) uint64) (OrderPolicy, error) {
+	var policy OrderPolicy
+	switch name {
+	case "", OrderPolicyDefault:
+		policy = defaultOrder{}
+	case OrderPolicyDifficulty:
+		policy = difficultyOrder{}
+	case OrderPolicyQuota:
+		policy = quotaOrder{quota: quota}
+	case OrderPolicyAge:
+		policy = ageOrder{}
+	case OrderPolicyFee:
+		policy = feeOrder{}
+	default:
+		return nil, fmt.Errorf("unknown onroad order policy %q", name)
+	}
+	if len(priorityCallers) == 0 {
+		return policy, nil
+	}
+	callers := make(map[types.Address]struct{}, len(priorityCallers))
+	for _, addr := range priorityCallers {
+		callers[addr] = struct{}{}
+	}
+	return priorityOrder{callers: callers, next: policy}, nil
+}
+
+// defaultOrder keeps the order of the pending cache, which is random among callers
+type defaultOrder struct{}
+
+func (defaultOrder) Less(a, b *PendingBlock) bool {
+	return false
+}
+
+// difficultyOrder receives the block with the higher PoW difficulty first
+type difficultyOrder struct{}
+
+func (difficultyOrder) Less(a, b *PendingBlock) bool {
+	return compareBigInt(a.Difficulty, b.Difficulty) > 0
+}
+
+// quotaOrder receives the block of the caller with more stake quota first
+type quotaOrder struct {
+	quota func(addr types.Address) uint64
+}
+
+func (o quotaOrder) Less(a, b *PendingBlock) bool {
+	return o.quota(a.AccountAddress) > o.quota(b.AccountAddress)
+}
+
+// ageOrder receives the block waiting for the longest time first
+type ageOrder struct{}
+
+func (ageOrder) Less(a, b *PendingBlock) bool {
+	return a.AddTime.Before(b.AddTime)
+}
+
+// feeOrder receives the block with the higher fee first
+type feeOrder struct{}
+
+func (feeOrder) Less(a, b *PendingBlock) bool {
+	return compareBigInt(a.Fee, b.Fee) > 0
+}
+
+// priorityOrder receives the blocks of the given callers first, others are ordered by next
+type priorityOrder struct {
+	callers map[types.Address]struct{}
+	next    OrderPolicy
+}
+
+func (o priorityOrder) Less(a, b *PendingBlock) bool {
+	_, pa := o.callers[a.AccountAddress]
+	_, pb := o.callers[b.AccountAddress]
+	if pa != pb {
+		return pa
+	}
+	return o.next.Less(a, b)
+}
+
+// compareBigInt compares two numbers, nil is regarded as zero
+func compareBigInt(a, b *big.Int) int {
+	if a == nil {
+		a = big.NewInt(0)
+	}
+	if b == nil {
+		b = big.NewInt(0)
+	}
+	return a.Cmp(b)
+}
@@ -0,0 +1,64 @@
+package onroad
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func TestOrderPolicy(t *testing.T) {
+	caller1, _ := types.BytesToAddress([]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0})
+	caller2, _ := types.BytesToAddress([]byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 0})
+	caller3, _ := types.BytesToAddress([]byte{3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 0})
+	quotas := map[types.Address]uint64{caller1: 10, caller2: 30, caller3: 20}
+	quota := func(addr types.Address) uint64 {
+		return quotas[addr]
+	}
+
+	now := time.Now()
+	blocks := []*ledger.AccountBlock{
+		{AccountAddress: caller1, Height: 1, Difficulty: big.NewInt(100), Fee: big.NewInt(0)},
+		{AccountAddress: caller2, Height: 1, Fee: big.NewInt(5)},
+		{AccountAddress: caller3, Height: 1, Difficulty: big.NewInt(50), Fee: big.NewInt(1)},
+	}
+	addTimes := []time.Time{now.Add(-time.Second), now, now.Add(-time.Minute)}
+
+	tests := []struct {
+		policy   string
+		priority []types.Address
+		expected []types.Address
+	}{
+		{OrderPolicyDifficulty, nil, []types.Address{caller1, caller3, caller2}},
+		{OrderPolicyQuota, nil, []types.Address{caller2, caller3, caller1}},
+		{OrderPolicyAge, nil, []types.Address{caller3, caller1, caller2}},
+		{OrderPolicyFee, nil, []types.Address{caller2, caller3, caller1}},
+		{OrderPolicyFee, []types.Address{caller1}, []types.Address{caller1, caller2, caller3}},
+	}
+	for _, test := range tests {
+		policy, err := NewOrderPolicy(test.policy, test.priority, quota)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := newCallerPendingMap()
+		p.policy = policy
+		for i, b := range blocks {
+			p.addPendingMap(b)
+			p.pmap[b.AccountAddress].Front().Value.(*PendingBlock).AddTime = addTimes[i]
+		}
+		for i, expected := range test.expected {
+			if b := p.getOnePending(); b == nil || b.AccountAddress != expected {
+				t.Fatalf("policy %v priority %v, unexpected block %v: %v", test.policy, test.priority, i, b)
+			}
+		}
+		if b := p.getOnePending(); b != nil {
+			t.Fatalf("policy %v, unexpected block %v", test.policy, b)
+		}
+	}
+
+	if _, err := NewOrderPolicy("unknown", nil, quota); err == nil {
+		t.Fatal("expected error of unknown policy")
+	}
+}
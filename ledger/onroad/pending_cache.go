@@ -3,6 +3,7 @@ package onroad
 import (
 	"container/list"
 	"sync"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
@@ -14,6 +15,9 @@ type callerPendingMap struct {
 	pmap         map[types.Address]*list.List
 	inferiorList map[types.Address]inferiorState
 
+	// policy selects the caller whose front block is received first, nil means the order of pmap
+	policy OrderPolicy
+
 	addrMutex sync.RWMutex
 }
 
@@ -54,14 +58,26 @@ func (p *callerPendingMap) getOnePending() *ledger.AccountBlock {
 	p.addrMutex.Lock()
 	defer p.addrMutex.Unlock()
 
-	for _, list := range p.pmap {
-		if ele := list.Front(); ele != nil {
-			front := ele.Value.(*ledger.AccountBlock)
-			list.Remove(ele)
-			return front
+	var selected *list.List
+	var front *PendingBlock
+	for _, l := range p.pmap {
+		ele := l.Front()
+		if ele == nil {
+			continue
+		}
+		candidate := ele.Value.(*PendingBlock)
+		if front == nil || (p.policy != nil && p.policy.Less(candidate, front)) {
+			selected, front = l, candidate
 		}
+		if p.policy == nil {
+			break
+		}
+	}
+	if front == nil {
+		return nil
 	}
-	return nil
+	selected.Remove(selected.Front())
+	return front.AccountBlock
 }
 
 func (p *callerPendingMap) addPendingMap(sendBlock *ledger.AccountBlock) (isExist bool) {
@@ -69,11 +85,12 @@ func (p *callerPendingMap) addPendingMap(sendBlock *ledger.AccountBlock) (isExis
 	defer p.addrMutex.Unlock()
 
 	caller := sendBlock.AccountAddress
+	pending := &PendingBlock{AccountBlock: sendBlock, AddTime: time.Now()}
 
 	value, ok := p.pmap[caller]
 	if !ok || value == nil {
 		l := list.New()
-		l.PushBack(sendBlock)
+		l.PushBack(pending)
 		p.pmap[caller] = l
 		return false
 	}
@@ -81,17 +98,17 @@ func (p *callerPendingMap) addPendingMap(sendBlock *ledger.AccountBlock) (isExis
 	l := p.pmap[caller]
 	var ele *list.Element
 	if ele = l.Back(); ele != nil {
-		pre := ele.Value.(*ledger.AccountBlock)
+		pre := ele.Value.(*PendingBlock)
 		if pre.Height == sendBlock.Height {
 			return true
 		}
 		if pre.Height < sendBlock.Height {
-			l.PushBack(sendBlock)
+			l.PushBack(pending)
 			return false
 		}
 	}
 	l.Init()
-	l.PushBack(sendBlock)
+	l.PushBack(pending)
 	return false
 }
 
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/generator"
 	"github.com/vitelabs/go-vite/v2/log15"
 	"github.com/vitelabs/go-vite/v2/vm/quota"
@@ -106,7 +108,8 @@ func (tp *ContractTaskProcessor) processOneAddress(task *contractTask) (canConti
 		blog.Error(fmt.Sprintf("NewGenerator failed, err:%v", err))
 		return true
 	}
-	genResult, err := gen.GenerateWithOnRoad(sBlock, &tp.worker.address, tp.worker.manager.coinbase.Sign, nil)
+	// the block is signed after generated, a BlockSigner signs by the full block
+	genResult, err := gen.GenerateWithOnRoad(sBlock, &tp.worker.address, nil, nil)
 
 	// judge generator result
 	if err != nil || genResult == nil {
@@ -124,6 +127,10 @@ func (tp *ContractTaskProcessor) processOneAddress(task *contractTask) (canConti
 
 	// judge vm result
 	if genResult.VMBlock != nil {
+		if err := interfaces.SignAccountBlock(tp.worker.manager.coinbase, genResult.VMBlock.AccountBlock); err != nil {
+			blog.Error(fmt.Sprintf("sign block failed, err:%v", err))
+			return true
+		}
 		blog.Info(fmt.Sprintf("insertBlockToPool %v, s[%v, p(%v,%v)]", genResult.VMBlock.AccountBlock.Hash, sBlock.Hash, completeBlockHeight, completeBlockHash))
 
 		if err := tp.worker.manager.insertBlockToPool(genResult.VMBlock); err != nil {
@@ -140,7 +147,12 @@ func (tp *ContractTaskProcessor) processOneAddress(task *contractTask) (canConti
 	} else {
 		if genResult.IsRetry {
 			blog.Info("genResult.IsRetry true")
-			if !types.IsBuiltinContractAddrInUseWithoutQuota(task.Addr) {
+			sb, err := gen.GetVMDB().LatestSnapshotBlock()
+			if err != nil {
+				blog.Error(fmt.Sprintf("failed to get the snapshot block of the vm db, err:%v", err))
+				return true
+			}
+			if !ledger.IsBuiltinContractAddrInUseWithoutQuota(task.Addr, sb.Height) {
 				_, q, err := tp.worker.manager.Chain().GetStakeQuota(task.Addr)
 				if err != nil || q == nil {
 					blog.Error(fmt.Sprintf("failed to get stake quota, err:%v", err))
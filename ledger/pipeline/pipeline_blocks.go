@@ -69,6 +69,11 @@ func NewBlocksPipeline(fromDir string, height uint64) (*blocks_pipeline, error)
 	return newBlocksPipelineWithRun(fromDir, height, chain_block.FixFileSize)
 }
 
+// NewChunksPipeline creates a pipeline which feeds the chunks from the channel, the channel should be closed after the last chunk
+func NewChunksPipeline(chunkCh chan *core.SnapshotChunk) *blocks_pipeline {
+	return &blocks_pipeline{chunkCh: chunkCh}
+}
+
 func (p *blocks_pipeline) Peek() *net.Chunk {
 	if p.curChunk != nil {
 		return p.curChunk
@@ -0,0 +1,205 @@
+package pool
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// journal is a write-ahead file of the account blocks received into the pool, so that the blocks not yet
+// inserted into the chain survive a restart:
+//
+//	record: size uint32 | crc32(payload) uint32 | payload
+//	payload: serialized account block
+//
+// a torn or corrupted record ends the journal, the records after it are dropped.
+type journal struct {
+	path    string
+	maxSize int64
+
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	size int64
+	full bool
+
+	log log15.Logger
+}
+
+const journalRecordHeaderSize = 8
+
+// DefaultJournalMaxSize is the size cap of the pool journal if none is given
+const DefaultJournalMaxSize = 64 * 1024 * 1024
+
+var errJournalClosed = errors.New("pool journal is closed")
+
+func newJournal(path string, maxSize int64, log log15.Logger) *journal {
+	if maxSize <= 0 {
+		maxSize = DefaultJournalMaxSize
+	}
+	return &journal{path: path, maxSize: maxSize, log: log}
+}
+
+// load reads the blocks of the journal, it stops at the first corrupted record
+func (j *journal) load() ([]*ledger.AccountBlock, error) {
+	file, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	header := make([]byte, journalRecordHeaderSize)
+	var blocks []*ledger.AccountBlock
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err != io.EOF {
+				j.log.Warn("pool journal is truncated", "records", len(blocks), "err", err)
+			}
+			return blocks, nil
+		}
+		size := binary.BigEndian.Uint32(header[:4])
+		if int64(size) > j.maxSize {
+			j.log.Warn("pool journal is corrupted, invalid record size", "records", len(blocks), "size", size)
+			return blocks, nil
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			j.log.Warn("pool journal is truncated", "records", len(blocks), "err", err)
+			return blocks, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+			j.log.Warn("pool journal is corrupted, checksum mismatch", "records", len(blocks))
+			return blocks, nil
+		}
+		block := &ledger.AccountBlock{}
+		if err := block.Deserialize(payload); err != nil {
+			j.log.Warn("pool journal is corrupted, invalid block", "records", len(blocks), "err", err)
+			return blocks, nil
+		}
+		blocks = append(blocks, block)
+	}
+}
+
+// rotate replaces the journal with the given blocks and opens it for appending
+func (j *journal) rotate(blocks []*ledger.AccountBlock) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return err
+	}
+	tmp := j.path + ".new"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(file)
+	var size int64
+	for i, b := range blocks {
+		buf, err := b.Serialize()
+		if err != nil {
+			file.Close()
+			return err
+		}
+		n := int64(journalRecordHeaderSize + len(buf))
+		if size+n > j.maxSize {
+			j.log.Warn("pool journal is full, blocks are dropped", "dropped", len(blocks)-i)
+			break
+		}
+		if err := writeJournalPayload(w, buf); err != nil {
+			file.Close()
+			return err
+		}
+		size += n
+	}
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	if j.file != nil {
+		j.w.Flush()
+		j.file.Close()
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		j.file = nil
+		return err
+	}
+	j.file, err = os.OpenFile(j.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		j.file = nil
+		return err
+	}
+	j.w = bufio.NewWriter(j.file)
+	j.size = size
+	j.full = false
+	return nil
+}
+
+// insert appends a block to the journal, blocks beyond the size cap are dropped until the next rotation
+func (j *journal) insert(block *ledger.AccountBlock) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errJournalClosed
+	}
+	if j.full {
+		return nil
+	}
+	buf, err := block.Serialize()
+	if err != nil {
+		return err
+	}
+	n := int64(journalRecordHeaderSize + len(buf))
+	if j.size+n > j.maxSize {
+		j.full = true
+		j.log.Warn("pool journal is full, blocks are not journaled until the next rotation", "size", j.size)
+		return nil
+	}
+	if err := writeJournalPayload(j.w, buf); err != nil {
+		return err
+	}
+	j.size += n
+	return j.w.Flush()
+}
+
+func (j *journal) close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	j.w.Flush()
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func writeJournalPayload(w io.Writer, payload []byte) error {
+	header := make([]byte, journalRecordHeaderSize)
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
@@ -0,0 +1,98 @@
+package pool
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+func newJournalTestBlock(height uint64) *ledger.AccountBlock {
+	b := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         height,
+		AccountAddress: types.AddressQuota,
+		ToAddress:      types.AddressAsset,
+		Amount:         big.NewInt(1),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            big.NewInt(0),
+	}
+	b.Hash = b.ComputeHash()
+	return b
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pool-journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "pool", "journal")
+
+	j := newJournal(path, 0, log15.New("module", "unittest"))
+	blocks, err := j.load()
+	assert.NoError(t, err)
+	assert.Empty(t, blocks)
+
+	assert.NoError(t, j.rotate([]*ledger.AccountBlock{newJournalTestBlock(1)}))
+	assert.NoError(t, j.insert(newJournalTestBlock(2)))
+	assert.NoError(t, j.insert(newJournalTestBlock(3)))
+	assert.NoError(t, j.close())
+	assert.Equal(t, errJournalClosed, j.insert(newJournalTestBlock(4)))
+
+	blocks, err = j.load()
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 3)
+	for i, b := range blocks {
+		assert.Equal(t, newJournalTestBlock(uint64(i+1)).Hash, b.Hash)
+	}
+
+	// a torn record at the tail is dropped
+	stat, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Truncate(path, stat.Size()-1))
+	blocks, err = j.load()
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 2)
+
+	// a corrupted record ends the journal
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	assert.NoError(t, os.WriteFile(path, data, 0600))
+	blocks, err = j.load()
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+}
+
+func TestJournal_maxSize(t *testing.T) {
+	dir, err := os.MkdirTemp("", "pool-journal")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	buf, err := newJournalTestBlock(2).Serialize()
+	assert.NoError(t, err)
+	// blocks above height 1 have the same size
+	recordSize := int64(journalRecordHeaderSize + len(buf))
+
+	j := newJournal(filepath.Join(dir, "journal"), recordSize*2, log15.New("module", "unittest"))
+	assert.NoError(t, j.rotate([]*ledger.AccountBlock{newJournalTestBlock(2), newJournalTestBlock(3), newJournalTestBlock(4)}))
+	assert.NoError(t, j.insert(newJournalTestBlock(5)))
+	assert.True(t, j.full)
+	blocks, err := j.load()
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 2)
+
+	// rotation makes room again
+	assert.NoError(t, j.rotate(nil))
+	assert.NoError(t, j.insert(newJournalTestBlock(5)))
+	assert.False(t, j.full)
+	assert.NoError(t, j.close())
+	blocks, err = j.load()
+	assert.NoError(t, err)
+	assert.Len(t, blocks, 1)
+}
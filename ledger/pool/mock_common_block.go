@@ -87,6 +87,10 @@ func (*mockCommonBlock) Latency() time.Duration {
 	return time.Second
 }
 
+func (*mockCommonBlock) Age() time.Duration {
+	return time.Second
+}
+
 func (*mockCommonBlock) ShouldFetch() bool {
 	return false
 }
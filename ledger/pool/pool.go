@@ -25,6 +25,11 @@ import (
 	"github.com/vitelabs/go-vite/v2/net"
 )
 
+const poolMetricsCollector = "pool"
+
+var pendingBlocksMetric = monitor.GetOrRegisterGaugeVec("vite_pool_pending_blocks",
+	"number of blocks pending in the pool, partitioned by chain type", "chain")
+
 // Writer is a writer of BlockPool
 type Writer interface {
 	// for normal account
@@ -61,6 +66,9 @@ type Debug interface {
 	Account(addr types.Address) map[string]interface{}
 	SnapshotChainDetail(chainID string, height uint64) map[string]interface{}
 	AccountChainDetail(addr types.Address, chainID string, height uint64) map[string]interface{}
+	Status() *Status
+	SnapshotStatus() *ChainStatus
+	AccountStatus(addr types.Address) *ChainStatus
 }
 
 // BlockPool is responsible for organizing blocks and inserting it into the chain
@@ -71,6 +79,14 @@ type BlockPool interface {
 	Debug
 	Pipeline
 
+	// SetJournal persists the account blocks received into the pool to path and replays them at start,
+	// it must be called before Start
+	SetJournal(path string, maxSize int64)
+
+	// RollbackSnapshotTo rolls the snapshot chain back to height, it's for the operator.
+	// check is called under the insert lock before rolling back, the rollback is refused if it returns an error.
+	RollbackSnapshotTo(height uint64, check func() error) error
+
 	Start()
 	Stop()
 	Init(s syncer,
@@ -88,6 +104,7 @@ type commonBlock interface {
 	forkVersion() uint64
 	Source() types.BlockSource
 	Latency() time.Duration
+	Age() time.Duration
 	ShouldFetch() bool
 	ReferHashes() ([]types.Hash, []types.Hash, *types.Hash)
 }
@@ -120,6 +137,11 @@ func (fb *forkBlock) Latency() time.Duration {
 	return time.Duration(0)
 }
 
+// Age is the time since the block was added to the pool, whatever the source is
+func (fb *forkBlock) Age() time.Duration {
+	return time.Now().Sub(fb.nTime)
+}
+
 func (fb *forkBlock) ShouldFetch() bool {
 	if fb.Source() != types.RemoteBroadcast {
 		return true
@@ -167,6 +189,8 @@ type pool struct {
 	hashBlacklist Blacklist
 	cs            consensus.Consensus
 	printer       *snapshotPrinter
+
+	journal *journal
 }
 
 func (pl *pool) Snapshot() map[string]interface{} {
@@ -286,6 +310,10 @@ func (pl *pool) Start() {
 	defer pl.log.Info("pool started.")
 	pl.closed = make(chan struct{})
 
+	if pl.journal != nil {
+		pl.replayJournal()
+	}
+
 	pl.accountSubID = pl.sync.SubscribeAccountBlock(pl.AddAccountBlock)
 	pl.snapshotSubID = pl.sync.SubscribeSnapshotBlock(pl.AddSnapshotBlock)
 
@@ -302,10 +330,25 @@ func (pl *pool) Start() {
 		pl.worker.work()
 	})
 	pl.printer.start()
+	if pl.journal != nil {
+		common.Go(func() {
+			pl.wg.Add(1)
+			defer pl.wg.Done()
+			pl.loopRotateJournal()
+		})
+	}
+	monitor.RegisterCollector(poolMetricsCollector, pl.reportMetrics)
+}
+
+func (pl *pool) reportMetrics() {
+	pendingBlocksMetric.With("snapshot").Set(float64(pl.SnapshotPendingNum()))
+	account, _ := new(big.Float).SetInt(pl.AccountPendingNum()).Float64()
+	pendingBlocksMetric.With("account").Set(account)
 }
 func (pl *pool) Stop() {
 	pl.log.Info("pool stop.")
 	defer pl.log.Info("pool stopped.")
+	monitor.UnregisterCollector(poolMetricsCollector)
 	pl.bc.UnRegister(pl.printer)
 	pl.sync.UnsubscribeAccountBlock(pl.accountSubID)
 	pl.accountSubID = 0
@@ -317,6 +360,10 @@ func (pl *pool) Stop() {
 	pl.newAccBlockCond.Stop()
 	pl.newSnapshotBlockCond.Stop()
 	pl.wg.Wait()
+	if pl.journal != nil {
+		pl.rotateJournal()
+		pl.journal.close()
+	}
 }
 
 func (pl *pool) AddSnapshotBlock(block *ledger.SnapshotBlock, source types.BlockSource) {
@@ -369,6 +416,15 @@ func (pl *pool) AddAccountBlock(address types.Address, block *ledger.AccountBloc
 	if pl.bc.IsGenesisAccountBlock(block.Hash) {
 		return
 	}
+	if pl.journal != nil {
+		if err := pl.journal.insert(block); err != nil {
+			pl.log.Error("journal account block fail", "err", err, "hash", block.Hash)
+		}
+	}
+	pl.addAccountBlock(address, block, source)
+}
+
+func (pl *pool) addAccountBlock(address types.Address, block *ledger.AccountBlock, source types.BlockSource) {
 	ac := pl.selfPendingAc(address)
 	ac.addBlock(newAccountPoolBlock(block, nil, pl.version, source))
 
@@ -2,6 +2,7 @@ package pool
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -11,6 +12,9 @@ import (
 	"github.com/vitelabs/go-vite/v2/ledger/pool/tree"
 )
 
+// rollbackBlacklistTimeout is how long the blocks rolled back by the operator are not inserted again
+const rollbackBlacklistTimeout = 30 * time.Minute
+
 func (pl *pool) checkFork() {
 	longest, longestH, err := pl.pendingSc.checkFork()
 	if err != nil {
@@ -146,6 +150,59 @@ func (pl *pool) snapshotRollback(longest tree.Branch, keyPoint *snapshotPoolBloc
 	return nil
 }
 
+// RollbackSnapshotTo rolls the snapshot chain back to height, the snapshot blocks higher than height and the account
+// blocks they confirm are deleted from the chain. The blocks deleted are put back to the pool as the fork rollback
+// does, and added to the blacklist for rollbackBlacklistTimeout, so they are not inserted again before the longest
+// fork is chosen from the blocks of the peers.
+func (pl *pool) RollbackSnapshotTo(height uint64, check func() error) error {
+	pl.LockInsert()
+	defer pl.UnLockInsert()
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	pl.LockRollback()
+	defer pl.UnLockRollback()
+	defer pl.rollbackVersion.Inc()
+	defer pl.version.Inc()
+
+	latest := pl.bc.GetLatestSnapshotBlock()
+	if height < types.GenesisHeight || height >= latest.Height {
+		return errors.Errorf("rollback height %d must be in [%d, %d)", height, types.GenesisHeight, latest.Height)
+	}
+	pl.log.Warn("rollback snapshot chain by operator", "height", height, "latestHeight", latest.Height, "latestHash", latest.Hash)
+
+	snapshots, accounts, e := pl.pendingSc.rw.delToHeight(height + 1)
+	if e != nil {
+		return e
+	}
+	for _, block := range snapshots {
+		pl.hashBlacklist.AddAddTimeout(block.Hash(), rollbackBlacklistTimeout)
+	}
+	for _, blocks := range accounts {
+		for _, block := range blocks {
+			pl.hashBlacklist.AddAddTimeout(block.Hash(), rollbackBlacklistTimeout)
+		}
+	}
+
+	if len(snapshots) > 0 {
+		err := pl.pendingSc.rollbackCurrent(snapshots)
+		if err != nil {
+			return err
+		}
+		pl.pendingSc.checkCurrent()
+	}
+
+	if len(accounts) > 0 {
+		err := pl.ForkAccounts(accounts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type irreversibleInfo struct {
 	point      *ledger.SnapshotBlock
 	proofPoint *ledger.SnapshotBlock
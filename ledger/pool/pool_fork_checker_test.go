@@ -1 +1,22 @@
 package pool
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPool_RollbackSnapshotTo_check(t *testing.T) {
+	pl := &pool{}
+	errProducing := errors.New("producing")
+	checked := false
+	err := pl.RollbackSnapshotTo(10, func() error {
+		checked = true
+		return errProducing
+	})
+	if !checked || err != errProducing {
+		t.Fatalf("expected the rollback refused by the check, got %v", err)
+	}
+	// the insert lock is released after the refusal
+	pl.LockInsert()
+	pl.UnLockInsert()
+}
@@ -0,0 +1,204 @@
+package pool
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/pool/tree"
+)
+
+// BlockStatus is a block waiting in the pool
+type BlockStatus struct {
+	Hash     types.Hash
+	PrevHash types.Hash
+	Height   uint64
+	Source   types.BlockSource
+	Age      time.Duration
+}
+
+// BranchStatus is a branch of the chain tree or a snippet waiting to be linked to the tree
+type BranchStatus struct {
+	ID         string
+	TailHeight uint64
+	TailHash   types.Hash
+	HeadHeight uint64
+	HeadHash   types.Hash
+	Current    bool
+}
+
+// ChainSummary counts the blocks of an account chain or the snapshot chain in the pool
+type ChainSummary struct {
+	ID      string
+	Address *types.Address // nil for the snapshot chain
+
+	// PendingNum is the number of blocks in the current branch waiting to be inserted into the chain
+	PendingNum uint64
+	// FreeNum is the number of orphaned blocks waiting for their previous block
+	FreeNum int
+	// CompoundNum is the number of blocks in snippets waiting to be linked to the tree
+	CompoundNum int
+	SnippetNum  int
+	BranchNum   int
+	// OldestAge is the age of the oldest block in the pool, zero if the pool is empty
+	OldestAge time.Duration
+}
+
+func (cs *ChainSummary) empty() bool {
+	return cs.PendingNum == 0 && cs.FreeNum == 0 && cs.CompoundNum == 0
+}
+
+// ChainStatus is the detail of an account chain or the snapshot chain in the pool,
+// the pending blocks of the snapshot chain are the candidates to be inserted
+type ChainStatus struct {
+	ChainSummary
+
+	Pending  []*BlockStatus
+	Free     []*BlockStatus
+	Snippets []*BranchStatus
+	Branches []*BranchStatus
+}
+
+// Status is a summary of the pool, only account chains with blocks in the pool are listed
+type Status struct {
+	Snapshot           *ChainSummary
+	Accounts           []*ChainSummary
+	AccountPendingNum  uint64
+	AccountFreeNum     int
+	AccountCompoundNum int
+	OldestAccountAge   time.Duration
+}
+
+func newBlockStatus(b commonBlock) *BlockStatus {
+	return &BlockStatus{
+		Hash:     b.Hash(),
+		PrevHash: b.PrevHash(),
+		Height:   b.Height(),
+		Source:   b.Source(),
+		Age:      b.Age(),
+	}
+}
+
+func sortBlockStatus(list []*BlockStatus) {
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Height < list[j].Height
+	})
+}
+
+func (bcp *BCPool) status() *ChainStatus {
+	bcp.blockpool.pendingMu.Lock()
+	defer bcp.blockpool.pendingMu.Unlock()
+
+	bcp.chainHeadMu.Lock()
+	defer bcp.chainHeadMu.Unlock()
+
+	bcp.chainTailMu.Lock()
+	defer bcp.chainTailMu.Unlock()
+
+	bp := bcp.blockpool
+	cp := bcp.chainpool
+	result := &ChainStatus{ChainSummary: ChainSummary{ID: bcp.ID}}
+
+	oldest := func(age time.Duration) {
+		if age > result.OldestAge {
+			result.OldestAge = age
+		}
+	}
+
+	main := cp.tree.Main()
+	tailHeight, _ := main.TailHH()
+	headHeight, _ := main.HeadHH()
+	for h := tailHeight + 1; h <= headHeight; h++ {
+		if k := main.GetKnot(h, false); k != nil {
+			b := newBlockStatus(k.(commonBlock))
+			oldest(b.Age)
+			result.Pending = append(result.Pending, b)
+		}
+	}
+
+	for _, v := range bp.freeBlocks {
+		b := newBlockStatus(v)
+		oldest(b.Age)
+		result.Free = append(result.Free, b)
+	}
+	sortBlockStatus(result.Free)
+
+	for _, v := range cp.snippetChains {
+		result.Snippets = append(result.Snippets, &BranchStatus{
+			ID:         v.id(),
+			TailHeight: v.tailHeight,
+			TailHash:   v.tailHash,
+			HeadHeight: v.headHeight,
+			HeadHash:   v.headHash,
+		})
+		for _, b := range v.heightBlocks {
+			oldest(b.Age())
+		}
+		result.CompoundNum += len(v.heightBlocks)
+	}
+	sort.Slice(result.Snippets, func(i, j int) bool {
+		return result.Snippets[i].TailHeight < result.Snippets[j].TailHeight
+	})
+
+	for _, v := range cp.tree.Branches() {
+		if v.Type() != tree.Normal {
+			continue
+		}
+		bs := &BranchStatus{ID: v.ID(), Current: v.ID() == main.ID()}
+		bs.TailHeight, bs.TailHash = v.TailHH()
+		bs.HeadHeight, bs.HeadHash = v.HeadHH()
+		result.Branches = append(result.Branches, bs)
+	}
+	sort.Slice(result.Branches, func(i, j int) bool {
+		return result.Branches[i].TailHeight < result.Branches[j].TailHeight
+	})
+
+	result.PendingNum = uint64(len(result.Pending))
+	result.FreeNum = len(result.Free)
+	result.SnippetNum = len(result.Snippets)
+	result.BranchNum = len(result.Branches)
+	return result
+}
+
+// Status returns the summary of the snapshot chain and all account chains with blocks in the pool
+func (pl *pool) Status() *Status {
+	result := &Status{Snapshot: &pl.SnapshotStatus().ChainSummary}
+	pl.pendingAc.Range(func(k, v interface{}) bool {
+		cs := pl.accountStatus(k.(types.Address), v.(*accountPool))
+		if cs.empty() {
+			return true
+		}
+		result.Accounts = append(result.Accounts, &cs.ChainSummary)
+		result.AccountPendingNum += cs.PendingNum
+		result.AccountFreeNum += cs.FreeNum
+		result.AccountCompoundNum += cs.CompoundNum
+		if cs.OldestAge > result.OldestAccountAge {
+			result.OldestAccountAge = cs.OldestAge
+		}
+		return true
+	})
+	sort.Slice(result.Accounts, func(i, j int) bool {
+		return result.Accounts[i].OldestAge > result.Accounts[j].OldestAge
+	})
+	return result
+}
+
+// SnapshotStatus returns the detail of the snapshot chain in the pool
+func (pl *pool) SnapshotStatus() *ChainStatus {
+	return pl.pendingSc.status()
+}
+
+// AccountStatus returns the detail of an account chain in the pool, nil if the pool has never seen the account
+func (pl *pool) AccountStatus(addr types.Address) *ChainStatus {
+	v, ok := pl.pendingAc.Load(addr)
+	if !ok {
+		return nil
+	}
+	return pl.accountStatus(addr, v.(*accountPool))
+}
+
+func (pl *pool) accountStatus(addr types.Address, p *accountPool) *ChainStatus {
+	result := p.status()
+	result.Address = &addr
+	return result
+}
@@ -0,0 +1,63 @@
+package pool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/pool/tree"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+func TestBCPool_status(t *testing.T) {
+	tr := tree.NewTree()
+	diskChain := tree.NewMockBranchRoot()
+	for i := 0; i < 3; i++ {
+		height, hash := diskChain.HeadHH()
+		diskChain.AddHead(newMockCommonBlockByHH(height, hash, "root"))
+	}
+
+	cp := &chainPool{
+		poolID: "unittest",
+		tree:   tr,
+		log:    log15.New("module", "unittest"),
+	}
+	cp.snippetChains = make(map[string]*snippetChain)
+	cp.tree.Init(cp.poolID, diskChain)
+
+	main := tr.Main()
+	for i := 0; i < 4; i++ {
+		height, hash := main.HeadHH()
+		assert.Empty(t, tr.AddHead(main, newMockCommonBlockByHH(height, hash, "main")))
+	}
+
+	// a snippet of two blocks and an orphaned block, both far above the main branch
+	snippetTail := newMockCommonBlockByHH(20, emptyBlock.Hash(), "snippet")
+	snp := newSnippetChain(newMockCommonBlockByHH(snippetTail.Height(), snippetTail.Hash(), "snippet"), "snippet1")
+	snp.addTail(snippetTail)
+	cp.snippetChains[snp.id()] = snp
+	free := newMockCommonBlockByHH(30, emptyBlock.Hash(), "free")
+
+	bcp := &BCPool{
+		ID:        "unittest",
+		blockpool: &blockPool{freeBlocks: map[types.Hash]commonBlock{free.Hash(): free}},
+		chainpool: cp,
+	}
+
+	status := bcp.status()
+	assert.Equal(t, "unittest", status.ID)
+	assert.Equal(t, uint64(4), status.PendingNum)
+	assert.Equal(t, uint64(4), status.Pending[0].Height)
+	assert.Equal(t, uint64(7), status.Pending[3].Height)
+	assert.Equal(t, 1, status.FreeNum)
+	assert.Equal(t, free.Hash(), status.Free[0].Hash)
+	assert.Equal(t, 2, status.CompoundNum)
+	assert.Equal(t, 1, status.SnippetNum)
+	assert.Equal(t, uint64(20), status.Snippets[0].TailHeight)
+	assert.Equal(t, uint64(22), status.Snippets[0].HeadHeight)
+	assert.Equal(t, 1, status.BranchNum)
+	assert.True(t, status.Branches[0].Current)
+	assert.NotZero(t, status.OldestAge)
+	assert.False(t, status.empty())
+}
@@ -0,0 +1,113 @@
+package pool
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/pool/tree"
+)
+
+const journalRotateInterval = time.Minute
+
+func (pl *pool) SetJournal(path string, maxSize int64) {
+	pl.journal = newJournal(path, maxSize, pl.log.New("module", "pool/journal"))
+}
+
+// replayJournal adds the journaled blocks which are not in the chain to the pool, they are verified again
+// before being added and are verified as any other block before being inserted
+func (pl *pool) replayJournal() {
+	blocks, err := pl.journal.load()
+	if err != nil {
+		pl.log.Error("load pool journal fail", "err", err)
+	}
+	replayed := 0
+	for _, b := range blocks {
+		latest, err := pl.bc.GetLatestAccountBlock(b.AccountAddress)
+		if err != nil {
+			pl.log.Error("replay pool journal fail", "err", err)
+			break
+		}
+		if latest != nil && latest.Height >= b.Height {
+			continue
+		}
+		if err := pl.selfPendingAc(b.AccountAddress).v.verifyAccountData(b); err != nil {
+			pl.log.Warn("drop journaled block", "err", err, "addr", b.AccountAddress, "height", b.Height, "hash", b.Hash)
+			continue
+		}
+		pl.addAccountBlock(b.AccountAddress, b, types.LocalJournal)
+		replayed++
+	}
+	pl.log.Info("replay pool journal", "blocks", len(blocks), "replayed", replayed)
+	pl.rotateJournal()
+}
+
+func (pl *pool) loopRotateJournal() {
+	t := time.NewTicker(journalRotateInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-pl.closed:
+			return
+		case <-t.C:
+			pl.rotateJournal()
+		}
+	}
+}
+
+// rotateJournal rewrites the journal with the account blocks in the pool, dropping the inserted ones
+func (pl *pool) rotateJournal() {
+	if err := pl.journal.rotate(pl.journalBlocks()); err != nil {
+		pl.log.Error("rotate pool journal fail", "err", err)
+	}
+}
+
+func (pl *pool) journalBlocks() []*ledger.AccountBlock {
+	var result []*ledger.AccountBlock
+	pl.pendingAc.Range(func(_, v interface{}) bool {
+		for _, b := range v.(*accountPool).allBlocks() {
+			result = append(result, b.(*accountPoolBlock).block)
+		}
+		return true
+	})
+	return result
+}
+
+// allBlocks returns the blocks of all branches, snippets and free blocks in the pool, ordered by height
+func (bcp *BCPool) allBlocks() []commonBlock {
+	bcp.blockpool.pendingMu.Lock()
+	defer bcp.blockpool.pendingMu.Unlock()
+
+	bcp.chainHeadMu.Lock()
+	defer bcp.chainHeadMu.Unlock()
+
+	bcp.chainTailMu.Lock()
+	defer bcp.chainTailMu.Unlock()
+
+	var result []commonBlock
+	for _, b := range bcp.chainpool.tree.Branches() {
+		if b.Type() != tree.Normal {
+			continue
+		}
+		tailHeight, _ := b.TailHH()
+		headHeight, _ := b.HeadHH()
+		for h := tailHeight + 1; h <= headHeight; h++ {
+			if k := b.GetKnot(h, false); k != nil {
+				result = append(result, k.(commonBlock))
+			}
+		}
+	}
+	for _, v := range bcp.chainpool.snippetChains {
+		for _, b := range v.heightBlocks {
+			result = append(result, b)
+		}
+	}
+	for _, b := range bcp.blockpool.freeBlocks {
+		result = append(result, b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Height() < result[j].Height()
+	})
+	return result
+}
@@ -48,7 +48,7 @@ func (v *AccountVerifier) InitOnRoadPool(manager *onroad.Manager) {
 func (v *AccountVerifier) verifyReferred(block *ledger.AccountBlock, snapshotHashHeight *ledger.HashHeight) (VerifyResult, *AccBlockPendingTask, *VerifierError) {
 	pendingTask := &AccBlockPendingTask{}
 
-	if err := v.verifySelf(block); err != nil {
+	if err := v.verifySelf(block, snapshotHashHeight.Height); err != nil {
 		return FAIL, pendingTask, err
 	}
 
@@ -63,7 +63,7 @@ func (v *AccountVerifier) verifyReferred(block *ledger.AccountBlock, snapshotHas
 }
 
 func (v *AccountVerifier) verifyConfirmedTimes(recvBlock *ledger.AccountBlock, sbHeight uint64) error {
-	meta, err := v.chain.GetContractMeta(recvBlock.AccountAddress)
+	meta, err := ledger.GetContractMetaAt(recvBlock.AccountAddress, sbHeight, v.chain.GetContractMeta)
 	if err != nil {
 		return errors.New("call GetContractMeta failed," + err.Error())
 	}
@@ -92,8 +92,8 @@ func (v *AccountVerifier) verifyConfirmedTimes(recvBlock *ledger.AccountBlock, s
 	return nil
 }
 
-func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock) *VerifierError {
-	if err := v.checkAccountAddress(block); err != nil {
+func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock, sbHeight uint64) *VerifierError {
+	if err := v.checkAccountAddress(block, sbHeight); err != nil {
 		return newError(err.Error())
 	}
 	if block.IsSendBlock() {
@@ -114,9 +114,9 @@ func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock) *VerifierError
 	return nil
 }
 
-func (v *AccountVerifier) checkAccountAddress(block *ledger.AccountBlock) error {
+func (v *AccountVerifier) checkAccountAddress(block *ledger.AccountBlock, sbHeight uint64) error {
 	if types.IsContractAddr(block.AccountAddress) {
-		meta, err := v.chain.GetContractMeta(block.AccountAddress)
+		meta, err := ledger.GetContractMetaAt(block.AccountAddress, sbHeight, v.chain.GetContractMeta)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,88 @@
+package verifier
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// checkpointLinks are the snapshot blocks verified to be the ancestors of a checkpoint by hash, keyed by height.
+// A link is removed once the chunk ending at it is verified.
+type checkpointLinks struct {
+	mu     sync.RWMutex
+	hashes map[uint64]types.Hash
+}
+
+func (l *checkpointLinks) get(height uint64) (types.Hash, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	hash, ok := l.hashes[height]
+	return hash, ok
+}
+
+func (l *checkpointLinks) add(height uint64, hash types.Hash) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.hashes == nil {
+		l.hashes = make(map[uint64]types.Hash)
+	}
+	l.hashes[height] = hash
+}
+
+func (l *checkpointLinks) remove(height uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.hashes, height)
+}
+
+// Checkpoints returns the trusted snapshot blocks
+func (v *verifier) Checkpoints() ledger.Checkpoints {
+	return v.Sv.checkpoints
+}
+
+// CheckpointLinked returns true if the snapshot block is a checkpoint, or an ancestor of one verified by LinkCheckpoint
+func (v *verifier) CheckpointLinked(hash types.Hash, height uint64) bool {
+	if cp := v.Sv.checkpoints.Get(height); cp != nil {
+		return cp.Hash == hash
+	}
+	linked, ok := v.links.get(height)
+	return ok && linked == hash
+}
+
+// LinkCheckpoint verifies the snapshot blocks, sorted by height, are linked by hash from the highest one down to
+// the lowest one. The highest one must be a checkpoint or a linked block, and the prev block of the lowest one is
+// linked then, so the chunks are linked one by one from a checkpoint down.
+func (v *verifier) LinkCheckpoint(snapshotBlocks []*ledger.SnapshotBlock) error {
+	if len(snapshotBlocks) == 0 {
+		return nil
+	}
+	top := snapshotBlocks[len(snapshotBlocks)-1]
+	if !v.CheckpointLinked(top.Hash, top.Height) {
+		return fmt.Errorf("snapshot block %s/%d is not linked to a checkpoint", top.Hash, top.Height)
+	}
+	if err := verifyHashLinks(snapshotBlocks, v.VerifySnapshotBlockHash); err != nil {
+		return err
+	}
+	if bottom := snapshotBlocks[0]; bottom.Height > 1 {
+		v.links.add(bottom.Height-1, bottom.PrevHash)
+	}
+	return nil
+}
+
+// verifyHashLinks verifies the hash of each snapshot block and that each one is the prev block of the next one
+func verifyHashLinks(snapshotBlocks []*ledger.SnapshotBlock, verifyHash func(*ledger.SnapshotBlock) error) error {
+	for i, block := range snapshotBlocks {
+		if err := verifyHash(block); err != nil {
+			return fmt.Errorf("snapshot block %s/%d: %v", block.Hash, block.Height, err)
+		}
+		if i == 0 {
+			continue
+		}
+		if prev := snapshotBlocks[i-1]; block.Height != prev.Height+1 || block.PrevHash != prev.Hash {
+			return fmt.Errorf("snapshot block %s/%d is not linked to %s/%d", block.Hash, block.Height, prev.Hash, prev.Height)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package verifier
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+// newSnapshotChain returns the snapshot blocks from height 1 to n, seed makes a different chain
+func newSnapshotChain(n int, seed byte) []*ledger.SnapshotBlock {
+	var blocks []*ledger.SnapshotBlock
+	var prev types.Hash
+	for h := 1; h <= n; h++ {
+		ts := time.Unix(int64(1600000000+h), 0)
+		block := &ledger.SnapshotBlock{
+			PrevHash:  prev,
+			Height:    uint64(h),
+			Timestamp: &ts,
+			Seed:      uint64(seed),
+		}
+		block.Hash = block.ComputeHash()
+		prev = block.Hash
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestVerifier_LinkCheckpoint(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	defer upgrade.CleanupUpgradeBox(t)
+
+	blocks := newSnapshotChain(10, 0)
+	fake := newSnapshotChain(10, 1)
+	v := &verifier{Sv: &SnapshotVerifier{checkpoints: ledger.Checkpoints{{Hash: blocks[9].Hash, Height: 10}}}}
+
+	// the chunks beneath the checkpoint are not trusted before they are linked
+	assert.True(t, v.CheckpointLinked(blocks[9].Hash, 10))
+	assert.False(t, v.CheckpointLinked(fake[9].Hash, 10))
+	assert.False(t, v.linkedChunk(blocks[:5]))
+	assert.False(t, v.linkedChunk(fake[:5]))
+
+	// the top is not linked
+	assert.Error(t, v.LinkCheckpoint(fake[5:]))
+	// a block is changed
+	changed := append([]*ledger.SnapshotBlock{}, blocks[5:]...)
+	changed[1] = fake[6]
+	assert.Error(t, v.LinkCheckpoint(changed))
+	assert.False(t, v.CheckpointLinked(blocks[4].Hash, 5))
+
+	assert.NoError(t, v.LinkCheckpoint(blocks[5:]))
+	assert.True(t, v.CheckpointLinked(blocks[4].Hash, 5))
+	assert.False(t, v.CheckpointLinked(fake[4].Hash, 5))
+	assert.True(t, v.linkedChunk(blocks[:5]))
+	assert.False(t, v.linkedChunk(fake[:5]))
+	// the blocks under the linked one must be linked to it
+	broken := append([]*ledger.SnapshotBlock{}, blocks[:5]...)
+	broken[2] = fake[2]
+	assert.False(t, v.linkedChunk(broken))
+
+	// the link is used once
+	assert.NoError(t, v.VerifyNetBlocks(nil, blocks[:5]))
+	assert.False(t, v.CheckpointLinked(blocks[4].Hash, 5))
+}
+
+// noGenesisChain has no genesis account blocks
+type noGenesisChain struct {
+	accountChain
+}
+
+func (noGenesisChain) IsGenesisAccountBlock(types.Hash) bool {
+	return false
+}
+
+// newAccountChain returns the send blocks from height 1 to n of the address, they are not signed
+func newAccountChain(addr types.Address, n int) []*ledger.AccountBlock {
+	var blocks []*ledger.AccountBlock
+	var prev types.Hash
+	for h := 1; h <= n; h++ {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         uint64(h),
+			PrevHash:       prev,
+			AccountAddress: addr,
+			Amount:         big.NewInt(int64(h)),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+		}
+		block.Hash = block.ComputeHash()
+		prev = block.Hash
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestVerifier_VerifyNetBlocks_Committed(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	defer upgrade.CleanupUpgradeBox(t)
+
+	signer, err := wallet.RandomAccount()
+	assert.NoError(t, err)
+	addr := signer.Address()
+	accountBlocks := newAccountChain(addr, 3)
+
+	// the top snapshot block commits the account blocks to height 2
+	snapshotBlocks := newSnapshotChain(10, 0)
+	top := snapshotBlocks[9]
+	top.SnapshotContent = ledger.SnapshotContent{addr: {Hash: accountBlocks[1].Hash, Height: 2}}
+	top.Hash = top.ComputeHash()
+
+	newVerifier := func() *verifier {
+		return &verifier{
+			Sv: &SnapshotVerifier{checkpoints: ledger.Checkpoints{{Hash: top.Hash, Height: 10}}},
+			Av: &AccountVerifier{chain: noGenesisChain{}, log: log15.New("module", "test")},
+		}
+	}
+
+	assert.NoError(t, newVerifier().VerifyNetBlocks(accountBlocks[:2], snapshotBlocks))
+
+	// the unsigned account block not committed by the snapshot blocks is injected
+	assert.Equal(t, map[types.Hash]struct{}{accountBlocks[0].Hash: {}, accountBlocks[1].Hash: {}},
+		committedAccountBlocks(accountBlocks, snapshotBlocks))
+	assert.Error(t, newVerifier().VerifyNetBlocks(accountBlocks, snapshotBlocks))
+
+	// the signatures of the blocks not committed are verified
+	accountBlocks[2].Signature, accountBlocks[2].PublicKey, err = signer.Sign(accountBlocks[2].Hash.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, newVerifier().VerifyNetBlocks(accountBlocks, snapshotBlocks))
+}
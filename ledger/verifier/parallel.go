@@ -0,0 +1,174 @@
+package verifier
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// verifyBatchSize is the number of blocks verified by a worker at a time
+const verifyBatchSize = 64
+
+// VerifyNetBlocks verifies the blocks of a chunk as VerifyNetAccountBlock and VerifyNetSnapshotBlock do.
+// The blocks are split into batches shared among workers of all cores, so that syncing is not bound to a single core.
+// It returns the error of the first failed block, account blocks are before snapshot blocks.
+// The chunk whose snapshot blocks are linked by hash to a checkpoint, see LinkCheckpoint, is trusted by hash,
+// the signatures of the snapshot blocks and of the account blocks committed by them are not verified.
+func (v *verifier) VerifyNetBlocks(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock) error {
+	trusted := v.linkedChunk(snapshotBlocks)
+	var committed map[types.Hash]struct{}
+	if trusted {
+		v.links.remove(snapshotBlocks[len(snapshotBlocks)-1].Height)
+		committed = committedAccountBlocks(accountBlocks, snapshotBlocks)
+	}
+
+	total := len(accountBlocks) + len(snapshotBlocks)
+	batches := (total + verifyBatchSize - 1) / verifyBatchSize
+	errs := make([]error, batches)
+	parallelDo(batches, func(i int) {
+		start, end := i*verifyBatchSize, (i+1)*verifyBatchSize
+		if end > total {
+			end = total
+		}
+		if trusted {
+			errs[i] = v.verifyNetHashes(accountBlocks, snapshotBlocks, committed, start, end)
+		} else {
+			errs[i] = v.verifyNetBatch(accountBlocks, snapshotBlocks, start, end)
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkedChunk returns true if the highest snapshot block is linked to a checkpoint and the others are linked to it
+// by prev hash, the hashes are verified by verifyNetHashes
+func (v *verifier) linkedChunk(snapshotBlocks []*ledger.SnapshotBlock) bool {
+	if len(snapshotBlocks) == 0 {
+		return false
+	}
+	top := snapshotBlocks[len(snapshotBlocks)-1]
+	if !v.CheckpointLinked(top.Hash, top.Height) {
+		return false
+	}
+	return verifyHashLinks(snapshotBlocks, func(*ledger.SnapshotBlock) error { return nil }) == nil
+}
+
+// committedAccountBlocks returns the hashes of the account blocks committed by the snapshot blocks, they are
+// the blocks in the snapshot contents and the blocks reachable from them by prev hash. The hashes of the account blocks
+// are verified by verifyNetHashes, so the prev hash of a committed block is committed by its hash.
+func committedAccountBlocks(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock) map[types.Hash]struct{} {
+	blocks := make(map[types.Hash]*ledger.AccountBlock, len(accountBlocks))
+	for _, block := range accountBlocks {
+		blocks[block.Hash] = block
+	}
+
+	committed := make(map[types.Hash]struct{}, len(accountBlocks))
+	for _, snapshotBlock := range snapshotBlocks {
+		for addr, hashHeight := range snapshotBlock.SnapshotContent {
+			block, ok := blocks[hashHeight.Hash]
+			if !ok || block.AccountAddress != addr || block.Height != hashHeight.Height {
+				continue
+			}
+			for {
+				if _, seen := committed[block.Hash]; seen {
+					break
+				}
+				committed[block.Hash] = struct{}{}
+
+				prev, found := blocks[block.PrevHash]
+				if !found || prev.AccountAddress != addr || prev.Height+1 != block.Height {
+					break
+				}
+				block = prev
+			}
+		}
+	}
+	return committed
+}
+
+// verifyNetBatch verifies the blocks from start to end, indexed as if snapshot blocks follow account blocks
+func (v *verifier) verifyNetBatch(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock, start, end int) error {
+	for i := start; i < end; i++ {
+		var err error
+		if i < len(accountBlocks) {
+			err = v.VerifyNetAccountBlock(accountBlocks[i])
+		} else {
+			err = v.VerifyNetSnapshotBlock(snapshotBlocks[i-len(accountBlocks)])
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyNetHashes verifies the blocks from start to end as verifyNetBatch does, except the signatures of the snapshot
+// blocks and the committed account blocks, the other account blocks are verified as VerifyNetAccountBlock does
+func (v *verifier) verifyNetHashes(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock,
+	committed map[types.Hash]struct{}, start, end int) error {
+	for i := start; i < end; i++ {
+		if i < len(accountBlocks) {
+			block := accountBlocks[i]
+			if _, ok := committed[block.Hash]; !ok {
+				if err := v.VerifyNetAccountBlock(block); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := v.VerifyAccountBlockHash(block); err != nil {
+				return err
+			}
+			continue
+		}
+
+		block := snapshotBlocks[i-len(accountBlocks)]
+		if err := v.Sv.verifyTimestamp(block); err != nil {
+			return err
+		}
+		if err := v.Sv.checkpoints.Check(block.Height, block.Hash); err != nil {
+			return err
+		}
+		if err := v.VerifySnapshotBlockHash(block); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parallelDo calls fn for 0 to n-1 by a worker per core, it returns after all calls return
+func parallelDo(n int, fn func(i int)) {
+	workers := runtime.NumCPU()
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	next := int64(-1)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,20 @@
+package verifier
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelDo(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 1000} {
+		counts := make([]int32, n)
+		parallelDo(n, func(i int) {
+			atomic.AddInt32(&counts[i], 1)
+		})
+		for i, c := range counts {
+			if c != 1 {
+				t.Fatalf("n %d, index %d is called %d times", n, i, c)
+			}
+		}
+	}
+}
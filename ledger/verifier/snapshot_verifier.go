@@ -16,8 +16,9 @@ import (
 )
 
 type SnapshotVerifier struct {
-	reader chain.Chain
-	cs     css.Verifier
+	reader      chain.Chain
+	cs          css.Verifier
+	checkpoints ledger.Checkpoints
 }
 
 func NewSnapshotVerifier(ch chain.Chain, cs css.Verifier) *SnapshotVerifier {
@@ -25,10 +26,18 @@ func NewSnapshotVerifier(ch chain.Chain, cs css.Verifier) *SnapshotVerifier {
 	return verifier
 }
 
+// SetCheckpoints sets the trusted snapshot blocks, it must be called before verifying blocks
+func (self *SnapshotVerifier) SetCheckpoints(checkpoints ledger.Checkpoints) {
+	self.checkpoints = checkpoints
+}
+
 func (self *SnapshotVerifier) VerifyNetSb(block *ledger.SnapshotBlock) error {
 	if err := self.verifyTimestamp(block); err != nil {
 		return err
 	}
+	if err := self.checkpoints.Check(block.Height, block.Hash); err != nil {
+		return err
+	}
 	if err := self.verifyDataValidity(block); err != nil {
 		return err
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/errors"
+	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto"
 	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
@@ -17,6 +18,7 @@ import (
 type Verifier interface {
 	VerifyNetSnapshotBlock(block *ledger.SnapshotBlock) error
 	VerifyNetAccountBlock(block *ledger.AccountBlock) error
+	VerifyNetBlocks(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock) error
 
 	VerifyRPCAccountBlock(block *ledger.AccountBlock, snapshot *ledger.SnapshotBlock) (*interfaces.VmAccountBlock, error)
 	VerifyPoolAccountBlock(block *ledger.AccountBlock, snapshot *ledger.SnapshotBlock) (*AccBlockPendingTask, *interfaces.VmAccountBlock, error)
@@ -30,6 +32,10 @@ type Verifier interface {
 	VerifySnapshotBlockSignature(block *ledger.SnapshotBlock) error
 
 	GetSnapshotVerifier() *SnapshotVerifier
+	SetCheckpoints(checkpoints ledger.Checkpoints)
+	Checkpoints() ledger.Checkpoints
+	CheckpointLinked(hash types.Hash, height uint64) bool
+	LinkCheckpoint(snapshotBlocks []*ledger.SnapshotBlock) error
 
 	InitOnRoadPool(manager *onroad.Manager)
 }
@@ -41,6 +47,8 @@ type verifier struct {
 	Sv *SnapshotVerifier
 	Av *AccountVerifier
 
+	links checkpointLinks
+
 	log log15.Logger
 }
 
@@ -62,6 +70,10 @@ func NewVerifier(sv *SnapshotVerifier, av *AccountVerifier) Verifier {
 	}
 }
 
+func (v *verifier) SetCheckpoints(checkpoints ledger.Checkpoints) {
+	v.Sv.SetCheckpoints(checkpoints)
+}
+
 func (v *verifier) InitOnRoadPool(manager *onroad.Manager) {
 	v.Av.InitOnRoadPool(manager)
 }
@@ -0,0 +1,136 @@
+package monitor
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime/debug"
+	rpprof "runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrDebugTokenRequired = errors.New("debug server requires a token")
+
+// maxDebugSeconds is the max duration of a cpu profile or a runtime trace
+const maxDebugSeconds = 60
+
+// DebugServer serves pprof, runtime trace and dumps of goroutines and heap.
+// Every request must carry the token by the header "Authorization: Bearer <token>".
+//
+//	/debug/pprof/                  pprof index and profiles, e.g. /debug/pprof/profile?seconds=30
+//	/debug/pprof/trace?seconds=5   runtime trace of the duration
+//	                               seconds of the profile and the trace are at most 60
+//	/debug/dump/goroutine          stacks of all goroutines
+//	/debug/dump/heap               heap dump of runtime/debug.WriteHeapDump
+type DebugServer struct {
+	token   string
+	tempDir string
+
+	listener net.Listener
+	server   *http.Server
+}
+
+// NewDebugServer creates a debug server, heap dumps are written to tempDir before sent, empty means the os temp dir
+func NewDebugServer(token string, tempDir string) (*DebugServer, error) {
+	if token == "" {
+		return nil, ErrDebugTokenRequired
+	}
+	return &DebugServer{token: token, tempDir: tempDir}, nil
+}
+
+func (s *DebugServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", limitSeconds(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", limitSeconds(pprof.Trace))
+	mux.HandleFunc("/debug/dump/goroutine", s.dumpGoroutine)
+	mux.HandleFunc("/debug/dump/heap", s.dumpHeap)
+	return s.authorize(mux)
+}
+
+func (s *DebugServer) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitSeconds rejects the request if the parameter seconds is greater than maxDebugSeconds,
+// which would keep the profiler or the tracer running for that long.
+func limitSeconds(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if sec, err := strconv.ParseInt(r.FormValue("seconds"), 10, 64); err == nil && sec > maxDebugSeconds {
+			http.Error(w, "seconds must not be greater than "+strconv.Itoa(maxDebugSeconds), http.StatusBadRequest)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *DebugServer) dumpGoroutine(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+func (s *DebugServer) dumpHeap(w http.ResponseWriter, r *http.Request) {
+	f, err := ioutil.TempFile(s.tempDir, "heapdump")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	debug.WriteHeapDump(f.Fd())
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="heapdump"`)
+	_, _ = io.Copy(w, f)
+}
+
+// Start listens on the endpoint and serves in background
+func (s *DebugServer) Start(endpoint string) error {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.server = &http.Server{Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the listening address, nil if not started
+func (s *DebugServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *DebugServer) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+	s.server = nil
+}
@@ -0,0 +1,68 @@
+package monitor
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugServer_Authorize(t *testing.T) {
+	if _, err := NewDebugServer("", ""); err != ErrDebugTokenRequired {
+		t.Fatalf("expected token required, got %v", err)
+	}
+	s, err := NewDebugServer("secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+
+	get := func(token string) (int, string) {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/debug/dump/goroutine", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if code, _ := get(""); code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", code)
+	}
+	if code, _ := get("wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("expected unauthorized, got %d", code)
+	}
+	code, body := get("secret")
+	if code != http.StatusOK || !strings.Contains(body, "TestDebugServer_Authorize") {
+		t.Fatalf("unexpected dump %d %s", code, body)
+	}
+}
+
+func TestDebugServer_LimitSeconds(t *testing.T) {
+	s, err := NewDebugServer("secret", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(s.handler())
+	defer server.Close()
+
+	for _, path := range []string{"/debug/pprof/profile?seconds=61", "/debug/pprof/trace?seconds=3600"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected bad request of %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
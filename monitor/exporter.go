@@ -0,0 +1,134 @@
+package monitor
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// MetricFamily is the snapshot of a registered metric and all of its label values
+type MetricFamily struct {
+	Name    string
+	Help    string
+	Type    string // gauge, counter or histogram
+	Buckets []float64
+	Samples []*MetricSample
+}
+
+// MetricSample is the snapshot of a metric of some label values.
+// Value is set for gauges and counters, Count, Sum and BucketCounts are set for histograms.
+type MetricSample struct {
+	Labels map[string]string
+	Value  float64
+
+	Count        uint64
+	Sum          float64
+	BucketCounts []uint64 // cumulative counts of the buckets
+}
+
+// Gather returns the snapshot of all metrics, sorted by name and label values
+func (r *Registry) Gather() []*MetricFamily {
+	r.collect()
+	var families []*MetricFamily
+	for _, v := range r.sorted() {
+		family := &MetricFamily{Name: v.name, Help: v.help, Type: v.typ}
+		for _, lg := range v.sortedGauges() {
+			family.Samples = append(family.Samples, &MetricSample{
+				Labels: labelMap(v.labels, lg.values),
+				Value:  lg.gauge.Value(),
+			})
+		}
+		families = append(families, family)
+	}
+	for _, v := range r.sortedHistograms() {
+		family := &MetricFamily{Name: v.name, Help: v.help, Type: typeHistogram, Buckets: v.buckets}
+		for _, lh := range v.sortedHistograms() {
+			sample := &MetricSample{
+				Labels:       labelMap(v.labels, lh.values),
+				BucketCounts: make([]uint64, len(v.buckets)),
+			}
+			sample.Count, sample.Sum = lh.histogram.Snapshot(sample.BucketCounts)
+			family.Samples = append(family.Samples, sample)
+		}
+		families = append(families, family)
+	}
+	return families
+}
+
+func labelMap(labels []string, values []string) map[string]string {
+	m := make(map[string]string, len(labels))
+	for i, l := range labels {
+		m[l] = values[i]
+	}
+	return m
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// Exporter pushes the metrics to an external system
+type Exporter interface {
+	Name() string
+	Export(families []*MetricFamily, now time.Time) error
+}
+
+// Pusher exports the metrics of a registry periodically
+type Pusher struct {
+	registry *Registry
+	exporter Exporter
+	interval time.Duration
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+	log  log15.Logger
+}
+
+// NewPusher creates a pusher of the registry, nil registry means DefaultRegistry
+func NewPusher(registry *Registry, exporter Exporter, interval time.Duration) *Pusher {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	return &Pusher{
+		registry: registry,
+		exporter: exporter,
+		interval: interval,
+		log:      log15.New("module", "monitor", "exporter", exporter.Name()),
+	}
+}
+
+// Start exports the metrics every interval in background
+func (p *Pusher) Start() {
+	p.stop = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case now := <-ticker.C:
+				if err := p.exporter.Export(p.registry.Gather(), now); err != nil {
+					p.log.Warn("export metrics failed", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the pusher and exports the metrics for the last time
+func (p *Pusher) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.stop = nil
+	if err := p.exporter.Export(p.registry.Gather(), time.Now()); err != nil {
+		p.log.Warn("export metrics failed", "err", err)
+	}
+}
@@ -0,0 +1,152 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newExporterTestRegistry() *Registry {
+	r := NewRegistry()
+	r.GetOrRegisterGauge("vite_height", "latest height").Set(10)
+	r.GetOrRegisterCounterVec("vite_slots_total", "slots", "address", "result").With("a b", "produced").Add(3)
+	vec := r.GetOrRegisterHistogramVec("vite_latency_seconds", "latency", []float64{0.1, 1}, "op")
+	vec.With("read").Observe(0.05)
+	vec.With("read").Observe(0.5)
+	vec.With("read").Observe(5)
+	return r
+}
+
+func TestRegistry_Gather(t *testing.T) {
+	families := newExporterTestRegistry().Gather()
+	if len(families) != 3 {
+		t.Fatalf("expected 3 families, got %d", len(families))
+	}
+	if families[0].Name != "vite_height" || families[0].Samples[0].Value != 10 {
+		t.Fatalf("unexpected gauge %+v", families[0])
+	}
+	if families[1].Type != typeCounter || families[1].Samples[0].Labels["address"] != "a b" {
+		t.Fatalf("unexpected counter %+v", families[1])
+	}
+	h := families[2].Samples[0]
+	if families[2].Type != typeHistogram || h.Count != 3 || h.Sum != 5.55 || h.BucketCounts[0] != 1 || h.BucketCounts[1] != 2 {
+		t.Fatalf("unexpected histogram %+v", h)
+	}
+}
+
+func TestWriteInfluxDBLines(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Unix(1, 0)
+	if err := WriteInfluxDBLines(&buf, newExporterTestRegistry().Gather(), map[string]string{"host": "node0", "result": "x"}, now); err != nil {
+		t.Fatal(err)
+	}
+	expected := `vite_height,host=node0,result=x value=10 1000000000
+vite_slots_total,address=a\ b,host=node0,result=produced value=3 1000000000
+vite_latency_seconds,host=node0,op=read,result=x count=3i,sum=5.55 1000000000
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestInfluxDBExporter(t *testing.T) {
+	var query string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	exporter, err := NewInfluxDBExporter(InfluxDBConfig{Endpoint: server.URL, Database: "vite", Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(newExporterTestRegistry().Gather(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if query != "db=vite&p=p&precision=ns&u=u" || !bytes.HasPrefix(body, []byte("vite_height value=10 ")) {
+		t.Fatalf("unexpected request %s\n%s", query, body)
+	}
+
+	if _, err := NewInfluxDBExporter(InfluxDBConfig{Endpoint: server.URL}); err == nil {
+		t.Fatal("database should be required")
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var request *otlpRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		request = &otlpRequest{}
+		if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(OTLPConfig{Endpoint: server.URL, Headers: map[string]string{"Authorization": "Bearer x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exporter.Export(newExporterTestRegistry().Gather(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer x" {
+		t.Fatalf("the headers are not sent")
+	}
+
+	rm := request.ResourceMetrics[0]
+	if rm.Resource.Attributes[0].Key != "service.name" || rm.Resource.Attributes[0].Value.StringValue != "gvite" {
+		t.Fatalf("unexpected resource %+v", rm.Resource.Attributes[0])
+	}
+	metrics := rm.ScopeMetrics[0].Metrics
+	if len(metrics) != 3 || metrics[0].Gauge == nil || metrics[1].Sum == nil || !metrics[1].Sum.IsMonotonic || metrics[2].Histogram == nil {
+		t.Fatalf("unexpected metrics %+v", metrics)
+	}
+	h := metrics[2].Histogram.DataPoints[0]
+	if h.Count != "3" || len(h.BucketCounts) != 3 || h.BucketCounts[0] != "1" || h.BucketCounts[1] != "1" || h.BucketCounts[2] != "1" {
+		t.Fatalf("unexpected histogram %+v", h)
+	}
+}
+
+type recordExporter struct {
+	exports chan int
+}
+
+func (e *recordExporter) Name() string { return "record" }
+
+func (e *recordExporter) Export(families []*MetricFamily, now time.Time) error {
+	e.exports <- len(families)
+	return nil
+}
+
+func TestPusher(t *testing.T) {
+	exporter := &recordExporter{exports: make(chan int, 100)}
+	pusher := NewPusher(newExporterTestRegistry(), exporter, 10*time.Millisecond)
+	pusher.Start()
+	select {
+	case n := <-exporter.exports:
+		if n != 3 {
+			t.Fatalf("expected 3 families, got %d", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the metrics are not exported")
+	}
+	pusher.Stop()
+
+	// the last export on stop
+	if len(exporter.exports) == 0 {
+		t.Fatal("the metrics are not exported on stop")
+	}
+}
@@ -0,0 +1,180 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const typeHistogram = "histogram"
+
+// LatencyBuckets are the default buckets in seconds for the latencies of storage and network operations
+var LatencyBuckets = []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5, 10}
+
+// Histogram counts observations in buckets of upper bounds
+type Histogram struct {
+	buckets []float64
+	counts  []uint64 // counts[i] is the number of observations in (buckets[i-1], buckets[i]]
+	count   uint64
+	sum     Gauge
+}
+
+// Observe adds an observation
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	if i < len(h.buckets) {
+		atomic.AddUint64(&h.counts[i], 1)
+	}
+	h.sum.Add(v)
+	atomic.AddUint64(&h.count, 1)
+}
+
+// ObserveSince adds the seconds elapsed since start
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Snapshot returns the count and the sum of observations,
+// and fills the cumulative counts of buckets into cumulative if it is not nil.
+func (h *Histogram) Snapshot(cumulative []uint64) (count uint64, sum float64) {
+	var c uint64
+	for i := range cumulative {
+		if i < len(h.counts) {
+			c += atomic.LoadUint64(&h.counts[i])
+		}
+		cumulative[i] = c
+	}
+	return atomic.LoadUint64(&h.count), h.sum.Value()
+}
+
+// HistogramVec is a set of histograms which share the same name and buckets and are partitioned by label values
+type HistogramVec struct {
+	name    string
+	help    string
+	buckets []float64
+	labels  []string
+
+	mu         sync.RWMutex
+	histograms map[string]*labeledHistogram
+}
+
+type labeledHistogram struct {
+	values    []string
+	histogram *Histogram
+}
+
+// With returns the histogram for the label values, the histogram is created if not exist
+func (v *HistogramVec) With(values ...string) *Histogram {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.RLock()
+	lh, ok := v.histograms[key]
+	v.mu.RUnlock()
+	if ok {
+		return lh.histogram
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if lh, ok = v.histograms[key]; ok {
+		return lh.histogram
+	}
+	lh = &labeledHistogram{
+		values: append([]string(nil), values...),
+		histogram: &Histogram{
+			buckets: v.buckets,
+			counts:  make([]uint64, len(v.buckets)),
+		},
+	}
+	v.histograms[key] = lh
+	return lh.histogram
+}
+
+func (v *HistogramVec) sortedHistograms() []*labeledHistogram {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	result := make([]*labeledHistogram, 0, len(v.histograms))
+	for _, lh := range v.histograms {
+		result = append(result, lh)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].values, "\xff") < strings.Join(result[j].values, "\xff")
+	})
+	return result
+}
+
+func (v *HistogramVec) writePrometheus(w io.Writer) error {
+	if v.help != "" {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help)); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", v.name, typeHistogram); err != nil {
+		return err
+	}
+	cumulative := make([]uint64, len(v.buckets))
+	for _, lh := range v.sortedHistograms() {
+		count, sum := lh.histogram.Snapshot(cumulative)
+		for i, le := range v.buckets {
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, formatLabelsWith(v.labels, lh.values, "le", formatValue(le)), cumulative[i]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, formatLabelsWith(v.labels, lh.values, "le", formatValue(math.Inf(1))), count); err != nil {
+			return err
+		}
+		labels := formatLabels(v.labels, lh.values)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", v.name, labels, formatValue(sum), v.name, labels, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOrRegisterHistogramVec returns the histogram vector with the name, it is registered if not exist.
+// buckets are the sorted upper bounds, nil means LatencyBuckets.
+func (r *Registry) GetOrRegisterHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.histograms[name]; ok {
+		if len(v.labels) != len(labels) {
+			panic(fmt.Sprintf("metric %s is registered with different type or labels", name))
+		}
+		return v
+	}
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metric %s is registered with different type or labels", name))
+	}
+	if buckets == nil {
+		buckets = LatencyBuckets
+	}
+	v := &HistogramVec{
+		name:       name,
+		help:       help,
+		buckets:    append([]float64(nil), buckets...),
+		labels:     append([]string(nil), labels...),
+		histograms: make(map[string]*labeledHistogram),
+	}
+	r.histograms[name] = v
+	return v
+}
+
+func (r *Registry) sortedHistograms() []*HistogramVec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*HistogramVec, 0, len(r.histograms))
+	for _, v := range r.histograms {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
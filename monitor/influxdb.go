@@ -0,0 +1,143 @@
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxDBConfig is the target of the InfluxDB exporter
+type InfluxDBConfig struct {
+	Endpoint string // eg: http://127.0.0.1:8086
+	Database string
+	Username string
+	Password string
+	// Tags are added to every point, eg: host
+	Tags map[string]string
+}
+
+// InfluxDBExporter writes the metrics to the /write api of InfluxDB in the line protocol.
+// Gauges and counters are written with the field value, histograms with the fields count and sum.
+type InfluxDBExporter struct {
+	cfg    InfluxDBConfig
+	url    string
+	client *http.Client
+}
+
+func NewInfluxDBExporter(cfg InfluxDBConfig) (*InfluxDBExporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid influxdb endpoint %s", cfg.Endpoint)
+	}
+	if cfg.Database == "" {
+		return nil, fmt.Errorf("influxdb database not set")
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/write"
+	query := url.Values{"db": {cfg.Database}, "precision": {"ns"}}
+	if cfg.Username != "" {
+		query.Set("u", cfg.Username)
+		query.Set("p", cfg.Password)
+	}
+	u.RawQuery = query.Encode()
+
+	return &InfluxDBExporter{
+		cfg:    cfg,
+		url:    u.String(),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (e *InfluxDBExporter) Name() string {
+	return "influxdb"
+}
+
+func (e *InfluxDBExporter) Export(families []*MetricFamily, now time.Time) error {
+	var buf bytes.Buffer
+	if err := WriteInfluxDBLines(&buf, families, e.cfg.Tags, now); err != nil {
+		return err
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	resp, err := e.client.Post(e.url, "text/plain; charset=utf-8", &buf)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("influxdb responds %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// WriteInfluxDBLines writes the metrics in the InfluxDB line protocol, the tags are added to every point
+func WriteInfluxDBLines(w io.Writer, families []*MetricFamily, tags map[string]string, now time.Time) error {
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+	for _, family := range families {
+		for _, sample := range family.Samples {
+			var fields string
+			if family.Type == typeHistogram {
+				fields = "count=" + strconv.FormatUint(sample.Count, 10) + "i,sum=" + formatInfluxFloat(sample.Sum)
+			} else {
+				if !isFinite(sample.Value) {
+					continue
+				}
+				fields = "value=" + formatInfluxFloat(sample.Value)
+			}
+			if _, err := fmt.Fprintf(w, "%s%s %s %s\n", influxMeasurementReplacer.Replace(family.Name), formatInfluxTags(tags, sample.Labels), fields, timestamp); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var influxMeasurementReplacer = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+var influxTagReplacer = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+
+// formatInfluxTags formats the tags sorted by key, the labels override the tags of the same key.
+// The empty values are skipped since they are not allowed by the line protocol.
+func formatInfluxTags(tags, labels map[string]string) string {
+	merged := make(map[string]string, len(tags)+len(labels))
+	for k, v := range tags {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	keys := make([]string, 0, len(merged))
+	for k, v := range merged {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(influxTagReplacer.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(influxTagReplacer.Replace(merged[k]))
+	}
+	return sb.String()
+}
+
+func formatInfluxFloat(v float64) string {
+	if !isFinite(v) {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
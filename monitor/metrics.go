@@ -0,0 +1,338 @@
+package monitor
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Gauge is a metric whose value can go up and down
+type Gauge struct {
+	bits uint64
+}
+
+// Set sets the value of the gauge
+func (g *Gauge) Set(v float64) {
+	atomic.StoreUint64(&g.bits, math.Float64bits(v))
+}
+
+// Add adds v to the value of the gauge
+func (g *Gauge) Add(v float64) {
+	for {
+		old := atomic.LoadUint64(&g.bits)
+		n := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&g.bits, old, n) {
+			return
+		}
+	}
+}
+
+// Value returns the current value of the gauge
+func (g *Gauge) Value() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&g.bits))
+}
+
+const (
+	typeGauge   = "gauge"
+	typeCounter = "counter"
+)
+
+// GaugeVec is a set of gauges which share the same name and are partitioned by label values
+type GaugeVec struct {
+	name   string
+	help   string
+	typ    string
+	labels []string
+
+	mu     sync.RWMutex
+	gauges map[string]*labeledGauge
+}
+
+type labeledGauge struct {
+	values []string
+	gauge  *Gauge
+}
+
+// With returns the gauge for the label values, the gauge is created if not exist
+func (v *GaugeVec) With(values ...string) *Gauge {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.RLock()
+	lg, ok := v.gauges[key]
+	v.mu.RUnlock()
+	if ok {
+		return lg.gauge
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if lg, ok = v.gauges[key]; ok {
+		return lg.gauge
+	}
+	lg = &labeledGauge{values: append([]string(nil), values...), gauge: &Gauge{}}
+	v.gauges[key] = lg
+	return lg.gauge
+}
+
+// Delete removes the gauge for the label values
+func (v *GaugeVec) Delete(values ...string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.gauges, strings.Join(values, "\xff"))
+}
+
+// Registry holds all registered metrics
+type Registry struct {
+	mu         sync.RWMutex
+	metrics    map[string]*GaugeVec
+	histograms map[string]*HistogramVec
+
+	collectorsMu sync.Mutex
+	collectors   map[string]func()
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics:    make(map[string]*GaugeVec),
+		histograms: make(map[string]*HistogramVec),
+		collectors: make(map[string]func()),
+	}
+}
+
+// DefaultRegistry is the registry used by the package level helpers
+var DefaultRegistry = NewRegistry()
+
+func (r *Registry) getOrRegister(name, help, typ string, labels []string) *GaugeVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.metrics[name]; ok {
+		if v.typ != typ || len(v.labels) != len(labels) {
+			panic(fmt.Sprintf("metric %s is registered with different type or labels", name))
+		}
+		return v
+	}
+	if _, ok := r.histograms[name]; ok {
+		panic(fmt.Sprintf("metric %s is registered with different type or labels", name))
+	}
+	v := &GaugeVec{
+		name:   name,
+		help:   help,
+		typ:    typ,
+		labels: append([]string(nil), labels...),
+		gauges: make(map[string]*labeledGauge),
+	}
+	r.metrics[name] = v
+	return v
+}
+
+// GetOrRegisterGauge returns the gauge with the name, it is registered if not exist
+func (r *Registry) GetOrRegisterGauge(name, help string) *Gauge {
+	return r.getOrRegister(name, help, typeGauge, nil).With()
+}
+
+// GetOrRegisterGaugeVec returns the gauge vector with the name, it is registered if not exist
+func (r *Registry) GetOrRegisterGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return r.getOrRegister(name, help, typeGauge, labels)
+}
+
+// GetOrRegisterCounterVec returns the counter vector with the name, it is registered if not exist.
+// Counters share the implementation of gauges, callers should only add non-negative values.
+func (r *Registry) GetOrRegisterCounterVec(name, help string, labels ...string) *GaugeVec {
+	return r.getOrRegister(name, help, typeCounter, labels)
+}
+
+// RegisterCollector registers fn by name, fn is called before the metrics are read
+// to update the gauges whose values are polled from other components.
+// The collector of the same name is replaced.
+func (r *Registry) RegisterCollector(name string, fn func()) {
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	r.collectors[name] = fn
+}
+
+// UnregisterCollector removes the collector of the name
+func (r *Registry) UnregisterCollector(name string) {
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	delete(r.collectors, name)
+}
+
+func (r *Registry) collect() {
+	r.collectorsMu.Lock()
+	defer r.collectorsMu.Unlock()
+	for _, fn := range r.collectors {
+		fn()
+	}
+}
+
+// Each calls fn for every sample in the registry, sorted by name and label values.
+// Histograms are reported by the samples of their count and sum.
+func (r *Registry) Each(fn func(name string, labels map[string]string, value float64)) {
+	r.collect()
+	for _, v := range r.sorted() {
+		for _, lg := range v.sortedGauges() {
+			labels := make(map[string]string, len(v.labels))
+			for i, l := range v.labels {
+				labels[l] = lg.values[i]
+			}
+			fn(v.name, labels, lg.gauge.Value())
+		}
+	}
+	for _, v := range r.sortedHistograms() {
+		for _, lh := range v.sortedHistograms() {
+			labels := make(map[string]string, len(v.labels))
+			for i, l := range v.labels {
+				labels[l] = lh.values[i]
+			}
+			count, sum := lh.histogram.Snapshot(nil)
+			fn(v.name+"_count", labels, float64(count))
+			fn(v.name+"_sum", labels, sum)
+		}
+	}
+}
+
+// WritePrometheus writes all metrics in the prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.collect()
+	for _, v := range r.sorted() {
+		if v.help != "" {
+			if _, err := fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help)); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ); err != nil {
+			return err
+		}
+		for _, lg := range v.sortedGauges() {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, lg.values), formatValue(lg.gauge.Value())); err != nil {
+				return err
+			}
+		}
+	}
+	for _, v := range r.sortedHistograms() {
+		if err := v.writePrometheus(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) sorted() []*GaugeVec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make([]*GaugeVec, 0, len(r.metrics))
+	for _, v := range r.metrics {
+		result = append(result, v)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+func (v *GaugeVec) sortedGauges() []*labeledGauge {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	result := make([]*labeledGauge, 0, len(v.gauges))
+	for _, lg := range v.gauges {
+		result = append(result, lg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.Join(result[i].values, "\xff") < strings.Join(result[j].values, "\xff")
+	})
+	return result
+}
+
+func formatLabels(labels []string, values []string) string {
+	return formatLabelsWith(labels, values, "", "")
+}
+
+// formatLabelsWith formats the labels with an extra label, which is ignored if empty
+func formatLabelsWith(labels []string, values []string, extra, extraValue string) string {
+	if extra != "" {
+		labels = append(labels[:len(labels):len(labels)], extra)
+		values = append(values[:len(values):len(values)], extraValue)
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, l := range labels {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(l)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabel(values[i]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
+
+// GetOrRegisterGauge registers a gauge in the default registry
+func GetOrRegisterGauge(name, help string) *Gauge {
+	return DefaultRegistry.GetOrRegisterGauge(name, help)
+}
+
+// GetOrRegisterGaugeVec registers a gauge vector in the default registry
+func GetOrRegisterGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return DefaultRegistry.GetOrRegisterGaugeVec(name, help, labels...)
+}
+
+// GetOrRegisterCounterVec registers a counter vector in the default registry
+func GetOrRegisterCounterVec(name, help string, labels ...string) *GaugeVec {
+	return DefaultRegistry.GetOrRegisterCounterVec(name, help, labels...)
+}
+
+// GetOrRegisterHistogramVec registers a histogram vector in the default registry
+func GetOrRegisterHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return DefaultRegistry.GetOrRegisterHistogramVec(name, help, buckets, labels...)
+}
+
+// RegisterCollector registers a collector in the default registry
+func RegisterCollector(name string, fn func()) {
+	DefaultRegistry.RegisterCollector(name, fn)
+}
+
+// UnregisterCollector removes a collector from the default registry
+func UnregisterCollector(name string) {
+	DefaultRegistry.UnregisterCollector(name)
+}
+
+// WritePrometheus writes the default registry in the prometheus text exposition format
+func WritePrometheus(w io.Writer) error {
+	return DefaultRegistry.WritePrometheus(w)
+}
@@ -0,0 +1,85 @@
+package monitor
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	r.GetOrRegisterGauge("vite_height", "latest height").Set(10)
+	vec := r.GetOrRegisterCounterVec("vite_slots_total", "slots by \"addr\"", "address", "result")
+	vec.With("b", "missed").Add(1)
+	vec.With("a", "produced").Add(2)
+	vec.With("a", "produced").Add(1)
+
+	if r.GetOrRegisterCounterVec("vite_slots_total", "", "address", "result") != vec {
+		t.Fatal("get or register should return the registered metric")
+	}
+
+	var buf bytes.Buffer
+	if err := r.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP vite_height latest height
+# TYPE vite_height gauge
+vite_height 10
+# HELP vite_slots_total slots by "addr"
+# TYPE vite_slots_total counter
+vite_slots_total{address="a",result="produced"} 3
+vite_slots_total{address="b",result="missed"} 1
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestGaugeVec_WithWrongLabels(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	NewRegistry().GetOrRegisterGaugeVec("vite_test", "", "a").With("x", "y")
+}
+
+func TestRegistry_Histogram(t *testing.T) {
+	r := NewRegistry()
+	vec := r.GetOrRegisterHistogramVec("vite_latency_seconds", "latency", []float64{0.1, 1}, "op")
+	vec.With("read").Observe(0.05)
+	vec.With("read").Observe(0.5)
+	vec.With("read").Observe(2)
+
+	var height float64
+	r.RegisterCollector("height", func() {
+		height++
+		r.GetOrRegisterGauge("vite_height", "").Set(height)
+	})
+
+	var buf bytes.Buffer
+	if err := r.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# TYPE vite_height gauge
+vite_height 1
+# HELP vite_latency_seconds latency
+# TYPE vite_latency_seconds histogram
+vite_latency_seconds_bucket{op="read",le="0.1"} 1
+vite_latency_seconds_bucket{op="read",le="1"} 2
+vite_latency_seconds_bucket{op="read",le="+Inf"} 3
+vite_latency_seconds_sum{op="read"} 2.55
+vite_latency_seconds_count{op="read"} 3
+`
+	if buf.String() != expected {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+
+	r.UnregisterCollector("height")
+	samples := make(map[string]float64)
+	r.Each(func(name string, labels map[string]string, value float64) {
+		samples[name] = value
+	})
+	if samples["vite_height"] != 1 || samples["vite_latency_seconds_count"] != 3 {
+		t.Fatalf("unexpected samples %v", samples)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"sort"
+	"sync/atomic"
 	"time"
 
 	"github.com/vitelabs/go-vite/v2/log15"
@@ -42,6 +43,18 @@ func (s durations) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
 
 var ntp_logger log15.Logger
 
+// the drift of the last check, and whether the drift has been measured
+var lastDrift, driftMeasured int64
+
+// ClockDrift returns the drift of the local clock to the ntp servers measured by the last check,
+// ok is false if it's never measured
+func ClockDrift() (drift time.Duration, ok bool) {
+	if atomic.LoadInt64(&driftMeasured) == 0 {
+		return 0, false
+	}
+	return time.Duration(atomic.LoadInt64(&lastDrift)), true
+}
+
 func InitNTPChecker(logger log15.Logger) {
 	ntp_logger = logger.New("module", "ntp")
 
@@ -90,6 +103,9 @@ func checkTime() {
 		}
 	}
 
+	atomic.StoreInt64(&lastDrift, int64(drift))
+	atomic.StoreInt64(&driftMeasured, 1)
+
 	if drift < -threshold || drift > threshold {
 		ntp_logger.Error(fmt.Sprintf("too much delta to ntp server: %s", drift))
 	} else {
@@ -0,0 +1,252 @@
+package monitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const otlpScopeName = "github.com/vitelabs/go-vite/monitor"
+
+// OTLPConfig is the target of the OpenTelemetry exporter
+type OTLPConfig struct {
+	// Endpoint is the base url of the collector, eg: http://127.0.0.1:4318, the metrics are posted to /v1/metrics
+	Endpoint string
+	// Headers are added to every request, eg: the authorization of the collector
+	Headers     map[string]string
+	ServiceName string
+	// Attributes are the resource attributes, eg: host.name
+	Attributes map[string]string
+}
+
+// OTLPExporter posts the metrics to an OpenTelemetry collector by OTLP/HTTP in the json encoding.
+// Gauges are exported as gauges, counters as cumulative monotonic sums and histograms as cumulative histograms.
+type OTLPExporter struct {
+	cfg       OTLPConfig
+	url       string
+	startTime time.Time
+	client    *http.Client
+}
+
+func NewOTLPExporter(cfg OTLPConfig) (*OTLPExporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid otlp endpoint %s", cfg.Endpoint)
+	}
+	if !strings.HasSuffix(u.Path, "/v1/metrics") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/metrics"
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "gvite"
+	}
+
+	return &OTLPExporter{
+		cfg:       cfg,
+		url:       u.String(),
+		startTime: time.Now(),
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (e *OTLPExporter) Name() string {
+	return "otlp"
+}
+
+func (e *OTLPExporter) Export(families []*MetricFamily, now time.Time) error {
+	data, err := json.Marshal(e.request(families, now))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("otlp collector responds %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// the json mapping of ExportMetricsServiceRequest, the 64-bit integers are encoded as strings
+
+type otlpRequest struct {
+	ResourceMetrics []*otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource        `json:"resource"`
+	ScopeMetrics []*otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope     `json:"scope"`
+	Metrics []*otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Gauge       *otlpGauge     `json:"gauge,omitempty"`
+	Sum         *otlpSum       `json:"sum,omitempty"`
+	Histogram   *otlpHistogram `json:"histogram,omitempty"`
+}
+
+// aggregation temporality cumulative
+const otlpCumulative = 2
+
+type otlpGauge struct {
+	DataPoints []*otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpSum struct {
+	DataPoints             []*otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                    `json:"aggregationTemporality"`
+	IsMonotonic            bool                   `json:"isMonotonic"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []*otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                       `json:"aggregationTemporality"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []*otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	AsDouble          float64         `json:"asDouble"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []*otlpKeyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               float64         `json:"sum"`
+	BucketCounts      []string        `json:"bucketCounts"`
+	ExplicitBounds    []float64       `json:"explicitBounds"`
+}
+
+func (e *OTLPExporter) request(families []*MetricFamily, now time.Time) *otlpRequest {
+	attributes := map[string]string{"service.name": e.cfg.ServiceName}
+	for k, v := range e.cfg.Attributes {
+		attributes[k] = v
+	}
+	startTime := strconv.FormatInt(e.startTime.UnixNano(), 10)
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+
+	var metrics []*otlpMetric
+	for _, family := range families {
+		metric := &otlpMetric{Name: family.Name, Description: family.Help}
+		switch family.Type {
+		case typeHistogram:
+			metric.Histogram = &otlpHistogram{AggregationTemporality: otlpCumulative}
+			for _, sample := range family.Samples {
+				metric.Histogram.DataPoints = append(metric.Histogram.DataPoints, &otlpHistogramDataPoint{
+					Attributes:        otlpAttributes(sample.Labels),
+					StartTimeUnixNano: startTime,
+					TimeUnixNano:      timestamp,
+					Count:             strconv.FormatUint(sample.Count, 10),
+					Sum:               sample.Sum,
+					BucketCounts:      otlpBucketCounts(sample.BucketCounts, sample.Count),
+					ExplicitBounds:    family.Buckets,
+				})
+			}
+		case typeCounter:
+			metric.Sum = &otlpSum{AggregationTemporality: otlpCumulative, IsMonotonic: true}
+			metric.Sum.DataPoints = otlpNumberDataPoints(family.Samples, startTime, timestamp)
+		default:
+			metric.Gauge = &otlpGauge{DataPoints: otlpNumberDataPoints(family.Samples, "", timestamp)}
+		}
+		metrics = append(metrics, metric)
+	}
+
+	return &otlpRequest{
+		ResourceMetrics: []*otlpResourceMetrics{{
+			Resource: otlpResource{Attributes: otlpAttributes(attributes)},
+			ScopeMetrics: []*otlpScopeMetrics{{
+				Scope:   otlpScope{Name: otlpScopeName},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
+
+// otlpNumberDataPoints skips the values which can't be encoded in json
+func otlpNumberDataPoints(samples []*MetricSample, startTime, timestamp string) []*otlpNumberDataPoint {
+	points := make([]*otlpNumberDataPoint, 0, len(samples))
+	for _, sample := range samples {
+		if !isFinite(sample.Value) {
+			continue
+		}
+		points = append(points, &otlpNumberDataPoint{
+			Attributes:        otlpAttributes(sample.Labels),
+			StartTimeUnixNano: startTime,
+			TimeUnixNano:      timestamp,
+			AsDouble:          sample.Value,
+		})
+	}
+	return points
+}
+
+func otlpAttributes(labels map[string]string) []*otlpKeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	attributes := make([]*otlpKeyValue, 0, len(keys))
+	for _, k := range keys {
+		attributes = append(attributes, &otlpKeyValue{Key: k, Value: otlpAnyValue{StringValue: labels[k]}})
+	}
+	return attributes
+}
+
+// otlpBucketCounts converts the cumulative counts to the counts of every bucket, the last one is the overflow bucket
+func otlpBucketCounts(cumulative []uint64, count uint64) []string {
+	counts := make([]string, 0, len(cumulative)+1)
+	var prev uint64
+	for _, c := range cumulative {
+		counts = append(counts, strconv.FormatUint(c-prev, 10))
+		prev = c
+	}
+	return append(counts, strconv.FormatUint(count-prev, 10))
+}
@@ -0,0 +1,61 @@
+package monitor
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// MetricsServer serves the metrics of a registry on /metrics in the prometheus text exposition format
+type MetricsServer struct {
+	registry *Registry
+	listener net.Listener
+	server   *http.Server
+}
+
+func NewMetricsServer(registry *Registry) *MetricsServer {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	return &MetricsServer{registry: registry}
+}
+
+func (s *MetricsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := s.registry.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Start listens on the endpoint and serves in background
+func (s *MetricsServer) Start(endpoint string) error {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s)
+	s.listener = listener
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the listening address, nil if not started
+func (s *MetricsServer) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *MetricsServer) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+	s.server = nil
+}
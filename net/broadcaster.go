@@ -435,6 +435,12 @@ func (b *broadcaster) codes() []Code {
 	return []Code{CodeNewAccountBlock, CodeNewSnapshotBlock}
 }
 
+func (b *broadcaster) offend(msg Msg, o offense) {
+	if msg.Sender != nil {
+		b.peers.offend(msg.Sender.Id, o)
+	}
+}
+
 func (b *broadcaster) handle(msg Msg) (err error) {
 	defer monitor.LogTime("broadcast", "handle", time.Now())
 
@@ -443,11 +449,13 @@ func (b *broadcaster) handle(msg Msg) (err error) {
 		nb := &NewSnapshotBlock{}
 		if err = nb.Deserialize(msg.Payload); err != nil {
 			msg.Recycle()
+			b.offend(msg, offenseProtocol)
 			return err
 		}
 		msg.Recycle()
 
 		if nb.Block == nil {
+			b.offend(msg, offenseProtocol)
 			return errMissingBroadcastBlock
 		}
 
@@ -475,6 +483,7 @@ func (b *broadcaster) handle(msg Msg) (err error) {
 
 		if err = b.verifier.VerifyNetSnapshotBlock(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new snapshotblock %s/%d from %s error: %v", hash, block.Height, msg.Sender, err))
+			b.offend(msg, offenseInvalidBlock)
 			return err
 		}
 
@@ -494,11 +503,13 @@ func (b *broadcaster) handle(msg Msg) (err error) {
 		nb := &NewAccountBlock{}
 		if err = nb.Deserialize(msg.Payload); err != nil {
 			msg.Recycle()
+			b.offend(msg, offenseProtocol)
 			return err
 		}
 		msg.Recycle()
 
 		if nb.Block == nil {
+			b.offend(msg, offenseProtocol)
 			return errMissingBroadcastBlock
 		}
 
@@ -540,6 +551,7 @@ func (b *broadcaster) handle(msg Msg) (err error) {
 
 		if err = b.verifier.VerifyNetAccountBlock(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new accountblock %s from %s error: %v", hash, msg.Sender, err))
+			b.offend(msg, offenseInvalidBlock)
 			return err
 		}
 
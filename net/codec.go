@@ -45,6 +45,12 @@ type Codec interface {
 	Address() _net.Addr
 }
 
+// compressor is implemented by the codecs compressing the message payload by snappy
+type compressor interface {
+	// setCompress disables the compression if it is false, it must be called before writing messages
+	setCompress(compress bool)
+}
+
 type CodecFactory interface {
 	CreateCodec(conn _net.Conn) Codec
 }
@@ -141,10 +147,12 @@ type transport struct {
 	_net.Conn
 	readTimeout       time.Duration
 	writeTimeout      time.Duration
-	minCompressLength int // will not compress message payload if small than minCompressLength bytes
+	minCompressLength int  // will not compress message payload if small than minCompressLength bytes
+	noCompress        bool // the peer can't decode the compressed payload
 	readHeadBuf       [4]byte
 	writeHeadBuf      [9]byte
 	writeBuf          []byte
+	traffic           *traffic // accounts the messages, nil is not accounted
 }
 
 func (t *transport) Address() _net.Addr {
@@ -155,21 +163,34 @@ type transportFactory struct {
 	minCompressLength int
 	readTimeout       time.Duration
 	writeTimeout      time.Duration
+	traffic           *traffic
 }
 
 func (tf *transportFactory) CreateCodec(conn _net.Conn) Codec {
-	return NewTransport(conn, tf.minCompressLength, tf.readTimeout, tf.writeTimeout)
+	if qc, ok := conn.(*quicStreamConn); ok {
+		return newQuicCodec(qc, tf.minCompressLength, tf.readTimeout, tf.writeTimeout, tf.traffic)
+	}
+	return newTransport(tf.traffic.wrap(conn, trafficP2P), tf.minCompressLength, tf.readTimeout, tf.writeTimeout, tf.traffic)
 }
 
 func NewTransport(conn _net.Conn, minCompressLength int, readTimeout, writeTimeout time.Duration) Codec {
+	return newTransport(conn, minCompressLength, readTimeout, writeTimeout, nil)
+}
+
+func newTransport(conn _net.Conn, minCompressLength int, readTimeout, writeTimeout time.Duration, traffic *traffic) *transport {
 	return &transport{
 		Conn:              conn,
 		minCompressLength: minCompressLength,
 		readTimeout:       readTimeout,
 		writeTimeout:      writeTimeout,
+		traffic:           traffic,
 	}
 }
 
+func (t *transport) setCompress(compress bool) {
+	t.noCompress = !compress
+}
+
 func (t *transport) SetReadTimeout(timeout time.Duration) {
 	t.readTimeout = timeout
 }
@@ -231,6 +252,8 @@ func (t *transport) ReadMsg() (msg Msg, err error) {
 		}
 	}
 
+	t.traffic.message(msg.Code, 2+int(isize)+int(lsize)+len(msg.Payload), false)
+
 	if compressed {
 		var payloadReadLength int
 		payloadReadLength, err = snappy.DecodedLen(msg.Payload)
@@ -268,7 +291,7 @@ func (t *transport) WriteMsg(msg Msg) (err error) {
 	// compress payload
 	var compress bool
 	payloadLen := len(msg.Payload)
-	if payloadLen > t.minCompressLength {
+	if !t.noCompress && payloadLen > t.minCompressLength {
 		preCompressLength := snappy.MaxEncodedLen(payloadLen)
 		if cap(t.writeBuf) < preCompressLength {
 			t.writeBuf = make([]byte, preCompressLength)
@@ -310,6 +333,8 @@ func (t *transport) WriteMsg(msg Msg) (err error) {
 		return errWriteTooShort
 	}
 
+	t.traffic.message(msg.Code, int(headLen)+payloadLen, true)
+
 	return
 }
 
@@ -46,6 +46,7 @@ var (
 	nodeActivePrefix = []byte("node:active:") // activeAt
 	nodeCheckPrefix  = []byte("node:check:")  // checkAt
 	nodeMarkPrefix   = []byte("node:mark:")   // mark
+	nodePeerPrefix   = []byte("node:peer:")   // lastSeen latency score
 
 	nodeBlockIPPrefix = []byte("node:block:ip:") // block expiration
 	nodeBlockIDPrefix = []byte("node:block:id:") // block expiration
@@ -179,6 +180,29 @@ func (db *DB) BlockId(id vnode.NodeID, expiration int64) {
 	db.StoreInt64(key, expiration)
 }
 
+func (db *DB) UnblockId(id vnode.NodeID) {
+	key := append(nodeBlockIDPrefix, id.Bytes()...)
+	_ = db.Delete(key, nil)
+}
+
+// BlockedIds returns the blocked nodes and their block expirations
+func (db *DB) BlockedIds() map[vnode.NodeID]int64 {
+	itr := db.NewIterator(util.BytesPrefix(nodeBlockIDPrefix), nil)
+	defer itr.Release()
+
+	prefixLen := len(nodeBlockIDPrefix)
+	ids := make(map[vnode.NodeID]int64)
+	for itr.Next() {
+		id, err := vnode.Bytes2NodeID(itr.Key()[prefixLen:])
+		if err != nil {
+			continue
+		}
+		ids[id] = decodeVarint(itr.Value())
+	}
+
+	return ids
+}
+
 // RetrieveNode Node according to the special nodeID
 func (db *DB) RetrieveNode(id vnode.NodeID) (node *vnode.Node, err error) {
 	key := append(nodeDataPrefix, id.Bytes()...)
@@ -225,6 +249,9 @@ func (db *DB) RemoveNode(ID vnode.NodeID) {
 
 	key = append(nodeMarkPrefix, id...)
 	_ = db.Delete(key, nil)
+
+	key = append(nodePeerPrefix, id...)
+	_ = db.Delete(key, nil)
 }
 
 // ReadNodes from database, if time.Now().Unix() - node.activeAt < expiration
@@ -377,6 +404,109 @@ func (db *DB) ReadMarkNodes(n int) (nodes []*vnode.Node) {
 	return nodes
 }
 
+// PeerRecord is the connection history of a peer, it is kept across restarts to redial the good peers first
+type PeerRecord struct {
+	ID       vnode.NodeID
+	LastSeen int64 // unix time the peer was connected lately
+	Latency  int64 // milliseconds of the handshake round trip, zero if unknown
+	Score    int64 // reputation score
+}
+
+// priority of the peer to be redialed, a point is lost every hour since last seen and every 100ms of latency
+func (r PeerRecord) priority(now int64) int64 {
+	return r.Score - (now-r.LastSeen)/3600 - r.Latency/100
+}
+
+// StorePeer the record of a connected peer, the node should be stored by StoreNode to be redialed
+func (db *DB) StorePeer(r PeerRecord) {
+	key := append(nodePeerPrefix, r.ID.Bytes()...)
+
+	value := make([]byte, 24)
+	binary.BigEndian.PutUint64(value, uint64(r.LastSeen))
+	binary.BigEndian.PutUint64(value[8:], uint64(r.Latency))
+	binary.BigEndian.PutUint64(value[16:], uint64(r.Score))
+
+	_ = db.DB.Put(key, value, nil)
+}
+
+func decodePeerRecord(id vnode.NodeID, data []byte) (r PeerRecord, ok bool) {
+	if len(data) < 24 {
+		return
+	}
+
+	r.ID = id
+	r.LastSeen = int64(binary.BigEndian.Uint64(data))
+	r.Latency = int64(binary.BigEndian.Uint64(data[8:]))
+	r.Score = int64(binary.BigEndian.Uint64(data[16:]))
+	return r, true
+}
+
+// RetrievePeer the record of the peer, ok is false if the peer has never been connected
+func (db *DB) RetrievePeer(id vnode.NodeID) (r PeerRecord, ok bool) {
+	key := append(nodePeerPrefix, id.Bytes()...)
+	data, err := db.Get(key, nil)
+	if err != nil {
+		return
+	}
+
+	return decodePeerRecord(id, data)
+}
+
+// ReadPeerNodes return at most n nodes of the peers seen in expiration seconds,
+// sorted by priority descending: high score, recently seen and low latency first.
+// Peers not seen in expiration are removed.
+func (db *DB) ReadPeerNodes(n int, expiration int64) (nodes []*vnode.Node) {
+	itr := db.NewIterator(util.BytesPrefix(nodePeerPrefix), nil)
+	defer itr.Release()
+
+	var records []PeerRecord
+	prefixLen := len(nodePeerPrefix)
+
+	now := time.Now().Unix()
+
+	for itr.Next() {
+		key := itr.Key()
+		id, err := vnode.Bytes2NodeID(key[prefixLen:])
+		if err != nil {
+			_ = db.Delete(key, nil)
+			continue
+		}
+
+		r, ok := decodePeerRecord(id, itr.Value())
+		if !ok {
+			_ = db.Delete(key, nil)
+			continue
+		}
+
+		if now-r.LastSeen > expiration {
+			db.RemoveNode(id)
+			continue
+		}
+
+		records = append(records, r)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].priority(now) > records[j].priority(now)
+	})
+
+	for _, r := range records {
+		if len(nodes) == n {
+			break
+		}
+
+		node, err := db.RetrieveNode(r.ID)
+		if err != nil {
+			db.RemoveNode(r.ID)
+			continue
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	return nodes
+}
+
 func (db *DB) Iterate(prefix []byte, fn func(key, value []byte) bool) {
 	itr := db.NewIterator(util.BytesPrefix(nodeMarkPrefix), nil)
 	defer itr.Release()
@@ -21,6 +21,7 @@ package database
 import (
 	"bytes"
 	"testing"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/net/vnode"
 )
@@ -58,3 +59,53 @@ func TestNodeDB_Store(t *testing.T) {
 		t.Error("diff net")
 	}
 }
+
+func TestDB_ReadPeerNodes(t *testing.T) {
+	mdb, err := New("", 1, id)
+	if err != nil {
+		panic(err)
+	}
+
+	now := time.Now().Unix()
+	records := []PeerRecord{
+		{LastSeen: now - 3600*24*8, Score: 100},            // expired
+		{LastSeen: now - 3600*10, Latency: 50, Score: 100}, // seen long ago
+		{LastSeen: now, Latency: 2000, Score: 100},         // slow
+		{LastSeen: now, Latency: 30, Score: 100},           // best
+		{LastSeen: now, Latency: 30, Score: 40},            // misbehaved
+	}
+
+	var nodes []*vnode.Node
+	for i := range records {
+		node := vnode.MockNode(false, true)
+		if err = mdb.StoreNode(node); err != nil {
+			panic(err)
+		}
+		records[i].ID = node.ID
+		mdb.StorePeer(records[i])
+		nodes = append(nodes, node)
+	}
+
+	if r, ok := mdb.RetrievePeer(nodes[2].ID); !ok || r != records[2] {
+		t.Errorf("retrieve peer %v %v", r, ok)
+	}
+
+	ret := mdb.ReadPeerNodes(10, 3600*24*7)
+	want := []int{3, 1, 2, 4}
+	if len(ret) != len(want) {
+		t.Fatalf("read %d peers, want %d", len(ret), len(want))
+	}
+	for i, j := range want {
+		if ret[i].ID != nodes[j].ID {
+			t.Errorf("peer %d should be node %d", i, j)
+		}
+	}
+
+	if _, ok := mdb.RetrievePeer(nodes[0].ID); ok {
+		t.Error("expired peer should be removed")
+	}
+
+	if ret = mdb.ReadPeerNodes(2, 3600*24*7); len(ret) != 2 || ret[0].ID != nodes[3].ID {
+		t.Errorf("read top peers: %v", ret)
+	}
+}
@@ -106,6 +106,7 @@ type Discovery struct {
 	bootSeeds []string
 
 	booters []booter
+	dns     *dnsBooter // nil if no DNS seeds
 
 	table nodeTable
 
@@ -221,8 +222,23 @@ func (d *Discovery) Start() (err error) {
 	if d.db != nil {
 		d.booters = append(d.booters, newDBBooter(d.db))
 	}
-	if len(d.bootSeeds) > 0 {
-		d.booters = append(d.booters, newNetBooter(d.node, d.bootSeeds))
+	var httpSeeds, dnsSeeds []string
+	for _, seed := range d.bootSeeds {
+		if IsDNSSeed(seed) {
+			dnsSeeds = append(dnsSeeds, seed)
+		} else {
+			httpSeeds = append(httpSeeds, seed)
+		}
+	}
+	if len(httpSeeds) > 0 {
+		d.booters = append(d.booters, newNetBooter(d.node, httpSeeds))
+	}
+	if len(dnsSeeds) > 0 {
+		d.dns, err = newDNSBooter(d.node, dnsSeeds, nil)
+		if err != nil {
+			return err
+		}
+		d.booters = append(d.booters, d.dns)
 	}
 	if len(d.bootNodes) > 0 {
 		var bt booter
@@ -355,7 +371,13 @@ func (d *Discovery) tableLoop() {
 	defer checkTicker.Stop()
 	defer refreshTicker.Stop()
 
-	var storeChan, cleanChan <-chan time.Time
+	var storeChan, cleanChan, dnsChan <-chan time.Time
+	if d.dns != nil {
+		dnsTicker := time.NewTicker(dnsRefreshInterval)
+		defer dnsTicker.Stop()
+
+		dnsChan = dnsTicker.C
+	}
 	if d.db != nil {
 		storeTicker := time.NewTicker(storeInterval)
 		cleanTicker := time.NewTicker(dbCleanInterval)
@@ -380,6 +402,14 @@ Loop:
 		case <-refreshTicker.C:
 			go d.init()
 
+		case <-dnsChan:
+			// the DNS seeds may be rotated, check the nodes of them
+			go func() {
+				for _, n := range d.dns.getBootNodes(bucketSize) {
+					d.receiveNode(n)
+				}
+			}()
+
 		case <-storeChan:
 			d.table.store(d.db)
 
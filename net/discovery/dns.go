@@ -0,0 +1,461 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/crypto"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+/*
+ * DNS seeds
+ *
+ * Boot nodes can be published in DNS TXT records, so the bootstrap infrastructure can be changed
+ * without new binaries. Two kinds of seeds are supported:
+ *
+ * dns://<domain>
+ *   a plain list, every TXT record of the domain is a node: vnode://<id>@<host>:<port>
+ *
+ * vtree://<base32 public key>@<domain>
+ *   a tree signed by the ed25519 key, like EIP-1459. The root is the TXT record of the domain:
+ *     vtree-root:v1 n=<nodes root hash> l=<links root hash> seq=<sequence> sig=<base64 signature>
+ *   other entries are the TXT records of <hash>.<domain>, the hash is the base32 of the first 16 bytes
+ *   of the blake2b-256 of the entry:
+ *     vtree-branch:<hash>,<hash>,...   a branch of the tree
+ *     vnode://<id>@<host>:<port>        a node, only in the nodes subtree
+ *     vtree://<public key>@<domain>     a link to another tree, only in the links subtree
+ *   The whole tree is resolved again only when the sequence of the root changes.
+ */
+
+const (
+	dnsSeedPrefix   = "dns://"
+	dnsLinkPrefix   = "vtree://"
+	dnsNodePrefix   = "vnode://"
+	dnsRootPrefix   = "vtree-root:v1"
+	dnsBranchPrefix = "vtree-branch:"
+
+	dnsRefreshInterval = 30 * time.Minute
+	dnsTimeout         = 10 * time.Second
+	// bounds of a tree and the linked trees, against malicious or broken zones
+	dnsMaxEntries = 2000
+	dnsMaxTrees   = 16
+	// the branches are kept in a single TXT string of 255 bytes
+	dnsMaxChildren = 8
+)
+
+var dnsHashEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+var dnsSigEncoding = base64.RawURLEncoding
+
+var errDNSInvalidRoot = errors.New("invalid root of dns tree")
+
+// IsDNSSeed reports whether the seed is a DNS seed, other seeds are queried by HTTP
+func IsDNSSeed(seed string) bool {
+	return strings.HasPrefix(seed, dnsSeedPrefix) || strings.HasPrefix(seed, dnsLinkPrefix)
+}
+
+// dnsResolver is implemented by *net.Resolver
+type dnsResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+func dnsHash(entry string) string {
+	return dnsHashEncoding.EncodeToString(crypto.Hash256([]byte(entry))[:16])
+}
+
+type dnsRoot struct {
+	nodes string
+	links string
+	seq   uint64
+	sig   []byte
+}
+
+func (r *dnsRoot) signedText() string {
+	return fmt.Sprintf("%s n=%s l=%s seq=%d", dnsRootPrefix, r.nodes, r.links, r.seq)
+}
+
+func (r *dnsRoot) String() string {
+	return r.signedText() + " sig=" + dnsSigEncoding.EncodeToString(r.sig)
+}
+
+func parseDNSRoot(txt string) (r *dnsRoot, err error) {
+	fields := strings.Fields(txt)
+	if len(fields) != 5 || fields[0] != dnsRootPrefix {
+		return nil, errDNSInvalidRoot
+	}
+
+	r = new(dnsRoot)
+	for _, f := range fields[1:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			return nil, errDNSInvalidRoot
+		}
+		switch kv[0] {
+		case "n":
+			r.nodes = kv[1]
+		case "l":
+			r.links = kv[1]
+		case "seq":
+			if r.seq, err = strconv.ParseUint(kv[1], 10, 64); err != nil {
+				return nil, errDNSInvalidRoot
+			}
+		case "sig":
+			if r.sig, err = dnsSigEncoding.DecodeString(kv[1]); err != nil {
+				return nil, errDNSInvalidRoot
+			}
+		default:
+			return nil, errDNSInvalidRoot
+		}
+	}
+
+	if r.nodes == "" || r.links == "" || len(r.sig) != ed25519.SignatureSize {
+		return nil, errDNSInvalidRoot
+	}
+
+	return r, nil
+}
+
+// dnsTree is the nodes resolved from a seed
+type dnsTree struct {
+	domain string
+	pubkey ed25519.PublicKey // nil for a plain list
+	seq    uint64
+	nodes  []*vnode.Node
+	links  []string
+	synced time.Time
+}
+
+func parseDNSSeed(seed string) (*dnsTree, error) {
+	if strings.HasPrefix(seed, dnsSeedPrefix) {
+		domain := strings.TrimPrefix(seed, dnsSeedPrefix)
+		if domain == "" {
+			return nil, fmt.Errorf("missing domain of dns seed %s", seed)
+		}
+		return &dnsTree{domain: domain}, nil
+	}
+
+	if !strings.HasPrefix(seed, dnsLinkPrefix) {
+		return nil, fmt.Errorf("unknown dns seed %s", seed)
+	}
+
+	str := strings.TrimPrefix(seed, dnsLinkPrefix)
+	index := strings.IndexByte(str, '@')
+	if index < 0 || index == len(str)-1 {
+		return nil, fmt.Errorf("missing domain of dns seed %s", seed)
+	}
+	key, err := dnsHashEncoding.DecodeString(str[:index])
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key of dns seed %s", seed)
+	}
+
+	return &dnsTree{domain: str[index+1:], pubkey: key}, nil
+}
+
+func parseDNSNode(entry string) (*vnode.Node, error) {
+	n, err := vnode.ParseNode(strings.TrimPrefix(entry, dnsNodePrefix))
+	if err != nil {
+		return nil, err
+	}
+	if n.ID.IsZero() {
+		return nil, fmt.Errorf("missing id of node %s", entry)
+	}
+	return n, nil
+}
+
+// dnsBooter supply bootNodes from DNS seeds, the seeds are resolved again every dnsRefreshInterval
+type dnsBooter struct {
+	self     *vnode.Node
+	seeds    []string
+	resolver dnsResolver
+
+	mu    sync.Mutex
+	trees map[string]*dnsTree // the seeds and the trees linked
+
+	log log15.Logger
+}
+
+func newDNSBooter(self *vnode.Node, seeds []string, resolver dnsResolver) (*dnsBooter, error) {
+	for _, seed := range seeds {
+		if _, err := parseDNSSeed(seed); err != nil {
+			return nil, err
+		}
+	}
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return &dnsBooter{
+		self:     self,
+		seeds:    seeds,
+		resolver: resolver,
+		trees:    make(map[string]*dnsTree),
+		log:      discvLog.New("module", "dnsBooter"),
+	}, nil
+}
+
+func (b *dnsBooter) getBootNodes(count int) (nodes []*Node) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh(time.Now())
+
+	for _, t := range b.trees {
+		for _, n := range t.nodes {
+			if n.Net != 0 && n.Net != b.self.Net {
+				continue
+			}
+			node := &Node{Node: *n}
+			node.Net = b.self.Net
+			nodes = append(nodes, node)
+		}
+	}
+
+	rand.Shuffle(len(nodes), func(i, j int) {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	})
+	if count > 0 && len(nodes) > count {
+		nodes = nodes[:count]
+	}
+
+	return
+}
+
+// refresh resolves the seeds and the trees linked by them, if they have not been resolved for a while
+func (b *dnsBooter) refresh(now time.Time) {
+	queue := append([]string(nil), b.seeds...)
+	visited := make(map[string]struct{})
+
+	for len(queue) > 0 && len(visited) < dnsMaxTrees {
+		seed := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[seed]; ok {
+			continue
+		}
+		visited[seed] = struct{}{}
+
+		t, ok := b.trees[seed]
+		if !ok {
+			var err error
+			if t, err = parseDNSSeed(seed); err != nil {
+				b.log.Warn(fmt.Sprintf("failed to parse dns seed %s: %v", seed, err))
+				continue
+			}
+			b.trees[seed] = t
+		}
+
+		if now.Sub(t.synced) >= dnsRefreshInterval {
+			if err := b.sync(t); err != nil {
+				b.log.Warn(fmt.Sprintf("failed to resolve dns seed %s: %v", seed, err))
+			} else {
+				t.synced = now
+			}
+		}
+
+		queue = append(queue, t.links...)
+	}
+
+	// the trees no longer linked
+	for seed := range b.trees {
+		if _, ok := visited[seed]; !ok {
+			delete(b.trees, seed)
+		}
+	}
+}
+
+func (b *dnsBooter) sync(t *dnsTree) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	txts, err := b.resolver.LookupTXT(ctx, t.domain)
+	if err != nil {
+		return err
+	}
+
+	// plain list
+	if t.pubkey == nil {
+		var nodes []*vnode.Node
+		for _, txt := range txts {
+			if n, err := parseDNSNode(txt); err == nil {
+				nodes = append(nodes, n)
+			}
+		}
+		t.nodes = nodes
+		return nil
+	}
+
+	var root *dnsRoot
+	for _, txt := range txts {
+		if strings.HasPrefix(txt, dnsRootPrefix) {
+			if root, err = parseDNSRoot(txt); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	if root == nil {
+		return errors.New("missing root of dns tree")
+	}
+	if !ed25519.Verify(t.pubkey, []byte(root.signedText()), root.sig) {
+		return errors.New("invalid signature of dns tree")
+	}
+	if !t.synced.IsZero() && root.seq == t.seq {
+		return nil
+	}
+
+	entries := 0
+	var nodes []*vnode.Node
+	var links []string
+	err = b.walk(ctx, t.domain, root.nodes, &entries, func(entry string) error {
+		if !strings.HasPrefix(entry, dnsNodePrefix) {
+			return fmt.Errorf("unexpected entry %q in nodes", entry)
+		}
+		n, err := parseDNSNode(entry)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = b.walk(ctx, t.domain, root.links, &entries, func(entry string) error {
+		if !strings.HasPrefix(entry, dnsLinkPrefix) {
+			return fmt.Errorf("unexpected entry %q in links", entry)
+		}
+		if _, err := parseDNSSeed(entry); err != nil {
+			return err
+		}
+		links = append(links, entry)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	b.log.Info(fmt.Sprintf("resolve dns tree %s seq %d: %d nodes, %d links", t.domain, root.seq, len(nodes), len(links)))
+	t.seq, t.nodes, t.links = root.seq, nodes, links
+	return nil
+}
+
+// walk resolves the subtree of hash, and calls leaf on every leaf entry
+func (b *dnsBooter) walk(ctx context.Context, domain, hash string, entries *int, leaf func(entry string) error) error {
+	stack := []string{hash}
+	for len(stack) > 0 {
+		hash = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if *entries++; *entries > dnsMaxEntries {
+			return errors.New("too many entries of dns tree")
+		}
+
+		entry, err := b.resolveEntry(ctx, domain, hash)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(entry, dnsBranchPrefix) {
+			children := strings.TrimPrefix(entry, dnsBranchPrefix)
+			if children != "" {
+				stack = append(stack, strings.Split(children, ",")...)
+			}
+			continue
+		}
+
+		if err = leaf(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *dnsBooter) resolveEntry(ctx context.Context, domain, hash string) (string, error) {
+	name := hash + "." + domain
+	txts, err := b.resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	for _, txt := range txts {
+		if strings.EqualFold(dnsHash(txt), hash) {
+			return txt, nil
+		}
+	}
+	return "", fmt.Errorf("missing entry %s", name)
+}
+
+// BuildDNSTree signs the tree of nodes and links, it returns the TXT records by names and the seed url of the tree.
+// nodes are like <id>@<host>:<port>, links are the seed urls of other trees.
+func BuildDNSTree(domain string, nodes, links []string, seq uint64, key ed25519.PrivateKey) (records map[string]string, seed string, err error) {
+	records = make(map[string]string)
+
+	nodeEntries := make([]string, len(nodes))
+	for i, str := range nodes {
+		entry := dnsNodePrefix + strings.TrimPrefix(str, dnsNodePrefix)
+		if _, err = parseDNSNode(entry); err != nil {
+			return nil, "", err
+		}
+		nodeEntries[i] = entry
+	}
+	for _, link := range links {
+		if !strings.HasPrefix(link, dnsLinkPrefix) {
+			return nil, "", fmt.Errorf("invalid link %s", link)
+		}
+		if _, err = parseDNSSeed(link); err != nil {
+			return nil, "", err
+		}
+	}
+
+	root := &dnsRoot{
+		nodes: buildDNSSubtree(nodeEntries, domain, records),
+		links: buildDNSSubtree(links, domain, records),
+		seq:   seq,
+	}
+	root.sig = ed25519.Sign(key, []byte(root.signedText()))
+	records[domain] = root.String()
+
+	seed = dnsLinkPrefix + dnsHashEncoding.EncodeToString(key.PubByte()) + "@" + domain
+	return records, seed, nil
+}
+
+// buildDNSSubtree puts the entries and the branches to records, it returns the hash of the subtree root
+func buildDNSSubtree(entries []string, domain string, records map[string]string) string {
+	put := func(entry string) string {
+		hash := dnsHash(entry)
+		records[hash+"."+domain] = entry
+		return hash
+	}
+
+	if len(entries) == 1 {
+		return put(entries[0])
+	}
+
+	var children []string
+	if len(entries) <= dnsMaxChildren {
+		for _, entry := range entries {
+			children = append(children, put(entry))
+		}
+	} else {
+		size := (len(entries) + dnsMaxChildren - 1) / dnsMaxChildren
+		for i := 0; i < len(entries); i += size {
+			end := i + size
+			if end > len(entries) {
+				end = len(entries)
+			}
+			children = append(children, buildDNSSubtree(entries[i:end], domain, records))
+		}
+	}
+
+	return put(dnsBranchPrefix + strings.Join(children, ","))
+}
@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+type mockResolver struct {
+	mu      sync.Mutex
+	records map[string][]string
+	lookups int
+}
+
+func (m *mockResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lookups++
+	txts, ok := m.records[name]
+	if !ok {
+		return nil, fmt.Errorf("no such host %s", name)
+	}
+	return txts, nil
+}
+
+func (m *mockResolver) add(records map[string]string) {
+	for name, txt := range records {
+		m.records[name] = []string{txt}
+	}
+}
+
+func mockDNSNodes(count int, port int) []string {
+	nodes := make([]string, count)
+	for i := range nodes {
+		nodes[i] = fmt.Sprintf("%s@127.0.0.1:%d", vnode.RandomNodeID(), port+i)
+	}
+	return nodes
+}
+
+func TestDNSBooter(t *testing.T) {
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+
+	resolver := &mockResolver{records: make(map[string][]string)}
+
+	linked, linkedSeed, err := BuildDNSTree("linked.example.org", mockDNSNodes(3, 9000), nil, 1, key2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.add(linked)
+
+	records, seed, err := BuildDNSTree("nodes.example.org", mockDNSNodes(20, 8000), []string{linkedSeed}, 1, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.add(records)
+
+	resolver.records["list.example.org"] = []string{
+		"vnode://" + mockDNSNodes(1, 7000)[0],
+		"v=spf1 -all",
+	}
+
+	self := &vnode.Node{ID: vnode.RandomNodeID(), Net: 3}
+	b, err := newDNSBooter(self, []string{seed, "dns://list.example.org"}, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := b.getBootNodes(0)
+	if len(nodes) != 24 {
+		t.Fatalf("expected 24 nodes, got %d", len(nodes))
+	}
+	for _, n := range nodes {
+		if n.Net != self.Net || n.ID.IsZero() {
+			t.Errorf("unexpected node %s", n.String())
+		}
+	}
+	if nodes = b.getBootNodes(5); len(nodes) != 5 {
+		t.Errorf("expected 5 nodes, got %d", len(nodes))
+	}
+
+	// not resolved again before the refresh interval
+	lookups := resolver.lookups
+	b.getBootNodes(0)
+	if resolver.lookups != lookups {
+		t.Errorf("unexpected lookups before refresh")
+	}
+
+	// only the roots are resolved if the sequences are unchanged
+	b.mu.Lock()
+	b.refresh(time.Now().Add(dnsRefreshInterval))
+	b.mu.Unlock()
+	if n := resolver.lookups - lookups; n != 3 {
+		t.Errorf("expected 3 lookups of the roots, got %d", n)
+	}
+
+	// the tree is rotated
+	records, _, err = BuildDNSTree("nodes.example.org", mockDNSNodes(2, 8000), nil, 2, key1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver.add(records)
+	b.mu.Lock()
+	b.refresh(time.Now().Add(2 * dnsRefreshInterval))
+	b.mu.Unlock()
+	if nodes = b.getBootNodes(0); len(nodes) != 3 {
+		t.Errorf("expected 3 nodes after rotation, got %d", len(nodes))
+	}
+	if len(b.trees) != 2 {
+		t.Errorf("expected linked tree removed, got %d trees", len(b.trees))
+	}
+}
+
+func TestDNSBooter_InvalidTree(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	_, other, _ := ed25519.GenerateKey(nil)
+
+	records, seed, err := BuildDNSTree("nodes.example.org", mockDNSNodes(10, 8000), nil, 1, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	self := &vnode.Node{ID: vnode.RandomNodeID()}
+
+	// signed by another key
+	resolver := &mockResolver{records: make(map[string][]string)}
+	resolver.add(records)
+	_, otherSeed, _ := BuildDNSTree("nodes.example.org", nil, nil, 1, other)
+	b, _ := newDNSBooter(self, []string{otherSeed}, resolver)
+	if nodes := b.getBootNodes(0); len(nodes) != 0 {
+		t.Errorf("expected no nodes of invalid signature, got %d", len(nodes))
+	}
+
+	// tampered entry
+	for name, txt := range records {
+		if strings.HasPrefix(txt, dnsNodePrefix) {
+			resolver.records[name] = []string{dnsNodePrefix + mockDNSNodes(1, 6000)[0]}
+			break
+		}
+	}
+	b, _ = newDNSBooter(self, []string{seed}, resolver)
+	if nodes := b.getBootNodes(0); len(nodes) != 0 {
+		t.Errorf("expected no nodes of tampered tree, got %d", len(nodes))
+	}
+
+	if _, err = newDNSBooter(self, []string{"vtree://abc@nodes.example.org"}, resolver); err == nil {
+		t.Error("expected error of invalid public key")
+	}
+}
@@ -0,0 +1,145 @@
+package net
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+/*
+ * Encryption
+ *
+ * The handshake is cleartext. If both sides support FeatureEncryption, the TCP connection is upgraded to TLS 1.3
+ * right after the handshake, the initiator is the TLS client and the receiver is the TLS server.
+ *
+ * The node key can't sign a TLS certificate, so the certificates are self-signed by a random key. Each side signs
+ * the keying material exported from the TLS session by its node key, and sends the signature as the first message
+ * in TLS. The session is bound to the node ids authenticated by the handshake, a man in the middle can't relay it.
+ *
+ * QUIC connections are encrypted by TLS 1.3 with the self-signed certificates too, the same binding is exchanged over
+ * the keying material exported from the QUIC session after the handshake, whatever the encryption config is.
+ */
+
+const encryptionALPN = "vite-p2p-tls"
+
+const encryptionExporterLabel = "EXPORTER-vite-p2p"
+
+// shouldEncrypt reports whether the connection of the codec will be upgraded to TLS after the handshake,
+// QUIC connections are encrypted already, but their sessions are always bound to the node ids by encrypt.
+func (h *handshaker) shouldEncrypt(c Codec, their *HandshakeMsg) (encrypt bool, err error) {
+	if _, ok := c.(*quicCodec); ok {
+		return true, nil
+	}
+
+	if !h.features.negotiate(their.Features).Has(FeatureEncryption) {
+		if h.encryption == config.EncryptionRequired {
+			return false, PeerEncryptionRequired
+		}
+		return false, nil
+	}
+
+	if _, ok := c.(*transport); !ok {
+		return false, PeerEncryptionRequired
+	}
+	return true, nil
+}
+
+// encrypt upgrades the connection of the codec to TLS and verifies the TLS session is bound to the node id,
+// the QUIC session is bound only
+func (h *handshaker) encrypt(c Codec, id vnode.NodeID, client bool) (err error) {
+	if qc, ok := c.(*quicCodec); ok {
+		return h.bindQuic(qc, id)
+	}
+
+	t := c.(*transport)
+
+	conf := &tls.Config{
+		Certificates: []tls.Certificate{h.certificate},
+		NextProtos:   []string{encryptionALPN},
+		MinVersion:   tls.VersionTLS13,
+	}
+
+	var tc *tls.Conn
+	if client {
+		conf.InsecureSkipVerify = true
+		tc = tls.Client(t.Conn, conf)
+	} else {
+		conf.ClientAuth = tls.RequireAnyClientCert
+		tc = tls.Server(t.Conn, conf)
+	}
+
+	_ = tc.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err = tc.Handshake(); err != nil {
+		netLog.Warn(fmt.Sprintf("failed to encrypt the connection with %s: %v", t.Address(), err))
+		return PeerNetworkError
+	}
+
+	state := tc.ConnectionState()
+	material, err := state.ExportKeyingMaterial(encryptionExporterLabel, nil, 32)
+	if err != nil {
+		netLog.Warn(fmt.Sprintf("failed to export the keying material of %s: %v", t.Address(), err))
+		return PeerNetworkError
+	}
+
+	t.Conn = tc
+
+	return h.bind(t, id, material)
+}
+
+// bindQuic verifies the QUIC session is bound to the node id
+func (h *handshaker) bindQuic(c *quicCodec, id vnode.NodeID) error {
+	state := c.conn.ConnectionState()
+	material, err := state.TLS.ExportKeyingMaterial(encryptionExporterLabel, nil, 32)
+	if err != nil {
+		netLog.Warn(fmt.Sprintf("failed to export the keying material of %s: %v", c.Address(), err))
+		return PeerNetworkError
+	}
+
+	return h.bind(c, id, material)
+}
+
+// bind sends the binding of the keying material signed by the node key, and verifies the one of the peer
+func (h *handshaker) bind(c Codec, id vnode.NodeID, material []byte) (err error) {
+	err = h.sendBinding(c, material)
+	if err != nil {
+		return
+	}
+
+	return h.verifyBinding(c, id, material)
+}
+
+func (h *handshaker) sendBinding(c Codec, material []byte) (err error) {
+	c.SetWriteTimeout(handshakeTimeout)
+	err = c.WriteMsg(Msg{
+		Code:    CodeHandshake,
+		Payload: ed25519.Sign(h.peerKey, material),
+	})
+	if err != nil {
+		netLog.Warn(fmt.Sprintf("failed to encrypt the connection with %s: write error: %v", c.Address(), err))
+		err = PeerNetworkError
+	}
+	return
+}
+
+func (h *handshaker) verifyBinding(c Codec, id vnode.NodeID, material []byte) (err error) {
+	c.SetReadTimeout(handshakeTimeout)
+	msg, err := c.ReadMsg()
+	if err != nil {
+		netLog.Warn(fmt.Sprintf("failed to encrypt the connection with %s: read error: %v", c.Address(), err))
+		return PeerNetworkError
+	}
+
+	if msg.Code != CodeHandshake {
+		return PeerNotHandshakeMsg
+	}
+
+	if false == ed25519.Verify(ed25519.PublicKey(id.Bytes()), material, msg.Payload) {
+		return PeerInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,201 @@
+package net
+
+import (
+	"crypto/tls"
+	_net "net"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/net/netool"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+func newEncryptionHandshaker(t *testing.T, encryption string) *handshaker {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := vnode.Bytes2NodeID(pub)
+
+	hk := &handshaker{
+		version: 1,
+		netId:   7,
+		id:      id,
+		peerKey: priv,
+		codecFactory: &transportFactory{
+			minCompressLength: 100,
+			readTimeout:       readMsgTimeout,
+			writeTimeout:      writeMsgTimeout,
+		},
+		blackList: netool.NewBlackList(func(t int64, count int) bool {
+			return false
+		}),
+		onHandshaker: func(c Codec, flag PeerFlag, their *HandshakeMsg) (superior bool, err error) {
+			return false, nil
+		},
+		encryption: encryption,
+	}
+	hk.setChain(mockChain{
+		height: 100,
+	})
+	if encryption != config.EncryptionNone {
+		if hk.certificate, err = selfSignedCertificate(); err != nil {
+			t.Fatal(err)
+		}
+		hk.features |= FeatureEncryption
+	}
+	return hk
+}
+
+type handshakeResult struct {
+	c   Codec
+	err error
+}
+
+func handshakePair(t *testing.T, receiver, initiator *handshaker) (inbound, outbound handshakeResult) {
+	ln, err := _net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	done := make(chan handshakeResult, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			done <- handshakeResult{err: err}
+			return
+		}
+		c, _, _, err := receiver.ReceiveHandshake(conn)
+		if err != nil {
+			_ = conn.Close()
+		}
+		done <- handshakeResult{c, err}
+	}()
+
+	conn, err := _net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _, _, err := initiator.InitiateHandshake(conn, receiver.id)
+	if err != nil {
+		_ = conn.Close()
+	}
+	outbound = handshakeResult{c, err}
+	inbound = <-done
+	return
+}
+
+func TestHandshake_encryption(t *testing.T) {
+	inbound, outbound := handshakePair(t, newEncryptionHandshaker(t, config.EncryptionPreferred), newEncryptionHandshaker(t, config.EncryptionRequired))
+	if inbound.err != nil || outbound.err != nil {
+		t.Fatalf("failed to handshake: %v, %v", inbound.err, outbound.err)
+	}
+	defer inbound.c.Close()
+	defer outbound.c.Close()
+
+	for _, c := range []Codec{inbound.c, outbound.c} {
+		if _, ok := c.(*transport).Conn.(*tls.Conn); !ok {
+			t.Fatal("the connection is not encrypted")
+		}
+	}
+
+	go func() {
+		_ = outbound.c.WriteMsg(Msg{Code: CodeHeartBeat, Id: 3, Payload: []byte("hello")})
+	}()
+	msg, err := inbound.c.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Code != CodeHeartBeat || msg.Id != 3 || string(msg.Payload) != "hello" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
+
+func TestHandshake_encryptionPreferred(t *testing.T) {
+	inbound, outbound := handshakePair(t, newEncryptionHandshaker(t, config.EncryptionPreferred), newEncryptionHandshaker(t, config.EncryptionNone))
+	if inbound.err != nil || outbound.err != nil {
+		t.Fatalf("failed to handshake: %v, %v", inbound.err, outbound.err)
+	}
+	defer inbound.c.Close()
+	defer outbound.c.Close()
+
+	if _, ok := inbound.c.(*transport).Conn.(*tls.Conn); ok {
+		t.Fatal("the connection should be cleartext")
+	}
+}
+
+func TestHandshake_encryptionRequired(t *testing.T) {
+	inbound, _ := handshakePair(t, newEncryptionHandshaker(t, config.EncryptionRequired), newEncryptionHandshaker(t, config.EncryptionNone))
+	if inbound.err != PeerEncryptionRequired {
+		t.Fatalf("expected %v, got %v", PeerEncryptionRequired, inbound.err)
+	}
+}
+
+// handshakePairQUIC returns the listener too, closing it closes the sessions accepted
+func handshakePairQUIC(t *testing.T, receiver, initiator *handshaker) (ln _net.Listener, inbound, outbound handshakeResult) {
+	ln, err := listenQUIC("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan handshakeResult, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			done <- handshakeResult{err: err}
+			return
+		}
+		c, _, _, err := receiver.ReceiveHandshake(conn)
+		if err != nil {
+			_ = conn.Close()
+		}
+		done <- handshakeResult{c, err}
+	}()
+
+	conn, err := dialQUIC(ln.Addr().String(), handshakeTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, _, _, err := initiator.InitiateHandshake(conn, receiver.id)
+	if err != nil {
+		_ = conn.Close()
+	}
+	outbound = handshakeResult{c, err}
+	inbound = <-done
+	return
+}
+
+func TestHandshake_quicBinding(t *testing.T) {
+	ln, inbound, outbound := handshakePairQUIC(t, newEncryptionHandshaker(t, config.EncryptionRequired), newEncryptionHandshaker(t, config.EncryptionNone))
+	defer ln.Close()
+	if inbound.err != nil || outbound.err != nil {
+		t.Fatalf("failed to handshake: %v, %v", inbound.err, outbound.err)
+	}
+	defer inbound.c.Close()
+	defer outbound.c.Close()
+
+	// a man in the middle relays the binding of the session with the peer to another session
+	state := outbound.c.(*quicCodec).conn.ConnectionState()
+	relayed, err := state.TLS.ExportKeyingMaterial(encryptionExporterLabel, nil, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln2, inbound2, outbound2 := handshakePairQUIC(t, newEncryptionHandshaker(t, config.EncryptionNone), newEncryptionHandshaker(t, config.EncryptionNone))
+	defer ln2.Close()
+	if inbound2.err != nil || outbound2.err != nil {
+		t.Fatalf("failed to handshake: %v, %v", inbound2.err, outbound2.err)
+	}
+	defer inbound2.c.Close()
+	defer outbound2.c.Close()
+
+	initiator := newEncryptionHandshaker(t, config.EncryptionNone)
+	go func() {
+		_ = initiator.sendBinding(outbound2.c, relayed)
+	}()
+	receiver := newEncryptionHandshaker(t, config.EncryptionNone)
+	if err = receiver.bindQuic(inbound2.c.(*quicCodec), initiator.id); err != PeerInvalidSignature {
+		t.Fatalf("the relayed binding is accepted: %v", err)
+	}
+}
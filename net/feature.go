@@ -0,0 +1,69 @@
+package net
+
+import "strings"
+
+// Feature is a set of the protocol features, the nodes advertise the features they support in the handshake,
+// and a feature is used with a peer only if both sides support it, so new features can roll out incrementally.
+type Feature uint64
+
+const (
+	// FeatureLightServing serves the light protocol: CodeGetLightHeaders, CodeGetAccountProof
+	FeatureLightServing Feature = 1 << iota
+	// FeatureSnapshotSync serves the chunks for syncing by the file server, the chunks are downloaded from and
+	// served to the peers supporting it only
+	FeatureSnapshotSync
+	// FeatureSnappy decodes the message payload compressed by snappy, the payload sent to a peer not supporting it
+	// is not compressed
+	FeatureSnappy
+	// FeatureEncryption upgrades the connection to TLS after the handshake
+	FeatureEncryption
+)
+
+// legacyFeatures are supported by the nodes before the features are advertised,
+// these nodes send no features in the handshake
+const legacyFeatures = FeatureSnapshotSync | FeatureSnappy
+
+var featureNames = []string{
+	"lightServing",
+	"snapshotSync",
+	"snappy",
+	"encryption",
+}
+
+// Has reports whether all the features of f2 are in f
+func (f Feature) Has(f2 Feature) bool {
+	return f&f2 == f2
+}
+
+// negotiate returns the features supported by both sides
+func (f Feature) negotiate(their Feature) Feature {
+	if their == 0 {
+		their = legacyFeatures
+	}
+	return f & their
+}
+
+// Names returns the names of the known features in f
+func (f Feature) Names() []string {
+	names := make([]string, 0, len(featureNames))
+	for i, name := range featureNames {
+		if f.Has(1 << uint(i)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (f Feature) String() string {
+	return strings.Join(f.Names(), "|")
+}
+
+// localFeatures are the features supported by the node, the light protocol is served if the chain can prove
+// the account blocks
+func localFeatures(chain Chain) Feature {
+	f := FeatureSnapshotSync | FeatureSnappy
+	if _, ok := chain.(lightChain); ok {
+		f |= FeatureLightServing
+	}
+	return f
+}
@@ -0,0 +1,78 @@
+package net
+
+import (
+	"bytes"
+	"io"
+	_net "net"
+	"testing"
+	"time"
+)
+
+func TestFeature_negotiate(t *testing.T) {
+	our := FeatureLightServing | FeatureSnapshotSync | FeatureSnappy
+
+	if f := our.negotiate(FeatureSnappy | FeatureEncryption); f != FeatureSnappy {
+		t.Errorf("wrong features: %s", f)
+	}
+	// the peer advertising no features supports the legacy features
+	if f := our.negotiate(0); f != legacyFeatures {
+		t.Errorf("wrong legacy features: %s", f)
+	}
+	if f := our.negotiate(FeatureLightServing); !f.Has(FeatureLightServing) || f.Has(FeatureSnappy) {
+		t.Errorf("wrong features: %s", f)
+	}
+	if s := our.String(); s != "lightServing|snapshotSync|snappy" {
+		t.Errorf("wrong string: %s", s)
+	}
+}
+
+func TestPeerSet_bestPeerWith(t *testing.T) {
+	m := newPeerSet()
+	m.m[peerId{1}] = &Peer{Id: peerId{1}, Height: 10, features: FeatureSnappy}
+	m.m[peerId{2}] = &Peer{Id: peerId{2}, Height: 5, features: FeatureSnappy | FeatureLightServing}
+
+	if p := m.bestPeer(); p.Height != 10 {
+		t.Errorf("wrong best peer: %d", p.Height)
+	}
+	if p := m.bestPeerWith(FeatureLightServing); p == nil || p.Height != 5 {
+		t.Errorf("wrong best light peer")
+	}
+	if p := m.bestPeerWith(FeatureEncryption); p != nil {
+		t.Errorf("should not find peer")
+	}
+}
+
+func TestTransport_setCompress(t *testing.T) {
+	payload := bytes.Repeat([]byte{1}, 1000)
+	for _, compress := range []bool{true, false} {
+		c1, c2 := _net.Pipe()
+		tp := newTransport(c1, 100, time.Second, time.Second, nil)
+		tp.setCompress(compress)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- tp.WriteMsg(Msg{Code: CodeSnapshotBlocks, Payload: payload})
+		}()
+		var meta [1]byte
+		if _, err := io.ReadFull(c2, meta[:]); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, compressed := retrieveMeta(meta[0]); compressed != compress {
+			t.Errorf("compress %v but compressed %v", compress, compressed)
+		}
+		_ = c2.Close()
+		<-done
+		_ = c1.Close()
+	}
+}
+
+func TestPeerSet_pickDownloadPeers(t *testing.T) {
+	m := newPeerSet()
+	m.m[peerId{1}] = &Peer{Id: peerId{1}, Height: 10, features: FeatureSnappy}
+	m.m[peerId{2}] = &Peer{Id: peerId{2}, Height: 10, features: FeatureSnapshotSync}
+	m.m[peerId{3}] = &Peer{Id: peerId{3}, Height: 5, features: FeatureSnapshotSync}
+
+	if peers := m.pickDownloadPeers(10); len(peers) != 1 || peers[peerId{2}] == nil {
+		t.Errorf("wrong download peers: %v", peers)
+	}
+}
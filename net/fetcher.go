@@ -122,6 +122,14 @@ func (f *fetcher) clean(t int64) {
 			delete(f.recordsByHash, r.hash)
 			delete(f.recordsById, r.id)
 
+			if r.st == reqPending {
+				for id, ret := range r.targets {
+					if ret.status == reqPending {
+						f.peers.offend(id, offenseTimeout)
+					}
+				}
+			}
+
 			r.done(nil, Msg{}, errFetchTimeout)
 
 			// recycle
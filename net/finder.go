@@ -20,6 +20,7 @@ package net
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
@@ -32,6 +33,9 @@ import (
 
 const extLen = 32 + 64
 
+// peers not seen in 7d are not redialed after restart
+const peerRecordMaxAge = 7 * 24 * 3600
+
 type Connector interface {
 	ConnectNode(node *vnode.Node) error
 }
@@ -45,7 +49,7 @@ type finder struct {
 	rw          sync.RWMutex
 	targets     map[types.Address]*vnode.Node
 	subId       int // table sub
-	minPeers    int
+	minPeers    int32
 	staticNodes []*vnode.Node
 	resolver    interface {
 		GetNodes(n int) []*vnode.Node
@@ -96,7 +100,7 @@ func newFinder(self types.Address, peers *peerSet, minPeers int, staticNodes []s
 		self:      self,
 		targets:   make(map[types.Address]*vnode.Node),
 		peers:     peers,
-		minPeers:  minPeers,
+		minPeers:  int32(minPeers),
 		connect:   connect,
 		consensus: consensus,
 		dialing:   make(map[peerId]struct{}),
@@ -248,13 +252,71 @@ func (f *finder) receiveNode(node *vnode.Node) {
 		f.rw.Unlock()
 	}
 
-	if f.total() < f.minPeers {
+	if f.total() < f.getMinPeers() {
 		f.rw.Lock()
 		f.dial(node)
 		f.rw.Unlock()
 	}
 }
 
+// addStatic keeps the node connected, it is redialed when disconnected
+func (f *finder) addStatic(node *vnode.Node) {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+
+	for i, n := range f.staticNodes {
+		if n.ID == node.ID {
+			f.staticNodes[i] = node
+			return
+		}
+	}
+	f.staticNodes = append(f.staticNodes, node)
+}
+
+// removeStatic stops redialing the node, it returns false if the node is not static
+func (f *finder) removeStatic(id peerId) bool {
+	f.rw.Lock()
+	defer f.rw.Unlock()
+
+	for i, n := range f.staticNodes {
+		if n.ID == id {
+			f.staticNodes = append(f.staticNodes[:i], f.staticNodes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *finder) isStatic(id peerId) bool {
+	f.rw.RLock()
+	defer f.rw.RUnlock()
+
+	for _, n := range f.staticNodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// statics returns a copy of the static nodes
+func (f *finder) statics() []*vnode.Node {
+	f.rw.RLock()
+	defer f.rw.RUnlock()
+
+	nodes := make([]*vnode.Node, len(f.staticNodes))
+	copy(nodes, f.staticNodes)
+	return nodes
+}
+
+func (f *finder) getMinPeers() int {
+	return int(atomic.LoadInt32(&f.minPeers))
+}
+
+func (f *finder) setMinPeers(n int) {
+	atomic.StoreInt32(&f.minPeers, int32(n))
+}
+
 func (f *finder) total() int {
 	f.rw.RLock()
 	defer f.rw.RUnlock()
@@ -273,6 +335,16 @@ func (f *finder) loop() {
 	}
 	f.rw.Unlock()
 
+	// redial the peers of last run instead of waiting for the discovery
+	if n := f.getMinPeers() - f.total(); n > 0 {
+		nodes = f.db.ReadPeerNodes(n, peerRecordMaxAge)
+		f.rw.Lock()
+		for _, node := range nodes {
+			f.dial(node)
+		}
+		f.rw.Unlock()
+	}
+
 	for {
 		select {
 		case <-checkTicker.C:
@@ -289,9 +361,9 @@ func (f *finder) loop() {
 			f.rw.Unlock()
 
 			if f.resolver != nil {
-				total := f.total()
-				if total < f.minPeers {
-					nodes = f.resolver.GetNodes((f.minPeers - total) * 2)
+				total, minPeers := f.total(), f.getMinPeers()
+				if total < minPeers {
+					nodes = f.resolver.GetNodes((minPeers - total) * 2)
 					f.rw.Lock()
 					for _, node := range nodes {
 						f.dial(node)
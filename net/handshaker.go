@@ -20,9 +20,11 @@ package net
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
 	_net "net"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/proto"
@@ -57,6 +59,8 @@ type HandshakeMsg struct {
 
 	FileAddress   []byte
 	PublicAddress []byte
+
+	Features Feature // the protocol features supported
 }
 
 func (b *HandshakeMsg) Serialize() (data []byte, err error) {
@@ -73,6 +77,7 @@ func (b *HandshakeMsg) Serialize() (data []byte, err error) {
 		Key:           b.Key,
 		Token:         b.Token,
 		PublicAddress: b.PublicAddress,
+		Features:      uint64(b.Features),
 	}
 
 	return proto.Marshal(pb)
@@ -106,6 +111,7 @@ func (b *HandshakeMsg) Deserialize(data []byte) (err error) {
 	}
 	b.FileAddress = pb.FileAddress
 	b.PublicAddress = pb.PublicAddress
+	b.Features = Feature(pb.Features)
 
 	b.Key = pb.Key
 	b.Token = pb.Token
@@ -119,12 +125,17 @@ type handshaker struct {
 	name          string
 	id            vnode.NodeID
 	genesis       types.Hash
+	addrMu        sync.RWMutex // protects the addresses, which are updated when the ports are mapped on NAT
 	fileAddress   []byte
 	publicAddress []byte
+	features      Feature
 
 	peerKey ed25519.PrivateKey
 	key     ed25519.PrivateKey
 
+	encryption  string          // config.EncryptionNone, EncryptionPreferred or EncryptionRequired
+	certificate tls.Certificate // of the TLS sessions, FeatureEncryption is supported if it is set
+
 	codecFactory CodecFactory
 
 	chain chainReader
@@ -228,8 +239,21 @@ func (h *handshaker) verifyHandshake(their *HandshakeMsg, secret []byte) (err er
 	return
 }
 
+func (h *handshaker) setAddress(publicAddress, fileAddress []byte) {
+	h.addrMu.Lock()
+	defer h.addrMu.Unlock()
+
+	h.publicAddress = publicAddress
+	h.fileAddress = fileAddress
+}
+
 func (h *handshaker) makeHandshake(secret []byte) (our *HandshakeMsg) {
 	latestBlock := h.chain.GetLatestSnapshotBlock()
+
+	h.addrMu.RLock()
+	publicAddress, fileAddress := h.publicAddress, h.fileAddress
+	h.addrMu.RUnlock()
+
 	our = &HandshakeMsg{
 		Version:       int64(h.version),
 		NetID:         int64(h.netId),
@@ -241,8 +265,9 @@ func (h *handshaker) makeHandshake(secret []byte) (our *HandshakeMsg) {
 		Genesis:       h.genesis,
 		Key:           nil,
 		Token:         nil,
-		FileAddress:   h.fileAddress,
-		PublicAddress: h.publicAddress,
+		FileAddress:   fileAddress,
+		PublicAddress: publicAddress,
+		Features:      h.features,
 	}
 
 	t := make([]byte, 8)
@@ -318,8 +343,18 @@ func (h *handshaker) ReceiveHandshake(conn _net.Conn) (c Codec, their *Handshake
 		return
 	}
 
+	encrypt, err := h.shouldEncrypt(c, their)
+	if err != nil {
+		return
+	}
+
 	our := h.makeHandshake(secret)
 	err = h.sendHandshake(c, our, msgId)
+	if err != nil || !encrypt {
+		return
+	}
+
+	err = h.encrypt(c, their.ID, false)
 	return
 }
 
@@ -363,6 +398,12 @@ func (h *handshaker) InitiateHandshake(conn _net.Conn, id vnode.NodeID) (c Codec
 		return
 	}
 
+	encrypt, err := h.shouldEncrypt(c, their)
+	if err != nil || !encrypt {
+		return
+	}
+
+	err = h.encrypt(c, their.ID, true)
 	return
 }
 
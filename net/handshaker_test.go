@@ -48,6 +48,7 @@ func TestProtoHandshakeMsg_Serialize(t *testing.T) {
 		Token:         []byte{5, 6, 7},
 		FileAddress:   []byte{1, 2},
 		PublicAddress: []byte{3, 4},
+		Features:      FeatureLightServing | FeatureSnappy,
 	}
 
 	data, err := msg.Serialize()
@@ -97,6 +98,9 @@ func TestProtoHandshakeMsg_Serialize(t *testing.T) {
 	if false == bytes.Equal(msg.Token, msg2.Token) {
 		t.Errorf("different token: %v %v", msg.Token, msg2.Token)
 	}
+	if msg.Features != msg2.Features {
+		t.Errorf("different features: %s %s", msg.Features, msg2.Features)
+	}
 }
 
 func TestExtractFileAddress(t *testing.T) {
@@ -3,6 +3,7 @@ package net
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
@@ -67,6 +68,13 @@ type Consensus interface {
 type Verifier interface {
 	VerifyNetSnapshotBlock(block *ledger.SnapshotBlock) error
 	VerifyNetAccountBlock(block *ledger.AccountBlock) error
+	// VerifyNetBlocks verifies the blocks of a chunk in parallel
+	VerifyNetBlocks(accountBlocks []*ledger.AccountBlock, snapshotBlocks []*ledger.SnapshotBlock) error
+	Checkpoints() ledger.Checkpoints
+	// CheckpointLinked returns true if the snapshot block is a checkpoint or linked to one by LinkCheckpoint
+	CheckpointLinked(hash types.Hash, height uint64) bool
+	// LinkCheckpoint links the snapshot blocks to a checkpoint by hash from the highest one down
+	LinkCheckpoint(snapshotBlocks []*ledger.SnapshotBlock) error
 }
 
 // SnapshotBlockCallback will be invoked when receive a block,
@@ -293,5 +301,17 @@ type Net interface {
 	Info() NodeInfo
 	Nodes() []*vnode.Node
 	PeerCount() int
+	SyncPeerHeight() uint64
+	PeerScores() []PeerScore
+	Traffic() TrafficStatus
+	UnbanPeer(id vnode.NodeID)
+	AddPeer(node *vnode.Node) error
+	RemovePeer(id vnode.NodeID) error
+	BanPeer(id vnode.NodeID, duration time.Duration) error
+	TrustPeer(node *vnode.Node) error
+	UntrustPeer(id vnode.NodeID) error
+	StaticPeers() []StaticPeer
+	PeersInfo() []PeerDetail
 	PeerKey() ed25519.PrivateKey
+	Light() *LightClient
 }
@@ -0,0 +1,550 @@
+package net
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// light client protocol, light nodes download snapshot headers and ask full nodes
+// for the proof that an account block has been snapshotted, and for the storage of contracts.
+//
+// the ledger has no state commitment in snapshot headers, so the proof of an account block is the
+// snapshot block which confirmed it, and the account blocks linking the block to the snapshot content.
+const maxLightHeaders = 200
+const maxLightProofBlocks = 1000
+const lightRequestTimeout = 10 * time.Second
+
+var errInvalidProof = errors.New("invalid proof")
+
+// GetAccountProof ask for the proof of the account block
+type GetAccountProof struct {
+	Hash types.Hash
+}
+
+func (g *GetAccountProof) String() string {
+	return "GetAccountProof<" + g.Hash.String() + ">"
+}
+
+func (g *GetAccountProof) Serialize() ([]byte, error) {
+	return g.Hash.Bytes(), nil
+}
+
+func (g *GetAccountProof) Deserialize(buf []byte) (err error) {
+	g.Hash, err = types.BytesToHash(buf)
+	return
+}
+
+// AccountProof is the snapshot block confirmed the account block, and the account blocks from
+// the requested block to the block snapshotted by the snapshot block. Empty proof means missing.
+type AccountProof struct {
+	Snapshot *ledger.SnapshotBlock
+	Blocks   []*ledger.AccountBlock
+}
+
+func (p *AccountProof) String() string {
+	return "AccountProof<" + fmt.Sprintf("%d", len(p.Blocks)) + ">"
+}
+
+func (p *AccountProof) Serialize() ([]byte, error) {
+	if p.Snapshot == nil {
+		return nil, nil
+	}
+
+	sb, err := p.Snapshot.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	abs, err := (&AccountBlocks{Blocks: p.Blocks}).Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(sb)+len(abs))
+	n := binary.PutUvarint(buf, uint64(len(sb)))
+	buf = append(buf[:n], sb...)
+	return append(buf, abs...), nil
+}
+
+func (p *AccountProof) Deserialize(buf []byte) error {
+	if len(buf) == 0 {
+		p.Snapshot, p.Blocks = nil, nil
+		return nil
+	}
+
+	length, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < length {
+		return errDeserialize
+	}
+
+	p.Snapshot = new(ledger.SnapshotBlock)
+	if err := p.Snapshot.Deserialize(buf[n : n+int(length)]); err != nil {
+		return err
+	}
+
+	abs := new(AccountBlocks)
+	if err := abs.Deserialize(buf[n+int(length):]); err != nil {
+		return err
+	}
+	p.Blocks = abs.Blocks
+
+	return nil
+}
+
+// Verify checks the proof of the account block. The snapshot block should be verified
+// by the caller against the headers it trusts, eg: by VerifySnapshotHeaders.
+func (p *AccountProof) Verify(hash types.Hash) error {
+	if p.Snapshot == nil || len(p.Blocks) == 0 {
+		return errNoResource
+	}
+	if p.Snapshot.ComputeHash() != p.Snapshot.Hash || !p.Snapshot.VerifySignature() {
+		return errInvalidProof
+	}
+	if p.Blocks[0].Hash != hash {
+		return errInvalidProof
+	}
+
+	for i, block := range p.Blocks {
+		if block.ComputeHash() != block.Hash {
+			return errInvalidProof
+		}
+		if i > 0 {
+			prev := p.Blocks[i-1]
+			if block.AccountAddress != prev.AccountAddress || block.PrevHash != prev.Hash || block.Height != prev.Height+1 {
+				return errInvalidProof
+			}
+		}
+	}
+
+	last := p.Blocks[len(p.Blocks)-1]
+	hh, ok := p.Snapshot.SnapshotContent[last.AccountAddress]
+	if !ok || hh.Hash != last.Hash || hh.Height != last.Height {
+		return errInvalidProof
+	}
+
+	return nil
+}
+
+// GetStorageProof ask for the value of the storage key of the contract at the snapshot block of Height
+type GetStorageProof struct {
+	Address types.Address
+	Height  uint64
+	Key     []byte
+}
+
+func (g *GetStorageProof) String() string {
+	return fmt.Sprintf("GetStorageProof<%s/%d>", g.Address, g.Height)
+}
+
+func (g *GetStorageProof) Serialize() ([]byte, error) {
+	buf := make([]byte, types.AddressSize+8, types.AddressSize+8+len(g.Key))
+	copy(buf, g.Address.Bytes())
+	binary.BigEndian.PutUint64(buf[types.AddressSize:], g.Height)
+	return append(buf, g.Key...), nil
+}
+
+func (g *GetStorageProof) Deserialize(buf []byte) (err error) {
+	if len(buf) < types.AddressSize+8 {
+		return errDeserialize
+	}
+	if g.Address, err = types.BytesToAddress(buf[:types.AddressSize]); err != nil {
+		return
+	}
+	g.Height = binary.BigEndian.Uint64(buf[types.AddressSize:])
+	g.Key = buf[types.AddressSize+8:]
+	return nil
+}
+
+// StorageProof is the value of a storage key at a snapshot block, and the proof of the latest account block
+// of the contract confirmed by the snapshot block, the state is of that account block. The proof is empty if
+// the contract has no account block confirmed. The snapshot headers carry no state root, so the value is
+// attested by the serving node only, light clients should compare it among peers.
+type StorageProof struct {
+	Value []byte
+	AccountProof
+}
+
+func (p *StorageProof) String() string {
+	return "StorageProof<" + fmt.Sprintf("%d", len(p.Value)) + ">"
+}
+
+func (p *StorageProof) Serialize() ([]byte, error) {
+	ap, err := p.AccountProof.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64, binary.MaxVarintLen64+len(p.Value)+len(ap))
+	n := binary.PutUvarint(buf, uint64(len(p.Value)))
+	buf = append(buf[:n], p.Value...)
+	return append(buf, ap...), nil
+}
+
+// Deserialize the proof, an empty buf means the storage is missing on the serving node
+func (p *StorageProof) Deserialize(buf []byte) error {
+	if len(buf) == 0 {
+		return errNoResource
+	}
+
+	length, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) < length {
+		return errDeserialize
+	}
+	p.Value = buf[n : n+int(length)]
+
+	return p.AccountProof.Deserialize(buf[n+int(length):])
+}
+
+// Verify checks that the state is of the latest account block of addr confirmed at the snapshot height,
+// the snapshot block should be verified by the caller against the headers it trusts.
+func (p *StorageProof) Verify(addr types.Address, height uint64) error {
+	if p.Snapshot == nil {
+		if len(p.Value) != 0 {
+			return errInvalidProof
+		}
+		return nil
+	}
+	if len(p.Blocks) != 1 {
+		return errInvalidProof
+	}
+	if err := p.AccountProof.Verify(p.Blocks[0].Hash); err != nil {
+		return err
+	}
+	if p.Blocks[0].AccountAddress != addr || p.Snapshot.Height > height {
+		return errInvalidProof
+	}
+
+	return nil
+}
+
+// ProducerVerifier checks whether the snapshot block is produced by the right producer
+type ProducerVerifier interface {
+	VerifySnapshotProducer(block *ledger.SnapshotBlock) (bool, error)
+}
+
+// VerifySnapshotHeaders checks the hash, signature and linkage of continuous snapshot headers,
+// the producers are checked if verifier is not nil.
+func VerifySnapshotHeaders(headers []*ledger.SnapshotBlock, verifier ProducerVerifier) error {
+	for i, header := range headers {
+		if header.ComputeHash() != header.Hash || !header.VerifySignature() {
+			return fmt.Errorf("invalid snapshot header %s/%d", header.Hash, header.Height)
+		}
+		if i > 0 {
+			prev := headers[i-1]
+			if header.PrevHash != prev.Hash || header.Height != prev.Height+1 {
+				return fmt.Errorf("snapshot header %s/%d is not linked to %s/%d", header.Hash, header.Height, prev.Hash, prev.Height)
+			}
+		}
+		if verifier != nil {
+			ok, err := verifier.VerifySnapshotProducer(header)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("snapshot header %s/%d has wrong producer %s", header.Hash, header.Height, header.Producer())
+			}
+		}
+	}
+
+	return nil
+}
+
+type lightChain interface {
+	snapshotBlockReader
+	accountBockReader
+	GetConfirmSnapshotBlockByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error)
+}
+
+// lightServer serve the requests from light clients
+type lightServer struct {
+	chain lightChain
+}
+
+func (s *lightServer) name() string {
+	return "LightServer"
+}
+
+func (s *lightServer) codes() []Code {
+	return []Code{CodeGetLightHeaders, CodeGetAccountProof, CodeGetStorageProof}
+}
+
+func (s *lightServer) handle(msg Msg) (err error) {
+	switch msg.Code {
+	case CodeGetLightHeaders:
+		req := new(GetSnapshotBlocks)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		return msg.Sender.send(CodeLightHeaders, msg.Id, &SnapshotBlocks{Blocks: s.headers(req)})
+
+	case CodeGetAccountProof:
+		req := new(GetAccountProof)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		proof, err := s.proof(req.Hash)
+		if err != nil {
+			netLog.Warn(fmt.Sprintf("handle %s from %s error: %v", req, msg.Sender, err))
+			proof = &AccountProof{}
+		}
+		return msg.Sender.send(CodeAccountProof, msg.Id, proof)
+
+	case CodeGetStorageProof:
+		req := new(GetStorageProof)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return
+		}
+		proof, err := s.storageProof(req)
+		if err != nil {
+			netLog.Warn(fmt.Sprintf("handle %s from %s error: %v", req, msg.Sender, err))
+			// empty payload means missing
+			return msg.Sender.WriteMsg(Msg{Code: CodeStorageProof, Id: msg.Id})
+		}
+		return msg.Sender.send(CodeStorageProof, msg.Id, proof)
+	}
+
+	return nil
+}
+
+func (s *lightServer) headers(req *GetSnapshotBlocks) []*ledger.SnapshotBlock {
+	count := req.Count
+	if count > maxLightHeaders {
+		count = maxLightHeaders
+	}
+
+	var blocks []*ledger.SnapshotBlock
+	var err error
+	if req.From.Hash != types.ZERO_HASH {
+		blocks, err = s.chain.GetSnapshotBlocks(req.From.Hash, req.Forward, count)
+	} else {
+		blocks, err = s.chain.GetSnapshotBlocksByHeight(req.From.Height, req.Forward, count)
+	}
+	if err != nil {
+		return nil
+	}
+
+	// the snapshot content is kept, the hash of the header covers it
+	return blocks
+}
+
+func (s *lightServer) proof(hash types.Hash) (*AccountProof, error) {
+	block, err := s.chain.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errNoResource
+	}
+
+	sb, err := s.chain.GetConfirmSnapshotBlockByAbHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, errNoResource
+	}
+
+	hh, ok := sb.SnapshotContent[block.AccountAddress]
+	if !ok || hh.Height < block.Height {
+		return nil, errNoResource
+	}
+	if hh.Height-block.Height >= maxLightProofBlocks {
+		return nil, fmt.Errorf("proof of %s is too long", hash)
+	}
+
+	blocks, err := s.chain.GetAccountBlocksByHeight(block.AccountAddress, hh.Height, hh.Height-block.Height+1)
+	if err != nil {
+		return nil, err
+	}
+
+	// GetAccountBlocksByHeight returns blocks from high to low
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+
+	return &AccountProof{
+		Snapshot: sb,
+		Blocks:   blocks,
+	}, nil
+}
+
+func (s *lightServer) storageProof(req *GetStorageProof) (*StorageProof, error) {
+	sc, ok := s.chain.(vm_db.SnapshotStateChain)
+	if !ok {
+		return nil, errNoResource
+	}
+
+	sb, err := sc.GetSnapshotHeaderByHeight(req.Height)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, errNoResource
+	}
+
+	db, err := vm_db.NewReadOnlyVmDb(sc, req.Address, req.Height)
+	if err != nil {
+		return nil, err
+	}
+	proof := new(StorageProof)
+	if proof.Value, err = db.GetValue(req.Key); err != nil {
+		return nil, err
+	}
+
+	block, err := vm_db.GetConfirmedAccountBlock(sc, req.Address, sb)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		ap, err := s.proof(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		proof.AccountProof = *ap
+	}
+
+	return proof, nil
+}
+
+// LightClient request headers and proofs from full nodes
+type LightClient struct {
+	peers *peerSet
+	idGen MsgIder
+
+	mu      sync.Mutex
+	pending map[MsgId]chan Msg
+
+	timeout time.Duration
+}
+
+func newLightClient(peers *peerSet) *LightClient {
+	return &LightClient{
+		peers:   peers,
+		idGen:   new(gid),
+		pending: make(map[MsgId]chan Msg),
+		timeout: lightRequestTimeout,
+	}
+}
+
+func (l *LightClient) name() string {
+	return "LightClient"
+}
+
+func (l *LightClient) codes() []Code {
+	return []Code{CodeLightHeaders, CodeAccountProof, CodeStorageProof}
+}
+
+func (l *LightClient) handle(msg Msg) error {
+	l.mu.Lock()
+	ch, ok := l.pending[msg.Id]
+	delete(l.pending, msg.Id)
+	l.mu.Unlock()
+
+	if ok {
+		ch <- msg
+	}
+
+	return nil
+}
+
+func (l *LightClient) request(code Code, payload Serializable) (Msg, error) {
+	p := l.peers.bestPeerWith(FeatureLightServing)
+	if p == nil {
+		return Msg{}, errNoSuitablePeer
+	}
+
+	id := l.idGen.MsgID()
+	ch := make(chan Msg, 1)
+	l.mu.Lock()
+	l.pending[id] = ch
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		delete(l.pending, id)
+		l.mu.Unlock()
+	}()
+
+	if err := p.send(code, id, payload); err != nil {
+		return Msg{}, err
+	}
+
+	select {
+	case msg := <-ch:
+		return msg, nil
+	case <-time.After(l.timeout):
+		return Msg{}, errFetchTimeout
+	}
+}
+
+// GetSnapshotHeaders downloads at most count continuous snapshot headers from height, the headers are verified
+// by VerifySnapshotHeaders before returned.
+func (l *LightClient) GetSnapshotHeaders(height uint64, count uint64, verifier ProducerVerifier) ([]*ledger.SnapshotBlock, error) {
+	msg, err := l.request(CodeGetLightHeaders, &GetSnapshotBlocks{
+		From:    ledger.HashHeight{Height: height},
+		Count:   count,
+		Forward: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bs := new(SnapshotBlocks)
+	if err = bs.Deserialize(msg.Payload); err != nil {
+		return nil, err
+	}
+	if len(bs.Blocks) == 0 {
+		return nil, errNoResource
+	}
+	if bs.Blocks[0].Height != height {
+		return nil, fmt.Errorf("snapshot headers should start at %d, not %d", height, bs.Blocks[0].Height)
+	}
+	if err = VerifySnapshotHeaders(bs.Blocks, verifier); err != nil {
+		return nil, err
+	}
+
+	return bs.Blocks, nil
+}
+
+// GetAccountProof downloads the proof of the account block, and verify it.
+func (l *LightClient) GetAccountProof(hash types.Hash) (*AccountProof, error) {
+	msg, err := l.request(CodeGetAccountProof, &GetAccountProof{Hash: hash})
+	if err != nil {
+		return nil, err
+	}
+
+	proof := new(AccountProof)
+	if err = proof.Deserialize(msg.Payload); err != nil {
+		return nil, err
+	}
+	if err = proof.Verify(hash); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// GetStorageProof downloads the value of the storage key of the contract at the snapshot block of height,
+// and verify the proof of the account state it's read from.
+func (l *LightClient) GetStorageProof(addr types.Address, key []byte, height uint64) (*StorageProof, error) {
+	msg, err := l.request(CodeGetStorageProof, &GetStorageProof{Address: addr, Height: height, Key: key})
+	if err != nil {
+		return nil, err
+	}
+
+	proof := new(StorageProof)
+	if err = proof.Deserialize(msg.Payload); err != nil {
+		return nil, err
+	}
+	if err = proof.Verify(addr, height); err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
@@ -0,0 +1,216 @@
+package net
+
+import (
+	crand "crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func mockAccountProof(t *testing.T) *AccountProof {
+	pub, priv, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := types.Address{1}
+	var blocks []*ledger.AccountBlock
+	var prev types.Hash
+	for i := uint64(1); i <= 3; i++ {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         i,
+			PrevHash:       prev,
+			AccountAddress: addr,
+			Amount:         big.NewInt(int64(i)),
+			Fee:            big.NewInt(0),
+		}
+		block.Hash = block.ComputeHash()
+		prev = block.Hash
+		blocks = append(blocks, block)
+	}
+
+	now := time.Unix(time.Now().Unix(), 0)
+	sb := &ledger.SnapshotBlock{
+		Height:    10,
+		Timestamp: &now,
+		PublicKey: pub,
+		SnapshotContent: ledger.SnapshotContent{
+			addr: &ledger.HashHeight{Hash: prev, Height: 3},
+		},
+	}
+	sb.Hash = sb.ComputeHash()
+	sb.Signature = ed25519.Sign(priv, sb.Hash.Bytes())
+
+	return &AccountProof{
+		Snapshot: sb,
+		Blocks:   blocks,
+	}
+}
+
+func TestAccountProof_Verify(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	proof := mockAccountProof(t)
+
+	buf, err := proof.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p2 := new(AccountProof)
+	if err = p2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p2.Verify(proof.Blocks[0].Hash); err != nil {
+		t.Fatalf("proof should be valid: %v", err)
+	}
+
+	if err = p2.Verify(proof.Blocks[1].Hash); err != errInvalidProof {
+		t.Fatalf("proof of another block should be invalid: %v", err)
+	}
+
+	p2.Blocks = p2.Blocks[:2]
+	if err = p2.Verify(proof.Blocks[0].Hash); err != errInvalidProof {
+		t.Fatalf("proof not linked to the snapshot content should be invalid: %v", err)
+	}
+
+	empty := new(AccountProof)
+	buf, err = empty.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err = p2.Verify(proof.Blocks[0].Hash); err != errNoResource {
+		t.Fatalf("empty proof should be missing: %v", err)
+	}
+}
+
+type headersChain struct {
+	lightChain
+	blocks []*ledger.SnapshotBlock
+}
+
+func (c *headersChain) GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error) {
+	var blocks []*ledger.SnapshotBlock
+	for _, block := range c.blocks {
+		if block.Height >= height && uint64(len(blocks)) < count {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func TestLightServer_headers(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	pub, priv, err := ed25519.GenerateKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := new(headersChain)
+	var prev types.Hash
+	for i := uint64(1); i <= 3; i++ {
+		now := time.Unix(time.Now().Unix(), 0)
+		sb := &ledger.SnapshotBlock{
+			PrevHash:  prev,
+			Height:    i,
+			Timestamp: &now,
+			PublicKey: pub,
+			SnapshotContent: ledger.SnapshotContent{
+				types.Address{byte(i)}: &ledger.HashHeight{Hash: types.Hash{byte(i)}, Height: i},
+			},
+		}
+		sb.Hash = sb.ComputeHash()
+		sb.Signature = ed25519.Sign(priv, sb.Hash.Bytes())
+		prev = sb.Hash
+		chain.blocks = append(chain.blocks, sb)
+	}
+
+	server := &lightServer{chain}
+	headers := server.headers(&GetSnapshotBlocks{From: ledger.HashHeight{Height: 2}, Count: 10, Forward: true})
+
+	buf, err := (&SnapshotBlocks{Blocks: headers}).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs := new(SnapshotBlocks)
+	if err = bs.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(bs.Blocks) != 2 || bs.Blocks[0].Height != 2 {
+		t.Fatalf("wrong headers %d", len(bs.Blocks))
+	}
+	if err = VerifySnapshotHeaders(bs.Blocks, nil); err != nil {
+		t.Fatalf("headers with content should be valid: %v", err)
+	}
+
+	bs.Blocks[1].SnapshotContent = nil
+	if err = VerifySnapshotHeaders(bs.Blocks, nil); err == nil {
+		t.Fatal("header without its content should be invalid")
+	}
+}
+
+func TestStorageProof_Verify(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	ap := mockAccountProof(t)
+	ap.Blocks = ap.Blocks[2:]
+	addr := ap.Blocks[0].AccountAddress
+	proof := &StorageProof{Value: []byte("value"), AccountProof: *ap}
+
+	buf, err := proof.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2 := new(StorageProof)
+	if err = p2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(p2.Value) != "value" {
+		t.Fatalf("wrong value %q", p2.Value)
+	}
+
+	if err = p2.Verify(addr, ap.Snapshot.Height); err != nil {
+		t.Fatalf("proof should be valid: %v", err)
+	}
+	if err = p2.Verify(addr, ap.Snapshot.Height-1); err != errInvalidProof {
+		t.Fatalf("proof confirmed after the height should be invalid: %v", err)
+	}
+	if err = p2.Verify(types.Address{2}, ap.Snapshot.Height); err != errInvalidProof {
+		t.Fatalf("proof of another contract should be invalid: %v", err)
+	}
+
+	// the contract has no account block confirmed
+	buf, err = (&StorageProof{}).Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if err = p2.Verify(addr, ap.Snapshot.Height); err != nil || len(p2.Value) != 0 {
+		t.Fatalf("empty state should be valid: %v", err)
+	}
+	p2.Value = []byte("value")
+	if err = p2.Verify(addr, ap.Snapshot.Height); err != errInvalidProof {
+		t.Fatalf("value without the account state should be invalid: %v", err)
+	}
+
+	if err = p2.Deserialize(nil); err != errNoResource {
+		t.Fatalf("empty payload should be missing: %v", err)
+	}
+}
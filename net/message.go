@@ -45,6 +45,14 @@ const (
 	CodeNewSnapshotBlock  Code = 31
 	CodeNewAccountBlock   Code = 32
 
+	// light client protocol
+	CodeGetLightHeaders Code = 33
+	CodeLightHeaders    Code = 34
+	CodeGetAccountProof Code = 35
+	CodeAccountProof    Code = 36
+	CodeGetStorageProof Code = 37
+	CodeStorageProof    Code = 38
+
 	CodeSyncHandshake   Code = 60
 	CodeSyncHandshakeOK Code = 61
 	CodeSyncRequest     Code = 62
@@ -1,6 +1,8 @@
 package net
 
 import (
+	"time"
+
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
@@ -15,6 +17,10 @@ func (n *mockNet) PeerKey() ed25519.PrivateKey {
 	return nil
 }
 
+func (n *mockNet) Light() *LightClient {
+	return nil
+}
+
 func (n *mockNet) SubscribeSyncStatus(fn SyncStateCallback) (subId int) {
 	return 0
 }
@@ -101,6 +107,49 @@ func (n *mockNet) Nodes() []*vnode.Node {
 	return nil
 }
 
+func (n *mockNet) Traffic() TrafficStatus {
+	return TrafficStatus{}
+}
+
+func (n *mockNet) SyncPeerHeight() uint64 {
+	return 0
+}
+
+func (n *mockNet) PeerScores() []PeerScore {
+	return nil
+}
+
+func (n *mockNet) UnbanPeer(id vnode.NodeID) {
+}
+
+func (n *mockNet) AddPeer(node *vnode.Node) error {
+	return nil
+}
+
+func (n *mockNet) RemovePeer(id vnode.NodeID) error {
+	return nil
+}
+
+func (n *mockNet) BanPeer(id vnode.NodeID, duration time.Duration) error {
+	return nil
+}
+
+func (n *mockNet) TrustPeer(node *vnode.Node) error {
+	return nil
+}
+
+func (n *mockNet) UntrustPeer(id vnode.NodeID) error {
+	return nil
+}
+
+func (n *mockNet) StaticPeers() []StaticPeer {
+	return nil
+}
+
+func (n *mockNet) PeersInfo() []PeerDetail {
+	return nil
+}
+
 func (n *mockNet) PeerCount() int {
 	return 0
 }
@@ -110,6 +110,11 @@ func newQueryHandler(chain Chain) (q *queryHandler, err error) {
 	if err = q.register(&checkHandler{chain, netLog.New("module", "checkHandler")}); err != nil {
 		return nil, err
 	}
+	if lc, ok := chain.(lightChain); ok {
+		if err = q.register(&lightServer{lc}); err != nil {
+			return nil, err
+		}
+	}
 
 	return q, nil
 }
@@ -0,0 +1,181 @@
+package nat
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// the mappings are refreshed before they expire
+const (
+	mapLifetime = 20 * time.Minute
+	mapRefresh  = 15 * time.Minute
+)
+
+// MappingStatus is the state of a port mapping
+type MappingStatus struct {
+	Name         string `json:"name"`
+	Protocol     string `json:"protocol"`
+	InternalPort int    `json:"internalPort"`
+	ExternalPort int    `json:"externalPort"` // zero if not mapped
+	Mapped       bool   `json:"mapped"`
+	Error        string `json:"error,omitempty"`
+}
+
+// Status is the state of the NAT traversal, it is shown in net_nodeInfo
+type Status struct {
+	Method     string          `json:"method"`
+	ExternalIP string          `json:"externalIP"`
+	Mappings   []MappingStatus `json:"mappings"`
+	Error      string          `json:"error,omitempty"`
+	Updated    int64           `json:"updated"` // unix time of the last refresh
+}
+
+// Mapper keeps the port mappings on the NAT device and tracks the external IP.
+// OnUpdate is called after every refresh if set, it must be set before Start.
+type Mapper struct {
+	nat      Interface
+	OnUpdate func(status Status)
+
+	mu     sync.Mutex
+	status Status
+
+	term chan struct{}
+	wg   sync.WaitGroup
+	log  log15.Logger
+}
+
+// NewMapper creates a mapper of the NAT device
+func NewMapper(nat Interface) *Mapper {
+	return &Mapper{
+		nat:  nat,
+		term: make(chan struct{}),
+		log:  log15.New("module", "net/nat"),
+	}
+}
+
+// Add maps the port, the external port requested is the same as the internal one. It must be called before Start.
+func (m *Mapper) Add(protocol string, port int, name string) {
+	m.status.Mappings = append(m.status.Mappings, MappingStatus{
+		Name:         name,
+		Protocol:     protocol,
+		InternalPort: port,
+	})
+}
+
+func (m *Mapper) Start() {
+	m.wg.Add(1)
+	go m.loop()
+}
+
+// Stop deletes the mappings from the device
+func (m *Mapper) Stop() {
+	close(m.term)
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, mp := range m.status.Mappings {
+		if !mp.Mapped {
+			continue
+		}
+		if err := m.nat.DeleteMapping(mp.Protocol, mp.ExternalPort, mp.InternalPort); err != nil {
+			m.log.Debug("failed to delete port mapping", "name", mp.Name, "port", mp.ExternalPort, "err", err)
+		}
+		m.status.Mappings[i].Mapped = false
+	}
+}
+
+// Status returns a copy of the current state
+func (m *Mapper) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.status
+	status.Method = m.nat.String()
+	status.Mappings = make([]MappingStatus, len(m.status.Mappings))
+	copy(status.Mappings, m.status.Mappings)
+
+	return status
+}
+
+// ExternalIP returns the external IP discovered, nil if unknown
+func (m *Mapper) ExternalIP() net.IP {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return net.ParseIP(m.status.ExternalIP)
+}
+
+func (m *Mapper) loop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(mapRefresh)
+	defer ticker.Stop()
+
+	for {
+		m.refresh()
+
+		select {
+		case <-m.term:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh maps the ports again and updates the external IP, the device is discovered at the first time
+func (m *Mapper) refresh() {
+	m.mu.Lock()
+	mappings := make([]MappingStatus, len(m.status.Mappings))
+	copy(mappings, m.status.Mappings)
+	m.mu.Unlock()
+
+	var status Status
+
+	ip, err := m.nat.ExternalIP()
+	if err != nil {
+		status.Error = err.Error()
+		m.log.Warn("failed to get external IP", "nat", m.nat, "err", err)
+	} else {
+		status.ExternalIP = ip.String()
+	}
+
+	for i, mp := range mappings {
+		extport := mp.ExternalPort
+		if extport == 0 {
+			extport = mp.InternalPort
+		}
+
+		extport, err = m.nat.AddMapping(mp.Protocol, extport, mp.InternalPort, mp.Name, mapLifetime)
+		if err != nil {
+			m.log.Warn("failed to map port", "nat", m.nat, "name", mp.Name, "port", mp.InternalPort, "err", err)
+			mappings[i].Mapped = false
+			mappings[i].ExternalPort = 0
+			mappings[i].Error = err.Error()
+			continue
+		}
+
+		if !mp.Mapped || extport != mp.ExternalPort {
+			m.log.Info("mapped port", "nat", m.nat, "name", mp.Name, "port", mp.InternalPort, "external", extport)
+		}
+		mappings[i].Mapped = true
+		mappings[i].ExternalPort = extport
+		mappings[i].Error = ""
+	}
+
+	status.Mappings = mappings
+	status.Updated = time.Now().Unix()
+
+	m.mu.Lock()
+	m.status = status
+	m.mu.Unlock()
+
+	if m.OnUpdate != nil {
+		status.Method = m.nat.String()
+		m.OnUpdate(status)
+	}
+}
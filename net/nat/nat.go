@@ -0,0 +1,189 @@
+// Package nat maps the p2p ports on the NAT device of the local network by UPnP or NAT-PMP,
+// and discovers the external IP, so nodes behind home routers can be dialed by other nodes.
+package nat
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Interface is a NAT device which maps ports
+type Interface interface {
+	// AddMapping maps extport of the device to intport of this host, it returns the external port
+	// actually mapped, which may differ from extport
+	AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error)
+	DeleteMapping(protocol string, extport, intport int) error
+
+	// ExternalIP returns the external (Internet-facing) IP of the device
+	ExternalIP() (net.IP, error)
+
+	// String returns the method and the device, e.g. "UPnP IGDv1-IP1" or "NAT-PMP(192.168.1.1)"
+	String() string
+}
+
+var errNoDevice = errors.New("no UPnP or NAT-PMP device found")
+
+// Parse creates the NAT of the spec:
+//
+//	""/"none"         no port mapping
+//	"any"             the first device found by UPnP or NAT-PMP
+//	"upnp"            UPnP device
+//	"pmp"             NAT-PMP on the default gateway
+//	"pmp:<gateway>"   NAT-PMP on the given gateway
+//	"extip:<ip>"      the router maps the ports manually, the external IP is given
+func Parse(spec string) (Interface, error) {
+	var (
+		parts = strings.SplitN(spec, ":", 2)
+		mech  = strings.ToLower(parts[0])
+		ip    net.IP
+	)
+	if len(parts) > 1 {
+		ip = net.ParseIP(parts[1])
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q of nat %q", parts[1], spec)
+		}
+	}
+
+	switch mech {
+	case "", "none", "off":
+		return nil, nil
+	case "any", "auto", "on":
+		return Any(), nil
+	case "upnp":
+		return UPnP(), nil
+	case "pmp", "natpmp", "nat-pmp":
+		return PMP(ip), nil
+	case "extip", "ip":
+		if ip == nil {
+			return nil, errors.New("missing IP address of nat extip")
+		}
+		return ExtIP(ip), nil
+	default:
+		return nil, fmt.Errorf("unknown nat %q", spec)
+	}
+}
+
+// ExtIP is the external IP of a router with manual port mappings
+type ExtIP net.IP
+
+func (e ExtIP) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error) {
+	return extport, nil
+}
+
+func (e ExtIP) DeleteMapping(protocol string, extport, intport int) error {
+	return nil
+}
+
+func (e ExtIP) ExternalIP() (net.IP, error) {
+	return net.IP(e), nil
+}
+
+func (e ExtIP) String() string {
+	return fmt.Sprintf("ExtIP(%v)", net.IP(e))
+}
+
+// discoverRetry is the interval to discover the devices again after a failure
+const discoverRetry = time.Minute
+
+// autodisc discovers the device lazily on the first use, since discovery may take seconds
+type autodisc struct {
+	what     string
+	discover func() Interface
+
+	discoverMu sync.Mutex // serializes the discoveries
+	failedAt   time.Time
+
+	mu    sync.Mutex
+	found Interface
+}
+
+// Any discovers the device by UPnP and NAT-PMP at the same time, the first one found is used
+func Any() Interface {
+	return &autodisc{what: "UPnP or NAT-PMP", discover: func() Interface {
+		found := make(chan Interface, 2)
+		go func() { found <- discoverUPnP() }()
+		go func() { found <- discoverPMP() }()
+		for i := 0; i < cap(found); i++ {
+			if c := <-found; c != nil {
+				return c
+			}
+		}
+		return nil
+	}}
+}
+
+// UPnP discovers the device by UPnP
+func UPnP() Interface {
+	return &autodisc{what: "UPnP", discover: discoverUPnP}
+}
+
+// PMP uses NAT-PMP on the gateway, the default gateway is discovered if gateway is nil
+func PMP(gateway net.IP) Interface {
+	if gateway != nil {
+		return newPMP(gateway)
+	}
+	return &autodisc{what: "NAT-PMP", discover: discoverPMP}
+}
+
+func (a *autodisc) get() Interface {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.found
+}
+
+func (a *autodisc) wait() (Interface, error) {
+	a.discoverMu.Lock()
+	defer a.discoverMu.Unlock()
+
+	if found := a.get(); found != nil {
+		return found, nil
+	}
+	if !a.failedAt.IsZero() && time.Since(a.failedAt) < discoverRetry {
+		return nil, errNoDevice
+	}
+	found := a.discover()
+	if found == nil {
+		a.failedAt = time.Now()
+		return nil, errNoDevice
+	}
+
+	a.mu.Lock()
+	a.found = found
+	a.mu.Unlock()
+	return found, nil
+}
+
+func (a *autodisc) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error) {
+	found, err := a.wait()
+	if err != nil {
+		return 0, err
+	}
+	return found.AddMapping(protocol, extport, intport, name, lifetime)
+}
+
+func (a *autodisc) DeleteMapping(protocol string, extport, intport int) error {
+	found, err := a.wait()
+	if err != nil {
+		return err
+	}
+	return found.DeleteMapping(protocol, extport, intport)
+}
+
+func (a *autodisc) ExternalIP() (net.IP, error) {
+	found, err := a.wait()
+	if err != nil {
+		return nil, err
+	}
+	return found.ExternalIP()
+}
+
+func (a *autodisc) String() string {
+	if found := a.get(); found != nil {
+		return found.String()
+	}
+	return a.what
+}
@@ -0,0 +1,149 @@
+package nat
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec   string
+		method string
+		err    bool
+	}{
+		{"", "", false},
+		{"none", "", false},
+		{"any", "UPnP or NAT-PMP", false},
+		{"upnp", "UPnP", false},
+		{"pmp", "NAT-PMP", false},
+		{"pmp:192.168.1.1", "NAT-PMP(192.168.1.1)", false},
+		{"extip:1.2.3.4", "ExtIP(1.2.3.4)", false},
+		{"extip", "", true},
+		{"pmp:x", "", true},
+		{"unknown", "", true},
+	}
+
+	for _, test := range tests {
+		n, err := Parse(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("spec %q: expected error", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("spec %q: unexpected error %v", test.spec, err)
+			continue
+		}
+		if test.method == "" {
+			if n != nil {
+				t.Errorf("spec %q: expected no nat, got %v", test.spec, n)
+			}
+			continue
+		}
+		if n == nil || n.String() != test.method {
+			t.Errorf("spec %q: expected %s, got %v", test.spec, test.method, n)
+		}
+	}
+}
+
+type mockNAT struct {
+	mu       sync.Mutex
+	fail     bool
+	mappings map[int]int
+}
+
+func (m *mockNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.fail {
+		return 0, errors.New("mapping refused")
+	}
+	// the device maps tcp ports to other ports
+	if protocol == "tcp" {
+		extport = intport + 10000
+	}
+	m.mappings[intport] = extport
+	return extport, nil
+}
+
+func (m *mockNAT) DeleteMapping(protocol string, extport, intport int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.mappings, intport)
+	return nil
+}
+
+func (m *mockNAT) ExternalIP() (net.IP, error) {
+	return net.IPv4(1, 2, 3, 4), nil
+}
+
+func (m *mockNAT) String() string {
+	return "mock"
+}
+
+func TestMapper(t *testing.T) {
+	device := &mockNAT{mappings: make(map[int]int)}
+	m := NewMapper(device)
+	m.Add("tcp", 8483, "vite p2p")
+	m.Add("udp", 8483, "vite discovery")
+
+	updated := make(chan Status, 1)
+	m.OnUpdate = func(status Status) {
+		updated <- status
+	}
+	m.Start()
+
+	status := <-updated
+	if status.Method != "mock" || status.ExternalIP != "1.2.3.4" || status.Error != "" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if len(status.Mappings) != 2 {
+		t.Fatalf("expected 2 mappings, got %d", len(status.Mappings))
+	}
+	if mp := status.Mappings[0]; !mp.Mapped || mp.ExternalPort != 18483 {
+		t.Errorf("unexpected tcp mapping %+v", mp)
+	}
+	if mp := status.Mappings[1]; !mp.Mapped || mp.ExternalPort != 8483 {
+		t.Errorf("unexpected udp mapping %+v", mp)
+	}
+	if ip := m.ExternalIP(); !ip.Equal(net.IPv4(1, 2, 3, 4)) {
+		t.Errorf("unexpected external IP %v", ip)
+	}
+
+	m.refresh()
+	<-updated
+	if mp := m.Status().Mappings[0]; !mp.Mapped || mp.ExternalPort != 18483 {
+		t.Errorf("unexpected tcp mapping after refresh %+v", mp)
+	}
+
+	device.mu.Lock()
+	device.fail = true
+	device.mu.Unlock()
+	m.refresh()
+	<-updated
+	if mp := m.Status().Mappings[1]; mp.Mapped || mp.Error == "" {
+		t.Errorf("expected failed mapping, got %+v", mp)
+	}
+
+	device.mu.Lock()
+	device.fail = false
+	device.mu.Unlock()
+	m.refresh()
+	<-updated
+
+	m.Stop()
+	if len(device.mappings) != 0 {
+		t.Errorf("expected mappings deleted, got %v", device.mappings)
+	}
+	for _, mp := range m.Status().Mappings {
+		if mp.Mapped {
+			t.Errorf("unexpected mapping after stop %+v", mp)
+		}
+	}
+}
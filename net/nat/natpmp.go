@@ -0,0 +1,64 @@
+package nat
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackpal/gateway"
+	natpmp "github.com/jackpal/go-nat-pmp"
+)
+
+const pmpTimeout = 2 * time.Second
+
+type pmp struct {
+	gateway net.IP
+	client  *natpmp.Client
+}
+
+func newPMP(gateway net.IP) *pmp {
+	return &pmp{gateway: gateway, client: natpmp.NewClientWithTimeout(gateway, pmpTimeout)}
+}
+
+func (n *pmp) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error) {
+	if lifetime <= 0 {
+		return 0, fmt.Errorf("invalid lifetime %v of the mapping", lifetime)
+	}
+	res, err := n.client.AddPortMapping(strings.ToLower(protocol), intport, extport, int(lifetime/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	return int(res.MappedExternalPort), nil
+}
+
+func (n *pmp) DeleteMapping(protocol string, extport, intport int) error {
+	// a mapping is deleted by mapping it again with lifetime 0
+	_, err := n.client.AddPortMapping(strings.ToLower(protocol), intport, 0, 0)
+	return err
+}
+
+func (n *pmp) ExternalIP() (net.IP, error) {
+	res, err := n.client.GetExternalAddress()
+	if err != nil {
+		return nil, err
+	}
+	return res.ExternalIPAddress[:], nil
+}
+
+func (n *pmp) String() string {
+	return fmt.Sprintf("NAT-PMP(%v)", n.gateway)
+}
+
+// discoverPMP checks whether the default gateway speaks NAT-PMP
+func discoverPMP() Interface {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil || gw.To4() == nil {
+		return nil
+	}
+	n := newPMP(gw.To4())
+	if _, err = n.ExternalIP(); err != nil {
+		return nil
+	}
+	return n
+}
@@ -0,0 +1,116 @@
+package nat
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/huin/goupnp"
+	"github.com/huin/goupnp/dcps/internetgateway2"
+)
+
+const (
+	upnpDiscoverTimeout = 3 * time.Second
+	upnpRequestTimeout  = 5 * time.Second
+)
+
+// upnpClient is the common part of the WANIPConnection and WANPPPConnection services
+type upnpClient interface {
+	GetServiceClient() *goupnp.ServiceClient
+	AddPortMappingCtx(ctx context.Context, remoteHost string, extport uint16, protocol string, intport uint16,
+		intclient string, enabled bool, desc string, lease uint32) error
+	DeletePortMappingCtx(ctx context.Context, remoteHost string, extport uint16, protocol string) error
+	GetExternalIPAddressCtx(ctx context.Context) (string, error)
+}
+
+type upnp struct {
+	client  upnpClient
+	service string
+}
+
+func (u *upnp) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) (int, error) {
+	ip := u.client.GetServiceClient().LocalAddr()
+	if ip == nil {
+		return 0, fmt.Errorf("no local address to the device %s", u)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), upnpRequestTimeout)
+	defer cancel()
+
+	protocol = strings.ToUpper(protocol)
+	// the mapping of the same port may be left by the last run, delete it first
+	_ = u.client.DeletePortMappingCtx(ctx, "", uint16(extport), protocol)
+	err := u.client.AddPortMappingCtx(ctx, "", uint16(extport), protocol, uint16(intport), ip.String(), true, name, uint32(lifetime/time.Second))
+	if err != nil {
+		return 0, err
+	}
+	return extport, nil
+}
+
+func (u *upnp) DeleteMapping(protocol string, extport, intport int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), upnpRequestTimeout)
+	defer cancel()
+
+	return u.client.DeletePortMappingCtx(ctx, "", uint16(extport), strings.ToUpper(protocol))
+}
+
+func (u *upnp) ExternalIP() (net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), upnpRequestTimeout)
+	defer cancel()
+
+	str, err := u.client.GetExternalIPAddressCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(str)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid external IP %q of the device %s", str, u)
+	}
+	return ip, nil
+}
+
+func (u *upnp) String() string {
+	return "UPnP " + u.service
+}
+
+// discoverUPnP searches for the Internet Gateway Devices, IGDv2 is preferred
+func discoverUPnP() Interface {
+	ctx, cancel := context.WithTimeout(context.Background(), upnpDiscoverTimeout)
+	defer cancel()
+
+	found := make(chan *upnp, 3)
+	go func() {
+		var u *upnp
+		if clients, _, err := internetgateway2.NewWANIPConnection2ClientsCtx(ctx); err == nil && len(clients) > 0 {
+			u = &upnp{client: clients[0], service: "IGDv2-IP2"}
+		}
+		found <- u
+	}()
+	go func() {
+		var u *upnp
+		if clients, _, err := internetgateway2.NewWANIPConnection1ClientsCtx(ctx); err == nil && len(clients) > 0 {
+			u = &upnp{client: clients[0], service: "IGDv1-IP1"}
+		}
+		found <- u
+	}()
+	go func() {
+		var u *upnp
+		if clients, _, err := internetgateway2.NewWANPPPConnection1ClientsCtx(ctx); err == nil && len(clients) > 0 {
+			u = &upnp{client: clients[0], service: "IGDv1-PPP1"}
+		}
+		found <- u
+	}()
+
+	var result *upnp
+	for i := 0; i < cap(found); i++ {
+		if u := <-found; u != nil && (result == nil || u.service == "IGDv2-IP2") {
+			result = u
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	return result
+}
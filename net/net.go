@@ -20,8 +20,10 @@ import (
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
 	"github.com/vitelabs/go-vite/v2/net/database"
 	"github.com/vitelabs/go-vite/v2/net/discovery"
+	"github.com/vitelabs/go-vite/v2/net/nat"
 	"github.com/vitelabs/go-vite/v2/net/netool"
 	"github.com/vitelabs/go-vite/v2/net/vnode"
 )
@@ -31,11 +33,17 @@ var netLog = log15.New("module", "net")
 var errNetIsRunning = errors.New("network is already running")
 var errNetIsNotRunning = errors.New("network is not running")
 
+const netMetricsCollector = "net"
+
+var peersMetric = monitor.GetOrRegisterGaugeVec("vite_net_peers",
+	"number of connected peers, partitioned by direction", "direction")
+
 const maxNeighbors = 100
 const DBDirName = "db"
 
 type net struct {
-	config  *config.Net
+	config   *config.Net
+	limitsMu sync.RWMutex // protects the peer limits of config, which can be reloaded
 	peerKey ed25519.PrivateKey
 	node    *vnode.Node
 
@@ -47,9 +55,12 @@ type net struct {
 
 	dialer       _net.Dialer
 	listener     _net.Listener
+	quicListener _net.Listener
 	hkr          *handshaker
 	receiveSlots chan struct{}
 
+	nat *nat.Mapper // nil if the ports are not mapped
+
 	confirmedHashHeightList []*ledger.HashHeight
 
 	syncServer *syncServer
@@ -66,10 +77,13 @@ type net struct {
 	BlockSubscriber
 	handlers *msgHandlers
 	query    *queryHandler
+	light    *LightClient
 	hb       *heartBeater
 
 	blackList netool.BlackList
 
+	traffic *traffic
+
 	running int32
 
 	log log15.Logger
@@ -77,18 +91,16 @@ type net struct {
 	wg sync.WaitGroup
 }
 
-func (n *net) listenLoop() {
+func (n *net) listenLoop(listener _net.Listener) {
 	defer n.wg.Done()
 
-	n.receiveSlots = make(chan struct{}, n.config.MaxPendingPeers)
-
 	var tempDelay time.Duration
 	var maxDelay = time.Second
 
 	for {
 		var err error
 		var conn _net.Conn
-		conn, err = n.listener.Accept()
+		conn, err = listener.Accept()
 		if err != nil {
 			if ne, ok := err.(_net.Error); ok && ne.Temporary() {
 				if tempDelay == 0 {
@@ -123,11 +135,12 @@ func (n *net) ConnectNode(node *vnode.Node) (err error) {
 		return
 	}
 
-	if n.blackList.Banned(node.ID.Bytes()) {
+	// trusted nodes are redialed regardless of the bans
+	if !n.peers.rep.isTrusted(node.ID) && (n.blackList.Banned(node.ID.Bytes()) || n.peers.rep.banned(node.ID)) {
 		return fmt.Errorf("node %s has been banned", node.ID)
 	}
 
-	conn, err := _net.Dial("tcp", node.Address())
+	conn, err := n.dial(node.Address())
 	if err != nil {
 		n.blackList.Ban(node.ID.Bytes(), 10)
 		return
@@ -138,19 +151,40 @@ func (n *net) ConnectNode(node *vnode.Node) (err error) {
 	return nil
 }
 
+// dial connects the node by QUIC if it is the transport, and falls back to TCP for nodes without QUIC
+func (n *net) dial(address string) (conn _net.Conn, err error) {
+	if n.config.Transport == config.TransportQUIC {
+		var quicAddr string
+		if quicAddr, err = quicAddress(address); err == nil {
+			if conn, err = dialQUIC(quicAddr, handshakeTimeout); err == nil {
+				return
+			}
+		}
+		n.log.Debug(fmt.Sprintf("failed to dial %s by quic: %v", address, err))
+	}
+
+	return _net.Dial("tcp", address)
+}
+
 func (n *net) onConnection(conn _net.Conn, id peerId, inbound bool) {
 	var c Codec
 	var their *HandshakeMsg
 	var superior bool
 	var err error
 	var flag PeerFlag
+	var latency time.Duration
 	if inbound {
 		flag = PeerFlagInbound
 		c, their, superior, err = n.hkr.ReceiveHandshake(conn)
 		<-n.receiveSlots
 	} else {
 		flag = PeerFlagOutbound
+		if n.finder.isStatic(id) {
+			flag |= PeerFlagStatic
+		}
+		start := time.Now()
 		c, their, superior, err = n.hkr.InitiateHandshake(conn, id)
+		latency = time.Since(start)
 	}
 
 	if err != nil {
@@ -167,6 +201,11 @@ func (n *net) onConnection(conn _net.Conn, id peerId, inbound bool) {
 	}
 
 	peer := newPeer(c, their, publicAddress, fileAddress, superior, flag, n.peers, n.handlers)
+	peer.features = n.hkr.features.negotiate(their.Features)
+	if cc, ok := c.(compressor); ok {
+		cc.setCompress(peer.features.Has(FeatureSnappy))
+	}
+	peer.latency = latency
 
 	// run peer
 	_ = n.onPeerAdded(peer)
@@ -185,6 +224,11 @@ func (n *net) authorize(c Codec, flag PeerFlag, msg *HandshakeMsg) (superior boo
 		return
 	}
 
+	if n.peers.rep.banned(msg.ID) {
+		err = PeerBanned
+		return
+	}
+
 	// is deny
 	var id = msg.ID.String()
 	var key string
@@ -218,13 +262,22 @@ func (n *net) authorize(c Codec, flag PeerFlag, msg *HandshakeMsg) (superior boo
 		return
 	}
 
+	// static or trusted by the operator
+	if n.finder.isStatic(msg.ID) || n.peers.rep.isTrusted(msg.ID) {
+		return
+	}
+
+	n.limitsMu.RLock()
+	maxPeers, maxInbound := n.config.MaxPeers, n.config.MaxPeers/n.config.MaxInboundRatio
+	n.limitsMu.RUnlock()
+
 	// no space
-	if n.peers.countWithoutSBP() >= n.config.MaxPeers {
+	if n.peers.countWithoutSBP() >= maxPeers {
 		err = PeerTooManyPeers
 		return
 	}
 
-	if n.peers.inboundWithoutSBP() >= (n.config.MaxPeers / n.config.MaxInboundRatio) {
+	if n.peers.inboundWithoutSBP() >= maxInbound {
 		err = PeerTooManyInboundPeers
 		return
 	}
@@ -315,10 +368,38 @@ func (n *net) onPeerAdded(peer *Peer) (err error) {
 
 func (n *net) onPeerRemoved(peer *Peer) {
 	_, _ = n.peers.remove(peer.Id)
+	n.storePeer(peer)
 
 	return
 }
 
+// storePeer records the peer to be redialed after restart
+func (n *net) storePeer(pe *Peer) {
+	ep, err := vnode.ParseEndPoint(pe.publicAddress)
+	if err != nil {
+		return
+	}
+	_ = n.db.StoreNode(&vnode.Node{
+		ID:       pe.Id,
+		EndPoint: ep,
+		Net:      n.node.Net,
+	})
+
+	latency := pe.latency.Milliseconds()
+	if latency == 0 {
+		// keep the latency measured by our former dials
+		if r, ok := n.db.RetrievePeer(pe.Id); ok {
+			latency = r.Latency
+		}
+	}
+	n.db.StorePeer(database.PeerRecord{
+		ID:       pe.Id,
+		LastSeen: time.Now().Unix(),
+		Latency:  latency,
+		Score:    int64(n.peers.rep.score(pe.Id)),
+	})
+}
+
 func retrieveAddressBytesFromConfig(address string, port int) (data []byte, err error) {
 	if address != "" {
 		var ep vnode.EndPoint
@@ -346,6 +427,25 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 
 	var err error
 
+	switch cfg.Transport {
+	case "", config.TransportTCP, config.TransportQUIC:
+	default:
+		return nil, fmt.Errorf("unknown p2p transport %q", cfg.Transport)
+	}
+
+	switch cfg.Encryption {
+	case "":
+		cfg.Encryption = config.EncryptionPreferred
+	case config.EncryptionNone, config.EncryptionPreferred, config.EncryptionRequired:
+	default:
+		return nil, fmt.Errorf("unknown p2p encryption %q", cfg.Encryption)
+	}
+
+	natDevice, err := nat.Parse(cfg.NAT)
+	if err != nil {
+		return nil, err
+	}
+
 	var blackHashList = make(map[types.Hash]struct{}, len(cfg.BlackBlockHashList))
 	for _, hexStr := range cfg.BlackBlockHashList {
 		strs := strings.Split(hexStr, "/")
@@ -377,6 +477,11 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		})
 	}
 
+	checkpoints, err := ledger.ParseCheckpoints(cfg.CheckpointList(chain.GetGenesisSnapshotBlock().Hash))
+	if err != nil {
+		return nil, err
+	}
+
 	var peerKey ed25519.PrivateKey
 	peerKey, err = cfg.Init()
 	if err != nil {
@@ -395,6 +500,8 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		Verifier:    verifier,
 	}
 
+	traffic := newTraffic(cfg.UploadLimit, cfg.DownloadLimit, cfg.PeerUploadLimit, cfg.PeerDownloadLimit)
+
 	var id peerId
 	id, _ = vnode.Bytes2NodeID(peerKey.PubByte())
 	syncConnFac := &defaultSyncConnectionFactory{
@@ -403,12 +510,14 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		id:      id,
 		peerKey: peerKey,
 		mineKey: cfg.MineKey,
+		traffic: traffic,
 	}
 	downloader := newExecutor(50, 10, peers, syncConnFac)
 
 	reader := newCacheReader(chain, verifier, downloader, irreader, blackHashList)
 
 	syncer := newSyncer(chain, peers, reader, downloader, irreader, 10*time.Minute, blackHashList)
+	syncer.checkpoints = checkpoints
 
 	fetcher := newFetcher(peers, receiver, blackHashList)
 
@@ -444,6 +553,7 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 
 			return false
 		}),
+		traffic:                 traffic,
 		log:                     netLog,
 		confirmedHashHeightList: confirmedHashList,
 	}
@@ -465,22 +575,40 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		genesis:       chain.GetGenesisSnapshotBlock().Hash,
 		fileAddress:   fileAddress,
 		publicAddress: publicAddress,
+		features:      localFeatures(chain),
 		peerKey:       peerKey,
 		key:           cfg.MineKey,
 		codecFactory: &transportFactory{
 			minCompressLength: 100,
 			readTimeout:       readMsgTimeout,
 			writeTimeout:      writeMsgTimeout,
+			traffic:           traffic,
 		},
 		chain:        chain,
 		blackList:    n.blackList,
 		onHandshaker: n.authorize,
+		encryption:   cfg.Encryption,
+	}
+
+	if cfg.Encryption != config.EncryptionNone {
+		n.hkr.certificate, err = selfSignedCertificate()
+		if err != nil {
+			return nil, err
+		}
+		n.hkr.features |= FeatureEncryption
+	}
+
+	if natDevice != nil {
+		n.nat = newNATMapper(natDevice, cfg)
+		n.nat.OnUpdate = n.onNATUpdate
 	}
 
 	n.db, err = database.New(path.Join(cfg.DataDir, DBDirName), 1, n.node.ID)
 	if err != nil {
 		return nil, err
 	}
+	n.peers.rep = newReputation(n.db)
+	n.reader.peers = n.peers
 
 	if cfg.Discover {
 		n.discover = discovery.New(peerKey, n.node, cfg.BootNodes, cfg.BootSeeds, cfg.ListenInterface+":"+strconv.Itoa(cfg.Port), n.db)
@@ -496,6 +624,16 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		return nil, err
 	}
 
+	for _, str := range cfg.TrustedNodes {
+		var node *vnode.Node
+		node, err = vnode.ParseNode(str)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse trusted node %s: %v", str, err)
+		}
+		n.finder.addStatic(node)
+		n.peers.rep.trust(node.ID, true)
+	}
+
 	if n.finder._selfIsSBP {
 		n.fetcher.sbp = true
 		n.syncer.sbp = true
@@ -541,6 +679,12 @@ func New(cfg *config.Net, chain Chain, verifier Verifier, consensus Consensus, i
 		panic(fmt.Errorf("cannot register handler: syncer: %v", err))
 	}
 
+	// CodeLightHeaders, CodeAccountProof
+	n.light = newLightClient(peers)
+	if err = n.handlers.register(n.light); err != nil {
+		panic(fmt.Errorf("cannot register handler: light: %v", err))
+	}
+
 	return n, nil
 }
 
@@ -572,15 +716,7 @@ func (n *net) beatLoop() {
 			}
 
 			for _, pe := range n.peers.peers() {
-				ep, err := vnode.ParseEndPoint(pe.publicAddress)
-				if err != nil {
-					continue
-				}
-				_ = n.db.StoreNode(&vnode.Node{
-					ID:       pe.Id,
-					EndPoint: ep,
-					Net:      n.node.Net,
-				})
+				n.storePeer(pe)
 
 				var weight int64
 				if pe.Superior {
@@ -665,6 +801,14 @@ func (n *net) Start() (err error) {
 			return
 		}
 
+		if n.config.Transport == config.TransportQUIC {
+			n.quicListener, err = listenQUIC(n.config.ListenInterface + ":" + strconv.Itoa(n.config.Port+quicPortOffset))
+			if err != nil {
+				_ = n.listener.Close()
+				return
+			}
+		}
+
 		if n.discover != nil {
 			err = n.discover.Start()
 			if err != nil {
@@ -672,13 +816,24 @@ func (n *net) Start() (err error) {
 			}
 		}
 
+		n.receiveSlots = make(chan struct{}, n.config.MaxPendingPeers)
+
 		n.wg.Add(1)
-		go n.listenLoop()
+		go n.listenLoop(n.listener)
+
+		if n.quicListener != nil {
+			n.wg.Add(1)
+			go n.listenLoop(n.quicListener)
+		}
 
 		if err = n.syncServer.start(); err != nil {
 			return
 		}
 
+		if n.nat != nil {
+			n.nat.Start()
+		}
+
 		n.finder.start()
 
 		n.downloader.start()
@@ -694,6 +849,7 @@ func (n *net) Start() (err error) {
 		n.wg.Add(1)
 		go n.beatLoop()
 
+		monitor.RegisterCollector(netMetricsCollector, n.reportMetrics)
 		return
 	}
 
@@ -702,11 +858,20 @@ func (n *net) Start() (err error) {
 
 func (n *net) Stop() error {
 	if atomic.CompareAndSwapInt32(&n.running, 1, 0) {
+		monitor.UnregisterCollector(netMetricsCollector)
+
+		if n.nat != nil {
+			n.nat.Stop()
+		}
+
 		if n.discover != nil {
 			_ = n.discover.Stop()
 		}
 
 		_ = n.listener.Close()
+		if n.quicListener != nil {
+			_ = n.quicListener.Close()
+		}
 
 		n.reader.stop()
 
@@ -731,6 +896,109 @@ func (n *net) Stop() error {
 	return errNetIsNotRunning
 }
 
+// Traffic returns the bytes and messages transferred since the node started
+func (n *net) Traffic() TrafficStatus {
+	return n.traffic.status()
+}
+
+// PeerScores returns the reputation of the peers which have misbehaved
+func (n *net) PeerScores() []PeerScore {
+	return n.peers.rep.list()
+}
+
+// UnbanPeer lifts the ban of the peer and resets its score
+func (n *net) UnbanPeer(id vnode.NodeID) {
+	n.peers.rep.unban(id)
+	n.blackList.UnBan(id.Bytes())
+}
+
+// AddPeer connects the node and keeps it connected as a static node
+func (n *net) AddPeer(node *vnode.Node) error {
+	if node.ID == n.node.ID {
+		return PeerConnectSelf
+	}
+
+	n.finder.addStatic(node)
+	if err := n.ConnectNode(node); err != nil && err != PeerAlreadyConnected {
+		return err
+	}
+	return nil
+}
+
+// RemovePeer disconnects the peer, and stops redialing it if it is static or trusted
+func (n *net) RemovePeer(id vnode.NodeID) error {
+	static := n.finder.removeStatic(id)
+	n.peers.rep.trust(id, false)
+
+	if p := n.peers.get(id); p != nil {
+		p.catch(PeerQuitting)
+		return nil
+	}
+	if !static {
+		return errPeerNotExist
+	}
+	return nil
+}
+
+// BanPeer disconnects the peer and refuses it for the duration
+func (n *net) BanPeer(id vnode.NodeID, duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("invalid ban duration %v", duration)
+	}
+
+	n.finder.removeStatic(id)
+	n.peers.rep.ban(id, duration)
+	if p := n.peers.get(id); p != nil {
+		p.catch(PeerBanned)
+	}
+	return nil
+}
+
+// TrustPeer keeps the node connected even if the peer limits are reached, and it is never demoted or banned
+func (n *net) TrustPeer(node *vnode.Node) error {
+	if node.ID == n.node.ID {
+		return PeerConnectSelf
+	}
+
+	n.peers.rep.trust(node.ID, true)
+	n.blackList.UnBan(node.ID.Bytes())
+	return n.AddPeer(node)
+}
+
+// UntrustPeer revokes the trust of the peer, it is still kept connected as a static node
+func (n *net) UntrustPeer(id vnode.NodeID) error {
+	if !n.peers.rep.isTrusted(id) {
+		return errPeerNotExist
+	}
+
+	n.peers.rep.trust(id, false)
+	return nil
+}
+
+// StaticPeers returns the static nodes, which are redialed when disconnected
+func (n *net) StaticPeers() []StaticPeer {
+	nodes := n.finder.statics()
+	peers := make([]StaticPeer, len(nodes))
+	for i, node := range nodes {
+		peers[i] = StaticPeer{
+			Node:      node.String(),
+			Trusted:   n.peers.rep.isTrusted(node.ID),
+			Connected: n.peers.has(node.ID),
+		}
+	}
+	return peers
+}
+
+// PeersInfo returns the details of the connected peers
+func (n *net) PeersInfo() []PeerDetail {
+	ps := n.peers.peers()
+	details := make([]PeerDetail, len(ps))
+	for i, p := range ps {
+		details[i] = p.detail(n.peers.rep.score(p.Id), n.peers.rep.isTrusted(p.Id))
+	}
+	return details
+}
+
 func (n *net) Nodes() []*vnode.Node {
 	return n.discover.Nodes()
 }
@@ -739,10 +1007,57 @@ func (n *net) PeerKey() ed25519.PrivateKey {
 	return n.peerKey
 }
 
+// Light returns the client of light protocol
+func (n *net) Light() *LightClient {
+	return n.light
+}
+
 func (n *net) PeerCount() int {
 	return n.peers.count()
 }
 
+// SyncPeerHeight returns the height of the peer chosen to sync from, it's 0 if there is no peer
+func (n *net) SyncPeerHeight() uint64 {
+	if p := n.peers.syncPeer(); p != nil {
+		return p.Height
+	}
+	return 0
+}
+
+// ReloadConfig applies the peer limits, connected peers are not disconnected
+func (n *net) ReloadConfig(cfg *config.Reloadable) error {
+	if cfg.MaxPeers <= 0 || cfg.MinPeers < 0 || cfg.MaxInboundRatio <= 0 {
+		return fmt.Errorf("invalid peer limits, MaxPeers %d, MinPeers %d, MaxInboundRatio %d",
+			cfg.MaxPeers, cfg.MinPeers, cfg.MaxInboundRatio)
+	}
+
+	n.limitsMu.Lock()
+	n.config.MaxPeers = cfg.MaxPeers
+	n.config.MinPeers = cfg.MinPeers
+	n.config.MaxInboundRatio = cfg.MaxInboundRatio
+	n.limitsMu.Unlock()
+
+	if n.finder != nil {
+		n.finder.setMinPeers(cfg.MinPeers)
+	}
+	return nil
+}
+
+func (n *net) reportMetrics() {
+	var inbound, outbound int
+	for _, p := range n.peers.peers() {
+		if p.Flag.is(PeerFlagInbound) {
+			inbound++
+		} else {
+			outbound++
+		}
+	}
+	peersMetric.With("inbound").Set(float64(inbound))
+	peersMetric.With("outbound").Set(float64(outbound))
+
+	n.traffic.reportMetrics()
+}
+
 func (n *net) Info() NodeInfo {
 	ps := n.peers.info()
 	info := NodeInfo{
@@ -764,6 +1079,11 @@ func (n *net) Info() NodeInfo {
 		info.Server = n.syncServer.status()
 	}
 
+	if n.nat != nil {
+		status := n.nat.Status()
+		info.NAT = &status
+	}
+
 	return info
 }
 
@@ -780,4 +1100,5 @@ type NodeInfo struct {
 	Latency               []int64          `json:"latency"` // [0,1,12,24]
 	BroadCheckFailedRatio float32          `json:"broadCheckFailedRatio"`
 	Server                FileServerStatus `json:"server"`
+	NAT                   *nat.Status      `json:"nat,omitempty"`
 }
@@ -80,6 +80,37 @@ type PeerInfo struct {
 	ReadQueue  int      `json:"readQueue"`
 	WriteQueue int      `json:"writeQueue"`
 	Peers      []string `json:"peers"`
+	Features   []string `json:"features"`
+}
+
+// PeerDetail is the connection of the peer for the operator
+type PeerDetail struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Address     string `json:"address"`
+	Direction   string `json:"direction"` // inbound or outbound
+	Static      bool   `json:"static"`
+	Trusted     bool   `json:"trusted"`
+	Transport   string `json:"transport"` // tcp or quic
+	Version     int64  `json:"version"`
+	Height      uint64 `json:"height"`
+	Head        string `json:"head"`
+	Superior    bool   `json:"superior"`
+	Reliable    bool   `json:"reliable"`
+	Score       int    `json:"score"`
+	Latency     int64  `json:"latency"` // milliseconds of the handshake round trip, zero if unknown
+	TrafficIn   uint64 `json:"trafficIn"`
+	TrafficOut  uint64 `json:"trafficOut"`
+	ConnectedAt string `json:"connectedAt"`
+
+	Features []string `json:"features"`
+}
+
+// StaticPeer is a node kept connected by the operator
+type StaticPeer struct {
+	Node      string `json:"node"`
+	Trusted   bool   `json:"trusted"`
+	Connected bool   `json:"connected"`
 }
 
 type PeerFlag byte
@@ -114,6 +145,10 @@ type Peer struct {
 	Flag     PeerFlag
 	Superior bool
 
+	features Feature // negotiated in the handshake
+
+	latency time.Duration // round trip of the handshake, zero for inbound peers
+
 	reliable int32 // whether the same chain
 
 	busy  int32
@@ -221,6 +256,44 @@ func (p *Peer) Info() PeerInfo {
 		ReadQueue:  len(p.readQueue),
 		WriteQueue: len(p.writeQueue),
 		Peers:      ps,
+		Features:   p.features.Names(),
+	}
+}
+
+// Supports reports whether the features are supported by both the peer and the node
+func (p *Peer) Supports(features Feature) bool {
+	return p.features.Has(features)
+}
+
+func (p *Peer) detail(score int, trusted bool) PeerDetail {
+	direction, transport := "inbound", "tcp"
+	if p.Flag.is(PeerFlagOutbound) {
+		direction = "outbound"
+	}
+	if _, ok := p.codec.(*quicCodec); ok {
+		transport = "quic"
+	}
+	in, out := codecTraffic(p.codec)
+
+	return PeerDetail{
+		Id:          p.Id.String(),
+		Name:        p.Name,
+		Address:     p.codec.Address().String(),
+		Direction:   direction,
+		Static:      p.Flag.is(PeerFlagStatic),
+		Trusted:     trusted,
+		Transport:   transport,
+		Version:     p.Version,
+		Height:      p.Height,
+		Head:        p.Head.String(),
+		Superior:    p.Superior,
+		Reliable:    p.isReliable(),
+		Score:       score,
+		Latency:     p.latency.Milliseconds(),
+		TrafficIn:   in,
+		TrafficOut:  out,
+		ConnectedAt: time.Unix(p.CreateAt, 0).Format("2006-01-02 15:04:05"),
+		Features:    p.features.Names(),
 	}
 }
 
@@ -493,6 +566,8 @@ type peerSet struct {
 	m   map[peerId]*Peer
 	prw sync.RWMutex
 
+	rep *reputation
+
 	subs []chan<- peerEvent
 }
 
@@ -501,7 +576,7 @@ func (m *peerSet) reliable() (l peers) {
 	defer m.prw.RUnlock()
 
 	for _, p := range m.m {
-		if atomic.LoadInt32(&p.reliable) == 1 {
+		if atomic.LoadInt32(&p.reliable) == 1 && (m.rep == nil || !m.rep.demoted(p.Id)) {
 			l = append(l, p)
 		}
 	}
@@ -529,7 +604,7 @@ func (m *peerSet) pickDownloadPeers(height uint64) (m2 map[peerId]*Peer) {
 	defer m.prw.RUnlock()
 
 	for id, p := range m.m {
-		if p.Height >= height {
+		if p.Height >= height && p.Supports(FeatureSnapshotSync) {
 			m2[id] = p
 		}
 	}
@@ -570,11 +645,19 @@ func (m *peerSet) notify(e peerEvent) {
 
 // bestPeer is the tallest peer
 func (m *peerSet) bestPeer() (best *Peer) {
+	return m.bestPeerWith(0)
+}
+
+// bestPeerWith is the tallest peer supporting the features
+func (m *peerSet) bestPeerWith(features Feature) (best *Peer) {
 	m.prw.RLock()
 	defer m.prw.RUnlock()
 
 	var maxHeight uint64
 	for _, p := range m.m {
+		if !p.Supports(features) {
+			continue
+		}
 		peerHeight := p.Height
 		if peerHeight > maxHeight {
 			maxHeight = peerHeight
@@ -41,6 +41,7 @@ const (
 	PeerInvalidMessage
 	PeerResponseTimeout
 	PeerInvalidToken
+	PeerEncryptionRequired
 	PeerUnknownReason PeerError = 255
 )
 
@@ -65,6 +66,7 @@ var peerErrStr = map[PeerError]string{
 	PeerInvalidMessage:      "invalid message",
 	PeerResponseTimeout:     "response timeout",
 	PeerInvalidToken:        "invalid token",
+	PeerEncryptionRequired:  "encryption required",
 	PeerUnknownReason:       "unknown reason",
 }
 
@@ -0,0 +1,67 @@
+package net
+
+import (
+	_net "net"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/net/nat"
+	"github.com/vitelabs/go-vite/v2/net/netool"
+)
+
+// names of the port mappings on NAT
+const (
+	natMappingP2P       = "vite p2p"
+	natMappingDiscovery = "vite discovery"
+	natMappingFile      = "vite file"
+	natMappingQUIC      = "vite quic"
+)
+
+func newNATMapper(device nat.Interface, cfg *config.Net) *nat.Mapper {
+	m := nat.NewMapper(device)
+	m.Add("tcp", cfg.Port, natMappingP2P)
+	if cfg.Discover {
+		m.Add("udp", cfg.Port, natMappingDiscovery)
+	}
+	m.Add("tcp", cfg.FilePort, natMappingFile)
+	if cfg.Transport == config.TransportQUIC {
+		m.Add("udp", cfg.Port+quicPortOffset, natMappingQUIC)
+	}
+	return m
+}
+
+// onNATUpdate advertises the external addresses in handshake, unless the public addresses are configured
+func (n *net) onNATUpdate(status nat.Status) {
+	publicAddress := natAddress(status, natMappingP2P, n.config.PublicAddress)
+	fileAddress := natAddress(status, natMappingFile, n.config.FilePublicAddress)
+
+	var err error
+	var public, file []byte
+	if public, err = retrieveAddressBytesFromConfig(publicAddress, n.config.Port); err != nil {
+		n.log.Warn("failed to parse external address", "address", publicAddress, "err", err)
+		return
+	}
+	if file, err = retrieveAddressBytesFromConfig(fileAddress, n.config.FilePort); err != nil {
+		n.log.Warn("failed to parse external address", "address", fileAddress, "err", err)
+		return
+	}
+
+	n.hkr.setAddress(public, file)
+}
+
+// natAddress returns the external address of the mapping, or the configured address.
+// The external IP in LAN is behind another NAT, it is useless to other nodes.
+func natAddress(status nat.Status, name string, configured string) string {
+	if configured != "" {
+		return configured
+	}
+	if ip := _net.ParseIP(status.ExternalIP); ip == nil || netool.IsLAN(ip) {
+		return configured
+	}
+	for _, mp := range status.Mappings {
+		if mp.Name == name && mp.Mapped {
+			return _net.JoinHostPort(status.ExternalIP, strconv.Itoa(mp.ExternalPort))
+		}
+	}
+	return configured
+}
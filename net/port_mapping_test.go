@@ -0,0 +1,32 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/net/nat"
+)
+
+func TestNatAddress(t *testing.T) {
+	status := nat.Status{
+		ExternalIP: "1.2.3.4",
+		Mappings: []nat.MappingStatus{
+			{Name: natMappingP2P, Protocol: "tcp", InternalPort: 8483, ExternalPort: 18483, Mapped: true},
+			{Name: natMappingFile, Protocol: "tcp", InternalPort: 8484},
+		},
+	}
+
+	if addr := natAddress(status, natMappingP2P, ""); addr != "1.2.3.4:18483" {
+		t.Errorf("unexpected p2p address %s", addr)
+	}
+	if addr := natAddress(status, natMappingP2P, "5.6.7.8:8483"); addr != "5.6.7.8:8483" {
+		t.Errorf("configured address should be kept, got %s", addr)
+	}
+	if addr := natAddress(status, natMappingFile, ""); addr != "" {
+		t.Errorf("unmapped port should not be advertised, got %s", addr)
+	}
+
+	status.ExternalIP = "192.168.1.2"
+	if addr := natAddress(status, natMappingP2P, ""); addr != "" {
+		t.Errorf("LAN address should not be advertised, got %s", addr)
+	}
+}
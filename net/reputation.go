@@ -0,0 +1,341 @@
+package net
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+// offense is a misbehavior of a peer, it lowers the score of the peer
+type offense byte
+
+const (
+	offenseInvalidBlock offense = iota // broadcast a block failed to verify
+	offenseProtocol                    // sent a malformed or unexpected message
+	offenseTimeout                     // didn't respond to a request in time
+	offenseUselessChunk                // served a chunk which can't be read or verified
+	offenseCount
+)
+
+var offensePenalty = [offenseCount]int{
+	offenseInvalidBlock: 40,
+	offenseProtocol:     20,
+	offenseTimeout:      5,
+	offenseUselessChunk: 30,
+}
+
+var offenseStr = [offenseCount]string{
+	offenseInvalidBlock: "invalid block",
+	offenseProtocol:     "protocol violation",
+	offenseTimeout:      "timeout",
+	offenseUselessChunk: "useless chunk",
+}
+
+func (o offense) String() string {
+	if o < offenseCount {
+		return offenseStr[o]
+	}
+	return "unknown offense"
+}
+
+const (
+	maxPeerScore    = 100
+	demotePeerScore = 50 // peers below it are not reliable for syncing and fetching
+	banPeerScore    = 0  // peers reaching it are disconnected and banned
+	// the score recovers by a point every scoreRecoverInterval
+	scoreRecoverInterval = time.Minute
+	// the first ban lasts peerBanDuration, the following ones double, up to maxPeerBanDuration
+	peerBanDuration    = time.Hour
+	maxPeerBanDuration = 16 * time.Hour
+)
+
+type peerAction byte
+
+const (
+	peerActionNone peerAction = iota
+	peerActionDemote
+	peerActionBan
+)
+
+// PeerScore is the reputation of a peer
+type PeerScore struct {
+	Id                 string `json:"id"`
+	Score              int    `json:"score"`
+	InvalidBlocks      int    `json:"invalidBlocks"`
+	ProtocolViolations int    `json:"protocolViolations"`
+	Timeouts           int    `json:"timeouts"`
+	UselessChunks      int    `json:"uselessChunks"`
+	Bans               int    `json:"bans"`
+	BannedUntil        int64  `json:"bannedUntil"` // unix time, zero if not banned
+}
+
+type peerScore struct {
+	score       int
+	offenses    [offenseCount]int
+	bans        int
+	bannedUntil time.Time
+	updated     time.Time
+}
+
+// recover adds the points recovered since last update
+func (s *peerScore) recover(now time.Time) {
+	if n := int(now.Sub(s.updated) / scoreRecoverInterval); n > 0 {
+		s.score += n
+		if s.score > maxPeerScore {
+			s.score = maxPeerScore
+		}
+		s.updated = s.updated.Add(time.Duration(n) * scoreRecoverInterval)
+	}
+}
+
+// banStore persists the bans across restarts
+type banStore interface {
+	BlockId(id vnode.NodeID, expiration int64)
+	UnblockId(id vnode.NodeID)
+	BlockedIds() map[vnode.NodeID]int64
+}
+
+// reputation scores peers on their misbehaviors, peers with low scores are demoted and then banned
+type reputation struct {
+	mu      sync.Mutex
+	scores  map[peerId]*peerScore
+	trusted map[peerId]struct{} // trusted peers are never demoted or banned
+	store   banStore
+	log     log15.Logger
+}
+
+func newReputation(store banStore) *reputation {
+	r := &reputation{
+		scores:  make(map[peerId]*peerScore),
+		trusted: make(map[peerId]struct{}),
+		store:   store,
+		log:     netLog.New("module", "reputation"),
+	}
+
+	if store != nil {
+		now := time.Now()
+		for id, expiration := range store.BlockedIds() {
+			until := time.Unix(expiration, 0)
+			if until.Before(now) {
+				store.UnblockId(id)
+				continue
+			}
+			r.scores[id] = &peerScore{
+				score:       banPeerScore,
+				bans:        1,
+				bannedUntil: until,
+				updated:     until,
+			}
+		}
+	}
+
+	return r
+}
+
+func (r *reputation) get(id peerId, now time.Time) *peerScore {
+	s, ok := r.scores[id]
+	if !ok {
+		s = &peerScore{score: maxPeerScore, updated: now}
+		r.scores[id] = s
+	} else if now.After(s.updated) {
+		s.recover(now)
+	}
+	return s
+}
+
+// offend records the offense of the peer and returns the action to take
+func (r *reputation) offend(id peerId, o offense) (action peerAction) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := r.get(id, now)
+	s.offenses[o]++
+	if _, ok := r.trusted[id]; ok {
+		return peerActionNone
+	}
+	s.score -= offensePenalty[o]
+
+	if s.score <= banPeerScore {
+		if now.Before(s.bannedUntil) {
+			return peerActionBan
+		}
+		duration := peerBanDuration << uint(s.bans)
+		if duration > maxPeerBanDuration {
+			duration = maxPeerBanDuration
+		}
+		s.bans++
+		s.score = banPeerScore
+		s.bannedUntil = now.Add(duration)
+		// recover after the ban
+		s.updated = s.bannedUntil
+		if r.store != nil {
+			r.store.BlockId(id, s.bannedUntil.Unix())
+		}
+		r.log.Warn("ban peer", "id", id, "offense", o, "bans", s.bans, "until", s.bannedUntil)
+		return peerActionBan
+	}
+
+	if s.score < demotePeerScore {
+		r.log.Info("demote peer", "id", id, "offense", o, "score", s.score)
+		return peerActionDemote
+	}
+
+	return peerActionNone
+}
+
+// ban bans the peer for the duration by the operator
+func (r *reputation) ban(id peerId, duration time.Duration) {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.trusted, id)
+	s := r.get(id, now)
+	s.bans++
+	s.score = banPeerScore
+	s.bannedUntil = now.Add(duration)
+	s.updated = s.bannedUntil
+	if r.store != nil {
+		r.store.BlockId(id, s.bannedUntil.Unix())
+	}
+	r.log.Warn("ban peer", "id", id, "until", s.bannedUntil)
+}
+
+// trust exempts the peer from demotions and bans, the ban of it is lifted
+func (r *reputation) trust(id peerId, trusted bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !trusted {
+		delete(r.trusted, id)
+		return
+	}
+
+	r.trusted[id] = struct{}{}
+	if s, ok := r.scores[id]; ok {
+		s.score = maxPeerScore
+		s.bannedUntil = time.Time{}
+		s.updated = time.Now()
+		if r.store != nil {
+			r.store.UnblockId(id)
+		}
+	}
+}
+
+func (r *reputation) isTrusted(id peerId) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.trusted[id]
+	return ok
+}
+
+// score returns the current score of the peer
+func (r *reputation) score(id peerId) int {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.scores[id]
+	if !ok {
+		return maxPeerScore
+	}
+	if now.After(s.updated) {
+		s.recover(now)
+	}
+	return s.score
+}
+
+// banned reports whether the peer is banned now
+func (r *reputation) banned(id peerId) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.scores[id]
+	return ok && time.Now().Before(s.bannedUntil)
+}
+
+// demoted reports whether the score of the peer is too low to be reliable
+func (r *reputation) demoted(id peerId) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.scores[id]
+	if !ok {
+		return false
+	}
+	s.recover(time.Now())
+	return s.score < demotePeerScore
+}
+
+// unban lifts the ban and resets the score of the peer
+func (r *reputation) unban(id peerId) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.scores, id)
+	if r.store != nil {
+		r.store.UnblockId(id)
+	}
+}
+
+// list returns the scores of the peers with offenses, sorted by score ascending
+func (r *reputation) list() []PeerScore {
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]PeerScore, 0, len(r.scores))
+	for id, s := range r.scores {
+		if now.After(s.updated) {
+			s.recover(now)
+		}
+		ps := PeerScore{
+			Id:                 id.String(),
+			Score:              s.score,
+			InvalidBlocks:      s.offenses[offenseInvalidBlock],
+			ProtocolViolations: s.offenses[offenseProtocol],
+			Timeouts:           s.offenses[offenseTimeout],
+			UselessChunks:      s.offenses[offenseUselessChunk],
+			Bans:               s.bans,
+		}
+		if now.Before(s.bannedUntil) {
+			ps.BannedUntil = s.bannedUntil.Unix()
+		}
+		list = append(list, ps)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Score < list[j].Score
+	})
+
+	return list
+}
+
+// offend records the offense of the peer, demotes or disconnects it if the score is too low
+func (m *peerSet) offend(id peerId, o offense) {
+	if m.rep == nil {
+		return
+	}
+
+	action := m.rep.offend(id, o)
+	p := m.get(id)
+	if p == nil {
+		return
+	}
+
+	switch action {
+	case peerActionDemote:
+		p.setReliable(false)
+	case peerActionBan:
+		p.catch(PeerBanned)
+	}
+}
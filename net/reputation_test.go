@@ -0,0 +1,149 @@
+package net
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/net/database"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+func TestReputation(t *testing.T) {
+	db, err := database.New("", 1, vnode.ZERO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	id := vnode.RandomNodeID()
+	rep := newReputation(db)
+
+	if action := rep.offend(id, offenseTimeout); action != peerActionNone {
+		t.Fatalf("unexpected action %d", action)
+	}
+	if action := rep.offend(id, offenseInvalidBlock); action != peerActionNone {
+		t.Fatalf("unexpected action %d", action)
+	}
+	if action := rep.offend(id, offenseInvalidBlock); action != peerActionDemote {
+		t.Fatalf("unexpected action %d", action)
+	}
+	if !rep.demoted(id) || rep.banned(id) {
+		t.Fatal("peer should be demoted")
+	}
+	if action := rep.offend(id, offenseInvalidBlock); action != peerActionBan {
+		t.Fatalf("unexpected action %d", action)
+	}
+	if !rep.banned(id) {
+		t.Fatal("peer should be banned")
+	}
+
+	list := rep.list()
+	if len(list) != 1 || list[0].Id != id.String() || list[0].InvalidBlocks != 3 || list[0].Timeouts != 1 || list[0].Bans != 1 {
+		t.Fatalf("unexpected scores %+v", list)
+	}
+	if until := time.Unix(list[0].BannedUntil, 0); until.Before(time.Now().Add(peerBanDuration - time.Minute)) {
+		t.Fatalf("unexpected ban expiration %s", until)
+	}
+
+	// the ban survives restarts
+	rep = newReputation(db)
+	if !rep.banned(id) {
+		t.Fatal("ban should be restored")
+	}
+
+	rep.unban(id)
+	if rep.banned(id) || rep.demoted(id) {
+		t.Fatal("peer should be unbanned")
+	}
+	if rep = newReputation(db); rep.banned(id) {
+		t.Fatal("unban should be persisted")
+	}
+}
+
+func TestReputation_banTrust(t *testing.T) {
+	db, err := database.New("", 1, vnode.ZERO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	id := vnode.RandomNodeID()
+	rep := newReputation(db)
+
+	rep.ban(id, time.Hour)
+	if !rep.banned(id) || rep.score(id) != banPeerScore {
+		t.Fatal("peer should be banned")
+	}
+	if rep = newReputation(db); !rep.banned(id) {
+		t.Fatal("ban should be restored")
+	}
+
+	rep.trust(id, true)
+	if !rep.isTrusted(id) || rep.banned(id) || rep.score(id) != maxPeerScore {
+		t.Fatal("trusted peer should be unbanned")
+	}
+	for i := 0; i < 10; i++ {
+		if action := rep.offend(id, offenseInvalidBlock); action != peerActionNone {
+			t.Fatalf("unexpected action %d of trusted peer", action)
+		}
+	}
+	if rep.demoted(id) || rep.banned(id) {
+		t.Fatal("trusted peer should not be demoted")
+	}
+
+	// the ban by the operator overrides the trust
+	rep.ban(id, time.Hour)
+	if rep.isTrusted(id) || !rep.banned(id) {
+		t.Fatal("peer should be banned")
+	}
+}
+
+func TestNet_authorizeStatic(t *testing.T) {
+	peers := newPeerSet()
+	peers.rep = newReputation(nil)
+	n := &net{
+		config: &config.Net{
+			MaxPeers:        1,
+			MaxInboundRatio: 1,
+			AccessControl:   "any",
+		},
+		node:   &vnode.Node{ID: vnode.RandomNodeID()},
+		peers:  peers,
+		finder: &finder{peers: peers},
+	}
+	connected := vnode.RandomNodeID()
+	peers.m[connected] = &Peer{Id: connected}
+
+	static, trusted, other := vnode.RandomNodeID(), vnode.RandomNodeID(), vnode.RandomNodeID()
+	n.finder.addStatic(&vnode.Node{ID: static})
+	peers.rep.trust(trusted, true)
+
+	if _, err := n.authorize(nil, PeerFlagInbound, &HandshakeMsg{ID: other}); err != PeerTooManyPeers {
+		t.Fatalf("peer beyond the limits should be refused: %v", err)
+	}
+	for _, id := range []vnode.NodeID{static, trusted} {
+		if _, err := n.authorize(nil, PeerFlagInbound, &HandshakeMsg{ID: id}); err != nil {
+			t.Fatalf("static and trusted peers should be accepted beyond the limits: %v", err)
+		}
+	}
+
+	// untrusted peers are limited again
+	peers.rep.trust(trusted, false)
+	if _, err := n.authorize(nil, PeerFlagInbound, &HandshakeMsg{ID: trusted}); err != PeerTooManyPeers {
+		t.Fatalf("untrusted peer should be refused: %v", err)
+	}
+}
+
+func TestPeerScore_recover(t *testing.T) {
+	now := time.Now()
+	s := &peerScore{score: 10, updated: now.Add(-5*scoreRecoverInterval - time.Second)}
+	s.recover(now)
+	if s.score != 15 {
+		t.Fatalf("unexpected score %d", s.score)
+	}
+	s.recover(now.Add(1000 * scoreRecoverInterval))
+	if s.score != maxPeerScore {
+		t.Fatalf("unexpected score %d", s.score)
+	}
+}
@@ -195,7 +195,10 @@ func (sk *skeleton) getHashList(p *Peer, msg *GetHashHeightList) {
 		sk.mu.Unlock()
 
 		time.AfterFunc(getHashHeightListTimeout, func() {
-			sk.getHashListFailed(mid, p, errTimeout)
+			if sk.isPending(mid) {
+				sk.peers.offend(p.Id, offenseTimeout)
+				sk.getHashListFailed(mid, p, errTimeout)
+			}
 		})
 	}
 }
@@ -205,6 +208,7 @@ func (sk *skeleton) receiveHashList(msg Msg, sender *Peer) {
 		var hh = &HashHeightPointList{}
 		err := hh.Deserialize(msg.Payload)
 		if err != nil {
+			sk.peers.offend(sender.Id, offenseProtocol)
 			sk.getHashListFailed(msg.Id, sender, err)
 			return
 		}
@@ -232,6 +236,13 @@ func (sk *skeleton) getHashListFailed(id MsgId, sender *Peer, err error) {
 	netLog.Warn(fmt.Sprintf("failed to get HashHeight list from %s: %v", sender, err))
 }
 
+func (sk *skeleton) isPending(id MsgId) bool {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	_, ok := sk.pending[id]
+	return ok
+}
+
 func (sk *skeleton) removePending(id MsgId) {
 	sk.mu.Lock()
 	if _, ok := sk.pending[id]; ok {
@@ -81,6 +81,7 @@ type cacheReader struct {
 	buffer Chunks
 
 	downloadRecord map[string]peerId
+	peers          *peerSet // scores the peers serving useless chunks
 
 	blackBlocks map[types.Hash]struct{}
 
@@ -563,6 +564,9 @@ func (s *cacheReader) chunkReadFailed(segment interfaces.Segment, fatal bool) {
 		s.mu.Unlock()
 
 		s.downloader.addBlackList(id)
+		if s.peers != nil {
+			s.peers.offend(id, offenseUselessChunk)
+		}
 		s.log.Warn(fmt.Sprintf("block sync peer: %s", id))
 
 		cache := s.chain.GetSyncCache()
@@ -635,6 +639,9 @@ func (s *cacheReader) read(c interfaces.Segment) (chunk *Chunk, fatal bool, err
 
 	var ab *ledger.AccountBlock
 	var sb *ledger.SnapshotBlock
+	// blocks of an unverified chunk are verified together after read
+	var abs []*ledger.AccountBlock
+	var sbs []*ledger.SnapshotBlock
 	for {
 		if false == s.running || false == s.canRead() {
 			_ = reader.Close()
@@ -657,9 +664,7 @@ func (s *cacheReader) read(c interfaces.Segment) (chunk *Chunk, fatal bool, err
 			}
 
 			if verified == false {
-				if err = s.verifier.VerifyNetAccountBlock(ab); err != nil {
-					break
-				}
+				abs = append(abs, ab)
 			}
 
 			if err = chunk.addAccountBlock(ab); err != nil {
@@ -673,9 +678,7 @@ func (s *cacheReader) read(c interfaces.Segment) (chunk *Chunk, fatal bool, err
 			}
 
 			if verified == false {
-				if err = s.verifier.VerifyNetSnapshotBlock(sb); err != nil {
-					break
-				}
+				sbs = append(sbs, sb)
 			}
 
 			if err = chunk.addSnapshotBlock(sb); err != nil {
@@ -690,6 +693,10 @@ func (s *cacheReader) read(c interfaces.Segment) (chunk *Chunk, fatal bool, err
 		err = chunk.done()
 	}
 
+	if err == nil && verified == false {
+		err = s.verifier.VerifyNetBlocks(abs, sbs)
+	}
+
 	// no error, set reader verified
 	if err == nil {
 		reader.Verify()
@@ -698,6 +705,77 @@ func (s *cacheReader) read(c interfaces.Segment) (chunk *Chunk, fatal bool, err
 	return
 }
 
+// linkCheckpoint links the cached chunks after c down from the latest checkpoint by hash, so that the blocks of c
+// are trusted by hash when c is verified. Nothing is linked if a chunk between c and the checkpoint is missing,
+// the signatures of c are verified then.
+func (s *cacheReader) linkCheckpoint(cs interfaces.SegmentList, c interfaces.Segment) {
+	cp := s.verifier.Checkpoints().Latest()
+	if cp == nil || c.To >= cp.Height || s.verifier.CheckpointLinked(c.Hash, c.To) {
+		return
+	}
+
+	// the chunks after c, up to the chunk of the checkpoint or a linked chunk
+	var above interfaces.SegmentList
+	prev := c
+	for _, seg := range cs {
+		if seg.To <= c.To {
+			continue
+		}
+		if seg.From != prev.To+1 || seg.PrevHash != prev.Hash {
+			return
+		}
+		above = append(above, seg)
+		if seg.To >= cp.Height || s.verifier.CheckpointLinked(seg.Hash, seg.To) {
+			break
+		}
+		prev = seg
+	}
+	if len(above) == 0 {
+		return
+	}
+	if last := above[len(above)-1]; last.To < cp.Height && !s.verifier.CheckpointLinked(last.Hash, last.To) {
+		return
+	}
+
+	for i := len(above) - 1; i >= 0; i-- {
+		sbs, err := s.readSnapshotBlocks(above[i])
+		if err != nil {
+			s.log.Warn(fmt.Sprintf("failed to read snapshot blocks of cache %d-%d: %v", above[i].From, above[i].To, err))
+			return
+		}
+		for len(sbs) > 0 && sbs[len(sbs)-1].Height > cp.Height {
+			sbs = sbs[:len(sbs)-1]
+		}
+		if err = s.verifier.LinkCheckpoint(sbs); err != nil {
+			s.log.Warn(fmt.Sprintf("failed to link cache %d-%d to checkpoint %s/%d: %v", above[i].From, above[i].To, cp.Hash, cp.Height, err))
+			return
+		}
+	}
+}
+
+// readSnapshotBlocks reads the snapshot blocks of the cached chunk
+func (s *cacheReader) readSnapshotBlocks(c interfaces.Segment) ([]*ledger.SnapshotBlock, error) {
+	reader, err := s.chain.GetSyncCache().NewReader(c)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var sbs []*ledger.SnapshotBlock
+	for {
+		_, sb, err := reader.Read()
+		if err == io.EOF {
+			return sbs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if sb != nil {
+			sbs = append(sbs, sb)
+		}
+	}
+}
+
 func (s *cacheReader) pause() {
 	atomic.StoreInt32(&s.readable, 0)
 }
@@ -752,6 +830,7 @@ Loop:
 			}
 
 			s.log.Info(fmt.Sprintf("begin read cache %d-%d", c.From, c.To))
+			s.linkCheckpoint(cs, c)
 			chunk, fatal, err := s.read(c)
 
 			if err != nil {
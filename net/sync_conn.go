@@ -45,6 +45,7 @@ var errWriteTooShort = errors.New("write too short")
 var errHandshakeError = errors.New("sync handshake error")
 var errServerNotReady = errors.New("server not ready")
 var errIncompleteChunk = errors.New("incomplete chunk")
+var errSlowPeer = errors.New("peer is too slow")
 
 type syncHandshake struct {
 	id    peerId
@@ -83,6 +84,7 @@ func (s *syncHandshake) deserialize(data []byte) error {
 type syncRequest struct {
 	from, to          uint64
 	prevHash, endHash types.Hash
+	offset            uint64 // the bytes downloaded before, to resume the chunk
 }
 
 func (s *syncRequest) Serialize() ([]byte, error) {
@@ -91,6 +93,7 @@ func (s *syncRequest) Serialize() ([]byte, error) {
 		To:       s.to,
 		PrevHash: s.prevHash.Bytes(),
 		EndHash:  s.endHash.Bytes(),
+		Offset:   s.offset,
 	}
 
 	return proto.Marshal(pb)
@@ -104,6 +107,7 @@ func (s *syncRequest) deserialize(data []byte) error {
 	}
 	s.from = pb.From
 	s.to = pb.To
+	s.offset = pb.Offset
 
 	s.prevHash, err = types.BytesToHash(pb.PrevHash)
 	if err != nil {
@@ -119,6 +123,7 @@ type syncResponse struct {
 	from, to          uint64
 	size              uint64
 	prevHash, endHash types.Hash
+	offset            uint64 // the chunk is sent from offset, 0 if the server doesn't support resuming
 }
 
 func (s *syncResponse) Serialize() ([]byte, error) {
@@ -128,6 +133,7 @@ func (s *syncResponse) Serialize() ([]byte, error) {
 		Size:     s.size,
 		PrevHash: s.prevHash.Bytes(),
 		EndHash:  s.endHash.Bytes(),
+		Offset:   s.offset,
 	}
 
 	return proto.Marshal(pb)
@@ -153,6 +159,7 @@ func (s *syncResponse) deserialize(data []byte) error {
 	s.from = pb.From
 	s.to = pb.To
 	s.size = pb.Size
+	s.offset = pb.Offset
 	return nil
 }
 
@@ -176,12 +183,14 @@ type defaultSyncConnectionFactory struct {
 	id      peerId
 	peerKey ed25519.PrivateKey
 	mineKey ed25519.PrivateKey
+	traffic *traffic
 }
 
 func (d *defaultSyncConnectionFactory) makeSyncConn(conn net2.Conn) *syncConn {
+	conn = d.traffic.wrap(conn, trafficSync)
 	return &syncConn{
 		conn: conn,
-		c:    NewTransport(conn, 100, 10*time.Second, 10*time.Second),
+		c:    newTransport(conn, 100, 10*time.Second, 10*time.Second, d.traffic),
 	}
 }
 
@@ -287,7 +296,7 @@ func (d *defaultSyncConnectionFactory) receive(conn net2.Conn) (*syncConn, error
 	}
 
 	p := d.peers.get(hk.id)
-	if p == nil {
+	if p == nil || !p.Supports(FeatureSnapshotSync) {
 		_ = c.c.WriteMsg(Msg{
 			Code:    CodeDisconnect,
 			Payload: []byte{byte(PeerNoPermission)},
@@ -394,7 +403,10 @@ func isRightChunk(msg *syncResponse, t *syncTask) (seg interfaces.Segment, err e
 	return t.Segment, err
 }
 
-func (f *syncConn) download(t *syncTask) (fatal bool, err error) {
+// download the chunk of t, minSpeed is the lowest acceptable speed after slowPeerGrace,
+// the download is aborted with errSlowPeer if the peer is slower, then the task can be retried on other peers.
+// minSpeed 0 means no limit.
+func (f *syncConn) download(t *syncTask, minSpeed uint64) (fatal bool, err error) {
 	if false == atomic.CompareAndSwapInt32(&f.busy, 0, 1) {
 		err = fmt.Errorf("task %s is downloading", f.task.String())
 		return
@@ -402,11 +414,16 @@ func (f *syncConn) download(t *syncTask) (fatal bool, err error) {
 	defer atomic.StoreInt32(&f.busy, 0)
 	f.task = *t
 
+	// resume the chunk downloaded from the same peer before
+	cache := f.cacher.GetSyncCache()
+	source := f.peer.Id.String()
+
 	request := &syncRequest{
 		from:     t.From,
 		to:       t.To,
 		prevHash: t.PrevHash,
 		endHash:  t.Hash,
+		offset:   uint64(cache.Progress(t.Segment, source)),
 	}
 	data, err := request.Serialize()
 	if err != nil {
@@ -443,24 +460,31 @@ func (f *syncConn) download(t *syncTask) (fatal bool, err error) {
 		return true, err
 	}
 
-	cache := f.cacher.GetSyncCache()
-	writer, err := cache.NewWriter(segment, int64(chunkInfo.size))
+	if chunkInfo.offset > chunkInfo.size {
+		return true, fmt.Errorf("chunk offset %d is larger than size %d", chunkInfo.offset, chunkInfo.size)
+	}
+
+	// the server may not support resuming, then chunkInfo.offset is 0 and the chunk is downloaded from scratch
+	writer, err := cache.ResumeWriter(segment, int64(chunkInfo.size), int64(chunkInfo.offset), source)
 	if err != nil {
 		return false, err
 	}
 
 	start := time.Now().Unix()
 	var nr, nw int
-	var total, count uint64
+	var count uint64
+	var total = chunkInfo.offset
 	var rerr, werr error
+	var slow bool
+	var checkAt = time.Now().Add(slowPeerGrace)
 
 	if f._speed < 10240 { // 10k/s
 		f._speed = 10240
 	}
 
-	timeout := time.Duration(2*chunkInfo.size/f._speed) * time.Second
+	timeout := time.Duration(2*(chunkInfo.size-chunkInfo.offset)/f._speed) * time.Second
 	_ = f.conn.SetReadDeadline(time.Now().Add(timeout))
-	for {
+	for total < chunkInfo.size {
 		count = chunkInfo.size - total
 		if count > 1024 {
 			count = 1024
@@ -480,36 +504,49 @@ func (f *syncConn) download(t *syncTask) (fatal bool, err error) {
 			break
 		}
 
-		if total == chunkInfo.size {
-			break
+		if minSpeed > 0 && total < chunkInfo.size {
+			if now := time.Now(); now.After(checkAt) {
+				if (total-chunkInfo.offset)/uint64(now.Unix()-start) < minSpeed {
+					slow = true
+					break
+				}
+				checkAt = now.Add(time.Second)
+			}
 		}
 	}
 
-	err = writer.Close()
-	if err != nil {
-		fatal = false
+	if werr != nil {
+		_ = writer.Close()
+		err = fmt.Errorf("failed to write cache %s: %v", t.String(), werr)
 		_ = cache.Delete(segment)
 		return
 	}
 
-	if rerr != nil {
+	if total != chunkInfo.size {
+		// keep the data downloaded, resume from the same peer later
+		_ = writer.Suspend()
+		// the rest data of the chunk is still in the connection
 		fatal = true
+		if slow {
+			err = errSlowPeer
+		} else {
+			err = errIncompleteChunk
+		}
+		return
 	}
 
-	if werr != nil {
-		err = fmt.Errorf("failed to write cache %s: %v", t.String(), werr)
+	err = writer.Close()
+	if err != nil {
+		fatal = false
 		_ = cache.Delete(segment)
 		return
 	}
 
-	if total != chunkInfo.size {
+	if rerr != nil {
 		fatal = true
-		err = errIncompleteChunk
-		_ = cache.Delete(segment)
-		return
 	}
 
-	f._speed = total / uint64(time.Now().Unix()-start+1)
+	f._speed = (total - chunkInfo.offset) / uint64(time.Now().Unix()-start+1)
 
 	t.source = f.peer.Id
 	return
@@ -561,6 +598,7 @@ type downloadConnPool struct {
 	mi        map[peerId]int // value is the index of `connPoolImpl.l`
 	l         connections    // connections sort by speed, from fast to slow
 	blackList map[peerId]int64
+	tracker   *throughputTracker
 }
 
 func newDownloadConnPool(peers *peerSet) *downloadConnPool {
@@ -568,6 +606,7 @@ func newDownloadConnPool(peers *peerSet) *downloadConnPool {
 		peers:     peers,
 		mi:        make(map[peerId]int),
 		blackList: make(map[peerId]int64),
+		tracker:   newThroughputTracker(),
 	}
 }
 
@@ -675,7 +714,7 @@ func (fp *downloadConnPool) chooseSource(t *syncTask) (*Peer, *syncConn, error)
 	// is in blackList
 	now := time.Now().Unix()
 	for k, p := range peerMap {
-		if tt, ok := fp.blackList[p.Id]; ok && now > tt {
+		if tt, ok := fp.blackList[p.Id]; ok && now < tt {
 			delete(peerMap, k)
 		}
 	}
@@ -691,22 +730,24 @@ func (fp *downloadConnPool) chooseSource(t *syncTask) (*Peer, *syncConn, error)
 			continue
 		}
 
+		if tt, ok := fp.blackList[c.peer.Id]; ok && now < tt {
+			continue
+		}
+
 		if len(fp.l)+1 > 3*(i+1) {
 			// fast enough
 			return nil, c, nil
 		}
 
 		if createNew {
-			for _, p := range peerMap {
-				return p, nil, nil
-			}
+			return fp.tracker.pick(peerMap), nil, nil
 		} else {
 			return nil, c, nil
 		}
 	}
 
-	for _, p := range peerMap {
-		return p, nil, nil
+	if len(peerMap) > 0 {
+		return fp.tracker.pick(peerMap), nil, nil
 	}
 
 	return nil, nil, nil
@@ -297,6 +297,9 @@ func compare(m1, m2 *syncResponse) error {
 	if m2.endHash != m2.endHash {
 		return fmt.Errorf("different end hash %s %s", m1.endHash, m2.endHash)
 	}
+	if m1.offset != m2.offset {
+		return fmt.Errorf("different offset %d %d", m1.offset, m2.offset)
+	}
 
 	return nil
 }
@@ -307,6 +310,7 @@ func TestSyncReadyMsg(t *testing.T) {
 		size:     20293,
 		prevHash: types.Hash{1},
 		endHash:  types.Hash{2},
+		offset:   1024,
 	}
 
 	data, err := msg.Serialize()
@@ -338,6 +342,7 @@ func TestSyncRequest_Serialize(t *testing.T) {
 		to:       segment.To,
 		prevHash: segment.PrevHash,
 		endHash:  segment.Hash,
+		offset:   1024,
 	}
 
 	data, err := request.Serialize()
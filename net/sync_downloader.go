@@ -103,6 +103,7 @@ func (s syncTasks) Swap(i, j int) {
 type DownloaderStatus struct {
 	Tasks       []string               `json:"tasks"`
 	Connections []SyncConnectionStatus `json:"connections"`
+	Peers       []PeerThroughput       `json:"peers"`
 }
 
 type syncDownloader interface {
@@ -203,6 +204,7 @@ func (e *executor) status() DownloaderStatus {
 	st := DownloaderStatus{
 		tasks,
 		e.pool.connections(),
+		e.pool.tracker.status(),
 	}
 
 	return st
@@ -414,9 +416,21 @@ func (e *executor) doJob(c *syncConn, t *syncTask) error {
 
 	e.log.Info(fmt.Sprintf("download chunk %s from %s", t.String(), c.address()))
 
-	if fatal, err := c.download(t); err != nil {
+	// abort the slow download only if the task can be retried on other peers
+	var minSpeed uint64
+	if e.pool.peers.count() > 1 {
+		minSpeed = e.pool.tracker.minSpeed()
+	}
+
+	if fatal, err := c.download(t, minSpeed); err != nil {
 		e.log.Warn(fmt.Sprintf("failed to download chunk %s from %s: %v", t, c.address(), err))
 
+		slow := err == errSlowPeer
+		e.pool.tracker.fail(c.peer.Id, slow)
+		if slow {
+			e.pool.blockPeer(c.peer.Id, slowPeerBlockTime)
+		}
+
 		if fatal {
 			e.pool.delConn(c)
 			e.log.Warn(fmt.Sprintf("delete sync connection %s: %v", c.address(), err))
@@ -425,6 +439,7 @@ func (e *executor) doJob(c *syncConn, t *syncTask) error {
 		return err
 	}
 
+	e.pool.tracker.record(c.peer.Id, c.speed())
 	e.log.Info(fmt.Sprintf("download chunk %s from %s elapse %s", t, c.address(), time.Now().Sub(start)))
 
 	return nil
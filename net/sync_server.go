@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	net2 "net"
 	"sync"
 	"sync/atomic"
@@ -213,12 +214,19 @@ func (s *syncServer) handleConn(conn net2.Conn) {
 			continue
 		}
 
+		// resume the chunk from the offset requested
+		offset := request.offset
+		if offset > uint64(reader.Size()) {
+			offset = 0
+		}
+
 		ready := &syncResponse{
 			from:     segment.From,
 			to:       segment.To,
 			size:     uint64(reader.Size()),
 			prevHash: segment.PrevHash,
 			endHash:  segment.Hash,
+			offset:   offset,
 		}
 		var data []byte
 		data, err = ready.Serialize()
@@ -244,11 +252,13 @@ func (s *syncServer) handleConn(conn net2.Conn) {
 
 		var wn int64
 		_ = conn.SetWriteDeadline(time.Now().Add(fileTimeout))
-		wn, err = io.Copy(conn, reader)
+		if _, err = io.CopyN(ioutil.Discard, reader, int64(offset)); err == nil {
+			wn, err = io.Copy(conn, reader)
+		}
 		_ = reader.Close()
 
-		if wn != int64(reader.Size()) {
-			err = fmt.Errorf("write %d/%d bytes", wn, reader.Size())
+		if wn+int64(offset) != int64(reader.Size()) {
+			err = fmt.Errorf("write %d/%d bytes from %d", wn, reader.Size(), offset)
 		}
 
 		if err != nil {
@@ -0,0 +1,142 @@
+package net
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	throughputWeight  = 0.3 // weight of the latest sample in the moving average
+	slowPeerRatio     = 8   // a peer is slow if it is slower than the fastest peer by slowPeerRatio times
+	slowPeerGrace     = 10 * time.Second
+	slowPeerBlockTime = 30 * time.Second
+)
+
+type PeerThroughput struct {
+	Peer     string `json:"peer"`
+	Speed    string `json:"speed"`
+	Chunks   int    `json:"chunks"`
+	Failures int    `json:"failures"`
+	Slow     int    `json:"slow"`
+}
+
+type peerThroughput struct {
+	speed    float64 // moving average of download speed, byte/s
+	chunks   int
+	failures int
+	slow     int
+}
+
+// throughputTracker records the download speed of peers, it is kept after the sync connection is closed,
+// so the peers known to be fast are chosen first when new connections are created.
+type throughputTracker struct {
+	mu    sync.Mutex
+	peers map[peerId]*peerThroughput
+}
+
+func newThroughputTracker() *throughputTracker {
+	return &throughputTracker{
+		peers: make(map[peerId]*peerThroughput),
+	}
+}
+
+func (tt *throughputTracker) getLocked(id peerId) *peerThroughput {
+	pt, ok := tt.peers[id]
+	if !ok {
+		pt = &peerThroughput{}
+		tt.peers[id] = pt
+	}
+	return pt
+}
+
+// record the speed of a chunk downloaded from the peer
+func (tt *throughputTracker) record(id peerId, speed uint64) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	pt := tt.getLocked(id)
+	if pt.chunks == 0 {
+		pt.speed = float64(speed)
+	} else {
+		pt.speed = throughputWeight*float64(speed) + (1-throughputWeight)*pt.speed
+	}
+	pt.chunks++
+}
+
+func (tt *throughputTracker) fail(id peerId, slow bool) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	pt := tt.getLocked(id)
+	pt.failures++
+	if slow {
+		pt.slow++
+		// the aborted chunk is not recorded, so halve the speed to move the peer back in the queue
+		pt.speed /= 2
+	}
+}
+
+// pick the fastest peer, the peers never downloaded from are ranked after the fast peers but before the slow peers
+func (tt *throughputTracker) pick(peers map[peerId]*Peer) (best *Peer) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	var fastest float64
+	for _, pt := range tt.peers {
+		if pt.speed > fastest {
+			fastest = pt.speed
+		}
+	}
+
+	var bestSpeed = -1.0
+	for id, p := range peers {
+		speed := fastest / 4
+		if pt, ok := tt.peers[id]; ok && pt.chunks+pt.failures > 0 {
+			speed = pt.speed
+		}
+		if speed > bestSpeed {
+			best, bestSpeed = p, speed
+		}
+	}
+
+	return
+}
+
+// minSpeed is the speed below which a download is aborted and retried on other peers,
+// 0 means the speed of peers is unknown yet
+func (tt *throughputTracker) minSpeed() uint64 {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	var fastest float64
+	for _, pt := range tt.peers {
+		if pt.speed > fastest {
+			fastest = pt.speed
+		}
+	}
+	return uint64(fastest) / slowPeerRatio
+}
+
+func (tt *throughputTracker) status() []PeerThroughput {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	ret := make([]PeerThroughput, 0, len(tt.peers))
+	speeds := make(map[string]float64, len(tt.peers))
+	for id, pt := range tt.peers {
+		ret = append(ret, PeerThroughput{
+			Peer:     id.Brief(),
+			Speed:    speedToString(pt.speed),
+			Chunks:   pt.chunks,
+			Failures: pt.failures,
+			Slow:     pt.slow,
+		})
+		speeds[id.Brief()] = pt.speed
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return speeds[ret[i].Peer] > speeds[ret[j].Peer]
+	})
+
+	return ret
+}
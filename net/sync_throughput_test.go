@@ -0,0 +1,54 @@
+package net
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+)
+
+func TestThroughputTracker(t *testing.T) {
+	tt := newThroughputTracker()
+
+	if tt.minSpeed() != 0 {
+		t.Fatalf("min speed should be 0 before any download")
+	}
+
+	fast, slow, unknown := vnode.RandomNodeID(), vnode.RandomNodeID(), vnode.RandomNodeID()
+	peers := map[peerId]*Peer{
+		fast:    {Id: fast},
+		slow:    {Id: slow},
+		unknown: {Id: unknown},
+	}
+
+	tt.record(fast, 800*1024)
+	tt.record(slow, 10*1024)
+	if minSpeed := tt.minSpeed(); minSpeed != 100*1024 {
+		t.Fatalf("min speed should be 100K, got %d", minSpeed)
+	}
+
+	if p := tt.pick(peers); p.Id != fast {
+		t.Fatalf("the fast peer should be picked, got %s", p.Id)
+	}
+
+	// the peer never downloaded from is tried before the slow peer
+	delete(peers, fast)
+	if p := tt.pick(peers); p.Id != unknown {
+		t.Fatalf("the unknown peer should be picked, got %s", p.Id)
+	}
+
+	// moving average
+	tt.record(fast, 0)
+	if speed := tt.peers[fast].speed; speed != 0.7*800*1024 {
+		t.Fatalf("unexpected speed %f", speed)
+	}
+
+	tt.fail(fast, true)
+	if pt := tt.peers[fast]; pt.speed != 0.35*800*1024 || pt.failures != 1 || pt.slow != 1 {
+		t.Fatalf("unexpected throughput %+v", pt)
+	}
+
+	st := tt.status()
+	if len(st) != 2 || st[0].Peer != fast.Brief() || st[0].Chunks != 2 {
+		t.Fatalf("unexpected status %+v", st)
+	}
+}
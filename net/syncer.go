@@ -66,6 +66,8 @@ type syncer struct {
 	sk           *skeleton
 	syncWG       sync.WaitGroup
 
+	checkpoints ledger.Checkpoints // the skeleton contradicting the checkpoints is rejected
+
 	chain      syncChain // query current block and height
 	downloader syncDownloader
 	reader     syncCacheReader
@@ -451,6 +453,12 @@ func (s *syncer) verifyHashHeightList(start []*ledger.HashHeight, points []*Hash
 		return nil, errors.New("skeleton has no right start point")
 	}
 
+	for _, p := range points {
+		if err = s.checkpoints.Check(p.Height, p.Hash); err != nil {
+			return nil, fmt.Errorf("skeleton is not trusted: %v", err)
+		}
+	}
+
 	return
 }
 
@@ -0,0 +1,344 @@
+package net
+
+import (
+	"crypto/tls"
+	_net "net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+// protocols of the connections
+const (
+	trafficP2P  = "p2p"  // messages of peers
+	trafficSync = "sync" // sync chunks
+)
+
+var trafficProtocols = [...]string{trafficP2P, trafficSync}
+
+var (
+	trafficBytesMetric = monitor.GetOrRegisterCounterVec("vite_net_traffic_bytes_total",
+		"bytes transferred, partitioned by protocol and direction", "protocol", "direction")
+	trafficMessagesMetric = monitor.GetOrRegisterCounterVec("vite_net_messages_total",
+		"messages transferred, partitioned by message code and direction", "code", "direction")
+	trafficMessageBytesMetric = monitor.GetOrRegisterCounterVec("vite_net_message_bytes_total",
+		"bytes of messages on the wire, partitioned by message code and direction", "code", "direction")
+)
+
+var codeNames = map[Code]string{
+	CodeDisconnect:        "disconnect",
+	CodeHandshake:         "handshake",
+	CodeControlFlow:       "controlFlow",
+	CodeHeartBeat:         "heartBeat",
+	CodeGetHashList:       "getHashList",
+	CodeHashList:          "hashList",
+	CodeGetSnapshotBlocks: "getSnapshotBlocks",
+	CodeSnapshotBlocks:    "snapshotBlocks",
+	CodeGetAccountBlocks:  "getAccountBlocks",
+	CodeAccountBlocks:     "accountBlocks",
+	CodeNewSnapshotBlock:  "newSnapshotBlock",
+	CodeNewAccountBlock:   "newAccountBlock",
+	CodeGetLightHeaders:   "getLightHeaders",
+	CodeLightHeaders:      "lightHeaders",
+	CodeGetAccountProof:   "getAccountProof",
+	CodeAccountProof:      "accountProof",
+	CodeGetStorageProof:   "getStorageProof",
+	CodeStorageProof:      "storageProof",
+	CodeSyncHandshake:     "syncHandshake",
+	CodeSyncHandshakeOK:   "syncHandshakeOK",
+	CodeSyncRequest:       "syncRequest",
+	CodeSyncReady:         "syncReady",
+	CodeException:         "exception",
+	CodeTrace:             "trace",
+}
+
+func codeName(code Code) string {
+	if name, ok := codeNames[code]; ok {
+		return name
+	}
+	return strconv.Itoa(int(code))
+}
+
+// byteLimiter is a token bucket of bytes refilled by rate per second, a large read or write takes tokens in
+// advance, the following ones wait until the bucket is refilled
+type byteLimiter struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newByteLimiter(rate int) *byteLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &byteLimiter{
+		rate:   float64(rate),
+		burst:  float64(rate),
+		tokens: float64(rate),
+	}
+}
+
+// reserve takes n tokens, it returns how long to wait for them
+func (l *byteLimiter) reserve(n int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait blocks until n bytes are allowed by all the limiters, nil limiters are unlimited
+func waitBytes(n int, limiters ...*byteLimiter) {
+	if n <= 0 {
+		return
+	}
+	now := time.Now()
+	var delay time.Duration
+	for _, l := range limiters {
+		if l == nil {
+			continue
+		}
+		if d := l.reserve(n, now); d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+type trafficCounter struct {
+	in, out uint64
+}
+
+type messageCounter struct {
+	inMsgs, inBytes, outMsgs, outBytes uint64
+}
+
+// traffic throttles the connections and accounts the bytes by protocols and message codes
+type traffic struct {
+	upload, download         *byteLimiter // of all connections
+	peerUpload, peerDownload int          // bytes per second of each connection
+
+	bytes    [len(trafficProtocols)]trafficCounter
+	messages [256]messageCounter
+}
+
+// newTraffic creates the traffic with the limits in bytes per second, zero is unlimited
+func newTraffic(upload, download, peerUpload, peerDownload int) *traffic {
+	return &traffic{
+		upload:       newByteLimiter(upload),
+		download:     newByteLimiter(download),
+		peerUpload:   peerUpload,
+		peerDownload: peerDownload,
+	}
+}
+
+// wrap throttles and accounts the connection
+func (t *traffic) wrap(conn _net.Conn, protocol string) _net.Conn {
+	if t == nil {
+		return conn
+	}
+	c := &meteredConn{
+		Conn:     conn,
+		traffic:  t,
+		own:      new(trafficCounter),
+		upload:   newByteLimiter(t.peerUpload),
+		download: newByteLimiter(t.peerDownload),
+	}
+	for i, p := range trafficProtocols {
+		if p == protocol {
+			c.counter = &t.bytes[i]
+		}
+	}
+	return c
+}
+
+// message accounts a message of size bytes on the wire
+func (t *traffic) message(code Code, size int, out bool) {
+	if t == nil {
+		return
+	}
+	c := &t.messages[code]
+	if out {
+		atomic.AddUint64(&c.outMsgs, 1)
+		atomic.AddUint64(&c.outBytes, uint64(size))
+	} else {
+		atomic.AddUint64(&c.inMsgs, 1)
+		atomic.AddUint64(&c.inBytes, uint64(size))
+	}
+}
+
+// ProtocolTraffic is the bytes transferred by a protocol
+type ProtocolTraffic struct {
+	Protocol string `json:"protocol"`
+	In       uint64 `json:"in"`
+	Out      uint64 `json:"out"`
+}
+
+// MessageTraffic is the messages transferred of a message code
+type MessageTraffic struct {
+	Code        string `json:"code"`
+	InMessages  uint64 `json:"inMessages"`
+	InBytes     uint64 `json:"inBytes"`
+	OutMessages uint64 `json:"outMessages"`
+	OutBytes    uint64 `json:"outBytes"`
+}
+
+// TrafficStatus is the traffic since the node started, and the limits in bytes per second
+type TrafficStatus struct {
+	Protocols         []ProtocolTraffic `json:"protocols"`
+	Messages          []MessageTraffic  `json:"messages"`
+	UploadLimit       int               `json:"uploadLimit"`
+	DownloadLimit     int               `json:"downloadLimit"`
+	PeerUploadLimit   int               `json:"peerUploadLimit"`
+	PeerDownloadLimit int               `json:"peerDownloadLimit"`
+}
+
+func (t *traffic) status() (s TrafficStatus) {
+	if t == nil {
+		return
+	}
+
+	for i, p := range trafficProtocols {
+		s.Protocols = append(s.Protocols, ProtocolTraffic{
+			Protocol: p,
+			In:       atomic.LoadUint64(&t.bytes[i].in),
+			Out:      atomic.LoadUint64(&t.bytes[i].out),
+		})
+	}
+
+	for code := range t.messages {
+		c := &t.messages[code]
+		m := MessageTraffic{
+			Code:        codeName(Code(code)),
+			InMessages:  atomic.LoadUint64(&c.inMsgs),
+			InBytes:     atomic.LoadUint64(&c.inBytes),
+			OutMessages: atomic.LoadUint64(&c.outMsgs),
+			OutBytes:    atomic.LoadUint64(&c.outBytes),
+		}
+		if m.InMessages > 0 || m.OutMessages > 0 {
+			s.Messages = append(s.Messages, m)
+		}
+	}
+
+	if t.upload != nil {
+		s.UploadLimit = int(t.upload.rate)
+	}
+	if t.download != nil {
+		s.DownloadLimit = int(t.download.rate)
+	}
+	s.PeerUploadLimit = t.peerUpload
+	s.PeerDownloadLimit = t.peerDownload
+
+	return
+}
+
+func (t *traffic) reportMetrics() {
+	s := t.status()
+	for _, p := range s.Protocols {
+		trafficBytesMetric.With(p.Protocol, "in").Set(float64(p.In))
+		trafficBytesMetric.With(p.Protocol, "out").Set(float64(p.Out))
+	}
+	for _, m := range s.Messages {
+		trafficMessagesMetric.With(m.Code, "in").Set(float64(m.InMessages))
+		trafficMessagesMetric.With(m.Code, "out").Set(float64(m.OutMessages))
+		trafficMessageBytesMetric.With(m.Code, "in").Set(float64(m.InBytes))
+		trafficMessageBytesMetric.With(m.Code, "out").Set(float64(m.OutBytes))
+	}
+}
+
+// meteredConn throttles the connection by the limits of itself and all connections
+type meteredConn struct {
+	_net.Conn
+	traffic          *traffic
+	counter          *trafficCounter // of the protocol
+	own              *trafficCounter // of the connection
+	upload, download *byteLimiter
+}
+
+// with returns the metered stream of the same connection, e.g. QUIC streams share the limits of the connection
+func (c *meteredConn) with(conn _net.Conn) *meteredConn {
+	return &meteredConn{
+		Conn:     conn,
+		traffic:  c.traffic,
+		counter:  c.counter,
+		own:      c.own,
+		upload:   c.upload,
+		download: c.download,
+	}
+}
+
+func (c *meteredConn) Read(p []byte) (n int, err error) {
+	n, err = c.Conn.Read(p)
+	if n > 0 {
+		if c.counter != nil {
+			atomic.AddUint64(&c.counter.in, uint64(n))
+		}
+		atomic.AddUint64(&c.own.in, uint64(n))
+		waitBytes(n, c.download, c.traffic.download)
+	}
+	return
+}
+
+// meteredWriteSize splits large writes, so the connection isn't blocked for a long time before writing
+const meteredWriteSize = 32 * 1024
+
+func (c *meteredConn) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		size := len(p)
+		if size > meteredWriteSize {
+			size = meteredWriteSize
+		}
+		waitBytes(size, c.upload, c.traffic.upload)
+
+		var m int
+		m, err = c.Conn.Write(p[:size])
+		n += m
+		if c.counter != nil {
+			atomic.AddUint64(&c.counter.out, uint64(m))
+		}
+		atomic.AddUint64(&c.own.out, uint64(m))
+		if err != nil {
+			return
+		}
+		p = p[size:]
+	}
+	return
+}
+
+// codecTraffic returns the bytes transferred by the connection of the codec, zero if not metered
+func codecTraffic(c Codec) (in, out uint64) {
+	var mc *meteredConn
+	switch c := c.(type) {
+	case *transport:
+		conn := c.Conn
+		if tc, ok := conn.(*tls.Conn); ok {
+			conn = tc.NetConn()
+		}
+		mc, _ = conn.(*meteredConn)
+	case *quicCodec:
+		mc = c.meter
+	}
+	if mc == nil {
+		return
+	}
+	return atomic.LoadUint64(&mc.own.in), atomic.LoadUint64(&mc.own.out)
+}
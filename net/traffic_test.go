@@ -0,0 +1,107 @@
+package net
+
+import (
+	"crypto/rand"
+	_net "net"
+	"testing"
+	"time"
+)
+
+func TestByteLimiter(t *testing.T) {
+	l := newByteLimiter(1000)
+	now := time.Now()
+
+	if d := l.reserve(1000, now); d != 0 {
+		t.Errorf("expected no wait for the burst, got %v", d)
+	}
+	if d := l.reserve(500, now); d != 500*time.Millisecond {
+		t.Errorf("expected wait 500ms, got %v", d)
+	}
+	// refilled 1000 tokens, 500 of them pay the debt
+	if d := l.reserve(500, now.Add(time.Second)); d != 0 {
+		t.Errorf("expected no wait after refilled, got %v", d)
+	}
+	// no more than the burst
+	if d := l.reserve(1500, now.Add(time.Hour)); d != 500*time.Millisecond {
+		t.Errorf("expected wait 500ms, got %v", d)
+	}
+
+	if newByteLimiter(0) != nil {
+		t.Error("expected unlimited")
+	}
+}
+
+func TestTraffic(t *testing.T) {
+	tr := newTraffic(0, 0, 64*1024, 0)
+
+	c1, c2 := _net.Pipe()
+	factory := &transportFactory{
+		minCompressLength: 100,
+		readTimeout:       10 * time.Second,
+		writeTimeout:      10 * time.Second,
+		traffic:           tr,
+	}
+	codec1 := factory.CreateCodec(c1)
+	codec2 := NewTransport(c2, 100, 10*time.Second, 10*time.Second)
+
+	// incompressible payload
+	payload := make([]byte, 96*1024)
+	_, _ = rand.Read(payload)
+
+	start := time.Now()
+	written := make(chan struct{})
+	go func() {
+		_ = codec1.WriteMsg(Msg{Code: CodeSnapshotBlocks, Payload: payload})
+		_ = codec1.WriteMsg(Msg{Code: CodeHeartBeat})
+		close(written)
+	}()
+
+	if _, err := codec2.ReadMsg(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := codec2.ReadMsg(); err != nil {
+		t.Fatal(err)
+	}
+	// the burst is 64KB, the rest 32KB takes about 500ms
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("expected upload throttled, took %v", elapsed)
+	}
+	<-written
+
+	go func() {
+		_ = codec2.WriteMsg(Msg{Code: CodeGetSnapshotBlocks, Payload: []byte{1, 2, 3}})
+	}()
+	if _, err := codec1.ReadMsg(); err != nil {
+		t.Fatal(err)
+	}
+
+	s := tr.status()
+	if s.PeerUploadLimit != 64*1024 || s.UploadLimit != 0 {
+		t.Errorf("unexpected limits %+v", s)
+	}
+	p2p := s.Protocols[0]
+	if p2p.Protocol != trafficP2P || p2p.Out <= uint64(len(payload)) || p2p.In != 2+1+3 {
+		t.Errorf("unexpected p2p traffic %+v", p2p)
+	}
+
+	if in, out := codecTraffic(codec1); in != p2p.In || out != p2p.Out {
+		t.Errorf("unexpected traffic of connection in %d out %d", in, out)
+	}
+
+	messages := make(map[string]MessageTraffic)
+	for _, m := range s.Messages {
+		messages[m.Code] = m
+	}
+	if m := messages["snapshotBlocks"]; m.OutMessages != 1 || m.OutBytes <= uint64(len(payload)) {
+		t.Errorf("unexpected snapshotBlocks traffic %+v", m)
+	}
+	if m := messages["heartBeat"]; m.OutMessages != 1 || m.OutBytes != 2 {
+		t.Errorf("unexpected heartBeat traffic %+v", m)
+	}
+	if m := messages["getSnapshotBlocks"]; m.InMessages != 1 || m.InBytes != 2+1+3 {
+		t.Errorf("unexpected getSnapshotBlocks traffic %+v", m)
+	}
+	if p2p.Out != messages["snapshotBlocks"].OutBytes+messages["heartBeat"].OutBytes {
+		t.Errorf("bytes of connection %d and messages are different", p2p.Out)
+	}
+}
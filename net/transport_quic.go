@@ -0,0 +1,412 @@
+package net
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	_net "net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+/*
+ * QUIC transport
+ *
+ * A QUIC connection carries the same messages as a TCP connection, the handshake is the same too.
+ * The dialer opens the control stream and sends the handshake on it, then messages are written to
+ * streams by their lanes, so that a large block response doesn't block the broadcast of new blocks
+ * or the heartbeats. The receiver reads messages from all streams of the connection.
+ *
+ * The identity of the peer is authenticated by the handshake, the TLS certificate is self-signed.
+ */
+
+const quicALPN = "vite-p2p"
+
+// quicPortOffset is the offset from the p2p port to the QUIC port, the udp port of p2p is taken by discovery
+const quicPortOffset = 1
+
+const (
+	quicLaneControl   = iota // handshake, heartbeat, disconnect and unknown messages
+	quicLaneBroadcast        // new blocks
+	quicLaneFetch            // block requests and responses
+	quicLaneCount
+)
+
+var errQuicCodecClosed = errors.New("quic codec closed")
+
+func quicLaneOf(code Code) int {
+	switch code {
+	case CodeNewSnapshotBlock, CodeNewAccountBlock:
+		return quicLaneBroadcast
+	case CodeGetHashList, CodeHashList, CodeGetSnapshotBlocks, CodeSnapshotBlocks, CodeGetAccountBlocks, CodeAccountBlocks:
+		return quicLaneFetch
+	default:
+		return quicLaneControl
+	}
+}
+
+func quicConfig() *quic.Config {
+	return &quic.Config{
+		HandshakeIdleTimeout: 10 * time.Second,
+		MaxIdleTimeout:       time.Minute,
+		KeepAlivePeriod:      15 * time.Second,
+		// a stream is opened per lane, the streams are bidirectional
+		MaxIncomingStreams:    quicLaneCount,
+		MaxIncomingUniStreams: -1,
+	}
+}
+
+// the client session cache resumes the TLS sessions of known peers, there is no early data
+var quicClientTLS = &tls.Config{
+	InsecureSkipVerify: true,
+	NextProtos:         []string{quicALPN},
+	ClientSessionCache: tls.NewLRUClientSessionCache(1000),
+	MinVersion:         tls.VersionTLS13,
+}
+
+func quicServerTLS() (*tls.Config, error) {
+	cert, err := selfSignedCertificate()
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		NextProtos:   []string{quicALPN},
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// selfSignedCertificate makes a certificate of a random key, the identity of the peer is authenticated by the node key
+func selfSignedCertificate() (tls.Certificate, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(10 * 365 * 24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, pub, priv)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: priv}, nil
+}
+
+// quicAddress returns the QUIC address of the p2p address
+func quicAddress(address string) (string, error) {
+	host, port, err := _net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", err
+	}
+	return _net.JoinHostPort(host, strconv.Itoa(p+quicPortOffset)), nil
+}
+
+// quicStreamConn is a stream of a QUIC connection as a net.Conn, the control stream is passed to the handshaker
+type quicStreamConn struct {
+	quic.Stream
+	conn quic.Connection
+}
+
+func (c *quicStreamConn) LocalAddr() _net.Addr {
+	return c.conn.LocalAddr()
+}
+
+func (c *quicStreamConn) RemoteAddr() _net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// SetReadDeadline is ignored, streams are read in background, quicCodec.ReadMsg times out by itself
+func (c *quicStreamConn) SetReadDeadline(t time.Time) error {
+	return nil
+}
+
+func (c *quicStreamConn) SetDeadline(t time.Time) error {
+	return c.Stream.SetWriteDeadline(t)
+}
+
+func (c *quicStreamConn) Close() error {
+	return c.conn.CloseWithError(0, "")
+}
+
+func dialQUIC(address string, timeout time.Duration) (_net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := quic.DialAddr(ctx, address, quicClientTLS, quicConfig())
+	if err != nil {
+		return nil, err
+	}
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		_ = conn.CloseWithError(0, "")
+		return nil, err
+	}
+	return &quicStreamConn{Stream: stream, conn: conn}, nil
+}
+
+// quicListener accepts QUIC connections as net.Conn of their control streams
+type quicListener struct {
+	ln    *quic.Listener
+	conns chan _net.Conn
+	err   error
+	done  chan struct{}
+	once  sync.Once
+}
+
+func listenQUIC(address string) (_net.Listener, error) {
+	tlsConf, err := quicServerTLS()
+	if err != nil {
+		return nil, err
+	}
+	ln, err := quic.ListenAddr(address, tlsConf, quicConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	l := &quicListener{
+		ln:    ln,
+		conns: make(chan _net.Conn),
+		done:  make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l, nil
+}
+
+func (l *quicListener) acceptLoop() {
+	for {
+		conn, err := l.ln.Accept(context.Background())
+		if err != nil {
+			l.err = err
+			l.once.Do(func() {
+				close(l.done)
+			})
+			return
+		}
+
+		// wait for the control stream in background, don't block other connections
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), handshakeTimeout)
+			defer cancel()
+			stream, err := conn.AcceptStream(ctx)
+			if err != nil {
+				_ = conn.CloseWithError(0, "")
+				return
+			}
+			select {
+			case l.conns <- &quicStreamConn{Stream: stream, conn: conn}:
+			case <-l.done:
+				_ = conn.CloseWithError(0, "")
+			}
+		}()
+	}
+}
+
+func (l *quicListener) Accept() (_net.Conn, error) {
+	select {
+	case c := <-l.conns:
+		return c, nil
+	case <-l.done:
+		if l.err != nil {
+			return nil, l.err
+		}
+		return nil, errors.New("quic listener closed")
+	}
+}
+
+func (l *quicListener) Close() error {
+	err := l.ln.Close()
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return err
+}
+
+func (l *quicListener) Addr() _net.Addr {
+	return l.ln.Addr()
+}
+
+type quicRead struct {
+	msg Msg
+	err error
+}
+
+// quicCodec writes messages to the streams of their lanes, and reads messages from all streams
+type quicCodec struct {
+	conn quic.Connection
+
+	minCompressLength int
+	noCompress        bool // of the lanes opened for writing
+	readTimeout       time.Duration
+	writeTimeout      time.Duration
+	traffic           *traffic
+	meter             *meteredConn // the limits of the connection shared by the streams, nil if not accounted
+
+	mu    sync.Mutex
+	lanes [quicLaneCount]Codec
+
+	reads chan quicRead
+	done  chan struct{}
+	once  sync.Once
+}
+
+func newQuicCodec(control *quicStreamConn, minCompressLength int, readTimeout, writeTimeout time.Duration, traffic *traffic) *quicCodec {
+	c := &quicCodec{
+		conn:              control.conn,
+		minCompressLength: minCompressLength,
+		readTimeout:       readTimeout,
+		writeTimeout:      writeTimeout,
+		traffic:           traffic,
+		reads:             make(chan quicRead, 10),
+		done:              make(chan struct{}),
+	}
+	if traffic != nil {
+		c.meter = traffic.wrap(control, trafficP2P).(*meteredConn)
+	}
+	c.lanes[quicLaneControl] = c.streamCodec(control)
+
+	go c.readLoop(c.lanes[quicLaneControl])
+	go c.acceptLoop()
+	return c
+}
+
+func (c *quicCodec) streamCodec(stream *quicStreamConn) *transport {
+	var conn _net.Conn = stream
+	if c.meter != nil {
+		conn = c.meter.with(stream)
+	}
+	return newTransport(conn, c.minCompressLength, c.readTimeout, c.writeTimeout, c.traffic)
+}
+
+func (c *quicCodec) acceptLoop() {
+	for {
+		stream, err := c.conn.AcceptStream(context.Background())
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		go c.readLoop(c.streamCodec(&quicStreamConn{Stream: stream, conn: c.conn}))
+	}
+}
+
+func (c *quicCodec) readLoop(stream Codec) {
+	for {
+		msg, err := stream.ReadMsg()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		select {
+		case c.reads <- quicRead{msg: msg}:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// fail ends reading with the first error of the streams
+func (c *quicCodec) fail(err error) {
+	select {
+	case c.reads <- quicRead{err: err}:
+	case <-c.done:
+	}
+}
+
+func (c *quicCodec) ReadMsg() (msg Msg, err error) {
+	timer := time.NewTimer(c.readTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-c.reads:
+		return r.msg, r.err
+	case <-c.done:
+		return msg, errQuicCodecClosed
+	case <-timer.C:
+		return msg, errors.New("failed to read message: timeout")
+	}
+}
+
+func (c *quicCodec) lane(code Code) (Codec, error) {
+	i := quicLaneOf(code)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lanes[i] == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), c.writeTimeout)
+		defer cancel()
+		stream, err := c.conn.OpenStreamSync(ctx)
+		if err != nil {
+			return nil, err
+		}
+		lane := c.streamCodec(&quicStreamConn{Stream: stream, conn: c.conn})
+		lane.setCompress(!c.noCompress)
+		c.lanes[i] = lane
+	}
+	return c.lanes[i], nil
+}
+
+// WriteMsg is NOT thread-safe, the same as transport
+func (c *quicCodec) WriteMsg(msg Msg) error {
+	lane, err := c.lane(msg.Code)
+	if err != nil {
+		return err
+	}
+	return lane.WriteMsg(msg)
+}
+
+func (c *quicCodec) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+	})
+	return c.conn.CloseWithError(0, "")
+}
+
+func (c *quicCodec) SetReadTimeout(timeout time.Duration) {
+	c.readTimeout = timeout
+}
+
+func (c *quicCodec) SetWriteTimeout(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeTimeout = timeout
+	for _, lane := range c.lanes {
+		if lane != nil {
+			lane.SetWriteTimeout(timeout)
+		}
+	}
+}
+
+func (c *quicCodec) setCompress(compress bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.noCompress = !compress
+	for _, lane := range c.lanes {
+		if lane != nil {
+			lane.(compressor).setCompress(compress)
+		}
+	}
+}
+
+func (c *quicCodec) SetTimeout(timeout time.Duration) {
+	c.SetReadTimeout(timeout)
+	c.SetWriteTimeout(timeout)
+}
+
+func (c *quicCodec) Address() _net.Addr {
+	return c.conn.RemoteAddr()
+}
@@ -0,0 +1,95 @@
+package net
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestQuicAddress(t *testing.T) {
+	addr, err := quicAddress("127.0.0.1:8483")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != "127.0.0.1:8484" {
+		t.Fatalf("unexpected quic address %s", addr)
+	}
+	if _, err = quicAddress("127.0.0.1"); err == nil {
+		t.Fatal("expected error of missing port")
+	}
+}
+
+func TestQuicCodec(t *testing.T) {
+	ln, err := listenQUIC("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	factory := &transportFactory{
+		minCompressLength: 100,
+		readTimeout:       5 * time.Second,
+		writeTimeout:      5 * time.Second,
+	}
+
+	accepted := make(chan Codec, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			t.Error(err)
+			close(accepted)
+			return
+		}
+		accepted <- factory.CreateCodec(conn)
+	}()
+
+	conn, err := dialQUIC(ln.Addr().String(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := factory.CreateCodec(conn)
+	defer client.Close()
+
+	// the first message opens the control stream
+	msgs := []Msg{
+		{Code: CodeHandshake, Payload: []byte("handshake")},
+		{Code: CodeSnapshotBlocks, Id: 3, Payload: bytes.Repeat([]byte{1}, 1000)},
+		{Code: CodeNewAccountBlock, Payload: []byte("block")},
+		{Code: CodeHeartBeat, Payload: []byte("beat")},
+	}
+	for _, msg := range msgs {
+		if err = client.WriteMsg(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := <-accepted
+	if server == nil {
+		t.FailNow()
+	}
+	defer server.Close()
+
+	received := make(map[Code]Msg)
+	for range msgs {
+		msg, err := server.ReadMsg()
+		if err != nil {
+			t.Fatal(err)
+		}
+		received[msg.Code] = msg
+	}
+	for _, msg := range msgs {
+		r, ok := received[msg.Code]
+		if !ok || r.Id != msg.Id || !bytes.Equal(r.Payload, msg.Payload) {
+			t.Fatalf("unexpected message of code %d: %v", msg.Code, r)
+		}
+	}
+
+	// and the reply
+	if err = server.WriteMsg(Msg{Code: CodeHeartBeat, Payload: []byte("pong")}); err != nil {
+		t.Fatal(err)
+	}
+	msg, err := client.ReadMsg()
+	if err != nil || string(msg.Payload) != "pong" {
+		t.Fatalf("unexpected reply %v: %v", msg, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/vitelabs/go-vite/v2/common"
 	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
@@ -23,6 +24,15 @@ type Config struct {
 
 	KeyStoreDir string `json:"KeyStoreDir"`
 
+	// kdf of the new entropy store files, "scrypt" or "argon2id", zero values mean the standard parameters
+	KeyStoreKDF           string `json:"KeyStoreKDF"`
+	KeyStoreScryptN       int    `json:"KeyStoreScryptN"`
+	KeyStoreScryptR       int    `json:"KeyStoreScryptR"`
+	KeyStoreScryptP       int    `json:"KeyStoreScryptP"`
+	KeyStoreArgon2Time    uint32 `json:"KeyStoreArgon2Time"`
+	KeyStoreArgon2Memory  uint32 `json:"KeyStoreArgon2Memory"` // in KiB
+	KeyStoreArgon2Threads uint8  `json:"KeyStoreArgon2Threads"`
+
 	// chain
 	LedgerGcRetain uint64          `json:"LedgerGcRetain"`
 	LedgerGc       *bool           `json:"LedgerGc"`
@@ -30,6 +40,34 @@ type Config struct {
 	VmLogWhiteList []types.Address `json:"vmLogWhiteList"` // contract address white list which save VM logs
 	VmLogAll       *bool           `json:"vmLogAll"`       // save all VM logs, it will cost more disk space
 
+	LedgerMode           string `json:"LedgerMode"`           // "archive", "full" or "light", default is "full"
+	HistoryRetentionDays uint64 `json:"HistoryRetentionDays"` // the history older than it is pruned at startup and hourly in full mode, 0 means never
+
+	AccountCacheSize int `json:"AccountCacheSize"` // size of the account cache of the index db, 0 means the default
+	StateCacheSize   int `json:"StateCacheSize"`   // size of the StateDB cache in MiB, 0 means the default
+
+	StateCacheWarmUp       bool   `json:"StateCacheWarmUp"`       // preload the hot balances into the StateDB cache at startup
+	StateCacheWarmUpBlocks uint64 `json:"StateCacheWarmUpBlocks"` // the accounts in the latest snapshot blocks are hot, 0 means the default
+
+	PoolJournal        bool `json:"PoolJournal"`        // persist the account blocks in the pool and replay them at startup
+	PoolJournalMaxSize int  `json:"PoolJournalMaxSize"` // in MiB, 0 means the default
+
+	BlockFileSyncMode     string `json:"BlockFileSyncMode"`     // "write", "flush" or "timed", default is "write"
+	BlockFileSyncInterval int    `json:"BlockFileSyncInterval"` // in milliseconds, the interval of the "timed" sync mode, 0 means the default
+	BlockFilePreallocate  bool   `json:"BlockFilePreallocate"`  // allocate the disk space of a block file when it's created
+	BlockFileDSync        bool   `json:"BlockFileDSync"`        // open the block files with O_DSYNC, the sync mode is ignored
+
+	CompactAt string `json:"CompactAt"` // "HH:MM" local time of the daily compaction of the index, state and redo dbs, off-peak is recommended
+
+	MaxBatchSize int `json:"MaxBatchSize"` // in MiB, the snapshot batches are split into sub-batches no larger than it, 0 means the default
+
+	// sync
+	SyncMode           string `json:"SyncMode"`           // "full" or "snapshot", default is "full"
+	SnapshotSyncUrl    string `json:"SnapshotSyncUrl"`    // url of the ledger snapshot, a tar.gz of the ledger dir
+	SnapshotSyncSha256 string `json:"SnapshotSyncSha256"` // hex sha256 of the ledger snapshot file
+	SnapshotSyncHeight uint64 `json:"SnapshotSyncHeight"` // height of the trusted snapshot block
+	SnapshotSyncHash   string `json:"SnapshotSyncHash"`   // hash of the trusted snapshot block
+
 	// genesis
 	GenesisFile string `json:"GenesisFile"`
 
@@ -38,8 +76,11 @@ type Config struct {
 	ListenInterface    string
 	Port               int
 	FilePort           int
+	Transport          string // "tcp" or "quic", default "tcp"
+	Encryption         string // "none", "preferred" or "required", default "preferred"
 	PublicAddress      string
 	FilePublicAddress  string
+	NAT                string // "none", "any", "upnp", "pmp", "pmp:<gateway>" or "extip:<ip>", default "any"
 	Identity           string
 	NetID              int
 	PeerKey            string `json:"PrivateKey"`
@@ -48,22 +89,58 @@ type Config struct {
 	MinPeers           int
 	MaxInboundRatio    int
 	MaxPendingPeers    int
+	UploadLimit        int // KB/s of all connections, 0 is unlimited
+	DownloadLimit      int // KB/s of all connections, 0 is unlimited
+	PeerUploadLimit    int // KB/s of each connection, 0 is unlimited
+	PeerDownloadLimit  int // KB/s of each connection, 0 is unlimited
 	BootNodes          []string
 	BootSeeds          []string
 	StaticNodes        []string
+	TrustedNodes       []string // static nodes beyond the peer limits and never banned
 	AccessControl      string
 	AccessAllowKeys    []string
 	AccessDenyKeys     []string
 	BlackBlockHashList []string // from high to low, like: "xxxxxx-11111"
 	WhiteBlockList     []string // from high to low, like: "xxxxxx-10001"
+	Checkpoints        []string // trusted snapshot blocks, like: "xxxxxx/10001", empty means the default of the network
 	ForwardStrategy    string
 
 	//producer
 	EntropyStorePath     string `json:"EntropyStorePath"`
 	EntropyStorePassword string `json:"EntropyStorePassword"`
 	CoinBase             string `json:"CoinBase"`
+	LedgerSigner         bool   `json:"LedgerSigner"`     // the coinbase key is held by a Ledger device
+	LedgerDevicePath     string `json:"LedgerDevicePath"` // hidraw path of the device, empty means the first device
+	RemoteSigner         string `json:"RemoteSigner"`     // endpoint of the signer daemon which holds the coinbase key
+	RemoteSignerToken    string `json:"RemoteSignerToken"`
+	RemoteSignerTimeout  int    `json:"RemoteSignerTimeout"` // in seconds, zero means no timeout
 	MinerEnabled         bool   `json:"Miner"`
 	ExternalMiner        bool   `json:"ExternalMiner"`
+	AutoMine             bool   `json:"AutoMine"`         // dev mode, requires ExternalMiner
+	AutoMineInterval     int    `json:"AutoMineInterval"` // in seconds, zero means mining only for pending blocks
+
+	EntropyStorePathFormat      string `json:"EntropyStorePathFormat"`      // derivation path like "m/44'/666666'/%d'", empty means the default
+	EntropyStoreBip39Passphrase string `json:"EntropyStoreBip39Passphrase"` // the optional 25th word
+
+	// consensus
+	ConsensusReplayWindow int `json:"ConsensusReplayWindow"` // in seconds
+
+	// onroad
+	OnRoadOrderPolicy     string          `json:"OnRoadOrderPolicy"`     // "default", "difficulty", "quota", "age" or "fee"
+	OnRoadPriorityCallers []types.Address `json:"OnRoadPriorityCallers"` // callers whose blocks are received first
+
+	// producer failover
+	ProducerStandby    bool     `json:"ProducerStandby"`    // hot-standby of the primary producer of CoinBase
+	HeartbeatListen    string   `json:"HeartbeatListen"`    // udp address the standby receives heartbeats on
+	HeartbeatAddrs     []string `json:"HeartbeatAddrs"`     // udp addresses of the standby nodes the primary sends heartbeats to
+	StandbyMissedSlots int      `json:"StandbyMissedSlots"` // missed slots of the primary before the standby takes over
+	SlotLockDir        string   `json:"SlotLockDir"`        // directory shared by primary and standby to lock slots
+
+	// producer health, the snapshot slot is skipped if the node is unhealthy
+	ProducerMaxSyncLag   uint64 `json:"ProducerMaxSyncLag"`   // max snapshot heights behind the sync peer, 0 means unchecked
+	ProducerMinPeers     int    `json:"ProducerMinPeers"`     // min peers connected, 0 means unchecked
+	ProducerCheckFlusher bool   `json:"ProducerCheckFlusher"` // skip the slots while the flusher is stopped or recovering
+	ProducerMaxClockSkew int    `json:"ProducerMaxClockSkew"` // in milliseconds, max drift to the ntp servers, 0 means unchecked
 
 	//rpc
 	RPCEnabled  bool  `json:"RPCEnabled"`
@@ -83,14 +160,29 @@ type Config struct {
 	PublicModules       []string `json:"PublicModules"`
 	WSExposeAll         bool     `json:"WSExposeAll"`
 	HttpExposeAll       bool     `json:"HttpExposeAll"`
+	GraphQLEnabled      bool     `json:"GraphQLEnabled"` // serve graphql at /graphql of the http endpoint
+	GRPCEnabled         bool     `json:"GRPCEnabled"`
+	GRPCHost            string   `json:"GRPCHost"`
+	GRPCPort            int      `json:"GRPCPort"`
+	EthDefaultTokenId   string   `json:"EthDefaultTokenId"` // the token of balances and values in the eth namespace
 	TestTokenHexPrivKey string   `json:"TestTokenHexPrivKey"`
 	TestTokenTti        string   `json:"TestTokenTti"`
 
+	// limits of the http and websocket endpoints, nil means unlimited
+	RPCLimit       *config.RPCLimit `json:"RPCLimit"`
+	RPCMaxPageSize uint64           `json:"RPCMaxPageSize"` // max page size of cursor paged queries, 0 means the default, at most 1000
+
 	PowServerUrl string `json:"PowServerUrl"`
+	// provider of the PoW of the sent transactions, "local" or "remote" (the PowServerUrl), default is "local"
+	PoWProvider  string `json:"PoWProvider"`
+	PoWWorkers   int    `json:"PoWWorkers"`   // the max concurrent PoW computations with the nonce cache, 0 means no pool
+	PoWCacheSize int    `json:"PoWCacheSize"` // the number of the cached nonces of the pool, 0 means the default
 
 	//Log level
 	LogLevel    string `json:"LogLevel"`
 	ErrorLogDir string `json:"ErrorLogDir"`
+	// format of the log files, logfmt or json
+	LogFormat string `json:"LogFormat"`
 
 	//VM
 	VMTestEnabled         bool `json:"VMTestEnabled"`
@@ -115,10 +207,42 @@ type Config struct {
 	InfluxDBUsername *string `json:"InfluxDBUsername"`
 	InfluxDBPassword *string `json:"InfluxDBPassword"`
 	InfluxDBHostTag  *string `json:"InfluxDBHostTag"`
+
+	// prometheus metrics on a dedicated port
+	PrometheusEnabled bool   `json:"PrometheusEnabled"`
+	PrometheusHost    string `json:"PrometheusHost"`
+	PrometheusPort    int    `json:"PrometheusPort"`
+
+	// push the metrics to an OpenTelemetry collector by OTLP/HTTP, eg: http://127.0.0.1:4318
+	OTLPEnabled  bool              `json:"OTLPEnabled"`
+	OTLPEndpoint string            `json:"OTLPEndpoint"`
+	OTLPHeaders  map[string]string `json:"OTLPHeaders"`
+	// interval in seconds of pushing the metrics to InfluxDB and the OpenTelemetry collector, 10 if not set
+	MetricsPushInterval int `json:"MetricsPushInterval"`
+
+	// pprof, runtime trace and dumps on an admin listener, requests must carry DebugToken
+	DebugEnabled bool   `json:"DebugEnabled"`
+	DebugHost    string `json:"DebugHost"`
+	DebugPort    int    `json:"DebugPort"`
+	DebugToken   string `json:"DebugToken"`
+
+	// the node is ready if it has at least ReadyMinPeers peers,
+	// and the latest snapshot block is not older than ReadyMaxSyncLag seconds
+	ReadyMinPeers   int `json:"ReadyMinPeers"`
+	ReadyMaxSyncLag int `json:"ReadyMaxSyncLag"`
 }
 
 func (c *Config) MakeWalletConfig() *config.Wallet {
-	return &config.Wallet{DataDir: c.KeyStoreDir}
+	return &config.Wallet{
+		DataDir:       c.KeyStoreDir,
+		KDF:           c.KeyStoreKDF,
+		ScryptN:       c.KeyStoreScryptN,
+		ScryptR:       c.KeyStoreScryptR,
+		ScryptP:       c.KeyStoreScryptP,
+		Argon2Time:    c.KeyStoreArgon2Time,
+		Argon2Memory:  c.KeyStoreArgon2Memory,
+		Argon2Threads: c.KeyStoreArgon2Threads,
+	}
 }
 
 func (c *Config) MakeViteConfig() *config.Config {
@@ -145,24 +269,33 @@ func (c *Config) MakeNetConfig() *config.Net {
 		ListenInterface:    c.ListenInterface,
 		Port:               c.Port,
 		FilePort:           c.FilePort,
+		Transport:          c.Transport,
+		Encryption:         c.Encryption,
 		PublicAddress:      c.PublicAddress,
 		FilePublicAddress:  c.FilePublicAddress,
+		NAT:                c.NAT,
 		DataDir:            datadir,
 		PeerKey:            c.PeerKey,
 		Discover:           c.Discover,
 		BootNodes:          c.BootNodes,
 		BootSeeds:          c.BootSeeds,
 		StaticNodes:        c.StaticNodes,
+		TrustedNodes:       c.TrustedNodes,
 		MaxPeers:           c.MaxPeers,
 		MaxInboundRatio:    c.MaxInboundRatio,
 		MinPeers:           c.MinPeers,
 		MaxPendingPeers:    c.MaxPendingPeers,
+		UploadLimit:        c.UploadLimit * 1024,
+		DownloadLimit:      c.DownloadLimit * 1024,
+		PeerUploadLimit:    c.PeerUploadLimit * 1024,
+		PeerDownloadLimit:  c.PeerDownloadLimit * 1024,
 		ForwardStrategy:    c.ForwardStrategy,
 		AccessControl:      c.AccessControl,
 		AccessAllowKeys:    c.AccessAllowKeys,
 		AccessDenyKeys:     c.AccessDenyKeys,
 		BlackBlockHashList: c.BlackBlockHashList,
 		WhiteBlockList:     c.WhiteBlockList,
+		Checkpoints:        c.Checkpoints,
 		MineKey:            nil,
 	}
 }
@@ -193,7 +326,32 @@ func (c *Config) MakeMinerConfig() *config.Producer {
 		Producer:         c.MinerEnabled,
 		Coinbase:         c.CoinBase,
 		EntropyStorePath: c.EntropyStorePath,
+		Ledger:           c.LedgerSigner,
+		LedgerDevicePath: c.LedgerDevicePath,
+
+		RemoteSigner:        c.RemoteSigner,
+		RemoteSignerToken:   c.RemoteSignerToken,
+		RemoteSignerTimeout: time.Duration(c.RemoteSignerTimeout) * time.Second,
+
 		ExternalMiner:    c.ExternalMiner,
+		AutoMine:         c.AutoMine,
+		AutoMineInterval: time.Duration(c.AutoMineInterval) * time.Second,
+
+		ConsensusReplayWindow: time.Duration(c.ConsensusReplayWindow) * time.Second,
+
+		OnRoadOrderPolicy:     c.OnRoadOrderPolicy,
+		OnRoadPriorityCallers: c.OnRoadPriorityCallers,
+
+		Standby:            c.ProducerStandby,
+		HeartbeatListen:    c.HeartbeatListen,
+		HeartbeatAddrs:     c.HeartbeatAddrs,
+		StandbyMissedSlots: c.StandbyMissedSlots,
+		SlotLockDir:        c.SlotLockDir,
+
+		HealthMaxSyncLag:   c.ProducerMaxSyncLag,
+		HealthMinPeers:     c.ProducerMinPeers,
+		HealthCheckFlusher: c.ProducerCheckFlusher,
+		HealthMaxClockSkew: time.Duration(c.ProducerMaxClockSkew) * time.Millisecond,
 	}
 	err := cfg.Parse()
 	if err != nil {
@@ -226,6 +384,41 @@ func (c *Config) MakeChainConfig() *config.Chain {
 		OpenPlugins:    openPlugins,
 		VmLogWhiteList: c.VmLogWhiteList,
 		VmLogAll:       vmLogAll,
+
+		Mode:                 config.LedgerMode(c.LedgerMode),
+		HistoryRetentionDays: c.HistoryRetentionDays,
+
+		AccountCacheSize: c.AccountCacheSize,
+		StateCacheSize:   int64(c.StateCacheSize) * 1024 * 1024,
+
+		StateCacheWarmUp:       c.StateCacheWarmUp,
+		StateCacheWarmUpBlocks: c.StateCacheWarmUpBlocks,
+
+		PoolJournal:        c.PoolJournal,
+		PoolJournalMaxSize: int64(c.PoolJournalMaxSize) * 1024 * 1024,
+
+		BlockFileSyncMode:     c.BlockFileSyncMode,
+		BlockFileSyncInterval: time.Duration(c.BlockFileSyncInterval) * time.Millisecond,
+		BlockFilePreallocate:  c.BlockFilePreallocate,
+		BlockFileDSync:        c.BlockFileDSync,
+
+		CompactAt: c.CompactAt,
+
+		MaxBatchSize: c.MaxBatchSize * 1024 * 1024,
+	}
+}
+
+// MakeReloadableConfig returns the part of the config which can be reloaded without restart
+func (c *Config) MakeReloadableConfig() *config.Reloadable {
+	return &config.Reloadable{
+		LogLevel:         c.LogLevel,
+		RPCLimit:         c.RPCLimit,
+		MaxPeers:         c.MaxPeers,
+		MinPeers:         c.MinPeers,
+		MaxInboundRatio:  c.MaxInboundRatio,
+		VmLogWhiteList:   c.VmLogWhiteList,
+		AccountCacheSize: c.AccountCacheSize,
+		StateCacheSize:   int64(c.StateCacheSize) * 1024 * 1024,
 	}
 }
 
@@ -243,6 +436,41 @@ func (c *Config) WSEndpoint() string {
 	return fmt.Sprintf("%s:%d", c.WSHost, c.WSPort)
 }
 
+// GRPCEndpoint returns the endpoint of the gRPC server, the default host and port are used if they're not set,
+// so the server never listens on all the interfaces or a random port by accident
+func (c *Config) GRPCEndpoint() string {
+	host, port := c.GRPCHost, c.GRPCPort
+	if host == "" {
+		host = common.DefaultGRPCHost
+	}
+	if port == 0 {
+		port = common.DefaultGRPCPort
+	}
+	return fmt.Sprintf("%s:%d", host, port)
+}
+
+func (c *Config) PrometheusEndpoint() string {
+	if c.PrometheusHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.PrometheusHost, c.PrometheusPort)
+}
+
+// MetricsPushPeriod returns the interval of pushing the metrics to InfluxDB and the OpenTelemetry collector
+func (c *Config) MetricsPushPeriod() time.Duration {
+	if c.MetricsPushInterval <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.MetricsPushInterval) * time.Second
+}
+
+func (c *Config) DebugEndpoint() string {
+	if c.DebugHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.DebugHost, c.DebugPort)
+}
+
 func (c *Config) SetPrivateKey(privateKey string) {
 	c.PeerKey = privateKey
 }
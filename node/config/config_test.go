@@ -62,3 +62,14 @@ func deferReturn() (e error) {
 	}()
 	return errors.New("aaa")
 }
+
+func TestConfig_GRPCEndpoint(t *testing.T) {
+	config := &Config{}
+	if endpoint := config.GRPCEndpoint(); endpoint != "localhost:48134" {
+		t.Fatalf("unexpected endpoint %s", endpoint)
+	}
+	config.GRPCHost, config.GRPCPort = "0.0.0.0", 1234
+	if endpoint := config.GRPCEndpoint(); endpoint != "0.0.0.0:1234" {
+		t.Fatalf("unexpected endpoint %s", endpoint)
+	}
+}
@@ -16,6 +16,16 @@ var DefaultNodeConfig = Config{
 	KeyStoreDir: DefaultDataDir(),
 	HttpPort:    common.DefaultHTTPPort,
 	WSPort:      common.DefaultWSPort,
+	GRPCHost:    common.DefaultGRPCHost,
+	GRPCPort:    common.DefaultGRPCPort,
+
+	PrometheusHost: common.DefaultPrometheusHost,
+	PrometheusPort: common.DefaultPrometheusPort,
+	DebugHost:      common.DefaultDebugHost,
+	DebugPort:      common.DefaultDebugPort,
+
+	ReadyMinPeers:   1,
+	ReadyMaxSyncLag: 180,
 
 	LogLevel:      "info",
 	HTTPCors:      []string{"*"},
@@ -30,6 +40,7 @@ var DefaultNodeConfig = Config{
 	Port:            config.DefaultPort,
 	FilePort:        config.DefaultFilePort,
 	Discover:        config.DefaultDiscover,
+	NAT:             config.DefaultNAT,
 	MaxPeers:        config.DefaultMaxPeers,
 	MaxInboundRatio: config.DefaultMaxInboundRatio,
 	MinPeers:        config.DefaultMinPeers,
@@ -0,0 +1,106 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+// The probes are served by the http endpoint of the rpc server:
+//   /live  returns 200 as long as the process is up
+//   /ready returns 200 if the node is ready, otherwise 503
+// Liveness is not served at /health, which is already taken by the health_health check of the rpc server.
+
+// ReadyCheck is the result of one readiness check
+type ReadyCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ReadyStatus is ready if all checks are ok
+type ReadyStatus struct {
+	Ready  bool          `json:"ready"`
+	Checks []*ReadyCheck `json:"checks"`
+}
+
+func (status *ReadyStatus) add(name string, ok bool, detail string) {
+	status.Checks = append(status.Checks, &ReadyCheck{Name: name, OK: ok, Detail: detail})
+	status.Ready = status.Ready && ok
+}
+
+// flusherState is the state of the chain flusher checked by Ready
+type flusherState interface {
+	Running() bool
+	Recovering() bool
+}
+
+// Ready checks whether the node is able to serve: the ledger is open, the flusher is running and not recovering
+// from a failed flush, the node has enough peers and the latest snapshot block is recent.
+func (node *Node) Ready() *ReadyStatus {
+	status := &ReadyStatus{Ready: true}
+	if node.viteServer == nil || node.viteServer.Chain() == nil {
+		status.add("ledger", false, "ledger is not open")
+		return status
+	}
+	c := node.viteServer.Chain()
+
+	sb := c.GetLatestSnapshotBlock()
+	if sb == nil {
+		status.add("ledger", false, "latest snapshot block is nil")
+		return status
+	}
+	status.add("ledger", true, fmt.Sprintf("snapshot height %d", sb.Height))
+
+	checkFlusher(status, chainFlusher(c))
+
+	if !node.config.Single && node.viteServer.Net() != nil {
+		peers := node.viteServer.Net().PeerCount()
+		status.add("peers", peers >= node.config.ReadyMinPeers,
+			fmt.Sprintf("%d peers, at least %d", peers, node.config.ReadyMinPeers))
+	}
+
+	lag := time.Since(*sb.Timestamp).Truncate(time.Second)
+	maxLag := time.Duration(node.config.ReadyMaxSyncLag) * time.Second
+	status.add("sync", maxLag <= 0 || lag <= maxLag,
+		fmt.Sprintf("latest snapshot block is %s behind, at most %s", lag, maxLag))
+	return status
+}
+
+// chainFlusher returns an untyped nil if the chain has no flusher, so that the nil check in checkFlusher works
+func chainFlusher(c chain.Chain) flusherState {
+	if flusher := c.Flusher(); flusher != nil {
+		return flusher
+	}
+	return nil
+}
+
+func checkFlusher(status *ReadyStatus, flusher flusherState) {
+	switch {
+	case flusher == nil || !flusher.Running():
+		status.add("flusher", false, "flusher is stopped")
+	case flusher.Recovering():
+		status.add("flusher", false, "the last flush failed, the flusher is recovering")
+	default:
+		status.add("flusher", true, "")
+	}
+}
+
+// serveLive returns 200 as long as the process is up
+func (node *Node) serveLive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"status":"ok"}`))
+}
+
+// serveReady returns 200 if the node is ready, otherwise 503, with the checks in the body
+func (node *Node) serveReady(w http.ResponseWriter, r *http.Request) {
+	status := node.Ready()
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
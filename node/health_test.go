@@ -0,0 +1,68 @@
+package node
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/rpc"
+)
+
+type testFlusher struct {
+	running, recovering bool
+}
+
+func (f *testFlusher) Running() bool {
+	return f.running
+}
+
+func (f *testFlusher) Recovering() bool {
+	return f.recovering
+}
+
+func TestCheckFlusher(t *testing.T) {
+	for _, c := range []struct {
+		flusher flusherState
+		ok      bool
+	}{
+		{nil, false},
+		{&testFlusher{}, false},
+		{&testFlusher{running: true, recovering: true}, false},
+		{&testFlusher{running: true}, true},
+	} {
+		status := &ReadyStatus{Ready: true}
+		checkFlusher(status, c.flusher)
+		assert.Equal(t, c.ok, status.Ready)
+		if assert.Len(t, status.Checks, 1) {
+			assert.Equal(t, "flusher", status.Checks[0].Name)
+			assert.Equal(t, c.ok, status.Checks[0].OK)
+		}
+	}
+}
+
+func TestNode_Probes(t *testing.T) {
+	node := &Node{}
+
+	// the health check of the rpc server is not shadowed
+	server := rpc.NewServer()
+	server.RegisterHTTPHandler("/live", http.HandlerFunc(node.serveLive))
+	server.RegisterHTTPHandler("/ready", http.HandlerFunc(node.serveReady))
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/live", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "health_health")
+
+	// the ledger is not open
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "ledger is not open")
+}
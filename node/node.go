@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"os/signal"
@@ -23,7 +24,10 @@ import (
 	"github.com/vitelabs/go-vite/v2/rpc"
 	"github.com/vitelabs/go-vite/v2/rpcapi"
 	"github.com/vitelabs/go-vite/v2/rpcapi/api/filters"
+	"github.com/vitelabs/go-vite/v2/rpcapi/graphql"
+	"github.com/vitelabs/go-vite/v2/rpcapi/grpcapi"
 	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
 )
 
 var (
@@ -61,6 +65,17 @@ type Node struct {
 
 	wsCli *rpc.WebSocketCli
 
+	rpcLimiter *rpc.Limiter
+
+	configLoader   func() (*nodeconfig.Config, error)
+	configWatchers []config.ConfigWatcher
+
+	grpcServer *grpcapi.Server
+
+	metricsServer  *monitor.MetricsServer
+	metricsPushers []*monitor.Pusher
+	debugServer    *monitor.DebugServer
+
 	// Channel to wait for termination notifications
 	stop            chan struct{}
 	lock            sync.RWMutex
@@ -89,6 +104,11 @@ func (node *Node) Prepare() (err error) {
 	}
 	log.Info(fmt.Sprintf("DataDir is OK. "))
 
+	if err = node.snapshotSync(); err != nil {
+		log.Error(fmt.Sprintf("snapshot sync error: %v", err))
+		return err
+	}
+
 	//prepare node
 	log.Info(fmt.Sprintf("Begin Prepare node... "))
 	//prepare wallet
@@ -122,12 +142,21 @@ func (node *Node) Prepare() (err error) {
 	//init rpc_PowServerUrl
 	remote.InitRawUrl(node.Config().PowServerUrl)
 	pow.Init(node.Config().VMTestParamEnabled)
+	if err = node.initPoWProvider(); err != nil {
+		log.Error(fmt.Sprintf("init pow provider error: %v", err))
+		return err
+	}
 
 	// Start vite
 	if err = node.viteServer.Init(); err != nil {
 		log.Error(fmt.Sprintf("ViteServer init error: %v", err))
 		return err
 	}
+
+	if err = node.checkSnapshotSync(); err != nil {
+		log.Error(fmt.Sprintf("check snapshot sync error: %v", err))
+		return err
+	}
 	return nil
 }
 
@@ -150,6 +179,34 @@ func (node *Node) Start() error {
 	}
 	monitor.InitNTPChecker(log)
 
+	if node.config.PrometheusEnabled {
+		server := monitor.NewMetricsServer(nil)
+		if err := server.Start(node.config.PrometheusEndpoint()); err != nil {
+			log.Error(fmt.Sprintf("Node start metrics server error: %v", err))
+			return err
+		}
+		node.metricsServer = server
+		log.Info("Prometheus metrics opened", "url", fmt.Sprintf("http://%s/metrics", server.Addr()))
+	}
+
+	if err := node.startMetricsPushers(); err != nil {
+		log.Error(fmt.Sprintf("Node start metrics exporter error: %v", err))
+		return err
+	}
+
+	if node.config.DebugEnabled {
+		server, err := monitor.NewDebugServer(node.config.DebugToken, node.config.DataDir)
+		if err == nil {
+			err = server.Start(node.config.DebugEndpoint())
+		}
+		if err != nil {
+			log.Error(fmt.Sprintf("Node start debug server error: %v", err))
+			return err
+		}
+		node.debugServer = server
+		log.Info("Debug server opened", "url", fmt.Sprintf("http://%s/debug/pprof/", server.Addr()))
+	}
+
 	return nil
 }
 
@@ -187,6 +244,19 @@ func (node *Node) Stop() error {
 		log.Error(fmt.Sprintf("Node stopRPC error: %v", err))
 	}
 
+	if node.metricsServer != nil {
+		node.metricsServer.Stop()
+		node.metricsServer = nil
+	}
+	for _, pusher := range node.metricsPushers {
+		pusher.Stop()
+	}
+	node.metricsPushers = nil
+	if node.debugServer != nil {
+		node.debugServer.Stop()
+		node.debugServer = nil
+	}
+
 	// Release instance directory lock.
 	log.Info(fmt.Sprintf("Begin relaeck dataDir lock... "))
 	if node.instanceDirLock != nil {
@@ -205,14 +275,24 @@ func (node *Node) Wait() {
 	// Listening event closes the node
 
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	defer signal.Stop(c)
-	select {
-	case <-c:
-		break
-	case _, ok := <-node.stop:
-		if !ok {
-			return
+	for wait := true; wait; {
+		select {
+		case sig := <-c:
+			if sig != syscall.SIGHUP {
+				wait = false
+				break
+			}
+			// SIGHUP reloads the config and keeps running
+			if err := node.ReloadConfig(); err != nil {
+				log.Error("reload config on SIGHUP failed", "err", err)
+			}
+		case _, ok := <-node.stop:
+			if !ok {
+				return
+			}
+			wait = false
 		}
 	}
 
@@ -256,7 +336,10 @@ func (node *Node) startWallet() (err error) {
 		}
 
 		//unlock
-		err := node.walletManager.Unlock(node.config.EntropyStorePath, node.config.EntropyStorePassword)
+		err := node.walletManager.UnlockWithOptions(node.config.EntropyStorePath, node.config.EntropyStorePassword, &entropystore.DeriveOptions{
+			PathFormat:      node.config.EntropyStorePathFormat,
+			Bip39Passphrase: node.config.EntropyStoreBip39Passphrase,
+		})
 		if err != nil {
 			log.Error(fmt.Sprintf("entropyStoreManager.Unlock error: %v, %s", err, node.config.EntropyStorePath))
 			return err
@@ -284,6 +367,10 @@ func (node *Node) startRPC() (e error) {
 
 	// Init rpc log
 	rpcapi.Init(node.config.DataDir, node.config.LogLevel, node.config.TestTokenHexPrivKey, node.config.TestTokenTti, uint(node.config.NetID), node.config.TxDexEnable)
+	if err := rpcapi.InitEth(node.config.EthDefaultTokenId); err != nil {
+		return err
+	}
+	rpcapi.InitMaxPageSize(node.config.RPCMaxPageSize)
 
 	publicApis := rpcapi.GetPublicApis(node.viteServer)
 	customApis := rpcapi.GetApis(node.viteServer, node.config.PublicModules...)
@@ -320,6 +407,15 @@ func (node *Node) startRPC() (e error) {
 				node.stopHTTP()
 			}
 		}()
+
+		// probes for load balancers, /health is still the health_health check of the rpc server
+		node.httpHandler.RegisterHTTPHandler("/live", http.HandlerFunc(node.serveLive))
+		node.httpHandler.RegisterHTTPHandler("/ready", http.HandlerFunc(node.serveReady))
+
+		if node.config.GraphQLEnabled {
+			node.httpHandler.RegisterLimitedHTTPHandler("/graphql", "graphql_query", graphql.NewHandler(node.viteServer.Chain()))
+			log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s/graphql", node.httpEndpoint))
+		}
 	}
 
 	if node.config.WSEnabled {
@@ -332,6 +428,30 @@ func (node *Node) startRPC() (e error) {
 			}
 		}()
 	}
+	if node.config.RPCLimit != nil {
+		node.rpcLimiter = rpc.NewLimiter(*node.config.RPCLimit)
+		if node.httpHandler != nil {
+			node.httpHandler.SetLimiter(node.rpcLimiter)
+		}
+		if node.wsHandler != nil {
+			node.wsHandler.SetLimiter(node.rpcLimiter)
+		}
+	}
+	if node.config.GRPCEnabled {
+		server := grpcapi.NewServer(node.viteServer.Chain())
+		server.SetLimiter(node.rpcLimiter)
+		if err := server.Start(node.config.GRPCEndpoint()); err != nil {
+			return err
+		}
+		node.grpcServer = server
+		log.Info("gRPC endpoint opened", "addr", server.Addr())
+		defer func() {
+			if e != nil {
+				node.stopGRPC()
+			}
+		}()
+	}
+
 	if len(node.config.DashboardTargetURL) > 0 {
 		targetUrl := node.config.DashboardTargetURL + "/ws/gvite/" + strconv.FormatUint(uint64(node.config.NetID), 10) + "@" + node.Vite().Net().Info().ID.String()
 
@@ -362,6 +482,82 @@ func (node *Node) startRPC() (e error) {
 	return nil
 }
 
+// initPoWProvider sets the provider of the PoW of the transactions sent by the node
+func (node *Node) initPoWProvider() error {
+	cfg := node.Config()
+	var provider pow.Provider
+	switch cfg.PoWProvider {
+	case "", "local":
+		provider = pow.LocalProvider{}
+	case "remote":
+		if cfg.PowServerUrl == "" {
+			return errors.New("PowServerUrl is required by the remote pow provider")
+		}
+		provider = remote.NewProvider(cfg.PowServerUrl)
+	default:
+		return fmt.Errorf("unknown pow provider %s", cfg.PoWProvider)
+	}
+	if cfg.PoWWorkers > 0 {
+		provider = pow.NewPoolProvider(provider, cfg.PoWWorkers, cfg.PoWCacheSize)
+	}
+	pow.SetProvider(provider)
+	log.Info("init pow provider", "provider", provider.Name())
+	return nil
+}
+
+// startMetricsPushers pushes the metrics to InfluxDB and the OpenTelemetry collector if they are enabled
+func (node *Node) startMetricsPushers() error {
+	cfg := node.config
+	var exporters []monitor.Exporter
+
+	if cfg.InfluxDBEnable != nil && *cfg.InfluxDBEnable {
+		influxCfg := monitor.InfluxDBConfig{
+			Endpoint: stringValue(cfg.InfluxDBEndpoint),
+			Database: stringValue(cfg.InfluxDBDatabase),
+			Username: stringValue(cfg.InfluxDBUsername),
+			Password: stringValue(cfg.InfluxDBPassword),
+		}
+		if host := stringValue(cfg.InfluxDBHostTag); host != "" {
+			influxCfg.Tags = map[string]string{"host": host}
+		}
+		exporter, err := monitor.NewInfluxDBExporter(influxCfg)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	if cfg.OTLPEnabled {
+		otlpCfg := monitor.OTLPConfig{
+			Endpoint: cfg.OTLPEndpoint,
+			Headers:  cfg.OTLPHeaders,
+		}
+		if cfg.Identity != "" {
+			otlpCfg.Attributes = map[string]string{"service.instance.id": cfg.Identity}
+		}
+		exporter, err := monitor.NewOTLPExporter(otlpCfg)
+		if err != nil {
+			return err
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	for _, exporter := range exporters {
+		pusher := monitor.NewPusher(nil, exporter, cfg.MetricsPushPeriod())
+		pusher.Start()
+		node.metricsPushers = append(node.metricsPushers, pusher)
+		log.Info("Metrics exporter opened", "exporter", exporter.Name(), "interval", cfg.MetricsPushPeriod())
+	}
+	return nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
 func (node *Node) stopWallet() error {
 
 	if node.walletManager == nil {
@@ -383,6 +579,7 @@ func (node *Node) stopVite() error {
 }
 
 func (node *Node) stopRPC() error {
+	node.stopGRPC()
 	node.stopWS()
 	node.stopHTTP()
 	node.stopIPC()
@@ -392,6 +589,13 @@ func (node *Node) stopRPC() error {
 	return nil
 }
 
+func (node *Node) stopGRPC() {
+	if node.grpcServer != nil {
+		node.grpcServer.Stop()
+		node.grpcServer = nil
+	}
+}
+
 func (node *Node) openDataDir() error {
 	if node.config.DataDir == "" {
 		return nil
@@ -0,0 +1,119 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/common/config"
+	nodeconfig "github.com/vitelabs/go-vite/v2/node/config"
+	"github.com/vitelabs/go-vite/v2/rpc"
+)
+
+var errNoConfigLoader = errors.New("no config loader is set")
+
+// SetConfigLoader sets the function which reads the node config again on reload
+func (node *Node) SetConfigLoader(loader func() (*nodeconfig.Config, error)) {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+	node.configLoader = loader
+}
+
+// RegisterConfigWatcher adds a watcher which is notified on every reload, besides the chain and the net
+func (node *Node) RegisterConfigWatcher(watcher config.ConfigWatcher) {
+	node.lock.Lock()
+	defer node.lock.Unlock()
+	node.configWatchers = append(node.configWatchers, watcher)
+}
+
+// ReloadConfig loads the config by the config loader and applies the reloadable part of it:
+// log level, rpc limits, peer limits, vmLogWhiteList and cache sizes.
+// Other changes of the config take effect after restart.
+func (node *Node) ReloadConfig() error {
+	node.lock.RLock()
+	loader := node.configLoader
+	node.lock.RUnlock()
+	if loader == nil {
+		return errNoConfigLoader
+	}
+
+	cfg, err := loader()
+	if err != nil {
+		return err
+	}
+	reloadable := cfg.MakeReloadableConfig()
+
+	node.lock.Lock()
+	defer node.lock.Unlock()
+
+	var firstErr error
+	apply := func(name string, fn func() error) {
+		if err := fn(); err != nil {
+			log.Error("reload config failed", "module", name, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		log.Info("reload config", "module", name)
+	}
+
+	apply("log", func() error {
+		if err := common.SetLogLevel(reloadable.LogLevel); err != nil {
+			return err
+		}
+		node.config.LogLevel = reloadable.LogLevel
+		return nil
+	})
+	apply("rpc", func() error {
+		node.reloadRPCLimit(reloadable.RPCLimit)
+		node.config.RPCLimit = reloadable.RPCLimit
+		return nil
+	})
+
+	var watchers []config.ConfigWatcher
+	if node.viteServer != nil {
+		if w, ok := node.viteServer.Chain().(config.ConfigWatcher); ok {
+			watchers = append(watchers, w)
+		}
+		if w, ok := node.viteServer.Net().(config.ConfigWatcher); ok {
+			watchers = append(watchers, w)
+		}
+	}
+	watchers = append(watchers, node.configWatchers...)
+	for _, w := range watchers {
+		w := w
+		apply(fmt.Sprintf("%T", w), func() error {
+			return w.ReloadConfig(reloadable)
+		})
+	}
+
+	node.config.MaxPeers = reloadable.MaxPeers
+	node.config.MinPeers = reloadable.MinPeers
+	node.config.MaxInboundRatio = reloadable.MaxInboundRatio
+	node.config.VmLogWhiteList = reloadable.VmLogWhiteList
+	node.config.AccountCacheSize = reloadable.AccountCacheSize
+	node.config.StateCacheSize = int(reloadable.StateCacheSize / 1024 / 1024)
+	return firstErr
+}
+
+func (node *Node) reloadRPCLimit(limit *config.RPCLimit) {
+	switch {
+	case limit == nil:
+		node.rpcLimiter = nil
+	case node.rpcLimiter != nil:
+		node.rpcLimiter.Update(*limit)
+		return
+	default:
+		node.rpcLimiter = rpc.NewLimiter(*limit)
+	}
+	if node.httpHandler != nil {
+		node.httpHandler.SetLimiter(node.rpcLimiter)
+	}
+	if node.wsHandler != nil {
+		node.wsHandler.SetLimiter(node.rpcLimiter)
+	}
+	if node.grpcServer != nil {
+		node.grpcServer.SetLimiter(node.rpcLimiter)
+	}
+}
@@ -0,0 +1,289 @@
+package node
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+const (
+	SyncModeFull     = "full"
+	SyncModeSnapshot = "snapshot"
+)
+
+const ledgerDirName = "ledger"
+
+// snapshotPendingFile marks the ledger dir installed from a snapshot and not verified by checkSnapshotSync yet
+const snapshotPendingFile = "ledger_snapshot.pending"
+
+const snapshotVerifyBatch = 1000
+
+// snapshotStateReader reads the ledger installed from a snapshot
+type snapshotStateReader interface {
+	GetLatestSnapshotBlock() *core.SnapshotBlock
+	GetSnapshotBlockByHeight(height uint64) (*core.SnapshotBlock, error)
+	GetSnapshotHeadersByHeight(height uint64, higher bool, count uint64) ([]*core.SnapshotBlock, error)
+	GetAccountBlockByHash(blockHash types.Hash) (*core.AccountBlock, error)
+	GetLatestAccountBlock(addr types.Address) (*core.AccountBlock, error)
+}
+
+// snapshotSync downloads the ledger snapshot and installs it as the ledger dir, if the ledger dir doesn't exist.
+// The blocks after the snapshot are downloaded by the normal sync, so the history before the snapshot is not
+// re-executed. Snapshot headers carry no state root, so the snapshot is trusted by its sha256 and the hash of
+// the snapshot block at SnapshotSyncHeight, the state is checked against that block by checkSnapshotSync after
+// the chain is opened. A ledger installed but not verified, e.g. the node crashed before the check, is installed again.
+func (node *Node) snapshotSync() error {
+	cfg := node.config
+	switch cfg.SyncMode {
+	case "", SyncModeFull:
+		return nil
+	case SyncModeSnapshot:
+	default:
+		return fmt.Errorf("unknown sync mode: %s", cfg.SyncMode)
+	}
+
+	if cfg.SnapshotSyncUrl == "" || cfg.SnapshotSyncSha256 == "" {
+		return fmt.Errorf("snapshot sync requires SnapshotSyncUrl and SnapshotSyncSha256")
+	}
+	if cfg.SnapshotSyncHeight == 0 || cfg.SnapshotSyncHash == "" {
+		return fmt.Errorf("snapshot sync requires SnapshotSyncHeight and SnapshotSyncHash")
+	}
+	if _, err := types.HexToHash(cfg.SnapshotSyncHash); err != nil {
+		return fmt.Errorf("invalid SnapshotSyncHash: %v", err)
+	}
+
+	ledgerDir := filepath.Join(cfg.DataDir, ledgerDirName)
+	pendingFile := filepath.Join(cfg.DataDir, snapshotPendingFile)
+	if _, err := os.Stat(pendingFile); err == nil {
+		log.Warn(fmt.Sprintf("ledger snapshot in %s is not verified, install it again", ledgerDir))
+		if err = discardSnapshot(cfg.DataDir); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if _, err := os.Stat(ledgerDir); err == nil {
+		log.Info(fmt.Sprintf("ledger dir %s exists, skip snapshot sync", ledgerDir))
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	file := filepath.Join(cfg.DataDir, "ledger_snapshot.tar.gz")
+	defer os.Remove(file)
+
+	log.Info(fmt.Sprintf("download ledger snapshot from %s", cfg.SnapshotSyncUrl))
+	sum, err := downloadFile(cfg.SnapshotSyncUrl, file)
+	if err != nil {
+		return fmt.Errorf("failed to download ledger snapshot: %v", err)
+	}
+	if !strings.EqualFold(sum, cfg.SnapshotSyncSha256) {
+		return fmt.Errorf("ledger snapshot sha256 mismatch: expected %s, got %s", cfg.SnapshotSyncSha256, sum)
+	}
+
+	tmpDir := filepath.Join(cfg.DataDir, "ledger_snapshot")
+	if err = os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err = extractTarGz(file, tmpDir); err != nil {
+		return fmt.Errorf("failed to extract ledger snapshot: %v", err)
+	}
+	if err = ioutil.WriteFile(pendingFile, nil, 0600); err != nil {
+		return err
+	}
+	if err = os.Rename(filepath.Join(tmpDir, ledgerDirName), ledgerDir); err != nil {
+		_ = os.Remove(pendingFile)
+		return fmt.Errorf("ledger snapshot has no ledger dir: %v", err)
+	}
+
+	log.Info(fmt.Sprintf("ledger snapshot is installed to %s", ledgerDir))
+	return nil
+}
+
+// checkSnapshotSync verifies the ledger installed by snapshotSync against the trusted snapshot block, the ledger
+// dir is removed if it's invalid, so the next start installs the snapshot again instead of failing forever.
+func (node *Node) checkSnapshotSync() error {
+	cfg := node.config
+	if cfg.SyncMode != SyncModeSnapshot {
+		return nil
+	}
+	pendingFile := filepath.Join(cfg.DataDir, snapshotPendingFile)
+	if _, err := os.Stat(pendingFile); os.IsNotExist(err) {
+		return nil
+	}
+
+	hash, err := types.HexToHash(cfg.SnapshotSyncHash)
+	if err != nil {
+		return err
+	}
+	c := node.viteServer.Chain()
+	if err = verifySnapshotState(c, cfg.SnapshotSyncHeight, hash); err != nil {
+		log.Error(fmt.Sprintf("ledger snapshot is invalid, remove it: %v", err))
+		if dErr := c.Destroy(); dErr != nil {
+			return fmt.Errorf("%v, and failed to close the ledger: %v", err, dErr)
+		}
+		node.viteServer = nil
+		if dErr := discardSnapshot(cfg.DataDir); dErr != nil {
+			return fmt.Errorf("%v, and failed to remove the ledger: %v", err, dErr)
+		}
+		return err
+	}
+
+	log.Info(fmt.Sprintf("ledger snapshot is verified at snapshot block %d %s", cfg.SnapshotSyncHeight, hash))
+	return os.Remove(pendingFile)
+}
+
+// verifySnapshotState checks that the ledger has the trusted snapshot block with the snapshot content it commits to,
+// the account state has the account blocks of the content, and the snapshot blocks after it are linked to it.
+func verifySnapshotState(c snapshotStateReader, height uint64, hash types.Hash) error {
+	trusted, err := c.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	if trusted == nil {
+		return fmt.Errorf("snapshot block at %d doesn't exist in the ledger snapshot", height)
+	}
+	if trusted.Hash != hash || trusted.ComputeHash() != hash {
+		return fmt.Errorf("snapshot block at %d is %s, not the trusted %s", height, trusted.Hash, hash)
+	}
+
+	for addr, hh := range trusted.SnapshotContent {
+		block, err := c.GetAccountBlockByHash(hh.Hash)
+		if err != nil {
+			return err
+		}
+		if block == nil || block.AccountAddress != addr || block.Height != hh.Height || block.ComputeHash() != hh.Hash {
+			return fmt.Errorf("account block %s/%d of %s in the trusted snapshot block is missing", hh.Hash, hh.Height, addr)
+		}
+		latest, err := c.GetLatestAccountBlock(addr)
+		if err != nil {
+			return err
+		}
+		if latest == nil || latest.Height < hh.Height {
+			return fmt.Errorf("account state of %s is behind the trusted snapshot block", addr)
+		}
+	}
+
+	prev := trusted
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	for prev.Height < latestHeight {
+		headers, err := c.GetSnapshotHeadersByHeight(prev.Height+1, true, snapshotVerifyBatch)
+		if err != nil {
+			return err
+		}
+		if len(headers) == 0 {
+			return fmt.Errorf("snapshot block at %d is missing", prev.Height+1)
+		}
+		for _, header := range headers {
+			if header.Height != prev.Height+1 || header.PrevHash != prev.Hash || header.ComputeHash() != header.Hash {
+				return fmt.Errorf("snapshot block %s/%d is not linked to the trusted snapshot block", header.Hash, header.Height)
+			}
+			prev = header
+		}
+	}
+
+	return nil
+}
+
+// discardSnapshot removes the ledger dir installed from a snapshot
+func discardSnapshot(dataDir string) error {
+	if err := os.RemoveAll(filepath.Join(dataDir, ledgerDirName)); err != nil {
+		return err
+	}
+	err := os.Remove(filepath.Join(dataDir, snapshotPendingFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func downloadFile(url, file string) (sum string, err error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, h), resp.Body); err != nil {
+		return "", err
+	}
+	if err = f.Sync(); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func extractTarGz(file, dir string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, hdr.Name)
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal file path in snapshot: %s", hdr.Name)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(target, 0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err = os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(out, tr)
+			out.Close()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
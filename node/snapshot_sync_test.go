@@ -0,0 +1,207 @@
+package node
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+	nodeconfig "github.com/vitelabs/go-vite/v2/node/config"
+)
+
+type snapshotLedger struct {
+	snapshotBlocks []*core.SnapshotBlock
+	accountBlocks  map[types.Hash]*core.AccountBlock
+	latest         map[types.Address]*core.AccountBlock
+}
+
+func (l *snapshotLedger) GetLatestSnapshotBlock() *core.SnapshotBlock {
+	return l.snapshotBlocks[len(l.snapshotBlocks)-1]
+}
+
+func (l *snapshotLedger) GetSnapshotBlockByHeight(height uint64) (*core.SnapshotBlock, error) {
+	if height == 0 || height > uint64(len(l.snapshotBlocks)) {
+		return nil, nil
+	}
+	return l.snapshotBlocks[height-1], nil
+}
+
+func (l *snapshotLedger) GetSnapshotHeadersByHeight(height uint64, higher bool, count uint64) ([]*core.SnapshotBlock, error) {
+	var headers []*core.SnapshotBlock
+	for h := height; h <= uint64(len(l.snapshotBlocks)) && uint64(len(headers)) < count; h++ {
+		headers = append(headers, l.snapshotBlocks[h-1])
+	}
+	return headers, nil
+}
+
+func (l *snapshotLedger) GetAccountBlockByHash(blockHash types.Hash) (*core.AccountBlock, error) {
+	return l.accountBlocks[blockHash], nil
+}
+
+func (l *snapshotLedger) GetLatestAccountBlock(addr types.Address) (*core.AccountBlock, error) {
+	return l.latest[addr], nil
+}
+
+// newSnapshotLedger returns a ledger of 5 snapshot blocks, the 3rd one confirms an account block of addr
+func newSnapshotLedger(addr types.Address) *snapshotLedger {
+	ab := &core.AccountBlock{
+		BlockType:      core.BlockTypeReceive,
+		Height:         1,
+		AccountAddress: addr,
+	}
+	ab.Hash = ab.ComputeHash()
+
+	l := &snapshotLedger{
+		accountBlocks: map[types.Hash]*core.AccountBlock{ab.Hash: ab},
+		latest:        map[types.Address]*core.AccountBlock{addr: ab},
+	}
+	var prev types.Hash
+	for h := uint64(1); h <= 5; h++ {
+		ts := time.Unix(int64(1600000000+h), 0)
+		sb := &core.SnapshotBlock{
+			PrevHash:        prev,
+			Height:          h,
+			Timestamp:       &ts,
+			SnapshotContent: core.SnapshotContent{},
+		}
+		if h == 3 {
+			sb.SnapshotContent[addr] = &core.HashHeight{Hash: ab.Hash, Height: ab.Height}
+		}
+		sb.Hash = sb.ComputeHash()
+		prev = sb.Hash
+		l.snapshotBlocks = append(l.snapshotBlocks, sb)
+	}
+	return l
+}
+
+func TestVerifySnapshotState(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	defer upgrade.CleanupUpgradeBox(t)
+
+	addr := types.AddressGovernance
+
+	l := newSnapshotLedger(addr)
+	trusted := l.snapshotBlocks[2]
+	assert.NoError(t, verifySnapshotState(l, 3, trusted.Hash))
+
+	// another trusted hash
+	assert.Error(t, verifySnapshotState(l, 3, l.snapshotBlocks[1].Hash))
+	// the trusted block is not in the ledger
+	assert.Error(t, verifySnapshotState(l, 6, trusted.Hash))
+
+	// the content of the trusted block is changed
+	l = newSnapshotLedger(addr)
+	l.snapshotBlocks[2].SnapshotContent[types.AddressQuota] = &core.HashHeight{Height: 1}
+	assert.Error(t, verifySnapshotState(l, 3, trusted.Hash))
+
+	// the account state is missing
+	l = newSnapshotLedger(addr)
+	delete(l.latest, addr)
+	assert.Error(t, verifySnapshotState(l, 3, trusted.Hash))
+	l = newSnapshotLedger(addr)
+	l.accountBlocks = map[types.Hash]*core.AccountBlock{}
+	assert.Error(t, verifySnapshotState(l, 3, trusted.Hash))
+
+	// a snapshot block after the trusted block isn't linked
+	l = newSnapshotLedger(addr)
+	l.snapshotBlocks[4].PrevHash = types.Hash{1}
+	l.snapshotBlocks[4].Hash = l.snapshotBlocks[4].ComputeHash()
+	assert.Error(t, verifySnapshotState(l, 3, trusted.Hash))
+}
+
+func newLedgerSnapshot(t *testing.T) []byte {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	files := []struct {
+		name string
+		body string
+	}{
+		{"ledger/", ""},
+		{"ledger/blocks/0", "block"},
+	}
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0600, Size: int64(len(f.body)), Typeflag: tar.TypeReg}
+		if f.body == "" {
+			hdr.Mode, hdr.Typeflag = 0700, tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+		_, err := tw.Write([]byte(f.body))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestNode_snapshotSync(t *testing.T) {
+	data := newLedgerSnapshot(t)
+	sum := sha256.Sum256(data)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	dataDir, err := ioutil.TempDir("", "snapshot_sync")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	node := &Node{config: &nodeconfig.Config{
+		DataDir:            dataDir,
+		SyncMode:           SyncModeSnapshot,
+		SnapshotSyncUrl:    server.URL,
+		SnapshotSyncSha256: hex.EncodeToString(sum[:]),
+		SnapshotSyncHeight: 3,
+		SnapshotSyncHash:   types.Hash{1}.String(),
+	}}
+	ledgerDir := filepath.Join(dataDir, ledgerDirName)
+	pendingFile := filepath.Join(dataDir, snapshotPendingFile)
+
+	// installed and pending until verified
+	assert.NoError(t, node.snapshotSync())
+	body, err := ioutil.ReadFile(filepath.Join(ledgerDir, "blocks", "0"))
+	assert.NoError(t, err)
+	assert.Equal(t, "block", string(body))
+	_, err = os.Stat(pendingFile)
+	assert.NoError(t, err)
+
+	// an unverified ledger is installed again
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(ledgerDir, "blocks", "1"), []byte("stale"), 0600))
+	assert.NoError(t, node.snapshotSync())
+	_, err = os.Stat(filepath.Join(ledgerDir, "blocks", "1"))
+	assert.True(t, os.IsNotExist(err))
+
+	// a verified ledger is kept
+	assert.NoError(t, os.Remove(pendingFile))
+	assert.NoError(t, node.snapshotSync())
+	_, err = os.Stat(filepath.Join(ledgerDir, "blocks", "0"))
+	assert.NoError(t, err)
+
+	// an invalid ledger is discarded
+	assert.NoError(t, ioutil.WriteFile(pendingFile, nil, 0600))
+	assert.NoError(t, discardSnapshot(dataDir))
+	_, err = os.Stat(ledgerDir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(pendingFile)
+	assert.True(t, os.IsNotExist(err))
+
+	// the sha256 mismatches
+	node.config.SnapshotSyncSha256 = types.Hash{}.String()
+	assert.Error(t, node.snapshotSync())
+	_, err = os.Stat(ledgerDir)
+	assert.True(t, os.IsNotExist(err))
+}
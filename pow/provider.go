@@ -0,0 +1,233 @@
+package pow
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// Provider computes the PoW nonce of a transaction, data = Hash(address + prev_hash)
+type Provider interface {
+	Name() string
+	Nonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error)
+}
+
+// Precomputer is implemented by the providers which can compute the nonce before it is requested
+type Precomputer interface {
+	Precompute(difficulty *big.Int, dataHash types.Hash)
+}
+
+var (
+	providerMu      sync.RWMutex
+	defaultProvider Provider = LocalProvider{}
+)
+
+// SetProvider sets the provider used to send transactions, nil means the local cpu
+func SetProvider(p Provider) {
+	if p == nil {
+		p = LocalProvider{}
+	}
+	providerMu.Lock()
+	defaultProvider = p
+	providerMu.Unlock()
+}
+
+// DefaultProvider returns the provider used to send transactions
+func DefaultProvider() Provider {
+	providerMu.RLock()
+	defer providerMu.RUnlock()
+	return defaultProvider
+}
+
+// Precompute asks the default provider to compute the nonce in the background if it supports
+func Precompute(difficulty *big.Int, dataHash types.Hash) {
+	if p, ok := DefaultProvider().(Precomputer); ok {
+		p.Precompute(difficulty, dataHash)
+	}
+}
+
+// LocalProvider computes the nonce by the local cpu
+type LocalProvider struct{}
+
+func (LocalProvider) Name() string {
+	return "local"
+}
+
+func (LocalProvider) Nonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	return GetPowNonce(difficulty, dataHash)
+}
+
+var errPoolClosed = errors.New("pow pool is closed")
+
+type nonceResult struct {
+	difficulty *big.Int
+	nonce      []byte
+}
+
+type nonceTask struct {
+	difficulty *big.Int
+	done       chan struct{}
+	result     *nonceResult
+	err        error
+}
+
+// PoolProvider limits the concurrent computations of the inner provider to the workers,
+// the same request is computed once and the results are cached.
+// A nonce of a larger difficulty is valid for a smaller one, so the cached nonce is reused if its difficulty is enough.
+type PoolProvider struct {
+	inner Provider
+	slots chan struct{}
+
+	mu        sync.Mutex
+	closed    bool
+	pending   map[types.Hash]*nonceTask
+	cache     map[types.Hash]*list.Element
+	cacheList *list.List
+	cacheSize int
+
+	log log15.Logger
+}
+
+type cachedNonce struct {
+	dataHash types.Hash
+	result   *nonceResult
+}
+
+// NewPoolProvider returns a pool of workers computing by the inner provider, cacheSize is the number of the cached nonces
+func NewPoolProvider(inner Provider, workers int, cacheSize int) *PoolProvider {
+	if workers <= 0 {
+		workers = 1
+	}
+	if cacheSize <= 0 {
+		cacheSize = 1024
+	}
+	return &PoolProvider{
+		inner:     inner,
+		slots:     make(chan struct{}, workers),
+		pending:   make(map[types.Hash]*nonceTask),
+		cache:     make(map[types.Hash]*list.Element),
+		cacheList: list.New(),
+		cacheSize: cacheSize,
+		log:       log15.New("module", "pow_pool"),
+	}
+}
+
+func (p *PoolProvider) Name() string {
+	return fmt.Sprintf("pool(%s)", p.inner.Name())
+}
+
+func (p *PoolProvider) Nonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, errPoolClosed
+		}
+		if result := p.getCache(dataHash, difficulty); result != nil {
+			p.mu.Unlock()
+			return result.nonce, nil
+		}
+		task, ok := p.pending[dataHash]
+		if !ok {
+			task = p.startTask(difficulty, dataHash)
+		}
+		p.mu.Unlock()
+
+		<-task.done
+		if task.err != nil {
+			return nil, task.err
+		}
+		if enough(task.result.difficulty, difficulty) {
+			return task.result.nonce, nil
+		}
+		// the pending task is for a smaller difficulty, compute again
+	}
+}
+
+// Precompute computes the nonce in the background, the result is cached for the next Nonce
+func (p *PoolProvider) Precompute(difficulty *big.Int, dataHash types.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.getCache(dataHash, difficulty) != nil {
+		return
+	}
+	if _, ok := p.pending[dataHash]; ok {
+		return
+	}
+	p.startTask(difficulty, dataHash)
+}
+
+// Close fails the waiting requests, the running computations are not interrupted
+func (p *PoolProvider) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+}
+
+func (p *PoolProvider) startTask(difficulty *big.Int, dataHash types.Hash) *nonceTask {
+	if difficulty != nil {
+		difficulty = new(big.Int).Set(difficulty)
+	}
+	task := &nonceTask{difficulty: difficulty, done: make(chan struct{})}
+	p.pending[dataHash] = task
+
+	go func() {
+		p.slots <- struct{}{}
+		nonce, err := p.inner.Nonce(difficulty, dataHash)
+		<-p.slots
+
+		p.mu.Lock()
+		delete(p.pending, dataHash)
+		if err == nil {
+			task.result = &nonceResult{difficulty: difficulty, nonce: nonce}
+			p.putCache(dataHash, task.result)
+		} else {
+			task.err = err
+			p.log.Warn("compute pow nonce failed", "provider", p.inner.Name(), "dataHash", dataHash, "err", err)
+		}
+		p.mu.Unlock()
+		close(task.done)
+	}()
+	return task
+}
+
+func (p *PoolProvider) getCache(dataHash types.Hash, difficulty *big.Int) *nonceResult {
+	elem, ok := p.cache[dataHash]
+	if !ok {
+		return nil
+	}
+	result := elem.Value.(*cachedNonce).result
+	if !enough(result.difficulty, difficulty) {
+		return nil
+	}
+	p.cacheList.MoveToFront(elem)
+	return result
+}
+
+func (p *PoolProvider) putCache(dataHash types.Hash, result *nonceResult) {
+	if elem, ok := p.cache[dataHash]; ok {
+		if enough(elem.Value.(*cachedNonce).result.difficulty, result.difficulty) {
+			return
+		}
+		p.cacheList.Remove(elem)
+	}
+	p.cache[dataHash] = p.cacheList.PushFront(&cachedNonce{dataHash: dataHash, result: result})
+	for p.cacheList.Len() > p.cacheSize {
+		elem := p.cacheList.Back()
+		p.cacheList.Remove(elem)
+		delete(p.cache, elem.Value.(*cachedNonce).dataHash)
+	}
+}
+
+// enough returns whether the nonce of difficulty a is valid for difficulty b
+func enough(a, b *big.Int) bool {
+	if b == nil {
+		return a == nil
+	}
+	return a != nil && a.Cmp(b) >= 0
+}
@@ -0,0 +1,70 @@
+package pow_test
+
+import (
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto"
+	"github.com/vitelabs/go-vite/v2/pow"
+)
+
+type countingProvider struct {
+	calls int32
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Nonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	atomic.AddInt32(&p.calls, 1)
+	time.Sleep(10 * time.Millisecond)
+	return difficulty.Bytes(), nil
+}
+
+func TestPoolProvider(t *testing.T) {
+	inner := &countingProvider{}
+	p := pow.NewPoolProvider(inner, 2, 2)
+	data, _ := types.BytesToHash(crypto.Hash256([]byte{1}))
+
+	// the same request is computed once
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := p.Nonce(big.NewInt(100), data)
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(100).Bytes(), nonce)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+
+	// the nonce of a larger difficulty is reused
+	nonce, err := p.Nonce(big.NewInt(50), data)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100).Bytes(), nonce)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&inner.calls))
+
+	nonce, err = p.Nonce(big.NewInt(200), data)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(200).Bytes(), nonce)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&inner.calls))
+
+	// precomputed
+	next, _ := types.BytesToHash(crypto.Hash256([]byte{2}))
+	p.Precompute(big.NewInt(100), next)
+	nonce, err = p.Nonce(big.NewInt(100), next)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100).Bytes(), nonce)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&inner.calls))
+
+	p.Close()
+	_, err = p.Nonce(big.NewInt(100), data)
+	assert.Error(t, err)
+}
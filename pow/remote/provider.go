@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/pow"
+)
+
+// Provider computes the nonce by an external pow-service, eg: the gpu server
+type Provider struct {
+	url string
+}
+
+// NewProvider returns a provider requesting the pow-service at url
+func NewProvider(url string) *Provider {
+	return &Provider{url: url}
+}
+
+func (p *Provider) Name() string {
+	return "remote"
+}
+
+func (p *Provider) Nonce(difficulty *big.Int, dataHash types.Hash) ([]byte, error) {
+	if pow.VMTestParamEnabled {
+		return pow.GetPowNonce(nil, dataHash)
+	}
+	if difficulty == nil {
+		return nil, errors.New("difficulty can't be nil")
+	}
+
+	work, err := generateWork(p.url, dataHash.Bytes(), difficulty)
+	if err != nil {
+		return nil, err
+	}
+	nonceBig, ok := new(big.Int).SetString(*work, 16)
+	if !ok {
+		return nil, errors.New("wrong nonce str")
+	}
+	nonce := make([]byte, 8)
+	binary.LittleEndian.PutUint64(nonce, nonceBig.Uint64())
+
+	if !pow.CheckPowNonce(difficulty, nonce, dataHash.Bytes()) {
+		return nil, errors.New("check nonce failed")
+	}
+	return nonce, nil
+}
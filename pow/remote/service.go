@@ -42,6 +42,10 @@ func GenerateWork(dataHash []byte, difficulty *big.Int) (*string, error) {
 	if !Working() {
 		return nil, errors.New("not supported")
 	}
+	return generateWork(requestUrl, dataHash, difficulty)
+}
+
+func generateWork(url string, dataHash []byte, difficulty *big.Int) (*string, error) {
 	threshold := pow.DifficultyToTarget(difficulty)
 	wg := &workGenerate{
 		Threshold: threshold.Text(16),
@@ -52,7 +56,7 @@ func GenerateWork(dataHash []byte, difficulty *big.Int) (*string, error) {
 		return nil, err
 	}
 	workResult := &workGenerateResult{}
-	if err := httpRequest(requestUrl+ApiActionGenerate, bytesData, workResult); err != nil {
+	if err := httpRequest(url+ApiActionGenerate, bytesData, workResult); err != nil {
 		return nil, err
 	}
 
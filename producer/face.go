@@ -12,4 +12,5 @@ type Producer interface {
 	Stop() error
 	GetCoinBase() types.Address
 	SnapshotOnce() error
+	Producing() bool
 }
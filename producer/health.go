@@ -0,0 +1,75 @@
+package producer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+var unhealthySlotsMetric = monitor.GetOrRegisterCounterVec("vite_producer_unhealthy_slots_total",
+	"number of the snapshot slots skipped because the producer is unhealthy, partitioned by reason", "reason")
+
+// HealthConfig is the preconditions checked before the producer signs a snapshot block in its slot.
+// A node producing while it's out of sync forks the chain and forfeits the rewards, it's better to miss the slot.
+// The zero value of a field disables its check.
+type HealthConfig struct {
+	// MaxSyncLag is the max snapshot heights the node is behind the peer it syncs from
+	MaxSyncLag uint64
+	// MinPeers is the min number of the peers connected
+	MinPeers int
+	// CheckFlusher skips the slots while the flusher of the chain is stopped or recovering
+	CheckFlusher bool
+	// MaxClockSkew is the max drift of the local clock to the ntp servers, it's not checked before the drift is measured
+	MaxClockSkew time.Duration
+}
+
+// NetStatus is the status of the net the health of the producer depends on
+type NetStatus interface {
+	PeerCount() int
+	SyncPeerHeight() uint64
+}
+
+type healthChecker struct {
+	cfg HealthConfig
+	net NetStatus
+
+	height        func() uint64
+	flusherHealth func() bool
+	clockDrift    func() (time.Duration, bool)
+}
+
+// unhealthyError is the reason why the producer is unhealthy
+type unhealthyError struct {
+	reason string
+	detail string
+}
+
+func (e *unhealthyError) Error() string {
+	return e.reason + ": " + e.detail
+}
+
+func (h *healthChecker) check() error {
+	cfg := h.cfg
+	if cfg.MaxSyncLag > 0 {
+		height, peerHeight := h.height(), h.net.SyncPeerHeight()
+		if peerHeight > height && peerHeight-height > cfg.MaxSyncLag {
+			return &unhealthyError{"sync_lag", fmt.Sprintf("height %d is %d behind the sync peer, the max is %d",
+				height, peerHeight-height, cfg.MaxSyncLag)}
+		}
+	}
+	if cfg.MinPeers > 0 {
+		if count := h.net.PeerCount(); count < cfg.MinPeers {
+			return &unhealthyError{"peers", fmt.Sprintf("%d peers connected, the min is %d", count, cfg.MinPeers)}
+		}
+	}
+	if cfg.CheckFlusher && !h.flusherHealth() {
+		return &unhealthyError{"flusher", "the flusher is stopped or recovering"}
+	}
+	if cfg.MaxClockSkew > 0 {
+		if drift, ok := h.clockDrift(); ok && (drift > cfg.MaxClockSkew || drift < -cfg.MaxClockSkew) {
+			return &unhealthyError{"clock_skew", fmt.Sprintf("clock drift is %s, the max is %s", drift, cfg.MaxClockSkew)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package producer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockNetStatus struct {
+	peers      int
+	peerHeight uint64
+}
+
+func (n *mockNetStatus) PeerCount() int {
+	return n.peers
+}
+
+func (n *mockNetStatus) SyncPeerHeight() uint64 {
+	return n.peerHeight
+}
+
+func TestHealthChecker(t *testing.T) {
+	status := &mockNetStatus{peers: 10, peerHeight: 100}
+	flusherHealth := true
+	drift, measured := time.Duration(0), false
+	h := &healthChecker{
+		cfg: HealthConfig{
+			MaxSyncLag:   5,
+			MinPeers:     3,
+			CheckFlusher: true,
+			MaxClockSkew: time.Second,
+		},
+		net: status,
+		height: func() uint64 {
+			return 96
+		},
+		flusherHealth: func() bool {
+			return flusherHealth
+		},
+		clockDrift: func() (time.Duration, bool) {
+			return drift, measured
+		},
+	}
+	reason := func() string {
+		err := h.check()
+		if err == nil {
+			return ""
+		}
+		return err.(*unhealthyError).reason
+	}
+	assert.Equal(t, "", reason())
+
+	status.peerHeight = 102
+	assert.Equal(t, "sync_lag", reason())
+	// the peers are lower
+	status.peerHeight = 50
+	assert.Equal(t, "", reason())
+
+	status.peers = 2
+	assert.Equal(t, "peers", reason())
+	status.peers = 3
+
+	flusherHealth = false
+	assert.Equal(t, "flusher", reason())
+	flusherHealth = true
+
+	// the drift is not measured
+	drift = -2 * time.Second
+	assert.Equal(t, "", reason())
+	measured = true
+	assert.Equal(t, "clock_skew", reason())
+
+	// the zero config checks nothing
+	h.cfg = HealthConfig{}
+	status.peers = 0
+	flusherHealth = false
+	assert.Equal(t, "", reason())
+}
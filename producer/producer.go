@@ -15,6 +15,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/ledger/pool"
 	"github.com/vitelabs/go-vite/v2/ledger/verifier"
 	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/monitor"
 	"github.com/vitelabs/go-vite/v2/net"
 	"github.com/vitelabs/go-vite/v2/producer/producerevent"
 )
@@ -63,6 +64,12 @@ type producer struct {
 	accountFn  func(producerevent.AccountEvent)
 	syncState  net.SyncState
 	netSyncId  int
+
+	failover  *FailoverConfig
+	standby   *standby
+	heartbeat *heartbeatSender
+
+	health *healthChecker
 }
 
 // todo syncDone
@@ -106,6 +113,9 @@ func (self *producer) Start() error {
 	if self.coinbase == nil {
 		return errors.New("coinbase must not be nil")
 	}
+	if err := self.startFailover(); err != nil {
+		return err
+	}
 
 	snapshotId := self.coinbase.Address().Hex() + "_snapshot"
 	contractId := self.coinbase.Address().Hex() + "_contract"
@@ -113,13 +123,21 @@ func (self *producer) Start() error {
 	addr := self.coinbase.Address()
 	self.cs.Subscribe(types.SNAPSHOT_GID, snapshotId, &addr, func(e consensus.Event) {
 		mLog.Info("snapshot producer trigger.", "addr", self.coinbase.Address, "syncState", self.syncState, "e", e)
-		if self.syncState == net.SyncDone {
+		if e.Replayed {
+			// the slot is over, nothing to produce
+			return
+		}
+		if self.syncState == net.SyncDone && self.healthy(e) && self.canProduce(e) {
 			self.worker.produceSnapshot(e)
 		}
 	})
 	self.cs.Subscribe(types.DELEGATE_GID, contractId, &addr, func(e consensus.Event) {
 		mLog.Info("contract producer trigger.", "addr", self.coinbase.Address, "syncState", self.syncState, "e", e)
-		if self.syncState == net.SyncDone {
+		if e.Replayed {
+			// the slot is over, nothing to produce
+			return
+		}
+		if self.syncState == net.SyncDone && self.canProduce(e) {
 			self.producerContract(e)
 		}
 	})
@@ -164,6 +182,8 @@ func (self *producer) Stop() error {
 	self.subscriber.UnsubscribeSyncStatus(self.netSyncId)
 	self.netSyncId = 0
 
+	self.stopFailover()
+
 	err := self.worker.Stop()
 	if err != nil {
 		return err
@@ -202,6 +222,109 @@ func (self *producer) producerContract(e consensus.Event) {
 	}
 }
 
+// SetFailover makes the producer work as the primary or the hot-standby of the coinbase, it must be called before Start
+func (self *producer) SetFailover(cfg *FailoverConfig) {
+	self.failover = cfg
+}
+
+func (self *producer) startFailover() error {
+	cfg := self.failover
+	if cfg == nil {
+		return nil
+	}
+	if err := cfg.check(); err != nil {
+		return err
+	}
+	var err error
+	if cfg.Standby {
+		self.standby, err = newStandby(self.coinbase.Address(), cfg.HeartbeatListen, cfg.MissedSlots)
+	} else if len(cfg.HeartbeatAddrs) > 0 {
+		self.heartbeat, err = newHeartbeatSender(self.coinbase, cfg.HeartbeatAddrs)
+	}
+	return err
+}
+
+func (self *producer) stopFailover() {
+	if self.standby != nil {
+		self.standby.close()
+		self.standby = nil
+	}
+	if self.heartbeat != nil {
+		self.heartbeat.close()
+		self.heartbeat = nil
+	}
+}
+
+// SetHealth checks the health of the node before signing a snapshot block, it must be called before Start
+func (self *producer) SetHealth(cfg *HealthConfig, status NetStatus) {
+	chain := self.tools.chain
+	self.health = &healthChecker{
+		cfg: *cfg,
+		net: status,
+		height: func() uint64 {
+			return chain.GetLatestSnapshotBlock().Height
+		},
+		flusherHealth: func() bool {
+			flusher := chain.Flusher()
+			return flusher.Running() && !flusher.Recovering()
+		},
+		clockDrift: monitor.ClockDrift,
+	}
+}
+
+// healthy reports whether the node is healthy enough to sign the slot of the event, the slot is missed if not
+func (self *producer) healthy(e consensus.Event) bool {
+	if self.health == nil {
+		return true
+	}
+	if err := self.health.check(); err != nil {
+		reason := "unknown"
+		if uErr, ok := err.(*unhealthyError); ok {
+			reason = uErr.reason
+		}
+		unhealthySlotsMetric.With(reason).Add(1)
+		mLog.Warn("skip the slot, the producer is unhealthy.", "addr", e.Address, "stime", e.Stime, "err", err)
+		return false
+	}
+	return true
+}
+
+// canProduce reports whether the node signs the slot of the event, the standby signs only after taking over,
+// and the slot lock prevents the primary and the standby from signing the same slot.
+func (self *producer) canProduce(e consensus.Event) bool {
+	if self.standby != nil {
+		if e.Gid == types.SNAPSHOT_GID {
+			if !self.standby.onSlot(e) {
+				return false
+			}
+		} else if !self.standby.isActive() {
+			return false
+		}
+	}
+	if self.failover != nil && self.failover.SlotLock != nil {
+		ok, err := self.failover.SlotLock.TryLock(slotLockKey(e))
+		if err != nil {
+			mLog.Error("lock slot fail.", "addr", e.Address, "gid", e.Gid, "stime", e.Stime, "err", err)
+			return false
+		}
+		if !ok {
+			mLog.Warn("slot has been signed by another node.", "addr", e.Address, "gid", e.Gid, "stime", e.Stime)
+			return false
+		}
+	}
+	return true
+}
+
+// Producing returns true if the producer is started and signs the slots of the coinbase,
+// the standby which has not taken over is not producing
+func (self *producer) Producing() bool {
+	// 4: started
+	if self.GetStatus() != 4 {
+		return false
+	}
+	return self.standby == nil || self.standby.isActive()
+}
+
 func (self *producer) SetAccountEventFunc(accountFn func(producerevent.AccountEvent)) {
 	self.accountFn = accountFn
 }
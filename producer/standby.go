@@ -0,0 +1,343 @@
+package producer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var sLog = log15.New("module", "producer/standby")
+
+// heartbeat settings of the primary producer
+const (
+	heartbeatInterval = time.Second
+	heartbeatTimeout  = 3 * heartbeatInterval
+
+	heartbeatMsgSize = types.AddressSize + 8
+	heartbeatSize    = heartbeatMsgSize + ed25519.PublicKeySize + ed25519.SignatureSize
+)
+
+// the lock files of the slots older than slotLockExpiry are removed every slotLockCleanInterval
+const (
+	slotLockExpiry        = time.Hour
+	slotLockCleanInterval = 10 * time.Minute
+)
+
+// DefaultStandbyMissedSlots is the number of missed slots of the primary before the standby takes over
+const DefaultStandbyMissedSlots = 2
+
+// SlotLock is the lock service shared by the primary and standby producers of a coinbase,
+// a slot is signed only by the node which locks it first, so the coinbase never signs a slot twice.
+type SlotLock interface {
+	// TryLock locks the key, it returns false if the key has been locked.
+	TryLock(key string) (bool, error)
+}
+
+// FailoverConfig makes a pair of producers with the same coinbase work as primary and hot-standby.
+//
+// The primary sends heartbeats signed by the coinbase to HeartbeatAddrs. The standby listens on HeartbeatListen,
+// tracks the chain as a normal node and starts producing after MissedSlots slots of the coinbase without valid
+// heartbeats, it stands by again once the heartbeats are back.
+type FailoverConfig struct {
+	Standby         bool
+	HeartbeatAddrs  []string
+	HeartbeatListen string
+	MissedSlots     int
+	// SlotLock is required, both nodes must use the same lock service. A lost heartbeat makes both nodes
+	// produce, the lock is the only guard against signing a slot twice.
+	SlotLock SlotLock
+}
+
+func (cfg *FailoverConfig) check() error {
+	if (cfg.Standby || len(cfg.HeartbeatAddrs) > 0) && cfg.SlotLock == nil {
+		return errors.New("slot lock must be set for the producer failover")
+	}
+	if cfg.Standby && cfg.HeartbeatListen == "" {
+		return errors.New("heartbeat listen address must be set for the standby producer")
+	}
+	return nil
+}
+
+// standby decides when the standby producer takes over from the primary
+type standby struct {
+	coinbase    types.Address
+	missedSlots int
+
+	conn     net.PacketConn
+	lastSeen int64 // unix nano of the last heartbeat of the primary
+
+	mu     sync.Mutex
+	missed int
+	active bool
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newStandby(coinbase types.Address, listen string, missedSlots int) (*standby, error) {
+	if missedSlots <= 0 {
+		missedSlots = DefaultStandbyMissedSlots
+	}
+	conn, err := net.ListenPacket("udp", listen)
+	if err != nil {
+		return nil, err
+	}
+	s := &standby{
+		coinbase:    coinbase,
+		missedSlots: missedSlots,
+		conn:        conn,
+		lastSeen:    time.Now().UnixNano(),
+		closed:      make(chan struct{}),
+	}
+	s.wg.Add(1)
+	common.Go(s.loopReceive)
+	return s, nil
+}
+
+func (s *standby) loopReceive() {
+	defer s.wg.Done()
+	buf := make([]byte, heartbeatSize)
+	for {
+		n, from, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-s.closed:
+				return
+			default:
+				sLog.Warn("read heartbeat fail", "err", err)
+				continue
+			}
+		}
+		addr, sent, ok := decodeHeartbeat(buf[:n])
+		if !ok || addr != s.coinbase {
+			sLog.Warn("invalid heartbeat", "from", from)
+			continue
+		}
+		s.heartbeat(sent)
+	}
+}
+
+func (s *standby) heartbeat(sent time.Time) {
+	if sent.After(time.Now().Add(heartbeatTimeout)) {
+		return
+	}
+	for {
+		last := atomic.LoadInt64(&s.lastSeen)
+		if sent.UnixNano() <= last || atomic.CompareAndSwapInt64(&s.lastSeen, last, sent.UnixNano()) {
+			return
+		}
+	}
+}
+
+// primaryAlive reports whether the primary has sent heartbeats recently
+func (s *standby) primaryAlive(now time.Time) bool {
+	return now.Sub(time.Unix(0, atomic.LoadInt64(&s.lastSeen))) < heartbeatTimeout
+}
+
+// onSlot is called at each snapshot slot of the coinbase, it reports whether the standby produces the slot
+func (s *standby) onSlot(e consensus.Event) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.primaryAlive(time.Now()) {
+		if s.active {
+			sLog.Info("primary is back, stand by", "addr", s.coinbase)
+		}
+		s.missed = 0
+		s.active = false
+		return false
+	}
+	if s.active {
+		return true
+	}
+	s.missed++
+	sLog.Warn("primary missed slot", "addr", s.coinbase, "stime", e.Stime, "missed", s.missed)
+	if s.missed < s.missedSlots {
+		return false
+	}
+	sLog.Warn("primary is down, take over", "addr", s.coinbase, "missed", s.missed)
+	s.active = true
+	return true
+}
+
+// isActive reports whether the standby is producing for the primary
+func (s *standby) isActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+func (s *standby) close() error {
+	close(s.closed)
+	err := s.conn.Close()
+	s.wg.Wait()
+	return err
+}
+
+// heartbeatSender sends the heartbeats of the primary to its standby producers
+type heartbeatSender struct {
+	coinbase interfaces.Account
+	addrs    []*net.UDPAddr
+	conn     *net.UDPConn
+
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newHeartbeatSender(coinbase interfaces.Account, addrs []string) (*heartbeatSender, error) {
+	h := &heartbeatSender{coinbase: coinbase, closed: make(chan struct{})}
+	for _, addr := range addrs {
+		udpAddr, err := net.ResolveUDPAddr("udp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid heartbeat address %s: %v", addr, err)
+		}
+		h.addrs = append(h.addrs, udpAddr)
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	h.conn = conn
+	h.wg.Add(1)
+	common.Go(h.loopSend)
+	return h, nil
+}
+
+func (h *heartbeatSender) loopSend() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		h.send()
+		select {
+		case <-h.closed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *heartbeatSender) send() {
+	msg, err := encodeHeartbeat(h.coinbase, time.Now())
+	if err != nil {
+		sLog.Warn("sign heartbeat fail", "err", err)
+		return
+	}
+	for _, addr := range h.addrs {
+		if _, err := h.conn.WriteToUDP(msg, addr); err != nil {
+			sLog.Warn("send heartbeat fail", "addr", addr, "err", err)
+		}
+	}
+}
+
+func (h *heartbeatSender) close() error {
+	close(h.closed)
+	h.wg.Wait()
+	return h.conn.Close()
+}
+
+// heartbeat: coinbase | unix nano uint64 | public key | signature of the coinbase and the time
+func encodeHeartbeat(coinbase interfaces.Account, t time.Time) ([]byte, error) {
+	buf := make([]byte, heartbeatMsgSize, heartbeatSize)
+	copy(buf, coinbase.Address().Bytes())
+	binary.BigEndian.PutUint64(buf[types.AddressSize:], uint64(t.UnixNano()))
+	sig, pub, err := coinbase.Sign(buf)
+	if err != nil {
+		return nil, err
+	}
+	if len(pub) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return nil, errors.New("invalid signature of the coinbase")
+	}
+	buf = append(buf, pub...)
+	return append(buf, sig...), nil
+}
+
+// decodeHeartbeat returns the coinbase and the time of the heartbeat, it's false if the heartbeat
+// isn't signed by the coinbase
+func decodeHeartbeat(buf []byte) (types.Address, time.Time, bool) {
+	if len(buf) != heartbeatSize {
+		return types.Address{}, time.Time{}, false
+	}
+	msg := buf[:heartbeatMsgSize]
+	pub := ed25519.PublicKey(buf[heartbeatMsgSize : heartbeatMsgSize+ed25519.PublicKeySize])
+	sig := buf[heartbeatMsgSize+ed25519.PublicKeySize:]
+
+	addr, err := types.BytesToAddress(msg[:types.AddressSize])
+	if err != nil || types.PubkeyToAddress(pub) != addr || !ed25519.Verify(pub, msg, sig) {
+		return types.Address{}, time.Time{}, false
+	}
+	return addr, time.Unix(0, int64(binary.BigEndian.Uint64(msg[types.AddressSize:]))), true
+}
+
+// slotLockKey is the key of the slot of the event
+func slotLockKey(e consensus.Event) string {
+	return fmt.Sprintf("%s_%s_%d", e.Address.Hex(), e.Gid.Hex(), e.Stime.Unix())
+}
+
+// fileSlotLock locks slots by creating files exclusively in a directory shared by the nodes, e.g. on NFS.
+// The files of the slots long past are removed, the producers never sign them.
+type fileSlotLock struct {
+	dir string
+
+	mu        sync.Mutex
+	lastClean time.Time
+}
+
+// NewFileSlotLock creates a SlotLock of the files in dir
+func NewFileSlotLock(dir string) (SlotLock, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &fileSlotLock{dir: dir}, nil
+}
+
+func (l *fileSlotLock) TryLock(key string) (bool, error) {
+	l.cleanExpired(time.Now())
+
+	file, err := os.OpenFile(filepath.Join(l.dir, key), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, file.Close()
+}
+
+// cleanExpired removes the lock files older than slotLockExpiry, at most once every slotLockCleanInterval
+func (l *fileSlotLock) cleanExpired(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastClean) < slotLockCleanInterval {
+		return
+	}
+	l.lastClean = now
+
+	files, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		sLog.Warn("read slot lock dir fail", "dir", l.dir, "err", err)
+		return
+	}
+	for _, f := range files {
+		if f.IsDir() || now.Sub(f.ModTime()) < slotLockExpiry {
+			continue
+		}
+		if err := os.Remove(filepath.Join(l.dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			sLog.Warn("remove slot lock fail", "file", f.Name(), "err", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package producer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+func TestStandby(t *testing.T) {
+	account, err := wallet.RandomAccount()
+	assert.NoError(t, err)
+	coinbase := account.Address()
+	s, err := newStandby(coinbase, "127.0.0.1:0", 2)
+	assert.NoError(t, err)
+	defer s.close()
+
+	h, err := newHeartbeatSender(account, []string{s.conn.LocalAddr().String()})
+	assert.NoError(t, err)
+
+	e := consensus.Event{Gid: types.SNAPSHOT_GID, Address: coinbase, Stime: time.Now()}
+	assert.False(t, s.onSlot(e))
+
+	// the primary goes down
+	assert.NoError(t, h.close())
+	time.Sleep(50 * time.Millisecond)
+	atomic.StoreInt64(&s.lastSeen, time.Now().Add(-heartbeatTimeout).UnixNano())
+	assert.False(t, s.onSlot(e))
+	assert.False(t, s.isActive())
+	assert.True(t, s.onSlot(e))
+	assert.True(t, s.isActive())
+	assert.True(t, s.onSlot(e))
+
+	// the primary is back
+	h, err = newHeartbeatSender(account, []string{s.conn.LocalAddr().String()})
+	assert.NoError(t, err)
+	defer h.close()
+	for i := 0; i < 50 && !s.primaryAlive(time.Now()); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, s.onSlot(e))
+	assert.False(t, s.isActive())
+}
+
+func TestHeartbeat(t *testing.T) {
+	account, err := wallet.RandomAccount()
+	assert.NoError(t, err)
+	now := time.Now()
+
+	msg, err := encodeHeartbeat(account, now)
+	assert.NoError(t, err)
+	addr, sent, ok := decodeHeartbeat(msg)
+	assert.True(t, ok)
+	assert.Equal(t, account.Address(), addr)
+	assert.Equal(t, now.UnixNano(), sent.UnixNano())
+
+	// the time is changed
+	forged := append([]byte{}, msg...)
+	forged[heartbeatMsgSize-1]++
+	_, _, ok = decodeHeartbeat(forged)
+	assert.False(t, ok)
+
+	// signed by another key for the coinbase
+	other, err := wallet.RandomAccount()
+	assert.NoError(t, err)
+	otherMsg, err := encodeHeartbeat(other, now)
+	assert.NoError(t, err)
+	copy(otherMsg, account.Address().Bytes())
+	_, _, ok = decodeHeartbeat(otherMsg)
+	assert.False(t, ok)
+
+	_, _, ok = decodeHeartbeat(msg[:heartbeatMsgSize])
+	assert.False(t, ok)
+}
+
+func TestFailoverConfig_check(t *testing.T) {
+	assert.Error(t, (&FailoverConfig{Standby: true, HeartbeatListen: "127.0.0.1:0"}).check())
+	assert.Error(t, (&FailoverConfig{HeartbeatAddrs: []string{"127.0.0.1:1"}}).check())
+	assert.Error(t, (&FailoverConfig{Standby: true, SlotLock: &fileSlotLock{}}).check())
+	assert.NoError(t, (&FailoverConfig{Standby: true, HeartbeatListen: "127.0.0.1:0", SlotLock: &fileSlotLock{}}).check())
+	assert.NoError(t, (&FailoverConfig{HeartbeatAddrs: []string{"127.0.0.1:1"}, SlotLock: &fileSlotLock{}}).check())
+}
+
+func TestFileSlotLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slot-lock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lock, err := NewFileSlotLock(dir)
+	assert.NoError(t, err)
+	e := consensus.Event{Gid: types.SNAPSHOT_GID, Address: types.AddressGovernance, Stime: time.Now()}
+
+	ok, err := lock.TryLock(slotLockKey(e))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = lock.TryLock(slotLockKey(e))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	e.Stime = e.Stime.Add(time.Second)
+	ok, err = lock.TryLock(slotLockKey(e))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// the lock of a slot long past is removed
+	past := slotLockKey(consensus.Event{Gid: types.SNAPSHOT_GID, Address: types.AddressGovernance, Stime: time.Now().Add(-2 * slotLockExpiry)})
+	ok, err = lock.TryLock(past)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	old := time.Now().Add(-slotLockExpiry - time.Minute)
+	assert.NoError(t, os.Chtimes(filepath.Join(dir, past), old, old))
+
+	fileLock := lock.(*fileSlotLock)
+	fileLock.cleanExpired(time.Now())
+	_, err = os.Stat(filepath.Join(dir, past))
+	assert.NoError(t, err, "cleaned before the interval")
+	fileLock.cleanExpired(time.Now().Add(slotLockCleanInterval))
+	_, err = os.Stat(filepath.Join(dir, past))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, slotLockKey(e)))
+	assert.NoError(t, err)
+}
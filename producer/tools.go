@@ -58,13 +58,9 @@ func (self *tools) generateSnapshot(e *consensus.Event, coinbase interfaces.Acco
 
 	block.Hash = block.ComputeHash()
 
-	signedData, pubkey, err := coinbase.Sign(block.Hash.Bytes())
-
-	if err != nil {
+	if err := interfaces.SignSnapshotBlock(coinbase, block); err != nil {
 		return nil, err
 	}
-	block.Signature = signedData
-	block.PublicKey = pubkey
 	return block, nil
 }
 func (self *tools) insertSnapshot(block *ledger.SnapshotBlock) error {
@@ -261,6 +261,21 @@ func (c *Client) SupportedModules() (map[string]string, error) {
 	return result, err
 }
 
+// DescribeAPI calls the rpc_describe method, retrieving the methods and param schemas
+// of the namespace, or of all namespaces if namespace is empty.
+func (c *Client) DescribeAPI(namespace string) (*APIDescription, error) {
+	var result *APIDescription
+	ctx, cancel := context.WithTimeout(context.Background(), subscribeTimeout)
+	defer cancel()
+	var err error
+	if namespace == "" {
+		err = c.CallContext(ctx, &result, "rpc_describe")
+	} else {
+		err = c.CallContext(ctx, &result, "rpc_describe", namespace)
+	}
+	return result, err
+}
+
 // Close closes the client, aborting any in-flight requests.
 func (c *Client) Close() {
 	if c.isHTTP {
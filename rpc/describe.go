@@ -0,0 +1,231 @@
+package rpc
+
+import (
+	"encoding"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// APIDescription describes the methods and subscriptions of all registered services,
+// named struct types are described once in Definitions and referred by Ref.
+type APIDescription struct {
+	Modules     []*ModuleDescription   `json:"modules"`
+	Definitions map[string]*TypeSchema `json:"definitions"`
+}
+
+type ModuleDescription struct {
+	Namespace     string               `json:"namespace"`
+	Methods       []*MethodDescription `json:"methods"`
+	Subscriptions []*MethodDescription `json:"subscriptions,omitempty"`
+}
+
+// MethodDescription describes a method by the Go handler signature, params are positional.
+// For subscriptions Name is the first param of namespace_subscribe and Result is the subscription id.
+type MethodDescription struct {
+	Name   string         `json:"name"`
+	Params []*ParamSchema `json:"params"`
+	Result *TypeSchema    `json:"result"`
+}
+
+// ParamSchema is a positional param, optional params may be omitted or null
+type ParamSchema struct {
+	Optional bool        `json:"optional,omitempty"`
+	Schema   *TypeSchema `json:"schema"`
+}
+
+// TypeSchema describes the JSON encoding of a Go type.
+// Type is one of string, integer, number, boolean, array, object and any.
+type TypeSchema struct {
+	Type       string            `json:"type"`
+	GoType     string            `json:"goType,omitempty"`
+	Nullable   bool              `json:"nullable,omitempty"`
+	Ref        string            `json:"ref,omitempty"`        // the named struct in definitions
+	Items      *TypeSchema       `json:"items,omitempty"`      // elements of arrays and values of maps
+	Properties []*PropertySchema `json:"properties,omitempty"` // fields of anonymous structs and definitions
+}
+
+type PropertySchema struct {
+	Name      string      `json:"name"`
+	OmitEmpty bool        `json:"omitEmpty,omitempty"`
+	Schema    *TypeSchema `json:"schema"`
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	bigIntType        = reflect.TypeOf(big.Int{})
+)
+
+type schemaBuilder struct {
+	definitions map[string]*TypeSchema
+}
+
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+func (b *schemaBuilder) schema(t reflect.Type) *TypeSchema {
+	if t == nil {
+		return &TypeSchema{Type: "any"}
+	}
+	if t.Kind() == reflect.Ptr {
+		s := b.schema(t.Elem())
+		s.Nullable = true
+		return s
+	}
+
+	goType := t.String()
+	switch {
+	case t == bigIntType:
+		return &TypeSchema{Type: "integer", GoType: goType}
+	case implements(t, textMarshalerType):
+		return &TypeSchema{Type: "string", GoType: goType}
+	case implements(t, jsonMarshalerType):
+		return &TypeSchema{Type: "any", GoType: goType}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &TypeSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &TypeSchema{Type: "integer", GoType: goType}
+	case reflect.Float32, reflect.Float64:
+		return &TypeSchema{Type: "number", GoType: goType}
+	case reflect.String:
+		return &TypeSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// []byte is encoded as base64
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &TypeSchema{Type: "string", GoType: goType}
+		}
+		return &TypeSchema{Type: "array", Items: b.schema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &TypeSchema{Type: "object", GoType: goType, Items: b.schema(t.Elem()), Nullable: true}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return &TypeSchema{Type: "object", Properties: b.properties(t)}
+		}
+		if _, ok := b.definitions[goType]; !ok {
+			// set first to stop the recursion of self referenced types
+			def := &TypeSchema{Type: "object", GoType: goType}
+			b.definitions[goType] = def
+			def.Properties = b.properties(t)
+		}
+		return &TypeSchema{Type: "object", Ref: goType}
+	}
+	return &TypeSchema{Type: "any", GoType: goType}
+}
+
+// properties follows the field rules of encoding/json, except for conflicting names
+func (b *schemaBuilder) properties(t reflect.Type) []*PropertySchema {
+	var props []*PropertySchema
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			if !implements(ft, jsonMarshalerType) && !implements(ft, textMarshalerType) {
+				props = append(props, b.properties(ft)...)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		s := b.schema(f.Type)
+		if strings.Contains(opts, "string") {
+			s = &TypeSchema{Type: "string", GoType: f.Type.String(), Nullable: s.Nullable}
+		}
+		props = append(props, &PropertySchema{
+			Name:      name,
+			OmitEmpty: strings.Contains(opts, "omitempty"),
+			Schema:    s,
+		})
+	}
+	return props
+}
+
+func (b *schemaBuilder) method(name string, cb *callback) *MethodDescription {
+	m := &MethodDescription{Name: name, Params: make([]*ParamSchema, len(cb.argTypes))}
+	optional := true
+	for i := len(cb.argTypes) - 1; i >= 0; i-- {
+		argType := cb.argTypes[i]
+		// only trailing pointer args can be omitted
+		optional = optional && argType.Kind() == reflect.Ptr
+		m.Params[i] = &ParamSchema{Optional: optional, Schema: b.schema(argType)}
+	}
+
+	if cb.isSubscribe {
+		m.Result = &TypeSchema{Type: "string", GoType: "rpc.ID"}
+		return m
+	}
+	mtype := cb.method.Type
+	for i := 0; i < mtype.NumOut(); i++ {
+		if i != cb.errPos {
+			m.Result = b.schema(mtype.Out(i))
+			break
+		}
+	}
+	return m
+}
+
+func describeCallbacks(b *schemaBuilder, cbs map[string]*callback) []*MethodDescription {
+	names := make([]string, 0, len(cbs))
+	for name := range cbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := make([]*MethodDescription, 0, len(names))
+	for _, name := range names {
+		list = append(list, b.method(name, cbs[name]))
+	}
+	return list
+}
+
+// Describe returns the description of the registered services, of all namespaces if namespace is nil
+func (s *RPCService) Describe(namespace *string) *APIDescription {
+	b := &schemaBuilder{definitions: make(map[string]*TypeSchema)}
+	desc := &APIDescription{Definitions: b.definitions}
+
+	names := make([]string, 0, len(s.server.services))
+	for name := range s.server.services {
+		if namespace == nil || *namespace == name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		svc := s.server.services[name]
+		module := &ModuleDescription{
+			Namespace:     name,
+			Methods:       describeCallbacks(b, svc.callbacks),
+			Subscriptions: describeCallbacks(b, svc.subscriptions),
+		}
+		for _, m := range module.Methods {
+			m.Name = name + serviceMethodSeparator + m.Name
+		}
+		desc.Modules = append(desc.Modules, module)
+	}
+	return desc
+}
@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"testing"
+)
+
+type DescribeNode struct {
+	Name     string            `json:"name"`
+	Value    uint64            `json:"value,string"`
+	Children []*DescribeNode   `json:"children,omitempty"`
+	Extra    map[string][]byte `json:"-"`
+}
+
+type DescribeService struct{}
+
+func (s *DescribeService) Node(id uint64, parent *string) (*DescribeNode, error) {
+	return nil, nil
+}
+
+func TestRPCService_Describe(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterName("tree", new(DescribeService)); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := "tree"
+	desc := (&RPCService{server}).Describe(&namespace)
+	if len(desc.Modules) != 1 || len(desc.Modules[0].Methods) != 1 {
+		t.Fatalf("unexpected modules %v", desc.Modules)
+	}
+	m := desc.Modules[0].Methods[0]
+	if m.Name != "tree_node" || len(m.Params) != 2 {
+		t.Fatalf("unexpected method %s %d", m.Name, len(m.Params))
+	}
+	if m.Params[0].Optional || m.Params[0].Schema.Type != "integer" || !m.Params[1].Optional {
+		t.Fatal("unexpected params")
+	}
+	if !m.Result.Nullable || m.Result.Ref != "rpc.DescribeNode" {
+		t.Fatalf("unexpected result %+v", m.Result)
+	}
+	def := desc.Definitions[m.Result.Ref]
+	if def == nil || len(def.Properties) != 3 {
+		t.Fatalf("unexpected definition %+v", def)
+	}
+	if p := def.Properties[1]; p.Name != "value" || p.Schema.Type != "string" {
+		t.Fatalf("unexpected property %+v", p)
+	}
+	if p := def.Properties[2]; !p.OmitEmpty || p.Schema.Items.Ref != m.Result.Ref {
+		t.Fatalf("unexpected property %+v", p)
+	}
+
+	desc = (&RPCService{server}).Describe(nil)
+	var found bool
+	for _, module := range desc.Modules {
+		if module.Namespace == "test" {
+			found = len(module.Subscriptions) == 1 && module.Subscriptions[0].Name == "subscription"
+		}
+	}
+	if !found {
+		t.Fatal("subscriptions of test are not described")
+	}
+}
@@ -39,6 +39,13 @@ func (e *callbackError) ErrorCode() int { return -32002 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// request is rejected by the limiter
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
 // received message isn't a valid request
 type invalidRequestError struct {
 	message string
@@ -222,8 +222,42 @@ func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *S
 	}
 }
 
+// RegisterHTTPHandler mounts the handler at the path, the requests to the path are not handled as JSON-RPC.
+func (srv *Server) RegisterHTTPHandler(path string, handler http.Handler) {
+	srv.httpHandlersMu.Lock()
+	defer srv.httpHandlersMu.Unlock()
+	if srv.httpHandlers == nil {
+		srv.httpHandlers = make(map[string]http.Handler)
+	}
+	srv.httpHandlers[path] = handler
+}
+
+// RegisterLimitedHTTPHandler is RegisterHTTPHandler with the requests limited by the limiter of the server,
+// each request is checked as a call of method, so the api keys can allow it like the JSON-RPC methods.
+func (srv *Server) RegisterLimitedHTTPHandler(path string, method string, handler http.Handler) {
+	srv.RegisterHTTPHandler(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if l, _ := srv.limiter.Load().(*Limiter); l != nil {
+			release, err := l.acquire(withRequestInfo(r.Context(), r), method)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			defer release()
+		}
+		handler.ServeHTTP(w, r)
+	}))
+}
+
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.httpHandlersMu.RLock()
+	handler, ok := srv.httpHandlers[r.URL.Path]
+	srv.httpHandlersMu.RUnlock()
+	if ok {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
 	// Permit dumb empty requests for remote health-checks (AWS)
 	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
 		if isHealthCheckRouter(r.URL) {
@@ -248,7 +282,7 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// untilEOF and writes the response to w and order the server to process chain
 	// single request.
 	ctx := r.Context()
-	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	ctx = withRequestInfo(ctx, r)
 	ctx = context.WithValue(ctx, "scheme", r.Proto)
 	ctx = context.WithValue(ctx, "local", r.Host)
 
@@ -21,6 +21,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
 )
 
 func TestHTTPErrorResponseWithDelete(t *testing.T) {
@@ -52,3 +54,39 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestServer_RegisterLimitedHTTPHandler(t *testing.T) {
+	srv := NewServer()
+	defer srv.Stop()
+	srv.RegisterLimitedHTTPHandler("/graphql", "graphql_query", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func(key string) int {
+		request := httptest.NewRequest(http.MethodPost, "http://url.com/graphql", strings.NewReader("{}"))
+		if key != "" {
+			request.Header.Set(apiKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, request)
+		return w.Code
+	}
+
+	// no limiter
+	if code := serve(""); code != http.StatusOK {
+		t.Fatalf("response code should be %d not %d", http.StatusOK, code)
+	}
+
+	srv.SetLimiter(NewLimiter(config.RPCLimit{
+		RequireAPIKey: true,
+		APIKeys: map[string]*config.RPCAPIKey{
+			"k1": {Methods: []string{"graphql_query"}},
+			"k2": {Methods: []string{"ledger_*"}},
+		},
+	}))
+	for key, expected := range map[string]int{"": http.StatusTooManyRequests, "k1": http.StatusOK, "k2": http.StatusTooManyRequests} {
+		if code := serve(key); code != expected {
+			t.Fatalf("key %q: response code should be %d not %d", key, expected, code)
+		}
+	}
+}
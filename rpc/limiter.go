@@ -0,0 +1,215 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/monitor"
+)
+
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyCtxKey is the context key of the api key of the request
+type apiKeyCtxKey struct{}
+
+// allowMethod checks the method against the allowlist of the api key
+func allowMethod(key *config.RPCAPIKey, method string) bool {
+	if len(key.Methods) == 0 {
+		return true
+	}
+	for _, m := range key.Methods {
+		if m == method || (strings.HasSuffix(m, "_*") && strings.HasPrefix(method, m[:len(m)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// bucket is a token bucket refilled by rate per second
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func (b *bucket) take(now time.Time, rate float64, burst int) bool {
+	if burst <= 0 {
+		burst = int(rate)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	if b.last.IsZero() {
+		b.tokens = float64(burst)
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * rate
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// idle buckets are full again, it's safe to drop them
+const bucketIdleTimeout = 10 * time.Minute
+
+var rejectedRequests = monitor.GetOrRegisterCounterVec("rpc_rejected_requests_total", "RPC requests rejected by the limiter", "reason")
+
+// Limiter limits the requests of RPC servers by ip, api key and concurrency
+type Limiter struct {
+	cfg config.RPCLimit
+	sem chan struct{}
+
+	mu        sync.Mutex
+	ipBuckets map[string]*bucket
+	keyBucket map[string]*bucket
+	lastClean time.Time
+
+	now func() time.Time
+}
+
+func NewLimiter(cfg config.RPCLimit) *Limiter {
+	l := &Limiter{
+		cfg:       cfg,
+		ipBuckets: make(map[string]*bucket),
+		keyBucket: make(map[string]*bucket),
+		now:       time.Now,
+	}
+	if cfg.MaxConcurrent > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrent)
+	}
+	return l
+}
+
+// Update replaces the config of the limiter, the state of the rate limits is kept
+func (l *Limiter) Update(cfg config.RPCLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cfg.MaxConcurrent != l.cfg.MaxConcurrent {
+		// the requests in flight are released to the old semaphore
+		l.sem = nil
+		if cfg.MaxConcurrent > 0 {
+			l.sem = make(chan struct{}, cfg.MaxConcurrent)
+		}
+	}
+	for key := range l.keyBucket {
+		if _, ok := cfg.APIKeys[key]; !ok {
+			delete(l.keyBucket, key)
+		}
+	}
+	l.cfg = cfg
+}
+
+// acquire checks the request of method, release must be called if no error is returned
+func (l *Limiter) acquire(ctx context.Context, method string) (release func(), err Error) {
+	l.mu.Lock()
+	cfg, sem := l.cfg, l.sem
+	l.mu.Unlock()
+
+	key, _ := ctx.Value(apiKeyCtxKey{}).(string)
+	keyCfg := cfg.APIKeys[key]
+	if key != "" && keyCfg == nil {
+		return nil, l.reject("invalid_key", "invalid api key")
+	}
+	if keyCfg == nil && cfg.RequireAPIKey {
+		return nil, l.reject("missing_key", "api key is required")
+	}
+	if keyCfg != nil && !allowMethod(keyCfg, method) {
+		return nil, l.reject("method", "method "+method+" is not allowed for the api key")
+	}
+
+	if keyCfg != nil {
+		if keyCfg.Rate > 0 && !l.take(l.keyBucket, key, keyCfg.Rate, keyCfg.Burst) {
+			return nil, l.reject("key_rate", "rate limit of the api key exceeded")
+		}
+	} else if cfg.IPRate > 0 {
+		if ip := remoteIP(ctx); ip != "" && !l.take(l.ipBuckets, ip, cfg.IPRate, cfg.IPBurst) {
+			return nil, l.reject("ip_rate", "rate limit exceeded")
+		}
+	}
+
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	default:
+		return nil, l.reject("concurrency", "too many concurrent requests")
+	}
+}
+
+// Acquire checks the request of method from the remote address with the api key for the servers other than JSON-RPC,
+// release must be called if no error is returned
+func (l *Limiter) Acquire(remote string, apiKey string, method string) (release func(), err error) {
+	ctx := context.WithValue(context.Background(), "remote", remote)
+	if apiKey != "" {
+		ctx = context.WithValue(ctx, apiKeyCtxKey{}, apiKey)
+	}
+	release, rErr := l.acquire(ctx, method)
+	if rErr != nil {
+		return nil, rErr
+	}
+	return release, nil
+}
+
+func (l *Limiter) take(buckets map[string]*bucket, id string, rate float64, burst int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastClean) > bucketIdleTimeout {
+		l.lastClean = now
+		for _, m := range []map[string]*bucket{l.ipBuckets, l.keyBucket} {
+			for k, b := range m {
+				if now.Sub(b.last) > bucketIdleTimeout {
+					delete(m, k)
+				}
+			}
+		}
+	}
+
+	b, ok := buckets[id]
+	if !ok {
+		b = &bucket{}
+		buckets[id] = b
+	}
+	return b.take(now, rate, burst)
+}
+
+func (l *Limiter) reject(reason, message string) Error {
+	rejectedRequests.With(reason).Add(1)
+	return &limitExceededError{message}
+}
+
+// remoteIP returns the ip of the remote address of the request, empty if unknown
+func remoteIP(ctx context.Context) string {
+	remote, _ := ctx.Value("remote").(string)
+	if remote == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		return remote
+	}
+	return host
+}
+
+// withRequestInfo adds the remote address and the api key of the http request to the context.
+// The api key is only read from the header, a key in the url ends up in the access logs and the proxies.
+func withRequestInfo(ctx context.Context, r *http.Request) context.Context {
+	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		ctx = context.WithValue(ctx, apiKeyCtxKey{}, key)
+	}
+	return ctx
+}
@@ -0,0 +1,136 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+)
+
+func TestLimiter_IPRate(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	l := NewLimiter(config.RPCLimit{IPRate: 1, IPBurst: 2})
+	l.now = func() time.Time { return now }
+
+	ctx := context.WithValue(context.Background(), "remote", "1.2.3.4:5678")
+	other := context.WithValue(context.Background(), "remote", "5.6.7.8:5678")
+	for i := 0; i < 2; i++ {
+		if _, err := l.acquire(ctx, "ledger_getSnapshotChainHeight"); err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+	}
+	if _, err := l.acquire(ctx, "ledger_getSnapshotChainHeight"); err == nil {
+		t.Fatal("expected rate limit exceeded")
+	}
+	if _, err := l.acquire(other, "ledger_getSnapshotChainHeight"); err != nil {
+		t.Fatalf("other ip: %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if _, err := l.acquire(ctx, "ledger_getSnapshotChainHeight"); err != nil {
+		t.Fatalf("after refill: %v", err)
+	}
+}
+
+func TestLimiter_APIKey(t *testing.T) {
+	l := NewLimiter(config.RPCLimit{
+		RequireAPIKey: true,
+		APIKeys: map[string]*config.RPCAPIKey{
+			"k1": {Methods: []string{"ledger_*", "net_syncInfo"}},
+		},
+	})
+
+	if _, err := l.acquire(context.Background(), "ledger_getSnapshotChainHeight"); err == nil {
+		t.Fatal("expected api key is required")
+	}
+	invalid := context.WithValue(context.Background(), apiKeyCtxKey{}, "k2")
+	if _, err := l.acquire(invalid, "ledger_getSnapshotChainHeight"); err == nil {
+		t.Fatal("expected invalid api key")
+	}
+
+	ctx := context.WithValue(context.Background(), apiKeyCtxKey{}, "k1")
+	for _, method := range []string{"ledger_getSnapshotChainHeight", "net_syncInfo"} {
+		if _, err := l.acquire(ctx, method); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+	if _, err := l.acquire(ctx, "net_peers"); err == nil {
+		t.Fatal("expected method is not allowed")
+	}
+}
+
+func TestLimiter_MaxConcurrent(t *testing.T) {
+	l := NewLimiter(config.RPCLimit{MaxConcurrent: 1})
+	release, err := l.acquire(context.Background(), "ledger_getSnapshotChainHeight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = l.acquire(context.Background(), "ledger_getSnapshotChainHeight"); err == nil {
+		t.Fatal("expected too many concurrent requests")
+	}
+	release()
+	if _, err = l.acquire(context.Background(), "ledger_getSnapshotChainHeight"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimiter_Update(t *testing.T) {
+	l := NewLimiter(config.RPCLimit{MaxConcurrent: 1})
+	release, err := l.acquire(context.Background(), "ledger_getSnapshotChainHeight")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l.Update(config.RPCLimit{MaxConcurrent: 2})
+	if _, err = l.acquire(context.Background(), "ledger_getSnapshotChainHeight"); err != nil {
+		t.Fatalf("after update: %v", err)
+	}
+	release()
+
+	l.Update(config.RPCLimit{RequireAPIKey: true})
+	if _, err = l.acquire(context.Background(), "ledger_getSnapshotChainHeight"); err == nil {
+		t.Fatal("expected api key is required")
+	}
+}
+
+func TestLimiter_IdleBuckets(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	l := NewLimiter(config.RPCLimit{
+		IPRate:  1,
+		APIKeys: map[string]*config.RPCAPIKey{"k1": {Rate: 1}},
+	})
+	l.now = func() time.Time { return now }
+
+	ip := context.WithValue(context.Background(), "remote", "1.2.3.4:5678")
+	key := context.WithValue(ip, apiKeyCtxKey{}, "k1")
+	for _, ctx := range []context.Context{ip, key} {
+		if _, err := l.acquire(ctx, "ledger_getSnapshotChainHeight"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(l.ipBuckets) != 1 || len(l.keyBucket) != 1 {
+		t.Fatalf("unexpected buckets %d %d", len(l.ipBuckets), len(l.keyBucket))
+	}
+
+	now = now.Add(bucketIdleTimeout + time.Second)
+	if _, err := l.acquire(context.WithValue(context.Background(), "remote", "5.6.7.8:5678"), "ledger_getSnapshotChainHeight"); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.ipBuckets) != 1 || len(l.keyBucket) != 0 {
+		t.Fatalf("idle buckets are not dropped %d %d", len(l.ipBuckets), len(l.keyBucket))
+	}
+}
+
+func TestWithRequestInfo(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/?apikey=k1", nil)
+	if key := withRequestInfo(context.Background(), r).Value(apiKeyCtxKey{}); key != nil {
+		t.Fatalf("the api key in the url is accepted: %v", key)
+	}
+	r.Header.Set(apiKeyHeader, "k2")
+	if key := withRequestInfo(context.Background(), r).Value(apiKeyCtxKey{}); key != "k2" {
+		t.Fatalf("unexpected api key %v", key)
+	}
+}
@@ -268,6 +268,20 @@ func (s *Server) Stop() {
 	}
 }
 
+// SetLimiter limits the requests of the server, nil removes the limits
+func (s *Server) SetLimiter(l *Limiter) {
+	s.limiter.Store(l)
+}
+
+// limit applies the limiter to the request, release must be called if no error is returned
+func (s *Server) limit(ctx context.Context, req *serverRequest) (release func(), err Error) {
+	l, _ := s.limiter.Load().(*Limiter)
+	if l == nil {
+		return func() {}, nil
+	}
+	return l.acquire(ctx, req.svcname+serviceMethodSeparator+formatName(req.callb.method.Name))
+}
+
 // createSubscription will call the subscription callback and returns the subscription id or error.
 func (s *Server) createSubscription(ctx context.Context, c ServerCodec, req *serverRequest) (ID, error) {
 	// subscription have as first argument the context following optional arguments
@@ -306,6 +320,12 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	if req.callb.isSubscribe {
+		release, limitErr := s.limit(ctx, req)
+		if limitErr != nil {
+			return codec.CreateErrorResponse(&req.id, limitErr), nil
+		}
+		release()
+
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
 			return codec.CreateErrorResponse(&req.id, &callbackError{err.Error()}), nil
@@ -320,6 +340,12 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateResponse(req.id, subid), activateSub
 	}
 
+	release, limitErr := s.limit(ctx, req)
+	if limitErr != nil {
+		return codec.CreateErrorResponse(&req.id, limitErr), nil
+	}
+	defer release()
+
 	// regular RPC call, prepare arguments
 	if len(req.args) != len(req.callb.argTypes) {
 		rpcErr := &invalidParamsError{fmt.Sprintf("%s%s%s expects %d parameters, got %d",
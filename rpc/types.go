@@ -17,8 +17,10 @@
 package rpc
 
 import (
+	"net/http"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set"
 )
@@ -69,6 +71,11 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	httpHandlersMu sync.RWMutex
+	httpHandlers   map[string]http.Handler // served by path over HTTP, besides JSON-RPC
+
+	limiter atomic.Value // *Limiter
 }
 
 // rpcRequest represents a raw incoming RPC request
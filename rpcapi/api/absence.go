@@ -0,0 +1,183 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// maxAbsenceScanBlocks is the max number of the unreceived blocks and the account blocks after the snapshot block
+// scanned to prove that an address has no unreceived blocks
+const maxAbsenceScanBlocks = 1000
+
+const absenceScanPageSize = 100
+
+// AbsenceProof proves that a storage key has no value or an address has no unreceived blocks at a snapshot block.
+//
+// The snapshot headers carry no state commitment, so the proof binds the answer to the account state it is derived
+// from: AccountBlockHash is the latest account block of the address confirmed by the snapshot block, and
+// ConfirmSnapshotHash is the snapshot block whose content has it. A light client verifies the binding by the
+// snapshot headers from ConfirmSnapshotHeight to SnapshotHeight, none of them after the confirm snapshot block
+// has the address in its content. The account block fields are nil if the address has no block confirmed.
+type AbsenceProof struct {
+	Absent         bool          `json:"absent"`
+	Address        types.Address `json:"address"`
+	SnapshotHash   types.Hash    `json:"snapshotHash"`
+	SnapshotHeight string        `json:"snapshotHeight"`
+
+	AccountBlockHash      *types.Hash `json:"accountBlockHash,omitempty"`
+	AccountBlockHeight    *string     `json:"accountBlockHeight,omitempty"`
+	ConfirmSnapshotHash   *types.Hash `json:"confirmSnapshotHash,omitempty"`
+	ConfirmSnapshotHeight *string     `json:"confirmSnapshotHeight,omitempty"`
+
+	// Value is the value of the storage key if it's not absent
+	Value []byte `json:"value,omitempty"`
+	// UnreceivedBlockHash is a send block unreceived at the snapshot block if the unreceived blocks are not absent
+	UnreceivedBlockHash *types.Hash `json:"unreceivedBlockHash,omitempty"`
+}
+
+type StorageAbsenceParam struct {
+	Address        types.Address `json:"address"`
+	Key            []byte        `json:"key"`
+	SnapshotHash   *types.Hash   `json:"snapshotHash"`
+	SnapshotHeight *uint64       `json:"snapshotHeight"`
+}
+
+type UnreceivedAbsenceParam struct {
+	Address        types.Address `json:"address"`
+	SnapshotHash   *types.Hash   `json:"snapshotHash"`
+	SnapshotHeight *uint64       `json:"snapshotHeight"`
+}
+
+// newAbsenceProof returns the proof of addr at the snapshot block with the account block it's bound to
+func newAbsenceProof(c chain.Chain, addr types.Address, snapshotHash *types.Hash, snapshotHeight *uint64) (*AbsenceProof, *ledger.SnapshotBlock, *ledger.AccountBlock, error) {
+	sb, err := getSnapshotHeader(c, snapshotHash, snapshotHeight)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	block, err := vm_db.GetConfirmedAccountBlock(c, addr, sb)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	proof := &AbsenceProof{
+		Address:        addr,
+		SnapshotHash:   sb.Hash,
+		SnapshotHeight: Uint64ToString(sb.Height),
+	}
+	if block == nil {
+		return proof, sb, nil, nil
+	}
+	confirmSb, err := c.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if confirmSb == nil {
+		return nil, nil, nil, fmt.Errorf("confirm snapshot block of %s not found", block.Hash)
+	}
+	blockHeight, confirmHeight := Uint64ToString(block.Height), Uint64ToString(confirmSb.Height)
+	proof.AccountBlockHash, proof.AccountBlockHeight = &block.Hash, &blockHeight
+	proof.ConfirmSnapshotHash, proof.ConfirmSnapshotHeight = &confirmSb.Hash, &confirmHeight
+	return proof, sb, block, nil
+}
+
+// GetStorageAbsenceProof proves that the storage key of the contract has no value at the snapshot block,
+// the latest snapshot block if neither SnapshotHash nor SnapshotHeight is set. Absent is false with the value
+// if the key has a value.
+func (c *ContractApi) GetStorageAbsenceProof(param StorageAbsenceParam) (*AbsenceProof, error) {
+	if len(param.Key) == 0 {
+		return nil, errors.New("empty key")
+	}
+	proof, sb, _, err := newAbsenceProof(c.chain, param.Address, param.SnapshotHash, param.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	db, err := vm_db.NewReadOnlyVmDb(c.chain, param.Address, sb.Height)
+	if err != nil {
+		return nil, err
+	}
+	if proof.Value, err = db.GetValue(param.Key); err != nil {
+		return nil, err
+	}
+	proof.Absent = len(proof.Value) == 0
+	return proof, nil
+}
+
+// GetUnreceivedAbsenceProof proves that the address has no unreceived blocks at the snapshot block, the latest
+// snapshot block if neither SnapshotHash nor SnapshotHeight is set. A send block is unreceived at the snapshot
+// block if it's confirmed by the snapshot block but its receive block isn't. Absent is false with such a block
+// if there is one.
+func (l *LedgerApi) GetUnreceivedAbsenceProof(param UnreceivedAbsenceParam) (*AbsenceProof, error) {
+	proof, sb, block, err := newAbsenceProof(l.chain, param.Address, param.SnapshotHash, param.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := l.findUnreceivedAt(param.Address, sb, block)
+	if err != nil {
+		return nil, err
+	}
+	proof.UnreceivedBlockHash = hash
+	proof.Absent = hash == nil
+	return proof, nil
+}
+
+// findUnreceivedAt returns a send block to addr unreceived at the snapshot block, confirmed is the latest account
+// block of addr confirmed by the snapshot block. The send block is either unreceived now, or received by a block
+// after confirmed.
+func (l *LedgerApi) findUnreceivedAt(addr types.Address, sb *ledger.SnapshotBlock, confirmed *ledger.AccountBlock) (*types.Hash, error) {
+	confirmedBy := func(hash types.Hash) (bool, error) {
+		confirmSb, err := l.chain.GetConfirmSnapshotHeaderByAbHash(hash)
+		if err != nil {
+			return false, err
+		}
+		return confirmSb != nil && confirmSb.Height <= sb.Height, nil
+	}
+
+	scanned := 0
+	for page := 0; ; page++ {
+		blocks, err := l.chain.GetOnRoadBlocksByAddr(addr, page, absenceScanPageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, send := range blocks {
+			if ok, err := confirmedBy(send.Hash); err != nil {
+				return nil, err
+			} else if ok {
+				return &send.Hash, nil
+			}
+		}
+		if scanned += len(blocks); scanned > maxAbsenceScanBlocks {
+			return nil, fmt.Errorf("more than %d unreceived blocks of %s", maxAbsenceScanBlocks, addr)
+		}
+		if len(blocks) < absenceScanPageSize {
+			break
+		}
+	}
+
+	block, err := l.chain.GetLatestAccountBlock(addr)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; block != nil && (confirmed == nil || block.Height > confirmed.Height); i++ {
+		if i >= maxAbsenceScanBlocks {
+			return nil, fmt.Errorf("more than %d blocks of %s after the snapshot block", maxAbsenceScanBlocks, addr)
+		}
+		if block.IsReceiveBlock() {
+			if ok, err := confirmedBy(block.FromBlockHash); err != nil {
+				return nil, err
+			} else if ok {
+				return &block.FromBlockHash, nil
+			}
+		}
+		if block.Height <= 1 {
+			break
+		}
+		if block, err = l.chain.GetAccountBlockByHash(block.PrevHash); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+// absenceMockChain has the snapshot blocks 1 to 10, the account blocks of addr and the send blocks to addr,
+// confirm is the height of the snapshot block confirming each block
+type absenceMockChain struct {
+	chain.Chain
+	addr    types.Address
+	blocks  map[types.Hash]*ledger.AccountBlock
+	latest  *ledger.AccountBlock
+	onRoad  []*ledger.AccountBlock
+	confirm map[types.Hash]uint64
+
+	prunedHeight uint64
+}
+
+func (c *absenceMockChain) snapshot(height uint64) *ledger.SnapshotBlock {
+	return &ledger.SnapshotBlock{Hash: types.Hash{0xff, byte(height)}, Height: height}
+}
+
+func (c *absenceMockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshot(10)
+}
+
+func (c *absenceMockChain) GetSnapshotHeaderByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	return c.snapshot(height), nil
+}
+
+func (c *absenceMockChain) CheckHistoryHeight(height uint64) error {
+	if height < c.prunedHeight {
+		return errors.New("pruned")
+	}
+	return nil
+}
+
+func (c *absenceMockChain) GetLatestAccountBlock(addr types.Address) (*ledger.AccountBlock, error) {
+	return c.latest, nil
+}
+
+func (c *absenceMockChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[hash], nil
+}
+
+func (c *absenceMockChain) GetAccountBlockByHeight(addr types.Address, height uint64) (*ledger.AccountBlock, error) {
+	for _, block := range c.blocks {
+		if block.AccountAddress == addr && block.Height == height {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *absenceMockChain) GetConfirmSnapshotHeaderByAbHash(hash types.Hash) (*ledger.SnapshotBlock, error) {
+	if height, ok := c.confirm[hash]; ok {
+		return c.snapshot(height), nil
+	}
+	return nil, nil
+}
+
+func (c *absenceMockChain) GetOnRoadBlocksByAddr(addr types.Address, pageNum, pageSize int) ([]*ledger.AccountBlock, error) {
+	if pageNum > 0 {
+		return nil, nil
+	}
+	return c.onRoad, nil
+}
+
+func TestLedgerApi_GetUnreceivedAbsenceProof(t *testing.T) {
+	c := &absenceMockChain{
+		addr:    types.Address{1},
+		blocks:  make(map[types.Hash]*ledger.AccountBlock),
+		confirm: make(map[types.Hash]uint64),
+	}
+	send := func(i byte, confirm uint64) *ledger.AccountBlock {
+		b := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.Hash{0xee, i}, ToAddress: c.addr}
+		c.blocks[b.Hash] = b
+		c.confirm[b.Hash] = confirm
+		return b
+	}
+	s1, s2, s3 := send(1, 5), send(2, 7), send(3, 9)
+	c.onRoad = []*ledger.AccountBlock{s3}
+
+	// block h of addr is confirmed by the snapshot block 2h, the blocks 3 and 4 receive s1 and s2
+	var prev types.Hash
+	for h := uint64(1); h <= 4; h++ {
+		b := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.Hash{byte(h)}, PrevHash: prev, Height: h, AccountAddress: c.addr}
+		switch h {
+		case 3:
+			b.FromBlockHash = s1.Hash
+		case 4:
+			b.FromBlockHash = s2.Hash
+		}
+		c.blocks[b.Hash] = b
+		c.confirm[b.Hash] = 2 * h
+		c.latest, prev = b, b.Hash
+	}
+
+	l := &LedgerApi{chain: c}
+	for _, sample := range []struct {
+		snapshot   uint64
+		block      uint64
+		unreceived *types.Hash
+	}{
+		{4, 2, nil},
+		{6, 3, nil},
+		{7, 3, &s2.Hash},
+		{8, 4, nil},
+		{9, 4, &s3.Hash},
+	} {
+		height := sample.snapshot
+		proof, err := l.GetUnreceivedAbsenceProof(UnreceivedAbsenceParam{Address: c.addr, SnapshotHeight: &height})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *proof.AccountBlockHeight != Uint64ToString(sample.block) {
+			t.Errorf("snapshot %d: expected account block %d, got %s", height, sample.block, *proof.AccountBlockHeight)
+		}
+		if *proof.ConfirmSnapshotHeight != Uint64ToString(2*sample.block) {
+			t.Errorf("snapshot %d: wrong confirm snapshot %s", height, *proof.ConfirmSnapshotHeight)
+		}
+		if proof.Absent != (sample.unreceived == nil) {
+			t.Errorf("snapshot %d: expected absent %t", height, sample.unreceived == nil)
+		}
+		if sample.unreceived != nil && (proof.UnreceivedBlockHash == nil || *proof.UnreceivedBlockHash != *sample.unreceived) {
+			t.Errorf("snapshot %d: expected unreceived block %s", height, sample.unreceived)
+		}
+	}
+
+	// the state of the snapshot block is pruned
+	c.prunedHeight = 5
+	height := uint64(4)
+	if _, err := l.GetUnreceivedAbsenceProof(UnreceivedAbsenceParam{Address: c.addr, SnapshotHeight: &height}); err == nil {
+		t.Errorf("expected pruned error")
+	}
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_backup "github.com/vitelabs/go-vite/v2/ledger/chain/backup"
+	"github.com/vitelabs/go-vite/v2/ledger/pool"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/net"
+	"github.com/vitelabs/go-vite/v2/net/vnode"
+	"github.com/vitelabs/go-vite/v2/producer"
+)
+
+// the confirmation token of a rollback expires after it
+const rollbackConfirmTimeout = time.Minute
+
+// AdminApi is for the operator of the node
+type AdminApi struct {
+	net      net.Net
+	chain    chain.Chain
+	pool     pool.BlockPool
+	producer producer.Producer
+	log      log15.Logger
+}
+
+func NewAdminApi(vite *vite.Vite) *AdminApi {
+	return &AdminApi{
+		net:      vite.Net(),
+		chain:    vite.Chain(),
+		pool:     vite.Pool(),
+		producer: vite.Producer(),
+		log:      log15.New("module", "rpc_api/admin_api"),
+	}
+}
+
+func (a AdminApi) String() string {
+	return "AdminApi"
+}
+
+// PeerScores returns the reputation of the peers which have misbehaved, the lowest score first
+func (a *AdminApi) PeerScores() []net.PeerScore {
+	return a.net.PeerScores()
+}
+
+// UnbanPeer lifts the ban of the peer and resets its score
+func (a *AdminApi) UnbanPeer(id string) error {
+	nodeId, err := vnode.Hex2NodeID(id)
+	if err != nil {
+		return err
+	}
+	a.net.UnbanPeer(nodeId)
+	a.log.Info("unban peer", "id", id)
+	return nil
+}
+
+// Backup takes a consistent backup of the ledger into dir on the node while the node is running,
+// the unchanged block files of the previous backup in dir are not rewritten
+func (a *AdminApi) Backup(dir string) (*chain_backup.Manifest, error) {
+	if dir == "" {
+		return nil, errors.New("backup dir is empty")
+	}
+	a.log.Info("start backup", "dir", dir)
+	manifest, err := a.chain.Backup(dir)
+	if err != nil {
+		a.log.Error("backup failed", "dir", dir, "err", err)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Compact starts to compact the dbs of the ledger in the background, the dbs are "index", "state" and "redo",
+// all of them are compacted if dbs is empty. The progress is returned by CompactProgress.
+func (a *AdminApi) Compact(dbs []string) (chain.CompactProgress, error) {
+	if err := a.chain.CompactDbs(dbs); err != nil {
+		return chain.CompactProgress{}, err
+	}
+	a.log.Info("start compaction", "dbs", dbs)
+	return a.chain.CompactProgress(), nil
+}
+
+// CompactProgress returns the progress of the running or the last compaction
+func (a *AdminApi) CompactProgress() chain.CompactProgress {
+	return a.chain.CompactProgress()
+}
+
+// SetLogLevel changes the level of the logs at runtime. The level of all modules is changed if module is empty or "*",
+// otherwise the level of the module overrides the level of every log handler, and the override is removed if level is empty.
+func (a *AdminApi) SetLogLevel(module string, level string) error {
+	var err error
+	if module == "" || module == "*" {
+		err = common.SetLogLevel(level)
+	} else {
+		err = common.SetModuleLogLevel(module, level)
+	}
+	if err != nil {
+		return err
+	}
+	a.log.Info("set log level", "target", module, "level", level)
+	return nil
+}
+
+// LogLevels returns the level of all modules by "*", and the levels overridden by the modules
+func (a *AdminApi) LogLevels() map[string]string {
+	levels := common.ModuleLogLevels()
+	levels["*"] = common.LogLevel()
+	return levels
+}
+
+// RollbackResult is the rollback requested or done by RollbackToHeight
+type RollbackResult struct {
+	Height       uint64    `json:"height"`
+	LatestHeight uint64    `json:"latestHeight"`
+	Token        string    `json:"token,omitempty"`      // the token to confirm the rollback
+	ExpireTime   time.Time `json:"expireTime,omitempty"` // the token expires at
+	Done         bool      `json:"done"`
+}
+
+// rollbackConfirmation is the pending rollback waiting for the confirmation, it's shared by all the endpoints
+type rollbackConfirmation struct {
+	mu         sync.Mutex
+	token      string
+	height     uint64
+	expireTime time.Time
+}
+
+var pendingRollback = &rollbackConfirmation{}
+
+// issue returns a new token for the rollback to height, the token issued before is revoked
+func (c *rollbackConfirmation) issue(height uint64, now time.Time) (string, time.Time, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = hex.EncodeToString(buf)
+	c.height = height
+	c.expireTime = now.Add(rollbackConfirmTimeout)
+	return c.token, c.expireTime, nil
+}
+
+// confirm checks the token of the rollback to height, the token can be used only once
+func (c *rollbackConfirmation) confirm(height uint64, token string, now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token == "" || c.token != token {
+		return errors.New("invalid rollback token, request a new one without the token")
+	}
+	if c.height != height {
+		return fmt.Errorf("the rollback token is issued for height %d", c.height)
+	}
+	c.token = ""
+	if now.After(c.expireTime) {
+		return errors.New("the rollback token is expired, request a new one without the token")
+	}
+	return nil
+}
+
+func (a *AdminApi) checkNotProducing() error {
+	if a.producer != nil && a.producer.Producing() {
+		return errors.New("the producer is active, stop producing before rolling back")
+	}
+	return nil
+}
+
+// RollbackToHeight rolls the ledger back to the snapshot height, the blocks higher than height are deleted.
+// It's done in two steps: the call without token returns a token, and the call with the token before it expires
+// does the rollback. The rollback is refused while the node is producing.
+func (a *AdminApi) RollbackToHeight(height uint64, token string) (*RollbackResult, error) {
+	if err := a.checkNotProducing(); err != nil {
+		return nil, err
+	}
+	latest := a.chain.GetLatestSnapshotBlock()
+	if height < 1 || height >= latest.Height {
+		return nil, fmt.Errorf("rollback height %d must be in [1, %d)", height, latest.Height)
+	}
+
+	now := time.Now()
+	if token == "" {
+		token, expireTime, err := pendingRollback.issue(height, now)
+		if err != nil {
+			return nil, err
+		}
+		a.log.Info("rollback requested", "height", height, "latestHeight", latest.Height)
+		return &RollbackResult{
+			Height:       height,
+			LatestHeight: latest.Height,
+			Token:        token,
+			ExpireTime:   expireTime,
+		}, nil
+	}
+
+	if err := pendingRollback.confirm(height, token, now); err != nil {
+		return nil, err
+	}
+	a.log.Warn("start rollback", "height", height, "latestHeight", latest.Height)
+	// the producer is checked again under the insert lock of the pool, which the producer holds to produce a block
+	if err := a.pool.RollbackSnapshotTo(height, a.checkNotProducing); err != nil {
+		a.log.Error("rollback failed", "height", height, "err", err)
+		return nil, err
+	}
+	return &RollbackResult{
+		Height:       height,
+		LatestHeight: a.chain.GetLatestSnapshotBlock().Height,
+		Done:         true,
+	}, nil
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackConfirmation(t *testing.T) {
+	c := &rollbackConfirmation{}
+	now := time.Now()
+
+	assert.Error(t, c.confirm(100, "", now))
+
+	token, expireTime, err := c.issue(100, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(rollbackConfirmTimeout), expireTime)
+
+	assert.Error(t, c.confirm(100, "wrong", now))
+	assert.Error(t, c.confirm(99, token, now))
+	assert.NoError(t, c.confirm(100, token, now))
+	// used only once
+	assert.Error(t, c.confirm(100, token, now))
+
+	// a new token revokes the old one
+	old, _, err := c.issue(100, now)
+	assert.NoError(t, err)
+	token, _, err = c.issue(100, now)
+	assert.NoError(t, err)
+	assert.NotEqual(t, old, token)
+	assert.Error(t, c.confirm(100, old, now))
+	assert.NoError(t, c.confirm(100, token, now))
+
+	token, _, err = c.issue(100, now)
+	assert.NoError(t, err)
+	assert.Error(t, c.confirm(100, token, now.Add(rollbackConfirmTimeout+time.Second)))
+}
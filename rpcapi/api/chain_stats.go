@@ -0,0 +1,147 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+const (
+	maxStatsDays        = 366
+	maxStatsHeightRange = 86400
+	secondsPerDay       = 24 * 3600
+)
+
+// ChainStatsApi returns the statistics of the chain maintained by the chainStats plugin
+type ChainStatsApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewChainStatsApi(vite *vite.Vite) *ChainStatsApi {
+	return &ChainStatsApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/chain_stats_api"),
+	}
+}
+
+func (c ChainStatsApi) String() string {
+	return "ChainStatsApi"
+}
+
+type TokenVolume struct {
+	TokenId types.TokenTypeId `json:"tokenId"`
+	Amount  string            `json:"amount"`
+}
+
+type DailyStats struct {
+	Date                string         `json:"date"`
+	StartHeight         string         `json:"startHeight"`
+	EndHeight           string         `json:"endHeight"`
+	Transactions        string         `json:"transactions"`
+	ContractDeployments string         `json:"contractDeployments"`
+	ActiveAddresses     string         `json:"activeAddresses"`
+	Volumes             []*TokenVolume `json:"volumes"`
+}
+
+type RangeStats struct {
+	StartHeight         string         `json:"startHeight"`
+	EndHeight           string         `json:"endHeight"`
+	Transactions        string         `json:"transactions"`
+	ContractDeployments string         `json:"contractDeployments"`
+	ActiveAddresses     string         `json:"activeAddresses"`
+	Volumes             []*TokenVolume `json:"volumes"`
+}
+
+func (c *ChainStatsApi) plugin() (*chain_plugins.ChainStats, error) {
+	plugins := c.chain.Plugins()
+	if plugins == nil {
+		return nil, errors.New("config.OpenPlugins is false, api can't work")
+	}
+	plugin, ok := plugins.GetPlugin("chainStats").(*chain_plugins.ChainStats)
+	if !ok {
+		return nil, errors.New("plugin chainStats is not enabled")
+	}
+	return plugin, nil
+}
+
+func toTokenVolumes(volumes []chain_plugins.Volume) []*TokenVolume {
+	list := make([]*TokenVolume, len(volumes))
+	for i, v := range volumes {
+		list[i] = &TokenVolume{TokenId: v.TokenId, Amount: *bigIntToString(v.Amount)}
+	}
+	return list
+}
+
+func dayToDate(day uint64) string {
+	return time.Unix(int64(day)*secondsPerDay, 0).UTC().Format("2006-01-02")
+}
+
+// GetDailyStats returns the statistics of the UTC days from startTime to endTime in unix seconds,
+// the days without blocks are omitted
+func (c *ChainStatsApi) GetDailyStats(startTime int64, endTime int64) ([]*DailyStats, error) {
+	if startTime < 0 || endTime < startTime {
+		return nil, fmt.Errorf("invalid time range [%d, %d]", startTime, endTime)
+	}
+	startDay, endDay := uint64(startTime/secondsPerDay), uint64(endTime/secondsPerDay)
+	if endDay-startDay >= maxStatsDays {
+		return nil, fmt.Errorf("time range is more than %d days", maxStatsDays)
+	}
+
+	plugin, err := c.plugin()
+	if err != nil {
+		return nil, err
+	}
+	list, err := plugin.GetDayStats(startDay, endDay)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*DailyStats, len(list))
+	for i, ds := range list {
+		result[i] = &DailyStats{
+			Date:                dayToDate(ds.Day),
+			StartHeight:         Uint64ToString(ds.StartHeight),
+			EndHeight:           Uint64ToString(ds.EndHeight),
+			Transactions:        Uint64ToString(ds.Transactions),
+			ContractDeployments: Uint64ToString(ds.ContractDeployments),
+			ActiveAddresses:     Uint64ToString(ds.ActiveAddresses),
+			Volumes:             toTokenVolumes(ds.Volumes),
+		}
+	}
+	return result, nil
+}
+
+// GetStatsByHeightRange returns the statistics of the snapshot blocks in [startHeight, endHeight]
+func (c *ChainStatsApi) GetStatsByHeightRange(startHeight uint64, endHeight uint64) (*RangeStats, error) {
+	if endHeight < startHeight {
+		return nil, fmt.Errorf("invalid height range [%d, %d]", startHeight, endHeight)
+	}
+	if endHeight-startHeight >= maxStatsHeightRange {
+		return nil, fmt.Errorf("height range is more than %d", maxStatsHeightRange)
+	}
+
+	plugin, err := c.plugin()
+	if err != nil {
+		return nil, err
+	}
+	s, err := plugin.GetRangeStats(startHeight, endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RangeStats{
+		StartHeight:         Uint64ToString(s.StartHeight),
+		EndHeight:           Uint64ToString(s.EndHeight),
+		Transactions:        Uint64ToString(s.Transactions),
+		ContractDeployments: Uint64ToString(s.ContractDeployments),
+		ActiveAddresses:     Uint64ToString(s.ActiveAddresses),
+		Volumes:             toTokenVolumes(s.Volumes),
+	}, nil
+}
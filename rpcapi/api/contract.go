@@ -39,6 +39,8 @@ type CreateContractDataParam struct {
 	QuotaRatio  uint8     `json:"quotaRatio"`
 	HexCode     string    `json:"hexCode"`
 	Params      []byte    `json:"params"`
+	// Salt creates the contract at the address derived from the creator, the salt and the code, see contract_createSaltedContractAddress
+	Salt *types.Hash `json:"salt,omitempty"`
 }
 
 // Private
@@ -47,6 +49,15 @@ func (c *ContractApi) GetCreateContractData(param CreateContractDataParam) ([]by
 	if err != nil {
 		return nil, err
 	}
+	if param.Salt != nil {
+		return util.GetSaltedCreateContractData(
+			helper.JoinBytes(code, param.Params),
+			param.ConfirmTime,
+			param.SeedCount,
+			param.QuotaRatio,
+			param.Gid,
+			*param.Salt), nil
+	}
 	if len(param.Params) > 0 {
 		data := util.GetCreateContractData(
 			helper.JoinBytes(code, param.Params),
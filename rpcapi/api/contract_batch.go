@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// maxCallOffChainBatchSize is the max number of calls in a batch
+const maxCallOffChainBatchSize = 1000
+
+// CallOffChainBatchItem is a call of an off-chain method of a contract.
+// The call data is packed from Abi, MethodName and Params if Abi is set, otherwise Data is used.
+// The code of the contract is used if Code is empty.
+type CallOffChainBatchItem struct {
+	Addr       types.Address `json:"address"`
+	Code       []byte        `json:"code"`
+	Abi        string        `json:"abi"`
+	MethodName string        `json:"methodName"`
+	Params     []string      `json:"params"`
+	Data       []byte        `json:"data"`
+	Height     *uint64       `json:"height"`
+}
+
+// CallOffChainBatchParam holds the calls which are executed on the same state,
+// the state confirmed by the snapshot block of SnapshotHash or SnapshotHeight, or the latest state if neither is set.
+type CallOffChainBatchParam struct {
+	Calls          []CallOffChainBatchItem `json:"calls"`
+	SnapshotHash   *types.Hash             `json:"snapshotHash"`
+	SnapshotHeight *uint64                 `json:"snapshotHeight"`
+}
+
+// CallOffChainBatchResult is the result of a call, Outputs is decoded by the abi of the call if set.
+type CallOffChainBatchResult struct {
+	Data    []byte        `json:"data,omitempty"`
+	Outputs []interface{} `json:"outputs,omitempty"`
+	Error   string        `json:"error,omitempty"`
+}
+
+type CallOffChainBatchRes struct {
+	SnapshotHash   types.Hash                 `json:"snapshotHash"`
+	SnapshotHeight string                     `json:"snapshotHeight"`
+	Results        []*CallOffChainBatchResult `json:"results"`
+}
+
+// callOffChainBatch caches the prev block hashes and the parsed abis shared by the calls of a batch
+type callOffChainBatch struct {
+	c        *ContractApi
+	state    *simulateState
+	prevHash map[types.Address]types.Hash
+	abis     map[string]abi.ABIContract
+}
+
+// CallOffChainBatch executes the off-chain methods of the calls against a single snapshot,
+// an error of a call is returned in its result and doesn't fail the others.
+func (c *ContractApi) CallOffChainBatch(param CallOffChainBatchParam) (*CallOffChainBatchRes, error) {
+	if len(param.Calls) == 0 {
+		return nil, errors.New("empty calls")
+	}
+	if len(param.Calls) > maxCallOffChainBatchSize {
+		return nil, fmt.Errorf("more than %d calls in a batch", maxCallOffChainBatchSize)
+	}
+	state, err := newSimulateState(c.chain, param.SnapshotHash, param.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	batch := &callOffChainBatch{
+		c:        c,
+		state:    state,
+		prevHash: make(map[types.Address]types.Hash),
+		abis:     make(map[string]abi.ABIContract),
+	}
+	res := &CallOffChainBatchRes{
+		SnapshotHash:   state.snapshot.Hash,
+		SnapshotHeight: Uint64ToString(state.snapshot.Height),
+		Results:        make([]*CallOffChainBatchResult, len(param.Calls)),
+	}
+	for i := range param.Calls {
+		result, err := batch.call(&param.Calls[i])
+		if err != nil {
+			result = &CallOffChainBatchResult{Error: err.Error()}
+		}
+		res.Results[i] = result
+	}
+	return res, nil
+}
+
+func (b *callOffChainBatch) call(item *CallOffChainBatchItem) (*CallOffChainBatchResult, error) {
+	var contract *abi.ABIContract
+	data := item.Data
+	if len(item.Abi) > 0 {
+		parsed, err := b.abi(item.Abi)
+		if err != nil {
+			return nil, err
+		}
+		method, ok := parsed.OffChains[item.MethodName]
+		if !ok {
+			return nil, errors.New("offchain name not found")
+		}
+		arguments, err := convert(item.Params, method.Inputs)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = parsed.PackOffChain(item.MethodName, arguments...); err != nil {
+			return nil, err
+		}
+		contract = &parsed
+	}
+	db, err := b.vmDb(item.Addr, item.Height)
+	if err != nil {
+		return nil, err
+	}
+	code := item.Code
+	if len(code) == 0 {
+		_, code = util.GetContractCode(db, &item.Addr, nil)
+	}
+	output, err := vm.NewVM(nil).OffChainReader(db, code, data)
+	if err != nil {
+		return nil, err
+	}
+	result := &CallOffChainBatchResult{Data: output}
+	if contract != nil {
+		if result.Outputs, err = contract.DirectUnpackOffchainOutput(item.MethodName, output); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (b *callOffChainBatch) abi(abiStr string) (abi.ABIContract, error) {
+	if parsed, ok := b.abis[abiStr]; ok {
+		return parsed, nil
+	}
+	parsed, err := abi.JSONToABIContract(strings.NewReader(abiStr))
+	if err != nil {
+		return abi.ABIContract{}, err
+	}
+	b.abis[abiStr] = parsed
+	return parsed, nil
+}
+
+// vmDb returns a VmDb of addr in the state of the batch, the account state is of the account block at height if height is set
+func (b *callOffChainBatch) vmDb(addr types.Address, height *uint64) (interfaces.VmDb, error) {
+	if height != nil {
+		prevHash, err := b.c.chain.GetAccountBlockHashByHeight(addr, *height)
+		if err != nil {
+			return nil, err
+		}
+		return vm_db.NewVmDb(b.state.chain, &addr, &b.state.snapshot.Hash, prevHash)
+	}
+	prevHash, ok := b.prevHash[addr]
+	if !ok {
+		prev, err := b.state.prevBlock(addr)
+		if err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			prevHash = prev.Hash
+		}
+		b.prevHash[addr] = prevHash
+	}
+	return vm_db.NewVmDb(b.state.chain, &addr, &b.state.snapshot.Hash, &prevHash)
+}
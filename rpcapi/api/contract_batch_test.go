@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/test_tools"
+	"github.com/vitelabs/go-vite/v2/vm"
+)
+
+// echoCode returns the first argument of the call data
+const echoCode = "60043560005260206000f3"
+
+const echoAbi = `[{"type":"offchain","name":"echo","inputs":[{"name":"a","type":"uint256"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+func TestContractApi_CallOffChainBatch(t *testing.T) {
+	vm.InitVMConfig(false, false, false, false, "")
+	c, tempDir := test_tools.NewTestChainInstance(t, true, config.MockGenesis())
+	defer test_tools.ClearChain(c, tempDir)
+
+	code, err := hex.DecodeString(echoCode)
+	assert.NoError(t, err)
+	api := &ContractApi{chain: c}
+
+	_, err = api.CallOffChainBatch(CallOffChainBatchParam{})
+	assert.Error(t, err)
+	_, err = api.CallOffChainBatch(CallOffChainBatchParam{Calls: make([]CallOffChainBatchItem, maxCallOffChainBatchSize+1)})
+	assert.Error(t, err)
+
+	addr := types.AddressGovernance
+	res, err := api.CallOffChainBatch(CallOffChainBatchParam{Calls: []CallOffChainBatchItem{
+		{Addr: addr, Code: code, Abi: echoAbi, MethodName: "echo", Params: []string{"42"}},
+		{Addr: addr, Code: code, Abi: echoAbi, MethodName: "missing"},
+		{Addr: addr, Code: code, Abi: echoAbi, MethodName: "echo", Params: []string{"7"}},
+		{Addr: addr, Code: code, Data: append(make([]byte, 4), types.Hash{31: 9}.Bytes()...)},
+	}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// all the calls are executed on the latest snapshot block
+	latest := c.GetLatestSnapshotBlock()
+	assert.Equal(t, latest.Hash, res.SnapshotHash)
+	assert.Equal(t, Uint64ToString(latest.Height), res.SnapshotHeight)
+
+	if !assert.Len(t, res.Results, 4) {
+		return
+	}
+	assert.Empty(t, res.Results[0].Error)
+	assert.Equal(t, []interface{}{big.NewInt(42)}, res.Results[0].Outputs)
+	// the error of a call doesn't fail the others
+	assert.NotEmpty(t, res.Results[1].Error)
+	assert.Equal(t, []interface{}{big.NewInt(7)}, res.Results[2].Outputs)
+	// the raw data is returned without the abi
+	assert.Equal(t, types.Hash{31: 9}.Bytes(), res.Results[3].Data)
+	assert.Nil(t, res.Results[3].Outputs)
+
+	// the snapshot block is pinned by height
+	height := latest.Height
+	res, err = api.CallOffChainBatch(CallOffChainBatchParam{
+		SnapshotHeight: &height,
+		Calls:          []CallOffChainBatchItem{{Addr: addr, Code: code, Abi: echoAbi, MethodName: "echo", Params: []string{"1"}}},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, latest.Hash, res.SnapshotHash)
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+)
+
+type NameInfo struct {
+	Name               string             `json:"name"`
+	Owner              types.Address      `json:"owner"`
+	Address            *types.Address     `json:"address"`
+	TokenId            *types.TokenTypeId `json:"tokenId"`
+	RegistrationHeight string             `json:"registrationHeight"`
+}
+
+func newNameInfo(info *abi.NameInfo) *NameInfo {
+	nameInfo := &NameInfo{
+		Name:               info.Name,
+		Owner:              info.Owner,
+		RegistrationHeight: Uint64ToString(info.RegistrationHeight),
+	}
+	if info.Address != types.ZERO_ADDRESS {
+		nameInfo.Address = &info.Address
+	}
+	if info.TokenId != types.ZERO_TOKENID {
+		nameInfo.TokenId = &info.TokenId
+	}
+	return nameInfo
+}
+
+// GetNameInfo returns the record of a name in the name service, nil if the name is not registered
+func (c *ContractApi) GetNameInfo(name string) (*NameInfo, error) {
+	db, err := getVmDb(c.chain, types.AddressNameService)
+	if err != nil {
+		return nil, err
+	}
+	info, err := abi.GetNameInfo(db, name)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return newNameInfo(info), nil
+}
+
+// ResolveName returns the address set for a name, nil if the name is not registered or has no address
+func (c *ContractApi) ResolveName(name string) (*types.Address, error) {
+	info, err := c.GetNameInfo(name)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return info.Address, nil
+}
+
+// GetNameInfoListByOwner returns the names registered by or transferred to owner
+func (c *ContractApi) GetNameInfoListByOwner(owner types.Address) ([]*NameInfo, error) {
+	db, err := getVmDb(c.chain, types.AddressNameService)
+	if err != nil {
+		return nil, err
+	}
+	infoList, err := abi.GetNameInfoListByOwner(db, owner)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*NameInfo, len(infoList))
+	for i, info := range infoList {
+		result[i] = newNameInfo(info)
+	}
+	return result, nil
+}
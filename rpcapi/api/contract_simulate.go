@@ -0,0 +1,110 @@
+package api
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+type SimulateCallParam struct {
+	SelfAddr types.Address      `json:"selfAddr"`
+	ToAddr   types.Address      `json:"toAddr"`
+	TokenId  *types.TokenTypeId `json:"tokenId"`
+	Amount   *string            `json:"amount"`
+	Data     []byte             `json:"data"`
+
+	// the snapshot block of the state, the latest state if neither is set
+	SnapshotHash   *types.Hash `json:"snapshotHash"`
+	SnapshotHeight *uint64     `json:"snapshotHeight"`
+}
+
+type SimulatedSendBlock struct {
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    string            `json:"amount"`
+	Data      []byte            `json:"data"`
+}
+
+type SimulateCallResult struct {
+	SnapshotHeight uint64              `json:"snapshotHeight"`
+	QuotaUsed      string              `json:"quotaUsed"`
+	Reverted       bool                `json:"reverted"`
+	Error          string              `json:"error,omitempty"`
+	ExecutionError *ExecutionErrorInfo `json:"executionError,omitempty"`
+
+	SendBlockList []*SimulatedSendBlock `json:"sendBlockList"`
+	VmLogList     ledger.VmLogList      `json:"vmLogList"`
+	// Storage is the changed storage of the contract, keys and values are hex encoded
+	Storage  map[string]string `json:"storage"`
+	Balances map[string]string `json:"balances"`
+}
+
+// SimulateCall receives a call to a contract against the latest state or the state of a snapshot block,
+// and returns the changes the receive block would make. Nothing is written to the chain.
+func (c *ContractApi) SimulateCall(param SimulateCallParam) (*SimulateCallResult, error) {
+	if !types.IsContractAddr(param.ToAddr) {
+		return nil, errors.New("toAddr is not a contract")
+	}
+	amount := big.NewInt(0)
+	if param.Amount != nil {
+		var err error
+		if amount, err = stringToBigInt(param.Amount); err != nil {
+			return nil, err
+		}
+	}
+	tokenId := ledger.ViteTokenId
+	if param.TokenId != nil {
+		tokenId = *param.TokenId
+	}
+
+	state, err := newSimulateState(c.chain, param.SnapshotHash, param.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	sendBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: param.SelfAddr,
+		ToAddress:      param.ToAddr,
+		TokenId:        tokenId,
+		Amount:         amount,
+		Fee:            big.NewInt(0),
+		Data:           param.Data,
+	}
+	sendBlock.Hash = sendBlock.ComputeHash()
+
+	receive, err := state.simulateReceive(c.cs, sendBlock, nil)
+	result := &SimulateCallResult{
+		SnapshotHeight: state.snapshot.Height,
+		SendBlockList:  make([]*SimulatedSendBlock, 0),
+		Storage:        make(map[string]string),
+		Balances:       make(map[string]string),
+	}
+	if err != nil {
+		result.Reverted = true
+		result.Error = err.Error()
+	}
+	if receive == nil {
+		return result, nil
+	}
+	result.QuotaUsed = Uint64ToString(receive.AccountBlock.QuotaUsed)
+	result.ExecutionError = executionErrorToInfo(receive.VmDb.GetUnsavedExecutionError())
+	for _, send := range receive.AccountBlock.SendBlockList {
+		result.SendBlockList = append(result.SendBlockList, &SimulatedSendBlock{
+			ToAddress: send.ToAddress,
+			TokenId:   send.TokenId,
+			Amount:    send.Amount.String(),
+			Data:      send.Data,
+		})
+	}
+	result.VmLogList = receive.VmDb.GetLogList()
+	for _, kv := range receive.VmDb.GetUnsavedStorage() {
+		result.Storage[hex.EncodeToString(kv[0])] = hex.EncodeToString(kv[1])
+	}
+	for tid, balance := range receive.VmDb.GetUnsavedBalanceMap() {
+		result.Balances[tid.String()] = balance.String()
+	}
+	return result, nil
+}
@@ -2,12 +2,15 @@ package api
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 
 	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/helper"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
 	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus"
@@ -49,6 +52,17 @@ func (c *ContractApi) CreateContractAddress(address types.Address, height string
 	return &addr, nil
 }
 
+// CreateSaltedContractAddress returns the address of the contract created with the salt, which depends on the code and
+// the constructor params instead of the height and the previous hash of the create block
+func (c *ContractApi) CreateSaltedContractAddress(address types.Address, salt types.Hash, hexCode string, params []byte) (*types.Address, error) {
+	code, err := hex.DecodeString(hexCode)
+	if err != nil {
+		return nil, err
+	}
+	addr := util.NewSaltedContractAddress(address, salt, helper.JoinBytes(code, params))
+	return &addr, nil
+}
+
 type ContractInfo struct {
 	Code            []byte    `json:"code"`
 	Gid             types.Gid `json:"gid"`
@@ -93,33 +107,32 @@ type CallOffChainMethodParam struct {
 	Data              []byte         `json:"data"`
 	Height            *uint64        `json:"height"`
 	SnapshotHash      *types.Hash    `json:"snapshotHash"`
+	SnapshotHeight    *uint64        `json:"snapshotHeight"`
 }
 
-func (c *ContractApi) CallOffChainMethod(param CallOffChainMethodParam) ([]byte, error) {
-	if param.Addr != nil {
-		param.SelfAddr = *param.Addr
+// offChainVmDb returns a VmDb of addr which reads the state confirmed by the snapshot block of snapshotHash or snapshotHeight,
+// or the latest state if neither is set. The account state is of the account block at height if height is set.
+func (c *ContractApi) offChainVmDb(addr types.Address, height *uint64, snapshotHash *types.Hash, snapshotHeight *uint64) (interfaces.VmDb, error) {
+	state, err := newSimulateState(c.chain, snapshotHash, snapshotHeight)
+	if err != nil {
+		return nil, err
 	}
-	var prevHash *types.Hash
-	var err error
-	if param.Height == nil {
-		prevHash, err = getPrevBlockHash(c.chain, param.SelfAddr)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		prevHash, err = c.chain.GetAccountBlockHashByHeight(param.SelfAddr, *(param.Height))
-		if err != nil {
-			return nil, err
-		}
+	if height == nil {
+		db, _, err := state.newVmDb(addr)
+		return db, err
 	}
-	var snapshotHash *types.Hash
-	if param.SnapshotHash == nil {
-		snapshotHash = &c.chain.GetLatestSnapshotBlock().Hash
-	} else {
-		snapshotHash = param.SnapshotHash
+	prevHash, err := c.chain.GetAccountBlockHashByHeight(addr, *height)
+	if err != nil {
+		return nil, err
 	}
+	return vm_db.NewVmDb(state.chain, &addr, &state.snapshot.Hash, prevHash)
+}
 
-	db, err := vm_db.NewVmDb(c.chain, &param.SelfAddr, snapshotHash, prevHash)
+func (c *ContractApi) CallOffChainMethod(param CallOffChainMethodParam) ([]byte, error) {
+	if param.Addr != nil {
+		param.SelfAddr = *param.Addr
+	}
+	db, err := c.offChainVmDb(param.SelfAddr, param.Height, param.SnapshotHash, param.SnapshotHeight)
 	if err != nil {
 		return nil, err
 	}
@@ -138,34 +151,15 @@ func (c *ContractApi) CallOffChainMethod(param CallOffChainMethodParam) ([]byte,
 }
 
 type QueryParam struct {
-	Addr              *types.Address `json:"address"`
-	Data              []byte         `json:"data"`
-	Height            *uint64        `json:"height"`
-	SnapshotHash      *types.Hash    `json:"snapshotHash"`
+	Addr           *types.Address `json:"address"`
+	Data           []byte         `json:"data"`
+	Height         *uint64        `json:"height"`
+	SnapshotHash   *types.Hash    `json:"snapshotHash"`
+	SnapshotHeight *uint64        `json:"snapshotHeight"`
 }
 
 func (c *ContractApi) Query(param QueryParam) ([]byte, error) {
-	var prevHash *types.Hash
-	var err error
-	if param.Height == nil {
-		prevHash, err = getPrevBlockHash(c.chain, *(param.Addr))
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		prevHash, err = c.chain.GetAccountBlockHashByHeight(*(param.Addr), *(param.Height))
-		if err != nil {
-			return nil, err
-		}
-	}
-	var snapshotHash *types.Hash
-	if param.SnapshotHash == nil {
-		snapshotHash = &c.chain.GetLatestSnapshotBlock().Hash
-	} else {
-		snapshotHash = param.SnapshotHash
-	}
-
-	db, err := vm_db.NewVmDb(c.chain, param.Addr, snapshotHash, prevHash)
+	db, err := c.offChainVmDb(*param.Addr, param.Height, param.SnapshotHash, param.SnapshotHeight)
 	if err != nil {
 		return nil, err
 	}
@@ -498,6 +492,95 @@ func (r *ContractApi) GetSBPRewardByCycle(cycle string) (*SBPRewardInfo, error)
 	return &SBPRewardInfo{rewardMap, startTime.Unix(), endTime.Unix(), cycle}, nil
 }
 
+type SBPRewardDetail struct {
+	Cycle          string     `json:"cycle"`
+	StartTime      int64      `json:"startTime"`
+	EndTime        int64      `json:"endTime"`
+	Reward         *SBPReward `json:"reward"`
+	RewardPerBlock string     `json:"rewardPerBlock"`
+	// blocks produced by all sbps in the cycle
+	TotalBlocks string `json:"totalBlocks"`
+	SBPNum      string `json:"sbpNum"`
+	Votes       string `json:"votes"`
+	TotalVotes  string `json:"totalVotes"`
+	StakeAmount string `json:"stakeAmount"`
+	// vote weighted share = voteWeight / totalVoteWeight
+	VoteWeight      string `json:"voteWeight"`
+	TotalVoteWeight string `json:"totalVoteWeight"`
+}
+
+const maxSBPRewardDetailCycles = 90
+
+// GetSBPRewardDetail returns the reward breakdown of the sbp whose block producing address is addr,
+// for every cycle in [startCycle, endCycle].
+func (r *ContractApi) GetSBPRewardDetail(addr types.Address, startCycle string, endCycle string) ([]*SBPRewardDetail, error) {
+	startIndex, err := StringToUint64(startCycle)
+	if err != nil {
+		return nil, err
+	}
+	endIndex, err := StringToUint64(endCycle)
+	if err != nil {
+		return nil, err
+	}
+	if startIndex > endIndex {
+		return nil, errors.New("start cycle must not be greater than end cycle")
+	}
+	if endIndex-startIndex >= maxSBPRewardDetailCycles {
+		return nil, fmt.Errorf("max cycles is %d", maxSBPRewardDetailCycles)
+	}
+	db, err := getVmDb(r.chain, types.AddressGovernance)
+	if err != nil {
+		return nil, err
+	}
+	list, err := abi.GetAllRegistrationList(db, types.SNAPSHOT_GID)
+	if err != nil {
+		return nil, err
+	}
+	var info *types.Registration
+	for _, v := range list {
+		if v.BlockProducingAddress == addr {
+			info = v
+			break
+		}
+		for _, hisAddr := range v.HisAddrList {
+			if hisAddr == addr {
+				info = v
+				break
+			}
+		}
+		if info != nil {
+			break
+		}
+	}
+	if info == nil {
+		return nil, errors.New("sbp not exist")
+	}
+	details, err := contracts.CalcRewardDetailByIndex(db, util.NewVMConsensusReader(r.cs.SBPReader()), startIndex, endIndex, info.Name)
+	if err != nil {
+		return nil, err
+	}
+	timeIndex := r.cs.SBPReader().GetDayTimeIndex()
+	result := make([]*SBPRewardDetail, 0, len(details))
+	for _, d := range details {
+		startTime, endTime := timeIndex.Index2Time(d.Index)
+		result = append(result, &SBPRewardDetail{
+			Cycle:           Uint64ToString(d.Index),
+			StartTime:       startTime.Unix(),
+			EndTime:         endTime.Unix(),
+			Reward:          ToSBPReward(d.Reward),
+			RewardPerBlock:  *bigIntToString(d.RewardPerBlock),
+			TotalBlocks:     Uint64ToString(d.TotalBlockNum),
+			SBPNum:          Uint64ToString(d.SbpNum),
+			Votes:           *bigIntToString(d.VoteCount),
+			TotalVotes:      *bigIntToString(d.TotalVoteCount),
+			StakeAmount:     *bigIntToString(d.StakeAmount),
+			VoteWeight:      *bigIntToString(d.VoteWeight),
+			TotalVoteWeight: *bigIntToString(d.TotalVoteWeight),
+		})
+	}
+	return result, nil
+}
+
 func (r *ContractApi) GetSBP(name string) (*SBPInfo, error) {
 	db, err := getVmDb(r.chain, types.AddressGovernance)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
 	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
 )
 
@@ -566,3 +567,86 @@ type VIPStakingRpc struct {
 	ExpirationTime   int64  `json:"expirationTime"`
 	Id               string `json:"id,omitempty"`
 }
+
+type RpcPriceLevel struct {
+	Price    string `json:"price"`
+	Quantity string `json:"quantity"`
+	Amount   string `json:"amount"`
+	Orders   int    `json:"orders"`
+}
+
+// RpcOrderBookDepth is the price levels of the order book, truncated is true if the levels of the side are
+// incomplete because of the limit of the orders read
+type RpcOrderBookDepth struct {
+	Buy           []*RpcPriceLevel `json:"buy"`
+	Sell          []*RpcPriceLevel `json:"sell"`
+	BuyTruncated  bool             `json:"buyTruncated,omitempty"`
+	SellTruncated bool             `json:"sellTruncated,omitempty"`
+	QueryStart    core.HashHeight  `json:"queryStart"`
+	QueryEnd      core.HashHeight  `json:"queryEnd"`
+}
+
+func PriceLevelsToRpc(levels []*dex.PriceLevel) []*RpcPriceLevel {
+	rpcLevels := make([]*RpcPriceLevel, len(levels))
+	for i, level := range levels {
+		rpcLevels[i] = &RpcPriceLevel{
+			Price:    dex.BytesToPrice(level.Price),
+			Quantity: level.Quantity.String(),
+			Amount:   level.Amount.String(),
+			Orders:   level.Orders,
+		}
+	}
+	return rpcLevels
+}
+
+type RpcTrade struct {
+	Id               string `json:"id"`
+	MarketId         int32  `json:"marketId"`
+	TakerSide        bool   `json:"takerSide"`
+	TakerId          string `json:"takerId"`
+	MakerId          string `json:"makerId"`
+	TakerAddress     string `json:"takerAddress,omitempty"`
+	MakerAddress     string `json:"makerAddress,omitempty"`
+	Price            string `json:"price"`
+	Quantity         string `json:"quantity"`
+	Amount           string `json:"amount"`
+	TakerFee         string `json:"takerFee"`
+	MakerFee         string `json:"makerFee"`
+	TakerOperatorFee string `json:"takerOperatorFee"`
+	MakerOperatorFee string `json:"makerOperatorFee"`
+	Timestamp        int64  `json:"timestamp"`
+	SnapshotHeight   uint64 `json:"snapshotHeight,string"`
+}
+
+type TradesRes struct {
+	Trades []*RpcTrade `json:"trades"`
+	// Cursor is the position of the last trade, passed to query the next page, nil if no more trades
+	Cursor *string `json:"cursor,omitempty"`
+}
+
+func TradeToRpc(trade *chain_plugins.DexTrade) *RpcTrade {
+	tx := trade.Transaction
+	rpcTrade := &RpcTrade{
+		Id:               hex.EncodeToString(tx.Id),
+		MarketId:         trade.MarketId,
+		TakerSide:        tx.TakerSide,
+		TakerId:          hex.EncodeToString(tx.TakerId),
+		MakerId:          hex.EncodeToString(tx.MakerId),
+		Price:            dex.BytesToPrice(tx.Price),
+		Quantity:         AmountBytesToString(tx.Quantity),
+		Amount:           AmountBytesToString(tx.Amount),
+		TakerFee:         AmountBytesToString(tx.TakerFee),
+		MakerFee:         AmountBytesToString(tx.MakerFee),
+		TakerOperatorFee: AmountBytesToString(tx.TakerOperatorFee),
+		MakerOperatorFee: AmountBytesToString(tx.MakerOperatorFee),
+		Timestamp:        tx.Timestamp,
+		SnapshotHeight:   trade.Height,
+	}
+	if trade.TakerAddress != types.ZERO_ADDRESS {
+		rpcTrade.TakerAddress = trade.TakerAddress.String()
+	}
+	if trade.MakerAddress != types.ZERO_ADDRESS {
+		rpcTrade.MakerAddress = trade.MakerAddress.String()
+	}
+	return rpcTrade
+}
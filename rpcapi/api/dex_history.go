@@ -0,0 +1,212 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	chain_plugins "github.com/vitelabs/go-vite/v2/ledger/chain/plugins"
+	apidex "github.com/vitelabs/go-vite/v2/rpcapi/api/dex"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+)
+
+const (
+	maxOrderBookDepth       = 500
+	maxOrderBookDepthOrders = 10000
+)
+
+// DexTradesQuery selects the trades confirmed by the snapshot blocks in [FromHeight, ToHeight] and [FromTime, ToTime],
+// the bounds are optional. The trades are returned latest first, Cursor is the cursor of the previous page.
+type DexTradesQuery struct {
+	FromHeight *string `json:"fromHeight,omitempty"`
+	ToHeight   *string `json:"toHeight,omitempty"`
+	FromTime   *int64  `json:"fromTime,omitempty"` // unix seconds
+	ToTime     *int64  `json:"toTime,omitempty"`
+	Cursor     *string `json:"cursor,omitempty"`
+	Limit      uint64  `json:"limit"`
+}
+
+// GetOrderBookDepth returns at most limit price levels of each side of the market from the best price
+func (f DexApi) GetOrderBookDepth(tradeToken, quoteToken types.TokenTypeId, limit int) (*apidex.RpcOrderBookDepth, error) {
+	if limit <= 0 || limit > maxOrderBookDepth {
+		limit = maxOrderBookDepth
+	}
+	fundDb, err := getVmDb(f.chain, types.AddressDexFund)
+	if err != nil {
+		return nil, err
+	}
+	marketInfo, ok := dex.GetMarketInfo(fundDb, tradeToken, quoteToken)
+	if !ok {
+		return nil, dex.TradeMarketNotExistsErr
+	}
+	latest, err := f.chain.GetLatestAccountBlock(types.AddressDexTrade)
+	if err != nil {
+		return nil, err
+	}
+	tradeDb, err := getVmDb(f.chain, types.AddressDexTrade)
+	if err != nil {
+		return nil, err
+	}
+	matcher := dex.NewMatcherWithMarketInfo(tradeDb, marketInfo)
+	buy, buyTruncated, err := matcher.GetDepthFromMarket(false, limit, maxOrderBookDepthOrders)
+	if err != nil {
+		return nil, err
+	}
+	sell, sellTruncated, err := matcher.GetDepthFromMarket(true, limit, maxOrderBookDepthOrders)
+	if err != nil {
+		return nil, err
+	}
+	latest2, err := f.chain.GetLatestAccountBlock(types.AddressDexTrade)
+	if err != nil {
+		return nil, err
+	}
+	return &apidex.RpcOrderBookDepth{
+		Buy:           apidex.PriceLevelsToRpc(buy),
+		Sell:          apidex.PriceLevelsToRpc(sell),
+		BuyTruncated:  buyTruncated,
+		SellTruncated: sellTruncated,
+		QueryStart:    latest.HashHeight(),
+		QueryEnd:      latest2.HashHeight(),
+	}, nil
+}
+
+// GetTradesForMarket returns a page of the trades of the market, by the index of the dexTrades plugin
+func (f DexApi) GetTradesForMarket(tradeToken, quoteToken types.TokenTypeId, query DexTradesQuery) (*apidex.TradesRes, error) {
+	plugin, err := f.dexTradesPlugin()
+	if err != nil {
+		return nil, err
+	}
+	fundDb, err := getVmDb(f.chain, types.AddressDexFund)
+	if err != nil {
+		return nil, err
+	}
+	marketInfo, ok := dex.GetMarketInfo(fundDb, tradeToken, quoteToken)
+	if !ok {
+		return nil, dex.TradeMarketNotExistsErr
+	}
+	return f.queryTrades(query, func(from, to uint64, before *chain_plugins.DexTradePosition, limit int) ([]*chain_plugins.DexTrade, error) {
+		return plugin.GetMarketTrades(marketInfo.MarketId, from, to, before, limit)
+	})
+}
+
+// GetTradesForAddress returns a page of the fills of the orders of the address, as the taker or the maker
+func (f DexApi) GetTradesForAddress(address types.Address, query DexTradesQuery) (*apidex.TradesRes, error) {
+	plugin, err := f.dexTradesPlugin()
+	if err != nil {
+		return nil, err
+	}
+	return f.queryTrades(query, func(from, to uint64, before *chain_plugins.DexTradePosition, limit int) ([]*chain_plugins.DexTrade, error) {
+		return plugin.GetAddressTrades(address, from, to, before, limit)
+	})
+}
+
+type dexTradesGetter func(from, to uint64, before *chain_plugins.DexTradePosition, limit int) ([]*chain_plugins.DexTrade, error)
+
+func (f DexApi) queryTrades(query DexTradesQuery, get dexTradesGetter) (*apidex.TradesRes, error) {
+	from, to, err := f.tradesHeightRange(query)
+	if err != nil {
+		return nil, err
+	}
+	var before *chain_plugins.DexTradePosition
+	if query.Cursor != nil {
+		if before, err = decodeDexTradeCursor(*query.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	limit := int(normalizePageSize(query.Limit))
+
+	result := &apidex.TradesRes{Trades: make([]*apidex.RpcTrade, 0)}
+	if from > to {
+		return result, nil
+	}
+	trades, err := get(from, to, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, trade := range trades {
+		result.Trades = append(result.Trades, apidex.TradeToRpc(trade))
+	}
+	if len(trades) == limit {
+		result.Cursor = encodeDexTradeCursor(trades[len(trades)-1].DexTradePosition)
+	}
+	return result, nil
+}
+
+// tradesHeightRange converts the bounds of the query to the snapshot heights, from > to means no trades
+func (f DexApi) tradesHeightRange(query DexTradesQuery) (uint64, uint64, error) {
+	from, to := uint64(0), ^uint64(0)
+	if query.FromHeight != nil {
+		h, err := StringToUint64(*query.FromHeight)
+		if err != nil {
+			return 0, 0, err
+		}
+		from = h
+	}
+	if query.ToHeight != nil {
+		h, err := StringToUint64(*query.ToHeight)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = h
+	}
+	if query.FromTime != nil {
+		// the first snapshot block not before FromTime
+		t := time.Unix(*query.FromTime, 0)
+		sb, err := f.chain.GetSnapshotHeaderBeforeTime(&t)
+		if err != nil {
+			return 0, 0, err
+		}
+		if sb != nil && sb.Height+1 > from {
+			from = sb.Height + 1
+		}
+	}
+	if query.ToTime != nil {
+		// the last snapshot block not after ToTime
+		t := time.Unix(*query.ToTime+1, 0)
+		sb, err := f.chain.GetSnapshotHeaderBeforeTime(&t)
+		if err != nil {
+			return 0, 0, err
+		}
+		if sb == nil {
+			return 1, 0, nil
+		}
+		if sb.Height < to {
+			to = sb.Height
+		}
+	}
+	return from, to, nil
+}
+
+func (f DexApi) dexTradesPlugin() (*chain_plugins.DexTrades, error) {
+	plugins := f.chain.Plugins()
+	if plugins == nil {
+		return nil, errors.New("config.OpenPlugins is false, api can't work")
+	}
+	plugin, ok := plugins.GetPlugin("dexTrades").(*chain_plugins.DexTrades)
+	if !ok {
+		return nil, errors.New("plugin dexTrades is not enabled")
+	}
+	return plugin, nil
+}
+
+// a dex trade cursor is the (height, index) of the last trade of a page, encoded as an opaque string
+func encodeDexTradeCursor(pos chain_plugins.DexTradePosition) *string {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf, pos.Height)
+	binary.BigEndian.PutUint32(buf[8:], pos.Index)
+	s := base64.RawURLEncoding.EncodeToString(buf)
+	return &s
+}
+
+func decodeDexTradeCursor(cursor string) (*chain_plugins.DexTradePosition, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(buf) != 12 {
+		return nil, ErrInvalidCursor
+	}
+	return &chain_plugins.DexTradePosition{
+		Height: binary.BigEndian.Uint64(buf),
+		Index:  binary.BigEndian.Uint32(buf[8:]),
+	}, nil
+}
@@ -1,8 +1,11 @@
 package api
 
 import (
+	stderrors "errors"
+
 	"github.com/vitelabs/go-vite/v2/common/db/xleveldb/errors"
 	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
 	"github.com/vitelabs/go-vite/v2/ledger/verifier"
 	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
 	"github.com/vitelabs/go-vite/v2/vm/util"
@@ -196,7 +199,28 @@ var (
 		Code:    -37013,
 	}
 
+	// -38001 ~ -38999 ledger, the message is the original error which has the details
+	ErrLedgerBlockNotFound = JsonRpc2Error{
+		Message: chain.ErrBlockNotFound.Error(),
+		Code:    -38001,
+	}
+	ErrLedgerLocationOutOfRange = JsonRpc2Error{
+		Message: chain.ErrLocationOutOfRange.Error(),
+		Code:    -38002,
+	}
+	ErrLedgerDBCorrupted = JsonRpc2Error{
+		Message: chain.ErrDBCorrupted.Error(),
+		Code:    -38003,
+	}
+	ErrLedgerRollbackTooDeep = JsonRpc2Error{
+		Message: chain.ErrRollbackTooDeep.Error(),
+		Code:    -38004,
+	}
+
 	concernedErrorMap map[string]JsonRpc2Error
+
+	// the errors of the ledger are wrapped with the details, so they are matched by errors.Is
+	ledgerErrorMap map[error]JsonRpc2Error
 )
 
 func init() {
@@ -245,6 +269,11 @@ func init() {
 	concernedErrorMap[ErrDexTradeMarketInvalidTokenPair.Error()] = ErrDexTradeMarketInvalidTokenPair
 	concernedErrorMap[ErrDexFundUserNotExists.Error()] = ErrDexFundUserNotExists
 
+	ledgerErrorMap = make(map[error]JsonRpc2Error)
+	ledgerErrorMap[chain.ErrBlockNotFound] = ErrLedgerBlockNotFound
+	ledgerErrorMap[chain.ErrLocationOutOfRange] = ErrLedgerLocationOutOfRange
+	ledgerErrorMap[chain.ErrDBCorrupted] = ErrLedgerDBCorrupted
+	ledgerErrorMap[chain.ErrRollbackTooDeep] = ErrLedgerRollbackTooDeep
 }
 
 func TryMakeConcernedError(err error) (newerr error, concerned bool) {
@@ -255,6 +284,11 @@ func TryMakeConcernedError(err error) (newerr error, concerned bool) {
 	if ok {
 		return rerr, ok
 	}
+	for target, lerr := range ledgerErrorMap {
+		if stderrors.Is(err, target) {
+			return JsonRpc2Error{Message: err.Error(), Code: lerr.Code}, true
+		}
+	}
 	return err, false
 
 }
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+func TestTryMakeConcernedError_Ledger(t *testing.T) {
+	err := fmt.Errorf("c.GetSnapshotHeaderByHeight failed. Error: %w", fmt.Errorf("%w: snapshotHeader is nil. height is 10", chain.ErrBlockNotFound))
+
+	newErr, concerned := TryMakeConcernedError(err)
+	if !concerned {
+		t.Fatal("expected concerned")
+	}
+	rpcErr, ok := newErr.(JsonRpc2Error)
+	if !ok || rpcErr.ErrorCode() != ErrLedgerBlockNotFound.Code || rpcErr.Error() != err.Error() {
+		t.Fatalf("unexpected %+v", newErr)
+	}
+
+	if _, concerned = TryMakeConcernedError(fmt.Errorf("block not found")); concerned {
+		t.Fatal("the message shouldn't be matched")
+	}
+}
@@ -0,0 +1,387 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/hexutil"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// the eth namespace maps the basic ethereum json-rpc methods onto vite account chains:
+//   - a block is a snapshot block, a transaction is an account block;
+//   - addresses are the 20 core bytes of vite addresses, 21 bytes are accepted too;
+//   - values and balances are in the default token, other tokens are shown as zero;
+//   - eth_sendRawTransaction accepts a signed and serialized vite account block;
+//   - fromBlock and toBlock of eth_getLogs are heights of the account chain of the address.
+var ethDefaultTokenId = ledger.ViteTokenId
+
+func InitEthConfig(defaultTokenId string) error {
+	if defaultTokenId == "" {
+		return nil
+	}
+	tti, err := types.HexToTokenTypeId(defaultTokenId)
+	if err != nil {
+		return err
+	}
+	ethDefaultTokenId = tti
+	return nil
+}
+
+var (
+	ErrEthBlockNotFound = errors.New("block not found")
+	ErrEthLogsAddress   = errors.New("address is required, logs are indexed by account chains")
+)
+
+type EthApi struct {
+	vite     *vite.Vite
+	chain    chain.Chain
+	ledger   *LedgerApi
+	contract *ContractApi
+	log      log15.Logger
+}
+
+func NewEthApi(vite *vite.Vite) *EthApi {
+	return &EthApi{
+		vite:     vite,
+		chain:    vite.Chain(),
+		ledger:   NewLedgerApi(vite),
+		contract: NewContractApi(vite),
+		log:      log15.New("module", "rpc_api/eth_api"),
+	}
+}
+
+func (e EthApi) String() string {
+	return "EthApi"
+}
+
+type EthCallArgs struct {
+	From *string       `json:"from"`
+	To   string        `json:"to"`
+	Data hexutil.Bytes `json:"data"`
+}
+
+type EthTransaction struct {
+	Hash             hexutil.Bytes   `json:"hash"`
+	Nonce            hexutil.Uint64  `json:"nonce"`
+	BlockHash        *hexutil.Bytes  `json:"blockHash"`
+	BlockNumber      *hexutil.Uint64 `json:"blockNumber"`
+	TransactionIndex *hexutil.Uint64 `json:"transactionIndex"`
+	From             hexutil.Bytes   `json:"from"`
+	To               *hexutil.Bytes  `json:"to"`
+	Value            *hexutil.Big    `json:"value"`
+	Gas              hexutil.Uint64  `json:"gas"`
+	GasPrice         *hexutil.Big    `json:"gasPrice"`
+	Input            hexutil.Bytes   `json:"input"`
+}
+
+type EthLog struct {
+	Address          hexutil.Bytes   `json:"address"`
+	Topics           []hexutil.Bytes `json:"topics"`
+	Data             hexutil.Bytes   `json:"data"`
+	BlockNumber      hexutil.Uint64  `json:"blockNumber"`
+	BlockHash        hexutil.Bytes   `json:"blockHash"`
+	TransactionHash  hexutil.Bytes   `json:"transactionHash"`
+	TransactionIndex hexutil.Uint64  `json:"transactionIndex"`
+	LogIndex         hexutil.Uint64  `json:"logIndex"`
+	Removed          bool            `json:"removed"`
+}
+
+type EthFilterQuery struct {
+	FromBlock string
+	ToBlock   string
+	Addresses []string
+	Topics    [][]types.Hash
+}
+
+func (q *EthFilterQuery) UnmarshalJSON(input []byte) error {
+	var raw struct {
+		FromBlock string            `json:"fromBlock"`
+		ToBlock   string            `json:"toBlock"`
+		Address   json.RawMessage   `json:"address"`
+		Topics    []json.RawMessage `json:"topics"`
+	}
+	if err := json.Unmarshal(input, &raw); err != nil {
+		return err
+	}
+	q.FromBlock, q.ToBlock = raw.FromBlock, raw.ToBlock
+
+	if len(raw.Address) > 0 && string(raw.Address) != "null" {
+		var addr string
+		if err := json.Unmarshal(raw.Address, &addr); err == nil {
+			q.Addresses = []string{addr}
+		} else if err = json.Unmarshal(raw.Address, &q.Addresses); err != nil {
+			return fmt.Errorf("invalid address: %v", err)
+		}
+	}
+
+	// a topic position is null, a topic, or a list of topics
+	q.Topics = make([][]types.Hash, len(raw.Topics))
+	for i, t := range raw.Topics {
+		if string(t) == "null" {
+			continue
+		}
+		var list []string
+		var single string
+		if err := json.Unmarshal(t, &single); err == nil {
+			list = []string{single}
+		} else if err = json.Unmarshal(t, &list); err != nil {
+			return fmt.Errorf("invalid topic %d: %v", i, err)
+		}
+		for _, s := range list {
+			b, err := hexutil.Decode(s)
+			if err != nil {
+				return fmt.Errorf("invalid topic %d: %v", i, err)
+			}
+			hash, err := types.BytesToHash(b)
+			if err != nil {
+				return fmt.Errorf("invalid topic %d: %v", i, err)
+			}
+			q.Topics[i] = append(q.Topics[i], hash)
+		}
+	}
+	return nil
+}
+
+// parseEthAddress accepts 20 core bytes or a full 21-byte vite address,
+// 20 bytes are resolved to the contract address if the contract exists
+func (e *EthApi) parseEthAddress(s string) (types.Address, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil {
+		return types.Address{}, err
+	}
+	switch len(b) {
+	case types.AddressSize:
+		return types.BytesToAddress(b)
+	case types.AddressCoreSize:
+		contractAddr, err := types.BytesToAddress(append(b, types.ContractAddrByte))
+		if err != nil {
+			return types.Address{}, err
+		}
+		meta, err := e.chain.GetContractMeta(contractAddr)
+		if err != nil {
+			return types.Address{}, err
+		}
+		if meta != nil {
+			return contractAddr, nil
+		}
+		return types.BytesToAddress(append(b, types.UserAddrByte))
+	}
+	return types.Address{}, fmt.Errorf("invalid address length %d", len(b))
+}
+
+func ethAddress(addr types.Address) hexutil.Bytes {
+	return addr.Bytes()[:types.AddressCoreSize]
+}
+
+// ethSnapshotBlock returns the snapshot block of the block tag, nil for latest and pending
+func (e *EthApi) ethSnapshotBlock(tag string) (*ledger.SnapshotBlock, error) {
+	switch tag {
+	case "", "latest", "pending":
+		return nil, nil
+	case "earliest":
+		return e.chain.GetGenesisSnapshotBlock(), nil
+	}
+	height, err := hexutil.DecodeUint64(tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number %s: %v", tag, err)
+	}
+	sb, err := e.chain.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, ErrEthBlockNotFound
+	}
+	return sb, nil
+}
+
+func (e *EthApi) ChainId() hexutil.Uint64 {
+	return hexutil.Uint64(netId)
+}
+
+func (e *EthApi) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(e.chain.GetLatestSnapshotBlock().Height)
+}
+
+// GetBalance returns the balance of the default token
+func (e *EthApi) GetBalance(address string, blockTag string) (*hexutil.Big, error) {
+	addr, err := e.parseEthAddress(address)
+	if err != nil {
+		return nil, err
+	}
+	sb, err := e.ethSnapshotBlock(blockTag)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance *big.Int
+	if sb == nil {
+		balance, err = e.chain.GetBalance(addr, ethDefaultTokenId)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		balances, err := e.chain.GetConfirmedBalanceList([]types.Address{addr}, ethDefaultTokenId, sb.Hash)
+		if err != nil {
+			return nil, err
+		}
+		balance = balances[addr]
+	}
+	if balance == nil {
+		balance = big.NewInt(0)
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+// GetTransactionCount returns the height of the account chain
+func (e *EthApi) GetTransactionCount(address string, blockTag string) (hexutil.Uint64, error) {
+	addr, err := e.parseEthAddress(address)
+	if err != nil {
+		return 0, err
+	}
+	height, err := e.chain.GetLatestAccountHeight(addr)
+	return hexutil.Uint64(height), err
+}
+
+func (e *EthApi) GetTransactionByHash(hash hexutil.Bytes) (*EthTransaction, error) {
+	blockHash, err := types.BytesToHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := e.chain.GetAccountBlockByHash(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	tx := &EthTransaction{
+		Hash:     block.Hash.Bytes(),
+		Nonce:    hexutil.Uint64(block.Height),
+		From:     ethAddress(block.AccountAddress),
+		Value:    (*hexutil.Big)(big.NewInt(0)),
+		Gas:      hexutil.Uint64(block.QuotaUsed),
+		GasPrice: (*hexutil.Big)(big.NewInt(0)),
+		Input:    block.Data,
+	}
+	if block.IsSendBlock() {
+		to := ethAddress(block.ToAddress)
+		tx.To = &to
+		if block.TokenId == ethDefaultTokenId && block.Amount != nil {
+			tx.Value = (*hexutil.Big)(block.Amount)
+		}
+	}
+
+	sb, err := e.chain.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if sb != nil {
+		sbHash := hexutil.Bytes(sb.Hash.Bytes())
+		sbHeight := hexutil.Uint64(sb.Height)
+		index := hexutil.Uint64(0)
+		tx.BlockHash, tx.BlockNumber, tx.TransactionIndex = &sbHash, &sbHeight, &index
+	}
+	return tx, nil
+}
+
+// Call runs the query of the contract at the block tag
+func (e *EthApi) Call(args EthCallArgs, blockTag string) (hexutil.Bytes, error) {
+	addr, err := e.parseEthAddress(args.To)
+	if err != nil {
+		return nil, err
+	}
+	sb, err := e.ethSnapshotBlock(blockTag)
+	if err != nil {
+		return nil, err
+	}
+	param := QueryParam{Addr: &addr, Data: args.Data}
+	if sb != nil {
+		param.SnapshotHash = &sb.Hash
+	}
+	return e.contract.Query(param)
+}
+
+// SendRawTransaction sends a signed account block serialized by vite, and returns its hash
+func (e *EthApi) SendRawTransaction(data hexutil.Bytes) (hexutil.Bytes, error) {
+	block := &ledger.AccountBlock{}
+	if err := block.Deserialize(data); err != nil {
+		return nil, fmt.Errorf("invalid account block: %v", err)
+	}
+	if !checkTxToAddressAvailable(block.ToAddress) {
+		return nil, errors.New("ToAddress is invalid")
+	}
+	if err := e.ledger.sendLedgerBlock(block); err != nil {
+		return nil, err
+	}
+	return block.Hash.Bytes(), nil
+}
+
+func (e *EthApi) GetLogs(query EthFilterQuery) ([]*EthLog, error) {
+	if len(query.Addresses) == 0 {
+		return nil, ErrEthLogsAddress
+	}
+	// zero toHeight means the latest block of the account chain
+	fromHeight, toHeight := uint64(1), uint64(0)
+	var err error
+	if query.FromBlock != "" && query.FromBlock != "earliest" {
+		if fromHeight, err = hexutil.DecodeUint64(query.FromBlock); err != nil {
+			return nil, err
+		}
+	}
+	if query.ToBlock != "" && query.ToBlock != "latest" && query.ToBlock != "pending" {
+		if toHeight, err = hexutil.DecodeUint64(query.ToBlock); err != nil {
+			return nil, err
+		}
+	}
+
+	rangeMap := make(map[string]*Range, len(query.Addresses))
+	for _, a := range query.Addresses {
+		addr, err := e.parseEthAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		rangeMap[addr.String()] = &Range{FromHeight: Uint64ToString(fromHeight), ToHeight: Uint64ToString(toHeight)}
+	}
+
+	logs, err := GetLogs(e.chain, rangeMap, query.Topics, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*EthLog, 0, len(logs))
+	var lastBlock types.Hash
+	var logIndex uint64
+	for _, l := range logs {
+		if l.AccountBlockHash != lastBlock {
+			lastBlock, logIndex = l.AccountBlockHash, 0
+		}
+		height, err := StringToUint64(l.AccountHeight)
+		if err != nil {
+			return nil, err
+		}
+		topics := make([]hexutil.Bytes, len(l.Log.Topics))
+		for i, t := range l.Log.Topics {
+			topics[i] = t.Bytes()
+		}
+		result = append(result, &EthLog{
+			Address:         ethAddress(*l.Addr),
+			Topics:          topics,
+			Data:            l.Log.Data,
+			BlockNumber:     hexutil.Uint64(height),
+			BlockHash:       l.AccountBlockHash.Bytes(),
+			TransactionHash: l.AccountBlockHash.Bytes(),
+			LogIndex:        hexutil.Uint64(logIndex),
+		})
+		logIndex++
+	}
+	return result, nil
+}
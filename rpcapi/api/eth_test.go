@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/hexutil"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+type ethMockChain struct {
+	chain.Chain
+	contract types.Address
+	balances map[types.Address]*big.Int
+}
+
+func (c *ethMockChain) GetContractMeta(addr types.Address) (*ledger.ContractMeta, error) {
+	if addr == c.contract {
+		return &ledger.ContractMeta{}, nil
+	}
+	return nil, nil
+}
+
+func (c *ethMockChain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	if tokenId != ethDefaultTokenId {
+		return nil, nil
+	}
+	return c.balances[addr], nil
+}
+
+func TestEthApi_ParseAddress(t *testing.T) {
+	user := types.Address{1, 2, 3}
+	contract := types.Address{4, 5, 6}
+	contract[types.AddressSize-1] = types.ContractAddrByte
+	e := &EthApi{chain: &ethMockChain{contract: contract}}
+
+	for _, c := range []struct {
+		input    string
+		expected types.Address
+	}{
+		{hexutil.Encode(user.Bytes()[:types.AddressCoreSize]), user},
+		{hexutil.Encode(contract.Bytes()[:types.AddressCoreSize]), contract},
+		{hexutil.Encode(contract.Bytes()), contract},
+	} {
+		addr, err := e.parseEthAddress(c.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr != c.expected {
+			t.Fatalf("%s: expected %s, got %s", c.input, c.expected, addr)
+		}
+	}
+	if _, err := e.parseEthAddress("0x0102"); err == nil {
+		t.Fatal("expected invalid address length")
+	}
+}
+
+func TestEthApi_GetBalance(t *testing.T) {
+	addr := types.Address{1}
+	e := &EthApi{chain: &ethMockChain{balances: map[types.Address]*big.Int{addr: big.NewInt(255)}}}
+
+	balance, err := e.GetBalance(hexutil.Encode(ethAddress(addr)), "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.String() != "0xff" {
+		t.Fatalf("unexpected balance %s", balance)
+	}
+	balance, err = e.GetBalance(hexutil.Encode(ethAddress(types.Address{2})), "latest")
+	if err != nil || balance.String() != "0x0" {
+		t.Fatalf("unexpected balance %v %v", balance, err)
+	}
+}
+
+func TestEthFilterQuery_UnmarshalJSON(t *testing.T) {
+	topic := "0x" + "01" + "00000000000000000000000000000000000000000000000000000000000000"
+	input := `{"fromBlock":"0x1","address":"0x0000000000000000000000000000000000000001","topics":[null,"` + topic + `",["` + topic + `","` + topic + `"]]}`
+	var q EthFilterQuery
+	if err := json.Unmarshal([]byte(input), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.FromBlock != "0x1" || len(q.Addresses) != 1 {
+		t.Fatalf("unexpected query %+v", q)
+	}
+	if len(q.Topics) != 3 || len(q.Topics[0]) != 0 || len(q.Topics[1]) != 1 || len(q.Topics[2]) != 2 {
+		t.Fatalf("unexpected topics %v", q.Topics)
+	}
+	if q.Topics[1][0] != (types.Hash{1}) {
+		t.Fatalf("unexpected topic %s", q.Topics[1][0])
+	}
+}
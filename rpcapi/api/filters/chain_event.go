@@ -0,0 +1,183 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/rpc"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+)
+
+// chainEventChanSize is the events buffered for a subscription, the subscription is stopped with an error message
+// if the client can't keep up, so the client never misses an event silently
+const chainEventChanSize = 1024
+
+// chainEventError is the type of the last message of a stopped subscription
+const chainEventError = "error"
+
+const chainEventOverflowError = "too many events pending, subscribe again"
+
+// ChainEventMsg is the event of inserting or rolling back blocks
+type ChainEventMsg struct {
+	Type           string                     `json:"type"`
+	Error          string                     `json:"error,omitempty"`
+	AccountBlocks  []*ChainEventAccountBlock  `json:"accountBlocks,omitempty"`
+	SnapshotBlocks []*ChainEventSnapshotBlock `json:"snapshotBlocks,omitempty"`
+}
+
+type ChainEventAccountBlock struct {
+	Hash          types.Hash    `json:"hash"`
+	Address       types.Address `json:"address"`
+	Height        string        `json:"height"`
+	BlockType     byte          `json:"blockType"`
+	FromBlockHash types.Hash    `json:"fromBlockHash"`
+	ToAddress     types.Address `json:"toAddress"`
+}
+
+// ChainEventSnapshotBlock carries the account blocks confirmed by the snapshot block,
+// the hash and height are empty for the unconfirmed account blocks rolled back
+type ChainEventSnapshotBlock struct {
+	Hash          *types.Hash               `json:"hash,omitempty"`
+	Height        string                    `json:"height,omitempty"`
+	AccountBlocks []*ChainEventAccountBlock `json:"accountBlocks"`
+}
+
+func newChainEventAccountBlocks(blocks []*ledger.AccountBlock) []*ChainEventAccountBlock {
+	msgs := make([]*ChainEventAccountBlock, len(blocks))
+	for i, b := range blocks {
+		msgs[i] = &ChainEventAccountBlock{
+			Hash:          b.Hash,
+			Address:       b.AccountAddress,
+			Height:        api.Uint64ToString(b.Height),
+			BlockType:     b.BlockType,
+			FromBlockHash: b.FromBlockHash,
+			ToAddress:     b.ToAddress,
+		}
+	}
+	return msgs
+}
+
+func newChainEventMsg(event chain.ChainEvent) *ChainEventMsg {
+	msg := &ChainEventMsg{
+		Type:          event.Type.String(),
+		AccountBlocks: newChainEventAccountBlocks(event.AccountBlocks),
+	}
+	for _, chunk := range event.Chunks {
+		sb := &ChainEventSnapshotBlock{AccountBlocks: newChainEventAccountBlocks(chunk.AccountBlocks)}
+		if chunk.SnapshotBlock != nil {
+			hash := chunk.SnapshotBlock.Hash
+			sb.Hash = &hash
+			sb.Height = api.Uint64ToString(chunk.SnapshotBlock.Height)
+		}
+		msg.SnapshotBlocks = append(msg.SnapshotBlocks, sb)
+	}
+	return msg
+}
+
+// NewChainEvent subscribes the events of inserting and rolling back blocks of the chain, the rolled back events carry
+// the removed blocks, so the indexers can revert them
+func (s *SubscribeApi) NewChainEvent(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewChainEvent")
+	return s.subscribeChainEvents(ctx, func(event chain.ChainEvent) interface{} {
+		return newChainEventMsg(event)
+	}, &ChainEventMsg{Type: chainEventError, Error: chainEventOverflowError}, nil)
+}
+
+// ReorgMsg is the event of rolling back blocks. RemovedSnapshotBlocks carry the account blocks confirmed by them,
+// RemovedAccountBlocks are all the account blocks removed, and Head is the latest snapshot block after rolling back.
+type ReorgMsg struct {
+	Error                 string                     `json:"error,omitempty"`
+	RemovedSnapshotBlocks []*ChainEventSnapshotBlock `json:"removedSnapshotBlocks,omitempty"`
+	RemovedAccountBlocks  []*ChainEventAccountBlock  `json:"removedAccountBlocks,omitempty"`
+	Head                  *ChainHead                 `json:"head,omitempty"`
+}
+
+type ChainHead struct {
+	Hash   types.Hash `json:"hash"`
+	Height string     `json:"height"`
+}
+
+func newReorgMsg(event chain.ChainEvent, head *ledger.SnapshotBlock) *ReorgMsg {
+	msg := &ReorgMsg{
+		RemovedAccountBlocks: newChainEventAccountBlocks(event.AccountBlocks),
+		Head:                 &ChainHead{Hash: head.Hash, Height: api.Uint64ToString(head.Height)},
+	}
+	for _, sb := range newChainEventMsg(event).SnapshotBlocks {
+		msg.RemovedAccountBlocks = append(msg.RemovedAccountBlocks, sb.AccountBlocks...)
+		if sb.Hash != nil {
+			msg.RemovedSnapshotBlocks = append(msg.RemovedSnapshotBlocks, sb)
+		}
+	}
+	return msg
+}
+
+// NewReorg subscribes the rolling back of the chain, each message carries exactly the snapshot blocks and the account
+// blocks removed and the new head, so the indexers can invalidate the affected data instead of rescanning
+func (s *SubscribeApi) NewReorg(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("NewReorg")
+	c := s.vite.Chain()
+	return s.subscribeChainEvents(ctx, func(event chain.ChainEvent) interface{} {
+		if event.Type != chain.ChunksRolledBack && event.Type != chain.AccountBlocksRolledBack {
+			return nil
+		}
+		// the callback is called after the chain is rolled back
+		return newReorgMsg(event, c.GetLatestSnapshotBlock())
+	}, &ReorgMsg{Error: chainEventOverflowError}, nil)
+}
+
+// subscribeChainEvents notifies the messages converted from the chain events, the events converted to nil are skipped.
+// The subscription is stopped with the overflow message if the client can't keep up.
+// If start is set, it's called after subscribing the chain events, and its message is notified first if not nil.
+func (s *SubscribeApi) subscribeChainEvents(ctx context.Context, convert func(event chain.ChainEvent) interface{}, overflowMsg interface{}, start func() interface{}) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	c := s.vite.Chain()
+	eventCh := make(chan interface{}, chainEventChanSize)
+	overflow := make(chan struct{})
+	subId := c.SubscribeChainEvents(func(event chain.ChainEvent) {
+		msg := convert(event)
+		if msg == nil {
+			return
+		}
+		select {
+		case eventCh <- msg:
+		default:
+			select {
+			case <-overflow:
+			default:
+				close(overflow)
+			}
+		}
+	})
+
+	go func() {
+		defer c.UnsubscribeChainEvents(subId)
+		if start != nil {
+			if msg := start(); msg != nil {
+				_ = notifier.Notify(rpcSub.ID, msg)
+			}
+		}
+		for {
+			select {
+			case msg := <-eventCh:
+				_ = notifier.Notify(rpcSub.ID, msg)
+			case <-overflow:
+				s.log.Warn("chain event subscription is too slow, stopped", "id", rpcSub.ID)
+				_ = notifier.Notify(rpcSub.ID, overflowMsg)
+				return
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
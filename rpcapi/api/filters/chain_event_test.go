@@ -0,0 +1,58 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+func TestNewReorgMsg(t *testing.T) {
+	newBlock := func(hash byte, addr types.Address, height uint64) *ledger.AccountBlock {
+		return &ledger.AccountBlock{Hash: types.Hash{hash}, AccountAddress: addr, Height: height}
+	}
+	head := &ledger.SnapshotBlock{Hash: types.Hash{10}, Height: 10}
+	sb11 := &ledger.SnapshotBlock{Hash: types.Hash{11}, Height: 11}
+	sb12 := &ledger.SnapshotBlock{Hash: types.Hash{12}, Height: 12}
+
+	// the snapshot blocks 11 and 12 are removed, the last chunk has the unconfirmed blocks
+	msg := newReorgMsg(chain.ChainEvent{
+		Type: chain.ChunksRolledBack,
+		Chunks: []*ledger.SnapshotChunk{
+			{SnapshotBlock: sb11, AccountBlocks: []*ledger.AccountBlock{newBlock(1, types.AddressGovernance, 5)}},
+			{SnapshotBlock: sb12, AccountBlocks: []*ledger.AccountBlock{newBlock(2, types.AddressGovernance, 6), newBlock(3, types.AddressQuota, 2)}},
+			{AccountBlocks: []*ledger.AccountBlock{newBlock(4, types.AddressQuota, 3)}},
+		},
+	}, head)
+
+	assert.Equal(t, &ChainHead{Hash: head.Hash, Height: "10"}, msg.Head)
+	if assert.Len(t, msg.RemovedSnapshotBlocks, 2) {
+		assert.Equal(t, sb11.Hash, *msg.RemovedSnapshotBlocks[0].Hash)
+		assert.Equal(t, "11", msg.RemovedSnapshotBlocks[0].Height)
+		assert.Len(t, msg.RemovedSnapshotBlocks[0].AccountBlocks, 1)
+		assert.Equal(t, sb12.Hash, *msg.RemovedSnapshotBlocks[1].Hash)
+		assert.Len(t, msg.RemovedSnapshotBlocks[1].AccountBlocks, 2)
+	}
+	// all the removed account blocks, including the unconfirmed ones
+	var removed []types.Hash
+	for _, b := range msg.RemovedAccountBlocks {
+		removed = append(removed, b.Hash)
+	}
+	assert.Equal(t, []types.Hash{{1}, {2}, {3}, {4}}, removed)
+	assert.Equal(t, types.AddressQuota, msg.RemovedAccountBlocks[3].Address)
+	assert.Equal(t, "3", msg.RemovedAccountBlocks[3].Height)
+
+	// only the unconfirmed account blocks are removed
+	msg = newReorgMsg(chain.ChainEvent{
+		Type:          chain.AccountBlocksRolledBack,
+		AccountBlocks: []*ledger.AccountBlock{newBlock(5, types.AddressQuota, 3)},
+	}, head)
+	assert.Empty(t, msg.RemovedSnapshotBlocks)
+	if assert.Len(t, msg.RemovedAccountBlocks, 1) {
+		assert.Equal(t, types.Hash{5}, msg.RemovedAccountBlocks[0].Hash)
+	}
+	assert.Equal(t, head.Hash, msg.Head.Hash)
+}
@@ -0,0 +1,212 @@
+package filters
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/rpc"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+)
+
+// maxConfirmationsTargets is the max number of hashes and addresses watched by a subscription
+const maxConfirmationsTargets = 1000
+
+// ConfirmationsParam selects the account blocks watched. Hashes are notified once they're confirmed by Confirmations
+// snapshot blocks, and the blocks of Addresses are notified the same way once they're confirmed after subscribing.
+type ConfirmationsParam struct {
+	Hashes        []types.Hash    `json:"hashes"`
+	Addresses     []types.Address `json:"addresses"`
+	Confirmations uint64          `json:"confirmations"`
+}
+
+// ConfirmationsMsg carries the account blocks which reached the confirmations
+type ConfirmationsMsg struct {
+	Error  string               `json:"error,omitempty"`
+	Blocks []*ConfirmationBlock `json:"blocks,omitempty"`
+}
+
+type ConfirmationBlock struct {
+	Hash           types.Hash    `json:"hash"`
+	Address        types.Address `json:"address"`
+	Height         string        `json:"height"`
+	SnapshotHash   types.Hash    `json:"snapshotHash"`
+	SnapshotHeight string        `json:"snapshotHeight"`
+	Confirmations  string        `json:"confirmations"`
+}
+
+type confirmationTarget struct {
+	block    *ledger.AccountBlock
+	snapshot *ledger.SnapshotBlock // the snapshot block confirming the block, nil if unconfirmed
+	explicit bool                  // watched by hash, kept after rolling back
+}
+
+// confirmationTracker keeps the watched account blocks until they reach the confirmations,
+// it's updated by the chain events and the confirmed-times index
+type confirmationTracker struct {
+	mu        sync.Mutex
+	depth     uint64
+	addresses map[types.Address]struct{}
+	latest    uint64
+	targets   map[types.Hash]*confirmationTarget
+}
+
+func newConfirmationTracker(param ConfirmationsParam) (*confirmationTracker, error) {
+	if param.Confirmations == 0 {
+		return nil, errors.New("confirmations should be greater than 0")
+	}
+	if len(param.Hashes) == 0 && len(param.Addresses) == 0 {
+		return nil, errors.New("empty hashes and addresses")
+	}
+	if len(param.Hashes)+len(param.Addresses) > maxConfirmationsTargets {
+		return nil, fmt.Errorf("more than %d hashes and addresses", maxConfirmationsTargets)
+	}
+	t := &confirmationTracker{
+		depth:     param.Confirmations,
+		addresses: make(map[types.Address]struct{}, len(param.Addresses)),
+		targets:   make(map[types.Hash]*confirmationTarget, len(param.Hashes)),
+	}
+	for _, addr := range param.Addresses {
+		t.addresses[addr] = struct{}{}
+	}
+	for _, hash := range param.Hashes {
+		t.targets[hash] = &confirmationTarget{explicit: true}
+	}
+	return t, nil
+}
+
+// init loads the watched hashes confirmed before subscribing, it's called after subscribing the chain events,
+// so the results of the events received in the meantime are kept
+func (t *confirmationTracker) init(c chain.Chain) interface{} {
+	t.mu.Lock()
+	hashes := make([]types.Hash, 0, len(t.targets))
+	for hash, target := range t.targets {
+		if target.explicit && target.block == nil {
+			hashes = append(hashes, hash)
+		}
+	}
+	t.mu.Unlock()
+
+	blocks := make(map[types.Hash]*ledger.AccountBlock, len(hashes))
+	snapshots := make(map[types.Hash]*ledger.SnapshotBlock, len(hashes))
+	for _, hash := range hashes {
+		block, err := c.GetAccountBlockByHash(hash)
+		if err != nil || block == nil {
+			continue
+		}
+		blocks[hash] = block
+		if sb, err := c.GetConfirmSnapshotHeaderByAbHash(hash); err == nil && sb != nil {
+			snapshots[hash] = sb
+		}
+	}
+	latest := c.GetLatestSnapshotBlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for hash, block := range blocks {
+		if target, ok := t.targets[hash]; ok && target.block == nil {
+			target.block = block
+			target.snapshot = snapshots[hash]
+		}
+	}
+	if t.latest == 0 {
+		t.latest = latest.Height
+	}
+	return t.ready()
+}
+
+func (t *confirmationTracker) onEvent(event chain.ChainEvent) interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch event.Type {
+	case chain.AccountBlocksInserted:
+		for _, block := range event.AccountBlocks {
+			if target, ok := t.targets[block.Hash]; ok && target.block == nil {
+				target.block = block
+			}
+		}
+		return nil
+	case chain.SnapshotBlocksInserted:
+		for _, chunk := range event.Chunks {
+			for _, block := range chunk.AccountBlocks {
+				target, ok := t.targets[block.Hash]
+				if !ok {
+					if _, watched := t.addresses[block.AccountAddress]; !watched {
+						continue
+					}
+					target = &confirmationTarget{}
+					t.targets[block.Hash] = target
+				}
+				target.block = block
+				target.snapshot = chunk.SnapshotBlock
+			}
+			t.latest = chunk.SnapshotBlock.Height
+		}
+		return t.ready()
+	case chain.ChunksRolledBack:
+		for _, chunk := range event.Chunks {
+			if chunk.SnapshotBlock != nil && chunk.SnapshotBlock.Height <= t.latest {
+				t.latest = chunk.SnapshotBlock.Height - 1
+			}
+		}
+		// the blocks confirmed by the removed snapshot blocks are unconfirmed again,
+		// the ones watched by address are tracked again when they're confirmed
+		for hash, target := range t.targets {
+			if target.snapshot == nil || target.snapshot.Height <= t.latest {
+				continue
+			}
+			if target.explicit {
+				target.snapshot = nil
+			} else {
+				delete(t.targets, hash)
+			}
+		}
+	}
+	return nil
+}
+
+// ready removes the blocks which reached the confirmations, it returns nil if there's no such block
+func (t *confirmationTracker) ready() interface{} {
+	var blocks []*ConfirmationBlock
+	for hash, target := range t.targets {
+		if target.block == nil || target.snapshot == nil || t.latest < target.snapshot.Height {
+			continue
+		}
+		confirmations := t.latest - target.snapshot.Height + 1
+		if confirmations < t.depth {
+			continue
+		}
+		blocks = append(blocks, &ConfirmationBlock{
+			Hash:           hash,
+			Address:        target.block.AccountAddress,
+			Height:         api.Uint64ToString(target.block.Height),
+			SnapshotHash:   target.snapshot.Hash,
+			SnapshotHeight: api.Uint64ToString(target.snapshot.Height),
+			Confirmations:  api.Uint64ToString(confirmations),
+		})
+		delete(t.targets, hash)
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return &ConfirmationsMsg{Blocks: blocks}
+}
+
+// NewConfirmations subscribes the account blocks reaching the snapshot confirmations, so the clients needn't poll
+// the confirmed times. Each block is notified once.
+func (s *SubscribeApi) NewConfirmations(ctx context.Context, param ConfirmationsParam) (*rpc.Subscription, error) {
+	s.log.Info("NewConfirmations")
+	tracker, err := newConfirmationTracker(param)
+	if err != nil {
+		return nil, err
+	}
+	c := s.vite.Chain()
+	return s.subscribeChainEvents(ctx, tracker.onEvent, &ConfirmationsMsg{Error: chainEventOverflowError}, func() interface{} {
+		return tracker.init(c)
+	})
+}
@@ -0,0 +1,110 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+func confirmedHashes(msg interface{}) []types.Hash {
+	if msg == nil {
+		return nil
+	}
+	var hashes []types.Hash
+	for _, b := range msg.(*ConfirmationsMsg).Blocks {
+		hashes = append(hashes, b.Hash)
+	}
+	return hashes
+}
+
+func snapshotInserted(height uint64, blocks ...*ledger.AccountBlock) chain.ChainEvent {
+	return chain.ChainEvent{
+		Type: chain.SnapshotBlocksInserted,
+		Chunks: []*ledger.SnapshotChunk{{
+			SnapshotBlock: &ledger.SnapshotBlock{Hash: types.Hash{byte(height)}, Height: height},
+			AccountBlocks: blocks,
+		}},
+	}
+}
+
+func TestNewConfirmationTracker(t *testing.T) {
+	_, err := newConfirmationTracker(ConfirmationsParam{Hashes: []types.Hash{{1}}})
+	assert.Error(t, err)
+	_, err = newConfirmationTracker(ConfirmationsParam{Confirmations: 1})
+	assert.Error(t, err)
+	_, err = newConfirmationTracker(ConfirmationsParam{Hashes: make([]types.Hash, maxConfirmationsTargets+1), Confirmations: 1})
+	assert.Error(t, err)
+}
+
+func TestConfirmationTracker_onEvent(t *testing.T) {
+	byHash := &ledger.AccountBlock{Hash: types.Hash{1}, AccountAddress: types.AddressQuota, Height: 1}
+	byAddr := &ledger.AccountBlock{Hash: types.Hash{2}, AccountAddress: types.AddressGovernance, Height: 7}
+	other := &ledger.AccountBlock{Hash: types.Hash{3}, AccountAddress: types.AddressQuota, Height: 2}
+
+	tracker, err := newConfirmationTracker(ConfirmationsParam{
+		Hashes:        []types.Hash{byHash.Hash},
+		Addresses:     []types.Address{byAddr.AccountAddress},
+		Confirmations: 3,
+	})
+	assert.NoError(t, err)
+	tracker.latest = 9
+
+	assert.Nil(t, tracker.onEvent(chain.ChainEvent{Type: chain.AccountBlocksInserted, AccountBlocks: []*ledger.AccountBlock{byHash}}))
+	assert.Nil(t, tracker.onEvent(snapshotInserted(10, byHash, byAddr, other)))
+	// the block not watched is skipped
+	assert.NotContains(t, tracker.targets, other.Hash)
+	assert.Nil(t, tracker.onEvent(snapshotInserted(11)))
+
+	// the snapshot block 11 is rolled back, the blocks are still confirmed by 10
+	assert.Nil(t, tracker.onEvent(chain.ChainEvent{
+		Type:   chain.ChunksRolledBack,
+		Chunks: []*ledger.SnapshotChunk{{SnapshotBlock: &ledger.SnapshotBlock{Height: 11}}},
+	}))
+	assert.Equal(t, uint64(10), tracker.latest)
+	assert.Nil(t, tracker.onEvent(snapshotInserted(11)))
+
+	msg := tracker.onEvent(snapshotInserted(12))
+	assert.ElementsMatch(t, []types.Hash{byHash.Hash, byAddr.Hash}, confirmedHashes(msg))
+	for _, b := range msg.(*ConfirmationsMsg).Blocks {
+		assert.Equal(t, "10", b.SnapshotHeight)
+		assert.Equal(t, "3", b.Confirmations)
+	}
+
+	// each block is notified once
+	assert.Empty(t, tracker.targets)
+	assert.Nil(t, tracker.onEvent(snapshotInserted(13)))
+}
+
+func TestConfirmationTracker_rollback(t *testing.T) {
+	byHash := &ledger.AccountBlock{Hash: types.Hash{1}, AccountAddress: types.AddressQuota, Height: 1}
+	byAddr := &ledger.AccountBlock{Hash: types.Hash{2}, AccountAddress: types.AddressGovernance, Height: 7}
+
+	tracker, err := newConfirmationTracker(ConfirmationsParam{
+		Hashes:        []types.Hash{byHash.Hash},
+		Addresses:     []types.Address{byAddr.AccountAddress},
+		Confirmations: 2,
+	})
+	assert.NoError(t, err)
+	tracker.latest = 9
+	assert.Nil(t, tracker.onEvent(snapshotInserted(10, byHash, byAddr)))
+
+	// the confirming snapshot block is rolled back, the block watched by hash is unconfirmed again
+	// and the block watched by address is dropped until it's confirmed again
+	assert.Nil(t, tracker.onEvent(chain.ChainEvent{
+		Type:   chain.ChunksRolledBack,
+		Chunks: []*ledger.SnapshotChunk{{SnapshotBlock: &ledger.SnapshotBlock{Height: 10}}},
+	}))
+	assert.Equal(t, uint64(9), tracker.latest)
+	if assert.Contains(t, tracker.targets, byHash.Hash) {
+		assert.Nil(t, tracker.targets[byHash.Hash].snapshot)
+	}
+	assert.NotContains(t, tracker.targets, byAddr.Hash)
+
+	assert.Nil(t, tracker.onEvent(snapshotInserted(10)))
+	assert.Nil(t, tracker.onEvent(snapshotInserted(11, byHash)))
+	assert.Equal(t, []types.Hash{byHash.Hash}, confirmedHashes(tracker.onEvent(snapshotInserted(12))))
+}
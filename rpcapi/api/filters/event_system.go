@@ -26,6 +26,7 @@ const (
 	OnroadBlocksSubscriptionV2
 	SnapshotBlocksSubscription
 	SnapshotBlocksSubscriptionV2
+	WatchOnlySubscription
 )
 
 type subscription struct {
@@ -41,6 +42,7 @@ type subscription struct {
 	accountBlockWithHeightCh chan []*AccountBlockWithHeight
 	logsCh                   chan []*Logs
 	onroadMsgCh              chan []*OnroadMsg
+	watchOnlyCh              chan []*WatchOnlyMsg
 }
 
 type EventSystem struct {
@@ -93,7 +95,7 @@ func (es *EventSystem) Stop() {
 func (es *EventSystem) eventLoop() {
 	es.log.Info("start event loop")
 	index := make(map[FilterType]map[rpc.ID]*subscription)
-	for i := LogsSubscription; i <= SnapshotBlocksSubscriptionV2; i++ {
+	for i := LogsSubscription; i <= WatchOnlySubscription; i++ {
 		index[i] = make(map[rpc.ID]*subscription)
 	}
 
@@ -224,6 +226,14 @@ func (es *EventSystem) handleAcEvent(filters map[FilterType]map[rpc.ID]*subscrip
 			f.onroadMsgCh <- onroadMsgs
 		}
 	}
+	// handle watch-only addresses
+	if len(filters[WatchOnlySubscription]) > 0 {
+		if watchMsgs := es.watchOnlyMsgs(heightMsgs, onroadMsgs); len(watchMsgs) > 0 {
+			for _, f := range filters[WatchOnlySubscription] {
+				f.watchOnlyCh <- watchMsgs
+			}
+		}
+	}
 	// handle logs
 	for _, f := range filters[LogsSubscription] {
 		var logs []*Logs
@@ -249,6 +259,23 @@ func (es *EventSystem) handleAcEvent(filters map[FilterType]map[rpc.ID]*subscrip
 	}
 }
 
+// watchOnlyMsgs picks the messages of the watch-only addresses of the wallet
+func (es *EventSystem) watchOnlyMsgs(heightMsgs map[types.Address][]*AccountBlockWithHeight, onroadMsgs map[types.Address][]*OnroadMsg) []*WatchOnlyMsg {
+	wallet := es.vite.WalletManager()
+	if wallet == nil {
+		return nil
+	}
+	var result []*WatchOnlyMsg
+	for addr, label := range wallet.WatchOnlyLabels() {
+		blocks, unreceived := heightMsgs[addr], onroadMsgs[addr]
+		if len(blocks) == 0 && len(unreceived) == 0 {
+			continue
+		}
+		result = append(result, &WatchOnlyMsg{Address: addr, Label: label, AccountBlocks: blocks, Unreceived: unreceived})
+	}
+	return result
+}
+
 func appendOnroadMsg(onroadMsgs map[types.Address][]*OnroadMsg, toAddr types.Address, hash types.Hash, closed, removed bool) map[types.Address][]*OnroadMsg {
 	if _, ok := onroadMsgs[toAddr]; !ok {
 		onroadMsgs[toAddr] = make([]*OnroadMsg, 0)
@@ -300,6 +327,7 @@ func (s *RpcSubscription) Unsubscribe() {
 			case <-s.sub.logsCh:
 			case <-s.sub.snapshotBlockCh:
 			case <-s.sub.onroadMsgCh:
+			case <-s.sub.watchOnlyCh:
 			}
 		}
 		<-s.Err()
@@ -389,6 +417,25 @@ func (es *EventSystem) SubscribeLogs(p *api.FilterParam, ch chan []*Logs, ft Fil
 	return es.subscribe(sub)
 }
 
+// SubscribeWatchOnly subscribes the account blocks and the unreceived blocks of the watch-only addresses of the wallet,
+// the addresses added later are included
+func (es *EventSystem) SubscribeWatchOnly(ch chan []*WatchOnlyMsg) *RpcSubscription {
+	sub := &subscription{
+		id:                       rpc.NewID(),
+		typ:                      WatchOnlySubscription,
+		createTime:               time.Now(),
+		installed:                make(chan struct{}),
+		err:                      make(chan error),
+		snapshotBlockCh:          make(chan []*SnapshotBlock),
+		accountBlockCh:           make(chan []*AccountBlock),
+		accountBlockWithHeightCh: make(chan []*AccountBlockWithHeight),
+		logsCh:                   make(chan []*Logs),
+		onroadMsgCh:              make(chan []*OnroadMsg),
+		watchOnlyCh:              ch,
+	}
+	return es.subscribe(sub)
+}
+
 func (es *EventSystem) subscribe(s *subscription) *RpcSubscription {
 	es.install <- s
 	<-s.installed
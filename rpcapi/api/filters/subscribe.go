@@ -97,6 +97,25 @@ type OnroadMsgV2 struct {
 	Removed  bool       `json:"removed"`
 }
 
+// OnroadBlockMsg carries the summary of the send block, the summary is empty if the send block is removed
+type OnroadBlockMsg struct {
+	Hash        types.Hash         `json:"hash"`
+	Received    bool               `json:"received"`
+	Removed     bool               `json:"removed"`
+	FromAddress *types.Address     `json:"fromAddress,omitempty"`
+	Height      string             `json:"height,omitempty"`
+	TokenId     *types.TokenTypeId `json:"tokenId,omitempty"`
+	Amount      *string            `json:"amount,omitempty"`
+}
+
+// WatchOnlyMsg carries the events of a watch-only address
+type WatchOnlyMsg struct {
+	Address       types.Address             `json:"address"`
+	Label         string                    `json:"label,omitempty"`
+	AccountBlocks []*AccountBlockWithHeight `json:"accountBlocks,omitempty"`
+	Unreceived    []*OnroadMsg              `json:"unreceived,omitempty"`
+}
+
 type AccountBlockWithHeight struct {
 	Hash      types.Hash `json:"hash"`
 	Height    uint64     `json:"height"` // Deprecated
@@ -623,16 +642,26 @@ func (s *SubscribeApi) createAccountBlockSubscriptionByAddress(ctx context.Conte
 
 // Deprecated: use subscribe_createUnreceivedBlockSubscriptionByAddress instead
 func (s *SubscribeApi) NewOnroadBlocksByAddr(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
-	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscription)
+	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscription, nil)
 }
 // Deprecated: replaced by subscribe_newUnreceivedBlockByAddress
 func (s *SubscribeApi) CreateUnreceivedBlockSubscriptionByAddress(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
-	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscriptionV2)
+	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscriptionV2, nil)
 }
 func (s *SubscribeApi) NewUnreceivedBlockByAddress(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
-	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscriptionV2)
+	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscriptionV2, nil)
 }
-func (s *SubscribeApi) createUnreceivedBlockSubscriptionByAddress(ctx context.Context, addr types.Address, ft FilterType) (*rpc.Subscription, error) {
+
+// OnroadBlocksByAddress pushes the summary of send blocks to addr when they become unreceived, received or removed
+func (s *SubscribeApi) OnroadBlocksByAddress(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
+	c := s.vite.Chain()
+	return s.createUnreceivedBlockSubscriptionByAddress(ctx, addr, OnroadBlocksSubscriptionV2, func(msgs []*OnroadMsg) interface{} {
+		return s.onroadBlockMsgs(c, msgs)
+	})
+}
+
+// createUnreceivedBlockSubscriptionByAddress notifies the onroad messages of addr, converted by convert if it's set
+func (s *SubscribeApi) createUnreceivedBlockSubscriptionByAddress(ctx context.Context, addr types.Address, ft FilterType, convert func(msgs []*OnroadMsg) interface{}) (*rpc.Subscription, error) {
 	s.log.Info("createUnreceivedBlockSubscriptionByAddress")
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -646,7 +675,9 @@ func (s *SubscribeApi) createUnreceivedBlockSubscriptionByAddress(ctx context.Co
 		for {
 			select {
 			case h := <-accountBlockHashCh:
-				if ft == OnroadBlocksSubscriptionV2 {
+				if convert != nil {
+					notifier.Notify(rpcSub.ID, convert(h))
+				} else if ft == OnroadBlocksSubscriptionV2 {
 					result := make([]*OnroadMsgV2, len(h))
 					for i, o := range h {
 						result[i] = &OnroadMsgV2{o.Hash, o.Closed, o.Removed}
@@ -668,6 +699,65 @@ func (s *SubscribeApi) createUnreceivedBlockSubscriptionByAddress(ctx context.Co
 	return rpcSub, nil
 }
 
+// WatchOnlyAddresses pushes the new or removed account blocks and the unreceived blocks of the watch-only addresses
+func (s *SubscribeApi) WatchOnlyAddresses(ctx context.Context) (*rpc.Subscription, error) {
+	s.log.Info("WatchOnlyAddresses")
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		watchOnlyCh := make(chan []*WatchOnlyMsg, 128)
+		woSub := s.eventSystem.SubscribeWatchOnly(watchOnlyCh)
+		for {
+			select {
+			case msgs := <-watchOnlyCh:
+				notifier.Notify(rpcSub.ID, msgs)
+			case <-rpcSub.Err():
+				woSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				woSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// accountBlockReader reads the send blocks of the onroad messages
+type accountBlockReader interface {
+	GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error)
+}
+
+// onroadBlockMsgs adds the summary of the send blocks to the onroad messages
+func (s *SubscribeApi) onroadBlockMsgs(c accountBlockReader, msgs []*OnroadMsg) []*OnroadBlockMsg {
+	result := make([]*OnroadBlockMsg, len(msgs))
+	for i, m := range msgs {
+		msg := &OnroadBlockMsg{Hash: m.Hash, Received: m.Closed, Removed: m.Removed}
+		result[i] = msg
+		if m.Removed {
+			continue
+		}
+		block, err := c.GetAccountBlockByHash(m.Hash)
+		if err != nil || block == nil {
+			s.log.Warn("get onroad send block fail", "hash", m.Hash, "err", err)
+			continue
+		}
+		msg.FromAddress = &block.AccountAddress
+		msg.Height = api.Uint64ToString(block.Height)
+		msg.TokenId = &block.TokenId
+		if block.Amount != nil {
+			amount := block.Amount.String()
+			msg.Amount = &amount
+		}
+	}
+	return result
+}
+
 // Deprecated: use subscribe_createVmLogSubscription instead
 func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.Subscription, error) {
 	return s.createVmLogSubscription(ctx, param.AddrRange, param.Topics, LogsSubscription)
@@ -0,0 +1,98 @@
+package filters
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/rpc"
+	"github.com/vitelabs/go-vite/v2/rpcapi/api"
+)
+
+// rangePartSize is the number of blocks or chunks in one notification of a range subscription
+const rangePartSize = 100
+
+// RangeErrorMsg is notified when a range subscription fails, no more notification follows it
+type RangeErrorMsg struct {
+	Error string `json:"error"`
+}
+
+// AccountBlocksByHeightRange streams the account blocks in [start,end] sorted by height desc, in parts of api.AccountBlocksRange.
+// The range is finished when the cursor of the part is nil.
+func (s *SubscribeApi) AccountBlocksByHeightRange(ctx context.Context, addr types.Address, start uint64, end uint64) (*rpc.Subscription, error) {
+	s.log.Info("AccountBlocksByHeightRange")
+	ledgerApi := api.NewLedgerApi(s.vite)
+	return s.createRangeSubscription(ctx, func(readCtx context.Context, cursor *string) (interface{}, *string, error) {
+		part, err := ledgerApi.GetAccountBlocksByHeightRangeByCursor(readCtx, addr, start, end, cursor, rangePartSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return part, part.Cursor, nil
+	})
+}
+
+// ChunksByHeightRange streams the snapshot chunks in [start,end] sorted by height asc, in parts of api.ChunksRange.
+// The range is finished when the cursor of the part is nil.
+func (s *SubscribeApi) ChunksByHeightRange(ctx context.Context, start uint64, end uint64) (*rpc.Subscription, error) {
+	s.log.Info("ChunksByHeightRange")
+	ledgerApi := api.NewLedgerApi(s.vite)
+	return s.createRangeSubscription(ctx, func(readCtx context.Context, cursor *string) (interface{}, *string, error) {
+		part, err := ledgerApi.GetChunksByCursor(readCtx, start, end, cursor, rangePartSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		return part, part.Cursor, nil
+	})
+}
+
+// createRangeSubscription notifies the parts one by one, so the whole range is never held in memory.
+// The reading of a part is canceled as soon as the subscription is unsubscribed or the connection is closed.
+func (s *SubscribeApi) createRangeSubscription(ctx context.Context, next func(readCtx context.Context, cursor *string) (interface{}, *string, error)) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	readCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		select {
+		case <-rpcSub.Err():
+		case <-notifier.Closed():
+		case <-readCtx.Done():
+		}
+	}()
+
+	go func() {
+		defer cancel()
+		var cursor *string
+		for {
+			select {
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			default:
+			}
+
+			part, nextCursor, err := next(readCtx, cursor)
+			if err != nil {
+				if readCtx.Err() != nil {
+					return
+				}
+				s.log.Warn("read range fail", "err", err)
+				notifier.Notify(rpcSub.ID, &RangeErrorMsg{Error: err.Error()})
+				return
+			}
+			if err = notifier.Notify(rpcSub.ID, part); err != nil {
+				return
+			}
+			if nextCursor == nil {
+				return
+			}
+			cursor = nextCursor
+		}
+	}()
+
+	return rpcSub, nil
+}
@@ -0,0 +1,55 @@
+package filters
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+type blockReader map[types.Hash]*ledger.AccountBlock
+
+func (r blockReader) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	return r[blockHash], nil
+}
+
+func TestSubscribeApi_onroadBlockMsgs(t *testing.T) {
+	send := &ledger.AccountBlock{
+		Hash:           types.Hash{1},
+		Height:         12,
+		AccountAddress: types.AddressGovernance,
+		ToAddress:      types.AddressQuota,
+		TokenId:        ledger.ViteTokenId,
+		Amount:         big.NewInt(100),
+	}
+	s := &SubscribeApi{log: log15.New()}
+	msgs := s.onroadBlockMsgs(blockReader{send.Hash: send}, []*OnroadMsg{
+		{Hash: send.Hash},
+		{Hash: send.Hash, Closed: true},
+		{Hash: types.Hash{2}, Removed: true},
+		{Hash: types.Hash{3}},
+	})
+	if !assert.Len(t, msgs, 4) {
+		return
+	}
+
+	// unreceived and received send blocks carry the summary
+	for i, received := range []bool{false, true} {
+		msg := msgs[i]
+		assert.Equal(t, send.Hash, msg.Hash)
+		assert.Equal(t, received, msg.Received)
+		assert.False(t, msg.Removed)
+		assert.Equal(t, send.AccountAddress, *msg.FromAddress)
+		assert.Equal(t, "12", msg.Height)
+		assert.Equal(t, send.TokenId, *msg.TokenId)
+		assert.Equal(t, "100", *msg.Amount)
+	}
+
+	// the summary is empty if the send block is removed or missing
+	assert.Equal(t, &OnroadBlockMsg{Hash: types.Hash{2}, Removed: true}, msgs[2])
+	assert.Equal(t, &OnroadBlockMsg{Hash: types.Hash{3}}, msgs[3])
+}
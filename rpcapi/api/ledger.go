@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 	"strconv"
@@ -220,7 +221,7 @@ func (l *LedgerApi) GetSnapshotBlocks(height interface{}, count int) ([]*Snapsho
 	return l.ledgerSnapshotBlocksToRpcBlocks(blocks)
 }
 
-func (l *LedgerApi) GetChunks(startHeight interface{}, endHeight interface{}) ([]*SnapshotChunk, error) {
+func (l *LedgerApi) GetChunks(ctx context.Context, startHeight interface{}, endHeight interface{}) ([]*SnapshotChunk, error) {
 	startHeightUint64, err := parseHeight(startHeight)
 	if err != nil {
 		return nil, err
@@ -231,7 +232,11 @@ func (l *LedgerApi) GetChunks(startHeight interface{}, endHeight interface{}) ([
 		return nil, err
 	}
 
-	chunks, err := l.chain.GetSubLedger(startHeightUint64-1, endHeightUint64)
+	if err := checkRangeSize(startHeightUint64, endHeightUint64); err != nil {
+		return nil, err
+	}
+
+	chunks, err := l.readSubLedger(ctx, startHeightUint64-1, endHeightUint64)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// Cursor paged queries are provided where index paging is unstable:
+//   GetAccountBlocksByAddressByCursor     replaces GetAccountBlocksByAddress and GetBlocksByAccAddr
+//   GetUnreceivedBlocksByAddressByCursor  replaces GetUnreceivedBlocksByAddress
+// The other paged queries are not covered: GetAccountBlocks, GetBlocksByHash, GetBlocksByHeight and GetSnapshotBlocks
+// page from a hash or a height given by the caller, which is stable already, and the height ranges are read by
+// the cursors of ledger_range.go. GetUnreceivedBlocksInBatch still pages by index.
+
+// DefaultMaxPageSize is the default max number of blocks returned by one page of cursor paged queries
+const DefaultMaxPageSize = 100
+
+var maxPageSize uint64 = DefaultMaxPageSize
+
+// InitMaxPageSize sets the max page size of cursor paged queries, 0 means DefaultMaxPageSize,
+// and the size is at most MaxRangeSize
+func InitMaxPageSize(size uint64) {
+	if size == 0 {
+		size = DefaultMaxPageSize
+	} else if size > MaxRangeSize {
+		size = MaxRangeSize
+	}
+	maxPageSize = size
+}
+
+var ErrCursorRolledBack = errors.New("the block of the cursor has been rolled back, query from the beginning")
+
+// a block cursor is a height cursor bound to the hash of the last block of a page
+func encodeBlockCursor(height uint64, hash types.Hash) *string {
+	return encodeCursor(height, hash.Bytes())
+}
+
+func decodeBlockCursor(cursor string) (uint64, types.Hash, error) {
+	height, extra, err := decodeCursor(cursor, types.HashSize)
+	if err != nil {
+		return 0, types.Hash{}, err
+	}
+	hash, err := types.BytesToHash(extra)
+	if err != nil {
+		return 0, types.Hash{}, ErrInvalidCursor
+	}
+	return height, hash, nil
+}
+
+// a hash cursor is a block cursor of the last unreceived block of a page, whose height is not used
+func encodeHashCursor(hash types.Hash) *string {
+	return encodeBlockCursor(0, hash)
+}
+
+func decodeHashCursor(cursor string) (types.Hash, error) {
+	height, hash, err := decodeBlockCursor(cursor)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	if height != 0 {
+		return types.Hash{}, ErrInvalidCursor
+	}
+	return hash, nil
+}
+
+func normalizePageSize(size uint64) uint64 {
+	if size == 0 || size > maxPageSize {
+		return maxPageSize
+	}
+	return size
+}
+
+// GetAccountBlocksByAddressByCursor returns a page of at most size account blocks of addr sorted by height desc,
+// starting from the latest block if the cursor is nil, otherwise from the block before the cursor.
+//
+// Unlike the index of GetAccountBlocksByAddress, the cursor is bound to the (height, hash) of the last block of the page,
+// so pages are not shifted by new blocks, and ErrCursorRolledBack is returned if that block has been rolled back.
+func (l *LedgerApi) GetAccountBlocksByAddressByCursor(ctx context.Context, addr types.Address, cursor *string, size uint64) (*AccountBlocksRange, error) {
+	size = normalizePageSize(size)
+
+	var end uint64
+	var prevHash *types.Hash
+	if cursor == nil {
+		height, err := l.chain.GetLatestAccountHeight(addr)
+		if err != nil {
+			return nil, err
+		}
+		end = height
+	} else {
+		height, hash, err := decodeBlockCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		if height == 0 {
+			return nil, ErrInvalidCursor
+		}
+		block, err := l.chain.GetAccountBlockByHeight(addr, height)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil || block.Hash != hash {
+			return nil, ErrCursorRolledBack
+		}
+		end = height - 1
+		prevHash = &block.PrevHash
+	}
+	if end == 0 {
+		return &AccountBlocksRange{}, nil
+	}
+
+	start := uint64(1)
+	if end > size {
+		start = end - size + 1
+	}
+	list, err := l.readAccountBlocksByRange(ctx, addr, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		if prevHash != nil {
+			return nil, ErrCursorRolledBack
+		}
+		return &AccountBlocksRange{}, nil
+	}
+	// the blocks before the cursor may be rolled back between the two reads
+	if prevHash != nil && list[0].Hash != *prevHash {
+		return nil, ErrCursorRolledBack
+	}
+
+	blocks, err := l.ledgerBlocksToRpcBlocks(list)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountBlocksRange{List: blocks}
+	if last := list[len(list)-1]; last.Height > 1 {
+		result.Cursor = encodeBlockCursor(last.Height, last.Hash)
+	}
+	return result, nil
+}
+
+// GetUnreceivedBlocksByAddressByCursor returns a page of at most size unreceived blocks of addr sorted by hash,
+// starting from the first one if the cursor is nil, otherwise from the block after the cursor.
+//
+// Unlike the index of GetUnreceivedBlocksByAddress, the cursor is bound to the hash of the last block of the page,
+// so pages are not shifted by the blocks received meanwhile.
+func (l *LedgerApi) GetUnreceivedBlocksByAddressByCursor(addr types.Address, cursor *string, size uint64) (*AccountBlocksRange, error) {
+	size = normalizePageSize(size)
+
+	var after *types.Hash
+	if cursor != nil {
+		hash, err := decodeHashCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		after = &hash
+	}
+
+	// read one more block to know whether there is a next page
+	list, err := l.chain.GetOnRoadBlocksByAddrAfter(addr, after, int(size)+1)
+	if err != nil {
+		return nil, err
+	}
+	more := uint64(len(list)) > size
+	if more {
+		list = list[:size]
+	}
+
+	blocks, err := l.ledgerBlocksToRpcBlocks(list)
+	if err != nil {
+		return nil, err
+	}
+	result := &AccountBlocksRange{List: blocks}
+	if more {
+		result.Cursor = encodeHashCursor(list[len(list)-1].Hash)
+	}
+	return result, nil
+}
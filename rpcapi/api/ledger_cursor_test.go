@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+type onRoadMockChain struct {
+	rangeMockChain
+	// sorted by hash
+	onRoad []*ledger.AccountBlock
+}
+
+func (c *onRoadMockChain) GetOnRoadBlocksByAddrAfter(addr types.Address, after *types.Hash, size int) ([]*ledger.AccountBlock, error) {
+	var list []*ledger.AccountBlock
+	for _, block := range c.onRoad {
+		if len(list) < size && (after == nil || bytes.Compare(block.Hash.Bytes(), after.Bytes()) > 0) {
+			list = append(list, block)
+		}
+	}
+	return list, nil
+}
+
+func (c *onRoadMockChain) GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+
+func TestBlockCursor(t *testing.T) {
+	hash := types.Hash{1, 2, 3}
+	height, h, err := decodeBlockCursor(*encodeBlockCursor(12345, hash))
+	if err != nil || height != 12345 || h != hash {
+		t.Fatalf("unexpected %d %s %v", height, h, err)
+	}
+	if _, _, err = decodeBlockCursor(*encodeHeightCursor(1)); err != ErrInvalidCursor {
+		t.Fatalf("expected invalid cursor, got %v", err)
+	}
+}
+
+func TestLedgerApi_GetAccountBlocksByAddressByCursor(t *testing.T) {
+	c := &rangeMockChain{addr: types.Address{1}, height: 250}
+	l := &LedgerApi{chain: c}
+
+	first, err := l.GetAccountBlocksByAddressByCursor(context.Background(), c.addr, nil, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.List) != DefaultMaxPageSize {
+		t.Fatalf("expected page size %d, got %d", DefaultMaxPageSize, len(first.List))
+	}
+
+	// new blocks don't shift the pages
+	c.height = 300
+	var cursor = first.Cursor
+	next := uint64(250 - DefaultMaxPageSize)
+	for cursor != nil {
+		page, err := l.GetAccountBlocksByAddressByCursor(context.Background(), c.addr, cursor, 60)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range page.List {
+			if block.Height != Uint64ToString(next) {
+				t.Fatalf("expected height %d, got %s", next, block.Height)
+			}
+			next--
+		}
+		cursor = page.Cursor
+	}
+	if next != 0 {
+		t.Fatalf("pages are not finished, next height %d", next)
+	}
+
+	// the block of the cursor is rolled back and replaced
+	c.forkHeight = 100
+	if _, err = l.GetAccountBlocksByAddressByCursor(context.Background(), c.addr, first.Cursor, 10); err != ErrCursorRolledBack {
+		t.Fatalf("expected rolled back, got %v", err)
+	}
+}
+
+func TestLedgerApi_GetUnreceivedBlocksByAddressByCursor(t *testing.T) {
+	c := &onRoadMockChain{}
+	for i := 0; i < 25; i++ {
+		c.onRoad = append(c.onRoad, &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Hash:           types.Hash{byte(i)},
+			Height:         1,
+			AccountAddress: types.Address{2},
+			ToAddress:      types.Address{1},
+		})
+	}
+	l := &LedgerApi{chain: c}
+
+	first, err := l.GetUnreceivedBlocksByAddressByCursor(types.Address{1}, nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.List) != 10 || first.Cursor == nil {
+		t.Fatalf("unexpected first page %d %v", len(first.List), first.Cursor)
+	}
+
+	// the received blocks don't shift the pages
+	c.onRoad = c.onRoad[5:]
+	next := byte(10)
+	cursor := first.Cursor
+	for cursor != nil {
+		page, err := l.GetUnreceivedBlocksByAddressByCursor(types.Address{1}, cursor, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range page.List {
+			if block.Hash != (types.Hash{next}) {
+				t.Fatalf("expected hash %d, got %s", next, block.Hash)
+			}
+			next++
+		}
+		cursor = page.Cursor
+	}
+	if next != 25 {
+		t.Fatalf("pages are not finished, next hash %d", next)
+	}
+
+	if _, err = l.GetUnreceivedBlocksByAddressByCursor(types.Address{1}, encodeBlockCursor(1, types.Hash{}), 10); err != ErrInvalidCursor {
+		t.Fatalf("expected invalid cursor, got %v", err)
+	}
+}
+
+func TestInitMaxPageSize(t *testing.T) {
+	defer InitMaxPageSize(0)
+	for _, c := range []struct{ size, expected uint64 }{
+		{0, DefaultMaxPageSize},
+		{500, 500},
+		{MaxRangeSize + 1, MaxRangeSize},
+	} {
+		InitMaxPageSize(c.size)
+		if maxPageSize != c.expected {
+			t.Fatalf("size %d: max page size is %d, expected %d", c.size, maxPageSize, c.expected)
+		}
+	}
+}
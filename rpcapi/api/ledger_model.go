@@ -79,9 +79,34 @@ type AccountBlock struct {
 	ReceiveBlockHeight *string     `json:"receiveBlockHeight"`
 	ReceiveBlockHash   *types.Hash `json:"receiveBlockHash"`
 
+	// ExecutionError is the reason why the receive block of a contract failed,
+	// only known by the nodes which executed the block
+	ExecutionError *ExecutionErrorInfo `json:"executionError,omitempty"`
+
 	Timestamp int64 `json:"timestamp"`
 }
 
+type ExecutionErrorInfo struct {
+	Error      string `json:"error"`
+	RevertData []byte `json:"revertData"`
+	// Reason is the message of Error(string) in the revert data
+	Reason string `json:"reason,omitempty"`
+	// PanicCode is the code of Panic(uint256) in the revert data
+	PanicCode *string `json:"panicCode,omitempty"`
+}
+
+func executionErrorToInfo(e *ledger.ExecutionError) *ExecutionErrorInfo {
+	if e == nil {
+		return nil
+	}
+	return &ExecutionErrorInfo{
+		Error:      e.Error,
+		RevertData: e.RevertData,
+		Reason:     e.Reason(),
+		PanicCode:  bigIntToString(e.PanicCode()),
+	}
+}
+
 type SnapshotBlock struct {
 	Producer types.Address `json:"producer"`
 	*ledger.SnapshotBlock
@@ -245,6 +270,15 @@ func (block *AccountBlock) addExtraInfo(chain chain.Chain) error {
 		}
 	}
 
+	// ExecutionError
+	if ledger.IsReceiveBlock(block.BlockType) && types.IsContractAddr(block.AccountAddress) {
+		executionError, err := chain.GetExecutionError(block.Hash)
+		if err != nil {
+			return err
+		}
+		block.ExecutionError = executionErrorToInfo(executionError)
+	}
+
 	// ConfirmedTimes & ConfirmedHash
 	latestSb := chain.GetLatestSnapshotBlock()
 	confirmedBlock, err := chain.GetConfirmSnapshotHeaderByAbHash(block.Hash)
@@ -0,0 +1,200 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// MaxRangeSize is the max number of blocks or chunks returned by one range query,
+// larger ranges are read by cursors or by subscriptions.
+const MaxRangeSize = 1000
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// AccountBlocksRange is a part of the account blocks in a height range,
+// Cursor is nil if the range is finished, otherwise pass it to get the next part.
+type AccountBlocksRange struct {
+	List   []*AccountBlock `json:"list"`
+	Cursor *string         `json:"cursor"`
+}
+
+// ChunksRange is a part of the snapshot chunks in a height range,
+// Cursor is nil if the range is finished, otherwise pass it to get the next part.
+type ChunksRange struct {
+	List   []*SnapshotChunkV2 `json:"list"`
+	Cursor *string            `json:"cursor"`
+}
+
+// a height cursor is the height where the next part starts, encoded as an opaque string
+func encodeHeightCursor(height uint64) *string {
+	return encodeCursor(height, nil)
+}
+
+func decodeHeightCursor(cursor string) (uint64, error) {
+	height, _, err := decodeCursor(cursor, 0)
+	return height, err
+}
+
+// encodeCursor encodes the height followed by the extra bytes bound to it
+func encodeCursor(height uint64, extra []byte) *string {
+	buf := make([]byte, 8+len(extra))
+	binary.BigEndian.PutUint64(buf, height)
+	copy(buf[8:], extra)
+	s := base64.RawURLEncoding.EncodeToString(buf)
+	return &s
+}
+
+// decodeCursor decodes the height and extraSize bytes bound to it
+func decodeCursor(cursor string, extraSize int) (uint64, []byte, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(buf) != 8+extraSize {
+		return 0, nil, ErrInvalidCursor
+	}
+	return binary.BigEndian.Uint64(buf), buf[8:], nil
+}
+
+func checkRangeSize(start, end uint64) error {
+	if end >= start && end-start >= MaxRangeSize {
+		return fmt.Errorf("range must be less than %d, query it by cursor or subscription", MaxRangeSize)
+	}
+	return nil
+}
+
+func normalizeRangeSize(size uint64) uint64 {
+	if size == 0 || size > MaxRangeSize {
+		return MaxRangeSize
+	}
+	return size
+}
+
+// GetAccountBlocksByHeightRangeByCursor returns at most size account blocks in [start,end] sorted by height desc,
+// starting from the cursor, or from end if the cursor is nil.
+func (l *LedgerApi) GetAccountBlocksByHeightRangeByCursor(ctx context.Context, addr types.Address, start uint64, end uint64, cursor *string, size uint64) (*AccountBlocksRange, error) {
+	if cursor != nil {
+		next, err := decodeHeightCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		if next > end {
+			return nil, ErrInvalidCursor
+		}
+		end = next
+	}
+	if start == 0 {
+		start = 1
+	}
+	if start > end {
+		return &AccountBlocksRange{}, nil
+	}
+
+	size = normalizeRangeSize(size)
+	partStart := start
+	if end-start >= size {
+		partStart = end - size + 1
+	}
+	list, err := l.readAccountBlocksByRange(ctx, addr, partStart, end)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := l.ledgerBlocksToRpcBlocks(list)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AccountBlocksRange{List: blocks}
+	if partStart > start {
+		result.Cursor = encodeHeightCursor(partStart - 1)
+	}
+	return result, nil
+}
+
+// GetChunksByCursor returns at most size snapshot chunks in [startHeight,endHeight] sorted by height asc,
+// starting from the cursor, or from startHeight if the cursor is nil.
+func (l *LedgerApi) GetChunksByCursor(ctx context.Context, startHeight interface{}, endHeight interface{}, cursor *string, size uint64) (*ChunksRange, error) {
+	start, err := parseHeight(startHeight)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseHeight(endHeight)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		next, err := decodeHeightCursor(*cursor)
+		if err != nil {
+			return nil, err
+		}
+		if next < start {
+			return nil, ErrInvalidCursor
+		}
+		start = next
+	}
+	if start == 0 {
+		start = 1
+	}
+	if latest := l.chain.GetLatestSnapshotBlock().Height; end > latest {
+		end = latest
+	}
+	if start > end {
+		return &ChunksRange{}, nil
+	}
+
+	size = normalizeRangeSize(size)
+	partEnd := end
+	if end-start >= size {
+		partEnd = start + size - 1
+	}
+	chunks, err := l.readSubLedger(ctx, start-1, partEnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) > 0 {
+		if chunks[0].SnapshotBlock == nil || chunks[0].SnapshotBlock.Height == start-1 {
+			chunks = chunks[1:]
+		}
+	}
+	list, err := l.ledgerChunksToRpcChunksV2(chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ChunksRange{List: list}
+	if partEnd < end {
+		result.Cursor = encodeHeightCursor(partEnd + 1)
+	}
+	return result, nil
+}
+
+// readAccountBlocksByRange reads the account blocks in [start,end] sorted by height desc,
+// the reading stops when the request is canceled or timed out
+func (l *LedgerApi) readAccountBlocksByRange(ctx context.Context, addr types.Address, start uint64, end uint64) ([]*ledger.AccountBlock, error) {
+	var list []*ledger.AccountBlock
+	err := l.chain.StreamAccountBlocksByRange(ctx, addr, start, end, func(block *ledger.AccountBlock) error {
+		list = append(list, block)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// readSubLedger reads the snapshot chunks in [start,end] sorted by height asc,
+// the reading stops when the request is canceled or timed out
+func (l *LedgerApi) readSubLedger(ctx context.Context, start uint64, end uint64) ([]*ledger.SnapshotChunk, error) {
+	var chunks []*ledger.SnapshotChunk
+	err := l.chain.StreamSubLedger(ctx, start, end, func(chunk *ledger.SnapshotChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
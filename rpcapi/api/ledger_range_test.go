@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+)
+
+type rangeMockChain struct {
+	chain.Chain
+	addr   types.Address
+	height uint64
+
+	// blocks not lower than forkHeight are replaced by a fork
+	forkHeight uint64
+}
+
+func (c *rangeMockChain) block(addr types.Address, h uint64) *ledger.AccountBlock {
+	hash := func(h uint64) types.Hash {
+		if c.forkHeight > 0 && h >= c.forkHeight {
+			return types.Hash{byte(h >> 8), byte(h), 1}
+		}
+		return types.Hash{byte(h >> 8), byte(h)}
+	}
+	return &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           hash(h),
+		PrevHash:       hash(h - 1),
+		Height:         h,
+		AccountAddress: addr,
+	}
+}
+
+func (c *rangeMockChain) GetAccountBlocksByRange(addr types.Address, start uint64, end uint64) ([]*ledger.AccountBlock, error) {
+	var list []*ledger.AccountBlock
+	for h := end; h >= start && h <= c.height; h-- {
+		list = append(list, c.block(addr, h))
+	}
+	return list, nil
+}
+
+func (c *rangeMockChain) StreamAccountBlocksByRange(ctx context.Context, addr types.Address, start uint64, end uint64, fn func(block *ledger.AccountBlock) error) error {
+	for h := end; h >= start && h <= c.height; h-- {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(c.block(addr, h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *rangeMockChain) GetAccountBlockByHeight(addr types.Address, height uint64) (*ledger.AccountBlock, error) {
+	if height == 0 || height > c.height {
+		return nil, nil
+	}
+	return c.block(addr, height), nil
+}
+
+func (c *rangeMockChain) GetLatestAccountHeight(addr types.Address) (uint64, error) {
+	return c.height, nil
+}
+
+func (c *rangeMockChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+
+func (c *rangeMockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return &ledger.SnapshotBlock{Height: 1}
+}
+
+func (c *rangeMockChain) GetConfirmSnapshotHeaderByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func TestHeightCursor(t *testing.T) {
+	height, err := decodeHeightCursor(*encodeHeightCursor(12345))
+	if err != nil || height != 12345 {
+		t.Fatalf("unexpected %d %v", height, err)
+	}
+	if _, err = decodeHeightCursor("not a cursor"); err != ErrInvalidCursor {
+		t.Fatalf("expected invalid cursor, got %v", err)
+	}
+}
+
+func TestLedgerApi_GetAccountBlocksByHeightRangeByCursor(t *testing.T) {
+	c := &rangeMockChain{addr: types.Address{1}, height: 250}
+	l := &LedgerApi{chain: c}
+
+	if _, err := l.GetAccountBlocksByHeightRange(context.Background(), c.addr, 1, MaxRangeSize+1); err == nil {
+		t.Fatal("expected range size error")
+	}
+
+	var cursor *string
+	next := uint64(240)
+	for i := 0; ; i++ {
+		part, err := l.GetAccountBlocksByHeightRangeByCursor(context.Background(), c.addr, 5, 240, cursor, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range part.List {
+			if block.Height != Uint64ToString(next) {
+				t.Fatalf("part %d, expected height %d, got %s", i, next, block.Height)
+			}
+			next--
+		}
+		if part.Cursor == nil {
+			break
+		}
+		cursor = part.Cursor
+	}
+	if next != 4 {
+		t.Fatalf("range is not finished, next height %d", next)
+	}
+}
+
+func TestLedgerApi_GetAccountBlocksByHeightRangeCanceled(t *testing.T) {
+	c := &rangeMockChain{addr: types.Address{1}, height: 250}
+	l := &LedgerApi{chain: c}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.GetAccountBlocksByHeightRangeByCursor(ctx, c.addr, 5, 240, nil, 100); err != context.Canceled {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// the status of the execution of a send block by its receive block
+const (
+	ReceiveStatusUnreceived    = "unreceived"
+	ReceiveStatusSuccess       = "success"
+	ReceiveStatusFailed        = "failed"
+	ReceiveStatusDepthExceeded = "depthExceeded"
+	ReceiveStatusUnknown       = "unknown"
+)
+
+// ReceiveInfo is the receive block paired with a send block, the receive fields are empty if it's not received
+type ReceiveInfo struct {
+	SendBlockHash      types.Hash          `json:"sendBlockHash"`
+	ToAddress          types.Address       `json:"toAddress"`
+	Received           bool                `json:"received"`
+	ReceiveBlockHash   *types.Hash         `json:"receiveBlockHash,omitempty"`
+	ReceiveBlockHeight *string             `json:"receiveBlockHeight,omitempty"`
+	Status             string              `json:"status"`
+	ExecutionError     *ExecutionErrorInfo `json:"executionError,omitempty"`
+	Confirmations      *string             `json:"confirmations,omitempty"`
+}
+
+// GetReceiveInfo returns whether the send block is received, by which receive block, and the execution status.
+// The pairing is read from the receive index which is written when the receive block is inserted,
+// the unconfirmed receive blocks are included and Confirmations is nil for them.
+func (l *LedgerApi) GetReceiveInfo(sendBlockHash types.Hash) (*ReceiveInfo, error) {
+	sendBlock, err := l.chain.GetAccountBlockByHash(sendBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil {
+		return nil, errors.New("send block not found")
+	}
+	if !sendBlock.IsSendBlock() {
+		return nil, errors.New("not a send block")
+	}
+
+	info := &ReceiveInfo{
+		SendBlockHash: sendBlockHash,
+		ToAddress:     sendBlock.ToAddress,
+		Status:        ReceiveStatusUnreceived,
+	}
+	receiveBlock, err := l.chain.GetReceiveAbBySendAb(sendBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if receiveBlock == nil {
+		return info, nil
+	}
+
+	height := strconv.FormatUint(receiveBlock.Height, 10)
+	info.Received = true
+	info.ReceiveBlockHash = &receiveBlock.Hash
+	info.ReceiveBlockHeight = &height
+	info.Status = receiveStatus(receiveBlock)
+
+	if types.IsContractAddr(receiveBlock.AccountAddress) {
+		executionError, err := l.chain.GetExecutionError(receiveBlock.Hash)
+		if err != nil {
+			return nil, err
+		}
+		info.ExecutionError = executionErrorToInfo(executionError)
+	}
+
+	confirmSb, err := l.chain.GetConfirmSnapshotHeaderByAbHash(receiveBlock.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if latestSb := l.chain.GetLatestSnapshotBlock(); confirmSb != nil && latestSb != nil && confirmSb.Height <= latestSb.Height {
+		confirmations := strconv.FormatUint(latestSb.Height-confirmSb.Height+1, 10)
+		info.Confirmations = &confirmations
+	}
+	return info, nil
+}
+
+// receiveStatus reads the status from the result byte at the end of the data of a contract receive block,
+// the receive blocks of the user accounts always succeed
+func receiveStatus(receiveBlock *ledger.AccountBlock) string {
+	if !types.IsContractAddr(receiveBlock.AccountAddress) {
+		return ReceiveStatusSuccess
+	}
+	if len(receiveBlock.Data) != types.HashSize+1 {
+		return ReceiveStatusUnknown
+	}
+	switch receiveBlock.Data[types.HashSize] {
+	case 0:
+		return ReceiveStatusSuccess
+	case 1:
+		return ReceiveStatusFailed
+	case 2:
+		return ReceiveStatusDepthExceeded
+	}
+	return ReceiveStatusUnknown
+}
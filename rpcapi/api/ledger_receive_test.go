@@ -0,0 +1,33 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func TestReceiveStatus(t *testing.T) {
+	user, _, _ := types.CreateAddress()
+	result := func(b byte) []byte {
+		return append(make([]byte, types.HashSize), b)
+	}
+	testCases := []struct {
+		addr   types.Address
+		data   []byte
+		status string
+	}{
+		{user, nil, ReceiveStatusSuccess},
+		{types.AddressQuota, result(0), ReceiveStatusSuccess},
+		{types.AddressQuota, result(1), ReceiveStatusFailed},
+		{types.AddressQuota, result(2), ReceiveStatusDepthExceeded},
+		{types.AddressQuota, result(3), ReceiveStatusUnknown},
+		{types.AddressQuota, nil, ReceiveStatusUnknown},
+	}
+	for i, tc := range testCases {
+		block := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: tc.addr, Data: tc.data}
+		if status := receiveStatus(block); status != tc.status {
+			t.Errorf("%d: expected %s, got %s", i, tc.status, status)
+		}
+	}
+}
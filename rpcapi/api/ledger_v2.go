@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
@@ -104,6 +105,9 @@ func (l *LedgerApi) GetAccountBlockByHeight(addr types.Address, height interface
 }
 
 // new api
+//
+// Deprecated: pages of index shift when new blocks are inserted or rolled back,
+// use GetAccountBlocksByAddressByCursor instead.
 func (l *LedgerApi) GetAccountBlocksByAddress(addr types.Address, index int, count int) ([]*AccountBlock, error) {
 	l.log.Info("GetAccountBlocksByAddress", "addr", addr, "index", index, "count", count)
 	if count > 1000 {
@@ -136,9 +140,11 @@ func (l *LedgerApi) GetAccountBlocksByAddress(addr types.Address, index int, cou
 }
 
 // GetAccountBlocksByHeightRange [start,end] sorted by height desc
-func (l *LedgerApi) GetAccountBlocksByHeightRange(addr types.Address, start uint64, end uint64) ([]*AccountBlock, error) {
-	list, err := l.chain.GetAccountBlocksByRange(addr, start, end)
-
+func (l *LedgerApi) GetAccountBlocksByHeightRange(ctx context.Context, addr types.Address, start uint64, end uint64) ([]*AccountBlock, error) {
+	if err := checkRangeSize(start, end); err != nil {
+		return nil, err
+	}
+	list, err := l.readAccountBlocksByRange(ctx, addr, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +239,56 @@ func (l *LedgerApi) GetVmLogs(blockHash types.Hash) (ledger.VmLogList, error) {
 		return nil, errors.New("get block failed")
 	}
 
-	return l.chain.GetVmLogList(block.LogHash)
+	list, err := l.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		return nil, err
+	}
+	if block.LogHash != nil && list == nil {
+		return nil, vmLogsNotSavedError(l.chain, block)
+	}
+	return list, nil
+}
+
+// vmLogsNotSavedError explains why the vm logs of the block are missing, they are either pruned
+// or not saved by the ledger mode
+func vmLogsNotSavedError(c chain.Chain, block *ledger.AccountBlock) error {
+	if sb, err := c.GetConfirmSnapshotHeaderByAbHash(block.Hash); err == nil && sb != nil {
+		if err := c.CheckHistoryHeight(sb.Height); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("the vm logs of %s are not saved in %s mode, add the address to VmLogWhiteList or run in archive mode",
+		block.AccountAddress, c.Mode())
+}
+
+type ExecutionResult struct {
+	VmLogList      ledger.VmLogList    `json:"vmLogList"`
+	ExecutionError *ExecutionErrorInfo `json:"executionError"`
+}
+
+// GetExecutionResult returns the vm logs of the block, and the reason why the block failed if it is a failed
+// receive block of a contract. A failed receive block has no vm logs.
+func (l *LedgerApi) GetExecutionResult(blockHash types.Hash) (*ExecutionResult, error) {
+	block, err := l.chain.GetAccountBlockByHash(blockHash)
+	if block == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New("get block failed")
+	}
+
+	logList, err := l.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		return nil, err
+	}
+	executionError, err := l.chain.GetExecutionError(blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{
+		VmLogList:      logList,
+		ExecutionError: executionErrorToInfo(executionError),
+	}, nil
 }
 
 // new api
@@ -249,6 +304,11 @@ func (l *LedgerApi) SendRawTransaction(block *AccountBlock) error {
 	if err != nil {
 		return err
 	}
+	return l.sendLedgerBlock(lb)
+}
+
+// sendLedgerBlock verifies the signed block and adds it to the pool
+func (l *LedgerApi) sendLedgerBlock(lb *ledger.AccountBlock) error {
 	if err := checkTokenIdValid(l.chain, &lb.TokenId); err != nil {
 		return err
 	}
@@ -276,6 +336,9 @@ func (l *LedgerApi) SendRawTransaction(block *AccountBlock) error {
 }
 
 // new api: ledger_getUnreceivedBlocksByAddress <- onroad_getOnroadBlocksByAddress
+//
+// Deprecated: pages of index shift when the unreceived blocks before them are received,
+// use GetUnreceivedBlocksByAddressByCursor instead.
 func (l *LedgerApi) GetUnreceivedBlocksByAddress(address types.Address, index, count uint64) ([]*AccountBlock, error) {
 	log.Info("GetUnreceivedBlocksByAddress", "addr", address, "index", index, "count", count)
 	if count > 1000 {
@@ -697,6 +760,10 @@ func (l *LedgerApi) GetChunksV2(startHeight interface{}, endHeight interface{})
 		return nil, err
 	}
 
+	if err := checkRangeSize(startHeightUint64, endHeightUint64); err != nil {
+		return nil, err
+	}
+
 	chunks, err := l.chain.GetSubLedger(startHeightUint64-1, endHeightUint64)
 	if err != nil {
 		return nil, err
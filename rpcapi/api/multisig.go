@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/ledger/pool"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/multisig"
+)
+
+// MultisigApi builds the calls of accounts managed by multisig contracts.
+// A payload is created by NewPayload, signed by the signers with SignPayload offline or on different nodes,
+// merged by MergePayloads, and sent to the contract by SendPayload when enough signers have signed.
+type MultisigApi struct {
+	wallet    *wallet.Manager
+	chain     chain.Chain
+	pool      pool.Writer
+	consensus generator.Consensus
+	log       log15.Logger
+}
+
+func NewMultisigApi(vite *vite.Vite) *MultisigApi {
+	return &MultisigApi{
+		wallet:    vite.WalletManager(),
+		chain:     vite.Chain(),
+		pool:      vite.Pool(),
+		consensus: vite.Consensus(),
+		log:       log15.New("module", "rpc_api/multisig_api"),
+	}
+}
+
+func (m MultisigApi) String() string {
+	return "MultisigApi"
+}
+
+type NewMultisigPayloadParams struct {
+	Contract  types.Address     `json:"contract"`
+	Nonce     uint64            `json:"nonce"`
+	Threshold int               `json:"threshold"`
+	Signers   []types.Address   `json:"signers"`
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    *string           `json:"amount"`
+	Data      []byte            `json:"data"`
+}
+
+type MultisigPayloadInfo struct {
+	Payload  *multisig.Payload `json:"payload"`
+	Hash     types.Hash        `json:"hash"`
+	Signed   []types.Address   `json:"signed"`
+	Missing  []types.Address   `json:"missing"`
+	Complete bool              `json:"complete"`
+}
+
+// NewPayload creates an unsigned payload
+func (m MultisigApi) NewPayload(params NewMultisigPayloadParams) (string, error) {
+	amount := big.NewInt(0)
+	if params.Amount != nil {
+		var ok bool
+		if amount, ok = new(big.Int).SetString(*params.Amount, 10); !ok {
+			return "", ErrStrToBigInt
+		}
+	}
+	p, err := multisig.NewPayload(params.Contract, params.Nonce, params.Threshold, params.Signers, multisig.Call{
+		ToAddress: params.ToAddress,
+		TokenId:   params.TokenId,
+		Amount:    amount,
+		Data:      params.Data,
+	})
+	if err != nil {
+		return "", err
+	}
+	return p.Encode()
+}
+
+// SignPayload signs the payload by the unlocked account of addr
+func (m MultisigApi) SignPayload(payload string, addr types.Address) (string, error) {
+	p, err := multisig.DecodePayload(payload)
+	if err != nil {
+		return "", err
+	}
+	acct, err := m.wallet.Account(addr)
+	if err != nil {
+		return "", err
+	}
+	if err := p.Sign(acct); err != nil {
+		return "", err
+	}
+	return p.Encode()
+}
+
+// MergePayloads merges the signatures of the payloads of the same call
+func (m MultisigApi) MergePayloads(payloads []string) (string, error) {
+	if len(payloads) == 0 {
+		return "", errors.New("no payload")
+	}
+	p, err := multisig.DecodePayload(payloads[0])
+	if err != nil {
+		return "", err
+	}
+	for _, s := range payloads[1:] {
+		other, err := multisig.DecodePayload(s)
+		if err != nil {
+			return "", err
+		}
+		if err := p.Merge(other); err != nil {
+			return "", err
+		}
+	}
+	return p.Encode()
+}
+
+// InspectPayload decodes the payload and returns the signers who have signed or not
+func (m MultisigApi) InspectPayload(payload string) (*MultisigPayloadInfo, error) {
+	p, err := multisig.DecodePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &MultisigPayloadInfo{
+		Payload:  p,
+		Hash:     p.Hash(),
+		Signed:   p.Signed(),
+		Missing:  p.Missing(),
+		Complete: p.Complete(),
+	}, nil
+}
+
+// SendPayload sends the complete payload to the multisig contract by a send block of submitter,
+// the submitter must be an unlocked account, it needs not to be a signer.
+func (m MultisigApi) SendPayload(payload string, submitter types.Address, difficulty *string) (*types.Hash, error) {
+	p, err := multisig.DecodePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	data, err := p.ExecuteData()
+	if err != nil {
+		return nil, err
+	}
+	var diff *big.Int
+	if difficulty != nil {
+		var ok bool
+		if diff, ok = new(big.Int).SetString(*difficulty, 10); !ok {
+			return nil, ErrStrToBigInt
+		}
+	}
+	acct, err := m.wallet.Account(submitter)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &interfaces.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: submitter,
+		ToAddress:      &p.Contract,
+		TokenId:        &ledger.ViteTokenId,
+		Amount:         big.NewInt(0),
+		Difficulty:     diff,
+		Data:           data,
+	}
+	addrState, err := generator.GetAddressStateForGenerator(m.chain, &msg.AccountAddress)
+	if err != nil || addrState == nil {
+		return nil, fmt.Errorf("failed to get addr state for generator, err:%v", err)
+	}
+	g, err := generator.NewGenerator(m.chain, m.consensus, msg.AccountAddress, addrState.LatestSnapshotHash, addrState.LatestAccountHash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := g.GenerateWithMessage(msg, &msg.AccountAddress, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if result.VMBlock == nil {
+		return nil, errors.New("generator gen an empty block")
+	}
+	block := result.VMBlock.AccountBlock
+	if err := interfaces.SignAccountBlock(acct, block); err != nil {
+		return nil, err
+	}
+	m.log.Info("send multisig payload", "contract", p.Contract, "nonce", p.Nonce, "hash", block.Hash)
+	return &block.Hash, m.pool.AddDirectAccountBlock(submitter, result.VMBlock)
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"strconv"
+	"time"
 
 	"github.com/vitelabs/go-vite/v2"
 	"github.com/vitelabs/go-vite/v2/log15"
@@ -70,3 +72,88 @@ func (n *NetApi) Nodes() Nodes {
 		Count: len(nodes),
 	}
 }
+
+// Traffic returns the bytes by protocols and the messages by codes transferred since the node started
+func (n *NetApi) Traffic() net.TrafficStatus {
+	return n.net.Traffic()
+}
+
+// PrivateNetApi manages the connectivity of the node at runtime
+type PrivateNetApi struct {
+	net net.Net
+	log log15.Logger
+}
+
+func NewPrivateNetApi(vite *vite.Vite) *PrivateNetApi {
+	return &PrivateNetApi{
+		net: vite.Net(),
+		log: log15.New("module", "rpc_api/private_net_api"),
+	}
+}
+
+func (n PrivateNetApi) String() string {
+	return "PrivateNetApi"
+}
+
+// AddPeer connects the node of url "<id>@<host>:<port>", and keeps it connected
+func (n *PrivateNetApi) AddPeer(url string) error {
+	node, err := vnode.ParseNode(url)
+	if err != nil {
+		return err
+	}
+	n.log.Info("add peer", "node", url)
+	return n.net.AddPeer(node)
+}
+
+// RemovePeer disconnects the peer and stops redialing it
+func (n *PrivateNetApi) RemovePeer(id string) error {
+	nodeId, err := vnode.Hex2NodeID(id)
+	if err != nil {
+		return err
+	}
+	n.log.Info("remove peer", "id", id)
+	return n.net.RemovePeer(nodeId)
+}
+
+// BanPeer disconnects the peer and refuses it for the seconds
+func (n *PrivateNetApi) BanPeer(id string, seconds int64) error {
+	nodeId, err := vnode.Hex2NodeID(id)
+	if err != nil {
+		return err
+	}
+	if seconds <= 0 {
+		return errors.New("seconds must be positive")
+	}
+	n.log.Info("ban peer", "id", id, "seconds", seconds)
+	return n.net.BanPeer(nodeId, time.Duration(seconds)*time.Second)
+}
+
+// TrustPeer connects the node of url, the peer is kept connected beyond the peer limits and never banned
+func (n *PrivateNetApi) TrustPeer(url string) error {
+	node, err := vnode.ParseNode(url)
+	if err != nil {
+		return err
+	}
+	n.log.Info("trust peer", "node", url)
+	return n.net.TrustPeer(node)
+}
+
+// UntrustPeer revokes the trust of the peer, it is still kept connected as a static peer
+func (n *PrivateNetApi) UntrustPeer(id string) error {
+	nodeId, err := vnode.Hex2NodeID(id)
+	if err != nil {
+		return err
+	}
+	n.log.Info("untrust peer", "id", id)
+	return n.net.UntrustPeer(nodeId)
+}
+
+// StaticPeers returns the static and trusted peers and whether they are connected
+func (n *PrivateNetApi) StaticPeers() []net.StaticPeer {
+	return n.net.StaticPeers()
+}
+
+// PeersInfo returns the direction, transport, head, traffic and score of the connected peers
+func (n *PrivateNetApi) PeersInfo() []net.PeerDetail {
+	return n.net.PeersInfo()
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/ledger/pool"
+)
+
+// PoolApi exposes the blocks waiting in the pool, to find out why a block is not inserted into the chain
+type PoolApi struct {
+	pool pool.BlockPool
+}
+
+func NewPoolApi(vite *vite.Vite) *PoolApi {
+	return &PoolApi{
+		pool: vite.Pool(),
+	}
+}
+
+func (p PoolApi) String() string {
+	return "PoolApi"
+}
+
+type PoolBlock struct {
+	Hash      types.Hash        `json:"hash"`
+	PrevHash  types.Hash        `json:"prevHash"`
+	Height    string            `json:"height"`
+	Source    types.BlockSource `json:"source"`
+	AgeMillis int64             `json:"ageMillis"`
+}
+
+type PoolBranch struct {
+	Id         string     `json:"id"`
+	TailHeight string     `json:"tailHeight"`
+	TailHash   types.Hash `json:"tailHash"`
+	HeadHeight string     `json:"headHeight"`
+	HeadHash   types.Hash `json:"headHash"`
+	Current    bool       `json:"current"`
+}
+
+type PoolChainSummary struct {
+	Id              string         `json:"id"`
+	Address         *types.Address `json:"address,omitempty"`
+	PendingNum      string         `json:"pendingNum"`
+	FreeNum         int            `json:"freeNum"`
+	CompoundNum     int            `json:"compoundNum"`
+	SnippetNum      int            `json:"snippetNum"`
+	BranchNum       int            `json:"branchNum"`
+	OldestAgeMillis int64          `json:"oldestAgeMillis"`
+}
+
+type PoolChainStatus struct {
+	PoolChainSummary
+	Pending  []*PoolBlock  `json:"pending"`
+	Free     []*PoolBlock  `json:"free"`
+	Snippets []*PoolBranch `json:"snippets"`
+	Branches []*PoolBranch `json:"branches"`
+}
+
+type PoolStatus struct {
+	Snapshot               *PoolChainSummary   `json:"snapshot"`
+	Accounts               []*PoolChainSummary `json:"accounts"`
+	AccountPendingNum      string              `json:"accountPendingNum"`
+	AccountFreeNum         int                 `json:"accountFreeNum"`
+	AccountCompoundNum     int                 `json:"accountCompoundNum"`
+	OldestAccountAgeMillis int64               `json:"oldestAccountAgeMillis"`
+}
+
+func durationToMillis(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func poolBlocksToRpc(list []*pool.BlockStatus) []*PoolBlock {
+	result := make([]*PoolBlock, len(list))
+	for i, b := range list {
+		result[i] = &PoolBlock{
+			Hash:      b.Hash,
+			PrevHash:  b.PrevHash,
+			Height:    Uint64ToString(b.Height),
+			Source:    b.Source,
+			AgeMillis: durationToMillis(b.Age),
+		}
+	}
+	return result
+}
+
+func poolBranchesToRpc(list []*pool.BranchStatus) []*PoolBranch {
+	result := make([]*PoolBranch, len(list))
+	for i, b := range list {
+		result[i] = &PoolBranch{
+			Id:         b.ID,
+			TailHeight: Uint64ToString(b.TailHeight),
+			TailHash:   b.TailHash,
+			HeadHeight: Uint64ToString(b.HeadHeight),
+			HeadHash:   b.HeadHash,
+			Current:    b.Current,
+		}
+	}
+	return result
+}
+
+func poolChainSummaryToRpc(s *pool.ChainSummary) *PoolChainSummary {
+	return &PoolChainSummary{
+		Id:              s.ID,
+		Address:         s.Address,
+		PendingNum:      Uint64ToString(s.PendingNum),
+		FreeNum:         s.FreeNum,
+		CompoundNum:     s.CompoundNum,
+		SnippetNum:      s.SnippetNum,
+		BranchNum:       s.BranchNum,
+		OldestAgeMillis: durationToMillis(s.OldestAge),
+	}
+}
+
+func poolChainStatusToRpc(s *pool.ChainStatus) *PoolChainStatus {
+	if s == nil {
+		return nil
+	}
+	return &PoolChainStatus{
+		PoolChainSummary: *poolChainSummaryToRpc(&s.ChainSummary),
+		Pending:          poolBlocksToRpc(s.Pending),
+		Free:             poolBlocksToRpc(s.Free),
+		Snippets:         poolBranchesToRpc(s.Snippets),
+		Branches:         poolBranchesToRpc(s.Branches),
+	}
+}
+
+// GetStatus returns the block counts and the oldest age of the snapshot chain and of every account chain
+// with blocks in the pool, account chains are ordered by the oldest age
+func (p *PoolApi) GetStatus() *PoolStatus {
+	status := p.pool.Status()
+	result := &PoolStatus{
+		Snapshot:               poolChainSummaryToRpc(status.Snapshot),
+		Accounts:               make([]*PoolChainSummary, len(status.Accounts)),
+		AccountPendingNum:      Uint64ToString(status.AccountPendingNum),
+		AccountFreeNum:         status.AccountFreeNum,
+		AccountCompoundNum:     status.AccountCompoundNum,
+		OldestAccountAgeMillis: durationToMillis(status.OldestAccountAge),
+	}
+	for i, s := range status.Accounts {
+		result.Accounts[i] = poolChainSummaryToRpc(s)
+	}
+	return result
+}
+
+// GetSnapshotChainStatus returns the snapshot blocks in the pool, the pending blocks are the candidates to be inserted
+func (p *PoolApi) GetSnapshotChainStatus() *PoolChainStatus {
+	return poolChainStatusToRpc(p.pool.SnapshotStatus())
+}
+
+// GetAccountChainStatus returns the account blocks of addr in the pool, nil if the pool has never seen addr
+func (p *PoolApi) GetAccountChainStatus(addr types.Address) *PoolChainStatus {
+	return poolChainStatusToRpc(p.pool.AccountStatus(addr))
+}
@@ -150,6 +150,90 @@ func (p *QuotaApi) GetQuotaCoefficient() (*QuotaCoefficientInfo, error) {
 	return &QuotaCoefficientInfo{bigIntToString(qc), Uint64ToString(globalQuota), Float64ToString(float64(globalQuota)/21000/74, 2), isCongestion}, nil
 }
 
+// QuotaNetworkState is the state of the network the quota is calculated with, nil fields mean the current state of the node
+type QuotaNetworkState struct {
+	ForkVersion *uint32 `json:"forkVersion"`
+	GlobalQuota *string `json:"globalQuota"`
+}
+
+func (p *QuotaApi) networkState(param *QuotaNetworkState) (quota.NetworkState, error) {
+	state := quota.StateAt(p.chain, p.chain.GetLatestSnapshotBlock().Height)
+	if param == nil {
+		return state, nil
+	}
+	if param.ForkVersion != nil {
+		state.ForkVersion = *param.ForkVersion
+	}
+	if param.GlobalQuota != nil {
+		globalQuota, err := StringToUint64(*param.GlobalQuota)
+		if err != nil {
+			return state, err
+		}
+		state.GlobalQuotaUsed = globalQuota
+	}
+	return state, nil
+}
+
+// CalcStakeQuota returns the quota per snapshot block of the stake amount
+func (p *QuotaApi) CalcStakeQuota(amount string, param *QuotaNetworkState) (string, error) {
+	bAmount, err := stringToBigInt(&amount)
+	if err != nil {
+		return "", err
+	}
+	state, err := p.networkState(param)
+	if err != nil {
+		return "", err
+	}
+	return Uint64ToString(quota.DefaultCalculator().StakeQuota(bAmount, state)), nil
+}
+
+// CalcStakeAmountForQuota returns the minimal stake amount to get the quota per snapshot block
+func (p *QuotaApi) CalcStakeAmountForQuota(q string, param *QuotaNetworkState) (*string, error) {
+	qUint64, err := StringToUint64(q)
+	if err != nil {
+		return nil, err
+	}
+	state, err := p.networkState(param)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := quota.DefaultCalculator().StakeAmountForQuota(qUint64, state)
+	if err != nil {
+		return nil, err
+	}
+	return bigIntToString(amount), nil
+}
+
+// CalcPoWQuota returns the one-shot quota of the PoW difficulty
+func (p *QuotaApi) CalcPoWQuota(difficulty string, param *QuotaNetworkState) (string, error) {
+	bDifficulty, err := stringToBigInt(&difficulty)
+	if err != nil {
+		return "", err
+	}
+	state, err := p.networkState(param)
+	if err != nil {
+		return "", err
+	}
+	return Uint64ToString(quota.DefaultCalculator().PoWQuota(bDifficulty, state)), nil
+}
+
+// CalcDifficultyForQuota returns the minimal PoW difficulty to get the one-shot quota
+func (p *QuotaApi) CalcDifficultyForQuota(q string, param *QuotaNetworkState) (*string, error) {
+	qUint64, err := StringToUint64(q)
+	if err != nil {
+		return nil, err
+	}
+	state, err := p.networkState(param)
+	if err != nil {
+		return nil, err
+	}
+	difficulty, err := quota.DefaultCalculator().DifficultyForQuota(qUint64, state)
+	if err != nil {
+		return nil, err
+	}
+	return bigIntToString(difficulty), nil
+}
+
 // ------------------------------------------------------------
 // ---------------------deprecated-----------------------------
 // ------------------------------------------------------------
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// simulateState is the state which blocks are simulated on, nothing is written to the chain
+type simulateState struct {
+	c        chain.Chain
+	chain    *vm_db.OverrideChain
+	snapshot *ledger.SnapshotBlock
+	history  bool
+}
+
+// newSimulateState reads the latest state if neither snapshotHash nor snapshotHeight is set,
+// otherwise the state confirmed by the snapshot block.
+func newSimulateState(c chain.Chain, snapshotHash *types.Hash, snapshotHeight *uint64) (*simulateState, error) {
+	sb, err := getSnapshotHeader(c, snapshotHash, snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if sb.Hash == c.GetLatestSnapshotBlock().Hash {
+		return &simulateState{c: c, chain: vm_db.NewOverrideChain(c), snapshot: sb}, nil
+	}
+	return &simulateState{
+		c:        c,
+		chain:    vm_db.NewOverrideChain(vm_db.NewHistoryChain(c, c, sb)),
+		snapshot: sb,
+		history:  true,
+	}, nil
+}
+
+// getSnapshotHeader returns the snapshot block of snapshotHash or snapshotHeight, the latest if neither is set.
+// It fails if the history of the snapshot block is pruned.
+func getSnapshotHeader(c chain.Chain, snapshotHash *types.Hash, snapshotHeight *uint64) (*ledger.SnapshotBlock, error) {
+	sb, err := findSnapshotHeader(c, snapshotHash, snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.CheckHistoryHeight(sb.Height); err != nil {
+		return nil, err
+	}
+	return sb, nil
+}
+
+func findSnapshotHeader(c chain.Chain, snapshotHash *types.Hash, snapshotHeight *uint64) (*ledger.SnapshotBlock, error) {
+	latest := c.GetLatestSnapshotBlock()
+	switch {
+	case snapshotHash != nil:
+		sb, err := c.GetSnapshotHeaderByHash(*snapshotHash)
+		if err != nil {
+			return nil, err
+		}
+		if sb == nil {
+			return nil, fmt.Errorf("snapshot block %s not found", snapshotHash)
+		}
+		if snapshotHeight != nil && *snapshotHeight != sb.Height {
+			return nil, errors.New("snapshotHash and snapshotHeight are not of the same snapshot block")
+		}
+		return sb, nil
+	case snapshotHeight != nil:
+		if *snapshotHeight > latest.Height {
+			return nil, fmt.Errorf("snapshot height %d is higher than the latest %d", *snapshotHeight, latest.Height)
+		}
+		sb, err := c.GetSnapshotHeaderByHeight(*snapshotHeight)
+		if err != nil {
+			return nil, err
+		}
+		if sb == nil {
+			return nil, fmt.Errorf("snapshot block %d not found", *snapshotHeight)
+		}
+		return sb, nil
+	}
+	return latest, nil
+}
+
+// prevBlock returns the latest account block of addr in the state
+func (s *simulateState) prevBlock(addr types.Address) (*ledger.AccountBlock, error) {
+	if !s.history {
+		return s.c.GetLatestAccountBlock(addr)
+	}
+	return vm_db.GetConfirmedAccountBlock(s.c, addr, s.snapshot)
+}
+
+// newVmDb returns a VmDb of addr and the prev account block of addr
+func (s *simulateState) newVmDb(addr types.Address) (interfaces.VmDb, *ledger.AccountBlock, error) {
+	prev, err := s.prevBlock(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	prevHash := types.Hash{}
+	if prev != nil {
+		prevHash = prev.Hash
+	}
+	db, err := vm_db.NewVmDb(s.chain, &addr, &s.snapshot.Hash, &prevHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, prev, nil
+}
+
+// simulateReceive executes the receive block of sendBlock by the contract, the result is returned
+// with the error of the execution, e.g. util.ErrExecutionReverted.
+func (s *simulateState) simulateReceive(consensus generator.Consensus, sendBlock *ledger.AccountBlock, tracer vm.Tracer) (*interfaces.VmAccountBlock, error) {
+	db, prev, err := s.newVmDb(sendBlock.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: sendBlock.ToAddress,
+		FromBlockHash:  sendBlock.Hash,
+		Height:         1,
+	}
+	if prev != nil {
+		block.PrevHash = prev.Hash
+		block.Height = prev.Height + 1
+	}
+
+	v := vm.NewVM(util.NewVMConsensusReader(consensus.SBPReader()))
+	if tracer != nil {
+		v.SetTracer(tracer)
+	}
+	result, isRetry, err := v.RunV2(db, block, sendBlock, generator.NewVMGlobalStatus(s.c, s.snapshot, sendBlock.Hash))
+	if err == nil && isRetry {
+		err = errors.New("the contract can't receive the block now, e.g. quota of the contract is not enough")
+	}
+	return result, err
+}
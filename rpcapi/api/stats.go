@@ -162,3 +162,117 @@ func (c StatsApi) GetSBP(idx uint64) (interface{}, error) {
 	events, _, err := c.cs.API().ReadByIndex(types.SNAPSHOT_GID, idx)
 	return events, err
 }
+
+type SBPPlan struct {
+	Address types.Address `json:"address"`
+	Stime   int64         `json:"stime"`
+	Etime   int64         `json:"etime"`
+}
+
+type SBPRoundPlans struct {
+	Gid   types.Gid  `json:"gid"`
+	Index uint64     `json:"index"`
+	Stime int64      `json:"stime"`
+	Etime int64      `json:"etime"`
+	Plans []*SBPPlan `json:"plans"`
+}
+
+// GetSBPPlans returns the full producer schedule for every round in [startIdx, endIdx].
+// Rounds in the future are projected from the latest vote result.
+func (c StatsApi) GetSBPPlans(gid types.Gid, startIdx uint64, endIdx uint64) ([]*SBPRoundPlans, error) {
+	rounds, err := c.cs.API().ReadPlansByIndex(gid, startIdx, endIdx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*SBPRoundPlans, 0, len(rounds))
+	for _, r := range rounds {
+		round := &SBPRoundPlans{
+			Gid:   r.Gid,
+			Index: r.Index,
+			Stime: r.Stime.Unix(),
+			Etime: r.Etime.Unix(),
+			Plans: make([]*SBPPlan, 0, len(r.Plans)),
+		}
+		for _, p := range r.Plans {
+			round.Plans = append(round.Plans, &SBPPlan{
+				Address: p.Address,
+				Stime:   p.Stime.Unix(),
+				Etime:   p.Etime.Unix(),
+			})
+		}
+		result = append(result, round)
+	}
+	return result, nil
+}
+
+type ProducerLiveness struct {
+	Index    uint64        `json:"index"`
+	Address  types.Address `json:"address"`
+	Produced uint64        `json:"produced"`
+	Missed   uint64        `json:"missed"`
+}
+
+// GetProducerLiveness returns produced and missed slots of every SBP in [startIdx, endIdx],
+// counted by this node since it started.
+func (c StatsApi) GetProducerLiveness(startIdx uint64, endIdx uint64) ([]*ProducerLiveness, error) {
+	list, err := c.cs.API().ReadProducerLiveness(startIdx, endIdx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*ProducerLiveness, 0, len(list))
+	for _, v := range list {
+		result = append(result, &ProducerLiveness{
+			Index:    v.Index,
+			Address:  v.Address,
+			Produced: v.Produced,
+			Missed:   v.Missed,
+		})
+	}
+	return result, nil
+}
+
+type SBPSlot struct {
+	Index   uint64        `json:"index"` // the index of the round
+	Address types.Address `json:"address"`
+	Stime   int64         `json:"stime"`
+	Etime   int64         `json:"etime"`
+}
+
+type SBPSchedule struct {
+	Gid     types.Gid  `json:"gid"`
+	Time    int64      `json:"time"`    // the time the schedule is read at
+	Current *SBPSlot   `json:"current"` // the slot in flight, nil before the genesis
+	Next    []*SBPSlot `json:"next"`
+}
+
+// GetUpcomingProducers returns the producer of the slot in flight and the producers of the next k slots of the
+// consensus group. The rounds in the future are projected from the latest vote result.
+func (c StatsApi) GetUpcomingProducers(gid types.Gid, k int) (*SBPSchedule, error) {
+	now := time.Now()
+	current, next, err := c.cs.API().ReadUpcomingProducers(gid, now, k)
+	if err != nil {
+		return nil, err
+	}
+	slot := func(e *consensus.Event) *SBPSlot {
+		index, _ := c.cs.VoteTimeToIndex(gid, e.PeriodStime)
+		return &SBPSlot{
+			Index:   index,
+			Address: e.Address,
+			Stime:   e.Stime.Unix(),
+			Etime:   e.Etime.Unix(),
+		}
+	}
+
+	result := &SBPSchedule{
+		Gid:  gid,
+		Time: now.Unix(),
+		Next: make([]*SBPSlot, 0, len(next)),
+	}
+	if current != nil {
+		result.Current = slot(current)
+	}
+	for _, e := range next {
+		result.Next = append(result.Next, slot(e))
+	}
+	return result, nil
+}
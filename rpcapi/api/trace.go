@@ -0,0 +1,242 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/log15"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// maxTraceReplayBlocks is the max number of blocks executed before the traced block
+const maxTraceReplayBlocks = 1000
+
+// TraceApi re-executes contract code with the vm tracer, it is registered in the debug namespace.
+type TraceApi struct {
+	chain     chain.Chain
+	consensus generator.Consensus
+	log       log15.Logger
+}
+
+func NewTraceApi(vite *vite.Vite) *TraceApi {
+	return &TraceApi{
+		chain:     vite.Chain(),
+		consensus: vite.Consensus(),
+		log:       log15.New("module", "rpc_api/trace_api"),
+	}
+}
+
+func (t TraceApi) String() string {
+	return "TraceApi"
+}
+
+type TraceResult struct {
+	Address        types.Address `json:"address"`
+	SnapshotHeight uint64        `json:"snapshotHeight"`
+	ReplayedBlocks int           `json:"replayedBlocks"`
+	Quota          uint64        `json:"quota"`
+	QuotaUsed      uint64        `json:"quotaUsed"`
+	SendBlockCount int           `json:"sendBlockCount"`
+	Error          string        `json:"error,omitempty"`
+	// Consistent is true if the re-executed block has the hash of the original block
+	Consistent *bool `json:"consistent,omitempty"`
+
+	Trace *vm.StructLogger `json:"trace"`
+}
+
+func newTraceResult(addr types.Address, sb *ledger.SnapshotBlock, result *interfaces.VmAccountBlock, err error, logger *vm.StructLogger) *TraceResult {
+	r := &TraceResult{Address: addr, SnapshotHeight: sb.Height, Trace: logger}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if result != nil {
+		r.Quota = result.AccountBlock.Quota
+		r.QuotaUsed = result.AccountBlock.QuotaUsed
+		r.SendBlockCount = len(result.AccountBlock.SendBlockList)
+	}
+	return r
+}
+
+// TraceAccountBlock re-executes a receive block of a contract at the state before the snapshot block which confirms it,
+// or at the latest snapshot block if it is unconfirmed. The earlier blocks of the contract which are not confirmed
+// by that snapshot block are executed first without trace.
+// The quota used list of the contract and the contract meta are read from the latest state.
+func (t TraceApi) TraceAccountBlock(hash types.Hash, config *vm.StructLoggerConfig) (*TraceResult, error) {
+	block, err := t.chain.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("account block not found")
+	}
+	if !block.IsReceiveBlock() || !types.IsContractAddr(block.AccountAddress) {
+		return nil, errors.New("only receive blocks of contracts execute code")
+	}
+
+	sb, err := t.traceSnapshot(hash)
+	if err != nil {
+		return nil, err
+	}
+	replay, err := t.blocksToReplay(block, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	hc := vm_db.NewOverrideChain(vm_db.NewHistoryChain(t.chain, t.chain, sb))
+	for _, b := range replay {
+		result, err := t.execute(hc, b, sb, nil)
+		if err != nil {
+			return nil, fmt.Errorf("execute block %s failed, %v", b.Hash, err)
+		}
+		hc.Apply(b.AccountAddress, result.VMBlock.VmDb)
+	}
+
+	logger := vm.NewStructLogger(config)
+	result, err := t.execute(hc, block, sb, logger)
+	if err != nil {
+		return nil, err
+	}
+	r := newTraceResult(block.AccountAddress, sb, result.VMBlock, result.Err, logger)
+	r.ReplayedBlocks = len(replay)
+	consistent := result.VMBlock.AccountBlock.Hash == block.Hash
+	r.Consistent = &consistent
+	return r, nil
+}
+
+// traceSnapshot returns the snapshot block before the one which confirms the block, or the latest one
+func (t TraceApi) traceSnapshot(hash types.Hash) (*ledger.SnapshotBlock, error) {
+	confirmSb, err := t.chain.GetConfirmSnapshotHeaderByAbHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if confirmSb == nil {
+		return t.chain.GetLatestSnapshotBlock(), nil
+	}
+	sb, err := t.chain.GetSnapshotBlockByHeight(confirmSb.Height - 1)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, fmt.Errorf("snapshot block %d not found", confirmSb.Height-1)
+	}
+	return sb, nil
+}
+
+// blocksToReplay returns the blocks before block which are not confirmed by sb, in the order of height
+func (t TraceApi) blocksToReplay(block *ledger.AccountBlock, sb *ledger.SnapshotBlock) ([]*ledger.AccountBlock, error) {
+	var blocks []*ledger.AccountBlock
+	prevHash := block.PrevHash
+	for height := block.Height - 1; height > 0; height-- {
+		confirmSb, err := t.chain.GetConfirmSnapshotHeaderByAbHash(prevHash)
+		if err != nil {
+			return nil, err
+		}
+		if confirmSb != nil && confirmSb.Height <= sb.Height {
+			break
+		}
+		if len(blocks) >= maxTraceReplayBlocks {
+			return nil, fmt.Errorf("more than %d blocks to execute before the block", maxTraceReplayBlocks)
+		}
+		prev, err := t.chain.GetAccountBlockByHash(prevHash)
+		if err != nil {
+			return nil, err
+		}
+		if prev == nil {
+			return nil, fmt.Errorf("account block %s not found", prevHash)
+		}
+		blocks = append(blocks, prev)
+		prevHash = prev.PrevHash
+	}
+	for i, j := 0, len(blocks)-1; i < j; i, j = i+1, j-1 {
+		blocks[i], blocks[j] = blocks[j], blocks[i]
+	}
+	return blocks, nil
+}
+
+func (t TraceApi) execute(hc vm_db.Chain, block *ledger.AccountBlock, sb *ledger.SnapshotBlock, tracer vm.Tracer) (*interfaces.GenResult, error) {
+	sendBlock, err := t.chain.GetAccountBlockByHash(block.FromBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil {
+		return nil, fmt.Errorf("send block %s not found", block.FromBlockHash)
+	}
+	var gen interfaces.Generator
+	if tracer != nil {
+		gen, err = generator.NewGeneratorWithTracer(hc, t.consensus, block.AccountAddress, &sb.Hash, &block.PrevHash, tracer)
+	} else {
+		gen, err = generator.NewGenerator(hc, t.consensus, block.AccountAddress, &sb.Hash, &block.PrevHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result, err := gen.GenerateWithBlock(block.Copy(), sendBlock)
+	if err != nil {
+		return nil, err
+	}
+	if result.VMBlock == nil {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return nil, errors.New("vm returns no block")
+	}
+	return result, nil
+}
+
+type TraceCallParams struct {
+	FromAddress types.Address      `json:"fromAddress"`
+	ToAddress   types.Address      `json:"toAddress"`
+	TokenId     *types.TokenTypeId `json:"tokenId"`
+	Amount      *string            `json:"amount"`
+	Data        []byte             `json:"data"`
+
+	// the snapshot block of the state, the latest state if neither is set
+	SnapshotHash   *types.Hash `json:"snapshotHash"`
+	SnapshotHeight *uint64     `json:"snapshotHeight"`
+}
+
+// TraceCall executes a call to a contract at the latest state or the state of a snapshot block
+// as if a send block of the params was received, nothing is written to the chain.
+func (t TraceApi) TraceCall(params TraceCallParams, config *vm.StructLoggerConfig) (*TraceResult, error) {
+	if !types.IsContractAddr(params.ToAddress) {
+		return nil, errors.New("toAddress is not a contract")
+	}
+	amount := big.NewInt(0)
+	if params.Amount != nil {
+		var err error
+		if amount, err = stringToBigInt(params.Amount); err != nil {
+			return nil, err
+		}
+	}
+	tokenId := ledger.ViteTokenId
+	if params.TokenId != nil {
+		tokenId = *params.TokenId
+	}
+
+	state, err := newSimulateState(t.chain, params.SnapshotHash, params.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	sendBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: params.FromAddress,
+		ToAddress:      params.ToAddress,
+		TokenId:        tokenId,
+		Amount:         amount,
+		Fee:            big.NewInt(0),
+		Data:           params.Data,
+	}
+	sendBlock.Hash = sendBlock.ComputeHash()
+
+	logger := vm.NewStructLogger(config)
+	result, err := state.simulateReceive(t.consensus, sendBlock, logger)
+	return newTraceResult(params.ToAddress, state.snapshot, result, err, logger), nil
+}
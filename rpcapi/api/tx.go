@@ -392,6 +392,9 @@ func (tx Tx) autoSend() {
 	num := atomic.NewUint32(0)
 
 	tx.vite.Consensus().Subscribe(types.SNAPSHOT_GID, "api-auto-send", nil, func(e consensus.Event) {
+		if e.Replayed {
+			return
+		}
 
 		if num.Load() > 0 {
 			fmt.Printf("something is loading[return].%s\n", time.Now())
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/chain"
+	"github.com/vitelabs/go-vite/v2/ledger/generator"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/dex"
+)
+
+type BuildTransferBlockParam struct {
+	SelfAddr    types.Address     `json:"selfAddr"`
+	ToAddr      types.Address     `json:"toAddr"`
+	TokenTypeId types.TokenTypeId `json:"tokenTypeId"`
+	Amount      string            `json:"amount"`
+	Data        []byte            `json:"data,omitempty"` //base64
+	// PoW difficulty, nil means the difficulty is calculated if the stake quota of the account is not enough
+	Difficulty *string `json:"difficulty,omitempty"`
+}
+
+type BuildContractCallBlockParam struct {
+	SelfAddr    types.Address     `json:"selfAddr"`
+	ToAddr      types.Address     `json:"toAddr"`
+	TokenTypeId types.TokenTypeId `json:"tokenTypeId"`
+	Amount      string            `json:"amount"`
+	Abi         string            `json:"abi"`
+	MethodName  string            `json:"methodName"`
+	Params      []string          `json:"params"`
+	Difficulty  *string           `json:"difficulty,omitempty"`
+}
+
+// BuildTransferBlock returns the unsigned send block with the hash computed, the PoW nonce is computed if required.
+// The caller fills the publicKey and the signature of the hash, and sends it by ledger_sendRawTransaction.
+func (t Tx) BuildTransferBlock(param BuildTransferBlockParam) (*AccountBlock, error) {
+	return buildSendBlock(t.vite.Chain(), t.vite.Consensus(), param.SelfAddr, param.ToAddr, param.TokenTypeId, param.Amount, param.Data, param.Difficulty)
+}
+
+// BuildContractCallBlock packs the method call by the abi and returns the unsigned send block like BuildTransferBlock
+func (t Tx) BuildContractCallBlock(param BuildContractCallBlockParam) (*AccountBlock, error) {
+	return buildContractCallBlock(t.vite.Chain(), t.vite.Consensus(), param)
+}
+
+func buildContractCallBlock(c chain.Chain, cs generator.Consensus, param BuildContractCallBlockParam) (*AccountBlock, error) {
+	if !types.IsContractAddr(param.ToAddr) {
+		return nil, errors.New("toAddr is not a contract address")
+	}
+	abiContract, err := abi.JSONToABIContract(strings.NewReader(param.Abi))
+	if err != nil {
+		return nil, err
+	}
+	method, ok := abiContract.Methods[param.MethodName]
+	if !ok {
+		return nil, errors.New("method name not found")
+	}
+	arguments, err := convert(param.Params, method.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	data, err := abiContract.PackMethod(param.MethodName, arguments...)
+	if err != nil {
+		return nil, err
+	}
+	return buildSendBlock(c, cs, param.SelfAddr, param.ToAddr, param.TokenTypeId, param.Amount, data, param.Difficulty)
+}
+
+func buildSendBlock(c chain.Chain, cs generator.Consensus, selfAddr, toAddr types.Address, tokenId types.TokenTypeId, amountStr string, data []byte, difficultyStr *string) (*AccountBlock, error) {
+	if !checkTxToAddressAvailable(toAddr) {
+		return nil, errors.New("ToAddress is invalid")
+	}
+	if toAddr == types.AddressDexFund && !dex.VerifyNewOrderPriceForRpc(data) {
+		return nil, dex.InvalidOrderPriceErr
+	}
+	if err := checkTokenIdValid(c, &tokenId); err != nil {
+		return nil, err
+	}
+	amount, ok := new(big.Int).SetString(amountStr, 10)
+	if !ok {
+		return nil, ErrStrToBigInt
+	}
+
+	addrState, err := generator.GetAddressStateForGenerator(c, &selfAddr)
+	if err != nil || addrState == nil {
+		return nil, fmt.Errorf("failed to get addr state for generator, err:%v", err)
+	}
+
+	var difficulty *big.Int
+	if difficultyStr != nil {
+		difficulty, ok = new(big.Int).SetString(*difficultyStr, 10)
+		if !ok {
+			return nil, ErrStrToBigInt
+		}
+	} else {
+		var prevHash types.Hash
+		if addrState.LatestAccountHash != nil {
+			prevHash = *addrState.LatestAccountHash
+		}
+		result, err := calcPoWDifficulty(c, CalcPoWDifficultyParam{
+			SelfAddr:      selfAddr,
+			PrevHash:      prevHash,
+			BlockType:     ledger.BlockTypeSendCall,
+			ToAddr:        &toAddr,
+			Data:          data,
+			UseStakeQuota: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if result.Difficulty != "" {
+			difficulty, ok = new(big.Int).SetString(result.Difficulty, 10)
+			if !ok {
+				return nil, ErrStrToBigInt
+			}
+		}
+	}
+
+	msg := &interfaces.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: selfAddr,
+		ToAddress:      &toAddr,
+		TokenId:        &tokenId,
+		Amount:         amount,
+		Data:           data,
+		Difficulty:     difficulty,
+	}
+	g, err := generator.NewGenerator(c, cs, selfAddr, addrState.LatestSnapshotHash, addrState.LatestAccountHash)
+	if err != nil {
+		return nil, err
+	}
+	// no sign func, the block is signed offline
+	result, err := g.GenerateWithMessage(msg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if result.VMBlock == nil {
+		return nil, errors.New("generator gen an empty block")
+	}
+	return ledgerToRpcBlock(c, result.VMBlock.AccountBlock)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/ledger/consensus/core"
+	"github.com/vitelabs/go-vite/v2/ledger/test_tools"
+	"github.com/vitelabs/go-vite/v2/vm"
+)
+
+type buildConsensus struct{}
+
+func (buildConsensus) SBPReader() core.SBPStatReader {
+	return nil
+}
+
+func TestBuildSendBlock(t *testing.T) {
+	vm.InitVMConfig(false, false, false, false, "")
+	c, tempDir := test_tools.NewTestChainInstance(t, true, config.MockGenesis())
+	defer test_tools.ClearChain(c, tempDir)
+
+	selfAddr := types.HexToAddressPanic("vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a")
+	toAddr := types.HexToAddressPanic("vite_360232b0378111b122685a15e612143dc9a89cfa7e803f4b5a")
+	prev, err := c.GetLatestAccountBlock(selfAddr)
+	assert.NoError(t, err)
+
+	difficulty := "0"
+	block, err := buildSendBlock(c, buildConsensus{}, selfAddr, toAddr, ledger.ViteTokenId, "100", []byte{1, 2}, &difficulty)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, ledger.BlockTypeSendCall, block.BlockType)
+	assert.Equal(t, selfAddr, block.AccountAddress)
+	assert.Equal(t, toAddr, block.ToAddress)
+	assert.Equal(t, "100", *block.Amount)
+	assert.Equal(t, []byte{1, 2}, block.Data)
+	if prev != nil {
+		assert.Equal(t, prev.Hash, block.PrevHash)
+		assert.Equal(t, Uint64ToString(prev.Height+1), block.Height)
+	}
+
+	// unsigned, and the hash covers all the fields
+	assert.Empty(t, block.Signature)
+	assert.Empty(t, block.PublicKey)
+	hash, err := block.ComputeHash()
+	assert.NoError(t, err)
+	assert.Equal(t, block.Hash, *hash)
+
+	// the difficulty is calculated, the nonce is computed if PoW is required
+	block, err = buildSendBlock(c, buildConsensus{}, selfAddr, toAddr, ledger.ViteTokenId, "100", nil, nil)
+	if assert.NoError(t, err) && block.Difficulty != nil {
+		assert.NotEmpty(t, block.Nonce)
+	}
+
+	// invalid params
+	_, err = buildSendBlock(c, buildConsensus{}, selfAddr, toAddr, ledger.ViteTokenId, "abc", nil, &difficulty)
+	assert.Error(t, err)
+	_, err = buildSendBlock(c, buildConsensus{}, selfAddr, toAddr, types.TokenTypeId{1}, "100", nil, &difficulty)
+	assert.Error(t, err)
+	_, err = buildContractCallBlock(c, buildConsensus{}, BuildContractCallBlockParam{SelfAddr: selfAddr, ToAddr: toAddr, Amount: "0"})
+	assert.Error(t, err)
+}
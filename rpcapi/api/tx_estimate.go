@@ -0,0 +1,301 @@
+package api
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm"
+	"github.com/vitelabs/go-vite/v2/vm/quota"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+// StateOverride replaces the state of an account during the estimation,
+// storage keys and values are hex encoded, an empty value deletes the key.
+type StateOverride struct {
+	Balances map[types.TokenTypeId]string `json:"balances"`
+	Storage  map[string]string            `json:"storage"`
+}
+
+type EstimateQuotaParam struct {
+	SelfAddr  types.Address      `json:"selfAddr"`
+	ToAddr    *types.Address     `json:"toAddr"`
+	BlockType byte               `json:"blockType"`
+	TokenId   *types.TokenTypeId `json:"tokenId"`
+	Amount    *string            `json:"amount"`
+	Data      []byte             `json:"data"`
+
+	// the snapshot block of the state, the latest state if neither is set
+	SnapshotHash   *types.Hash                      `json:"snapshotHash"`
+	SnapshotHeight *uint64                          `json:"snapshotHeight"`
+	StateOverrides map[types.Address]*StateOverride `json:"stateOverrides"`
+}
+
+type EstimateQuotaResult struct {
+	SnapshotHeight uint64 `json:"snapshotHeight"`
+	QuotaRequired  string `json:"quotaRequired"`
+	UtRequired     string `json:"utRequired"`
+
+	// StakeQuota is the current quota of selfAddr by staking
+	StakeQuota       string `json:"stakeQuota"`
+	StakeQuotaEnough bool   `json:"stakeQuotaEnough"`
+	// Difficulty is the PoW difficulty required if selfAddr has no stake, empty if selfAddr can't do PoW now
+	Difficulty   string  `json:"difficulty"`
+	Qc           *string `json:"qc"`
+	IsCongestion bool    `json:"isCongestion"`
+
+	// ReceiveQuotaUsed is the quota used by the contract to receive the call
+	ReceiveQuotaUsed *string `json:"receiveQuotaUsed,omitempty"`
+	Reverted         bool    `json:"reverted"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// EstimateQuota simulates the block against the latest state or the state of a snapshot block,
+// with the state overrides applied. A call to a contract is also received by the contract to find out
+// whether it reverts, nothing is written to the chain.
+func (t Tx) EstimateQuota(param EstimateQuotaParam) (*EstimateQuotaResult, error) {
+	c := t.vite.Chain()
+	if param.BlockType == 0 {
+		param.BlockType = ledger.BlockTypeSendCall
+	}
+	if param.ToAddr == nil && param.BlockType == ledger.BlockTypeSendCall {
+		return nil, errors.New("toAddress is nil")
+	}
+	amount := big.NewInt(0)
+	if param.Amount != nil {
+		var err error
+		if amount, err = stringToBigInt(param.Amount); err != nil {
+			return nil, err
+		}
+	}
+	tokenId := ledger.ViteTokenId
+	if param.TokenId != nil {
+		tokenId = *param.TokenId
+	}
+
+	state, err := newSimulateState(c, param.SnapshotHash, param.SnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	for addr, override := range param.StateOverrides {
+		if err := applyStateOverride(state, addr, override); err != nil {
+			return nil, err
+		}
+	}
+
+	db, prev, err := state.newVmDb(param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	block := &ledger.AccountBlock{
+		BlockType:      param.BlockType,
+		AccountAddress: param.SelfAddr,
+		TokenId:        tokenId,
+		Amount:         amount,
+		Fee:            big.NewInt(0),
+		Data:           param.Data,
+		Height:         1,
+	}
+	if prev != nil {
+		block.PrevHash = prev.Hash
+		block.Height = prev.Height + 1
+	}
+	if param.ToAddr != nil {
+		block.ToAddress = *param.ToAddr
+	}
+
+	sb := state.snapshot
+	quotaRequired, err := vm.GasRequiredForBlock(db, block, util.QuotaTableByHeight(sb.Height), sb.Height)
+	if err != nil {
+		return nil, err
+	}
+	qc, _, isCongestion := quota.CalcQc(db, sb.Height)
+	result := &EstimateQuotaResult{
+		SnapshotHeight: sb.Height,
+		QuotaRequired:  Uint64ToString(quotaRequired),
+		UtRequired:     Float64ToString(float64(quotaRequired)/float64(quota.QuotaPerUt), 4),
+		Qc:             bigIntToString(qc),
+		IsCongestion:   isCongestion,
+	}
+
+	stakeAmount, err := state.chain.GetStakeBeneficialAmount(param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	q, err := quota.GetQuota(db, param.SelfAddr, stakeAmount, sb.Height)
+	if err != nil {
+		return nil, err
+	}
+	result.StakeQuota = Uint64ToString(q.Current())
+	result.StakeQuotaEnough = q.Current() >= quotaRequired
+	if quota.CanPoW(db, param.SelfAddr) {
+		d, err := quota.CalcPoWDifficulty(db, quotaRequired, types.NewQuota(0, 0, 0, 0, false, 0), sb.Height)
+		if err != nil {
+			return nil, err
+		}
+		result.Difficulty = d.String()
+	}
+
+	if ledger.IsSendBlock(param.BlockType) && amount.Sign() > 0 {
+		balance, err := db.GetBalance(&tokenId)
+		if err != nil {
+			return nil, err
+		}
+		if balance.Cmp(amount) < 0 {
+			result.Error = util.ErrInsufficientBalance.Error()
+			return result, nil
+		}
+	}
+
+	if param.BlockType == ledger.BlockTypeSendCall && types.IsContractAddr(block.ToAddress) {
+		block.Hash = block.ComputeHash()
+		receive, err := state.simulateReceive(t.vite.Consensus(), block, nil)
+		if receive != nil {
+			result.ReceiveQuotaUsed = bigIntToString(new(big.Int).SetUint64(receive.AccountBlock.QuotaUsed))
+		}
+		if err != nil {
+			result.Reverted = true
+			result.Error = err.Error()
+		}
+	}
+	return result, nil
+}
+
+func applyStateOverride(state *simulateState, addr types.Address, override *StateOverride) error {
+	if override == nil {
+		return nil
+	}
+	for tokenId, s := range override.Balances {
+		balance, ok := new(big.Int).SetString(s, 10)
+		if !ok || balance.Sign() < 0 {
+			return fmt.Errorf("invalid balance %q of %s", s, addr)
+		}
+		state.chain.SetBalance(addr, tokenId, balance)
+	}
+	for k, v := range override.Storage {
+		key, err := hex.DecodeString(strings.TrimPrefix(k, "0x"))
+		if err != nil || len(key) == 0 || len(key) > types.HashSize {
+			return fmt.Errorf("invalid storage key %q of %s", k, addr)
+		}
+		value, err := hex.DecodeString(strings.TrimPrefix(v, "0x"))
+		if err != nil {
+			return fmt.Errorf("invalid storage value %q of %s", v, addr)
+		}
+		state.chain.SetValue(addr, key, value)
+	}
+	return nil
+}
+
+const (
+	SuggestTxTypeTransfer = "transfer"
+	SuggestTxTypeReceive  = "receive"
+)
+
+type SuggestParamsResult struct {
+	QuotaRequired string `json:"quotaRequired"`
+	// CurrentQuota is the current quota of the account by staking
+	CurrentQuota               string `json:"currentQuota"`
+	StakeQuotaPerSnapshotBlock string `json:"stakeQuotaPerSnapshotBlock"`
+	StakeQuotaEnough           bool   `json:"stakeQuotaEnough"`
+	// WaitSnapshotBlocks is the expected snapshot blocks until the stake quota is enough,
+	// nil if the stake quota will never be enough
+	WaitSnapshotBlocks *string `json:"waitSnapshotBlocks"`
+	// Difficulty is the PoW difficulty required if the stake quota is not enough, empty if no PoW is required
+	Difficulty   string  `json:"difficulty"`
+	CanPoW       bool    `json:"canPoW"`
+	Qc           *string `json:"qc"`
+	IsCongestion bool    `json:"isCongestion"`
+}
+
+// SuggestParams tells whether the stake quota of the account is enough for the transaction of txType now,
+// how long to wait for the quota otherwise, and the PoW difficulty to send it at once.
+// txType is "transfer", a send block without data to a user account, or "receive".
+func (t Tx) SuggestParams(addr types.Address, txType string) (*SuggestParamsResult, error) {
+	c := t.vite.Chain()
+	block := &ledger.AccountBlock{AccountAddress: addr}
+	switch txType {
+	case "", SuggestTxTypeTransfer:
+		block.BlockType = ledger.BlockTypeSendCall
+		block.ToAddress = addr
+	case SuggestTxTypeReceive:
+		block.BlockType = ledger.BlockTypeReceive
+	default:
+		return nil, fmt.Errorf("unknown tx type %s", txType)
+	}
+
+	prev, err := c.GetLatestAccountBlock(addr)
+	if err != nil {
+		return nil, err
+	}
+	var prevHash types.Hash
+	if prev != nil {
+		prevHash = prev.Hash
+		block.PrevHash = prev.Hash
+	}
+	sb := c.GetLatestSnapshotBlock()
+	db, err := vm_db.NewVmDb(c, &addr, &sb.Hash, &prevHash)
+	if err != nil {
+		return nil, err
+	}
+	quotaRequired, err := vm.GasRequiredForBlock(db, block, util.QuotaTableByHeight(sb.Height), sb.Height)
+	if err != nil {
+		return nil, err
+	}
+	stakeAmount, err := c.GetStakeBeneficialAmount(addr)
+	if err != nil {
+		return nil, err
+	}
+	q, err := quota.GetQuota(db, addr, stakeAmount, sb.Height)
+	if err != nil && err != util.ErrInvalidUnconfirmedQuota {
+		return nil, err
+	}
+	qc, _, isCongestion := quota.CalcQc(db, sb.Height)
+
+	result := &SuggestParamsResult{
+		QuotaRequired:              Uint64ToString(quotaRequired),
+		CurrentQuota:               Uint64ToString(q.Current()),
+		StakeQuotaPerSnapshotBlock: Uint64ToString(q.StakeQuotaPerSnapshotBlock()),
+		StakeQuotaEnough:           !q.Blocked() && q.Current() >= quotaRequired,
+		CanPoW:                     quota.CanPoW(db, addr),
+		Qc:                         bigIntToString(qc),
+		IsCongestion:               isCongestion,
+	}
+	if wait, ok := quotaWaitSnapshotBlocks(q, quotaRequired); ok {
+		waitStr := Uint64ToString(wait)
+		result.WaitSnapshotBlocks = &waitStr
+	}
+	if !result.StakeQuotaEnough && result.CanPoW {
+		d, err := quota.CalcPoWDifficulty(db, quotaRequired, types.NewQuota(0, 0, 0, 0, false, 0), sb.Height)
+		if err != nil {
+			return nil, err
+		}
+		result.Difficulty = d.String()
+	}
+	return result, nil
+}
+
+// quotaWaitSnapshotBlocks returns the snapshot blocks until the stake quota is enough,
+// the quota is accumulated for QuotaAccumulationBlockCount snapshot blocks at most
+func quotaWaitSnapshotBlocks(q types.Quota, quotaRequired uint64) (uint64, bool) {
+	wait := uint64(0)
+	if q.Blocked() {
+		wait = q.BlockReleaseHeight()
+	}
+	if q.Current() >= quotaRequired {
+		return wait, true
+	}
+	perSb := q.StakeQuotaPerSnapshotBlock()
+	if perSb == 0 || perSb*util.QuotaAccumulationBlockCount < quotaRequired {
+		return 0, false
+	}
+	need := (quotaRequired - q.Current() + perSb - 1) / perSb
+	if need > wait {
+		wait = need
+	}
+	return wait, true
+}
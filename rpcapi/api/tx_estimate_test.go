@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm_db"
+)
+
+func TestApplyStateOverride(t *testing.T) {
+	state := &simulateState{chain: vm_db.NewOverrideChain(nil)}
+	addr := types.AddressQuota
+
+	err := applyStateOverride(state, addr, &StateOverride{
+		Balances: map[types.TokenTypeId]string{ledger.ViteTokenId: "100"},
+		Storage:  map[string]string{"0x01": "0x0a0b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, err := state.chain.GetBalance(addr, ledger.ViteTokenId)
+	if err != nil || balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("unexpected balance %v, err %v", balance, err)
+	}
+	value, err := state.chain.GetValue(addr, []byte{1})
+	if err != nil || !bytes.Equal(value, []byte{0x0a, 0x0b}) {
+		t.Fatalf("unexpected value %x, err %v", value, err)
+	}
+
+	invalid := []*StateOverride{
+		{Balances: map[types.TokenTypeId]string{ledger.ViteTokenId: "-1"}},
+		{Balances: map[types.TokenTypeId]string{ledger.ViteTokenId: "abc"}},
+		{Storage: map[string]string{"": "01"}},
+		{Storage: map[string]string{"0x" + string(bytes.Repeat([]byte("00"), 33)): "01"}},
+		{Storage: map[string]string{"01": "zz"}},
+	}
+	for i, override := range invalid {
+		if err := applyStateOverride(state, addr, override); err == nil {
+			t.Fatalf("override %d should be invalid", i)
+		}
+	}
+}
+
+func TestQuotaWaitSnapshotBlocks(t *testing.T) {
+	cases := []struct {
+		q        types.Quota
+		required uint64
+		wait     uint64
+		ok       bool
+	}{
+		{types.NewQuota(0, 0, 0, 0, false, 0), 21000, 0, false},
+		{types.NewQuota(280, 30000, 0, 0, false, 0), 21000, 0, true},
+		{types.NewQuota(280, 20000, 0, 0, false, 0), 21000, 4, true},
+		{types.NewQuota(280, 0, 0, 0, false, 0), 21000, 75, true},
+		{types.NewQuota(280, 0, 0, 0, false, 0), 21001, 0, false},
+		{types.NewQuota(280, 30000, 0, 0, true, 10), 21000, 10, true},
+	}
+	for i, c := range cases {
+		wait, ok := quotaWaitSnapshotBlocks(c.q, c.required)
+		if wait != c.wait || ok != c.ok {
+			t.Fatalf("case %d: expected %d %v, got %d %v", i, c.wait, c.ok, wait, ok)
+		}
+	}
+}
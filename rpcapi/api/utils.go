@@ -41,7 +41,7 @@ func InitConfig(id uint, dexAvailable *bool) {
 }
 
 func InitLog(dir, lvl string) {
-	log.SetHandler(common.LogHandler(dir, "rpclog", "rpc.log", lvl))
+	log.SetHandler(common.ReloadableLogHandler(dir, "rpclog", "rpc.log", lvl))
 }
 
 func InitTestAPIParams(priv, tti string) {
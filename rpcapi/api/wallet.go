@@ -63,6 +63,7 @@ func NewWalletApi(vite *vite.Vite) *WalletApi {
 		chain:     vite.Chain(),
 		pool:      vite.Pool(),
 		consensus: vite.Consensus(),
+		ledgerApi: NewLedgerApi(vite),
 	}
 }
 
@@ -71,6 +72,7 @@ type WalletApi struct {
 	chain     chain.Chain
 	pool      pool.Writer
 	consensus generator.Consensus
+	ledgerApi *LedgerApi
 }
 
 func (m WalletApi) String() string {
@@ -317,7 +319,7 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 	if err != nil {
 		return nil, err
 	}
-	result, e := g.GenerateWithMessage(msg, &msg.AccountAddress, account.Sign)
+	result, e := g.GenerateWithMessage(msg, &msg.AccountAddress, nil)
 
 	if e != nil {
 		return nil, e
@@ -326,6 +328,9 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 		return nil, result.Err
 	}
 	if result.VMBlock != nil {
+		if err := interfaces.SignAccountBlock(account, result.VMBlock.AccountBlock); err != nil {
+			return nil, err
+		}
 		return &result.VMBlock.AccountBlock.Hash, m.pool.AddDirectAccountBlock(params.SelfAddr, result.VMBlock)
 	} else {
 		return nil, errors.New("generator gen an empty block")
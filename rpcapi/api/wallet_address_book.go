@@ -0,0 +1,59 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+// LabeledAccountInfo is the balances of an address with its local label if requested
+type LabeledAccountInfo struct {
+	Address  types.Address     `json:"address"`
+	Label    string            `json:"label,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Balance  *AccountInfo      `json:"balance"`
+}
+
+// SetAddressLabel labels the address in the local address book, the label is never sent to the chain.
+// metadata is optional, nil keeps the existing metadata.
+func (m WalletApi) SetAddressLabel(addr types.Address, label string, metadata map[string]string) error {
+	return m.wallet.SetAddressLabel(addr, label, metadata)
+}
+
+func (m WalletApi) GetAddressLabel(addr types.Address) (*wallet.AddressLabel, error) {
+	l := m.wallet.GetAddressLabel(addr)
+	if l == nil {
+		return nil, wallet.ErrAddressLabelNotFound
+	}
+	return l, nil
+}
+
+func (m WalletApi) DeleteAddressLabel(addr types.Address) error {
+	return m.wallet.DeleteAddressLabel(addr)
+}
+
+func (m WalletApi) ListAddressLabels() []*wallet.AddressLabel {
+	return m.wallet.ListAddressLabels()
+}
+
+// GetAccountInfoList returns the balances of the addresses, the local labels are included if withLabels is true
+func (m WalletApi) GetAccountInfoList(addrs []types.Address, withLabels *bool) ([]*LabeledAccountInfo, error) {
+	result := make([]*LabeledAccountInfo, 0, len(addrs))
+	for _, addr := range addrs {
+		balance, err := m.ledgerApi.getAccountInfoByAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		info := &LabeledAccountInfo{
+			Address: addr,
+			Balance: ToAccountInfo(m.chain, balance),
+		}
+		if withLabels != nil && *withLabels {
+			if l := m.wallet.GetAddressLabel(addr); l != nil {
+				info.Label = l.Label
+				info.Metadata = l.Metadata
+			}
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
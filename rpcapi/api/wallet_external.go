@@ -0,0 +1,66 @@
+package api
+
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet/hardware"
+)
+
+type HardwareAccountResult struct {
+	Address types.Address `json:"address"`
+	Index   uint32        `json:"index"`
+	Device  string        `json:"device"`
+}
+
+// ListLedgerDevices lists the connected Ledger devices
+func (m WalletApi) ListLedgerDevices() ([]*hardware.DeviceInfo, error) {
+	return m.wallet.ListLedgerDevices()
+}
+
+// AddLedgerAccount adds the account of index on the Ledger device, empty devicePath means the first device.
+// SignData and CreateTxWithPassphrase sign by the device for the account after it is added.
+func (m WalletApi) AddLedgerAccount(devicePath string, index uint32, addr *types.Address) (*HardwareAccountResult, error) {
+	acct, err := m.wallet.AddLedgerAccount(devicePath, index, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &HardwareAccountResult{
+		Address: acct.Address(),
+		Index:   acct.Index(),
+		Device:  acct.Device().Info().Path,
+	}, nil
+}
+
+// VerifyLedgerAddress displays the address on device, it returns after the user approves or rejects
+func (m WalletApi) VerifyLedgerAddress(addr types.Address) error {
+	acct := m.wallet.HardwareAccount(addr)
+	if acct == nil {
+		return hardware.ErrDeviceNotFound
+	}
+	return acct.VerifyOnDevice()
+}
+
+// AddRemoteSigner adds the accounts held by the signer daemon of endpoint, only the account of addr if it is not nil.
+// timeout is in seconds and should cover the manual approval on the signer.
+func (m WalletApi) AddRemoteSigner(endpoint string, token string, timeout uint64, addr *types.Address) ([]types.Address, error) {
+	accts, err := m.wallet.AddRemoteSigner(endpoint, token, time.Duration(timeout)*time.Second, addr)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]types.Address, 0, len(accts))
+	for _, acct := range accts {
+		addrs = append(addrs, acct.Address())
+	}
+	return addrs, nil
+}
+
+// RemoveExternalAccount removes the account added by AddLedgerAccount or AddRemoteSigner
+func (m WalletApi) RemoveExternalAccount(addr types.Address) {
+	m.wallet.RemoveExternalAccount(addr)
+}
+
+// ListExternalAccounts lists the accounts added by AddLedgerAccount or AddRemoteSigner
+func (m WalletApi) ListExternalAccounts() []types.Address {
+	return m.wallet.ListExternalAccounts()
+}
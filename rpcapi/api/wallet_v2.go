@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
 )
 
 func (m WalletApi) GetEntropyFilesInStandardDir() ([]string, error) {
@@ -54,6 +55,42 @@ func (m WalletApi) DeriveAddressesByIndexRange(entropyFile string, startIndex, e
 	return manager.ListAddress(startIndex, endIndex)
 }
 
+// UnlockWithOptions unlocks the entropy file with a custom derivation path format, e.g. "m/44'/666666'/1'/%d'",
+// or a bip39 passphrase. The addresses of the unlocked entropy file are derived by the options until it is unlocked again.
+func (m WalletApi) UnlockWithOptions(entropyFile string, passphrase string, options *entropystore.DeriveOptions) error {
+	return m.wallet.UnlockWithOptions(entropyFile, passphrase, options)
+}
+
+// DeriveAddressesWithOptions derives the addresses of index in [startIndex, endIndex) by the options, the entropy file is not unlocked
+func (m WalletApi) DeriveAddressesWithOptions(entropyFile string, passphrase string, options *entropystore.DeriveOptions, startIndex, endIndex uint32) ([]*entropystore.DerivedAddress, error) {
+	manager, e := m.wallet.GetEntropyStoreManager(entropyFile)
+	if e != nil {
+		return nil, e
+	}
+	return manager.DeriveRangeWithPassphrase(passphrase, options, startIndex, endIndex)
+}
+
+// DeriveAddressesFromMnemonic derives the addresses of index in [startIndex, endIndex) of a mnemonic by the options,
+// it helps to find the funds of a wallet created by other software before recovering it. Nothing is stored.
+func (m WalletApi) DeriveAddressesFromMnemonic(mnemonics string, options *entropystore.DeriveOptions, startIndex, endIndex uint32) ([]*entropystore.DerivedAddress, error) {
+	return entropystore.DeriveRangeFromMnemonic(mnemonics, options, startIndex, endIndex)
+}
+
+// GetEntropyFileKDF returns the kdf parameters of the entropy file
+func (m WalletApi) GetEntropyFileKDF(entropyFile string) (*entropystore.KDFParams, error) {
+	manager, e := m.wallet.GetEntropyStoreManager(entropyFile)
+	if e != nil {
+		return nil, e
+	}
+	return manager.KDFParams()
+}
+
+// MigrateEntropyFile encrypts the entropy file again by the kdf parameters, e.g. {"kdf":"argon2id"},
+// nil means the kdf parameters of the node config. The passphrase is not changed.
+func (m WalletApi) MigrateEntropyFile(entropyFile string, passphrase string, params *entropystore.KDFParams) error {
+	return m.wallet.MigrateEntropyStore(entropyFile, passphrase, params)
+}
+
 type CreateEntropyFileResponse struct {
 	Mnemonics      string        `json:"mnemonics"`
 	PrimaryAddress types.Address `json:"primaryAddress"`
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+// WatchOnlyAccountInfo is the balances and the unreceived summary of a watch-only address
+type WatchOnlyAccountInfo struct {
+	Address    types.Address `json:"address"`
+	Label      string        `json:"label,omitempty"`
+	Balance    *AccountInfo  `json:"balance"`
+	Unreceived *AccountInfo  `json:"unreceived,omitempty"`
+}
+
+// WatchOnlySummary aggregates the watch-only addresses, the totals are the sums of each token in all addresses
+type WatchOnlySummary struct {
+	Accounts        []*WatchOnlyAccountInfo      `json:"accounts"`
+	TotalBalance    map[types.TokenTypeId]string `json:"totalBalance"`
+	TotalUnreceived map[types.TokenTypeId]string `json:"totalUnreceived"`
+}
+
+// AddWatchOnlyAddress monitors the address without its key, label is optional
+func (m WalletApi) AddWatchOnlyAddress(addr types.Address, label string) error {
+	return m.wallet.AddWatchOnly(addr, label)
+}
+
+func (m WalletApi) RemoveWatchOnlyAddress(addr types.Address) error {
+	return m.wallet.RemoveWatchOnly(addr)
+}
+
+func (m WalletApi) ListWatchOnlyAddresses() []*wallet.WatchOnlyAddress {
+	return m.wallet.ListWatchOnly()
+}
+
+// GetWatchOnlySummary returns the balances and the unreceived amounts of all watch-only addresses
+func (m WalletApi) GetWatchOnlySummary() (*WatchOnlySummary, error) {
+	list := m.wallet.ListWatchOnly()
+	summary := &WatchOnlySummary{
+		Accounts:        make([]*WatchOnlyAccountInfo, 0, len(list)),
+		TotalBalance:    make(map[types.TokenTypeId]string),
+		TotalUnreceived: make(map[types.TokenTypeId]string),
+	}
+	totalBalance := make(map[types.TokenTypeId]*big.Int)
+	totalUnreceived := make(map[types.TokenTypeId]*big.Int)
+	for _, w := range list {
+		balance, err := m.ledgerApi.getAccountInfoByAddress(w.Address)
+		if err != nil {
+			return nil, err
+		}
+		unreceived, err := m.chain.GetAccountOnRoadInfo(w.Address)
+		if err != nil {
+			return nil, err
+		}
+		for tti, b := range balance.TokenBalanceInfoMap {
+			addToTotal(totalBalance, tti, &b.TotalAmount)
+		}
+		if unreceived != nil {
+			for tti, b := range unreceived.TokenBalanceInfoMap {
+				addToTotal(totalUnreceived, tti, &b.TotalAmount)
+			}
+		}
+		summary.Accounts = append(summary.Accounts, &WatchOnlyAccountInfo{
+			Address:    w.Address,
+			Label:      w.Label,
+			Balance:    ToAccountInfo(m.chain, balance),
+			Unreceived: ToAccountInfo(m.chain, unreceived),
+		})
+	}
+	for tti, amount := range totalBalance {
+		summary.TotalBalance[tti] = amount.String()
+	}
+	for tti, amount := range totalUnreceived {
+		summary.TotalUnreceived[tti] = amount.String()
+	}
+	return summary, nil
+}
+
+func addToTotal(total map[types.TokenTypeId]*big.Int, tti types.TokenTypeId, amount *big.Int) {
+	if _, ok := total[tti]; !ok {
+		total[tti] = new(big.Int)
+	}
+	total[tti].Add(total[tti], amount)
+}
@@ -36,6 +36,13 @@ const (
 	DATA
 	LEDGERDEBUG
 	MINER
+	ETH
+	MULTISIG
+	TRACE
+	POOL
+	ADMIN
+	PRIVATE_NET
+	STATS
 	apiTypeLimit // this will be the last ApiType + 1
 )
 
@@ -66,6 +73,13 @@ var apiTypeStrings = []string{
 	"data",
 	"ledgerdebug",
 	"miner",
+	"eth",
+	"multisig",
+	"trace",
+	"pool",
+	"admin",
+	"private_net",
+	"stats",
 }
 
 func (at ApiType) name() string {
@@ -86,6 +100,16 @@ func Init(dir, lvl string, testApi_prikey, testApi_tti string, netId uint, dexAv
 	api.InitConfig(netId, dexAvailable)
 }
 
+// InitEth sets the default token of the eth compatible api, empty means VITE
+func InitEth(defaultTokenId string) error {
+	return api.InitEthConfig(defaultTokenId)
+}
+
+// InitMaxPageSize sets the max page size of cursor paged ledger queries, 0 means the default
+func InitMaxPageSize(size uint64) {
+	api.InitMaxPageSize(size)
+}
+
 func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 	switch apiModule {
 	// private IPC
@@ -272,6 +296,55 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewMinerApi(vite),
 			Public:    false,
 		}
+	case ApiType(ETH).name():
+		return rpc.API{
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   api.NewEthApi(vite),
+			Public:    true,
+		}
+	case ApiType(MULTISIG).name():
+		return rpc.API{
+			Namespace: "multisig",
+			Version:   "1.0",
+			Service:   api.NewMultisigApi(vite),
+			Public:    false,
+		}
+	case ApiType(TRACE).name():
+		return rpc.API{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   api.NewTraceApi(vite),
+			Public:    false,
+		}
+	case ApiType(POOL).name():
+		return rpc.API{
+			Namespace: "pool",
+			Version:   "1.0",
+			Service:   api.NewPoolApi(vite),
+			Public:    false,
+		}
+	case ApiType(ADMIN).name():
+		return rpc.API{
+			Namespace: "admin",
+			Version:   "1.0",
+			Service:   api.NewAdminApi(vite),
+			Public:    false,
+		}
+	case ApiType(PRIVATE_NET).name():
+		return rpc.API{
+			Namespace: "net",
+			Version:   "1.0",
+			Service:   api.NewPrivateNetApi(vite),
+			Public:    false,
+		}
+	case ApiType(STATS).name():
+		return rpc.API{
+			Namespace: "stats",
+			Version:   "1.0",
+			Service:   api.NewChainStatsApi(vite),
+			Public:    true,
+		}
 	default:
 		return rpc.API{Namespace: apiModule}
 	}
@@ -0,0 +1,271 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// resolver resolves a field of the parent object
+type resolver func(parent interface{}, args args) (interface{}, error)
+
+// fieldDef defines a field, typ is the object type of the result, empty for scalars.
+// size estimates the number of objects in the result by the arguments, nil for a single object.
+type fieldDef struct {
+	typ     string
+	resolve resolver
+	size    func(a args) uint64
+}
+
+type objectDef map[string]*fieldDef
+
+// schema is the object types, the root type is "Query"
+type schema map[string]objectDef
+
+const queryType = "Query"
+
+const (
+	maxQueryDepth      = 10
+	maxQueryComplexity = 10000
+)
+
+// args are the resolved arguments of a field
+type args map[string]interface{}
+
+func (a args) String(name string) (string, bool, error) {
+	v, ok := a[name]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, fmt.Errorf("argument %s should be a string", name)
+	}
+	return s, true, nil
+}
+
+func (a args) Int(name string) (int64, bool, error) {
+	v, ok := a[name]
+	if !ok || v == nil {
+		return 0, false, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f != math.Trunc(f) {
+		return 0, false, fmt.Errorf("argument %s should be an int", name)
+	}
+	return int64(f), true, nil
+}
+
+type gqlError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+type response struct {
+	Data   *orderedMap `json:"data"`
+	Errors []*gqlError `json:"errors,omitempty"`
+}
+
+// orderedMap keeps the order of fields as they are requested
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: make(map[string]interface{})}
+}
+
+func (m *orderedMap) set(key string, v interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = v
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(kb)
+		buf.WriteByte(':')
+		vb, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type executor struct {
+	schema schema
+	vars   map[string]interface{}
+	errors []*gqlError
+}
+
+func (s schema) execute(query string, operationName string, vars map[string]interface{}) *response {
+	doc, err := parse(query)
+	if err != nil {
+		return &response{Errors: []*gqlError{{Message: err.Error()}}}
+	}
+
+	var op *operation
+	if operationName == "" {
+		if len(doc.operations) > 1 {
+			return &response{Errors: []*gqlError{{Message: "operationName is required for multiple operations"}}}
+		}
+		op = doc.operations[0]
+	} else {
+		for _, o := range doc.operations {
+			if o.name == operationName {
+				op = o
+				break
+			}
+		}
+		if op == nil {
+			return &response{Errors: []*gqlError{{Message: fmt.Sprintf("unknown operation %s", operationName)}}}
+		}
+	}
+
+	allVars := make(map[string]interface{}, len(op.variables)+len(vars))
+	for k, v := range op.variables {
+		allVars[k] = v
+	}
+	for k, v := range vars {
+		allVars[k] = v
+	}
+
+	if err := s.checkLimits(op.selections, allVars); err != nil {
+		return &response{Errors: []*gqlError{{Message: err.Error()}}}
+	}
+
+	e := &executor{schema: s, vars: allVars}
+	data := e.executeObject(queryType, nil, op.selections, nil)
+	return &response{Data: data, Errors: e.errors}
+}
+
+func (e *executor) executeObject(typ string, parent interface{}, selections []*field, path []interface{}) *orderedMap {
+	def, ok := e.schema[typ]
+	if !ok {
+		panic("unknown graphql type " + typ)
+	}
+
+	result := newOrderedMap()
+	for _, f := range selections {
+		fieldPath := append(append([]interface{}{}, path...), f.key())
+		if f.name == "__typename" {
+			result.set(f.key(), typ)
+			continue
+		}
+
+		fd, ok := def[f.name]
+		if !ok {
+			e.errorf(fieldPath, "unknown field %s on type %s", f.name, typ)
+			result.set(f.key(), nil)
+			continue
+		}
+		if fd.typ == "" && len(f.selections) > 0 {
+			e.errorf(fieldPath, "field %s of type %s must not have a selection", f.name, typ)
+			result.set(f.key(), nil)
+			continue
+		}
+		if fd.typ != "" && len(f.selections) == 0 {
+			e.errorf(fieldPath, "field %s of type %s must have a selection", f.name, typ)
+			result.set(f.key(), nil)
+			continue
+		}
+
+		a := make(args, len(f.args))
+		for k, v := range f.args {
+			a[k] = v.resolve(e.vars)
+		}
+		v, err := fd.resolve(parent, a)
+		if err != nil {
+			e.errorf(fieldPath, "%v", err)
+			result.set(f.key(), nil)
+			continue
+		}
+
+		result.set(f.key(), e.complete(fd.typ, v, f.selections, fieldPath))
+	}
+	return result
+}
+
+// complete builds the result of objects and lists of objects, scalars are returned as they are
+func (e *executor) complete(typ string, v interface{}, selections []*field, path []interface{}) interface{} {
+	if typ == "" || v == nil {
+		return v
+	}
+	if list, ok := v.([]interface{}); ok {
+		result := make([]interface{}, len(list))
+		for i, item := range list {
+			result[i] = e.complete(typ, item, selections, append(append([]interface{}{}, path...), i))
+		}
+		return result
+	}
+	return e.executeObject(typ, v, selections, path)
+}
+
+// checkLimits rejects the queries nested too deep or selecting too many objects before resolving any field,
+// the schema has cycles like AccountBlock.account and Account.blocks
+func (s schema) checkLimits(selections []*field, vars map[string]interface{}) error {
+	depth, complexity := s.measure(queryType, selections, vars)
+	if depth > maxQueryDepth {
+		return fmt.Errorf("query depth %d exceeds the limit %d", depth, maxQueryDepth)
+	}
+	if complexity > maxQueryComplexity {
+		return fmt.Errorf("query complexity %d exceeds the limit %d", complexity, maxQueryComplexity)
+	}
+	return nil
+}
+
+// measure returns the depth of the selections and the number of fields resolved, the lists are counted by
+// their estimated size. The unknown fields are left to the executor.
+func (s schema) measure(typ string, selections []*field, vars map[string]interface{}) (depth int, complexity uint64) {
+	def := s[typ]
+	for _, f := range selections {
+		complexity++
+		fd, ok := def[f.name]
+		if !ok || fd.typ == "" || len(f.selections) == 0 {
+			depth = maxInt(depth, 1)
+			continue
+		}
+		childDepth, childComplexity := s.measure(fd.typ, f.selections, vars)
+		depth = maxInt(depth, childDepth+1)
+
+		size := uint64(1)
+		if fd.size != nil {
+			a := make(args, len(f.args))
+			for k, v := range f.args {
+				a[k] = v.resolve(vars)
+			}
+			size = fd.size(a)
+		}
+		complexity += size * childComplexity
+		if complexity > maxQueryComplexity {
+			return depth, complexity
+		}
+	}
+	return depth, complexity
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (e *executor) errorf(path []interface{}, format string, a ...interface{}) {
+	e.errors = append(e.errors, &gqlError{Message: fmt.Sprintf(format, a...), Path: path})
+}
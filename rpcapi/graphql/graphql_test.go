@@ -0,0 +1,140 @@
+package graphql
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+type mockChain struct {
+	Chain
+	addr   types.Address
+	blocks []*ledger.AccountBlock
+	sb     *ledger.SnapshotBlock
+}
+
+func newMockChain() *mockChain {
+	c := &mockChain{addr: types.Address{1}}
+	for i := uint64(1); i <= 5; i++ {
+		c.blocks = append(c.blocks, &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Hash:           types.Hash{byte(i)},
+			Height:         i,
+			AccountAddress: c.addr,
+			Amount:         big.NewInt(int64(i)),
+			TokenId:        ledger.ViteTokenId,
+		})
+	}
+	now := time.Unix(1600000000, 0)
+	c.sb = &ledger.SnapshotBlock{Height: 10, Hash: types.Hash{10}, Timestamp: &now}
+	return c
+}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.sb
+}
+
+func (c *mockChain) GetLatestAccountHeight(addr types.Address) (uint64, error) {
+	if addr != c.addr {
+		return 0, nil
+	}
+	return uint64(len(c.blocks)), nil
+}
+
+func (c *mockChain) GetAccountBlocksByHeight(addr types.Address, height uint64, count uint64) ([]*ledger.AccountBlock, error) {
+	var result []*ledger.AccountBlock
+	for h := height; h >= 1 && uint64(len(result)) < count; h-- {
+		result = append(result, c.blocks[h-1])
+	}
+	return result, nil
+}
+
+func (c *mockChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	for _, b := range c.blocks {
+		if b.Hash == hash {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *mockChain) GetTokenInfoById(tokenId types.TokenTypeId) (*types.TokenInfo, error) {
+	if tokenId != ledger.ViteTokenId {
+		return nil, nil
+	}
+	return &types.TokenInfo{TokenSymbol: "VITE", Decimals: 18}, nil
+}
+
+func execute(t *testing.T, query string, vars map[string]interface{}) string {
+	resp := newSchema(newMockChain()).execute(query, "", vars)
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
+
+func TestExecute_Pagination(t *testing.T) {
+	addr := types.Address{1}
+	query := `query Blocks($addr: String!, $first: Int = 2) {
+		account(address: $addr) {
+			blocks(first: $first, before: "5") {
+				blocks { height amount token { symbol } }
+				hasMore
+				nextBefore
+			}
+		}
+	}`
+	result := execute(t, query, map[string]interface{}{"addr": addr.String()})
+	expected := `{"data":{"account":{"blocks":{"blocks":[{"height":"4","amount":"4","token":{"symbol":"VITE"}},{"height":"3","amount":"3","token":{"symbol":"VITE"}}],"hasMore":true,"nextBefore":"3"}}}}`
+	if result != expected {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+}
+
+func TestExecute_Errors(t *testing.T) {
+	result := execute(t, `{ latest: latestSnapshotBlock { height timestamp } accountBlock(hash: "0000000000000000000000000000000000000000000000000000000000000009") { hash } unknown }`, nil)
+	expected := `{"data":{"latest":{"height":"10","timestamp":1600000000},"accountBlock":null,"unknown":null},"errors":[{"message":"unknown field unknown on type Query","path":["unknown"]}]}`
+	if result != expected {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+
+	result = execute(t, `{ latestSnapshotBlock { ...fields } }`, nil)
+	if result != `{"data":null,"errors":[{"message":"fragments are not supported, at 24"}]}` {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+}
+
+func TestExecute_Limits(t *testing.T) {
+	// AccountBlock.account and Account.blocks are a cycle
+	query := `{ accountBlock(hash: "0100000000000000000000000000000000000000000000000000000000000000") {`
+	for i := 0; i < 5; i++ {
+		query += ` account { blocks(first: 1) { blocks {`
+	}
+	query += ` hash` + strings.Repeat(` } } }`, 5) + ` } }`
+	result := execute(t, query, nil)
+	if result != `{"data":null,"errors":[{"message":"query depth 17 exceeds the limit 10"}]}` {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+
+	// the page sizes are multiplied, the variables are resolved
+	query = `query Blocks($addr: String!, $first: Int) {
+		account(address: $addr) {
+			blocks(first: $first) { blocks { account { blocks(first: 100) { blocks { hash } } } } }
+		}
+	}`
+	addr := types.Address{1}.String()
+	result = execute(t, query, map[string]interface{}{"addr": addr, "first": float64(100)})
+	if result != `{"data":null,"errors":[{"message":"query complexity 20302 exceeds the limit 10000"}]}` {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+	result = execute(t, query, map[string]interface{}{"addr": addr, "first": float64(1)})
+	if !strings.HasPrefix(result, `{"data":{"account":{"blocks":{"blocks":[{"account":`) {
+		t.Fatalf("unexpected result:\n%s", result)
+	}
+}
@@ -0,0 +1,412 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the parser supports the query subset used by the ledger schema:
+// operations, variables with defaults, aliases, arguments and nested selections.
+// fragments, directives and mutations are not supported.
+
+type document struct {
+	operations []*operation
+}
+
+type operation struct {
+	name       string
+	variables  map[string]interface{} // default values
+	selections []*field
+}
+
+type field struct {
+	alias      string
+	name       string
+	args       map[string]value
+	selections []*field
+}
+
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// value is a literal, or a variable reference
+type value struct {
+	variable string
+	literal  interface{}
+	list     []value
+	isList   bool
+}
+
+func (v value) resolve(vars map[string]interface{}) interface{} {
+	if v.variable != "" {
+		return vars[v.variable]
+	}
+	if v.isList {
+		result := make([]interface{}, len(v.list))
+		for i, item := range v.list {
+			result[i] = item.resolve(vars)
+		}
+		return result
+	}
+	return v.literal
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenString
+	tokenInt
+	tokenFloat
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	// skip ignored tokens: whitespace, commas and comments
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.pos++
+		} else if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		} else {
+			break
+		}
+	}
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case c == '.':
+		if strings.HasPrefix(l.src[l.pos:], "...") {
+			return token{}, fmt.Errorf("fragments are not supported, at %d", start)
+		}
+		return token{}, fmt.Errorf("unexpected character %q at %d", c, start)
+	case strings.IndexByte("{}()[]:!=$", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, text: string(c), pos: start}, nil
+	case c == '"':
+		l.pos++
+		var sb strings.Builder
+		for l.pos < len(l.src) {
+			c = l.src[l.pos]
+			if c == '"' {
+				l.pos++
+				return token{kind: tokenString, text: sb.String(), pos: start}, nil
+			}
+			if c == '\\' && l.pos+1 < len(l.src) {
+				l.pos++
+				switch e := l.src[l.pos]; e {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(e)
+				}
+				l.pos++
+				continue
+			}
+			sb.WriteByte(c)
+			l.pos++
+		}
+		return token{}, fmt.Errorf("unterminated string at %d", start)
+	case c == '-' || (c >= '0' && c <= '9'):
+		l.pos++
+		kind := tokenInt
+		for l.pos < len(l.src) {
+			c = l.src[l.pos]
+			if c >= '0' && c <= '9' {
+				l.pos++
+			} else if c == '.' || c == 'e' || c == 'E' {
+				kind = tokenFloat
+				l.pos++
+			} else {
+				break
+			}
+		}
+		return token{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		for l.pos < len(l.src) {
+			c = l.src[l.pos]
+			if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+				l.pos++
+			} else {
+				break
+			}
+		}
+		return token{kind: tokenName, text: l.src[start:l.pos], pos: start}, nil
+	}
+	return token{}, fmt.Errorf("unexpected character %q at %d", c, start)
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func parse(src string) (*document, error) {
+	p := &parser{lex: &lexer{src: src}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{}
+	for p.tok.kind != tokenEOF {
+		op, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		doc.operations = append(doc.operations, op)
+	}
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("no operation in the query")
+	}
+	return doc, nil
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lex.next()
+	return
+}
+
+func (p *parser) peek(text string) bool {
+	return p.tok.kind == tokenPunct && p.tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.peek(text) {
+		return fmt.Errorf("expected %q at %d, got %q", text, p.tok.pos, p.tok.text)
+	}
+	return p.advance()
+}
+
+func (p *parser) expectName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", fmt.Errorf("expected name at %d, got %q", p.tok.pos, p.tok.text)
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{variables: make(map[string]interface{})}
+	if p.tok.kind == tokenName {
+		if p.tok.text != "query" {
+			return nil, fmt.Errorf("%s is not supported, only query is supported", p.tok.text)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == tokenName {
+			op.name = p.tok.text
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		if p.peek("(") {
+			if err := p.parseVariableDefinitions(op); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var err error
+	op.selections, err = p.parseSelectionSet()
+	return op, err
+}
+
+func (p *parser) parseVariableDefinitions(op *operation) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		if err = p.parseType(); err != nil {
+			return err
+		}
+		if p.peek("=") {
+			if err = p.advance(); err != nil {
+				return err
+			}
+			v, err := p.parseValue(true)
+			if err != nil {
+				return err
+			}
+			op.variables[name] = v.resolve(nil)
+		}
+	}
+	return p.advance()
+}
+
+// parseType skips the type of variables, the values are checked by resolvers
+func (p *parser) parseType() error {
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.parseType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.expectName(); err != nil {
+		return err
+	}
+	if p.peek("!") {
+		return p.advance()
+	}
+	return nil
+}
+
+func (p *parser) parseSelectionSet() ([]*field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var fields []*field
+	for !p.peek("}") {
+		if p.tok.kind == tokenEOF {
+			return nil, fmt.Errorf("unexpected end of query")
+		}
+		f, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, p.advance()
+}
+
+func (p *parser) parseField() (*field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+	f := &field{name: name}
+	if p.peek(":") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		f.alias = name
+		if f.name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		f.args = make(map[string]value)
+		for !p.peek(")") {
+			argName, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect(":"); err != nil {
+				return nil, err
+			}
+			if f.args[argName], err = p.parseValue(false); err != nil {
+				return nil, err
+			}
+		}
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) parseValue(constant bool) (v value, err error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenPunct && tok.text == "$" && !constant:
+		if err = p.advance(); err != nil {
+			return
+		}
+		v.variable, err = p.expectName()
+		return
+	case tok.kind == tokenPunct && tok.text == "[":
+		if err = p.advance(); err != nil {
+			return
+		}
+		v.isList = true
+		for !p.peek("]") {
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return v, err
+			}
+			v.list = append(v.list, item)
+		}
+		err = p.advance()
+		return
+	case tok.kind == tokenString:
+		v.literal = tok.text
+	case tok.kind == tokenInt:
+		n, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return v, fmt.Errorf("invalid int %s at %d", tok.text, tok.pos)
+		}
+		v.literal = float64(n)
+	case tok.kind == tokenFloat:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return v, fmt.Errorf("invalid float %s at %d", tok.text, tok.pos)
+		}
+		v.literal = f
+	case tok.kind == tokenName:
+		switch tok.text {
+		case "true":
+			v.literal = true
+		case "false":
+			v.literal = false
+		case "null":
+			v.literal = nil
+		default:
+			// enum values are passed as strings
+			v.literal = tok.text
+		}
+	default:
+		return v, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+	}
+	err = p.advance()
+	return
+}
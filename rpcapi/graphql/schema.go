@@ -0,0 +1,509 @@
+package graphql
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+const maxPageSize = 100
+const defaultPageSize = 10
+
+// Chain is the ledger reader used by the resolvers
+type Chain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error)
+	GetSnapshotBlockByHash(hash types.Hash) (*ledger.SnapshotBlock, error)
+	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error)
+	GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error)
+	GetAccountBlocksByHeight(addr types.Address, height uint64, count uint64) ([]*ledger.AccountBlock, error)
+	GetLatestAccountHeight(addr types.Address) (uint64, error)
+	GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountBlock, error)
+	GetConfirmSnapshotHeaderByAbHash(abHash types.Hash) (*ledger.SnapshotBlock, error)
+	GetBalanceMap(addr types.Address) (map[types.TokenTypeId]*big.Int, error)
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+	GetTokenInfoById(tokenId types.TokenTypeId) (*types.TokenInfo, error)
+}
+
+type account struct {
+	address types.Address
+}
+
+type balance struct {
+	tokenId types.TokenTypeId
+	amount  *big.Int
+}
+
+type accountBlockPage struct {
+	blocks     []interface{}
+	hasMore    bool
+	nextBefore uint64
+}
+
+type tokenInfo struct {
+	id   types.TokenTypeId
+	info *types.TokenInfo
+}
+
+type snapshotContent struct {
+	address types.Address
+	hh      *ledger.HashHeight
+}
+
+func newSchema(c Chain) schema {
+	return schema{
+		queryType: objectDef{
+			"latestSnapshotBlock": {typ: "SnapshotBlock", resolve: func(_ interface{}, _ args) (interface{}, error) {
+				return snapshotBlockOrNil(c.GetLatestSnapshotBlock(), nil)
+			}},
+			"snapshotBlock": {typ: "SnapshotBlock", resolve: func(_ interface{}, a args) (interface{}, error) {
+				if hash, ok, err := a.String("hash"); err != nil {
+					return nil, err
+				} else if ok {
+					h, err := types.HexToHash(hash)
+					if err != nil {
+						return nil, err
+					}
+					return snapshotBlockOrNil(c.GetSnapshotBlockByHash(h))
+				}
+				height, ok, err := heightArg(a, "height")
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, fmt.Errorf("hash or height is required")
+				}
+				return snapshotBlockOrNil(c.GetSnapshotBlockByHeight(height))
+			}},
+			"snapshotBlocks": {typ: "SnapshotBlock", size: pageSizeOf("count"), resolve: func(_ interface{}, a args) (interface{}, error) {
+				from, ok, err := heightArg(a, "from")
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, fmt.Errorf("from is required")
+				}
+				count, err := pageSize(a, "count")
+				if err != nil {
+					return nil, err
+				}
+				blocks, err := c.GetSnapshotBlocksByHeight(from, true, count)
+				if err != nil {
+					return nil, err
+				}
+				result := make([]interface{}, len(blocks))
+				for i, b := range blocks {
+					result[i] = b
+				}
+				return result, nil
+			}},
+			"account": {typ: "Account", resolve: func(_ interface{}, a args) (interface{}, error) {
+				addr, err := addressArg(a, "address")
+				if err != nil {
+					return nil, err
+				}
+				return &account{address: addr}, nil
+			}},
+			"accountBlock": {typ: "AccountBlock", resolve: func(_ interface{}, a args) (interface{}, error) {
+				hash, ok, err := a.String("hash")
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, fmt.Errorf("hash is required")
+				}
+				h, err := types.HexToHash(hash)
+				if err != nil {
+					return nil, err
+				}
+				return accountBlockOrNil(c.GetAccountBlockByHash(h))
+			}},
+			"token": {typ: "Token", resolve: func(_ interface{}, a args) (interface{}, error) {
+				id, ok, err := a.String("id")
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					return nil, fmt.Errorf("id is required")
+				}
+				tti, err := types.HexToTokenTypeId(id)
+				if err != nil {
+					return nil, err
+				}
+				return tokenOrNil(c, tti)
+			}},
+		},
+
+		"Account": objectDef{
+			"address": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*account).address.String(), nil
+			}},
+			"height": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				height, err := c.GetLatestAccountHeight(p.(*account).address)
+				if err != nil {
+					return nil, err
+				}
+				return strconv.FormatUint(height, 10), nil
+			}},
+			"balances": {typ: "Balance", size: fixedSize(defaultPageSize), resolve: func(p interface{}, _ args) (interface{}, error) {
+				m, err := c.GetBalanceMap(p.(*account).address)
+				if err != nil {
+					return nil, err
+				}
+				result := make([]interface{}, 0, len(m))
+				for tti, amount := range m {
+					result = append(result, &balance{tokenId: tti, amount: amount})
+				}
+				sort.Slice(result, func(i, j int) bool {
+					return result[i].(*balance).tokenId.String() < result[j].(*balance).tokenId.String()
+				})
+				return result, nil
+			}},
+			"blocks": {typ: "AccountBlockPage", size: pageSizeOf("first"), resolve: func(p interface{}, a args) (interface{}, error) {
+				addr := p.(*account).address
+				count, err := pageSize(a, "first")
+				if err != nil {
+					return nil, err
+				}
+				before, ok, err := heightArg(a, "before")
+				if err != nil {
+					return nil, err
+				}
+				if !ok {
+					latest, err := c.GetLatestAccountHeight(addr)
+					if err != nil {
+						return nil, err
+					}
+					before = latest + 1
+				}
+				if before <= 1 {
+					return &accountBlockPage{}, nil
+				}
+				if count > before-1 {
+					count = before - 1
+				}
+				// blocks are returned from high to low
+				blocks, err := c.GetAccountBlocksByHeight(addr, before-1, count)
+				if err != nil {
+					return nil, err
+				}
+				page := &accountBlockPage{blocks: make([]interface{}, len(blocks))}
+				for i, b := range blocks {
+					page.blocks[i] = b
+				}
+				if len(blocks) > 0 {
+					page.nextBefore = blocks[len(blocks)-1].Height
+					page.hasMore = page.nextBefore > 1
+				}
+				return page, nil
+			}},
+		},
+
+		"AccountBlockPage": objectDef{
+			"blocks": {typ: "AccountBlock", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*accountBlockPage).blocks, nil
+			}},
+			"hasMore": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*accountBlockPage).hasMore, nil
+			}},
+			"nextBefore": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				page := p.(*accountBlockPage)
+				if !page.hasMore {
+					return nil, nil
+				}
+				return strconv.FormatUint(page.nextBefore, 10), nil
+			}},
+		},
+
+		"Balance": objectDef{
+			"tokenId": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*balance).tokenId.String(), nil
+			}},
+			"balance": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return bigString(p.(*balance).amount), nil
+			}},
+			"token": {typ: "Token", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return tokenOrNil(c, p.(*balance).tokenId)
+			}},
+		},
+
+		"AccountBlock": objectDef{
+			"hash": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.AccountBlock).Hash.String(), nil
+			}},
+			"prevHash": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.AccountBlock).PrevHash.String(), nil
+			}},
+			"height": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return strconv.FormatUint(p.(*ledger.AccountBlock).Height, 10), nil
+			}},
+			"blockType": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return int(p.(*ledger.AccountBlock).BlockType), nil
+			}},
+			"address": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.AccountBlock).AccountAddress.String(), nil
+			}},
+			"account": {typ: "Account", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return &account{address: p.(*ledger.AccountBlock).AccountAddress}, nil
+			}},
+			"toAddress": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				b := p.(*ledger.AccountBlock)
+				if !b.IsSendBlock() {
+					return nil, nil
+				}
+				return b.ToAddress.String(), nil
+			}},
+			"amount": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return bigString(p.(*ledger.AccountBlock).Amount), nil
+			}},
+			"fee": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return bigString(p.(*ledger.AccountBlock).Fee), nil
+			}},
+			"tokenId": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.AccountBlock).TokenId.String(), nil
+			}},
+			"token": {typ: "Token", resolve: func(p interface{}, _ args) (interface{}, error) {
+				b := p.(*ledger.AccountBlock)
+				if !b.IsSendBlock() {
+					return nil, nil
+				}
+				return tokenOrNil(c, b.TokenId)
+			}},
+			"data": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return hex.EncodeToString(p.(*ledger.AccountBlock).Data), nil
+			}},
+			"quotaUsed": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return strconv.FormatUint(p.(*ledger.AccountBlock).QuotaUsed, 10), nil
+			}},
+			"fromBlock": {typ: "AccountBlock", resolve: func(p interface{}, _ args) (interface{}, error) {
+				b := p.(*ledger.AccountBlock)
+				if !b.IsReceiveBlock() {
+					return nil, nil
+				}
+				return accountBlockOrNil(c.GetAccountBlockByHash(b.FromBlockHash))
+			}},
+			"receiveBlock": {typ: "AccountBlock", resolve: func(p interface{}, _ args) (interface{}, error) {
+				b := p.(*ledger.AccountBlock)
+				if !b.IsSendBlock() {
+					return nil, nil
+				}
+				return accountBlockOrNil(c.GetReceiveAbBySendAb(b.Hash))
+			}},
+			"snapshotBlock": {typ: "SnapshotBlock", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return snapshotBlockOrNil(c.GetConfirmSnapshotHeaderByAbHash(p.(*ledger.AccountBlock).Hash))
+			}},
+			"logs": {typ: "VmLog", size: fixedSize(defaultPageSize), resolve: func(p interface{}, _ args) (interface{}, error) {
+				b := p.(*ledger.AccountBlock)
+				if b.LogHash == nil {
+					return []interface{}{}, nil
+				}
+				logs, err := c.GetVmLogList(b.LogHash)
+				if err != nil {
+					return nil, err
+				}
+				result := make([]interface{}, len(logs))
+				for i, l := range logs {
+					result[i] = l
+				}
+				return result, nil
+			}},
+		},
+
+		"SnapshotBlock": objectDef{
+			"hash": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.SnapshotBlock).Hash.String(), nil
+			}},
+			"prevHash": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.SnapshotBlock).PrevHash.String(), nil
+			}},
+			"height": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return strconv.FormatUint(p.(*ledger.SnapshotBlock).Height, 10), nil
+			}},
+			"timestamp": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				sb := p.(*ledger.SnapshotBlock)
+				if sb.Timestamp == nil {
+					return nil, nil
+				}
+				return sb.Timestamp.Unix(), nil
+			}},
+			"producer": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*ledger.SnapshotBlock).Producer().String(), nil
+			}},
+			"snapshotContent": {typ: "SnapshotContent", size: fixedSize(maxPageSize), resolve: func(p interface{}, _ args) (interface{}, error) {
+				sb := p.(*ledger.SnapshotBlock)
+				if sb.SnapshotContent == nil {
+					// headers don't carry the content
+					full, err := c.GetSnapshotBlockByHash(sb.Hash)
+					if err != nil {
+						return nil, err
+					}
+					if full == nil {
+						return nil, nil
+					}
+					sb = full
+				}
+				result := make([]interface{}, 0, len(sb.SnapshotContent))
+				for addr, hh := range sb.SnapshotContent {
+					result = append(result, &snapshotContent{address: addr, hh: hh})
+				}
+				sort.Slice(result, func(i, j int) bool {
+					return result[i].(*snapshotContent).address.String() < result[j].(*snapshotContent).address.String()
+				})
+				return result, nil
+			}},
+		},
+
+		"SnapshotContent": objectDef{
+			"address": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*snapshotContent).address.String(), nil
+			}},
+			"hash": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*snapshotContent).hh.Hash.String(), nil
+			}},
+			"height": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return strconv.FormatUint(p.(*snapshotContent).hh.Height, 10), nil
+			}},
+			"block": {typ: "AccountBlock", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return accountBlockOrNil(c.GetAccountBlockByHash(p.(*snapshotContent).hh.Hash))
+			}},
+		},
+
+		"Token": objectDef{
+			"id": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*tokenInfo).id.String(), nil
+			}},
+			"name": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*tokenInfo).info.TokenName, nil
+			}},
+			"symbol": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return p.(*tokenInfo).info.TokenSymbol, nil
+			}},
+			"decimals": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return int(p.(*tokenInfo).info.Decimals), nil
+			}},
+			"totalSupply": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return bigString(p.(*tokenInfo).info.TotalSupply), nil
+			}},
+			"maxSupply": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return bigString(p.(*tokenInfo).info.MaxSupply), nil
+			}},
+			"owner": {typ: "Account", resolve: func(p interface{}, _ args) (interface{}, error) {
+				return &account{address: p.(*tokenInfo).info.Owner}, nil
+			}},
+		},
+
+		"VmLog": objectDef{
+			"topics": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				l := p.(*ledger.VmLog)
+				topics := make([]string, len(l.Topics))
+				for i, t := range l.Topics {
+					topics[i] = t.String()
+				}
+				return topics, nil
+			}},
+			"data": {resolve: func(p interface{}, _ args) (interface{}, error) {
+				return hex.EncodeToString(p.(*ledger.VmLog).Data), nil
+			}},
+		},
+	}
+}
+
+// the resolvers must return untyped nil for missing objects
+func snapshotBlockOrNil(b *ledger.SnapshotBlock, err error) (interface{}, error) {
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func accountBlockOrNil(b *ledger.AccountBlock, err error) (interface{}, error) {
+	if err != nil || b == nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func tokenOrNil(c Chain, tti types.TokenTypeId) (interface{}, error) {
+	info, err := c.GetTokenInfoById(tti)
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return &tokenInfo{id: tti, info: info}, nil
+}
+
+func bigString(b *big.Int) interface{} {
+	if b == nil {
+		return "0"
+	}
+	return b.String()
+}
+
+// heightArg accepts heights as strings, like the json rpc, or ints
+func heightArg(a args, name string) (uint64, bool, error) {
+	if s, ok, err := a.String(name); err == nil {
+		if !ok {
+			return 0, false, nil
+		}
+		h, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("argument %s should be a height", name)
+		}
+		return h, true, nil
+	}
+	n, ok, err := a.Int(name)
+	if err != nil || !ok {
+		return 0, ok, err
+	}
+	if n < 0 {
+		return 0, false, fmt.Errorf("argument %s should be a height", name)
+	}
+	return uint64(n), true, nil
+}
+
+func pageSize(a args, name string) (uint64, error) {
+	n, ok, err := a.Int(name)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return defaultPageSize, nil
+	}
+	if n <= 0 || n > maxPageSize {
+		return 0, fmt.Errorf("argument %s should be in [1, %d]", name, maxPageSize)
+	}
+	return uint64(n), nil
+}
+
+// pageSizeOf estimates the size of a list by the page size argument, the max page size if it's invalid
+func pageSizeOf(name string) func(a args) uint64 {
+	return func(a args) uint64 {
+		n, err := pageSize(a, name)
+		if err != nil {
+			return maxPageSize
+		}
+		return n
+	}
+}
+
+// fixedSize estimates the size of a list without the page size argument
+func fixedSize(n uint64) func(a args) uint64 {
+	return func(args) uint64 {
+		return n
+	}
+}
+
+func addressArg(a args, name string) (types.Address, error) {
+	s, ok, err := a.String(name)
+	if err != nil {
+		return types.Address{}, err
+	}
+	if !ok {
+		return types.Address{}, fmt.Errorf("%s is required", name)
+	}
+	return types.HexToAddress(s)
+}
@@ -0,0 +1,86 @@
+package grpcapi
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+func toSnapshotBlock(block *core.SnapshotBlock) *SnapshotBlock {
+	if block == nil {
+		return nil
+	}
+	result := &SnapshotBlock{
+		Hash:      block.Hash.Bytes(),
+		PrevHash:  block.PrevHash.Bytes(),
+		Height:    block.Height,
+		PublicKey: block.PublicKey,
+		Producer:  block.Producer().Bytes(),
+		Signature: block.Signature,
+		Seed:      block.Seed,
+		Version:   block.Version,
+	}
+	if block.Timestamp != nil {
+		result.Timestamp = block.Timestamp.Unix()
+	}
+	if block.SeedHash != nil {
+		result.SeedHash = block.SeedHash.Bytes()
+	}
+
+	result.SnapshotContent = make([]*SnapshotContentItem, 0, len(block.SnapshotContent))
+	for addr, hashHeight := range block.SnapshotContent {
+		result.SnapshotContent = append(result.SnapshotContent, &SnapshotContentItem{
+			Address: addr.Bytes(),
+			Block: &HashHeight{
+				Hash:   hashHeight.Hash.Bytes(),
+				Height: hashHeight.Height,
+			},
+		})
+	}
+	sort.Slice(result.SnapshotContent, func(i, j int) bool {
+		return bytes.Compare(result.SnapshotContent[i].Address, result.SnapshotContent[j].Address) < 0
+	})
+	return result
+}
+
+func toAccountBlock(block *core.AccountBlock) *AccountBlock {
+	if block == nil {
+		return nil
+	}
+	result := &AccountBlock{
+		BlockType:      uint32(block.BlockType),
+		Hash:           block.Hash.Bytes(),
+		PrevHash:       block.PrevHash.Bytes(),
+		Height:         block.Height,
+		AccountAddress: block.AccountAddress.Bytes(),
+		Producer:       block.Producer().Bytes(),
+		PublicKey:      block.PublicKey,
+		ToAddress:      block.ToAddress.Bytes(),
+		Amount:         bigIntString(block.Amount),
+		TokenId:        block.TokenId.Bytes(),
+		FromBlockHash:  block.FromBlockHash.Bytes(),
+		Data:           block.Data,
+		Quota:          block.Quota,
+		QuotaUsed:      block.QuotaUsed,
+		Fee:            bigIntString(block.Fee),
+		Difficulty:     bigIntString(block.Difficulty),
+		Nonce:          block.Nonce,
+		Signature:      block.Signature,
+	}
+	if block.LogHash != nil {
+		result.LogHash = block.LogHash.Bytes()
+	}
+	for _, send := range block.SendBlockList {
+		result.SendBlockList = append(result.SendBlockList, toAccountBlock(send))
+	}
+	return result
+}
+
+func bigIntString(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
@@ -0,0 +1,543 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// LedgerClient is the client API for Ledger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type LedgerClient interface {
+	GetLatestSnapshotBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SnapshotBlock, error)
+	GetSnapshotBlockByHeight(ctx context.Context, in *HeightRequest, opts ...grpc.CallOption) (*SnapshotBlock, error)
+	GetSnapshotBlockByHash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*SnapshotBlock, error)
+	GetSnapshotBlocks(ctx context.Context, in *SnapshotBlocksRequest, opts ...grpc.CallOption) (*SnapshotBlockList, error)
+	GetAccountBlockByHash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*AccountBlock, error)
+	GetLatestAccountBlock(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (*AccountBlock, error)
+	GetAccountBlockByHeight(ctx context.Context, in *AccountHeightRequest, opts ...grpc.CallOption) (*AccountBlock, error)
+	GetAccountBlocks(ctx context.Context, in *AccountBlocksRequest, opts ...grpc.CallOption) (*AccountBlockList, error)
+	SubscribeSnapshotBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Ledger_SubscribeSnapshotBlocksClient, error)
+	SubscribeAccountBlocks(ctx context.Context, in *AccountBlocksFilter, opts ...grpc.CallOption) (Ledger_SubscribeAccountBlocksClient, error)
+	SubscribeReorgs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Ledger_SubscribeReorgsClient, error)
+}
+
+type ledgerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLedgerClient(cc grpc.ClientConnInterface) LedgerClient {
+	return &ledgerClient{cc}
+}
+
+func (c *ledgerClient) GetLatestSnapshotBlock(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SnapshotBlock, error) {
+	out := new(SnapshotBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetLatestSnapshotBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetSnapshotBlockByHeight(ctx context.Context, in *HeightRequest, opts ...grpc.CallOption) (*SnapshotBlock, error) {
+	out := new(SnapshotBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetSnapshotBlockByHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetSnapshotBlockByHash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*SnapshotBlock, error) {
+	out := new(SnapshotBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetSnapshotBlockByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetSnapshotBlocks(ctx context.Context, in *SnapshotBlocksRequest, opts ...grpc.CallOption) (*SnapshotBlockList, error) {
+	out := new(SnapshotBlockList)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetSnapshotBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetAccountBlockByHash(ctx context.Context, in *HashRequest, opts ...grpc.CallOption) (*AccountBlock, error) {
+	out := new(AccountBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetAccountBlockByHash", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetLatestAccountBlock(ctx context.Context, in *AddressRequest, opts ...grpc.CallOption) (*AccountBlock, error) {
+	out := new(AccountBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetLatestAccountBlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetAccountBlockByHeight(ctx context.Context, in *AccountHeightRequest, opts ...grpc.CallOption) (*AccountBlock, error) {
+	out := new(AccountBlock)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetAccountBlockByHeight", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) GetAccountBlocks(ctx context.Context, in *AccountBlocksRequest, opts ...grpc.CallOption) (*AccountBlockList, error) {
+	out := new(AccountBlockList)
+	err := c.cc.Invoke(ctx, "/grpcapi.Ledger/GetAccountBlocks", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ledgerClient) SubscribeSnapshotBlocks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Ledger_SubscribeSnapshotBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Ledger_ServiceDesc.Streams[0], "/grpcapi.Ledger/SubscribeSnapshotBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ledgerSubscribeSnapshotBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ledger_SubscribeSnapshotBlocksClient interface {
+	Recv() (*SnapshotBlock, error)
+	grpc.ClientStream
+}
+
+type ledgerSubscribeSnapshotBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *ledgerSubscribeSnapshotBlocksClient) Recv() (*SnapshotBlock, error) {
+	m := new(SnapshotBlock)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ledgerClient) SubscribeAccountBlocks(ctx context.Context, in *AccountBlocksFilter, opts ...grpc.CallOption) (Ledger_SubscribeAccountBlocksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Ledger_ServiceDesc.Streams[1], "/grpcapi.Ledger/SubscribeAccountBlocks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ledgerSubscribeAccountBlocksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ledger_SubscribeAccountBlocksClient interface {
+	Recv() (*AccountBlock, error)
+	grpc.ClientStream
+}
+
+type ledgerSubscribeAccountBlocksClient struct {
+	grpc.ClientStream
+}
+
+func (x *ledgerSubscribeAccountBlocksClient) Recv() (*AccountBlock, error) {
+	m := new(AccountBlock)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *ledgerClient) SubscribeReorgs(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Ledger_SubscribeReorgsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Ledger_ServiceDesc.Streams[2], "/grpcapi.Ledger/SubscribeReorgs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ledgerSubscribeReorgsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Ledger_SubscribeReorgsClient interface {
+	Recv() (*Reorg, error)
+	grpc.ClientStream
+}
+
+type ledgerSubscribeReorgsClient struct {
+	grpc.ClientStream
+}
+
+func (x *ledgerSubscribeReorgsClient) Recv() (*Reorg, error) {
+	m := new(Reorg)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LedgerServer is the server API for Ledger service.
+// All implementations must embed UnimplementedLedgerServer
+// for forward compatibility
+type LedgerServer interface {
+	GetLatestSnapshotBlock(context.Context, *Empty) (*SnapshotBlock, error)
+	GetSnapshotBlockByHeight(context.Context, *HeightRequest) (*SnapshotBlock, error)
+	GetSnapshotBlockByHash(context.Context, *HashRequest) (*SnapshotBlock, error)
+	GetSnapshotBlocks(context.Context, *SnapshotBlocksRequest) (*SnapshotBlockList, error)
+	GetAccountBlockByHash(context.Context, *HashRequest) (*AccountBlock, error)
+	GetLatestAccountBlock(context.Context, *AddressRequest) (*AccountBlock, error)
+	GetAccountBlockByHeight(context.Context, *AccountHeightRequest) (*AccountBlock, error)
+	GetAccountBlocks(context.Context, *AccountBlocksRequest) (*AccountBlockList, error)
+	SubscribeSnapshotBlocks(*Empty, Ledger_SubscribeSnapshotBlocksServer) error
+	SubscribeAccountBlocks(*AccountBlocksFilter, Ledger_SubscribeAccountBlocksServer) error
+	SubscribeReorgs(*Empty, Ledger_SubscribeReorgsServer) error
+	mustEmbedUnimplementedLedgerServer()
+}
+
+// UnimplementedLedgerServer must be embedded to have forward compatible implementations.
+type UnimplementedLedgerServer struct {
+}
+
+func (UnimplementedLedgerServer) GetLatestSnapshotBlock(context.Context, *Empty) (*SnapshotBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestSnapshotBlock not implemented")
+}
+func (UnimplementedLedgerServer) GetSnapshotBlockByHeight(context.Context, *HeightRequest) (*SnapshotBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshotBlockByHeight not implemented")
+}
+func (UnimplementedLedgerServer) GetSnapshotBlockByHash(context.Context, *HashRequest) (*SnapshotBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshotBlockByHash not implemented")
+}
+func (UnimplementedLedgerServer) GetSnapshotBlocks(context.Context, *SnapshotBlocksRequest) (*SnapshotBlockList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshotBlocks not implemented")
+}
+func (UnimplementedLedgerServer) GetAccountBlockByHash(context.Context, *HashRequest) (*AccountBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountBlockByHash not implemented")
+}
+func (UnimplementedLedgerServer) GetLatestAccountBlock(context.Context, *AddressRequest) (*AccountBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestAccountBlock not implemented")
+}
+func (UnimplementedLedgerServer) GetAccountBlockByHeight(context.Context, *AccountHeightRequest) (*AccountBlock, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountBlockByHeight not implemented")
+}
+func (UnimplementedLedgerServer) GetAccountBlocks(context.Context, *AccountBlocksRequest) (*AccountBlockList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccountBlocks not implemented")
+}
+func (UnimplementedLedgerServer) SubscribeSnapshotBlocks(*Empty, Ledger_SubscribeSnapshotBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeSnapshotBlocks not implemented")
+}
+func (UnimplementedLedgerServer) SubscribeAccountBlocks(*AccountBlocksFilter, Ledger_SubscribeAccountBlocksServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAccountBlocks not implemented")
+}
+func (UnimplementedLedgerServer) SubscribeReorgs(*Empty, Ledger_SubscribeReorgsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeReorgs not implemented")
+}
+func (UnimplementedLedgerServer) mustEmbedUnimplementedLedgerServer() {}
+
+// UnsafeLedgerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LedgerServer will
+// result in compilation errors.
+type UnsafeLedgerServer interface {
+	mustEmbedUnimplementedLedgerServer()
+}
+
+func RegisterLedgerServer(s grpc.ServiceRegistrar, srv LedgerServer) {
+	s.RegisterService(&Ledger_ServiceDesc, srv)
+}
+
+func _Ledger_GetLatestSnapshotBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetLatestSnapshotBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetLatestSnapshotBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetLatestSnapshotBlock(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetSnapshotBlockByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetSnapshotBlockByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetSnapshotBlockByHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetSnapshotBlockByHeight(ctx, req.(*HeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetSnapshotBlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetSnapshotBlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetSnapshotBlockByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetSnapshotBlockByHash(ctx, req.(*HashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetSnapshotBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetSnapshotBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetSnapshotBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetSnapshotBlocks(ctx, req.(*SnapshotBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetAccountBlockByHash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetAccountBlockByHash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetAccountBlockByHash",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetAccountBlockByHash(ctx, req.(*HashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetLatestAccountBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetLatestAccountBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetLatestAccountBlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetLatestAccountBlock(ctx, req.(*AddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetAccountBlockByHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountHeightRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetAccountBlockByHeight(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetAccountBlockByHeight",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetAccountBlockByHeight(ctx, req.(*AccountHeightRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_GetAccountBlocks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccountBlocksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LedgerServer).GetAccountBlocks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/grpcapi.Ledger/GetAccountBlocks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LedgerServer).GetAccountBlocks(ctx, req.(*AccountBlocksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ledger_SubscribeSnapshotBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LedgerServer).SubscribeSnapshotBlocks(m, &ledgerSubscribeSnapshotBlocksServer{stream})
+}
+
+type Ledger_SubscribeSnapshotBlocksServer interface {
+	Send(*SnapshotBlock) error
+	grpc.ServerStream
+}
+
+type ledgerSubscribeSnapshotBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *ledgerSubscribeSnapshotBlocksServer) Send(m *SnapshotBlock) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Ledger_SubscribeAccountBlocks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AccountBlocksFilter)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LedgerServer).SubscribeAccountBlocks(m, &ledgerSubscribeAccountBlocksServer{stream})
+}
+
+type Ledger_SubscribeAccountBlocksServer interface {
+	Send(*AccountBlock) error
+	grpc.ServerStream
+}
+
+type ledgerSubscribeAccountBlocksServer struct {
+	grpc.ServerStream
+}
+
+func (x *ledgerSubscribeAccountBlocksServer) Send(m *AccountBlock) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Ledger_SubscribeReorgs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LedgerServer).SubscribeReorgs(m, &ledgerSubscribeReorgsServer{stream})
+}
+
+type Ledger_SubscribeReorgsServer interface {
+	Send(*Reorg) error
+	grpc.ServerStream
+}
+
+type ledgerSubscribeReorgsServer struct {
+	grpc.ServerStream
+}
+
+func (x *ledgerSubscribeReorgsServer) Send(m *Reorg) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Ledger_ServiceDesc is the grpc.ServiceDesc for Ledger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Ledger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "grpcapi.Ledger",
+	HandlerType: (*LedgerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetLatestSnapshotBlock",
+			Handler:    _Ledger_GetLatestSnapshotBlock_Handler,
+		},
+		{
+			MethodName: "GetSnapshotBlockByHeight",
+			Handler:    _Ledger_GetSnapshotBlockByHeight_Handler,
+		},
+		{
+			MethodName: "GetSnapshotBlockByHash",
+			Handler:    _Ledger_GetSnapshotBlockByHash_Handler,
+		},
+		{
+			MethodName: "GetSnapshotBlocks",
+			Handler:    _Ledger_GetSnapshotBlocks_Handler,
+		},
+		{
+			MethodName: "GetAccountBlockByHash",
+			Handler:    _Ledger_GetAccountBlockByHash_Handler,
+		},
+		{
+			MethodName: "GetLatestAccountBlock",
+			Handler:    _Ledger_GetLatestAccountBlock_Handler,
+		},
+		{
+			MethodName: "GetAccountBlockByHeight",
+			Handler:    _Ledger_GetAccountBlockByHeight_Handler,
+		},
+		{
+			MethodName: "GetAccountBlocks",
+			Handler:    _Ledger_GetAccountBlocks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeSnapshotBlocks",
+			Handler:       _Ledger_SubscribeSnapshotBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeAccountBlocks",
+			Handler:       _Ledger_SubscribeAccountBlocks_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeReorgs",
+			Handler:       _Ledger_SubscribeReorgs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "ledger.proto",
+}
@@ -0,0 +1,118 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/vitelabs/go-vite/v2/rpc"
+)
+
+// apiKeyMetadata is the metadata key of the api key, the same as the header of the http endpoint
+const apiKeyMetadata = "x-api-key"
+
+// Server serves the ledger service on a tcp endpoint
+type Server struct {
+	service  *Service
+	server   *grpc.Server
+	listener net.Listener
+	limiter  atomic.Value
+}
+
+func NewServer(c Chain) *Server {
+	return &Server{service: NewService(c)}
+}
+
+func (s *Server) Start(endpoint string) error {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.server = grpc.NewServer(grpc.UnaryInterceptor(s.unaryLimit), grpc.StreamInterceptor(s.streamLimit))
+	RegisterLedgerServer(s.server, s.service)
+	s.service.Start()
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			s.service.log.Error("grpc server stopped", "err", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop() {
+	if s.server == nil {
+		return
+	}
+	s.service.Stop()
+	// streams are endless, don't wait for them
+	s.server.Stop()
+	s.server = nil
+}
+
+// Addr returns the listening address
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// SetLimiter limits the calls like the JSON-RPC requests, nil removes the limits.
+// The api key is read from the x-api-key metadata, and a call of the method Foo is checked as grpc_foo.
+func (s *Server) SetLimiter(l *rpc.Limiter) {
+	s.limiter.Store(l)
+}
+
+func (s *Server) unaryLimit(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	release, err := s.limit(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+func (s *Server) streamLimit(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := s.limit(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, ss)
+}
+
+func (s *Server) limit(ctx context.Context, fullMethod string) (release func(), err error) {
+	l, _ := s.limiter.Load().(*rpc.Limiter)
+	if l == nil {
+		return func() {}, nil
+	}
+	var remote, apiKey string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		remote = p.Addr.String()
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(apiKeyMetadata); len(keys) > 0 {
+			apiKey = keys[0]
+		}
+	}
+	release, err = l.Acquire(remote, apiKey, limitMethod(fullMethod))
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return release, nil
+}
+
+// limitMethod converts /grpcapi.Ledger/GetLatestSnapshotBlock to grpc_getLatestSnapshotBlock
+func limitMethod(fullMethod string) string {
+	name := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if name == "" {
+		return "grpc_"
+	}
+	return "grpc_" + strings.ToLower(name[:1]) + name[1:]
+}
@@ -0,0 +1,77 @@
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/rpc"
+)
+
+func TestLimitMethod(t *testing.T) {
+	if m := limitMethod("/grpcapi.Ledger/GetLatestSnapshotBlock"); m != "grpc_getLatestSnapshotBlock" {
+		t.Fatalf("unexpected method %s", m)
+	}
+}
+
+func TestServer_SetLimiter(t *testing.T) {
+	block := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, Hash: types.Hash{1}, Height: 1}
+	server := NewServer(&mockChain{blocks: map[types.Hash]*ledger.AccountBlock{block.Hash: block}})
+	server.SetLimiter(rpc.NewLimiter(config.RPCLimit{
+		RequireAPIKey: true,
+		APIKeys: map[string]*config.RPCAPIKey{
+			"k1": {Methods: []string{"grpc_getAccountBlockByHash"}},
+			"k2": {Methods: []string{"grpc_subscribeReorgs"}},
+		},
+	}))
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	conn, err := grpc.Dial(server.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := NewLedgerClient(conn)
+
+	call := func(key string) error {
+		ctx := context.Background()
+		if key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, apiKeyMetadata, key)
+		}
+		_, err := client.GetAccountBlockByHash(ctx, &HashRequest{Hash: block.Hash.Bytes()})
+		return err
+	}
+	if err := call(""); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected api key is required, got %v", err)
+	}
+	if err := call("k2"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected method is not allowed, got %v", err)
+	}
+	if err := call("k1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the streams are limited too
+	stream, err := client.SubscribeSnapshotBlocks(metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "k1"), &Empty{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected method is not allowed, got %v", err)
+	}
+
+	server.SetLimiter(nil)
+	if err := call(""); err != nil {
+		t.Fatal(err)
+	}
+}
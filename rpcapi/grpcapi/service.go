@@ -0,0 +1,357 @@
+package grpcapi
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+// The pb files are generated by protoc v3.17.3, protoc-gen-go v1.27.1 and protoc-gen-go-grpc v1.1.0:
+//
+//	go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.27.1
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.1.0
+//
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ledger.proto
+
+const (
+	maxBlocksCount = 1000
+
+	// events are dropped for the subscriber and the stream is closed if the buffer is full
+	subscriptionBuffer = 1024
+)
+
+// Chain is the part of the chain used by the ledger service
+type Chain interface {
+	GetLatestSnapshotBlock() *core.SnapshotBlock
+	GetSnapshotBlockByHeight(height uint64) (*core.SnapshotBlock, error)
+	GetSnapshotBlockByHash(hash types.Hash) (*core.SnapshotBlock, error)
+	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*core.SnapshotBlock, error)
+
+	GetAccountBlockByHash(blockHash types.Hash) (*core.AccountBlock, error)
+	GetLatestAccountBlock(addr types.Address) (*core.AccountBlock, error)
+	GetAccountBlockByHeight(addr types.Address, height uint64) (*core.AccountBlock, error)
+	GetAccountBlocksByHeight(addr types.Address, height uint64, count uint64) ([]*core.AccountBlock, error)
+
+	Register(listener interfaces.EventListener)
+	UnRegister(listener interfaces.EventListener)
+}
+
+// subscription buffers the events of one stream
+type subscription struct {
+	ch        chan interface{}
+	addresses map[types.Address]struct{} // nil means all addresses, only for account blocks
+	overflow  chan struct{}
+}
+
+func (sub *subscription) push(event interface{}) bool {
+	select {
+	case sub.ch <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// Service implements LedgerServer, and listens the chain to feed the streams
+type Service struct {
+	UnimplementedLedgerServer
+
+	chain Chain
+	log   log15.Logger
+
+	mu           sync.Mutex
+	snapshotSubs map[*subscription]struct{}
+	accountSubs  map[*subscription]struct{}
+	reorgSubs    map[*subscription]struct{}
+}
+
+func NewService(c Chain) *Service {
+	return &Service{
+		chain:        c,
+		log:          log15.New("module", "rpc_api/grpc"),
+		snapshotSubs: make(map[*subscription]struct{}),
+		accountSubs:  make(map[*subscription]struct{}),
+		reorgSubs:    make(map[*subscription]struct{}),
+	}
+}
+
+func (s *Service) Start() {
+	s.chain.Register(s)
+}
+
+func (s *Service) Stop() {
+	s.chain.UnRegister(s)
+}
+
+func (s *Service) GetLatestSnapshotBlock(ctx context.Context, req *Empty) (*SnapshotBlock, error) {
+	block := s.chain.GetLatestSnapshotBlock()
+	if block == nil {
+		return nil, status.Error(codes.NotFound, "no snapshot block")
+	}
+	return toSnapshotBlock(block), nil
+}
+
+func (s *Service) GetSnapshotBlockByHeight(ctx context.Context, req *HeightRequest) (*SnapshotBlock, error) {
+	block, err := s.chain.GetSnapshotBlockByHeight(req.Height)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "snapshot block %d not found", req.Height)
+	}
+	return toSnapshotBlock(block), nil
+}
+
+func (s *Service) GetSnapshotBlockByHash(ctx context.Context, req *HashRequest) (*SnapshotBlock, error) {
+	hash, err := types.BytesToHash(req.Hash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	block, err := s.chain.GetSnapshotBlockByHash(hash)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "snapshot block %s not found", hash)
+	}
+	return toSnapshotBlock(block), nil
+}
+
+func (s *Service) GetSnapshotBlocks(ctx context.Context, req *SnapshotBlocksRequest) (*SnapshotBlockList, error) {
+	if req.Count == 0 || req.Count > maxBlocksCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count should be in [1, %d]", maxBlocksCount)
+	}
+	blocks, err := s.chain.GetSnapshotBlocksByHeight(req.Height, false, req.Count)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	list := &SnapshotBlockList{Blocks: make([]*SnapshotBlock, 0, len(blocks))}
+	for _, block := range blocks {
+		list.Blocks = append(list.Blocks, toSnapshotBlock(block))
+	}
+	return list, nil
+}
+
+func (s *Service) GetAccountBlockByHash(ctx context.Context, req *HashRequest) (*AccountBlock, error) {
+	hash, err := types.BytesToHash(req.Hash)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	block, err := s.chain.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "account block %s not found", hash)
+	}
+	return toAccountBlock(block), nil
+}
+
+func (s *Service) GetLatestAccountBlock(ctx context.Context, req *AddressRequest) (*AccountBlock, error) {
+	addr, err := types.BytesToAddress(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	block, err := s.chain.GetLatestAccountBlock(addr)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "account %s has no block", addr)
+	}
+	return toAccountBlock(block), nil
+}
+
+func (s *Service) GetAccountBlockByHeight(ctx context.Context, req *AccountHeightRequest) (*AccountBlock, error) {
+	addr, err := types.BytesToAddress(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	block, err := s.chain.GetAccountBlockByHeight(addr, req.Height)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if block == nil {
+		return nil, status.Errorf(codes.NotFound, "account block %s %d not found", addr, req.Height)
+	}
+	return toAccountBlock(block), nil
+}
+
+func (s *Service) GetAccountBlocks(ctx context.Context, req *AccountBlocksRequest) (*AccountBlockList, error) {
+	addr, err := types.BytesToAddress(req.Address)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.Count == 0 || req.Count > maxBlocksCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count should be in [1, %d]", maxBlocksCount)
+	}
+	blocks, err := s.chain.GetAccountBlocksByHeight(addr, req.Height, req.Count)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	list := &AccountBlockList{Blocks: make([]*AccountBlock, 0, len(blocks))}
+	for _, block := range blocks {
+		list.Blocks = append(list.Blocks, toAccountBlock(block))
+	}
+	return list, nil
+}
+
+func (s *Service) SubscribeSnapshotBlocks(req *Empty, stream Ledger_SubscribeSnapshotBlocksServer) error {
+	return s.serve(s.snapshotSubs, &subscription{}, stream.Context(), func(event interface{}) error {
+		return stream.Send(event.(*SnapshotBlock))
+	})
+}
+
+func (s *Service) SubscribeAccountBlocks(req *AccountBlocksFilter, stream Ledger_SubscribeAccountBlocksServer) error {
+	sub := &subscription{}
+	if len(req.Addresses) > 0 {
+		sub.addresses = make(map[types.Address]struct{}, len(req.Addresses))
+		for _, b := range req.Addresses {
+			addr, err := types.BytesToAddress(b)
+			if err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			sub.addresses[addr] = struct{}{}
+		}
+	}
+	return s.serve(s.accountSubs, sub, stream.Context(), func(event interface{}) error {
+		return stream.Send(event.(*AccountBlock))
+	})
+}
+
+func (s *Service) SubscribeReorgs(req *Empty, stream Ledger_SubscribeReorgsServer) error {
+	return s.serve(s.reorgSubs, &subscription{}, stream.Context(), func(event interface{}) error {
+		return stream.Send(event.(*Reorg))
+	})
+}
+
+// serve registers the subscription to subs, and sends the events until the stream is done
+func (s *Service) serve(subs map[*subscription]struct{}, sub *subscription, ctx context.Context, send func(event interface{}) error) error {
+	sub.ch = make(chan interface{}, subscriptionBuffer)
+	sub.overflow = make(chan struct{})
+
+	s.mu.Lock()
+	subs[sub] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(subs, sub)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case event := <-sub.ch:
+			if err := send(event); err != nil {
+				return err
+			}
+		case <-sub.overflow:
+			return status.Error(codes.ResourceExhausted, "subscriber is too slow, events are dropped")
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// publish pushes the event to subs, it never blocks the chain
+func (s *Service) publish(subs map[*subscription]struct{}, event interface{}, match func(sub *subscription) bool) {
+	for sub := range subs {
+		if match != nil && !match(sub) {
+			continue
+		}
+		if !sub.push(event) {
+			s.log.Warn("grpc subscriber overflow, close it")
+			close(sub.overflow)
+			delete(subs, sub)
+		}
+	}
+}
+
+func (s *Service) PrepareInsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	return nil
+}
+
+func (s *Service) InsertAccountBlocks(blocks []*interfaces.VmAccountBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.accountSubs) == 0 {
+		return nil
+	}
+	for _, b := range blocks {
+		addr := b.AccountBlock.AccountAddress
+		s.publish(s.accountSubs, toAccountBlock(b.AccountBlock), func(sub *subscription) bool {
+			if sub.addresses == nil {
+				return true
+			}
+			_, ok := sub.addresses[addr]
+			return ok
+		})
+	}
+	return nil
+}
+
+func (s *Service) PrepareInsertSnapshotBlocks(chunks []*core.SnapshotChunk) error {
+	return nil
+}
+
+func (s *Service) InsertSnapshotBlocks(chunks []*core.SnapshotChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.snapshotSubs) == 0 {
+		return nil
+	}
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock != nil {
+			s.publish(s.snapshotSubs, toSnapshotBlock(chunk.SnapshotBlock), nil)
+		}
+	}
+	return nil
+}
+
+func (s *Service) PrepareDeleteAccountBlocks(blocks []*core.AccountBlock) error {
+	return nil
+}
+
+func (s *Service) DeleteAccountBlocks(blocks []*core.AccountBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.reorgSubs) == 0 || len(blocks) == 0 {
+		return nil
+	}
+	reorg := &Reorg{}
+	for _, b := range blocks {
+		reorg.AccountBlocks = append(reorg.AccountBlocks, toAccountBlock(b))
+	}
+	s.publish(s.reorgSubs, reorg, nil)
+	return nil
+}
+
+func (s *Service) PrepareDeleteSnapshotBlocks(chunks []*core.SnapshotChunk) error {
+	return nil
+}
+
+func (s *Service) DeleteSnapshotBlocks(chunks []*core.SnapshotChunk) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.reorgSubs) == 0 || len(chunks) == 0 {
+		return nil
+	}
+	reorg := &Reorg{}
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock != nil {
+			reorg.SnapshotBlocks = append(reorg.SnapshotBlocks, toSnapshotBlock(chunk.SnapshotBlock))
+		}
+		for _, b := range chunk.AccountBlocks {
+			reorg.AccountBlocks = append(reorg.AccountBlocks, toAccountBlock(b))
+		}
+	}
+	s.publish(s.reorgSubs, reorg, nil)
+	return nil
+}
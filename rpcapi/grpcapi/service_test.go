@@ -0,0 +1,153 @@
+package grpcapi
+
+import (
+	"context"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+type mockChain struct {
+	Chain
+	blocks   map[types.Hash]*ledger.AccountBlock
+	listener interfaces.EventListener
+}
+
+func (c *mockChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[hash], nil
+}
+
+func (c *mockChain) Register(listener interfaces.EventListener) {
+	c.listener = listener
+}
+
+func (c *mockChain) UnRegister(listener interfaces.EventListener) {
+	c.listener = nil
+}
+
+func newTestClient(t *testing.T, c Chain) (LedgerClient, *Service, func()) {
+	lis := bufconn.Listen(1024 * 1024)
+	service := NewService(c)
+	service.Start()
+	server := grpc.NewServer()
+	RegisterLedgerServer(server, service)
+	go server.Serve(lis)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewLedgerClient(conn), service, func() {
+		conn.Close()
+		server.Stop()
+		service.Stop()
+	}
+}
+
+func waitSubscribed(t *testing.T, s *Service, subs map[*subscription]struct{}) {
+	for i := 0; i < 100; i++ {
+		s.mu.Lock()
+		n := len(subs)
+		s.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("subscription is not registered")
+}
+
+func TestService_GetAccountBlockByHash(t *testing.T) {
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.Hash{1},
+		Height:         3,
+		AccountAddress: types.Address{1},
+		Amount:         big.NewInt(100),
+	}
+	client, _, stop := newTestClient(t, &mockChain{blocks: map[types.Hash]*ledger.AccountBlock{block.Hash: block}})
+	defer stop()
+
+	result, err := client.GetAccountBlockByHash(context.Background(), &HashRequest{Hash: block.Hash.Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Height != 3 || result.Amount != "100" || result.BlockType != uint32(ledger.BlockTypeSendCall) {
+		t.Fatalf("unexpected block %v", result)
+	}
+
+	_, err = client.GetAccountBlockByHash(context.Background(), &HashRequest{Hash: types.Hash{2}.Bytes()})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	_, err = client.GetAccountBlockByHash(context.Background(), &HashRequest{Hash: []byte{1}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+}
+
+func TestService_SubscribeAccountBlocks(t *testing.T) {
+	c := &mockChain{}
+	client, service, stop := newTestClient(t, c)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.SubscribeAccountBlocks(ctx, &AccountBlocksFilter{Addresses: [][]byte{types.Address{2}.Bytes()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribed(t, service, service.accountSubs)
+
+	c.listener.InsertAccountBlocks([]*interfaces.VmAccountBlock{
+		{AccountBlock: &ledger.AccountBlock{Hash: types.Hash{1}, Height: 1, AccountAddress: types.Address{1}}},
+		{AccountBlock: &ledger.AccountBlock{Hash: types.Hash{2}, Height: 1, AccountAddress: types.Address{2}}},
+	})
+
+	block, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := types.BytesToHash(block.Hash); hash != (types.Hash{2}) {
+		t.Fatalf("unexpected block %s", hash)
+	}
+}
+
+func TestService_SubscribeReorgs(t *testing.T) {
+	c := &mockChain{}
+	client, service, stop := newTestClient(t, c)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.SubscribeReorgs(ctx, &Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitSubscribed(t, service, service.reorgSubs)
+
+	c.listener.DeleteSnapshotBlocks([]*ledger.SnapshotChunk{{
+		SnapshotBlock: &ledger.SnapshotBlock{Hash: types.Hash{5}, Height: 5},
+		AccountBlocks: []*ledger.AccountBlock{{Hash: types.Hash{1}, Height: 1}},
+	}})
+
+	reorg, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reorg.SnapshotBlocks) != 1 || reorg.SnapshotBlocks[0].Height != 5 || len(reorg.AccountBlocks) != 1 {
+		t.Fatalf("unexpected reorg %v", reorg)
+	}
+}
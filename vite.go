@@ -3,12 +3,15 @@ package vite
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/vitelabs/go-vite/v2/common/config"
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 	"github.com/vitelabs/go-vite/v2/ledger/chain"
 	"github.com/vitelabs/go-vite/v2/ledger/consensus"
 	"github.com/vitelabs/go-vite/v2/ledger/onroad"
@@ -36,24 +39,42 @@ type Vite struct {
 	pool          pool.BlockPool
 	consensus     consensus.Consensus
 	onRoad        *onroad.Manager
+	autoMiner     *consensus.AutoMiner
 }
 
 func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err error) {
 	// set upgrade
 	upgrade.InitUpgradeBox(cfg.UpgradeCfg.MakeUpgradeBox())
 
-	var account *wallet.Account
-	if cfg.Producer.IsMine() {
-		account, err = walletManager.AccountAtIndex(cfg.EntropyStorePath, cfg.Producer.GetCoinbase(), cfg.Producer.GetIndex())
+	var account interfaces.Account
+	if cfg.Producer.IsMine() && cfg.Producer.Ledger {
+		coinbase := cfg.Producer.GetCoinbase()
+		account, err = walletManager.AddLedgerAccount(cfg.Producer.LedgerDevicePath, cfg.Producer.GetIndex(), &coinbase)
+		if err != nil {
+			log.Error(fmt.Sprintf("coinBase is not the key on ledger device, coinBase is : %v", cfg.Producer.Coinbase), "err", err)
+			return nil, err
+		}
+		// the private key never leaves the device, the node is not bound to the coinbase in p2p
+	} else if cfg.Producer.IsMine() && cfg.Producer.RemoteSigner != "" {
+		coinbase := cfg.Producer.GetCoinbase()
+		accounts, err := walletManager.AddRemoteSigner(cfg.Producer.RemoteSigner, cfg.Producer.RemoteSignerToken, cfg.Producer.RemoteSignerTimeout, &coinbase)
+		if err != nil {
+			log.Error(fmt.Sprintf("coinBase is not held by remote signer, coinBase is : %v", cfg.Producer.Coinbase), "err", err)
+			return nil, err
+		}
+		account = accounts[0]
+	} else if cfg.Producer.IsMine() {
+		softAccount, err := walletManager.AccountAtIndex(cfg.EntropyStorePath, cfg.Producer.GetCoinbase(), cfg.Producer.GetIndex())
 		if err != nil {
 			log.Error(fmt.Sprintf("coinBase is not child of entropyStore, coinBase is : %v", cfg.Producer.Coinbase), "err", err)
 			return nil, err
 		}
 
-		cfg.Net.MineKey, err = account.PrivateKey()
+		cfg.Net.MineKey, err = softAccount.PrivateKey()
 		if err != nil {
-			return
+			return nil, err
 		}
+		account = softAccount
 	}
 
 	// chain
@@ -68,10 +89,18 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	if err != nil {
 		return nil, err
 	}
+	if cfg.Chain.PoolJournal {
+		pl.SetJournal(filepath.Join(cfg.DataDir, "pool", "journal"), cfg.Chain.PoolJournalMaxSize)
+	}
 	// consensus
 	cs := consensus.NewConsensus(chain, pl)
 
 	verifier := verifier.NewVerifier2(chain, cs)
+	checkpoints, err := ledger.ParseCheckpoints(cfg.Net.CheckpointList(chain.GetGenesisSnapshotBlock().Hash))
+	if err != nil {
+		return nil, err
+	}
+	verifier.SetCheckpoints(checkpoints)
 
 	// net
 	net, err := net.New(cfg.Net, chain, verifier, cs, pl)
@@ -91,10 +120,40 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	}
 
 	if account != nil {
-		vite.producer = producer.NewProducer(chain, net, account, cs, verifier.GetSnapshotVerifier(), pl)
+		p := producer.NewProducer(chain, net, account, cs, verifier.GetSnapshotVerifier(), pl)
+		if cfg.Producer.Standby || len(cfg.Producer.HeartbeatAddrs) > 0 || cfg.Producer.SlotLockDir != "" {
+			failover := &producer.FailoverConfig{
+				Standby:         cfg.Producer.Standby,
+				HeartbeatAddrs:  cfg.Producer.HeartbeatAddrs,
+				HeartbeatListen: cfg.Producer.HeartbeatListen,
+				MissedSlots:     cfg.Producer.StandbyMissedSlots,
+			}
+			if cfg.Producer.SlotLockDir == "" {
+				// the primary and the standby may both produce when the heartbeats are lost
+				return nil, errors.New("SlotLockDir must be set for the producer failover")
+			}
+			if failover.SlotLock, err = producer.NewFileSlotLock(cfg.Producer.SlotLockDir); err != nil {
+				return nil, err
+			}
+			p.SetFailover(failover)
+		}
+		if cfg.Producer.HealthEnabled() {
+			p.SetHealth(&producer.HealthConfig{
+				MaxSyncLag:   cfg.Producer.HealthMaxSyncLag,
+				MinPeers:     cfg.Producer.HealthMinPeers,
+				CheckFlusher: cfg.Producer.HealthCheckFlusher,
+				MaxClockSkew: cfg.Producer.HealthMaxClockSkew,
+			}, net)
+		}
+		vite.producer = p
 	}
 	// set onroad
 	vite.onRoad = onroad.NewManager(net, pl, vite.producer, vite.consensus, account)
+	if cfg.Producer != nil {
+		if err = vite.onRoad.SetOrderPolicy(cfg.Producer.OnRoadOrderPolicy, cfg.Producer.OnRoadPriorityCallers); err != nil {
+			return nil, err
+		}
+	}
 	return
 }
 
@@ -121,7 +180,13 @@ func (v *Vite) Start() (err error) {
 
 	v.chain.Start()
 
-	err = v.consensus.Init(consensus.Cfg(v.Config().Producer.ExternalMiner))
+	csCfg := consensus.Cfg(v.Config().Producer.ExternalMiner)
+	csCfg.ReplayWindow = v.Config().Producer.ConsensusReplayWindow
+	csCfg.Algos, err = v.Config().Genesis.ConsensusAlgos()
+	if err != nil {
+		return err
+	}
+	err = v.consensus.Init(csCfg)
 	if err != nil {
 		return err
 	}
@@ -142,11 +207,25 @@ func (v *Vite) Start() (err error) {
 			log.Error("producer.Start failed, error is "+err.Error(), "method", "vite.Start")
 			return err
 		}
+
+		if v.config.Producer.AutoMine {
+			v.autoMiner, err = consensus.NewAutoMiner(v.consensus, v.producer.GetCoinBase(), v.config.Producer.AutoMineInterval, func() bool {
+				return len(v.chain.GetAllUnconfirmedBlocks()) > 0
+			})
+			if err != nil {
+				log.Error("consensus.NewAutoMiner failed, error is "+err.Error(), "method", "vite.Start")
+				return err
+			}
+			v.autoMiner.Start()
+		}
 	}
 	return nil
 }
 
 func (v *Vite) Stop() (err error) {
+	if v.autoMiner != nil {
+		v.autoMiner.Stop()
+	}
 
 	v.net.Stop()
 	v.pool.Stop()
@@ -61,5 +61,15 @@ func (c *contract) run(vm *VM) (ret []byte, err error) {
 		c.intPool = nil
 	}()
 
+	if vm.tracer == nil {
+		return vm.i.runLoop(vm, c)
+	}
+	depth := vm.traceDepth
+	vm.tracer.CaptureStart(depth, c.codeAddr, c.code, c.data, c.quotaLeft)
+	vm.traceDepth++
+	defer func() {
+		vm.traceDepth--
+		vm.tracer.CaptureEnd(depth, ret, c.quotaLeft, err)
+	}()
 	return vm.i.runLoop(vm, c)
 }
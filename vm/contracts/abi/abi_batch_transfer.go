@@ -0,0 +1,32 @@
+package abi
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+)
+
+const (
+	jsonBatchTransfer = `
+	[
+		{"type":"function","name":"BatchTransfer","inputs":[{"name":"toList","type":"address[]"},{"name":"tokenIdList","type":"tokenId[]"},{"name":"amountList","type":"uint256[]"}]},
+
+		{"type":"event","name":"batchTransfer","inputs":[{"name":"to","type":"address","indexed":true},{"name":"index","type":"uint16"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"success","type":"bool"}]}
+	]`
+
+	MethodNameBatchTransfer = "BatchTransfer"
+	EventNameBatchTransfer  = "batchTransfer"
+)
+
+var (
+	// ABIBatchTransfer is abi definition of batch transfer contract
+	ABIBatchTransfer, _ = abi.JSONToABIContract(strings.NewReader(jsonBatchTransfer))
+)
+
+type ParamBatchTransfer struct {
+	ToList      []types.Address
+	TokenIdList []types.TokenTypeId
+	AmountList  []*big.Int
+}
@@ -0,0 +1,174 @@
+package abi
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+const (
+	jsonHeaderRelay = `
+	[
+		{"type":"function","name":"RegisterRelay","inputs":[{"name":"relayId","type":"uint64"},{"name":"verifier","type":"uint8"},{"name":"anchorHeight","type":"uint64"},{"name":"anchorHeader","type":"bytes"},{"name":"params","type":"bytes"}]},
+		{"type":"function","name":"SubmitHeaders","inputs":[{"name":"relayId","type":"uint64"},{"name":"headers","type":"bytes"}]},
+		{"type":"function","name":"SubmitSyncCommitteeUpdate","inputs":[{"name":"relayId","type":"uint64"},{"name":"update","type":"bytes"}]},
+		{"type":"function","name":"VerifyInclusion","inputs":[{"name":"relayId","type":"uint64"},{"name":"blockHash","type":"bytes32"},{"name":"leaf","type":"bytes32"},{"name":"proof","type":"bytes32[]"},{"name":"index","type":"uint64"},{"name":"minConfirmations","type":"uint64"}]},
+		{"type":"callback","name":"VerifyInclusion","inputs":[{"name":"id","type":"bytes32"},{"name":"relayId","type":"uint64"},{"name":"blockHash","type":"bytes32"},{"name":"leaf","type":"bytes32"},{"name":"verified","type":"bool"},{"name":"confirmations","type":"uint64"}]},
+
+		{"type":"variable","name":"relayInfo","inputs":[{"name":"verifier","type":"uint8"},{"name":"owner","type":"address"},{"name":"tipHash","type":"bytes32"},{"name":"tipHeight","type":"uint64"},{"name":"tipWork","type":"uint256"},{"name":"params","type":"bytes"},{"name":"state","type":"bytes"}]},
+		{"type":"variable","name":"relayHeader","inputs":[{"name":"height","type":"uint64"},{"name":"parentHash","type":"bytes32"},{"name":"root","type":"bytes32"},{"name":"work","type":"uint256"},{"name":"timestamp","type":"uint64"},{"name":"extra","type":"bytes"}]},
+
+		{"type":"event","name":"registerRelay","inputs":[{"name":"relayId","type":"uint64","indexed":true},{"name":"verifier","type":"uint8"},{"name":"owner","type":"address"},{"name":"anchorHash","type":"bytes32"},{"name":"anchorHeight","type":"uint64"}]},
+		{"type":"event","name":"newTip","inputs":[{"name":"relayId","type":"uint64","indexed":true},{"name":"tipHash","type":"bytes32"},{"name":"tipHeight","type":"uint64"}]}
+	]`
+
+	MethodNameRegisterRelay             = "RegisterRelay"
+	MethodNameSubmitHeaders             = "SubmitHeaders"
+	MethodNameSubmitSyncCommitteeUpdate = "SubmitSyncCommitteeUpdate"
+	MethodNameVerifyInclusion           = "VerifyInclusion"
+	VariableNameRelayInfo               = "relayInfo"
+	VariableNameRelayHeader             = "relayHeader"
+	EventNameNewTip                     = "newTip"
+)
+
+var (
+	// ABIHeaderRelay is abi definition of header relay contract
+	ABIHeaderRelay, _ = abi.JSONToABIContract(strings.NewReader(jsonHeaderRelay))
+)
+
+// verifiers of the foreign chain headers
+const (
+	RelayVerifierBTC uint8 = 1 // bitcoin SPV, the headers are verified by the proof of work
+	RelayVerifierETH uint8 = 2 // ethereum beacon chain light client, the headers are verified by the sync committee
+)
+
+var (
+	relayInfoKeyPrefix   = []byte{1}
+	relayHeaderKeyPrefix = []byte{2}
+	relayHeightKeyPrefix = []byte{3}
+)
+
+// RelayInfo is the state of a relay of a foreign chain, the tip is the header of the most work
+type RelayInfo struct {
+	Verifier  uint8
+	Owner     types.Address
+	TipHash   types.Hash
+	TipHeight uint64
+	TipWork   *big.Int
+	Params    []byte // the params of the verifier, fixed at registration
+	State     []byte // the state of the verifier, updated by the headers
+}
+
+// RelayHeader is a verified header of a foreign chain.
+// Work is the cumulative work for the proof of work chains, or the slot for the beacon chain.
+type RelayHeader struct {
+	Height     uint64
+	ParentHash types.Hash
+	Root       types.Hash
+	Work       *big.Int
+	Timestamp  uint64
+	Extra      []byte
+}
+
+type ParamRegisterRelay struct {
+	RelayId      uint64
+	Verifier     uint8
+	AnchorHeight uint64
+	AnchorHeader []byte
+	Params       []byte
+}
+
+// ParamSubmitHeaders holds the encoded headers, which are split by the verifier of the relay
+type ParamSubmitHeaders struct {
+	RelayId uint64
+	Headers []byte
+}
+
+// ParamSubmitSyncCommitteeUpdate holds an update of the beacon chain signed by the sync committee
+type ParamSubmitSyncCommitteeUpdate struct {
+	RelayId uint64
+	Update  []byte
+}
+
+type ParamVerifyInclusion struct {
+	RelayId          uint64
+	BlockHash        types.Hash
+	Leaf             types.Hash
+	Proof            [][32]byte
+	Index            uint64
+	MinConfirmations uint64
+}
+
+// GetRelayInfoKey generate db key for relay info
+func GetRelayInfoKey(relayId uint64) []byte {
+	return appendUint64(relayInfoKeyPrefix, relayId)
+}
+
+// GetRelayHeaderKey generate db key for a header of the relay
+func GetRelayHeaderKey(relayId uint64, hash types.Hash) []byte {
+	return append(appendUint64(relayHeaderKeyPrefix, relayId), hash.Bytes()...)
+}
+
+// GetRelayHeightKey generate db key for the hash of the header at the height in the chain of the tip
+func GetRelayHeightKey(relayId uint64, height uint64) []byte {
+	return appendUint64(appendUint64(relayHeightKeyPrefix, relayId), height)
+}
+
+func appendUint64(prefix []byte, n uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], n)
+	return key
+}
+
+// GetRelayInfo query relay info by id
+func GetRelayInfo(db StorageDatabase, relayId uint64) (*RelayInfo, error) {
+	if *db.Address() != types.AddressHeaderRelay {
+		return nil, util.ErrAddressNotMatch
+	}
+	data, err := db.GetValue(GetRelayInfoKey(relayId))
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	relayInfo := new(RelayInfo)
+	if err := ABIHeaderRelay.UnpackVariable(relayInfo, VariableNameRelayInfo, data); err != nil {
+		return nil, err
+	}
+	return relayInfo, nil
+}
+
+// GetRelayHeader query a verified header by hash
+func GetRelayHeader(db StorageDatabase, relayId uint64, hash types.Hash) (*RelayHeader, error) {
+	if *db.Address() != types.AddressHeaderRelay {
+		return nil, util.ErrAddressNotMatch
+	}
+	data, err := db.GetValue(GetRelayHeaderKey(relayId, hash))
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	header := new(RelayHeader)
+	if err := ABIHeaderRelay.UnpackVariable(header, VariableNameRelayHeader, data); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+// GetRelayHashByHeight query the hash of the header at the height in the chain of the tip, nil if not existed
+func GetRelayHashByHeight(db StorageDatabase, relayId uint64, height uint64) (*types.Hash, error) {
+	if *db.Address() != types.AddressHeaderRelay {
+		return nil, util.ErrAddressNotMatch
+	}
+	data, err := db.GetValue(GetRelayHeightKey(relayId, height))
+	if err != nil || len(data) == 0 {
+		return nil, err
+	}
+	hash, err := types.BytesToHash(data)
+	if err != nil {
+		return nil, err
+	}
+	return &hash, nil
+}
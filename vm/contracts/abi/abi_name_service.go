@@ -0,0 +1,126 @@
+package abi
+
+import (
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+const (
+	jsonNameService = `
+	[
+		{"type":"function","name":"RegisterName","inputs":[{"name":"name","type":"string"}]},
+		{"type":"function","name":"SetNameAddress","inputs":[{"name":"name","type":"string"},{"name":"address","type":"address"}]},
+		{"type":"function","name":"SetNameTokenId","inputs":[{"name":"name","type":"string"},{"name":"tokenId","type":"tokenId"}]},
+		{"type":"function","name":"TransferNameOwnership","inputs":[{"name":"name","type":"string"},{"name":"newOwner","type":"address"}]},
+
+		{"type":"variable","name":"nameInfo","inputs":[{"name":"name","type":"string"},{"name":"owner","type":"address"},{"name":"address","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"registrationHeight","type":"uint64"}]},
+
+		{"type":"event","name":"registerName","inputs":[{"name":"nameHash","type":"bytes32","indexed":true},{"name":"name","type":"string"},{"name":"owner","type":"address"}]},
+		{"type":"event","name":"setNameAddress","inputs":[{"name":"nameHash","type":"bytes32","indexed":true},{"name":"address","type":"address"}]},
+		{"type":"event","name":"setNameTokenId","inputs":[{"name":"nameHash","type":"bytes32","indexed":true},{"name":"tokenId","type":"tokenId"}]},
+		{"type":"event","name":"transferNameOwnership","inputs":[{"name":"nameHash","type":"bytes32","indexed":true},{"name":"owner","type":"address"}]}
+	]`
+
+	MethodNameRegisterName          = "RegisterName"
+	MethodNameSetNameAddress        = "SetNameAddress"
+	MethodNameSetNameTokenId        = "SetNameTokenId"
+	MethodNameTransferNameOwnership = "TransferNameOwnership"
+	VariableNameNameInfo            = "nameInfo"
+)
+
+var (
+	// ABINameService is abi definition of name service contract
+	ABINameService, _ = abi.JSONToABIContract(strings.NewReader(jsonNameService))
+)
+
+// NameInfo is the record of a name, Address and TokenId are empty until set by the owner
+type NameInfo struct {
+	Name               string
+	Owner              types.Address
+	Address            types.Address
+	TokenId            types.TokenTypeId
+	RegistrationHeight uint64
+}
+
+type ParamSetNameAddress struct {
+	Name    string
+	Address types.Address
+}
+
+type ParamSetNameTokenId struct {
+	Name    string
+	TokenId types.TokenTypeId
+}
+
+type ParamTransferNameOwnership struct {
+	Name     string
+	NewOwner types.Address
+}
+
+// GetNameHash returns the hash of a name, which is the db key of the name info and the topic of name events
+func GetNameHash(name string) types.Hash {
+	return types.DataHash([]byte(name))
+}
+
+// GetNameInfoKey generate db key for name info
+func GetNameInfoKey(name string) []byte {
+	return GetNameHash(name).Bytes()
+}
+
+func isNameInfoKey(key []byte) bool {
+	return len(key) == types.HashSize
+}
+
+// GetNameInfo query name info by name
+func GetNameInfo(db StorageDatabase, name string) (*NameInfo, error) {
+	if *db.Address() != types.AddressNameService {
+		return nil, util.ErrAddressNotMatch
+	}
+	data, err := db.GetValue(GetNameInfoKey(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return parseNameInfo(data)
+}
+
+// GetNameInfoListByOwner query all names of an owner
+func GetNameInfoListByOwner(db StorageDatabase, owner types.Address) ([]*NameInfo, error) {
+	if *db.Address() != types.AddressNameService {
+		return nil, util.ErrAddressNotMatch
+	}
+	iterator, err := db.NewStorageIterator(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Release()
+	nameInfoList := make([]*NameInfo, 0)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		if !filterKeyValue(iterator.Key(), iterator.Value(), isNameInfoKey) {
+			continue
+		}
+		if nameInfo, err := parseNameInfo(iterator.Value()); err == nil && nameInfo.Owner == owner {
+			nameInfoList = append(nameInfoList, nameInfo)
+		}
+	}
+	return nameInfoList, nil
+}
+
+func parseNameInfo(data []byte) (*NameInfo, error) {
+	nameInfo := new(NameInfo)
+	if err := ABINameService.UnpackVariable(nameInfo, VariableNameNameInfo, data); err != nil {
+		return nil, err
+	}
+	return nameInfo, nil
+}
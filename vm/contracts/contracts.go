@@ -61,7 +61,7 @@ var (
 	earthContracts           = newEarthContracts()
 	dexRobotContracts        = newDexRobotContracts()
 	dexStableMarketContracts = newDexStableMarketContracts()
-	dexEnrichOrderContracts  = newDexEnrichOrderContracts()
+	versionXContracts        = newVersionXContracts()
 )
 
 func newSimpleContracts() map[types.Address]*builtinContract {
@@ -243,11 +243,40 @@ func newDexEnrichOrderContracts() map[types.Address]*builtinContract {
 	return contracts
 }
 
+func newVersionXContracts() map[types.Address]*builtinContract {
+	contracts := newDexEnrichOrderContracts()
+	contracts[types.AddressNameService] = &builtinContract{
+		map[string]BuiltinContractMethod{
+			cabi.MethodNameRegisterName:          &MethodRegisterName{cabi.MethodNameRegisterName},
+			cabi.MethodNameSetNameAddress:        &MethodSetNameAddress{cabi.MethodNameSetNameAddress},
+			cabi.MethodNameSetNameTokenId:        &MethodSetNameTokenId{cabi.MethodNameSetNameTokenId},
+			cabi.MethodNameTransferNameOwnership: &MethodTransferNameOwnership{cabi.MethodNameTransferNameOwnership},
+		},
+		cabi.ABINameService,
+	}
+	contracts[types.AddressBatchTransfer] = &builtinContract{
+		map[string]BuiltinContractMethod{
+			cabi.MethodNameBatchTransfer: &MethodBatchTransfer{cabi.MethodNameBatchTransfer},
+		},
+		cabi.ABIBatchTransfer,
+	}
+	contracts[types.AddressHeaderRelay] = &builtinContract{
+		map[string]BuiltinContractMethod{
+			cabi.MethodNameRegisterRelay:             &MethodRegisterRelay{cabi.MethodNameRegisterRelay},
+			cabi.MethodNameSubmitHeaders:             &MethodSubmitHeaders{cabi.MethodNameSubmitHeaders},
+			cabi.MethodNameSubmitSyncCommitteeUpdate: &MethodSubmitSyncCommitteeUpdate{cabi.MethodNameSubmitSyncCommitteeUpdate},
+			cabi.MethodNameVerifyInclusion:           &MethodVerifyInclusion{cabi.MethodNameVerifyInclusion},
+		},
+		cabi.ABIHeaderRelay,
+	}
+	return contracts
+}
+
 // GetBuiltinContractMethod finds method instance of built-in contract method by address and method id
 func GetBuiltinContractMethod(addr types.Address, methodSelector []byte, sbHeight uint64) (BuiltinContractMethod, bool, error) {
 	var contractsMap map[types.Address]*builtinContract
 	if upgrade.IsVersionXUpgrade(sbHeight) {
-		contractsMap = dexEnrichOrderContracts
+		contractsMap = versionXContracts
 	} else if upgrade.IsDexStableMarketUpgrade(sbHeight) {
 		contractsMap = dexStableMarketContracts
 	} else if upgrade.IsDexRobotUpgrade(sbHeight) {
@@ -273,7 +302,7 @@ func GetBuiltinContractMethod(addr types.Address, methodSelector []byte, sbHeigh
 		}
 		return nil, addrExists, util.ErrAbiMethodNotFound
 	}
-	return nil, addrExists, nil
+	return getPluginContractMethod(addr, methodSelector, sbHeight)
 }
 
 // NewLog generate vm log
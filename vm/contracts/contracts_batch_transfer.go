@@ -0,0 +1,104 @@
+package contracts
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+// checkBatchTransfer verifies a batch transfer, lists must be of the same non-zero length,
+// amounts must be positive and built-in contracts are not allowed as receivers
+func checkBatchTransfer(param *abi.ParamBatchTransfer) error {
+	count := len(param.ToList)
+	if count == 0 || count > batchTransferCountMax || len(param.TokenIdList) != count || len(param.AmountList) != count {
+		return util.ErrInvalidMethodParam
+	}
+	for i := 0; i < count; i++ {
+		if param.AmountList[i].Sign() <= 0 || types.IsBuiltinContractAddr(param.ToList[i]) {
+			return util.ErrInvalidMethodParam
+		}
+	}
+	return nil
+}
+
+type MethodBatchTransfer struct {
+	MethodName string
+}
+
+func (p *MethodBatchTransfer) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodBatchTransfer) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodBatchTransfer) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	param := new(abi.ParamBatchTransfer)
+	if err := abi.ABIBatchTransfer.UnpackMethod(param, p.MethodName, data); err != nil {
+		return 0, util.ErrInvalidMethodParam
+	}
+	itemQuota, overflow := helper.SafeMul(gasTable.BatchTransferItemQuota, uint64(len(param.ToList)))
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	quota, overflow := helper.SafeAdd(gasTable.BatchTransferQuota, itemQuota)
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	return quota, nil
+}
+func (p *MethodBatchTransfer) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodBatchTransfer) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() <= 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamBatchTransfer)
+	if err := abi.ABIBatchTransfer.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if err := checkBatchTransfer(param); err != nil {
+		return err
+	}
+	block.Data, _ = abi.ABIBatchTransfer.PackMethod(p.MethodName, param.ToList, param.TokenIdList, param.AmountList)
+	return nil
+}
+
+// DoReceive transfers each item in order from the amount of the send block. An item fails if its token
+// is not the token of the send block or the remaining amount is not enough, the result of every item is
+// recorded in a vm log and the remaining amount is refunded to the sender.
+func (p *MethodBatchTransfer) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamBatchTransfer)
+	abi.ABIBatchTransfer.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	remain := new(big.Int).Set(sendBlock.Amount)
+	blockList := make([]*ledger.AccountBlock, 0, len(param.ToList)+1)
+	for i, to := range param.ToList {
+		success := param.TokenIdList[i] == sendBlock.TokenId && param.AmountList[i].Cmp(remain) <= 0
+		if success {
+			remain.Sub(remain, param.AmountList[i])
+			blockList = append(blockList, util.MakeRequestBlock(
+				block.AccountAddress,
+				to,
+				ledger.BlockTypeSendCall,
+				new(big.Int).Set(param.AmountList[i]),
+				sendBlock.TokenId,
+				[]byte{}))
+		}
+		db.AddLog(NewLog(abi.ABIBatchTransfer, abi.EventNameBatchTransfer, to, uint16(i), param.TokenIdList[i], param.AmountList[i], success))
+	}
+	if remain.Sign() > 0 {
+		blockList = append(blockList, util.MakeRequestBlock(
+			block.AccountAddress,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			remain,
+			sendBlock.TokenId,
+			[]byte{}))
+	}
+	return blockList, nil
+}
@@ -0,0 +1,90 @@
+package contracts
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+type logDb struct {
+	interfaces.VmDb
+	logList []*ledger.VmLog
+}
+
+func (db *logDb) AddLog(log *ledger.VmLog) {
+	db.logList = append(db.logList, log)
+}
+
+func TestBatchTransfer(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox().AddPoint(11, 100))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	to1, _ := types.BytesToAddress([]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0})
+	to2, _ := types.BytesToAddress([]byte{2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 0})
+	otherToken := types.TokenTypeId{1}
+	data, err := abi.ABIBatchTransfer.PackMethod(abi.MethodNameBatchTransfer,
+		[]types.Address{to1, to2, to1, to2},
+		[]types.TokenTypeId{ledger.ViteTokenId, otherToken, ledger.ViteTokenId, ledger.ViteTokenId},
+		[]*big.Int{big.NewInt(30), big.NewInt(10), big.NewInt(50), big.NewInt(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := GetBuiltinContractMethod(types.AddressBatchTransfer, data, 99); ok || err != nil {
+		t.Fatalf("expected not a built-in contract before the fork, got %v %v", ok, err)
+	}
+	method, ok, err := GetBuiltinContractMethod(types.AddressBatchTransfer, data, 100)
+	if !ok || err != nil {
+		t.Fatalf("method not found after the fork, %v %v", ok, err)
+	}
+
+	quotaTable := util.QuotaTableByHeight(100)
+	if quota, err := method.GetSendQuota(data, quotaTable); err != nil || quota != quotaTable.BatchTransferQuota+4*quotaTable.BatchTransferItemQuota {
+		t.Fatalf("unexpected send quota %v %v", quota, err)
+	}
+
+	sendBlock := &ledger.AccountBlock{
+		AccountAddress: to1,
+		ToAddress:      types.AddressBatchTransfer,
+		Amount:         big.NewInt(60),
+		TokenId:        ledger.ViteTokenId,
+		Data:           data,
+	}
+	if err := method.DoSend(nil, sendBlock); err != nil {
+		t.Fatal(err)
+	}
+	db := &logDb{}
+	blockList, err := method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressBatchTransfer}, sendBlock, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// item 1 has another token, item 2 exceeds the remaining amount, the remaining 10 is refunded
+	expected := []struct {
+		to     types.Address
+		amount int64
+	}{{to1, 30}, {to2, 20}, {to1, 10}}
+	if len(blockList) != len(expected) {
+		t.Fatalf("unexpected block list length %v", len(blockList))
+	}
+	for i, e := range expected {
+		if blockList[i].ToAddress != e.to || blockList[i].Amount.Int64() != e.amount || blockList[i].TokenId != ledger.ViteTokenId {
+			t.Fatalf("unexpected block %v, %v %v %v", i, blockList[i].ToAddress, blockList[i].Amount, blockList[i].TokenId)
+		}
+	}
+	if len(db.logList) != 4 {
+		t.Fatalf("unexpected log list length %v", len(db.logList))
+	}
+
+	invalid, _ := abi.ABIBatchTransfer.PackMethod(abi.MethodNameBatchTransfer,
+		[]types.Address{types.AddressQuota}, []types.TokenTypeId{ledger.ViteTokenId}, []*big.Int{big.NewInt(1)})
+	if err := method.DoSend(nil, &ledger.AccountBlock{Amount: big.NewInt(1), Data: invalid}); err != util.ErrInvalidMethodParam {
+		t.Fatalf("expected transfer to built-in contract rejected, got %v", err)
+	}
+}
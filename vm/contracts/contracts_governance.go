@@ -644,6 +644,81 @@ func calcRewardByDayDetail(detail *core.DayStats, name string, stakeAmount *big.
 	return reward
 }
 
+// RewardDetail describes the inputs and the result of reward calculation of a sbp in one cycle
+type RewardDetail struct {
+	Index            uint64
+	Reward           *Reward
+	RewardPerBlock   *big.Int
+	TotalBlockNum    uint64   // blocks produced by all sbps in the cycle
+	SbpNum           uint64   // sbps listed in the cycle
+	VoteCount        *big.Int // self vote count
+	TotalVoteCount   *big.Int // vote count of all sbps
+	StakeAmount      *big.Int // stake amount of one sbp
+	VoteWeight       *big.Int // self vote count + stake amount
+	TotalVoteWeight  *big.Int // total vote count + sbp num * stake amount
+	BlockNum         uint64
+	ExpectedBlockNum uint64
+}
+
+// CalcRewardDetailByIndex calculates reward detail of a sbp for every cycle in [startIndex, endIndex]
+func CalcRewardDetailByIndex(db interfaces.VmDb, reader util.ConsensusReader, startIndex, endIndex uint64, name string) (details []*RewardDetail, err error) {
+	defer func() {
+		if panicErr := recover(); panicErr != nil {
+			debug.PrintStack()
+			err = util.ErrChainForked
+		}
+	}()
+	if startIndex > endIndex {
+		return nil, util.ErrInvalidMethodParam
+	}
+	endTime := reader.GetEndTimeByIndex(endIndex)
+	current, err := db.LatestSnapshotBlock()
+	if err != nil {
+		return nil, err
+	}
+	if endTime > getRewardTimeLimit(current) {
+		return nil, util.ErrRewardNotDue
+	}
+	dayStats, err := reader.GetConsensusDetailByDay(startIndex, endIndex)
+	if err != nil {
+		return nil, err
+	}
+	genesisTime := db.GetGenesisSnapshotBlock().Timestamp.Unix()
+	forkIndex := uint64(0)
+	for _, detail := range dayStats {
+		var stakeAmount *big.Int
+		stakeAmount, forkIndex, err = getSnapshotGroupStakeAmount(db, reader, genesisTime, detail.Index, forkIndex)
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, calcRewardDetailByDayDetail(detail, name, stakeAmount))
+	}
+	return details, nil
+}
+
+func calcRewardDetailByDayDetail(detail *core.DayStats, name string, stakeAmount *big.Int) *RewardDetail {
+	sbpNum := uint64(len(detail.Stats))
+	result := &RewardDetail{
+		Index:          detail.Index,
+		Reward:         calcRewardByDayDetail(detail, name, stakeAmount),
+		RewardPerBlock: new(big.Int).Set(rewardPerBlock),
+		TotalBlockNum:  detail.BlockTotal,
+		SbpNum:         sbpNum,
+		VoteCount:      big.NewInt(0),
+		TotalVoteCount: new(big.Int).Set(detail.VoteSum.Int),
+		StakeAmount:    new(big.Int).Set(stakeAmount),
+	}
+	if selfDetail, ok := detail.Stats[name]; ok {
+		result.VoteCount.Set(selfDetail.VoteCnt.Int)
+		result.BlockNum = selfDetail.BlockNum
+		result.ExpectedBlockNum = selfDetail.ExceptedBlockNum
+	}
+	result.VoteWeight = new(big.Int).Add(result.VoteCount, stakeAmount)
+	result.TotalVoteWeight = new(big.Int).Mul(new(big.Int).SetUint64(sbpNum), stakeAmount)
+	result.TotalVoteWeight.Add(result.TotalVoteWeight, result.TotalVoteCount)
+	return result
+}
+
 type MethodUpdateBlockProducingAddress struct {
 	MethodName string
 }
@@ -0,0 +1,400 @@
+package contracts
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/helper"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+var (
+	errRelayNotExists     = errors.New("relay not exists")
+	errRelayInvalidHeader = errors.New("invalid relay header")
+	errRelayReorgTooDeep  = errors.New("relay reorg too deep")
+	errRelayWrongMethod   = errors.New("relay headers submitted by a wrong method")
+)
+
+// relayVerifier verifies the headers of a foreign chain
+type relayVerifier interface {
+	// checkParams verifies the verifier params of a relay at registration
+	checkParams(params []byte) error
+	// verifyAnchor verifies the trusted header which the relay starts from, returns the hash, the header and the initial state
+	verifyAnchor(params []byte, height uint64, raw []byte) (types.Hash, *abi.RelayHeader, []byte, error)
+	// splitHeaders splits the data of SubmitHeaders into encoded headers
+	splitHeaders(data []byte) ([][]byte, error)
+	// verifyHeader verifies an encoded header by the stored headers and the state of the relay, info.State is updated in place
+	verifyHeader(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo, raw []byte) (types.Hash, *abi.RelayHeader, error)
+	// verifyInclusion verifies the proof of the leaf at index against a verified header
+	verifyInclusion(hash types.Hash, header *abi.RelayHeader, leaf types.Hash, proof [][32]byte, index uint64) bool
+}
+
+var relayVerifiers = map[uint8]relayVerifier{
+	abi.RelayVerifierBTC: btcRelayVerifier{},
+	abi.RelayVerifierETH: ethRelayVerifier{},
+}
+
+func getRelayInfo(db interfaces.VmDb, relayId uint64) (*abi.RelayInfo, relayVerifier, error) {
+	info, err := abi.GetRelayInfo(db, relayId)
+	util.DealWithErr(err)
+	if info == nil {
+		return nil, nil, errRelayNotExists
+	}
+	return info, relayVerifiers[info.Verifier], nil
+}
+
+func setRelayInfo(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo) {
+	data, _ := abi.ABIHeaderRelay.PackVariable(
+		abi.VariableNameRelayInfo,
+		info.Verifier,
+		info.Owner,
+		info.TipHash,
+		info.TipHeight,
+		info.TipWork,
+		info.Params,
+		info.State)
+	util.SetValue(db, abi.GetRelayInfoKey(relayId), data)
+}
+
+func setRelayHeader(db interfaces.VmDb, relayId uint64, hash types.Hash, header *abi.RelayHeader) {
+	data, _ := abi.ABIHeaderRelay.PackVariable(
+		abi.VariableNameRelayHeader,
+		header.Height,
+		header.ParentHash,
+		header.Root,
+		header.Work,
+		header.Timestamp,
+		header.Extra)
+	util.SetValue(db, abi.GetRelayHeaderKey(relayId, hash), data)
+}
+
+// setRelayTip makes the header the tip of the relay. The heights index is rewritten from the new tip back to
+// the fork point of the chain of the old tip, and the heights above the new tip are removed.
+func setRelayTip(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo, hash types.Hash, header *abi.RelayHeader) error {
+	if info.TipHeight > header.Height {
+		if info.TipHeight-header.Height > relayReorgDepthMax {
+			return errRelayReorgTooDeep
+		}
+		for h := header.Height + 1; h <= info.TipHeight; h++ {
+			util.SetValue(db, abi.GetRelayHeightKey(relayId, h), nil)
+		}
+	}
+	current, currentHeader := hash, header
+	for depth := uint64(0); ; depth++ {
+		old, err := abi.GetRelayHashByHeight(db, relayId, currentHeader.Height)
+		util.DealWithErr(err)
+		if old != nil && *old == current {
+			break
+		}
+		if depth > relayReorgDepthMax {
+			return errRelayReorgTooDeep
+		}
+		util.SetValue(db, abi.GetRelayHeightKey(relayId, currentHeader.Height), current.Bytes())
+		parent, err := abi.GetRelayHeader(db, relayId, currentHeader.ParentHash)
+		util.DealWithErr(err)
+		if parent == nil {
+			break
+		}
+		current, currentHeader = currentHeader.ParentHash, parent
+	}
+	info.TipHash = hash
+	info.TipHeight = header.Height
+	info.TipWork = header.Work
+	return nil
+}
+
+type MethodRegisterRelay struct {
+	MethodName string
+}
+
+func (p *MethodRegisterRelay) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	if block.Amount.Sign() > 0 {
+		return big.NewInt(0), util.ErrInvalidMethodParam
+	}
+	return new(big.Int).Set(relayRegistrationFee), nil
+}
+func (p *MethodRegisterRelay) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodRegisterRelay) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.RelayRegisterQuota, nil
+}
+func (p *MethodRegisterRelay) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodRegisterRelay) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	param := new(abi.ParamRegisterRelay)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	verifier, ok := relayVerifiers[param.Verifier]
+	if !ok || len(param.Params) > relayParamsLengthMax || len(param.AnchorHeader) > relayHeadersLengthMax {
+		return util.ErrInvalidMethodParam
+	}
+	if err := verifier.checkParams(param.Params); err != nil {
+		return err
+	}
+	if _, _, _, err := verifier.verifyAnchor(param.Params, param.AnchorHeight, param.AnchorHeader); err != nil {
+		return err
+	}
+	block.Data, _ = abi.ABIHeaderRelay.PackMethod(p.MethodName, param.RelayId, param.Verifier, param.AnchorHeight, param.AnchorHeader, param.Params)
+	return nil
+}
+
+// DoReceive stores the anchor header as the tip of the new relay, the fee is burned
+func (p *MethodRegisterRelay) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamRegisterRelay)
+	abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	oldInfo, err := abi.GetRelayInfo(db, param.RelayId)
+	util.DealWithErr(err)
+	if oldInfo != nil {
+		return nil, util.ErrIDCollision
+	}
+	hash, header, state, err := relayVerifiers[param.Verifier].verifyAnchor(param.Params, param.AnchorHeight, param.AnchorHeader)
+	if err != nil {
+		return nil, err
+	}
+	info := &abi.RelayInfo{
+		Verifier: param.Verifier,
+		Owner:    sendBlock.AccountAddress,
+		Params:   param.Params,
+		State:    state,
+	}
+	setRelayHeader(db, param.RelayId, hash, header)
+	if err := setRelayTip(db, param.RelayId, info, hash, header); err != nil {
+		return nil, err
+	}
+	setRelayInfo(db, param.RelayId, info)
+	db.AddLog(NewLog(abi.ABIHeaderRelay, util.FirstToLower(p.MethodName), param.RelayId, param.Verifier, sendBlock.AccountAddress, hash, header.Height))
+	return nil, nil
+}
+
+type MethodSubmitHeaders struct {
+	MethodName string
+}
+
+func (p *MethodSubmitHeaders) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodSubmitHeaders) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+
+// GetSendQuota charges the submitted headers by words, for the cost of verification grows with the size of a header
+func (p *MethodSubmitHeaders) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	param := new(abi.ParamSubmitHeaders)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, data); err != nil {
+		return 0, util.ErrInvalidMethodParam
+	}
+	wordQuota, overflow := helper.SafeMul(gasTable.RelayHeadersWordQuota, uint64((len(param.Headers)+31)/32))
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	quota, overflow := helper.SafeAdd(gasTable.RelaySubmitHeadersQuota, wordQuota)
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	return quota, nil
+}
+func (p *MethodSubmitHeaders) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodSubmitHeaders) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamSubmitHeaders)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if len(param.Headers) == 0 || len(param.Headers) > relayHeadersLengthMax {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIHeaderRelay.PackMethod(p.MethodName, param.RelayId, param.Headers)
+	return nil
+}
+
+// DoReceive verifies the headers in order and stores them, the header of the most work becomes the tip.
+// Headers already stored are skipped, so relayers racing to submit the same headers don't fail each other.
+// Any invalid header fails the whole call.
+func (p *MethodSubmitHeaders) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamSubmitHeaders)
+	abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	info, verifier, err := getRelayInfo(db, param.RelayId)
+	if err != nil {
+		return nil, err
+	}
+	rawHeaders, err := verifier.splitHeaders(param.Headers)
+	if err != nil {
+		return nil, err
+	}
+	return nil, submitRelayHeaders(db, param.RelayId, info, verifier, rawHeaders)
+}
+
+// submitRelayHeaders verifies the encoded headers in order and stores them, see MethodSubmitHeaders.DoReceive
+func submitRelayHeaders(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo, verifier relayVerifier, rawHeaders [][]byte) error {
+	oldTip := info.TipHash
+	for _, raw := range rawHeaders {
+		hash, header, err := verifier.verifyHeader(db, relayId, info, raw)
+		if err != nil {
+			return err
+		}
+		oldHeader, err := abi.GetRelayHeader(db, relayId, hash)
+		util.DealWithErr(err)
+		if oldHeader != nil {
+			continue
+		}
+		setRelayHeader(db, relayId, hash, header)
+		if header.Work.Cmp(info.TipWork) > 0 {
+			if err := setRelayTip(db, relayId, info, hash, header); err != nil {
+				return err
+			}
+		}
+	}
+	setRelayInfo(db, relayId, info)
+	if info.TipHash != oldTip {
+		db.AddLog(NewLog(abi.ABIHeaderRelay, abi.EventNameNewTip, relayId, info.TipHash, info.TipHeight))
+	}
+	return nil
+}
+
+type MethodSubmitSyncCommitteeUpdate struct {
+	MethodName string
+}
+
+func (p *MethodSubmitSyncCommitteeUpdate) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodSubmitSyncCommitteeUpdate) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+
+// GetSendQuota charges the update by words as SubmitHeaders does, plus the pairing and the aggregation of the public keys
+// of the participants, which are the most of the cost of verifying the signature
+func (p *MethodSubmitSyncCommitteeUpdate) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	param := new(abi.ParamSubmitSyncCommitteeUpdate)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, data); err != nil {
+		return 0, util.ErrInvalidMethodParam
+	}
+	participants, ok := ethUpdateParticipants(param.Update)
+	if !ok {
+		return 0, util.ErrInvalidMethodParam
+	}
+	wordQuota, overflow := helper.SafeMul(gasTable.RelayHeadersWordQuota, uint64((len(param.Update)+31)/32))
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	pubkeyQuota, overflow := helper.SafeMul(gasTable.RelayBlsPubkeyQuota, uint64(participants))
+	if overflow {
+		return 0, util.ErrGasUintOverflow
+	}
+	quota := gasTable.RelaySubmitHeadersQuota
+	for _, q := range []uint64{wordQuota, gasTable.RelayBlsPairingQuota, pubkeyQuota} {
+		quota, overflow = helper.SafeAdd(quota, q)
+		if overflow {
+			return 0, util.ErrGasUintOverflow
+		}
+	}
+	return quota, nil
+}
+func (p *MethodSubmitSyncCommitteeUpdate) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodSubmitSyncCommitteeUpdate) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamSubmitSyncCommitteeUpdate)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if _, ok := ethUpdateParticipants(param.Update); !ok || len(param.Update) > relayHeadersLengthMax {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIHeaderRelay.PackMethod(p.MethodName, param.RelayId, param.Update)
+	return nil
+}
+
+// DoReceive verifies the update of a relay of the beacon chain and stores the header as SubmitHeaders does
+func (p *MethodSubmitSyncCommitteeUpdate) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamSubmitSyncCommitteeUpdate)
+	abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	info, verifier, err := getRelayInfo(db, param.RelayId)
+	if err != nil {
+		return nil, err
+	}
+	if info.Verifier != abi.RelayVerifierETH {
+		return nil, errRelayWrongMethod
+	}
+	return nil, submitRelayHeaders(db, param.RelayId, info, verifier, [][]byte{param.Update})
+}
+
+type MethodVerifyInclusion struct {
+	MethodName string
+}
+
+func (p *MethodVerifyInclusion) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodVerifyInclusion) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	param := new(abi.ParamVerifyInclusion)
+	abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	callbackData, _ := abi.ABIHeaderRelay.PackCallback(p.MethodName, sendBlock.Hash, param.RelayId, param.BlockHash, param.Leaf, false, uint64(0))
+	return callbackData, true
+}
+func (p *MethodVerifyInclusion) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.RelayVerifyInclusionQuota, nil
+}
+func (p *MethodVerifyInclusion) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodVerifyInclusion) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamVerifyInclusion)
+	if err := abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if len(param.Proof) > relayProofLengthMax {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIHeaderRelay.PackMethod(p.MethodName, param.RelayId, param.BlockHash, param.Leaf, param.Proof, param.Index, param.MinConfirmations)
+	return nil
+}
+
+// DoReceive calls back the sender with the result. The leaf is verified only if the block is in the chain of
+// the tip with at least minConfirmations confirmations, the tip itself has 1 confirmation.
+func (p *MethodVerifyInclusion) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamVerifyInclusion)
+	abi.ABIHeaderRelay.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	verified, confirmations := false, uint64(0)
+	if info, verifier, err := getRelayInfo(db, param.RelayId); err == nil {
+		header, err := abi.GetRelayHeader(db, param.RelayId, param.BlockHash)
+		util.DealWithErr(err)
+		if header != nil && header.Height <= info.TipHeight {
+			hash, err := abi.GetRelayHashByHeight(db, param.RelayId, header.Height)
+			util.DealWithErr(err)
+			if hash != nil && *hash == param.BlockHash {
+				confirmations = info.TipHeight - header.Height + 1
+				verified = confirmations >= param.MinConfirmations &&
+					verifier.verifyInclusion(param.BlockHash, header, param.Leaf, param.Proof, param.Index)
+			}
+		}
+	}
+	callbackData, _ := abi.ABIHeaderRelay.PackCallback(p.MethodName, sendBlock.Hash, param.RelayId, param.BlockHash, param.Leaf, verified, confirmations)
+	return []*ledger.AccountBlock{
+		{
+			AccountAddress: block.AccountAddress,
+			ToAddress:      sendBlock.AccountAddress,
+			BlockType:      ledger.BlockTypeSendCall,
+			Amount:         big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			Data:           callbackData,
+		},
+	}, nil
+}
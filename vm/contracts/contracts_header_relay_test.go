@@ -0,0 +1,350 @@
+package contracts
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	kbls "github.com/kilic/bls12-381"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+type storageDb struct {
+	logDb
+	address types.Address
+	storage map[string][]byte
+}
+
+func newStorageDb(address types.Address) *storageDb {
+	return &storageDb{address: address, storage: make(map[string][]byte)}
+}
+
+func (db *storageDb) Address() *types.Address {
+	return &db.address
+}
+func (db *storageDb) GetValue(key []byte) ([]byte, error) {
+	return db.storage[string(key)], nil
+}
+func (db *storageDb) SetValue(key []byte, value []byte) error {
+	if len(value) == 0 {
+		delete(db.storage, string(key))
+	} else {
+		db.storage[string(key)] = value
+	}
+	return nil
+}
+
+// mineBtcHeader finds a nonce of the header for the regtest target
+func mineBtcHeader(t *testing.T, parent types.Hash, merkleRoot types.Hash, timestamp uint32) []byte {
+	raw := make([]byte, btcHeaderLength)
+	binary.LittleEndian.PutUint32(raw, 1)
+	copy(raw[4:36], parent[:])
+	copy(raw[36:68], merkleRoot[:])
+	binary.LittleEndian.PutUint32(raw[68:72], timestamp)
+	binary.LittleEndian.PutUint32(raw[72:76], 0x207fffff)
+	target, _ := btcCompactToTarget(0x207fffff)
+	for nonce := uint32(0); nonce < 1000; nonce++ {
+		binary.LittleEndian.PutUint32(raw[76:80], nonce)
+		if btcHashToBig(btcDoubleHash(raw)).Cmp(target) <= 0 {
+			return raw
+		}
+	}
+	t.Fatal("failed to mine a header")
+	return nil
+}
+
+func TestBtcRelayVerifier(t *testing.T) {
+	// bitcoin genesis block
+	raw, _ := hex.DecodeString("0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c")
+	hash, header, _, err := btcRelayVerifier{}.verifyAnchor([]byte{0x1d, 0x00, 0xff, 0xff, 0}, 0, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(btcHashToBig(hash).FillBytes(make([]byte, 32))) != "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f" {
+		t.Fatalf("unexpected genesis hash %v", hash)
+	}
+	if header.Work.Cmp(big.NewInt(0x100010001)) != 0 {
+		t.Fatalf("unexpected work %v", header.Work)
+	}
+	if _, _, _, err := (btcRelayVerifier{}).verifyAnchor([]byte{0x1d, 0x00, 0xff, 0xff, 0}, 1, raw); err == nil {
+		t.Fatal("expected the anchor not at the start of a retarget period to fail")
+	}
+
+	// the retarget at height 32256
+	if bits := btcRetarget(&btcRelayParams{powLimit: mustBtcTarget(0x1d00ffff)}, 0x1d00ffff, 1262152739-1261130161); bits != 0x1d00d86a {
+		t.Fatalf("unexpected retarget bits %x", bits)
+	}
+	for _, bits := range []uint32{0x1d00ffff, 0x207fffff, 0x1b0404cb, 0x03123456} {
+		if target, ok := btcCompactToTarget(bits); !ok || btcTargetToCompact(target) != bits {
+			t.Fatalf("compact %x round trip failed", bits)
+		}
+	}
+	if _, ok := btcCompactToTarget(0x04923456); ok {
+		t.Fatal("expected negative target to be invalid")
+	}
+}
+
+func mustBtcTarget(bits uint32) *big.Int {
+	target, _ := btcCompactToTarget(bits)
+	return target
+}
+
+func TestHeaderRelay(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox().AddPoint(11, 100))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	sender, _ := types.BytesToAddress([]byte{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 0})
+	regtest := []byte{0x20, 0x7f, 0xff, 0xff, 1}
+	db := newStorageDb(types.AddressHeaderRelay)
+	call := func(data []byte, height uint64) ([]*ledger.AccountBlock, error) {
+		method, ok, err := GetBuiltinContractMethod(types.AddressHeaderRelay, data, height)
+		if !ok || err != nil {
+			return nil, err
+		}
+		sendBlock := &ledger.AccountBlock{AccountAddress: sender, ToAddress: types.AddressHeaderRelay, Amount: big.NewInt(0), Data: data}
+		if err := method.DoSend(nil, sendBlock); err != nil {
+			return nil, err
+		}
+		return method.DoReceive(db, &ledger.AccountBlock{AccountAddress: types.AddressHeaderRelay}, sendBlock, nil)
+	}
+
+	tx0, tx1 := types.DataHash([]byte{0}), types.DataHash([]byte{1})
+	anchor := mineBtcHeader(t, types.Hash{}, types.Hash{}, 1000)
+	a1 := mineBtcHeader(t, btcDoubleHash(anchor), btcDoubleHash(tx0[:], tx1[:]), 1600)
+	a2 := mineBtcHeader(t, btcDoubleHash(a1), types.Hash{}, 2200)
+	b2 := mineBtcHeader(t, btcDoubleHash(a1), types.Hash{1}, 2200)
+	b3 := mineBtcHeader(t, btcDoubleHash(b2), types.Hash{}, 2800)
+
+	register, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameRegisterRelay, uint64(1), abi.RelayVerifierBTC, uint64(10), anchor, regtest)
+	if _, ok, err := GetBuiltinContractMethod(types.AddressHeaderRelay, register, 99); ok || err != nil {
+		t.Fatalf("expected not a built-in contract before the fork, got %v %v", ok, err)
+	}
+	if _, err := call(register, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := call(register, 100); err != util.ErrIDCollision {
+		t.Fatalf("expected id collision, got %v", err)
+	}
+
+	submit := func(headers ...[]byte) error {
+		data, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameSubmitHeaders, uint64(1), bytes.Join(headers, nil))
+		_, err := call(data, 100)
+		return err
+	}
+	tip := func() *abi.RelayInfo {
+		info, err := abi.GetRelayInfo(db, 1)
+		if err != nil || info == nil {
+			t.Fatalf("get relay info failed, %v", err)
+		}
+		return info
+	}
+	if err := submit(a1, a2); err != nil {
+		t.Fatal(err)
+	}
+	if info := tip(); info.TipHash != btcDoubleHash(a2) || info.TipHeight != 12 {
+		t.Fatalf("unexpected tip %v %v", info.TipHash, info.TipHeight)
+	}
+	// a fork of the same work doesn't replace the tip, a longer fork does
+	if err := submit(b2); err != nil {
+		t.Fatal(err)
+	}
+	if info := tip(); info.TipHash != btcDoubleHash(a2) {
+		t.Fatalf("unexpected tip %v", info.TipHash)
+	}
+	if err := submit(b2, b3); err != nil {
+		t.Fatal(err)
+	}
+	if info := tip(); info.TipHash != btcDoubleHash(b3) || info.TipHeight != 13 {
+		t.Fatalf("unexpected tip %v %v", info.TipHash, info.TipHeight)
+	}
+	if hash, _ := abi.GetRelayHashByHeight(db, 1, 12); hash == nil || *hash != btcDoubleHash(b2) {
+		t.Fatalf("height 12 should be rewritten by the reorg, got %v", hash)
+	}
+	// an orphan header fails the call
+	if err := submit(mineBtcHeader(t, types.Hash{2}, types.Hash{}, 3400)); err != errRelayInvalidHeader {
+		t.Fatalf("expected invalid header, got %v", err)
+	}
+	// the sync committee updates are only for the relays of the beacon chain
+	update, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameSubmitSyncCommitteeUpdate, uint64(1), make([]byte, ethUpdateLength))
+	if _, err := call(update, 100); err != errRelayWrongMethod {
+		t.Fatalf("expected wrong method, got %v", err)
+	}
+
+	verify := func(blockHash types.Hash, leaf types.Hash, proof [][32]byte, index uint64, minConfirmations uint64) (bool, uint64) {
+		data, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameVerifyInclusion, uint64(1), blockHash, leaf, proof, index, minConfirmations)
+		blockList, err := call(data, 100)
+		if err != nil || len(blockList) != 1 || blockList[0].ToAddress != sender {
+			t.Fatalf("unexpected callback %v %v", blockList, err)
+		}
+		var result struct {
+			Id            types.Hash
+			RelayId       uint64
+			BlockHash     types.Hash
+			Leaf          types.Hash
+			Verified      bool
+			Confirmations uint64
+		}
+		if err := abi.ABIHeaderRelay.Callbacks[abi.MethodNameVerifyInclusion+"Callback"].Inputs.Unpack(&result, blockList[0].Data[4:]); err != nil {
+			t.Fatal(err)
+		}
+		return result.Verified, result.Confirmations
+	}
+	a1Hash := btcDoubleHash(a1)
+	if verified, confirmations := verify(a1Hash, tx1, [][32]byte{tx0}, 1, 3); !verified || confirmations != 3 {
+		t.Fatalf("unexpected result %v %v", verified, confirmations)
+	}
+	if verified, _ := verify(a1Hash, tx1, [][32]byte{tx0}, 0, 1); verified {
+		t.Fatal("expected the proof of a wrong index to fail")
+	}
+	if verified, confirmations := verify(a1Hash, tx1, [][32]byte{tx0}, 1, 4); verified || confirmations != 3 {
+		t.Fatalf("expected not enough confirmations, got %v %v", verified, confirmations)
+	}
+	if verified, confirmations := verify(btcDoubleHash(a2), tx1, nil, 0, 0); verified || confirmations != 0 {
+		t.Fatalf("expected the header out of the chain of the tip to fail, got %v %v", verified, confirmations)
+	}
+}
+
+// ethSyncCommittee returns the compressed pubkeys of the sync committee, the secret key of the i-th member is i+1
+func ethSyncCommittee() []byte {
+	g1 := kbls.NewG1()
+	pubkeys := make([]byte, 0, ethSyncCommitteeSize*ethPubkeyLength)
+	p := g1.New()
+	for i := 0; i < ethSyncCommitteeSize; i++ {
+		g1.Add(p, p, &kbls.G1One)
+		pubkeys = append(pubkeys, g1.ToCompressed(p)...)
+	}
+	return pubkeys
+}
+
+// ethSign returns the signature of the message aggregated by the first n members of ethSyncCommittee
+func ethSign(t *testing.T, message []byte, n int) []byte {
+	g2 := kbls.NewG2()
+	h, err := g2.HashToCurve(message, []byte("BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := big.NewInt(int64(n * (n + 1) / 2))
+	return g2.ToCompressed(g2.MulScalarBig(g2.New(), h, sum))
+}
+
+func TestEthRelayVerifier(t *testing.T) {
+	params := make([]byte, ethParamsFixedLength+ethForkLength)
+	params[0] = 1
+	binary.BigEndian.PutUint64(params[40:48], 12)
+	binary.BigEndian.PutUint64(params[ethParamsFixedLength+12:], 55)
+	if err := (ethRelayVerifier{}).checkParams(params); err != nil {
+		t.Fatal(err)
+	}
+
+	// a state root with the current and the next sync committee at the generalized index 54 and 55
+	pubkeys := ethSyncCommittee()
+	aggregatePubkey := bytes.Repeat([]byte{8}, ethPubkeyLength)
+	committeeRoot := ethSyncCommitteeRoot(pubkeys, aggregatePubkey)
+	branch := [][32]byte{committeeRoot, {1}, {2}, {3}, {4}}
+	stateRoot := types.Hash(sha256.Sum256(append(committeeRoot.Bytes(), committeeRoot.Bytes()...)))
+	for i, sibling := range branch[1:] {
+		if (54>>uint(i+1))&1 == 1 {
+			stateRoot = sha256.Sum256(append(sibling[:], stateRoot[:]...))
+		} else {
+			stateRoot = sha256.Sum256(append(stateRoot[:], sibling[:]...))
+		}
+	}
+	encodeHeader := func(slot uint64) []byte {
+		raw := make([]byte, ethHeaderLength)
+		binary.LittleEndian.PutUint64(raw, slot)
+		copy(raw[48:80], stateRoot[:])
+		return raw
+	}
+	anchor := append(append(encodeHeader(100), committeeRoot.Bytes()...), joinHashes(branch)...)
+	_, anchorHeader, state, err := ethRelayVerifier{}.verifyAnchor(params, 100, anchor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &abi.RelayInfo{Verifier: abi.RelayVerifierETH, TipHeight: anchorHeader.Height, TipWork: anchorHeader.Work, Params: params, State: state}
+
+	update := encodeHeader(200)
+	update = append(update, make([]byte, 8)...)
+	binary.BigEndian.PutUint64(update[ethHeaderLength:], 201)
+	committeeBits := make([]byte, ethSyncCommitteeSize/8)
+	for i := 0; i < 400; i++ {
+		committeeBits[i/8] |= 1 << uint(i%8)
+	}
+	update = append(update, committeeBits...)
+	domain := ethSyncCommitteeDomain([4]byte{}, [32]byte{1})
+	signingRoot := sha256.Sum256(append(decodeEthHeader(update).root().Bytes(), domain[:]...))
+	signature := ethSign(t, signingRoot[:], 400)
+	update = append(update, signature...)
+	update = append(update, pubkeys...)
+	update = append(update, aggregatePubkey...)
+	update = append(update, committeeRoot.Bytes()...)
+	// the next sync committee is the same as the current one, so is the branch
+	update = append(update, joinHashes(branch)...)
+
+	hash, header, err := ethRelayVerifier{}.verifyHeader(nil, 1, info, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Height != 200 || header.Timestamp != 2400 || hash != decodeEthHeader(update).root() {
+		t.Fatalf("unexpected header %+v", header)
+	}
+	newState, _ := decodeEthRelayState(info.State)
+	if newState.nextRoot != committeeRoot {
+		t.Fatal("the next sync committee should be set")
+	}
+
+	// the updates are submitted by SubmitSyncCommitteeUpdate, which charges the signature verification by the participants
+	if _, err := (ethRelayVerifier{}).splitHeaders(update); err != errRelayWrongMethod {
+		t.Fatalf("expected wrong method, got %v", err)
+	}
+	gasTable := &util.QuotaTable{RelaySubmitHeadersQuota: 21000, RelayHeadersWordQuota: 100, RelayBlsPairingQuota: 40000, RelayBlsPubkeyQuota: 1500}
+	data, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameSubmitSyncCommitteeUpdate, uint64(1), update)
+	method := &MethodSubmitSyncCommitteeUpdate{abi.MethodNameSubmitSyncCommitteeUpdate}
+	if quota, err := method.GetSendQuota(data, gasTable); err != nil || quota != 21000+100*uint64((len(update)+31)/32)+40000+400*1500 {
+		t.Fatalf("unexpected quota %v %v", quota, err)
+	}
+	short, _ := abi.ABIHeaderRelay.PackMethod(abi.MethodNameSubmitSyncCommitteeUpdate, uint64(1), update[:ethUpdateLength-1])
+	if _, err := method.GetSendQuota(short, gasTable); err != util.ErrInvalidMethodParam {
+		t.Fatalf("expected invalid param, got %v", err)
+	}
+	// the state root is the 4th of the 8 leaves of the header, the slot is 200 and the others are zero
+	var slotLeaf [32]byte
+	slotLeaf[0] = 200
+	var zero [32]byte
+	zeroPair := sha256.Sum256(make([]byte, 64))
+	proof := [][32]byte{zero, sha256.Sum256(append(slotLeaf[:], zero[:]...)), sha256.Sum256(append(zeroPair[:], zeroPair[:]...))}
+	if !(ethRelayVerifier{}).verifyInclusion(hash, header, stateRoot, proof, 11) {
+		t.Fatal("expected the state root in the header to be verified")
+	}
+
+	// signed by another set of the members
+	wrong := append([]byte{}, update...)
+	copy(wrong[ethHeaderLength+8+ethSyncCommitteeSize/8:], ethSign(t, signingRoot[:], 399))
+	if _, _, err := (ethRelayVerifier{}).verifyHeader(nil, 1, info, wrong); err != errRelayInvalidHeader {
+		t.Fatalf("expected invalid header, got %v", err)
+	}
+
+	// not enough participants
+	binary.LittleEndian.PutUint64(update, 300)
+	committeeBits[0] = 0
+	copy(update[ethHeaderLength+8:], committeeBits)
+	if _, _, err := (ethRelayVerifier{}).verifyHeader(nil, 1, info, update); err != errRelayInvalidHeader {
+		t.Fatalf("expected invalid header, got %v", err)
+	}
+}
+
+func joinHashes(hashes [][32]byte) []byte {
+	data := make([]byte, 0, len(hashes)*32)
+	for _, h := range hashes {
+		data = append(data, h[:]...)
+	}
+	return data
+}
@@ -0,0 +1,223 @@
+package contracts
+
+import (
+	"math/big"
+	"regexp"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+var nameRegexp = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+
+// checkName verifies a name, lowercase letters and digits separated by single hyphens
+func checkName(name string) error {
+	if len(name) < nameLengthMin || len(name) > nameLengthMax || !nameRegexp.MatchString(name) {
+		return util.ErrInvalidMethodParam
+	}
+	return nil
+}
+
+// getOwnedNameInfo returns the name info if the name is registered by owner
+func getOwnedNameInfo(db interfaces.VmDb, name string, owner types.Address) (*abi.NameInfo, error) {
+	nameInfo, err := abi.GetNameInfo(db, name)
+	util.DealWithErr(err)
+	if nameInfo == nil || nameInfo.Owner != owner {
+		return nil, util.ErrInvalidMethodParam
+	}
+	return nameInfo, nil
+}
+
+func setNameInfo(db interfaces.VmDb, nameInfo *abi.NameInfo) {
+	data, _ := abi.ABINameService.PackVariable(
+		abi.VariableNameNameInfo,
+		nameInfo.Name,
+		nameInfo.Owner,
+		nameInfo.Address,
+		nameInfo.TokenId,
+		nameInfo.RegistrationHeight)
+	util.SetValue(db, abi.GetNameInfoKey(nameInfo.Name), data)
+}
+
+type MethodRegisterName struct {
+	MethodName string
+}
+
+func (p *MethodRegisterName) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	if block.Amount.Sign() > 0 {
+		return big.NewInt(0), util.ErrInvalidMethodParam
+	}
+	return new(big.Int).Set(nameRegistrationFee), nil
+}
+func (p *MethodRegisterName) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodRegisterName) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.NameServiceRegisterQuota, nil
+}
+func (p *MethodRegisterName) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodRegisterName) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	name := new(string)
+	if err := abi.ABINameService.UnpackMethod(name, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if err := checkName(*name); err != nil {
+		return err
+	}
+	block.Data, _ = abi.ABINameService.PackMethod(p.MethodName, *name)
+	return nil
+}
+func (p *MethodRegisterName) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	name := new(string)
+	abi.ABINameService.UnpackMethod(name, p.MethodName, sendBlock.Data)
+	oldNameInfo, err := abi.GetNameInfo(db, *name)
+	util.DealWithErr(err)
+	if oldNameInfo != nil {
+		return nil, util.ErrIDCollision
+	}
+	// the fee is not added to the balance of the contract, so it is burned
+	setNameInfo(db, &abi.NameInfo{
+		Name:               *name,
+		Owner:              sendBlock.AccountAddress,
+		RegistrationHeight: vm.GlobalStatus().SnapshotBlock().Height,
+	})
+	db.AddLog(NewLog(abi.ABINameService, util.FirstToLower(p.MethodName), abi.GetNameHash(*name), *name, sendBlock.AccountAddress))
+	return nil, nil
+}
+
+type MethodSetNameAddress struct {
+	MethodName string
+}
+
+func (p *MethodSetNameAddress) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodSetNameAddress) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodSetNameAddress) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.NameServiceUpdateQuota, nil
+}
+func (p *MethodSetNameAddress) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodSetNameAddress) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamSetNameAddress)
+	if err := abi.ABINameService.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if err := checkName(param.Name); err != nil {
+		return err
+	}
+	block.Data, _ = abi.ABINameService.PackMethod(p.MethodName, param.Name, param.Address)
+	return nil
+}
+func (p *MethodSetNameAddress) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamSetNameAddress)
+	abi.ABINameService.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	nameInfo, err := getOwnedNameInfo(db, param.Name, sendBlock.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	nameInfo.Address = param.Address
+	setNameInfo(db, nameInfo)
+	db.AddLog(NewLog(abi.ABINameService, util.FirstToLower(p.MethodName), abi.GetNameHash(param.Name), param.Address))
+	return nil, nil
+}
+
+type MethodSetNameTokenId struct {
+	MethodName string
+}
+
+func (p *MethodSetNameTokenId) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodSetNameTokenId) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodSetNameTokenId) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.NameServiceUpdateQuota, nil
+}
+func (p *MethodSetNameTokenId) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodSetNameTokenId) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamSetNameTokenId)
+	if err := abi.ABINameService.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if err := checkName(param.Name); err != nil {
+		return err
+	}
+	block.Data, _ = abi.ABINameService.PackMethod(p.MethodName, param.Name, param.TokenId)
+	return nil
+}
+func (p *MethodSetNameTokenId) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamSetNameTokenId)
+	abi.ABINameService.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	nameInfo, err := getOwnedNameInfo(db, param.Name, sendBlock.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	nameInfo.TokenId = param.TokenId
+	setNameInfo(db, nameInfo)
+	db.AddLog(NewLog(abi.ABINameService, util.FirstToLower(p.MethodName), abi.GetNameHash(param.Name), param.TokenId))
+	return nil, nil
+}
+
+type MethodTransferNameOwnership struct {
+	MethodName string
+}
+
+func (p *MethodTransferNameOwnership) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodTransferNameOwnership) GetRefundData(sendBlock *ledger.AccountBlock, sbHeight uint64) ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodTransferNameOwnership) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return gasTable.NameServiceTransferOwnershipQuota, nil
+}
+func (p *MethodTransferNameOwnership) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return 0
+}
+func (p *MethodTransferNameOwnership) DoSend(db interfaces.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamTransferNameOwnership)
+	if err := abi.ABINameService.UnpackMethod(param, p.MethodName, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if err := checkName(param.Name); err != nil {
+		return err
+	}
+	if param.NewOwner == block.AccountAddress {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABINameService.PackMethod(p.MethodName, param.Name, param.NewOwner)
+	return nil
+}
+func (p *MethodTransferNameOwnership) DoReceive(db interfaces.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamTransferNameOwnership)
+	abi.ABINameService.UnpackMethod(param, p.MethodName, sendBlock.Data)
+	nameInfo, err := getOwnedNameInfo(db, param.Name, sendBlock.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	nameInfo.Owner = param.NewOwner
+	setNameInfo(db, nameInfo)
+	db.AddLog(NewLog(abi.ABINameService, util.FirstToLower(p.MethodName), abi.GetNameHash(param.Name), param.NewOwner))
+	return nil, nil
+}
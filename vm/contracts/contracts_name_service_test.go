@@ -0,0 +1,67 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+)
+
+func TestCheckName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"vite", true},
+		{"my-wallet-01", true},
+		{"abc", true},
+		{"ab", false},
+		{"Vite", false},
+		{"-vite", false},
+		{"vite-", false},
+		{"vi--te", false},
+		{"vite.vite", false},
+		{"abcdefghijklmnopqrstuvwxyz012345", true},
+		{"abcdefghijklmnopqrstuvwxyz0123456", false},
+	}
+	for _, test := range tests {
+		if err := checkName(test.name); (err == nil) != test.valid {
+			t.Fatalf("check name %q, expected valid %v, got %v", test.name, test.valid, err)
+		}
+	}
+}
+
+func TestNameServiceActivation(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox().AddPoint(11, 100))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	data, _ := abi.ABINameService.PackMethod(abi.MethodNameRegisterName, "vite")
+	if _, ok, err := GetBuiltinContractMethod(types.AddressNameService, data, 99); ok || err != nil {
+		t.Fatalf("expected not a built-in contract before the fork, got %v %v", ok, err)
+	}
+	method, ok, err := GetBuiltinContractMethod(types.AddressNameService, data, 100)
+	if !ok || err != nil {
+		t.Fatalf("method not found after the fork, %v %v", ok, err)
+	}
+	if _, ok := method.(*MethodRegisterName); !ok {
+		t.Fatalf("unexpected method %T", method)
+	}
+
+	info := &abi.NameInfo{Name: "vite", Owner: types.AddressQuota, TokenId: types.TokenTypeId{1}, RegistrationHeight: 100}
+	packed, err := abi.ABINameService.PackVariable(abi.VariableNameNameInfo, info.Name, info.Owner, info.Address, info.TokenId, info.RegistrationHeight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unpacked := new(abi.NameInfo)
+	if err := abi.ABINameService.UnpackVariable(unpacked, abi.VariableNameNameInfo, packed); err != nil {
+		t.Fatal(err)
+	}
+	if *unpacked != *info {
+		t.Fatalf("unpacked %+v, expected %+v", unpacked, info)
+	}
+	if log := NewLog(abi.ABINameService, "registerName", abi.GetNameHash("vite"), "vite", info.Owner); len(log.Topics) != 2 {
+		t.Fatalf("unexpected topics %v", log.Topics)
+	}
+}
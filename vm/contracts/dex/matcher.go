@@ -127,6 +127,43 @@ func (mc *Matcher) GetOrdersFromMarket(side bool, begin, end int) ([]*Order, int
 	return orders, len(orders), nil
 }
 
+// PriceLevel is the unexecuted quantity and amount of the orders at a price
+type PriceLevel struct {
+	Price    []byte
+	Quantity *big.Int
+	Amount   *big.Int
+	Orders   int
+}
+
+// GetDepthFromMarket aggregates the orders of the side by price, at most levels prices from the best one.
+// At most maxOrders orders are read, truncated is true if they are all read before the levels are complete.
+func (mc *Matcher) GetDepthFromMarket(side bool, levels, maxOrders int) (depth []*PriceLevel, truncated bool, err error) {
+	var book *levelDbBook
+	if book, err = getMakerBook(mc.db, mc.MarketInfo.MarketId, side); err != nil {
+		return nil, false, err
+	}
+	defer book.release()
+
+	depth = make([]*PriceLevel, 0, levels)
+	for i := 0; i < maxOrders; i++ {
+		order, ok := book.nextOrder()
+		if !ok {
+			return depth, false, nil
+		}
+		if len(depth) == 0 || !bytes.Equal(depth[len(depth)-1].Price, order.Price) {
+			if len(depth) == levels {
+				return depth, false, nil
+			}
+			depth = append(depth, &PriceLevel{Price: order.Price, Quantity: new(big.Int), Amount: new(big.Int)})
+		}
+		level := depth[len(depth)-1]
+		level.Quantity.Add(level.Quantity, SubBigInt(order.Quantity, order.ExecutedQuantity))
+		level.Amount.Add(level.Amount, SubBigInt(order.Amount, order.ExecutedAmount))
+		level.Orders++
+	}
+	return depth, len(depth) > 0, nil
+}
+
 func (mc *Matcher) CancelOrderById(order *Order) {
 	switch order.Status {
 	case Pending:
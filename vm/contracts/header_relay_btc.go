@@ -0,0 +1,241 @@
+package contracts
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+const (
+	btcHeaderLength     = 80
+	btcRetargetInterval = 2016
+	btcTargetTimespan   = 14 * 24 * 60 * 60
+)
+
+var btcTwo256 = new(big.Int).Lsh(big.NewInt(1), 256)
+
+// btcRelayVerifier is a SPV client of bitcoin like chains, the headers are verified by the proof of work and the retarget rules.
+// Hashes are the double SHA-256 in the byte order of serialization, which is the reverse of the order shown by explorers.
+//
+// The params are the compact pow limit(4 bytes, big endian) and a flag byte, 1 means the target never changes like regtest.
+// The extra of a stored header is its compact target(4 bytes) and the timestamp of the first block of its retarget period(8 bytes).
+type btcRelayVerifier struct{}
+
+type btcRelayParams struct {
+	powLimit   *big.Int
+	noRetarget bool
+}
+
+type btcHeader struct {
+	hash       types.Hash
+	parentHash types.Hash
+	merkleRoot types.Hash
+	timestamp  uint64
+	bits       uint32
+	target     *big.Int
+}
+
+func decodeBtcRelayParams(params []byte) (*btcRelayParams, error) {
+	if len(params) != 5 || params[4] > 1 {
+		return nil, util.ErrInvalidMethodParam
+	}
+	powLimit, ok := btcCompactToTarget(binary.BigEndian.Uint32(params))
+	if !ok {
+		return nil, util.ErrInvalidMethodParam
+	}
+	return &btcRelayParams{powLimit: powLimit, noRetarget: params[4] == 1}, nil
+}
+
+// decodeBtcHeader parses a serialized header and checks its proof of work
+func decodeBtcHeader(params *btcRelayParams, raw []byte) (*btcHeader, error) {
+	if len(raw) != btcHeaderLength {
+		return nil, errRelayInvalidHeader
+	}
+	header := &btcHeader{
+		hash:      btcDoubleHash(raw),
+		timestamp: uint64(binary.LittleEndian.Uint32(raw[68:72])),
+		bits:      binary.LittleEndian.Uint32(raw[72:76]),
+	}
+	copy(header.parentHash[:], raw[4:36])
+	copy(header.merkleRoot[:], raw[36:68])
+	target, ok := btcCompactToTarget(header.bits)
+	if !ok || target.Cmp(params.powLimit) > 0 || btcHashToBig(header.hash).Cmp(target) > 0 {
+		return nil, errRelayInvalidHeader
+	}
+	header.target = target
+	return header, nil
+}
+
+func (btcRelayVerifier) checkParams(params []byte) error {
+	_, err := decodeBtcRelayParams(params)
+	return err
+}
+
+// verifyAnchor requires the anchor to be the first block of a retarget period, so the period start is known
+func (btcRelayVerifier) verifyAnchor(params []byte, height uint64, raw []byte) (types.Hash, *abi.RelayHeader, []byte, error) {
+	p, err := decodeBtcRelayParams(params)
+	if err != nil {
+		return types.Hash{}, nil, nil, err
+	}
+	if !p.noRetarget && height%btcRetargetInterval != 0 {
+		return types.Hash{}, nil, nil, util.ErrInvalidMethodParam
+	}
+	header, err := decodeBtcHeader(p, raw)
+	if err != nil {
+		return types.Hash{}, nil, nil, err
+	}
+	return header.hash, &abi.RelayHeader{
+		Height:     height,
+		ParentHash: header.parentHash,
+		Root:       header.merkleRoot,
+		Work:       btcWork(header.target),
+		Timestamp:  header.timestamp,
+		Extra:      btcHeaderExtra(header.bits, header.timestamp),
+	}, []byte{}, nil
+}
+
+func (btcRelayVerifier) splitHeaders(data []byte) ([][]byte, error) {
+	if len(data)%btcHeaderLength != 0 {
+		return nil, errRelayInvalidHeader
+	}
+	headers := make([][]byte, 0, len(data)/btcHeaderLength)
+	for i := 0; i < len(data); i += btcHeaderLength {
+		headers = append(headers, data[i:i+btcHeaderLength])
+	}
+	return headers, nil
+}
+
+// verifyHeader requires the parent of the header to be stored, Work of the header is the cumulative work from the anchor
+func (btcRelayVerifier) verifyHeader(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo, raw []byte) (types.Hash, *abi.RelayHeader, error) {
+	p, err := decodeBtcRelayParams(info.Params)
+	if err != nil {
+		return types.Hash{}, nil, err
+	}
+	header, err := decodeBtcHeader(p, raw)
+	if err != nil {
+		return types.Hash{}, nil, err
+	}
+	parent, err := abi.GetRelayHeader(db, relayId, header.parentHash)
+	util.DealWithErr(err)
+	if parent == nil || len(parent.Extra) != 12 {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	parentBits := binary.BigEndian.Uint32(parent.Extra)
+	periodStart := binary.BigEndian.Uint64(parent.Extra[4:])
+	height := parent.Height + 1
+	expectedBits := parentBits
+	if !p.noRetarget && height%btcRetargetInterval == 0 {
+		expectedBits = btcRetarget(p, parentBits, int64(parent.Timestamp)-int64(periodStart))
+		periodStart = header.timestamp
+	}
+	if header.bits != expectedBits {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	return header.hash, &abi.RelayHeader{
+		Height:     height,
+		ParentHash: header.parentHash,
+		Root:       header.merkleRoot,
+		Work:       new(big.Int).Add(parent.Work, btcWork(header.target)),
+		Timestamp:  header.timestamp,
+		Extra:      btcHeaderExtra(header.bits, periodStart),
+	}, nil
+}
+
+// verifyInclusion verifies a merkle branch of a transaction id, index is the position of the transaction in the block
+func (btcRelayVerifier) verifyInclusion(hash types.Hash, header *abi.RelayHeader, leaf types.Hash, proof [][32]byte, index uint64) bool {
+	current := leaf
+	for _, sibling := range proof {
+		if index&1 == 1 {
+			current = btcDoubleHash(sibling[:], current[:])
+		} else {
+			current = btcDoubleHash(current[:], sibling[:])
+		}
+		index >>= 1
+	}
+	return index == 0 && current == header.Root
+}
+
+// btcRetarget calculates the compact target of the first block of a retarget period, timespan is the time
+// from the first block to the last block of the previous period
+func btcRetarget(p *btcRelayParams, bits uint32, timespan int64) uint32 {
+	if timespan < btcTargetTimespan/4 {
+		timespan = btcTargetTimespan / 4
+	} else if timespan > btcTargetTimespan*4 {
+		timespan = btcTargetTimespan * 4
+	}
+	target, _ := btcCompactToTarget(bits)
+	target.Mul(target, big.NewInt(timespan))
+	target.Div(target, big.NewInt(btcTargetTimespan))
+	if target.Cmp(p.powLimit) > 0 {
+		target = p.powLimit
+	}
+	return btcTargetToCompact(target)
+}
+
+func btcHeaderExtra(bits uint32, periodStart uint64) []byte {
+	extra := make([]byte, 12)
+	binary.BigEndian.PutUint32(extra, bits)
+	binary.BigEndian.PutUint64(extra[4:], periodStart)
+	return extra
+}
+
+func btcDoubleHash(data ...[]byte) types.Hash {
+	h := sha256.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return sha256.Sum256(h.Sum(nil))
+}
+
+// btcHashToBig converts a hash to the number compared with the target, the hash is little endian
+func btcHashToBig(hash types.Hash) *big.Int {
+	buf := make([]byte, len(hash))
+	for i, b := range hash {
+		buf[len(hash)-1-i] = b
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+// btcWork is the expected count of hashes to find a block of the target, 2^256 / (target + 1)
+func btcWork(target *big.Int) *big.Int {
+	return new(big.Int).Div(btcTwo256, new(big.Int).Add(target, big.NewInt(1)))
+}
+
+// btcCompactToTarget decodes a compact target, negative, zero and overflowed targets are invalid
+func btcCompactToTarget(bits uint32) (*big.Int, bool) {
+	mantissa := int64(bits & 0x007fffff)
+	exponent := uint(bits >> 24)
+	if bits&0x00800000 != 0 {
+		return nil, false
+	}
+	target := big.NewInt(mantissa)
+	if exponent <= 3 {
+		target.Rsh(target, 8*(3-exponent))
+	} else {
+		target.Lsh(target, 8*(exponent-3))
+	}
+	if target.Sign() == 0 || target.BitLen() > 256 {
+		return nil, false
+	}
+	return target, true
+}
+
+func btcTargetToCompact(target *big.Int) uint32 {
+	var mantissa uint32
+	exponent := uint(len(target.Bytes()))
+	if exponent <= 3 {
+		mantissa = uint32(target.Uint64()) << (8 * (3 - exponent))
+	} else {
+		mantissa = uint32(new(big.Int).Rsh(target, 8*(exponent-3)).Uint64())
+	}
+	if mantissa&0x00800000 != 0 {
+		mantissa >>= 8
+		exponent++
+	}
+	return uint32(exponent<<24) | mantissa
+}
@@ -0,0 +1,365 @@
+package contracts
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/bls"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+const (
+	ethSlotsPerEpoch         = 32
+	ethSlotsPerPeriod        = ethSlotsPerEpoch * 256 // 256 epochs per sync committee period
+	ethSyncCommitteeSize     = 512
+	ethPubkeyLength          = 48
+	ethSignatureLength       = 96
+	ethHeaderLength          = 112
+	ethParamsFixedLength     = 48
+	ethForkLength            = 20
+	ethStateLength           = 72
+	ethUpdateLength          = ethHeaderLength + 8 + ethSyncCommitteeSize/8 + ethSignatureLength + (ethSyncCommitteeSize+1)*ethPubkeyLength
+	ethDomainSyncCommittee   = 0x07000000
+	ethMaxCommitteeGindexLen = 16
+)
+
+// ethRelayVerifier is a light client of the ethereum beacon chain, a header is verified by the signature of
+// at least 2/3 of the sync committee. The height of a header is its slot, Work is the slot too, so the latest
+// signed header is the tip. The hash of a header is its SSZ root and Root is its state root.
+//
+// The params are the genesis validators root(32 bytes), the genesis time(8 bytes), seconds per slot(8 bytes) and the
+// forks in ascending order of epoch, each fork is its epoch(8 bytes), version(4 bytes) and the generalized index
+// of the next sync committee in the beacon state(8 bytes), integers are big endian.
+// The state is the current sync committee period(8 bytes), the roots of the current and the next sync committee.
+type ethRelayVerifier struct{}
+
+type ethFork struct {
+	epoch                  uint64
+	version                [4]byte
+	nextCommitteeGindex    uint64
+	currentCommitteeGindex uint64
+}
+
+type ethRelayParams struct {
+	genesisValidatorsRoot [32]byte
+	genesisTime           uint64
+	secondsPerSlot        uint64
+	forks                 []ethFork
+}
+
+type ethRelayState struct {
+	period      uint64
+	currentRoot types.Hash
+	nextRoot    types.Hash
+}
+
+type ethHeader struct {
+	slot          uint64
+	proposerIndex uint64
+	parentRoot    types.Hash
+	stateRoot     types.Hash
+	bodyRoot      types.Hash
+}
+
+func decodeEthRelayParams(params []byte) (*ethRelayParams, error) {
+	if len(params) < ethParamsFixedLength+ethForkLength || (len(params)-ethParamsFixedLength)%ethForkLength != 0 {
+		return nil, util.ErrInvalidMethodParam
+	}
+	p := &ethRelayParams{
+		genesisTime:    binary.BigEndian.Uint64(params[32:40]),
+		secondsPerSlot: binary.BigEndian.Uint64(params[40:48]),
+	}
+	copy(p.genesisValidatorsRoot[:], params[:32])
+	if p.secondsPerSlot == 0 {
+		return nil, util.ErrInvalidMethodParam
+	}
+	for i := ethParamsFixedLength; i < len(params); i += ethForkLength {
+		fork := ethFork{
+			epoch:               binary.BigEndian.Uint64(params[i:]),
+			nextCommitteeGindex: binary.BigEndian.Uint64(params[i+12:]),
+		}
+		copy(fork.version[:], params[i+8:i+12])
+		// the current sync committee is the left sibling of the next sync committee in the beacon state
+		if fork.nextCommitteeGindex < 3 || fork.nextCommitteeGindex%2 == 0 || bits.Len64(fork.nextCommitteeGindex) > ethMaxCommitteeGindexLen {
+			return nil, util.ErrInvalidMethodParam
+		}
+		fork.currentCommitteeGindex = fork.nextCommitteeGindex - 1
+		if len(p.forks) > 0 && fork.epoch <= p.forks[len(p.forks)-1].epoch {
+			return nil, util.ErrInvalidMethodParam
+		}
+		p.forks = append(p.forks, fork)
+	}
+	return p, nil
+}
+
+// forkAt returns the fork active at the slot, the first fork is used for the slots before it
+func (p *ethRelayParams) forkAt(slot uint64) ethFork {
+	epoch := slot / ethSlotsPerEpoch
+	fork := p.forks[0]
+	for _, f := range p.forks[1:] {
+		if f.epoch > epoch {
+			break
+		}
+		fork = f
+	}
+	return fork
+}
+
+func decodeEthRelayState(state []byte) (*ethRelayState, error) {
+	if len(state) != ethStateLength {
+		return nil, errRelayInvalidHeader
+	}
+	s := &ethRelayState{period: binary.BigEndian.Uint64(state)}
+	copy(s.currentRoot[:], state[8:40])
+	copy(s.nextRoot[:], state[40:72])
+	return s, nil
+}
+
+func (s *ethRelayState) encode() []byte {
+	state := make([]byte, ethStateLength)
+	binary.BigEndian.PutUint64(state, s.period)
+	copy(state[8:40], s.currentRoot[:])
+	copy(state[40:72], s.nextRoot[:])
+	return state
+}
+
+// decodeEthHeader parses a BeaconBlockHeader in SSZ serialization
+func decodeEthHeader(raw []byte) *ethHeader {
+	h := &ethHeader{
+		slot:          binary.LittleEndian.Uint64(raw[0:8]),
+		proposerIndex: binary.LittleEndian.Uint64(raw[8:16]),
+	}
+	copy(h.parentRoot[:], raw[16:48])
+	copy(h.stateRoot[:], raw[48:80])
+	copy(h.bodyRoot[:], raw[80:112])
+	return h
+}
+
+func (h *ethHeader) root() types.Hash {
+	leaves := make([][32]byte, 8)
+	binary.LittleEndian.PutUint64(leaves[0][:], h.slot)
+	binary.LittleEndian.PutUint64(leaves[1][:], h.proposerIndex)
+	leaves[2], leaves[3], leaves[4] = h.parentRoot, h.stateRoot, h.bodyRoot
+	return sszMerkleize(leaves)
+}
+
+func (h *ethHeader) relayHeader(p *ethRelayParams) *abi.RelayHeader {
+	return &abi.RelayHeader{
+		Height:     h.slot,
+		ParentHash: h.parentRoot,
+		Root:       h.stateRoot,
+		Work:       new(big.Int).SetUint64(h.slot),
+		Timestamp:  p.genesisTime + h.slot*p.secondsPerSlot,
+		Extra:      h.bodyRoot.Bytes(),
+	}
+}
+
+func (ethRelayVerifier) checkParams(params []byte) error {
+	_, err := decodeEthRelayParams(params)
+	return err
+}
+
+// verifyAnchor verifies the trusted header with the root of its current sync committee and the branch
+// of the root in the beacon state: header(112 bytes) | committee root(32 bytes) | branch(32 bytes each)
+func (ethRelayVerifier) verifyAnchor(params []byte, height uint64, raw []byte) (types.Hash, *abi.RelayHeader, []byte, error) {
+	p, err := decodeEthRelayParams(params)
+	if err != nil {
+		return types.Hash{}, nil, nil, err
+	}
+	if len(raw) < ethHeaderLength+32 {
+		return types.Hash{}, nil, nil, errRelayInvalidHeader
+	}
+	header := decodeEthHeader(raw)
+	if header.slot != height {
+		return types.Hash{}, nil, nil, util.ErrInvalidMethodParam
+	}
+	var committeeRoot types.Hash
+	copy(committeeRoot[:], raw[ethHeaderLength:ethHeaderLength+32])
+	branch, ok := splitHashes(raw[ethHeaderLength+32:])
+	if !ok || !sszVerifyBranch(committeeRoot, branch, p.forkAt(header.slot).currentCommitteeGindex, header.stateRoot) {
+		return types.Hash{}, nil, nil, errRelayInvalidHeader
+	}
+	state := &ethRelayState{period: header.slot / ethSlotsPerPeriod, currentRoot: committeeRoot}
+	return header.root(), header.relayHeader(p), state.encode(), nil
+}
+
+// splitHeaders refuses the headers, the updates are submitted by SubmitSyncCommitteeUpdate which charges the signature
+// verification by the participants
+func (ethRelayVerifier) splitHeaders(data []byte) ([][]byte, error) {
+	return nil, errRelayWrongMethod
+}
+
+// ethUpdateParticipants returns the number of the sync committee members signed the update
+func ethUpdateParticipants(raw []byte) (int, bool) {
+	if len(raw) < ethUpdateLength {
+		return 0, false
+	}
+	offset := ethHeaderLength + 8
+	n := 0
+	for _, b := range raw[offset : offset+ethSyncCommitteeSize/8] {
+		n += bits.OnesCount8(b)
+	}
+	return n, true
+}
+
+// verifyHeader verifies an update signed by the sync committee of the state:
+//
+//	attested header(112 bytes) | signature slot(8 bytes, big endian) | sync committee bits(64 bytes) | signature(96 bytes) |
+//	pubkeys of the signing sync committee(512 * 48 bytes) | aggregate pubkey(48 bytes) |
+//	optional, the next sync committee root of the attested header(32 bytes) | branch(32 bytes each)
+//
+// The state moves to the next period when the update is signed by the next sync committee.
+func (ethRelayVerifier) verifyHeader(db interfaces.VmDb, relayId uint64, info *abi.RelayInfo, raw []byte) (types.Hash, *abi.RelayHeader, error) {
+	p, err := decodeEthRelayParams(info.Params)
+	if err != nil {
+		return types.Hash{}, nil, err
+	}
+	state, err := decodeEthRelayState(info.State)
+	if err != nil {
+		return types.Hash{}, nil, err
+	}
+	if len(raw) < ethUpdateLength {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	header := decodeEthHeader(raw)
+	offset := ethHeaderLength
+	signatureSlot := binary.BigEndian.Uint64(raw[offset:])
+	offset += 8
+	committeeBits := raw[offset : offset+ethSyncCommitteeSize/8]
+	offset += ethSyncCommitteeSize / 8
+	signature := raw[offset : offset+ethSignatureLength]
+	offset += ethSignatureLength
+	pubkeys := raw[offset : offset+ethSyncCommitteeSize*ethPubkeyLength]
+	offset += ethSyncCommitteeSize * ethPubkeyLength
+	aggregatePubkey := raw[offset : offset+ethPubkeyLength]
+	offset += ethPubkeyLength
+
+	if header.slot <= info.TipHeight || signatureSlot <= header.slot {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	signaturePeriod := signatureSlot / ethSlotsPerPeriod
+	var committeeRoot types.Hash
+	if signaturePeriod == state.period {
+		committeeRoot = state.currentRoot
+	} else if signaturePeriod == state.period+1 && state.nextRoot != (types.Hash{}) {
+		committeeRoot = state.nextRoot
+	} else {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	if ethSyncCommitteeRoot(pubkeys, aggregatePubkey) != committeeRoot {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+
+	participants := make([][]byte, 0, ethSyncCommitteeSize)
+	for i := 0; i < ethSyncCommitteeSize; i++ {
+		if committeeBits[i/8]&(1<<uint(i%8)) != 0 {
+			participants = append(participants, pubkeys[i*ethPubkeyLength:(i+1)*ethPubkeyLength])
+		}
+	}
+	if len(participants)*3 < ethSyncCommitteeSize*2 {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+	hash := header.root()
+	domain := ethSyncCommitteeDomain(p.forkAt(signatureSlot-1).version, p.genesisValidatorsRoot)
+	signingRoot := sha256.Sum256(append(hash.Bytes(), domain[:]...))
+	if !bls.FastAggregateVerify(participants, signingRoot[:], signature) {
+		return types.Hash{}, nil, errRelayInvalidHeader
+	}
+
+	if signaturePeriod == state.period+1 {
+		state.period, state.currentRoot, state.nextRoot = signaturePeriod, state.nextRoot, types.Hash{}
+	}
+	if offset < len(raw) {
+		if len(raw)-offset < 32 {
+			return types.Hash{}, nil, errRelayInvalidHeader
+		}
+		var nextRoot types.Hash
+		copy(nextRoot[:], raw[offset:offset+32])
+		branch, ok := splitHashes(raw[offset+32:])
+		if !ok || !sszVerifyBranch(nextRoot, branch, p.forkAt(header.slot).nextCommitteeGindex, header.stateRoot) {
+			return types.Hash{}, nil, errRelayInvalidHeader
+		}
+		if header.slot/ethSlotsPerPeriod == state.period && state.nextRoot == (types.Hash{}) {
+			state.nextRoot = nextRoot
+		}
+	}
+	info.State = state.encode()
+	return hash, header.relayHeader(p), nil
+}
+
+// verifyInclusion verifies a SSZ branch of the leaf, index is the generalized index in the BeaconBlockHeader
+func (ethRelayVerifier) verifyInclusion(hash types.Hash, header *abi.RelayHeader, leaf types.Hash, proof [][32]byte, index uint64) bool {
+	return sszVerifyBranch(leaf, proof, index, hash)
+}
+
+func ethSyncCommitteeRoot(pubkeys []byte, aggregatePubkey []byte) types.Hash {
+	leaves := make([][32]byte, ethSyncCommitteeSize)
+	for i := range leaves {
+		leaves[i] = sszPubkeyRoot(pubkeys[i*ethPubkeyLength : (i+1)*ethPubkeyLength])
+	}
+	root := sszMerkleize(leaves)
+	aggregateRoot := sszPubkeyRoot(aggregatePubkey)
+	return sha256.Sum256(append(root.Bytes(), aggregateRoot[:]...))
+}
+
+// ethSyncCommitteeDomain is compute_domain(DOMAIN_SYNC_COMMITTEE, fork_version, genesis_validators_root)
+func ethSyncCommitteeDomain(version [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	var versionLeaf [32]byte
+	copy(versionLeaf[:], version[:])
+	forkDataRoot := sha256.Sum256(append(versionLeaf[:], genesisValidatorsRoot[:]...))
+	var domain [32]byte
+	binary.BigEndian.PutUint32(domain[:], ethDomainSyncCommittee)
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+func sszPubkeyRoot(pubkey []byte) [32]byte {
+	var chunks [64]byte
+	copy(chunks[:], pubkey)
+	return sha256.Sum256(chunks[:])
+}
+
+// sszMerkleize calculates the root of the leaves, the count of leaves must be a power of 2
+func sszMerkleize(leaves [][32]byte) types.Hash {
+	layer := leaves
+	for len(layer) > 1 {
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+// sszVerifyBranch verifies the branch of the leaf at the generalized index against the root
+func sszVerifyBranch(leaf types.Hash, branch [][32]byte, gindex uint64, root types.Hash) bool {
+	if gindex == 0 || bits.Len64(gindex)-1 != len(branch) {
+		return false
+	}
+	current := [32]byte(leaf)
+	for i, sibling := range branch {
+		if (gindex>>uint(i))&1 == 1 {
+			current = sha256.Sum256(append(sibling[:], current[:]...))
+		} else {
+			current = sha256.Sum256(append(current[:], sibling[:]...))
+		}
+	}
+	return current == root
+}
+
+func splitHashes(data []byte) ([][32]byte, bool) {
+	if len(data)%32 != 0 {
+		return nil, false
+	}
+	hashes := make([][32]byte, len(data)/32)
+	for i := range hashes {
+		copy(hashes[i][:], data[i*32:])
+	}
+	return hashes, true
+}
@@ -25,6 +25,16 @@ const (
 	rewardTimeLimit   int64  = 3600 // Cannot get snapshot block reward of current few blocks, for latest snapshot block could be reverted
 
 	stakeHeightMax uint64 = 3600 * 24 * 365
+
+	nameLengthMin int = 3  // Minimum length of a name in name service(include)
+	nameLengthMax int = 32 // Maximum length of a name in name service(include)
+
+	batchTransferCountMax int = 100 // Maximum count of transfers in a batch transfer(include)
+
+	relayHeadersLengthMax int    = 32 * 1024 // Maximum length of the encoded headers submitted to header relay in a call(include)
+	relayParamsLengthMax  int    = 1024      // Maximum length of the verifier params of a relay(include)
+	relayProofLengthMax   int    = 64        // Maximum count of the hashes in an inclusion proof(include)
+	relayReorgDepthMax    uint64 = 144       // Maximum count of the headers replaced by a reorg of a relay(include)
 )
 
 var (
@@ -36,6 +46,10 @@ var (
 	rewardPerBlock = big.NewInt(951293759512937595)
 	stakeAmountMin = new(big.Int).Mul(big.NewInt(134), util.AttovPerVite)
 	issueFee       = new(big.Int).Mul(big.NewInt(1e3), util.AttovPerVite)
+	// nameRegistrationFee is burned when a name is registered
+	nameRegistrationFee = new(big.Int).Mul(big.NewInt(1e2), util.AttovPerVite)
+	// relayRegistrationFee is burned when a header relay is registered
+	relayRegistrationFee = new(big.Int).Mul(big.NewInt(1e3), util.AttovPerVite)
 )
 
 type contractsParams struct {
@@ -0,0 +1,111 @@
+package contracts
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+// PluginMethodQuota is the fixed quota of a plugin contract method, it replaces GetSendQuota and GetReceiveQuota of the method
+type PluginMethodQuota struct {
+	Send    uint64
+	Receive uint64
+}
+
+// PluginContract is a built-in contract added by a compiled-in extension, e.g. native functionality of a private chain
+type PluginContract struct {
+	Address types.Address
+	ABI     abi.ABIContract
+	// Methods are keyed by the method name in ABI
+	Methods map[string]BuiltinContractMethod
+	// ActivationHeight is the snapshot height from which the contract can be called
+	ActivationHeight uint64
+	// Quota is the fixed quota of methods keyed by the method name, methods not in Quota calculate quota by themselves
+	Quota map[string]PluginMethodQuota
+	// SendConfirm is whether a send block to the contract must be confirmed by a snapshot block before received
+	SendConfirm bool
+	// ReceiveUseQuota is whether the contract uses its own quota to receive, like the dex fund contract
+	ReceiveUseQuota bool
+}
+
+type pluginContract struct {
+	builtinContract
+	activationHeight uint64
+}
+
+var pluginContracts = make(map[types.Address]*pluginContract)
+
+// RegisterPluginContract adds a built-in contract at a fixed address. It is not safe for concurrent use
+// and is supposed to be called in init of the extension, before the node started.
+// All nodes of a chain must register the same contracts, or they will fork at the activation height.
+func RegisterPluginContract(c *PluginContract) error {
+	if !types.IsBuiltinContractAddr(c.Address) {
+		return fmt.Errorf("%s is not a built-in contract address", c.Address)
+	}
+	if _, ok := pluginContracts[c.Address]; ok || types.IsBuiltinContractAddrInUse(c.Address) {
+		return fmt.Errorf("built-in contract %s already exists", c.Address)
+	}
+	if len(c.Methods) == 0 {
+		return fmt.Errorf("built-in contract %s has no method", c.Address)
+	}
+	m := make(map[string]BuiltinContractMethod, len(c.Methods))
+	for name, method := range c.Methods {
+		if _, ok := c.ABI.Methods[name]; !ok {
+			return fmt.Errorf("method %s of built-in contract %s is not in abi", name, c.Address)
+		}
+		if q, ok := c.Quota[name]; ok {
+			method = &pluginMethod{method, q}
+		}
+		m[name] = method
+	}
+	for name := range c.Quota {
+		if _, ok := c.Methods[name]; !ok {
+			return fmt.Errorf("quota of unknown method %s of built-in contract %s", name, c.Address)
+		}
+	}
+
+	// the contract is in use from the activation height, it's the same address as before until then
+	if err := ledger.AddPluginBuiltinContract(c.Address, c.ReceiveUseQuota, c.SendConfirm, c.ActivationHeight); err != nil {
+		return err
+	}
+	pluginContracts[c.Address] = &pluginContract{builtinContract{m, c.ABI}, c.ActivationHeight}
+	return nil
+}
+
+// GetPluginContractABI returns the abi of a registered plugin contract
+func GetPluginContractABI(addr types.Address) (abi.ABIContract, bool) {
+	if p, ok := pluginContracts[addr]; ok {
+		return p.abi, true
+	}
+	return abi.ABIContract{}, false
+}
+
+// getPluginContractMethod finds the method of a plugin contract. Before the activation height, the address isn't
+// a built-in contract, like an unregistered one.
+func getPluginContractMethod(addr types.Address, methodSelector []byte, sbHeight uint64) (BuiltinContractMethod, bool, error) {
+	p, ok := pluginContracts[addr]
+	if !ok || sbHeight < p.activationHeight {
+		return nil, false, nil
+	}
+	if method, err := p.abi.MethodById(methodSelector); err == nil {
+		if c, methodExists := p.m[method.Name]; methodExists {
+			return c, true, nil
+		}
+	}
+	return nil, true, util.ErrAbiMethodNotFound
+}
+
+type pluginMethod struct {
+	BuiltinContractMethod
+	quota PluginMethodQuota
+}
+
+func (p *pluginMethod) GetSendQuota(data []byte, gasTable *util.QuotaTable) (uint64, error) {
+	return p.quota.Send, nil
+}
+func (p *pluginMethod) GetReceiveQuota(gasTable *util.QuotaTable) uint64 {
+	return p.quota.Receive
+}
@@ -0,0 +1,73 @@
+package contracts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+func TestRegisterPluginContract(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+
+	defer func() {
+		pluginContracts = make(map[types.Address]*pluginContract)
+	}()
+
+	pluginABI, err := abi.JSONToABIContract(strings.NewReader(`[{"type":"function","name":"Stake", "inputs":[{"name":"beneficiary","type":"address"}]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _ := types.BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x20, types.ContractAddrByte})
+	c := &PluginContract{
+		Address:          addr,
+		ABI:              pluginABI,
+		Methods:          map[string]BuiltinContractMethod{"Stake": &MethodStake{"Stake"}},
+		ActivationHeight: 100,
+		Quota:            map[string]PluginMethodQuota{"Stake": {Send: 1000, Receive: 10}},
+	}
+
+	if err := RegisterPluginContract(&PluginContract{Address: types.AddressQuota, ABI: pluginABI, Methods: c.Methods}); err == nil {
+		t.Fatal("expected error of an address in use")
+	}
+	if err := RegisterPluginContract(&PluginContract{Address: types.AddressNameService, ABI: pluginABI, Methods: c.Methods}); err == nil {
+		t.Fatal("expected error of an address of a fork")
+	}
+	if err := RegisterPluginContract(c); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterPluginContract(c); err == nil {
+		t.Fatal("expected error of a registered address")
+	}
+
+	// the address is not a built-in contract before the activation height
+	if ledger.IsBuiltinContractAddrInUse(addr, 99) || ledger.GetBuiltinContractMeta(addr, 99) != nil {
+		t.Fatal("plugin contract is in use before the activation height")
+	}
+	if !ledger.IsBuiltinContractAddrInUse(addr, 100) || !ledger.IsBuiltinContractAddrInUseWithoutQuota(addr, 100) || ledger.IsBuiltinContractAddrInUseWithSendConfirm(addr, 100) {
+		t.Fatal("plugin contract is not a built-in contract in use")
+	}
+
+	data, _ := pluginABI.PackMethod("Stake", addr)
+	if method, ok, err := GetBuiltinContractMethod(addr, data, 99); method != nil || ok || err != nil {
+		t.Fatalf("expected no contract before the activation height, got %v %v", ok, err)
+	}
+	method, ok, err := GetBuiltinContractMethod(addr, data, 100)
+	if !ok || err != nil {
+		t.Fatalf("method not found, %v %v", ok, err)
+	}
+	if q, _ := method.GetSendQuota(data, nil); q != 1000 {
+		t.Fatalf("send quota is %d", q)
+	}
+	if q := method.GetReceiveQuota(nil); q != 10 {
+		t.Fatalf("receive quota is %d", q)
+	}
+	if _, ok, err := GetBuiltinContractMethod(addr, []byte{1, 2, 3, 4}, 100); !ok || err != util.ErrAbiMethodNotFound {
+		t.Fatalf("expected no method of an unknown selector, got %v %v", ok, err)
+	}
+}
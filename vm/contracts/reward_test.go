@@ -322,6 +322,41 @@ func TestCalcRewardSingle(t *testing.T) {
 	}
 }
 
+func TestCalcRewardDetailByDayDetail(t *testing.T) {
+	name1 := "s1"
+	name2 := "s2"
+	stakeAmount := stringToBigInt("100000000000000000000000")
+	detail := &core.DayStats{
+		Index: 10,
+		Stats: map[string]*core.SbpStats{
+			name1: {Index: 10, BlockNum: 900, ExceptedBlockNum: 921, VoteCnt: &core.BigInt{stringToBigInt("899798000000000000000000000")}, Name: name1},
+			name2: {Index: 10, BlockNum: 0, ExceptedBlockNum: 921, VoteCnt: &core.BigInt{stringToBigInt("100000000000000000000000000")}, Name: name2},
+		},
+		VoteSum:    &core.BigInt{stringToBigInt("999798000000000000000000000")},
+		BlockTotal: 900,
+	}
+
+	result := calcRewardDetailByDayDetail(detail, name1, stakeAmount)
+	reward := calcRewardByDayDetail(detail, name1, stakeAmount)
+	if result.Reward.TotalReward.Cmp(reward.TotalReward) != 0 {
+		t.Fatalf("total reward not match, expected %v, got %v", reward.TotalReward, result.Reward.TotalReward)
+	}
+	if result.Index != 10 || result.SbpNum != 2 || result.TotalBlockNum != 900 || result.BlockNum != 900 || result.ExpectedBlockNum != 921 {
+		t.Fatalf("detail not match, got %+v", result)
+	}
+	if result.VoteWeight.Cmp(stringToBigInt("899898000000000000000000000")) != 0 {
+		t.Fatalf("vote weight not match, got %v", result.VoteWeight)
+	}
+	if result.TotalVoteWeight.Cmp(stringToBigInt("999998000000000000000000000")) != 0 {
+		t.Fatalf("total vote weight not match, got %v", result.TotalVoteWeight)
+	}
+
+	result = calcRewardDetailByDayDetail(detail, "s3", stakeAmount)
+	if result.Reward.TotalReward.Sign() != 0 || result.VoteCount.Sign() != 0 || result.BlockNum != 0 {
+		t.Fatalf("detail of unknown sbp should be zero, got %+v", result)
+	}
+}
+
 func stringToBigInt(s string) *big.Int {
 	i, _ := new(big.Int).SetString(s, 10)
 	return i
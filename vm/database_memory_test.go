@@ -61,6 +61,11 @@ func (db *memoryDatabase) GetSnapshotBlockByHeight(height uint64) (*ledger.Snaps
 func (db *memoryDatabase) Reset()  {}
 func (db *memoryDatabase) Finish() {}
 
+func (db *memoryDatabase) SetExecutionError(*ledger.ExecutionError) {}
+func (db *memoryDatabase) GetUnsavedExecutionError() *ledger.ExecutionError {
+	return nil
+}
+
 func (db *memoryDatabase) SetContractCode(code []byte) {
 	db.storage[getCodeKey(db.addr)] = code
 }
@@ -223,6 +228,9 @@ func (db *memoryDatabase) GetConfirmSnapshotHeader(blockHash types.Hash) (*ledge
 func (db *memoryDatabase) GetContractMetaInSnapshot(contractAddress types.Address, snapshotBlock *ledger.SnapshotBlock) (*ledger.ContractMeta, error) {
 	return &ledger.ContractMeta{Gid: types.DELEGATE_GID, SendConfirmedTimes: 0, QuotaRatio: 10}, nil
 }
+func (db *memoryDatabase) GetContractMetaByAddress(contractAddress types.Address) (*ledger.ContractMeta, error) {
+	return nil, nil
+}
 
 func (db *memoryDatabase) GetStakeBeneficialAmount(addr *types.Address) (*big.Int, error) {
 	return big.NewInt(0), nil
@@ -73,6 +73,11 @@ func (db *testDatabase) GetReceiptHash() *types.Hash {
 }
 func (db *testDatabase) Reset() {
 }
+func (db *testDatabase) SetExecutionError(*ledger.ExecutionError) {
+}
+func (db *testDatabase) GetUnsavedExecutionError() *ledger.ExecutionError {
+	return nil
+}
 func (db *testDatabase) Finish() {
 
 }
@@ -185,6 +190,9 @@ func (db *testDatabase) GetContractMetaInSnapshot(contractAddress types.Address,
 	meta := db.contractMetaMap[contractAddress]
 	return meta, nil
 }
+func (db *testDatabase) GetContractMetaByAddress(contractAddress types.Address) (*ledger.ContractMeta, error) {
+	return db.contractMetaMap[contractAddress], nil
+}
 func (db *testDatabase) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
 	for _, sb := range db.snapshotBlockList {
 		if sb.Height == height {
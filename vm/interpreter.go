@@ -50,6 +50,7 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 		pc   = uint64(0)
 		cost uint64
 		flag bool
+		step *TraceStep
 	)
 
 	for atomic.LoadInt32(&vm.abort) == 0 {
@@ -81,6 +82,7 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 		if err != nil {
 			return nil, err
 		}
+		quotaLeft := c.quotaLeft
 		c.quotaLeft, err = util.UseQuotaWithFlag(c.quotaLeft, cost, flag)
 		if err != nil {
 			return nil, err
@@ -90,8 +92,17 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 			mem.resize(memorySize)
 		}
 
+		if vm.tracer != nil {
+			step = newTraceStep(vm.traceDepth, currentPc, op, quotaLeft, cost, st, mem)
+		}
+
 		res, err := operation.execute(&pc, vm, c, mem, st)
 
+		if step != nil {
+			step.captureResult(op, st, err)
+			vm.tracer.CaptureState(step)
+		}
+
 		if nodeConfig.IsDebug {
 			currentCode := ""
 			if currentPc < uint64(len(c.code)) {
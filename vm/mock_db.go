@@ -28,6 +28,7 @@ type mockDB struct {
 	contractMetaMap        map[types.Address]*ledger.ContractMeta
 	contractMetaMapOrigin  map[types.Address]*ledger.ContractMeta
 	logList                []*ledger.VmLog
+	executionError         *ledger.ExecutionError
 	code                   []byte
 	genesisBlock           *ledger.SnapshotBlock
 }
@@ -152,6 +153,13 @@ func (db *mockDB) Reset() {
 	db.storageMap = make(map[string]string)
 	db.contractMetaMap = make(map[types.Address]*ledger.ContractMeta)
 	db.logList = make([]*ledger.VmLog, 0)
+	db.executionError = nil
+}
+func (db *mockDB) SetExecutionError(err *ledger.ExecutionError) {
+	db.executionError = err
+}
+func (db *mockDB) GetUnsavedExecutionError() *ledger.ExecutionError {
+	return db.executionError
 }
 func (db *mockDB) Finish() {
 
@@ -342,7 +350,7 @@ func (db *mockDB) SetContractMeta(toAddr types.Address, meta *ledger.ContractMet
 	db.contractMetaMap[toAddr] = meta
 }
 func (db *mockDB) GetContractMeta() (*ledger.ContractMeta, error) {
-	if meta := ledger.GetBuiltinContractMeta(*db.currentAddr); meta != nil {
+	if meta := ledger.GetBuiltinContractMeta(*db.currentAddr, db.latestSnapshotBlock.Height); meta != nil {
 		return meta, nil
 	}
 	if meta, ok := db.contractMetaMap[*db.currentAddr]; ok {
@@ -354,7 +362,7 @@ func (db *mockDB) GetContractMeta() (*ledger.ContractMeta, error) {
 	return nil, nil
 }
 func (db *mockDB) GetContractMetaInSnapshot(contractAddress types.Address, snapshotBlock *ledger.SnapshotBlock) (meta *ledger.ContractMeta, err error) {
-	if meta := ledger.GetBuiltinContractMeta(contractAddress); meta != nil {
+	if meta := ledger.GetBuiltinContractMeta(contractAddress, snapshotBlock.Height); meta != nil {
 		return meta, nil
 	}
 	if meta, ok := db.contractMetaMap[contractAddress]; ok {
@@ -365,6 +373,9 @@ func (db *mockDB) GetContractMetaInSnapshot(contractAddress types.Address, snaps
 	}
 	return nil, nil
 }
+func (db *mockDB) GetContractMetaByAddress(contractAddress types.Address) (*ledger.ContractMeta, error) {
+	return db.GetContractMetaInSnapshot(contractAddress, nil)
+}
 func (db *mockDB) getContractMetaMap() map[types.Address]*ledger.ContractMeta {
 	metaMap := make(map[types.Address]*ledger.ContractMeta)
 	for addr, meta := range db.contractMetaMap {
@@ -0,0 +1,97 @@
+package quota
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+// DexForkVersion is the version of the upgrade point since which the quota depends on the congestion of the network
+const DexForkVersion uint32 = 2
+
+// NetworkState is the input of the quota calculation besides the stake amount and the difficulty
+type NetworkState struct {
+	// ForkVersion is the version of the latest active upgrade point, eg: upgrade.GetCurPoint(height).Version
+	ForkVersion uint32
+	// GlobalQuotaUsed is the quota used by all accounts in the latest 75 snapshot blocks
+	GlobalQuotaUsed uint64
+}
+
+// Calculator converts the stake amount and the PoW difficulty to quota and back.
+// It doesn't read the ledger, so it can be used by wallets and exchanges without a node.
+type Calculator struct {
+	cfg quotaConfigParams
+}
+
+// NewCalculator returns a calculator with the mainnet params, or the test network params if testParams is true
+func NewCalculator(testParams bool) *Calculator {
+	return &Calculator{cfg: newQuotaConfigParams(testParams)}
+}
+
+// DefaultCalculator returns the calculator with the params the node is initialized with by InitQuotaConfig
+func DefaultCalculator() *Calculator {
+	return &Calculator{cfg: quotaConfig}
+}
+
+// StateAt returns the state of the network at the snapshot block height, the upgrade box must be initialized
+func StateAt(db quotaDb, sbHeight uint64) NetworkState {
+	state := NetworkState{}
+	if point := upgrade.GetCurPoint(sbHeight); point != nil {
+		state.ForkVersion = point.Version
+	}
+	if state.ForkVersion >= DexForkVersion {
+		state.GlobalQuotaUsed = db.GetGlobalQuota().QuotaUsedTotal
+	}
+	return state
+}
+
+// Qc returns the quota congestion coefficient and whether the network is congested
+func (c *Calculator) Qc(state NetworkState) (*big.Int, bool) {
+	return calcQcByGlobalQuota(&c.cfg, state.ForkVersion >= DexForkVersion, state.GlobalQuotaUsed)
+}
+
+// StakeQuota returns the quota per snapshot block of the stake amount
+func (c *Calculator) StakeQuota(stakeAmount *big.Int, state NetworkState) uint64 {
+	qc, isCongestion := c.Qc(state)
+	return calcStakeQuotaWithParams(&c.cfg, qc, isCongestion, stakeAmount)
+}
+
+// StakeAmountForQuota returns the minimal stake amount to get the quota per snapshot block
+func (c *Calculator) StakeAmountForQuota(q uint64, state NetworkState) (*big.Int, error) {
+	if q > getMaxQuota() {
+		return nil, util.ErrInvalidMethodParam
+	} else if q == 0 {
+		return big.NewInt(0), nil
+	}
+	index := (q + quotaForSection - 1) / quotaForSection
+	amount := new(big.Int).Set(c.cfg.stakeAmountList[index])
+	qc, isCongestion := c.Qc(state)
+	if !isCongestion {
+		return amount, nil
+	}
+	return calcStakeTargetParam(qc, isCongestion, amount)
+}
+
+// PoWQuota returns the one-shot quota of the PoW difficulty
+func (c *Calculator) PoWQuota(difficulty *big.Int, state NetworkState) uint64 {
+	qc, isCongestion := c.Qc(state)
+	return calcPoWQuotaWithParams(&c.cfg, qc, isCongestion, difficulty)
+}
+
+// DifficultyForQuota returns the minimal PoW difficulty to get the one-shot quota
+func (c *Calculator) DifficultyForQuota(q uint64, state NetworkState) (*big.Int, error) {
+	if q > quotaLimitForBlock {
+		return nil, util.ErrBlockQuotaLimitReached
+	}
+	index, err := getIndexByQuota(q)
+	if err != nil {
+		return nil, err
+	}
+	difficulty := new(big.Int).Set(c.cfg.difficultyList[index])
+	qc, isCongestion := c.Qc(state)
+	if !isCongestion {
+		return difficulty, nil
+	}
+	return calcStakeTargetParam(qc, isCongestion, difficulty)
+}
@@ -0,0 +1,57 @@
+package quota
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+func TestCalculator(t *testing.T) {
+	initForkPointsForQuotaTest(t)
+	InitQuotaConfig(false, false)
+	c := NewCalculator(false)
+
+	congested := &testQuotaDb{globalQuota: types.QuotaInfo{QuotaUsedTotal: qcGap * 100}}
+	idle := &testQuotaDb{}
+	for _, height := range []uint64{1, 199, 200, 1000} {
+		for _, db := range []*testQuotaDb{congested, idle} {
+			state := StateAt(db, height)
+			qc, _, isCongestion := CalcQc(db, height)
+			if cqc, cIsCongestion := c.Qc(state); cqc.Cmp(qc) != 0 || cIsCongestion != isCongestion {
+				t.Fatalf("qc mismatch at %d, expected %v %v, got %v %v", height, qc, isCongestion, cqc, cIsCongestion)
+			}
+
+			for _, q := range []uint64{0, 1, 21000, 21001, 280000, getMaxQuota()} {
+				amount, err := c.StakeAmountForQuota(q, state)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := c.StakeQuota(amount, state); got < q {
+					t.Fatalf("stake %v gets %d quota at %d, expected %d at least", amount, got, height, q)
+				}
+				if got := c.StakeQuota(amount, state); got != calcStakeQuota(qc, isCongestion, amount) {
+					t.Fatalf("stake quota mismatch at %d", height)
+				}
+
+				difficulty, err := c.DifficultyForQuota(q, state)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := c.PoWQuota(difficulty, state); got < q {
+					t.Fatalf("difficulty %v gets %d quota at %d, expected %d at least", difficulty, got, height, q)
+				}
+				if got := c.PoWQuota(difficulty, state); got != calcPoWQuotaByQc(db, difficulty, height) {
+					t.Fatalf("pow quota mismatch at %d", height)
+				}
+			}
+		}
+	}
+
+	if _, err := c.DifficultyForQuota(quotaLimitForBlock+1, StateAt(idle, 1000)); err == nil {
+		t.Fatal("the quota over the block limit should fail")
+	}
+	if c.StakeQuota(big.NewInt(0), StateAt(idle, 1000)) != 0 {
+		t.Fatal("no stake no quota")
+	}
+}
@@ -24,37 +24,43 @@ var quotaConfig quotaConfigParams
 // InitQuotaConfig init global status of quota calculation. This method is
 // supposed be called when the node started.
 func InitQuotaConfig(isTest, isTestParam bool) {
+	quotaConfig = newQuotaConfigParams(isTestParam)
+
+	if isTest {
+		quotaConfig.calcQuotaFunc = func(db quotaDb, addr types.Address, stakeAmount *big.Int, difficulty *big.Int, sbHeight uint64) (quotaTotal, quotaStake, quotaAddition, snapshotCurrentQuota, quotaAvg uint64, blocked bool, blockReleaseHeight uint64, err error) {
+			return 75000000, 1000000, 0, 1000000, 0, false, 0, nil
+		}
+	} else {
+		quotaConfig.calcQuotaFunc = func(db quotaDb, addr types.Address, stakeAmount *big.Int, difficulty *big.Int, sbHeight uint64) (quotaTotal, quotaStake, quotaAddition, snapshotCurrentQuota, quotaAvg uint64, blocked bool, blockReleaseHeight uint64, err error) {
+			return calcQuotaV3(db, addr, stakeAmount, difficulty, sbHeight)
+		}
+	}
+}
+
+func newQuotaConfigParams(isTestParam bool) quotaConfigParams {
+	var cfg quotaConfigParams
 	if isTestParam {
-		quotaConfig = quotaConfigParams{
+		cfg = quotaConfigParams{
 			difficultyList: difficultyListTestnet}
 		stakeAmountList := make([]*big.Int, len(stakeAmountListTestnet))
 		for i, str := range stakeAmountListTestnet {
 			stakeAmountList[i], _ = new(big.Int).SetString(str, 10)
 		}
-		quotaConfig.stakeAmountList = stakeAmountList
+		cfg.stakeAmountList = stakeAmountList
 	} else {
-		quotaConfig = quotaConfigParams{
+		cfg = quotaConfigParams{
 			difficultyList: difficultyListMainnet}
 		stakeAmountList := make([]*big.Int, len(stakeAmountListMainnet))
 		for i, str := range stakeAmountListMainnet {
 			stakeAmountList[i], _ = new(big.Int).SetString(str, 10)
 		}
-		quotaConfig.stakeAmountList = stakeAmountList
+		cfg.stakeAmountList = stakeAmountList
 	}
 
-	quotaConfig.qcIndexMin = qcIndexMinMainnet
-	quotaConfig.qcIndexMax = qcIndexMaxMainnet
-	quotaConfig.qcMap = qcMapMainnet
-
-	if isTest {
-		quotaConfig.calcQuotaFunc = func(db quotaDb, addr types.Address, stakeAmount *big.Int, difficulty *big.Int, sbHeight uint64) (quotaTotal, quotaStake, quotaAddition, snapshotCurrentQuota, quotaAvg uint64, blocked bool, blockReleaseHeight uint64, err error) {
-			return 75000000, 1000000, 0, 1000000, 0, false, 0, nil
-		}
-	} else {
-		quotaConfig.calcQuotaFunc = func(db quotaDb, addr types.Address, stakeAmount *big.Int, difficulty *big.Int, sbHeight uint64) (quotaTotal, quotaStake, quotaAddition, snapshotCurrentQuota, quotaAvg uint64, blocked bool, blockReleaseHeight uint64, err error) {
-			return calcQuotaV3(db, addr, stakeAmount, difficulty, sbHeight)
-		}
-	}
+	cfg.qcIndexMin = qcIndexMinMainnet
+	cfg.qcIndexMax = qcIndexMaxMainnet
+	cfg.qcMap = qcMapMainnet
+	return cfg
 }
 
 type quotaDb interface {
@@ -205,11 +211,15 @@ func calcQuotaTotal(db quotaDb, addr types.Address, stakeAmount *big.Int, diffic
 }
 
 func calcStakeQuota(qc *big.Int, isCongestion bool, stakeAmount *big.Int) uint64 {
+	return calcStakeQuotaWithParams(&quotaConfig, qc, isCongestion, stakeAmount)
+}
+
+func calcStakeQuotaWithParams(cfg *quotaConfigParams, qc *big.Int, isCongestion bool, stakeAmount *big.Int) uint64 {
 	if stakeAmount == nil || stakeAmount.Sign() <= 0 {
 		return 0
 	}
 	stakeAmount = calcStakeParam(qc, isCongestion, stakeAmount)
-	return calcQuotaByIndex(getIndexInBigIntList(stakeAmount, quotaConfig.stakeAmountList, 0, sectionLen))
+	return calcQuotaByIndex(getIndexInBigIntList(stakeAmount, cfg.stakeAmountList, 0, sectionLen))
 }
 
 func calcPoWQuotaByQc(db quotaDb, difficulty *big.Int, sbHeight uint64) uint64 {
@@ -221,11 +231,15 @@ func calcPoWQuotaByQc(db quotaDb, difficulty *big.Int, sbHeight uint64) uint64 {
 }
 
 func calcPoWQuota(qc *big.Int, isCongestion bool, difficulty *big.Int) uint64 {
+	return calcPoWQuotaWithParams(&quotaConfig, qc, isCongestion, difficulty)
+}
+
+func calcPoWQuotaWithParams(cfg *quotaConfigParams, qc *big.Int, isCongestion bool, difficulty *big.Int) uint64 {
 	if difficulty == nil || difficulty.Sign() <= 0 {
 		return 0
 	}
 	difficulty = calcStakeParam(qc, isCongestion, difficulty)
-	return calcQuotaByIndex(getIndexInBigIntList(difficulty, quotaConfig.difficultyList, 0, sectionLen))
+	return calcQuotaByIndex(getIndexInBigIntList(difficulty, cfg.difficultyList, 0, sectionLen))
 }
 
 func calcQuotaByIndex(index int) uint64 {
@@ -353,11 +367,19 @@ func CalcQc(db quotaDb, sbHeight uint64) (*big.Int, uint64, bool) {
 		return big.NewInt(0), 0, false
 	}
 	globalQuota := db.GetGlobalQuota().QuotaUsedTotal
+	qc, isCongestion := calcQcByGlobalQuota(&quotaConfig, true, globalQuota)
+	return qc, globalQuota, isCongestion
+}
+
+func calcQcByGlobalQuota(cfg *quotaConfigParams, isDexUpgrade bool, globalQuota uint64) (*big.Int, bool) {
+	if !isDexUpgrade {
+		return big.NewInt(0), false
+	}
 	qcIndex := (globalQuota + qcGap - 1) / qcGap
-	if qcIndex < quotaConfig.qcIndexMin {
-		return qcDivision, globalQuota, false
-	} else if qcIndex >= quotaConfig.qcIndexMax {
-		qcIndex = quotaConfig.qcIndexMax
+	if qcIndex < cfg.qcIndexMin {
+		return qcDivision, false
+	} else if qcIndex >= cfg.qcIndexMax {
+		qcIndex = cfg.qcIndexMax
 	}
-	return quotaConfig.qcMap[qcIndex], globalQuota, true
+	return cfg.qcMap[qcIndex], true
 }
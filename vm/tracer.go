@@ -0,0 +1,188 @@
+package vm
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// Tracer receives the execution of contract code by the interpreter, it is set by VM.SetTracer.
+// Built-in contracts are not executed by the interpreter, so they are not traced.
+type Tracer interface {
+	// CaptureStart is called before the code of addr is executed, depth is 0 for the called contract
+	// and increases by delegate calls
+	CaptureStart(depth int, addr types.Address, code, data []byte, quotaLeft uint64)
+	// CaptureState is called after each instruction is executed
+	CaptureState(step *TraceStep)
+	// CaptureEnd is called when the code halts, reverts or fails
+	CaptureEnd(depth int, ret []byte, quotaLeft uint64, err error)
+}
+
+// StorageAccess is the storage read by SLOAD or written by SSTORE
+type StorageAccess struct {
+	Key   types.Hash `json:"key"`
+	Value types.Hash `json:"value"`
+	Write bool       `json:"write"`
+}
+
+// TraceStep is an executed instruction, Stack and Memory are the states before the instruction
+type TraceStep struct {
+	Depth     int
+	Pc        uint64
+	Op        string
+	QuotaLeft uint64 // before the instruction
+	QuotaCost uint64
+	Stack     []*big.Int
+	Memory    []byte
+	Storage   *StorageAccess
+	Err       error
+}
+
+// newTraceStep captures the state before op is executed
+func newTraceStep(depth int, pc uint64, op opCode, quotaLeft, cost uint64, st *stack, mem *memory) *TraceStep {
+	step := &TraceStep{
+		Depth:     depth,
+		Pc:        pc,
+		Op:        op.String(),
+		QuotaLeft: quotaLeft,
+		QuotaCost: cost,
+		Stack:     make([]*big.Int, len(st.data)),
+		Memory:    mem.get(0, int64(mem.len())),
+	}
+	for i, v := range st.data {
+		step.Stack[i] = new(big.Int).Set(v)
+	}
+	switch op {
+	case SLOAD:
+		step.Storage = &StorageAccess{Key: bigToHash(st.peek())}
+	case SSTORE:
+		step.Storage = &StorageAccess{Key: bigToHash(st.back(0)), Value: bigToHash(st.back(1)), Write: true}
+	}
+	return step
+}
+
+// captureResult captures the state after the instruction is executed
+func (step *TraceStep) captureResult(op opCode, st *stack, err error) {
+	step.Err = err
+	if op == SLOAD && err == nil {
+		step.Storage.Value = bigToHash(st.peek())
+	}
+}
+
+func bigToHash(v *big.Int) types.Hash {
+	hash, _ := types.BigToHash(v)
+	return hash
+}
+
+// StructLog is the json form of a TraceStep
+type StructLog struct {
+	Depth     int            `json:"depth"`
+	Pc        uint64         `json:"pc"`
+	Op        string         `json:"op"`
+	QuotaLeft uint64         `json:"quotaLeft"`
+	QuotaCost uint64         `json:"quotaCost"`
+	Stack     []string       `json:"stack,omitempty"`
+	Memory    string         `json:"memory,omitempty"`
+	Storage   *StorageAccess `json:"storage,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// CallTrace is the execution of a contract code
+type CallTrace struct {
+	Depth     int    `json:"depth"`
+	Address   string `json:"address"`
+	Data      string `json:"data,omitempty"`
+	QuotaLeft uint64 `json:"quotaLeft"`
+	QuotaUsed uint64 `json:"quotaUsed"`
+	Return    string `json:"return,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	quotaStart uint64
+}
+
+// StructLoggerConfig selects what is logged of each step
+type StructLoggerConfig struct {
+	DisableStack   bool `json:"disableStack"`
+	DisableMemory  bool `json:"disableMemory"`
+	DisableStorage bool `json:"disableStorage"`
+	// Limit is the max number of logged steps, 0 means no limit
+	Limit int `json:"limit"`
+}
+
+// StructLogger is a Tracer which keeps the steps in memory
+type StructLogger struct {
+	cfg StructLoggerConfig
+
+	Calls     []*CallTrace `json:"calls"`
+	Logs      []*StructLog `json:"structLogs"`
+	Truncated bool         `json:"truncated,omitempty"`
+
+	open []*CallTrace
+}
+
+// NewStructLogger creates a StructLogger, nil cfg logs everything
+func NewStructLogger(cfg *StructLoggerConfig) *StructLogger {
+	l := &StructLogger{Calls: make([]*CallTrace, 0), Logs: make([]*StructLog, 0)}
+	if cfg != nil {
+		l.cfg = *cfg
+	}
+	return l
+}
+
+func (l *StructLogger) CaptureStart(depth int, addr types.Address, code, data []byte, quotaLeft uint64) {
+	call := &CallTrace{
+		Depth:      depth,
+		Address:    addr.String(),
+		Data:       hex.EncodeToString(data),
+		QuotaLeft:  quotaLeft,
+		quotaStart: quotaLeft,
+	}
+	l.Calls = append(l.Calls, call)
+	l.open = append(l.open, call)
+}
+
+func (l *StructLogger) CaptureState(step *TraceStep) {
+	if l.cfg.Limit > 0 && len(l.Logs) >= l.cfg.Limit {
+		l.Truncated = true
+		return
+	}
+	log := &StructLog{
+		Depth:     step.Depth,
+		Pc:        step.Pc,
+		Op:        step.Op,
+		QuotaLeft: step.QuotaLeft,
+		QuotaCost: step.QuotaCost,
+	}
+	if !l.cfg.DisableStack {
+		log.Stack = make([]string, len(step.Stack))
+		for i, v := range step.Stack {
+			log.Stack[i] = "0x" + v.Text(16)
+		}
+	}
+	if !l.cfg.DisableMemory && len(step.Memory) > 0 {
+		log.Memory = hex.EncodeToString(step.Memory)
+	}
+	if !l.cfg.DisableStorage {
+		log.Storage = step.Storage
+	}
+	if step.Err != nil {
+		log.Error = step.Err.Error()
+	}
+	l.Logs = append(l.Logs, log)
+}
+
+func (l *StructLogger) CaptureEnd(depth int, ret []byte, quotaLeft uint64, err error) {
+	if len(l.open) == 0 {
+		return
+	}
+	call := l.open[len(l.open)-1]
+	l.open = l.open[:len(l.open)-1]
+	if call.quotaStart >= quotaLeft {
+		call.QuotaUsed = call.quotaStart - quotaLeft
+	}
+	call.Return = hex.EncodeToString(ret)
+	if err != nil {
+		call.Error = err.Error()
+	}
+}
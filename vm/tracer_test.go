@@ -0,0 +1,92 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/v2/vm/util"
+)
+
+func TestStructLogger(t *testing.T) {
+	initCustomFork(t)
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, hash12, _, _ := prepareDb(viteTotalSupply)
+
+	// code stores 5 at slot 0 and loads it
+	addr2 := types.Address{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 3, 1}
+	code := []byte{1, byte(PUSH1), 5, byte(PUSH1), 0, byte(SSTORE), byte(PUSH1), 0, byte(SLOAD), byte(STOP)}
+	db.codeMap[addr2] = code
+	db.contractMetaMap[addr2] = &ledger.ContractMeta{Gid: types.DELEGATE_GID, SendConfirmedTimes: 0, QuotaRatio: 10}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.storageMap[types.AddressQuota][ToKey(abi.GetStakeBeneficialKey(addr2))], _ = abi.ABIQuota.PackVariable(abi.VariableNameStakeBeneficial, new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)))
+
+	sendBlock := &ledger.AccountBlock{
+		Height:         3,
+		AccountAddress: addr1,
+		BlockType:      ledger.BlockTypeSendCall,
+		PrevHash:       hash12,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Hash:           types.DataHash([]byte{1, 3}),
+		ToAddress:      addr2,
+	}
+	receiveBlock := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		FromBlockHash:  sendBlock.Hash,
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.DataHash([]byte{2, 1}),
+	}
+
+	logger := NewStructLogger(nil)
+	vm := NewVM(nil)
+	vm.SetTracer(logger)
+	db.addr = addr2
+	result, _, err := vm.RunV2(db, receiveBlock, sendBlock, nil)
+	if err != nil || result == nil {
+		t.Fatalf("receive call failed, err %v", err)
+	}
+
+	if len(logger.Calls) != 1 || logger.Calls[0].Address != addr2.String() || logger.Calls[0].QuotaUsed == 0 {
+		t.Fatalf("unexpected calls %+v", logger.Calls)
+	}
+	ops := []string{"PUSH1", "PUSH1", "SSTORE", "PUSH1", "SLOAD", "STOP"}
+	if len(logger.Logs) != len(ops) {
+		t.Fatalf("expected %d steps, got %d", len(ops), len(logger.Logs))
+	}
+	for i, op := range ops {
+		if logger.Logs[i].Op != op {
+			t.Fatalf("step %d expected %s, got %s", i, op, logger.Logs[i].Op)
+		}
+		if logger.Logs[i].QuotaLeft < logger.Logs[i].QuotaCost {
+			t.Fatalf("step %d quota left %d less than cost %d", i, logger.Logs[i].QuotaLeft, logger.Logs[i].QuotaCost)
+		}
+	}
+	five, _ := types.BigToHash(big.NewInt(5))
+	if store := logger.Logs[2].Storage; store == nil || !store.Write || store.Value != five {
+		t.Fatalf("unexpected sstore %+v", store)
+	}
+	if load := logger.Logs[4].Storage; load == nil || load.Write || load.Value != five {
+		t.Fatalf("unexpected sload %+v", load)
+	}
+	if len(logger.Logs[2].Stack) != 2 {
+		t.Fatalf("unexpected stack of sstore %v", logger.Logs[2].Stack)
+	}
+
+	limited := NewStructLogger(&StructLoggerConfig{DisableStack: true, Limit: 2})
+	vm = NewVM(nil)
+	vm.SetTracer(limited)
+	receiveBlock.Height = 2
+	receiveBlock.PrevHash = receiveBlock.Hash
+	receiveBlock.Hash = types.DataHash([]byte{2, 2})
+	if _, _, err := vm.RunV2(db, receiveBlock, sendBlock, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(limited.Logs) != 2 || !limited.Truncated || limited.Logs[0].Stack != nil {
+		t.Fatalf("unexpected limited logs %+v", limited.Logs)
+	}
+}
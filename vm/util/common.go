@@ -19,6 +19,8 @@ var (
 	CreateContractDataLengthMin = 13
 	// CreateContractDataLengthMinRand defines create contract request block data prefix length after seed fork
 	CreateContractDataLengthMinRand = 14
+	// CreateSaltedContractDataLengthMin defines create contract request block data prefix length with a salt
+	CreateSaltedContractDataLengthMin = CreateContractDataLengthMinRand + saltSize
 )
 
 // IsViteToken checks whether tokenId is vite token
@@ -51,11 +53,15 @@ func MakeRequestBlock(fromAddress types.Address, toAddress types.Address, blockT
 
 var (
 	// SolidityPPContractType defines contract type of solidity++ byte code
-	SolidityPPContractType    uint8 = 1
-	contractTypeSize                = 1
-	snapshotCountSize               = 1
-	snapshotWithSeedCountSize       = 1
-	quotaMultiplierSize             = 1
+	SolidityPPContractType uint8 = 1
+	// SolidityPPSaltedContractType defines contract type of solidity++ byte code created at the address derived from
+	// the salt before the code in data, the code is stored as SolidityPPContractType
+	SolidityPPSaltedContractType uint8 = 2
+	contractTypeSize                   = 1
+	snapshotCountSize                  = 1
+	snapshotWithSeedCountSize          = 1
+	quotaMultiplierSize                = 1
+	saltSize                           = types.HashSize
 )
 
 // GetCreateContractData generate create contract request block data
@@ -74,14 +80,37 @@ func GetContractTypeFromCreateContractData(data []byte) uint8 {
 	return uint8(data[types.GidSize])
 }
 
-// IsExistContractType check contract type validation
-func IsExistContractType(contractType uint8) bool {
+// GetSaltedCreateContractData generate create contract request block data of a contract created at the address derived from the salt
+func GetSaltedCreateContractData(bytecode []byte, snapshotCount uint8, snapshotWithSeedCount uint8, quotaMultiplier uint8, gid types.Gid, salt types.Hash) []byte {
+	return GetCreateContractData(helper.JoinBytes(salt.Bytes(), bytecode), SolidityPPSaltedContractType, snapshotCount, snapshotWithSeedCount, quotaMultiplier, gid)
+}
+
+// IsExistContractType check contract type validation, salted contracts are supported since version x fork
+func IsExistContractType(contractType uint8, snapshotHeight uint64) bool {
 	if contractType == SolidityPPContractType {
 		return true
 	}
+	if contractType == SolidityPPSaltedContractType {
+		return upgrade.IsVersionXUpgrade(snapshotHeight)
+	}
 	return false
 }
 
+// GetCodeTypeFromCreateContractData decode the type of the contract code on chain from create contract request block data
+func GetCodeTypeFromCreateContractData(data []byte) uint8 {
+	if contractType := GetContractTypeFromCreateContractData(data); contractType != SolidityPPSaltedContractType {
+		return contractType
+	}
+	return SolidityPPContractType
+}
+
+// GetSaltFromCreateContractData decode salt from create contract request block data of a salted contract
+func GetSaltFromCreateContractData(data []byte) types.Hash {
+	offset := types.GidSize + contractTypeSize + snapshotCountSize + snapshotWithSeedCountSize + quotaMultiplierSize
+	salt, _ := types.BytesToHash(data[offset : offset+saltSize])
+	return salt
+}
+
 // GetSnapshotCountFromCreateContractData decode snapshot block count(response latency) from create contract request block data
 func GetSnapshotCountFromCreateContractData(data []byte) uint8 {
 	return uint8(data[types.GidSize+contractTypeSize])
@@ -105,6 +134,9 @@ func GetCodeFromCreateContractData(data []byte, snapshotHeight uint64) []byte {
 	if !upgrade.IsSeedUpgrade(snapshotHeight) {
 		return data[types.GidSize+contractTypeSize+snapshotCountSize+quotaMultiplierSize:]
 	}
+	if GetContractTypeFromCreateContractData(data) == SolidityPPSaltedContractType {
+		return data[types.GidSize+contractTypeSize+snapshotCountSize+snapshotWithSeedCountSize+quotaMultiplierSize+saltSize:]
+	}
 	return data[types.GidSize+contractTypeSize+snapshotCountSize+snapshotWithSeedCountSize+quotaMultiplierSize:]
 }
 
@@ -121,6 +153,16 @@ func NewContractAddress(accountAddress types.Address, accountBlockHeight uint64,
 		prevBlockHash.Bytes())
 }
 
+// NewSaltedContractAddress generate contract address by the creator, the salt and the hash of the code and constructor params,
+// so the address is known before the contract is created
+func NewSaltedContractAddress(accountAddress types.Address, salt types.Hash, initCode []byte) types.Address {
+	codeHash := types.DataHash(initCode)
+	return types.CreateContractAddress(
+		accountAddress.Bytes(),
+		salt.Bytes(),
+		codeHash.Bytes())
+}
+
 // PrintMap is used to print storage map under debug mode
 func PrintMap(m map[string][]byte) string {
 	var result string
@@ -243,6 +243,17 @@ type QuotaTable struct {
 	DexFundTransferQuota                      uint64
 	DexFundAgentDepositQuota                  uint64
 	DexFundAssignedWithdrawQuota              uint64
+	NameServiceRegisterQuota                  uint64
+	NameServiceUpdateQuota                    uint64
+	NameServiceTransferOwnershipQuota         uint64
+	BatchTransferQuota                        uint64
+	BatchTransferItemQuota                    uint64
+	RelayRegisterQuota                        uint64
+	RelaySubmitHeadersQuota                   uint64
+	RelayHeadersWordQuota                     uint64
+	RelayVerifyInclusionQuota                 uint64
+	RelayBlsPairingQuota                      uint64
+	RelayBlsPubkeyQuota                       uint64
 }
 
 // QuotaTableByHeight returns different quota table by hard fork version
@@ -537,5 +548,16 @@ func newVersionXQuotaTable() QuotaTable {
 	gt.DexFundTransferQuota = 10500
 	gt.DexFundAgentDepositQuota = 10500
 	gt.DexFundAssignedWithdrawQuota = 10500
+	gt.NameServiceRegisterQuota = 105000
+	gt.NameServiceUpdateQuota = 52500
+	gt.NameServiceTransferOwnershipQuota = 52500
+	gt.BatchTransferQuota = 31500
+	gt.BatchTransferItemQuota = 10500
+	gt.RelayRegisterQuota = 105000
+	gt.RelaySubmitHeadersQuota = 21000
+	gt.RelayHeadersWordQuota = 100
+	gt.RelayVerifyInclusionQuota = 21000
+	gt.RelayBlsPairingQuota = 40000
+	gt.RelayBlsPubkeyQuota = 1500
 	return gt
 }
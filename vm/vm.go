@@ -136,6 +136,9 @@ type VM struct {
 	// latest snapshot block height, used for fork check
 	latestSnapshotHeight uint64
 	gasTable             *util.QuotaTable
+	// tracer receives the executed instructions, nil if not traced
+	tracer     Tracer
+	traceDepth int
 }
 
 // NewVM is a constructor of VM. This method is called before running an
@@ -144,6 +147,11 @@ func NewVM(cr util.ConsensusReader) *VM {
 	return &VM{reader: cr}
 }
 
+// SetTracer sets the tracer of the interpreter, it must be called before an execution.
+func (vm *VM) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
 // GlobalStatus is a getter method.
 func (vm *VM) GlobalStatus() util.GlobalStatus {
 	return vm.globalStatus
@@ -301,7 +309,10 @@ func (vm *VM) sendCreate(db interfaces.VmDb, block *ledger.AccountBlock, useQuot
 		return nil, util.ErrInvalidMethodParam
 	}
 	contractType := util.GetContractTypeFromCreateContractData(block.Data)
-	if !util.IsExistContractType(contractType) {
+	if !util.IsExistContractType(contractType, vm.latestSnapshotHeight) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	if contractType == util.SolidityPPSaltedContractType && len(block.Data) < util.CreateSaltedContractDataLengthMin {
 		return nil, util.ErrInvalidMethodParam
 	}
 	snapshotCount := util.GetSnapshotCountFromCreateContractData(block.Data)
@@ -344,10 +355,24 @@ func (vm *VM) sendCreate(db interfaces.VmDb, block *ledger.AccountBlock, useQuot
 		return nil, util.ErrInsufficientBalance
 	}
 	// Generate a new contract address and set block field.
-	contractAddr := util.NewContractAddress(
-		block.AccountAddress,
-		block.Height,
-		block.PrevHash)
+	var contractAddr types.Address
+	if contractType == util.SolidityPPSaltedContractType {
+		// The address of a salted contract can be created only once, check the contracts not received yet as well.
+		contractAddr = util.NewSaltedContractAddress(
+			block.AccountAddress,
+			util.GetSaltFromCreateContractData(block.Data),
+			util.GetCodeFromCreateContractData(block.Data, vm.latestSnapshotHeight))
+		meta, err := db.GetContractMetaByAddress(contractAddr)
+		util.DealWithErr(err)
+		if meta != nil {
+			return nil, util.ErrAddressCollision
+		}
+	} else {
+		contractAddr = util.NewContractAddress(
+			block.AccountAddress,
+			block.Height,
+			block.PrevHash)
+	}
 	block.ToAddress = contractAddr
 	// Deduct balance and service fee.
 	if !util.SubBalance(db, &block.TokenId, block.Amount) {
@@ -398,7 +423,7 @@ func (vm *VM) receiveCreate(db interfaces.VmDb, block *ledger.AccountBlock, send
 	c.setCallCode(block.AccountAddress, initCode)
 	code, err := c.run(vm)
 	if err == nil && len(code) <= maxCodeSize {
-		code := util.PackContractCode(util.GetContractTypeFromCreateContractData(sendBlock.Data), code)
+		code := util.PackContractCode(util.GetCodeTypeFromCreateContractData(sendBlock.Data), code)
 		codeCost := uint64(len(code)) * vm.gasTable.CodeQuota
 		c.quotaLeft, err = util.UseQuota(c.quotaLeft, codeCost)
 		if err == nil {
@@ -524,6 +549,7 @@ func (vm *VM) receiveCall(db interfaces.VmDb, block *ledger.AccountBlock, sendBl
 	if checkDepth(db, sendBlock) {
 		util.AddBalance(db, &sendBlock.TokenId, sendBlock.Amount)
 		block.Data = getReceiveCallData(db, util.ErrDepth)
+		db.SetExecutionError(newExecutionError(util.ErrDepth, nil))
 		vm.updateBlock(db, block, util.ErrDepth, 0, 0)
 		return &interfaces.VmAccountBlock{block, db}, noRetry, util.ErrDepth
 	}
@@ -556,6 +582,7 @@ func (vm *VM) receiveCall(db interfaces.VmDb, block *ledger.AccountBlock, sendBl
 			}
 		}
 		vm.revert(db)
+		db.SetExecutionError(newExecutionError(err, nil))
 		refundFlag := false
 		refundData, needRefund := p.GetRefundData(sendBlock, vm.latestSnapshotHeight)
 		refundFlag = doRefund(vm, db, block, sendBlock, refundData, needRefund, ledger.BlockTypeSendCall)
@@ -602,7 +629,7 @@ func (vm *VM) receiveCall(db interfaces.VmDb, block *ledger.AccountBlock, sendBl
 	_, code := util.GetContractCode(db, &block.AccountAddress, nil)
 	c := newContract(block, db, sendBlock, sendBlock.Data, quotaLeft)
 	c.setCallCode(block.AccountAddress, code)
-	_, err = c.run(vm)
+	ret, err := c.run(vm)
 	if err == nil {
 		qStakeUsed, qUsed := util.CalcQuotaUsed(true, quotaTotal, quotaAddition, c.quotaLeft, nil)
 		vm.updateBlock(db, block, err, qStakeUsed, qUsed)
@@ -618,6 +645,7 @@ func (vm *VM) receiveCall(db interfaces.VmDb, block *ledger.AccountBlock, sendBl
 	}
 
 	vm.revert(db)
+	db.SetExecutionError(newExecutionError(err, ret))
 
 	if err == util.ErrOutOfQuota {
 		unConfirmedList := db.GetUnconfirmedBlocks(*db.Address())
@@ -763,7 +791,7 @@ func (vm *VM) receiveReward(db interfaces.VmDb, block *ledger.AccountBlock, send
 	quotaLeft := uint64(0)
 	quotaAddition := uint64(0)
 	var err error
-	if !types.IsBuiltinContractAddrInUse(block.AccountAddress) {
+	if !ledger.IsBuiltinContractAddrInUse(block.AccountAddress, vm.latestSnapshotHeight) {
 		quotaTotal, quotaAddition, err = quota.GetQuotaForBlock(
 			db,
 			block.AccountAddress,
@@ -871,6 +899,15 @@ func (vm *VM) revert(db interfaces.VmDb) {
 	db.Reset()
 }
 
+// newExecutionError returns the reason why a receive block failed, ret is kept only if the execution reverted
+func newExecutionError(err error, ret []byte) *ledger.ExecutionError {
+	e := &ledger.ExecutionError{Error: err.Error()}
+	if err == util.ErrExecutionReverted && len(ret) > 0 {
+		e.RevertData = append([]byte(nil), ret...)
+	}
+	return e
+}
+
 // AppendBlock method append a send block to send block list of a contract receive block
 func (context *vmContext) AppendBlock(block *ledger.AccountBlock) {
 	context.sendBlockList = append(context.sendBlockList, block)
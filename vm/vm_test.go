@@ -888,3 +888,63 @@ func testsss(t *testing.T, code []byte) {
 func TestAux(t *testing.T) {
 
 }
+
+func TestSaltedContractCreate(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox().AddPoint(11, 3))
+	defer upgrade.CleanupUpgradeBox(t)
+
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite)
+	db, addr1, _, hash12, _, _ := prepareDb(viteTotalSupply)
+	code, _ := hex.DecodeString("608060405260858060116000396000f300608060405260043610603e5763ffffffff7c0100000000000000000000000000000000000000000000000000000000600035041663f021ab8f81146043575b600080fd5b604c600435604e565b005b6000805490910190555600a165627a7a72305820b8d8d60a46c6ac6569047b17b012aa1ea458271f9bc8078ef0cff9208999d0900029")
+	salt := types.DataHash([]byte("salt"))
+	hash13 := types.DataHash([]byte{1, 3})
+	newBlock := func() *ledger.AccountBlock {
+		return &ledger.AccountBlock{
+			Height:         3,
+			AccountAddress: addr1,
+			BlockType:      ledger.BlockTypeSendCreate,
+			PrevHash:       hash12,
+			Amount:         big.NewInt(0),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			Data:           util.GetSaltedCreateContractData(code, 1, 0, 10, types.DELEGATE_GID, salt),
+			Hash:           hash13,
+		}
+	}
+	db.addr = addr1
+	if _, _, err := NewVM(nil).RunV2(db, newBlock(), nil, nil); err != util.ErrInvalidMethodParam {
+		t.Fatalf("expected salted contract not supported before the fork, got %v", err)
+	}
+
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox().AddPoint(11, 2))
+	sendCreateBlock, _, err := NewVM(nil).RunV2(db, newBlock(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	contractAddr := util.NewSaltedContractAddress(addr1, salt, code)
+	if sendCreateBlock.AccountBlock.ToAddress != contractAddr || db.contractMetaMap[contractAddr] == nil {
+		t.Fatalf("unexpected contract address %v", sendCreateBlock.AccountBlock.ToAddress)
+	}
+	if _, _, err := NewVM(nil).RunV2(db, newBlock(), nil, nil); err != util.ErrAddressCollision {
+		t.Fatalf("expected address collision, got %v", err)
+	}
+
+	db.accountBlockMap[addr1][hash13] = sendCreateBlock.AccountBlock
+	db.storageMap[types.AddressQuota][ToKey(abi.GetStakeBeneficialKey(contractAddr))], _ = abi.ABIQuota.PackVariable(abi.VariableNameStakeBeneficial, new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18)))
+	block21 := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: contractAddr,
+		FromBlockHash:  hash13,
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.DataHash([]byte{2, 1}),
+	}
+	db.addr = contractAddr
+	if _, _, err := NewVM(nil).RunV2(db, block21, sendCreateBlock.AccountBlock, nil); err != nil {
+		t.Fatal(err)
+	}
+	if storedCode := db.codeMap[contractAddr]; len(storedCode) == 0 || storedCode[0] != util.SolidityPPContractType {
+		t.Fatalf("unexpected contract code %v", storedCode)
+	}
+}
@@ -0,0 +1,64 @@
+package vm_db
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// HistoryReader reads the state confirmed by a snapshot block
+type HistoryReader interface {
+	GetSnapshotValue(snapshotHeight uint64, addr types.Address, key []byte) ([]byte, error)
+
+	GetSnapshotStorageIterator(snapshotHeight uint64, addr types.Address, prefix []byte) (interfaces.StorageIterator, error)
+
+	GetConfirmedBalanceList(addrList []types.Address, tokenId types.TokenTypeId, sbHash types.Hash) (map[types.Address]*big.Int, error)
+
+	GetStakeBeneficialAmountInSnapshot(addr types.Address, snapshotHash types.Hash) (*big.Int, error)
+}
+
+// HistoryChain is a Chain which reads the storage, the balances and the stake amounts confirmed by a snapshot block,
+// the other methods read the latest state, e.g. the quota used list and the contract meta.
+type HistoryChain struct {
+	Chain
+	reader   HistoryReader
+	snapshot *core.SnapshotBlock
+}
+
+func NewHistoryChain(chain Chain, reader HistoryReader, snapshot *core.SnapshotBlock) *HistoryChain {
+	return &HistoryChain{
+		Chain:    chain,
+		reader:   reader,
+		snapshot: snapshot,
+	}
+}
+
+// Snapshot returns the snapshot block of the state
+func (hc *HistoryChain) Snapshot() *core.SnapshotBlock {
+	return hc.snapshot
+}
+
+func (hc *HistoryChain) GetValue(addr types.Address, key []byte) ([]byte, error) {
+	return hc.reader.GetSnapshotValue(hc.snapshot.Height, addr, key)
+}
+
+func (hc *HistoryChain) GetStorageIterator(addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	return hc.reader.GetSnapshotStorageIterator(hc.snapshot.Height, addr, prefix)
+}
+
+func (hc *HistoryChain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	balanceMap, err := hc.reader.GetConfirmedBalanceList([]types.Address{addr}, tokenId, hc.snapshot.Hash)
+	if err != nil {
+		return nil, err
+	}
+	if balance := balanceMap[addr]; balance != nil {
+		return balance, nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (hc *HistoryChain) GetStakeBeneficialAmount(addr types.Address) (*big.Int, error) {
+	return hc.reader.GetStakeBeneficialAmountInSnapshot(addr, hc.snapshot.Hash)
+}
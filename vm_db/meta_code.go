@@ -23,13 +23,35 @@ func (vdb *vmDb) GetContractMeta() (*ledger.ContractMeta, error) {
 		}
 	}
 
-	return vdb.chain.GetContractMeta(*vdb.address)
+	return vdb.getContractMeta(*vdb.address)
 }
 
 func (db *vmDb) GetContractMetaInSnapshot(contractAddress types.Address, snapshotBlock *ledger.SnapshotBlock) (meta *ledger.ContractMeta, err error) {
 	return db.chain.GetContractMetaInSnapshot(contractAddress, snapshotBlock.Height)
 }
 
+func (db *vmDb) GetContractMetaByAddress(contractAddress types.Address) (*ledger.ContractMeta, error) {
+	if db.uns != nil {
+		if meta := db.unsaved().GetContractMeta(contractAddress); meta != nil {
+			return meta, nil
+		}
+	}
+	return db.getContractMeta(contractAddress)
+}
+
+// getContractMeta returns the meta of the contract at the latest snapshot block of the context,
+// or at the latest snapshot block of the chain if the vmDb has no context
+func (vdb *vmDb) getContractMeta(contractAddress types.Address) (*ledger.ContractMeta, error) {
+	if vdb.latestSnapshotBlockHash == nil {
+		return vdb.chain.GetContractMeta(contractAddress)
+	}
+	sb, err := vdb.LatestSnapshotBlock()
+	if err != nil {
+		return nil, err
+	}
+	return ledger.GetContractMetaAt(contractAddress, sb.Height, vdb.chain.GetContractMeta)
+}
+
 func (db *vmDb) SetContractCode(code []byte) {
 	db.unsaved().SetCode(code)
 }
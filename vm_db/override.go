@@ -0,0 +1,70 @@
+package vm_db
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+)
+
+// OverrideChain is a Chain whose storage values and balances can be overridden, it is used to simulate
+// blocks on a state which is not on the chain. The storage iterator doesn't read the overridden values.
+type OverrideChain struct {
+	Chain
+
+	storage  map[types.Address]map[string][]byte
+	balances map[types.Address]map[types.TokenTypeId]*big.Int
+}
+
+func NewOverrideChain(chain Chain) *OverrideChain {
+	return &OverrideChain{
+		Chain:    chain,
+		storage:  make(map[types.Address]map[string][]byte),
+		balances: make(map[types.Address]map[types.TokenTypeId]*big.Int),
+	}
+}
+
+// SetValue overrides the storage value of key, an empty value deletes the key
+func (oc *OverrideChain) SetValue(addr types.Address, key []byte, value []byte) {
+	storage, ok := oc.storage[addr]
+	if !ok {
+		storage = make(map[string][]byte)
+		oc.storage[addr] = storage
+	}
+	storage[string(key)] = value
+}
+
+// SetBalance overrides the balance of tokenId
+func (oc *OverrideChain) SetBalance(addr types.Address, tokenId types.TokenTypeId, balance *big.Int) {
+	balances, ok := oc.balances[addr]
+	if !ok {
+		balances = make(map[types.TokenTypeId]*big.Int)
+		oc.balances[addr] = balances
+	}
+	balances[tokenId] = new(big.Int).Set(balance)
+}
+
+// Apply overrides the storage values and the balances changed by an executed block of addr,
+// so that the blocks executed later read them.
+func (oc *OverrideChain) Apply(addr types.Address, db interfaces.VmDb) {
+	for _, kv := range db.GetUnsavedStorage() {
+		oc.SetValue(addr, kv[0], kv[1])
+	}
+	for tokenId, balance := range db.GetUnsavedBalanceMap() {
+		oc.SetBalance(addr, tokenId, balance)
+	}
+}
+
+func (oc *OverrideChain) GetValue(addr types.Address, key []byte) ([]byte, error) {
+	if value, ok := oc.storage[addr][string(key)]; ok {
+		return value, nil
+	}
+	return oc.Chain.GetValue(addr, key)
+}
+
+func (oc *OverrideChain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	if balance, ok := oc.balances[addr][tokenId]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return oc.Chain.GetBalance(addr, tokenId)
+}
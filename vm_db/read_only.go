@@ -0,0 +1,117 @@
+package vm_db
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// SnapshotStateChain is a Chain which keeps the history state of the snapshot blocks, e.g. the chain of the node
+type SnapshotStateChain interface {
+	Chain
+	HistoryReader
+
+	GetLatestSnapshotBlock() *core.SnapshotBlock
+
+	GetSnapshotHeaderByHeight(height uint64) (*core.SnapshotBlock, error)
+
+	GetAccountBlockByHeight(addr types.Address, height uint64) (*core.AccountBlock, error)
+}
+
+// AccountConfirmReader reads the account blocks and the snapshot blocks confirming them
+type AccountConfirmReader interface {
+	GetLatestAccountBlock(addr types.Address) (*core.AccountBlock, error)
+
+	GetAccountBlockByHeight(addr types.Address, height uint64) (*core.AccountBlock, error)
+
+	GetConfirmSnapshotHeaderByAbHash(abHash types.Hash) (*core.SnapshotBlock, error)
+}
+
+// readOnlyVmDb can be executed on, but the result can't be written to the chain
+type readOnlyVmDb struct {
+	*vmDb
+}
+
+func (vdb *readOnlyVmDb) CanWrite() bool {
+	return false
+}
+
+// NewReadOnlyVmDb returns a VmDb of addr which reads the state confirmed by the snapshot block at snapshotHeight,
+// the account state is of the latest account block of addr confirmed by that snapshot block.
+// The changes made by the execution are kept in memory and can't be written to the chain.
+// The state before the height pruned by the chain can't be read.
+func NewReadOnlyVmDb(c SnapshotStateChain, addr types.Address, snapshotHeight uint64) (interfaces.VmDb, error) {
+	latest := c.GetLatestSnapshotBlock()
+	if snapshotHeight > latest.Height {
+		return nil, fmt.Errorf("snapshot height %d is higher than the latest %d", snapshotHeight, latest.Height)
+	}
+	sb, err := c.GetSnapshotHeaderByHeight(snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, fmt.Errorf("snapshot block %d not found", snapshotHeight)
+	}
+	prev, err := GetConfirmedAccountBlock(c, addr, sb)
+	if err != nil {
+		return nil, err
+	}
+	prevHash := types.Hash{}
+	if prev != nil {
+		prevHash = prev.Hash
+	}
+	db, err := NewVmDb(NewHistoryChain(c, c, sb), &addr, &sb.Hash, &prevHash)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyVmDb{vmDb: db}, nil
+}
+
+// GetConfirmedAccountBlock returns the latest account block of addr confirmed by the snapshot block,
+// nil if there's no such block.
+// The heights of the snapshot blocks confirming the account blocks don't decrease with the account heights,
+// and the unconfirmed blocks are at the top, so the block is searched by the account height.
+func GetConfirmedAccountBlock(c AccountConfirmReader, addr types.Address, snapshot *core.SnapshotBlock) (*core.AccountBlock, error) {
+	latest, err := c.GetLatestAccountBlock(addr)
+	if err != nil || latest == nil {
+		return nil, err
+	}
+	confirmed := func(block *core.AccountBlock) (bool, error) {
+		confirmSb, err := c.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+		if err != nil {
+			return false, err
+		}
+		return confirmSb != nil && confirmSb.Height <= snapshot.Height, nil
+	}
+	if ok, err := confirmed(latest); err != nil {
+		return nil, err
+	} else if ok {
+		return latest, nil
+	}
+
+	// the block at low is confirmed, low 0 is the virtual block before the first one, the block at high is not
+	var block *core.AccountBlock
+	low, high := uint64(0), latest.Height
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		midBlock, err := c.GetAccountBlockByHeight(addr, mid)
+		if err != nil {
+			return nil, err
+		}
+		if midBlock == nil {
+			return nil, fmt.Errorf("account block %s %d not found", addr, mid)
+		}
+		ok, err := confirmed(midBlock)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			low, block = mid, midBlock
+		} else {
+			high = mid
+		}
+	}
+	return block, nil
+}
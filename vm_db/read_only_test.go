@@ -0,0 +1,181 @@
+package vm_db
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// historyMockChain has the account blocks of addr, the block at height h is confirmed by the snapshot block at
+// confirmHeight(h), the blocks above confirmedBlocks are unconfirmed
+type historyMockChain struct {
+	Chain
+
+	addr            types.Address
+	blocks          []*core.AccountBlock
+	snapshots       []*core.SnapshotBlock
+	confirmedBlocks uint64
+
+	heightReads int
+}
+
+func confirmHeight(accountHeight uint64) uint64 {
+	return 10 + accountHeight/2
+}
+
+func newHistoryMockChain(accountBlocks, confirmedBlocks uint64) *historyMockChain {
+	c := &historyMockChain{addr: types.AddressGovernance, confirmedBlocks: confirmedBlocks}
+	var prev types.Hash
+	for h := uint64(1); h <= accountBlocks; h++ {
+		block := &core.AccountBlock{AccountAddress: c.addr, Height: h, PrevHash: prev, Hash: types.DataHash([]byte(fmt.Sprintf("block %d", h)))}
+		c.blocks = append(c.blocks, block)
+		prev = block.Hash
+	}
+	for h := uint64(1); h <= confirmHeight(confirmedBlocks)+10; h++ {
+		c.snapshots = append(c.snapshots, &core.SnapshotBlock{Height: h, Hash: types.DataHash([]byte(fmt.Sprintf("snapshot %d", h)))})
+	}
+	return c
+}
+
+func (c *historyMockChain) GetLatestSnapshotBlock() *core.SnapshotBlock {
+	return c.snapshots[len(c.snapshots)-1]
+}
+
+func (c *historyMockChain) GetSnapshotHeaderByHeight(height uint64) (*core.SnapshotBlock, error) {
+	if height == 0 || height > uint64(len(c.snapshots)) {
+		return nil, nil
+	}
+	return c.snapshots[height-1], nil
+}
+
+func (c *historyMockChain) GetLatestAccountBlock(addr types.Address) (*core.AccountBlock, error) {
+	if addr != c.addr || len(c.blocks) == 0 {
+		return nil, nil
+	}
+	return c.blocks[len(c.blocks)-1], nil
+}
+
+func (c *historyMockChain) GetAccountBlockByHeight(addr types.Address, height uint64) (*core.AccountBlock, error) {
+	c.heightReads++
+	if addr != c.addr || height == 0 || height > uint64(len(c.blocks)) {
+		return nil, nil
+	}
+	return c.blocks[height-1], nil
+}
+
+func (c *historyMockChain) GetAccountBlockByHash(hash types.Hash) (*core.AccountBlock, error) {
+	for _, block := range c.blocks {
+		if block.Hash == hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *historyMockChain) GetConfirmSnapshotHeaderByAbHash(hash types.Hash) (*core.SnapshotBlock, error) {
+	block, _ := c.GetAccountBlockByHash(hash)
+	if block == nil || block.Height > c.confirmedBlocks {
+		return nil, nil
+	}
+	return c.GetSnapshotHeaderByHeight(confirmHeight(block.Height))
+}
+
+// the value of every key at a snapshot height is the height
+func (c *historyMockChain) GetSnapshotValue(snapshotHeight uint64, addr types.Address, key []byte) ([]byte, error) {
+	return new(big.Int).SetUint64(snapshotHeight).Bytes(), nil
+}
+
+func (c *historyMockChain) GetSnapshotStorageIterator(snapshotHeight uint64, addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	return nil, nil
+}
+
+func (c *historyMockChain) GetConfirmedBalanceList(addrList []types.Address, tokenId types.TokenTypeId, sbHash types.Hash) (map[types.Address]*big.Int, error) {
+	return nil, nil
+}
+
+func (c *historyMockChain) GetStakeBeneficialAmountInSnapshot(addr types.Address, snapshotHash types.Hash) (*big.Int, error) {
+	return nil, nil
+}
+
+func TestGetConfirmedAccountBlock(t *testing.T) {
+	// far more blocks after the snapshot block than a walk back would read
+	c := newHistoryMockChain(5000, 4990)
+
+	cases := []struct {
+		snapshotHeight uint64
+		expected       uint64 // the height of the expected block, 0 means nil
+	}{
+		{1, 0},
+		{9, 0},
+		{10, 1},
+		{11, 3},
+		{500, 981},
+		{confirmHeight(4990), 4990},
+		{c.GetLatestSnapshotBlock().Height, 4990},
+	}
+	for _, tc := range cases {
+		c.heightReads = 0
+		sb, _ := c.GetSnapshotHeaderByHeight(tc.snapshotHeight)
+		block, err := GetConfirmedAccountBlock(c, c.addr, sb)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		if tc.expected == 0 {
+			assert.Nil(t, block, "snapshot height %d", tc.snapshotHeight)
+		} else if assert.NotNil(t, block, "snapshot height %d", tc.snapshotHeight) {
+			assert.Equal(t, tc.expected, block.Height, "snapshot height %d", tc.snapshotHeight)
+		}
+		assert.True(t, c.heightReads <= 13, "%d blocks are read", c.heightReads)
+	}
+
+	// all blocks are confirmed
+	c = newHistoryMockChain(100, 100)
+	block, err := GetConfirmedAccountBlock(c, c.addr, c.GetLatestSnapshotBlock())
+	assert.NoError(t, err)
+	assert.Equal(t, c.blocks[99], block)
+	assert.Equal(t, 0, c.heightReads)
+
+	// no blocks
+	block, err = GetConfirmedAccountBlock(c, types.AddressQuota, c.GetLatestSnapshotBlock())
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+}
+
+func TestNewReadOnlyVmDb(t *testing.T) {
+	c := newHistoryMockChain(3000, 2990)
+
+	db, err := NewReadOnlyVmDb(c, c.addr, 500)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, db.CanWrite())
+	assert.Equal(t, c.snapshots[499].Hash, *db.(*readOnlyVmDb).latestSnapshotBlockHash)
+	assert.Equal(t, c.blocks[980].Hash, *db.(*readOnlyVmDb).prevAccountBlockHash)
+	prev, err := db.PrevAccountBlock()
+	assert.NoError(t, err)
+	assert.Equal(t, c.blocks[980], prev)
+
+	// the storage is read at the snapshot height, the changes are kept in memory
+	value, err := db.GetValue([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(500), new(big.Int).SetBytes(value).Uint64())
+	assert.NoError(t, db.SetValue([]byte("key"), []byte{1}))
+	value, err = db.GetValue([]byte("key"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{1}, value)
+
+	// before the first block is confirmed
+	db, err = NewReadOnlyVmDb(c, c.addr, 5)
+	if assert.NoError(t, err) {
+		assert.True(t, db.(*readOnlyVmDb).prevAccountBlockHash.IsZero())
+	}
+
+	_, err = NewReadOnlyVmDb(c, c.addr, c.GetLatestSnapshotBlock().Height+1)
+	assert.Error(t, err)
+}
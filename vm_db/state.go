@@ -3,6 +3,7 @@ package vm_db
 import (
 	"github.com/vitelabs/go-vite/v2/common/types"
 	"github.com/vitelabs/go-vite/v2/crypto"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
 )
 
 func (vdb *vmDb) GetReceiptHash() *types.Hash {
@@ -31,6 +32,14 @@ func (vdb *vmDb) Reset() {
 	vdb.unsaved().Reset()
 }
 
+func (vdb *vmDb) SetExecutionError(err *ledger.ExecutionError) {
+	vdb.unsaved().SetExecutionError(err)
+}
+
+func (vdb *vmDb) GetUnsavedExecutionError() *ledger.ExecutionError {
+	return vdb.unsaved().GetExecutionError()
+}
+
 func (vdb *vmDb) Finish() {
 	vdb.unsaved().ReleaseRuntime()
 }
@@ -28,6 +28,8 @@ type Unsaved struct {
 
 	balanceMap map[types.TokenTypeId]*big.Int
 
+	executionError *ledger.ExecutionError
+
 	rnd *rand.Rand
 }
 
@@ -59,6 +61,8 @@ func (unsaved *Unsaved) Reset() {
 	unsaved.storageCache = nil
 
 	unsaved.balanceMap = make(map[types.TokenTypeId]*big.Int)
+
+	unsaved.executionError = nil
 }
 
 func (unsaved *Unsaved) GetStorage() [][2][]byte {
@@ -80,6 +84,14 @@ func (unsaved *Unsaved) GetBalanceMap() map[types.TokenTypeId]*big.Int {
 	return unsaved.balanceMap
 }
 
+func (unsaved *Unsaved) SetExecutionError(err *ledger.ExecutionError) {
+	unsaved.executionError = err
+}
+
+func (unsaved *Unsaved) GetExecutionError() *ledger.ExecutionError {
+	return unsaved.executionError
+}
+
 func (unsaved *Unsaved) GetCode() []byte {
 	return unsaved.code
 }
@@ -0,0 +1,177 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// addressBookFilename is the file of the address labels in the keystore dir,
+// it starts with "." so it is not taken as an entropy store file
+const addressBookFilename = ".addressbook.json"
+
+const (
+	maxLabelLength    = 128
+	maxMetadataKeys   = 32
+	maxMetadataLength = 1024
+)
+
+var (
+	ErrAddressLabelNotFound = errors.New("address label not found")
+	ErrAddressLabelTooLarge = errors.New("address label or metadata is too large")
+)
+
+// AddressLabel is the local context of an address kept by the wallet, it is never sent to the chain
+type AddressLabel struct {
+	Address   types.Address     `json:"address"`
+	Label     string            `json:"label"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Timestamp int64             `json:"timestamp"` // the time the label is last updated
+}
+
+func (l *AddressLabel) copy() *AddressLabel {
+	c := *l
+	c.Metadata = copyMetadata(l.Metadata)
+	return &c
+}
+
+func copyMetadata(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	c := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		c[k] = v
+	}
+	return c
+}
+
+func (m *Manager) addressBookFile() string {
+	return filepath.Join(m.config.DataDir, addressBookFilename)
+}
+
+// loadAddressBook reads the address labels, a missing file means none
+func (m *Manager) loadAddressBook() error {
+	m.addressBookMutex.Lock()
+	defer m.addressBookMutex.Unlock()
+
+	m.addressBook = make(map[types.Address]*AddressLabel)
+	data, err := ioutil.ReadFile(m.addressBookFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var list []*AddressLabel
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Wrap(err, "parse "+addressBookFilename)
+	}
+	for _, l := range list {
+		m.addressBook[l.Address] = l
+	}
+	return nil
+}
+
+func (m *Manager) saveAddressBook() error {
+	data, err := json.MarshalIndent(m.listAddressLabels(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.config.DataDir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(m.config.DataDir, addressBookFilename+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), m.addressBookFile())
+}
+
+// SetAddressLabel adds or replaces the label and the metadata of addr, nil metadata keeps the existing one
+func (m *Manager) SetAddressLabel(addr types.Address, label string, metadata map[string]string) error {
+	if len(label) > maxLabelLength || len(metadata) > maxMetadataKeys {
+		return ErrAddressLabelTooLarge
+	}
+	for k, v := range metadata {
+		if len(k)+len(v) > maxMetadataLength {
+			return ErrAddressLabelTooLarge
+		}
+	}
+
+	m.addressBookMutex.Lock()
+	defer m.addressBookMutex.Unlock()
+
+	l, ok := m.addressBook[addr]
+	if !ok {
+		l = &AddressLabel{Address: addr}
+		m.addressBook[addr] = l
+	}
+	l.Label = label
+	if metadata != nil {
+		l.Metadata = copyMetadata(metadata)
+	}
+	l.Timestamp = time.Now().Unix()
+	if err := m.saveAddressBook(); err != nil {
+		return err
+	}
+	m.log.Info("set address label", "address", addr, "label", label)
+	return nil
+}
+
+// GetAddressLabel returns the label of addr, or nil if addr isn't labeled
+func (m *Manager) GetAddressLabel(addr types.Address) *AddressLabel {
+	m.addressBookMutex.RLock()
+	defer m.addressBookMutex.RUnlock()
+	if l, ok := m.addressBook[addr]; ok {
+		return l.copy()
+	}
+	return nil
+}
+
+// DeleteAddressLabel removes the label and the metadata of addr
+func (m *Manager) DeleteAddressLabel(addr types.Address) error {
+	m.addressBookMutex.Lock()
+	defer m.addressBookMutex.Unlock()
+
+	if _, ok := m.addressBook[addr]; !ok {
+		return ErrAddressLabelNotFound
+	}
+	delete(m.addressBook, addr)
+	if err := m.saveAddressBook(); err != nil {
+		return err
+	}
+	m.log.Info("delete address label", "address", addr)
+	return nil
+}
+
+// ListAddressLabels returns all labels ordered by the address
+func (m *Manager) ListAddressLabels() []*AddressLabel {
+	m.addressBookMutex.RLock()
+	defer m.addressBookMutex.RUnlock()
+	return m.listAddressLabels()
+}
+
+func (m *Manager) listAddressLabels() []*AddressLabel {
+	list := make([]*AddressLabel, 0, len(m.addressBook))
+	for _, l := range m.addressBook {
+		list = append(list, l.copy())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Address.String() < list[j].Address.String()
+	})
+	return list
+}
@@ -0,0 +1,58 @@
+package wallet_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+func TestManager_AddressBook(t *testing.T) {
+	dir := tmpDir()
+	defer os.RemoveAll(dir)
+
+	manager := wallet.New(&config.Wallet{DataDir: dir})
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	addr1, _, _ := types.CreateAddress()
+	addr2, _, _ := types.CreateAddress()
+
+	assert.Nil(t, manager.GetAddressLabel(addr1))
+	assert.NoError(t, manager.SetAddressLabel(addr1, "hot wallet", map[string]string{"owner": "ops"}))
+	assert.NoError(t, manager.SetAddressLabel(addr2, "deposit", nil))
+	// nil metadata keeps the existing one
+	assert.NoError(t, manager.SetAddressLabel(addr1, "hot wallet 1", nil))
+	l := manager.GetAddressLabel(addr1)
+	assert.Equal(t, "hot wallet 1", l.Label)
+	assert.Equal(t, map[string]string{"owner": "ops"}, l.Metadata)
+
+	// the returned label is a copy
+	l.Metadata["owner"] = "someone"
+	assert.Equal(t, "ops", manager.GetAddressLabel(addr1).Metadata["owner"])
+
+	assert.Equal(t, wallet.ErrAddressLabelTooLarge, manager.SetAddressLabel(addr1, strings.Repeat("a", 129), nil))
+
+	// the address book file is not an entropy store
+	files, err := manager.ListEntropyFilesInStandardDir()
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	// the labels are kept across restarts
+	restarted := wallet.New(&config.Wallet{DataDir: dir})
+	if err := restarted.Start(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, manager.ListAddressLabels(), restarted.ListAddressLabels())
+	assert.Equal(t, 2, len(restarted.ListAddressLabels()))
+
+	assert.NoError(t, restarted.DeleteAddressLabel(addr1))
+	assert.Equal(t, wallet.ErrAddressLabelNotFound, restarted.DeleteAddressLabel(addr1))
+	assert.Nil(t, restarted.GetAddressLabel(addr1))
+	assert.NotNil(t, restarted.GetAddressLabel(addr2))
+}
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/tyler-smith/go-bip39"
-	"golang.org/x/crypto/scrypt"
 
 	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
@@ -40,6 +39,11 @@ type CryptoStore struct {
 }
 
 func (ks CryptoStore) ExtractSeed(passphrase string) (seed, entropy []byte, err error) {
+	return ks.ExtractSeedWithBip39Passphrase(passphrase, "")
+}
+
+// ExtractSeedWithBip39Passphrase decrypts the entropy by passphrase, and makes the seed with the bip39 passphrase(the 25th word)
+func (ks CryptoStore) ExtractSeedWithBip39Passphrase(passphrase, bip39Passphrase string) (seed, entropy []byte, err error) {
 	entropy, err = ks.ExtractEntropy(passphrase)
 	if err != nil {
 		return nil, nil, err
@@ -50,7 +54,7 @@ func (ks CryptoStore) ExtractSeed(passphrase string) (seed, entropy []byte, err
 		return nil, nil, e
 	}
 
-	return bip39.NewSeed(s, ""), entropy, nil
+	return bip39.NewSeed(s, bip39Passphrase), entropy, nil
 }
 
 func (ks CryptoStore) ExtractEntropy(passphrase string) ([]byte, error) {
@@ -67,8 +71,13 @@ func (ks CryptoStore) ExtractEntropy(passphrase string) ([]byte, error) {
 }
 
 func (ks CryptoStore) StoreEntropy(entropy []byte, primaryAddr types.Address, passphrase string) error {
+	return ks.StoreEntropyWithParams(entropy, primaryAddr, passphrase, nil)
+}
 
-	keyjson, e := EncryptEntropy(entropy, primaryAddr, passphrase)
+// StoreEntropyWithParams encrypts the entropy by the kdf parameters, nil means the standard scrypt parameters
+func (ks CryptoStore) StoreEntropyWithParams(entropy []byte, primaryAddr types.Address, passphrase string, params *KDFParams) error {
+
+	keyjson, e := EncryptEntropyWithParams(entropy, primaryAddr, passphrase, params)
 	if e != nil {
 		return e
 	}
@@ -80,6 +89,41 @@ func (ks CryptoStore) StoreEntropy(entropy []byte, primaryAddr types.Address, pa
 	return nil
 }
 
+// KDFParams returns the kdf parameters of the file
+func (ks CryptoStore) KDFParams() (*KDFParams, error) {
+	keyjson, err := ioutil.ReadFile(ks.EntropyStoreFilename)
+	if err != nil {
+		return nil, err
+	}
+	k, _, _, _, _, err := parseJson(keyjson)
+	if err != nil {
+		return nil, err
+	}
+	params, _, err := kdfParamsOf(&k.Crypto)
+	return params, err
+}
+
+// ReEncrypt decrypts the file by passphrase and encrypts it again by the kdf parameters, the file is replaced atomically
+func (ks CryptoStore) ReEncrypt(passphrase string, params *KDFParams) error {
+	keyjson, err := ioutil.ReadFile(ks.EntropyStoreFilename)
+	if err != nil {
+		return err
+	}
+	k, addr, _, _, _, err := parseJson(keyjson)
+	if err != nil {
+		return err
+	}
+	entropy, err := DecryptEntropy(keyjson, passphrase)
+	if err != nil {
+		return err
+	}
+	newjson, err := encryptEntropy(entropy, *addr, passphrase, params, k.Timestamp)
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(ks.EntropyStoreFilename, newjson)
+}
+
 func parseJson(keyjson []byte) (k *entropyJSON, kAddress *types.Address, cipherData, nonce, salt []byte, err error) {
 	k = new(entropyJSON)
 	// parse and check entropyJSON params
@@ -102,9 +146,6 @@ func parseJson(keyjson []byte) (k *entropyJSON, kAddress *types.Address, cipherD
 	if k.Crypto.CipherName != aesMode {
 		return nil, nil, nil, nil, nil, fmt.Errorf("cipherName  error : %v", k.Crypto.CipherName)
 	}
-	if k.Crypto.KDF != scryptName {
-		return nil, nil, nil, nil, nil, fmt.Errorf("scryptName  error : %v", k.Crypto.KDF)
-	}
 	cipherData, err = hex.DecodeString(k.Crypto.CipherText)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
@@ -114,9 +155,8 @@ func parseJson(keyjson []byte) (k *entropyJSON, kAddress *types.Address, cipherD
 		return nil, nil, nil, nil, nil, err
 	}
 
-	// parse and check  scryptParams params
-	scryptParams := k.Crypto.ScryptParams
-	salt, err = hex.DecodeString(scryptParams.Salt)
+	// parse and check kdf params
+	_, salt, err = kdfParamsOf(&k.Crypto)
 	if err != nil {
 		return nil, nil, nil, nil, nil, err
 	}
@@ -129,10 +169,13 @@ func DecryptEntropy(entropyJson []byte, passphrase string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	scryptParams := k.Crypto.ScryptParams
+	params, _, err := kdfParamsOf(&k.Crypto)
+	if err != nil {
+		return nil, err
+	}
 
 	// begin decrypt
-	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptParams.N, scryptParams.R, scryptParams.P, scryptParams.KeyLen)
+	derivedKey, err := params.deriveKey(passphrase, salt)
 	if err != nil {
 		return nil, err
 	}
@@ -162,11 +205,18 @@ func DecryptEntropy(entropyJson []byte, passphrase string) ([]byte, error) {
 }
 
 func EncryptEntropy(seed []byte, addr types.Address, passphrase string) ([]byte, error) {
-	n := StandardScryptN
-	p := StandardScryptP
-	pwdArray := []byte(passphrase)
+	return EncryptEntropyWithParams(seed, addr, passphrase, nil)
+}
+
+// EncryptEntropyWithParams encrypts the entropy by the kdf parameters, nil means the standard scrypt parameters
+func EncryptEntropyWithParams(seed []byte, addr types.Address, passphrase string, params *KDFParams) ([]byte, error) {
+	return encryptEntropy(seed, addr, passphrase, params, time.Now().UTC().Unix())
+}
+
+func encryptEntropy(seed []byte, addr types.Address, passphrase string, params *KDFParams, timestamp int64) ([]byte, error) {
+	params = params.withDefaults()
 	salt := vcrypto.GetEntropyCSPRNG(32)
-	derivedKey, err := scrypt.Key(pwdArray, salt, n, scryptR, p, scryptKeyLen)
+	derivedKey, err := params.deriveKey(passphrase, salt)
 	if err != nil {
 		return nil, err
 	}
@@ -177,28 +227,19 @@ func EncryptEntropy(seed []byte, addr types.Address, passphrase string) ([]byte,
 		return nil, err
 	}
 
-	ScryptParams := scryptParams{
-		N:      n,
-		R:      scryptR,
-		P:      p,
-		KeyLen: scryptKeyLen,
-		Salt:   hex.EncodeToString(salt),
-	}
-
 	cryptoJSON := cryptoJSON{
-		CipherName:   aesMode,
-		CipherText:   hex.EncodeToString(ciphertext),
-		Nonce:        hex.EncodeToString(nonce),
-		KDF:          scryptName,
-		ScryptParams: ScryptParams,
+		CipherName: aesMode,
+		CipherText: hex.EncodeToString(ciphertext),
+		Nonce:      hex.EncodeToString(nonce),
 	}
+	cryptoJSON.setKDFParams(params, salt)
 
 	encryptedKeyJSON := entropyJSON{
 
 		PrimaryAddress: addr.String(),
 		Crypto:         cryptoJSON,
 		Version:        cryptoStoreVersion,
-		Timestamp:      time.Now().UTC().Unix(),
+		Timestamp:      timestamp,
 	}
 
 	return json.Marshal(encryptedKeyJSON)
@@ -0,0 +1,153 @@
+package entropystore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/tyler-smith/go-bip39"
+
+	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet/hd-bip/derivation"
+)
+
+// MaxDeriveRange is the max number of addresses derived in one call
+const MaxDeriveRange = 5000
+
+// DeriveOptions are the options to derive the keys of a seed, which are used to recover the wallets created by other software
+type DeriveOptions struct {
+	// PathFormat is the derivation path with %d for the index, e.g. "m/44'/666666'/1'/%d'".
+	// Empty means derivation.ViteAccountPathFormat.
+	PathFormat string `json:"pathFormat"`
+
+	// Bip39Passphrase is the optional bip39 passphrase, also called the 25th word
+	Bip39Passphrase string `json:"bip39Passphrase"`
+}
+
+func (opts *DeriveOptions) pathFormat() string {
+	if opts == nil || opts.PathFormat == "" {
+		return derivation.ViteAccountPathFormat
+	}
+	return opts.PathFormat
+}
+
+func (opts *DeriveOptions) bip39Passphrase() string {
+	if opts == nil {
+		return ""
+	}
+	return opts.Bip39Passphrase
+}
+
+// Validate checks the path format
+func (opts *DeriveOptions) Validate() error {
+	return derivation.ValidatePathFormat(opts.pathFormat())
+}
+
+// DerivedAddress is an address derived by index
+type DerivedAddress struct {
+	Index   uint32        `json:"index"`
+	Path    string        `json:"path"`
+	Address types.Address `json:"address"`
+}
+
+// DeriveRangeFromSeed derives the addresses of index in [from, to)
+func DeriveRangeFromSeed(seed []byte, pathFormat string, from, to uint32) ([]*DerivedAddress, error) {
+	if from > to {
+		return nil, errors.New("from > to")
+	}
+	if to-from > MaxDeriveRange {
+		return nil, fmt.Errorf("at most %d addresses in one call", MaxDeriveRange)
+	}
+	result := make([]*DerivedAddress, 0, to-from)
+	for i := from; i < to; i++ {
+		key, err := derivation.DeriveWithPathFormat(pathFormat, i, seed)
+		if err != nil {
+			return nil, err
+		}
+		addr, err := key.Address()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, &DerivedAddress{Index: i, Path: fmt.Sprintf(pathFormat, i), Address: *addr})
+	}
+	return result, nil
+}
+
+// DeriveRangeFromMnemonic derives the addresses of index in [from, to) of the mnemonic without storing it
+func DeriveRangeFromMnemonic(mnemonic string, opts *DeriveOptions, from, to uint32) ([]*DerivedAddress, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, opts.bip39Passphrase())
+	if err != nil {
+		return nil, err
+	}
+	return DeriveRangeFromSeed(seed, opts.pathFormat(), from, to)
+}
+
+// FindAddrFromSeedWithPath is like FindAddrFromSeed, but derives by the path format
+func FindAddrFromSeedWithPath(seed []byte, addr types.Address, maxSearchIndex uint32, pathFormat string) (key *derivation.Key, index uint32, e error) {
+	for i := uint32(0); i < maxSearchIndex; i++ {
+		key, e := derivation.DeriveWithPathFormat(pathFormat, i, seed)
+		if e != nil {
+			return nil, 0, e
+		}
+		genAddr, e := key.Address()
+		if e != nil {
+			return nil, 0, e
+		}
+		if addr == *genAddr {
+			return key, i, nil
+		}
+	}
+	return nil, 0, walleterrors.ErrAddressNotFound
+}
+
+// UnlockWithOptions unlocks the store, the keys are derived by the options until locked or unlocked again
+func (km *Manager) UnlockWithOptions(passphrase string, opts *DeriveOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	seed, entropy, e := km.ks.ExtractSeedWithBip39Passphrase(passphrase, opts.bip39Passphrase())
+	if e != nil {
+		return e
+	}
+	km.unlockedSeed = seed
+	km.unlockedEntropy = entropy
+	km.pathFormat = opts.pathFormat()
+
+	if km.unlockChangedLis != nil {
+		km.unlockChangedLis(UnlockEvent{
+			EntropyStoreFile: km.GetEntropyStoreFile(),
+			PrimaryAddr:      km.primaryAddr,
+			event:            UnLocked})
+	}
+	return nil
+}
+
+// DeriveRange derives the addresses of index in [from, to) by the options of unlock
+func (km *Manager) DeriveRange(from, to uint32) ([]*DerivedAddress, error) {
+	if !km.IsUnlocked() {
+		return nil, walleterrors.ErrLocked
+	}
+	return DeriveRangeFromSeed(km.unlockedSeed, km.getPathFormat(), from, to)
+}
+
+// DeriveRangeWithPassphrase derives the addresses of index in [from, to) by the options, the store is not unlocked
+func (km *Manager) DeriveRangeWithPassphrase(passphrase string, opts *DeriveOptions, from, to uint32) ([]*DerivedAddress, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	seed, _, err := km.ks.ExtractSeedWithBip39Passphrase(passphrase, opts.bip39Passphrase())
+	if err != nil {
+		return nil, err
+	}
+	return DeriveRangeFromSeed(seed, opts.pathFormat(), from, to)
+}
+
+func (km *Manager) getPathFormat() string {
+	if km.pathFormat == "" {
+		return derivation.ViteAccountPathFormat
+	}
+	return km.pathFormat
+}
@@ -0,0 +1,86 @@
+package entropystore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
+)
+
+func TestDeriveRangeFromMnemonic(t *testing.T) {
+	derived, err := entropystore.DeriveRangeFromMnemonic(TestMnemonic, nil, 0, uint32(len(testTuples)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, tuple := range testTuples {
+		assert.Equal(t, uint32(i), derived[i].Index)
+		assert.Equal(t, tuple.path, derived[i].Path)
+		assert.Equal(t, tuple.address, derived[i].Address.String())
+	}
+
+	// the bip39 passphrase derives another wallet
+	withPassphrase, err := entropystore.DeriveRangeFromMnemonic(TestMnemonic, &entropystore.DeriveOptions{Bip39Passphrase: "25th"}, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, derived[0].Address, withPassphrase[0].Address)
+	assert.NotEqual(t, derived[1].Address, withPassphrase[1].Address)
+
+	custom, err := entropystore.DeriveRangeFromMnemonic(TestMnemonic, &entropystore.DeriveOptions{PathFormat: "m/44'/666666'/1'/%d'"}, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(custom))
+	assert.Equal(t, "m/44'/666666'/1'/3'", custom[0].Path)
+
+	for _, format := range []string{"m/44'/666666'/0'", "m/44'/666666'/%d", "m/44'/%d'/%d'", "m/44'/666666'/%s'"} {
+		_, err := entropystore.DeriveRangeFromMnemonic(TestMnemonic, &entropystore.DeriveOptions{PathFormat: format}, 0, 1)
+		assert.Error(t, err, format)
+	}
+	_, err = entropystore.DeriveRangeFromMnemonic(TestMnemonic, nil, 0, entropystore.MaxDeriveRange+1)
+	assert.Error(t, err)
+}
+
+func TestManager_UnlockWithOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entropystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager, err := entropystore.StoreNewEntropy(dir, TestMnemonic, "123456", entropystore.DefaultMaxIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &entropystore.DeriveOptions{PathFormat: "m/44'/666666'/1'/%d'", Bip39Passphrase: "25th"}
+	expected, err := entropystore.DeriveRangeFromMnemonic(TestMnemonic, opts, 0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := manager.UnlockWithOptions("123456", opts); err != nil {
+		t.Fatal(err)
+	}
+	derived, err := manager.DeriveRange(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected, derived)
+	assert.True(t, manager.IsAddrUnlocked(expected[2].Address))
+	_, index, err := manager.FindAddr(expected[2].Address)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(2), index)
+
+	withPassphrase, err := manager.DeriveRangeWithPassphrase("123456", opts, 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, withPassphrase)
+
+	// unlocking without options goes back to the default path
+	if err := manager.Unlock("123456"); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, manager.IsAddrUnlocked(expected[2].Address))
+}
@@ -0,0 +1,188 @@
+package entropystore
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	argon2idName = "argon2id"
+
+	// StandardArgon2Time is the number of passes of Argon2id
+	StandardArgon2Time = 3
+	// StandardArgon2Memory is the memory of Argon2id in KiB, 256MB like the standard scrypt parameters
+	StandardArgon2Memory = 256 * 1024
+	// StandardArgon2Threads is the parallelism of Argon2id
+	StandardArgon2Threads = 4
+
+	// the limits of the parameters accepted from a file, a forged file must not exhaust the memory
+	maxScryptMemory = 4 << 30
+	maxArgon2Memory = 4 * 1024 * 1024
+	maxArgon2Time   = 64
+)
+
+// KDFParams are the key derivation parameters of new or migrated entropy store files
+type KDFParams struct {
+	// KDF is "scrypt" or "argon2id", empty means "scrypt"
+	KDF string `json:"kdf"`
+
+	ScryptN int `json:"scryptN,omitempty"`
+	ScryptR int `json:"scryptR,omitempty"`
+	ScryptP int `json:"scryptP,omitempty"`
+
+	Argon2Time    uint32 `json:"argon2Time,omitempty"`
+	Argon2Memory  uint32 `json:"argon2Memory,omitempty"` // in KiB
+	Argon2Threads uint8  `json:"argon2Threads,omitempty"`
+}
+
+// DefaultKDFParams returns the standard scrypt parameters
+func DefaultKDFParams() *KDFParams {
+	return &KDFParams{KDF: scryptName, ScryptN: StandardScryptN, ScryptR: scryptR, ScryptP: StandardScryptP}
+}
+
+// withDefaults returns a copy of p whose zero parameters are replaced by the standard ones
+func (p *KDFParams) withDefaults() *KDFParams {
+	result := KDFParams{}
+	if p != nil {
+		result = *p
+	}
+	if result.KDF == "" {
+		result.KDF = scryptName
+	}
+	switch result.KDF {
+	case scryptName:
+		if result.ScryptN == 0 {
+			result.ScryptN = StandardScryptN
+		}
+		if result.ScryptR == 0 {
+			result.ScryptR = scryptR
+		}
+		if result.ScryptP == 0 {
+			result.ScryptP = StandardScryptP
+		}
+	case argon2idName:
+		if result.Argon2Time == 0 {
+			result.Argon2Time = StandardArgon2Time
+		}
+		if result.Argon2Memory == 0 {
+			result.Argon2Memory = StandardArgon2Memory
+		}
+		if result.Argon2Threads == 0 {
+			result.Argon2Threads = StandardArgon2Threads
+		}
+	}
+	return &result
+}
+
+// Validate checks the parameters after the defaults are applied
+func (p *KDFParams) Validate() error {
+	return p.withDefaults().validate()
+}
+
+func (p *KDFParams) validate() error {
+	switch p.KDF {
+	case scryptName:
+		if p.ScryptN <= 1 || p.ScryptN&(p.ScryptN-1) != 0 {
+			return errors.New("scrypt N must be a power of 2 greater than 1")
+		}
+		if p.ScryptR <= 0 || p.ScryptP <= 0 || uint64(p.ScryptR)*uint64(p.ScryptP) >= 1<<30 {
+			return errors.New("invalid scrypt r or p")
+		}
+		if uint64(128)*uint64(p.ScryptN)*uint64(p.ScryptR) > maxScryptMemory {
+			return errors.New("scrypt parameters use too much memory")
+		}
+	case argon2idName:
+		if p.Argon2Time == 0 || p.Argon2Time > maxArgon2Time {
+			return fmt.Errorf("argon2 time must be in [1, %d]", maxArgon2Time)
+		}
+		if p.Argon2Threads == 0 {
+			return errors.New("argon2 threads must be positive")
+		}
+		if p.Argon2Memory < 8*uint32(p.Argon2Threads) || p.Argon2Memory > maxArgon2Memory {
+			return fmt.Errorf("argon2 memory must be in [8*threads, %d] KiB", maxArgon2Memory)
+		}
+	default:
+		return fmt.Errorf("unknown kdf %q", p.KDF)
+	}
+	return nil
+}
+
+// deriveKey derives the encryption key of the passphrase
+func (p *KDFParams) deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	switch p.KDF {
+	case scryptName:
+		return scrypt.Key([]byte(passphrase), salt, p.ScryptN, p.ScryptR, p.ScryptP, scryptKeyLen)
+	default:
+		return argon2.IDKey([]byte(passphrase), salt, p.Argon2Time, p.Argon2Memory, p.Argon2Threads, scryptKeyLen), nil
+	}
+}
+
+// kdfParamsOf returns the parameters of a parsed file
+func kdfParamsOf(c *cryptoJSON) (*KDFParams, []byte, error) {
+	switch c.KDF {
+	case scryptName:
+		if c.ScryptParams == nil {
+			return nil, nil, errors.New("scryptparams is required")
+		}
+		// the key is a prefix of the output of scrypt, so a longer keylen derives the same key
+		if c.ScryptParams.KeyLen < scryptKeyLen {
+			return nil, nil, fmt.Errorf("keylen error : %v", c.ScryptParams.KeyLen)
+		}
+		salt, err := hex.DecodeString(c.ScryptParams.Salt)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &KDFParams{KDF: scryptName, ScryptN: c.ScryptParams.N, ScryptR: c.ScryptParams.R, ScryptP: c.ScryptParams.P}, salt, nil
+	case argon2idName:
+		if c.Argon2Params == nil {
+			return nil, nil, errors.New("argon2params is required")
+		}
+		if c.Argon2Params.KeyLen != scryptKeyLen {
+			return nil, nil, fmt.Errorf("keylen error : %v", c.Argon2Params.KeyLen)
+		}
+		salt, err := hex.DecodeString(c.Argon2Params.Salt)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &KDFParams{
+			KDF:           argon2idName,
+			Argon2Time:    c.Argon2Params.Time,
+			Argon2Memory:  c.Argon2Params.Memory,
+			Argon2Threads: c.Argon2Params.Threads,
+		}, salt, nil
+	default:
+		return nil, nil, fmt.Errorf("kdf error : %v", c.KDF)
+	}
+}
+
+// setKDFParams sets the parameters of p and the salt into the file
+func (c *cryptoJSON) setKDFParams(p *KDFParams, salt []byte) {
+	c.KDF = p.KDF
+	c.ScryptParams = nil
+	c.Argon2Params = nil
+	switch p.KDF {
+	case scryptName:
+		c.ScryptParams = &scryptParams{
+			N:      p.ScryptN,
+			R:      p.ScryptR,
+			P:      p.ScryptP,
+			KeyLen: scryptKeyLen,
+			Salt:   hex.EncodeToString(salt),
+		}
+	case argon2idName:
+		c.Argon2Params = &argon2Params{
+			Time:    p.Argon2Time,
+			Memory:  p.Argon2Memory,
+			Threads: p.Argon2Threads,
+			KeyLen:  scryptKeyLen,
+			Salt:    hex.EncodeToString(salt),
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package entropystore_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tyler-smith/go-bip39"
+
+	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
+	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
+)
+
+// small parameters to keep the test fast
+var (
+	testScryptParams = &entropystore.KDFParams{KDF: "scrypt", ScryptN: 1 << 10, ScryptR: 8, ScryptP: 1}
+	testArgon2Params = &entropystore.KDFParams{KDF: "argon2id", Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+)
+
+func TestManager_MigrateKDF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entropystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager, err := entropystore.StoreNewEntropyWithParams(dir, TestMnemonic, "123456", entropystore.DefaultMaxIndex, testScryptParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, err := manager.KDFParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testScryptParams, params)
+
+	assert.Equal(t, walleterrors.ErrDecryptEntropy, manager.MigrateKDF("654321", testArgon2Params))
+	if err := manager.MigrateKDF("123456", testArgon2Params); err != nil {
+		t.Fatal(err)
+	}
+	params, err = manager.KDFParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testArgon2Params, params)
+
+	// the file is still a valid entropy store of the same wallet
+	mayValid, addr, err := entropystore.IsMayValidEntropystoreFile(manager.GetEntropyStoreFile())
+	assert.NoError(t, err)
+	assert.True(t, mayValid)
+	assert.Equal(t, manager.GetPrimaryAddr(), *addr)
+	mnemonic, err := manager.ExtractMnemonic("123456")
+	assert.NoError(t, err)
+	assert.Equal(t, TestMnemonic, mnemonic)
+	_, err = manager.ExtractMnemonic("654321")
+	assert.Equal(t, walleterrors.ErrDecryptEntropy, err)
+}
+
+func TestDecryptEntropy_Formats(t *testing.T) {
+	entropy, _ := bip39.EntropyFromMnemonic(TestMnemonic)
+	addr, _ := entropystore.MnemonicToPrimaryAddr(TestMnemonic)
+
+	for _, params := range []*entropystore.KDFParams{testScryptParams, testArgon2Params} {
+		keyjson, err := entropystore.EncryptEntropyWithParams(entropy, *addr, "123456", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decrypted, err := entropystore.DecryptEntropy(keyjson, "123456")
+		assert.NoError(t, err, params.KDF)
+		assert.Equal(t, entropy, decrypted)
+	}
+
+	// a file of the format before the kdf parameters were configurable
+	legacy := `{"primaryAddress":"` + addr.String() + `","crypto":{"ciphername":"aes-256-gcm","ciphertext":"00","nonce":"00","kdf":"scrypt","scryptparams":{"n":1024,"r":8,"p":1,"keylen":32,"salt":"00"}},"seedstoreversion":1,"timestamp":0}`
+	file := filepath.Join(os.TempDir(), addr.String()+"-legacy")
+	defer os.Remove(file)
+	assert.NoError(t, ioutil.WriteFile(file, []byte(legacy), 0600))
+	params, err := entropystore.CryptoStore{EntropyStoreFilename: file}.KDFParams()
+	assert.NoError(t, err)
+	assert.Equal(t, testScryptParams, params)
+
+	for _, invalid := range []*entropystore.KDFParams{
+		{KDF: "pbkdf2"},
+		{KDF: "scrypt", ScryptN: 1000},
+		{KDF: "argon2id", Argon2Memory: 1 << 30},
+	} {
+		assert.Error(t, invalid.Validate())
+		_, err := entropystore.EncryptEntropyWithParams(entropy, *addr, "123456", invalid)
+		assert.Error(t, err)
+	}
+	assert.NoError(t, (*entropystore.KDFParams)(nil).Validate())
+}
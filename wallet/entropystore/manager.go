@@ -41,6 +41,8 @@ type Manager struct {
 
 	unlockedSeed    []byte
 	unlockedEntropy []byte
+	// pathFormat is the derivation path of the unlocked seed
+	pathFormat string
 
 	unlockChangedLis func(event UnlockEvent)
 
@@ -61,7 +63,7 @@ func (km *Manager) IsAddrUnlocked(addr types.Address) bool {
 	if !km.IsUnlocked() {
 		return false
 	}
-	_, _, e := FindAddrFromSeed(km.unlockedSeed, addr, km.maxSearchIndex)
+	_, _, e := FindAddrFromSeedWithPath(km.unlockedSeed, addr, km.maxSearchIndex, km.getPathFormat())
 	if e != nil {
 		return false
 	}
@@ -98,20 +100,20 @@ func (km *Manager) ListAddress(from, to uint32) ([]types.Address, error) {
 }
 
 func (km *Manager) Unlock(passphrase string) error {
-	seed, entropy, e := km.ks.ExtractSeed(passphrase)
-	if e != nil {
-		return e
-	}
-	km.unlockedSeed = seed
-	km.unlockedEntropy = entropy
+	return km.UnlockWithOptions(passphrase, nil)
+}
 
-	if km.unlockChangedLis != nil {
-		km.unlockChangedLis(UnlockEvent{
-			EntropyStoreFile: km.GetEntropyStoreFile(),
-			PrimaryAddr:      km.primaryAddr,
-			event:            UnLocked})
+// KDFParams returns the kdf parameters of the entropy store file
+func (km *Manager) KDFParams() (*KDFParams, error) {
+	return km.ks.KDFParams()
+}
+
+// MigrateKDF encrypts the entropy store file again by the kdf parameters, the passphrase is not changed
+func (km *Manager) MigrateKDF(passphrase string, params *KDFParams) error {
+	if err := params.Validate(); err != nil {
+		return err
 	}
-	return nil
+	return km.ks.ReEncrypt(passphrase, params)
 }
 
 func (km *Manager) Lock() {
@@ -138,14 +140,14 @@ func (km *Manager) FindAddr(addr types.Address) (key *derivation.Key, index uint
 		return nil, 0, walleterrors.ErrLocked
 	}
 
-	return FindAddrFromSeed(km.unlockedSeed, addr, km.maxSearchIndex)
+	return FindAddrFromSeedWithPath(km.unlockedSeed, addr, km.maxSearchIndex, km.getPathFormat())
 }
 
 func (km *Manager) SignData(a types.Address, data []byte) (signedData, pubkey []byte, err error) {
 	if !km.IsUnlocked() {
 		return nil, nil, walleterrors.ErrLocked
 	}
-	key, _, e := FindAddrFromSeed(km.unlockedSeed, a, km.maxSearchIndex)
+	key, _, e := FindAddrFromSeedWithPath(km.unlockedSeed, a, km.maxSearchIndex, km.getPathFormat())
 	if e != nil {
 		return nil, nil, walleterrors.ErrAddressNotFound
 	}
@@ -153,7 +155,7 @@ func (km *Manager) SignData(a types.Address, data []byte) (signedData, pubkey []
 }
 
 func (km *Manager) GetPrivateKey(a types.Address) (ed25519.PrivateKey, error) {
-	key, _, e := FindAddrFromSeed(km.unlockedSeed, a, km.maxSearchIndex)
+	key, _, e := FindAddrFromSeedWithPath(km.unlockedSeed, a, km.maxSearchIndex, km.getPathFormat())
 	if e != nil {
 		return nil, walleterrors.ErrAddressNotFound
 	}
@@ -187,7 +189,7 @@ func (km *Manager) DeriveForFullPath(path string) (fpath string, key *derivation
 }
 
 func (km *Manager) DeriveForIndexPath(index uint32) (path string, key *derivation.Key, err error) {
-	return km.DeriveForFullPath(fmt.Sprintf(derivation.ViteAccountPathFormat, index))
+	return km.DeriveForFullPath(fmt.Sprintf(km.getPathFormat(), index))
 }
 
 func (km *Manager) DeriveForFullPathWithPassphrase(path, passphrase string) (fpath string, key *derivation.Key, err error) {
@@ -225,6 +227,11 @@ func (km Manager) ExtractMnemonic(passphrase string) (string, error) {
 }
 
 func StoreNewEntropy(storeDir string, mnemonic string, pwd string, maxSearchIndex uint32) (*Manager, error) {
+	return StoreNewEntropyWithParams(storeDir, mnemonic, pwd, maxSearchIndex, nil)
+}
+
+// StoreNewEntropyWithParams is like StoreNewEntropy, the file is encrypted by the kdf parameters
+func StoreNewEntropyWithParams(storeDir string, mnemonic string, pwd string, maxSearchIndex uint32, params *KDFParams) (*Manager, error) {
 	entropy, e := bip39.EntropyFromMnemonic(mnemonic)
 	if e != nil {
 		return nil, e
@@ -234,7 +241,7 @@ func StoreNewEntropy(storeDir string, mnemonic string, pwd string, maxSearchInde
 
 	filename := FullKeyFileName(storeDir, *primaryAddress)
 	ss := CryptoStore{filename}
-	e = ss.StoreEntropy(entropy, *primaryAddress, pwd)
+	e = ss.StoreEntropyWithParams(entropy, *primaryAddress, pwd, params)
 	if e != nil {
 		return nil, e
 	}
@@ -12,11 +12,12 @@ type entropyJSON struct {
 }
 
 type cryptoJSON struct {
-	CipherName   string       `json:"ciphername"`
-	CipherText   string       `json:"ciphertext"`
-	Nonce        string       `json:"nonce"`
-	KDF          string       `json:"kdf"`
-	ScryptParams scryptParams `json:"scryptparams"`
+	CipherName   string        `json:"ciphername"`
+	CipherText   string        `json:"ciphertext"`
+	Nonce        string        `json:"nonce"`
+	KDF          string        `json:"kdf"`
+	ScryptParams *scryptParams `json:"scryptparams,omitempty"`
+	Argon2Params *argon2Params `json:"argon2params,omitempty"`
 }
 
 type scryptParams struct {
@@ -26,3 +27,11 @@ type scryptParams struct {
 	KeyLen int    `json:"keylen"`
 	Salt   string `json:"salt"`
 }
+
+type argon2Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	KeyLen  int    `json:"keylen"`
+	Salt    string `json:"salt"`
+}
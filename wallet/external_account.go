@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/wallet/hardware"
+	"github.com/vitelabs/go-vite/v2/wallet/remote"
+)
+
+// ListLedgerDevices lists the connected Ledger devices
+func (m *Manager) ListLedgerDevices() ([]*hardware.DeviceInfo, error) {
+	return hardware.EnumerateLedgers()
+}
+
+// AddLedgerAccount adds the account of index on the Ledger device of devicePath, empty path means the first device.
+// If address is not nil, it must match the key on device.
+func (m *Manager) AddLedgerAccount(devicePath string, index uint32, address *types.Address) (*hardware.Account, error) {
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+
+	device, err := m.openLedger(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	acct, err := hardware.NewAccount(device, index, address)
+	if err != nil {
+		return nil, err
+	}
+	m.externalAccounts[acct.Address()] = acct
+	m.log.Info("add ledger account", "address", acct.Address(), "index", index, "device", device.Info().Path)
+	return acct, nil
+}
+
+// AddRemoteSigner adds the accounts held by the signer daemon of endpoint, see remote.NewClient.
+// If address is not nil, only the account of address is added.
+func (m *Manager) AddRemoteSigner(endpoint, token string, timeout time.Duration, address *types.Address) ([]*remote.Account, error) {
+	client, err := remote.NewClient(endpoint, token, timeout)
+	if err != nil {
+		return nil, err
+	}
+	var accts []*remote.Account
+	if address != nil {
+		acct, err := client.Account(*address)
+		if err != nil {
+			return nil, err
+		}
+		accts = append(accts, acct)
+	} else {
+		addrs, err := client.Accounts()
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			acct, err := client.Account(addr)
+			if err != nil {
+				return nil, err
+			}
+			accts = append(accts, acct)
+		}
+	}
+
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+	for _, acct := range accts {
+		m.externalAccounts[acct.Address()] = acct
+		m.log.Info("add remote signer account", "address", acct.Address(), "signer", endpoint)
+	}
+	return accts, nil
+}
+
+// RemoveExternalAccount removes the account of address which is added by AddLedgerAccount or AddRemoteSigner
+func (m *Manager) RemoveExternalAccount(address types.Address) {
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+	delete(m.externalAccounts, address)
+}
+
+// ExternalAccount returns the account of address held by a hardware wallet or a remote signer, nil if not added
+func (m *Manager) ExternalAccount(address types.Address) interfaces.Account {
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+	if acct, ok := m.externalAccounts[address]; ok {
+		return acct
+	}
+	return nil
+}
+
+// HardwareAccount returns the hardware account of address, nil if not added
+func (m *Manager) HardwareAccount(address types.Address) *hardware.Account {
+	acct, _ := m.ExternalAccount(address).(*hardware.Account)
+	return acct
+}
+
+// ListExternalAccounts returns the addresses of the hardware and the remote signer accounts
+func (m *Manager) ListExternalAccounts() []types.Address {
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+	addrs := make([]types.Address, 0, len(m.externalAccounts))
+	for addr := range m.externalAccounts {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+func (m *Manager) openLedger(devicePath string) (*hardware.LedgerDevice, error) {
+	if device, ok := m.ledgers[devicePath]; ok {
+		return device, nil
+	}
+	device, err := hardware.OpenLedger(devicePath)
+	if err != nil {
+		return nil, err
+	}
+	m.ledgers[devicePath] = device
+	return device, nil
+}
+
+func (m *Manager) closeExternalAccounts() {
+	m.externalMutex.Lock()
+	defer m.externalMutex.Unlock()
+	for path, device := range m.ledgers {
+		if err := device.Close(); err != nil {
+			m.log.Warn("close ledger failed", "device", path, "err", err)
+		}
+	}
+	m.ledgers = make(map[string]*hardware.LedgerDevice)
+	m.externalAccounts = make(map[types.Address]interfaces.Account)
+}
@@ -0,0 +1,63 @@
+package hardware
+
+import (
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+// Account is an address whose key is held by a Ledger device, it implements interfaces.Account
+type Account struct {
+	device  *LedgerDevice
+	index   uint32
+	address types.Address
+	pub     ed25519.PublicKey
+}
+
+// NewAccount reads the public key of index from the device, and checks it matches address if address is not nil
+func NewAccount(device *LedgerDevice, index uint32, address *types.Address) (*Account, error) {
+	pub, err := device.PublicKey(index, false)
+	if err != nil {
+		return nil, err
+	}
+	addr := types.PubkeyToAddress(pub)
+	if address != nil && *address != addr {
+		return nil, ErrAddressMismatch
+	}
+	return &Account{device: device, index: index, address: addr, pub: pub}, nil
+}
+
+func (acct *Account) Address() types.Address {
+	return acct.address
+}
+
+func (acct *Account) Index() uint32 {
+	return acct.index
+}
+
+func (acct *Account) Device() *LedgerDevice {
+	return acct.device
+}
+
+// VerifyOnDevice displays the address on device for the user to compare
+func (acct *Account) VerifyOnDevice() error {
+	addr, err := acct.device.Address(acct.index, true)
+	if err != nil {
+		return err
+	}
+	if addr != acct.address {
+		return ErrAddressMismatch
+	}
+	return nil
+}
+
+func (acct *Account) Sign(msg []byte) (signData []byte, pub ed25519.PublicKey, err error) {
+	signData, err = acct.device.SignHash(acct.index, msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signData, acct.pub, nil
+}
+
+func (acct *Account) Verify(pub ed25519.PublicKey, message, signdata []byte) error {
+	return ed25519.VerifySig(pub, message, signdata)
+}
@@ -0,0 +1,125 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	hidPacketSize = 64
+	hidChannel    = 0x0101
+	hidTagAPDU    = 0x05
+)
+
+var (
+	ErrUnsupportedPlatform = errors.New("hid devices are not supported on this platform")
+	ErrDeviceNotFound      = errors.New("hardware wallet device not found")
+	ErrAddressMismatch     = errors.New("address does not match the key on device")
+	errInvalidHIDPacket    = errors.New("invalid hid packet")
+)
+
+// DeviceInfo describes a hid device
+type DeviceInfo struct {
+	Path      string `json:"path"`
+	VendorID  uint16 `json:"vendorId"`
+	ProductID uint16 `json:"productId"`
+	Product   string `json:"product"`
+}
+
+// Transport exchanges apdus with a device
+type Transport interface {
+	Exchange(apdu []byte) ([]byte, error)
+	Close() error
+}
+
+// hidTransport frames apdus into the hid packets of the ledger protocol:
+// channel(2) tag(1) sequence(2) [length(2) in the first packet] data, padded with zeros to 64 bytes.
+type hidTransport struct {
+	rw io.ReadWriteCloser
+
+	// reportID is prepended to every written packet, hidraw requires the report id 0
+	reportID bool
+}
+
+func (t *hidTransport) Exchange(apdu []byte) ([]byte, error) {
+	for _, packet := range wrapAPDU(apdu) {
+		if t.reportID {
+			packet = append([]byte{0}, packet...)
+		}
+		if _, err := t.rw.Write(packet); err != nil {
+			return nil, err
+		}
+	}
+	return unwrapAPDU(func() ([]byte, error) {
+		packet := make([]byte, hidPacketSize)
+		n, err := io.ReadFull(t.rw, packet)
+		return packet[:n], err
+	})
+}
+
+func (t *hidTransport) Close() error {
+	return t.rw.Close()
+}
+
+func wrapAPDU(apdu []byte) [][]byte {
+	data := make([]byte, 2+len(apdu))
+	binary.BigEndian.PutUint16(data, uint16(len(apdu)))
+	copy(data[2:], apdu)
+
+	var packets [][]byte
+	for seq := 0; len(data) > 0; seq++ {
+		packet := make([]byte, hidPacketSize)
+		binary.BigEndian.PutUint16(packet, hidChannel)
+		packet[2] = hidTagAPDU
+		binary.BigEndian.PutUint16(packet[3:], uint16(seq))
+		n := copy(packet[5:], data)
+		data = data[n:]
+		packets = append(packets, packet)
+	}
+	return packets
+}
+
+func unwrapAPDU(read func() ([]byte, error)) ([]byte, error) {
+	var result []byte
+	total := -1
+	for seq := 0; total < 0 || len(result) < total; seq++ {
+		packet, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if len(packet) < 5 ||
+			binary.BigEndian.Uint16(packet) != hidChannel ||
+			packet[2] != hidTagAPDU ||
+			binary.BigEndian.Uint16(packet[3:]) != uint16(seq) {
+			return nil, errInvalidHIDPacket
+		}
+		data := packet[5:]
+		if seq == 0 {
+			if len(data) < 2 {
+				return nil, errInvalidHIDPacket
+			}
+			total = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		}
+		result = append(result, data...)
+	}
+	return result[:total], nil
+}
+
+// hidError is returned if the status word of a response is not 0x9000
+type hidError struct {
+	sw uint16
+}
+
+func (e hidError) Error() string {
+	switch e.sw {
+	case 0x6985:
+		return "request is rejected on device"
+	case 0x6d00, 0x6e00:
+		return "vite app is not open on device"
+	default:
+		return fmt.Sprintf("device returned status 0x%04x", e.sw)
+	}
+}
@@ -0,0 +1,82 @@
+//go:build linux
+// +build linux
+
+package hardware
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const sysHidraw = "/sys/class/hidraw"
+
+// Enumerate lists the hidraw devices of the vendor
+func Enumerate(vendorID uint16) ([]*DeviceInfo, error) {
+	entries, err := ioutil.ReadDir(sysHidraw)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result []*DeviceInfo
+	for _, entry := range entries {
+		info, err := readUevent(filepath.Join(sysHidraw, entry.Name(), "device", "uevent"))
+		if err != nil || info.VendorID != vendorID {
+			continue
+		}
+		info.Path = filepath.Join("/dev", entry.Name())
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// readUevent parses HID_ID=0003:00002C97:00001015 and HID_NAME of the uevent file
+func readUevent(path string) (*DeviceInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info := &DeviceInfo{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		kv := strings.SplitN(scanner.Text(), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "HID_ID":
+			ids := strings.Split(kv[1], ":")
+			if len(ids) != 3 {
+				return nil, ErrDeviceNotFound
+			}
+			vendor, err := strconv.ParseUint(ids[1], 16, 16)
+			if err != nil {
+				return nil, err
+			}
+			product, err := strconv.ParseUint(ids[2], 16, 16)
+			if err != nil {
+				return nil, err
+			}
+			info.VendorID, info.ProductID = uint16(vendor), uint16(product)
+		case "HID_NAME":
+			info.Product = kv[1]
+		}
+	}
+	return info, scanner.Err()
+}
+
+// OpenTransport opens the hidraw device of the path
+func OpenTransport(path string) (Transport, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &hidTransport{rw: f, reportID: true}, nil
+}
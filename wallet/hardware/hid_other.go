@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package hardware
+
+// Enumerate lists the hid devices of the vendor
+func Enumerate(vendorID uint16) ([]*DeviceInfo, error) {
+	return nil, ErrUnsupportedPlatform
+}
+
+// OpenTransport opens the hid device of the path
+func OpenTransport(path string) (Transport, error) {
+	return nil, ErrUnsupportedPlatform
+}
@@ -0,0 +1,151 @@
+package hardware
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+// LedgerVendorID is the usb vendor id of Ledger devices
+const LedgerVendorID = 0x2c97
+
+// apdus of the Vite app, the key of index is derived by m/44'/666666'/index' on device
+const (
+	ledgerCLA = 0xe0
+
+	insGetVersion   = 0x01
+	insGetPublicKey = 0x02 // data: index(4), response: public key(32)
+	insSignHash     = 0x04 // data: index(4) hash(32), response: signature(64)
+
+	p1NoConfirm = 0x00
+	p1Confirm   = 0x01 // display the address and wait for the approval on device
+
+	swOK = 0x9000
+)
+
+var errInvalidResponse = errors.New("invalid response of device")
+
+// LedgerDevice signs by the Vite app of a Ledger device
+type LedgerDevice struct {
+	info      *DeviceInfo
+	transport Transport
+
+	// a device handles one request at a time
+	mu sync.Mutex
+}
+
+// EnumerateLedgers lists the connected Ledger devices
+func EnumerateLedgers() ([]*DeviceInfo, error) {
+	return Enumerate(LedgerVendorID)
+}
+
+// OpenLedger opens the Ledger device of the path, the first device is opened if path is empty
+func OpenLedger(path string) (*LedgerDevice, error) {
+	infos, err := EnumerateLedgers()
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		if path != "" && info.Path != path {
+			continue
+		}
+		transport, err := OpenTransport(info.Path)
+		if err != nil {
+			return nil, err
+		}
+		return NewLedgerDevice(info, transport), nil
+	}
+	return nil, ErrDeviceNotFound
+}
+
+func NewLedgerDevice(info *DeviceInfo, transport Transport) *LedgerDevice {
+	return &LedgerDevice{info: info, transport: transport}
+}
+
+func (d *LedgerDevice) Info() *DeviceInfo {
+	return d.info
+}
+
+func (d *LedgerDevice) Close() error {
+	return d.transport.Close()
+}
+
+func (d *LedgerDevice) exchange(ins, p1 byte, data []byte) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	apdu := append([]byte{ledgerCLA, ins, p1, 0x00, byte(len(data))}, data...)
+	resp, err := d.transport.Exchange(apdu)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < 2 {
+		return nil, errInvalidResponse
+	}
+	if sw := binary.BigEndian.Uint16(resp[len(resp)-2:]); sw != swOK {
+		return nil, hidError{sw: sw}
+	}
+	return resp[:len(resp)-2], nil
+}
+
+// Version returns the version of the Vite app
+func (d *LedgerDevice) Version() (string, error) {
+	resp, err := d.exchange(insGetVersion, p1NoConfirm, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(resp) < 3 {
+		return "", errInvalidResponse
+	}
+	return fmt.Sprintf("%d.%d.%d", resp[0], resp[1], resp[2]), nil
+}
+
+// PublicKey returns the public key of index, the address is displayed on device for verification if confirm is true
+func (d *LedgerDevice) PublicKey(index uint32, confirm bool) (ed25519.PublicKey, error) {
+	p1 := byte(p1NoConfirm)
+	if confirm {
+		p1 = p1Confirm
+	}
+	resp, err := d.exchange(insGetPublicKey, p1, indexBytes(index))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) != ed25519.PublicKeySize {
+		return nil, errInvalidResponse
+	}
+	return ed25519.PublicKey(resp), nil
+}
+
+// Address returns the address of index, see PublicKey
+func (d *LedgerDevice) Address(index uint32, confirm bool) (types.Address, error) {
+	pub, err := d.PublicKey(index, confirm)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return types.PubkeyToAddress(pub), nil
+}
+
+// SignHash signs the hash of an account block or a snapshot block by the key of index,
+// the hash is displayed on device and signed after approval.
+func (d *LedgerDevice) SignHash(index uint32, hash []byte) ([]byte, error) {
+	if len(hash) != types.HashSize {
+		return nil, fmt.Errorf("hash must be %d bytes", types.HashSize)
+	}
+	resp, err := d.exchange(insSignHash, p1NoConfirm, append(indexBytes(index), hash...))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) != ed25519.SignatureSize {
+		return nil, errInvalidResponse
+	}
+	return resp, nil
+}
+
+func indexBytes(index uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, index)
+	return b
+}
@@ -0,0 +1,117 @@
+package hardware
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+)
+
+func TestHIDFraming(t *testing.T) {
+	apdu := make([]byte, 200)
+	_, _ = rand.Read(apdu)
+
+	packets := wrapAPDU(apdu)
+	if len(packets) != 4 {
+		t.Fatalf("expected 4 packets, got %d", len(packets))
+	}
+	for _, packet := range packets {
+		if len(packet) != hidPacketSize {
+			t.Fatalf("packet size %d", len(packet))
+		}
+	}
+
+	i := 0
+	result, err := unwrapAPDU(func() ([]byte, error) {
+		i++
+		return packets[i-1], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, apdu) {
+		t.Fatal("apdu mismatch")
+	}
+
+	packets[1][4] = 5
+	i = 0
+	if _, err = unwrapAPDU(func() ([]byte, error) {
+		i++
+		return packets[i-1], nil
+	}); err != errInvalidHIDPacket {
+		t.Fatalf("expected invalid packet, got %v", err)
+	}
+}
+
+// fakeApp emulates the Vite app with keys of index
+type fakeApp struct {
+	keys   map[uint32]ed25519.PrivateKey
+	reject bool
+}
+
+func (app *fakeApp) Exchange(apdu []byte) ([]byte, error) {
+	ok := []byte{0x90, 0x00}
+	data := apdu[5:]
+	switch apdu[1] {
+	case insGetVersion:
+		return append([]byte{1, 2, 3}, ok...), nil
+	case insGetPublicKey:
+		priv := app.keys[binary.BigEndian.Uint32(data)]
+		return append(priv.PubByte(), ok...), nil
+	case insSignHash:
+		if app.reject {
+			return []byte{0x69, 0x85}, nil
+		}
+		priv := app.keys[binary.BigEndian.Uint32(data)]
+		return append(ed25519.Sign(priv, data[4:]), ok...), nil
+	}
+	return []byte{0x6d, 0x00}, nil
+}
+
+func (app *fakeApp) Close() error {
+	return nil
+}
+
+func TestLedgerAccount(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &fakeApp{keys: map[uint32]ed25519.PrivateKey{3: priv}}
+	device := NewLedgerDevice(&DeviceInfo{Path: "fake"}, app)
+
+	version, err := device.Version()
+	if err != nil || version != "1.2.3" {
+		t.Fatalf("version %s, err %v", version, err)
+	}
+
+	addr := types.PrikeyToAddress(priv)
+	other := types.AddressGovernance
+	if _, err = NewAccount(device, 3, &other); err != ErrAddressMismatch {
+		t.Fatalf("expected address mismatch, got %v", err)
+	}
+	acct, err := NewAccount(device, 3, &addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = acct.VerifyOnDevice(); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := types.DataHash([]byte("block"))
+	sig, pub, err := acct.Sign(hash.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = acct.Verify(pub, hash.Bytes(), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	app.reject = true
+	if _, _, err = acct.Sign(hash.Bytes()); err == nil {
+		t.Fatal("expected rejected on device")
+	}
+}
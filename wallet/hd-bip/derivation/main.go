@@ -91,6 +91,22 @@ func DeriveWithIndex(i uint32, seed []byte) (*Key, error) {
 	return DeriveForPath(path, seed)
 }
 
+// DeriveWithPathFormat derives key for the path of format with index i, e.g. "m/44'/666666'/%d'"
+func DeriveWithPathFormat(format string, i uint32, seed []byte) (*Key, error) {
+	return DeriveForPath(fmt.Sprintf(format, i), seed)
+}
+
+// ValidatePathFormat checks the format has exactly one %d as a hardened segment, and the paths of it are valid
+func ValidatePathFormat(format string) error {
+	if strings.Count(format, "%d") != 1 || strings.Count(format, "%") != 1 || !strings.Contains(format, "/%d'") {
+		return ErrInvalidPath
+	}
+	if !isValidPath(fmt.Sprintf(format, 0)) {
+		return ErrInvalidPath
+	}
+	return nil
+}
+
 func GetPrimaryAddress(seed []byte) (*types.Address, error) {
 	key, e := DeriveWithIndex(0, seed)
 	if e != nil {
@@ -13,8 +13,10 @@ import (
 	"github.com/vitelabs/go-vite/v2/common/config"
 	walleterrors "github.com/vitelabs/go-vite/v2/common/errors"
 	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
 	"github.com/vitelabs/go-vite/v2/log15"
 	"github.com/vitelabs/go-vite/v2/wallet/entropystore"
+	"github.com/vitelabs/go-vite/v2/wallet/hardware"
 	"github.com/vitelabs/go-vite/v2/wallet/hd-bip/derivation"
 )
 
@@ -25,6 +27,19 @@ type Manager struct {
 	unlockChangedLis    map[int]func(event entropystore.UnlockEvent)
 	mutex               sync.Mutex
 
+	// accounts whose keys are held by hardware wallets or remote signers
+	externalAccounts map[types.Address]interfaces.Account
+	ledgers          map[string]*hardware.LedgerDevice // key is the device path
+	externalMutex    sync.Mutex
+
+	// addresses without keys which are monitored, see watch_only.go
+	watchOnly      map[types.Address]*WatchOnlyAddress
+	watchOnlyMutex sync.RWMutex
+
+	// local labels of addresses, see address_book.go
+	addressBook      map[types.Address]*AddressLabel
+	addressBookMutex sync.RWMutex
+
 	log log15.Logger
 }
 
@@ -41,6 +56,10 @@ func New(config *config.Wallet) *Manager {
 		config:              config,
 		unlockChangedLis:    make(map[int]func(event entropystore.UnlockEvent)),
 		entropyStoreManager: make(map[string]*entropystore.Manager),
+		externalAccounts:    make(map[types.Address]interfaces.Account),
+		ledgers:             make(map[string]*hardware.LedgerDevice),
+		watchOnly:           make(map[types.Address]*WatchOnlyAddress),
+		addressBook:         make(map[types.Address]*AddressLabel),
 
 		log: log15.New("module", "wallet"),
 	}
@@ -63,6 +82,16 @@ func (m *Manager) Unlock(entropyStore, passphrase string) error {
 	return manager.Unlock(passphrase)
 }
 
+// UnlockWithOptions unlocks the entropy store with a custom derivation path or a bip39 passphrase
+func (m *Manager) UnlockWithOptions(entropyStore, passphrase string, opts *entropystore.DeriveOptions) error {
+	manager, e := m.GetEntropyStoreManager(entropyStore)
+	if e != nil {
+		return e
+	}
+
+	return manager.UnlockWithOptions(passphrase, opts)
+}
+
 func (m *Manager) IsUnlocked(entropyStore string) bool {
 	manager, e := m.GetEntropyStoreManager(entropyStore)
 	if e != nil {
@@ -98,7 +127,11 @@ func (m *Manager) RefreshCache() {
 	}
 }
 
-func (m Manager) Account(address types.Address) (*Account, error) {
+// Account returns the account of address from the unlocked entropy stores, the hardware wallets and the remote signers
+func (m *Manager) Account(address types.Address) (interfaces.Account, error) {
+	if acct := m.ExternalAccount(address); acct != nil {
+		return acct, nil
+	}
 	for _, em := range m.entropyStoreManager {
 		if em.IsUnlocked() {
 			key, _, err := em.FindAddr(address)
@@ -134,8 +167,11 @@ func (m Manager) AccountAtIndex(entryPath string, target types.Address, index ui
 	return newAccount(target, key)
 }
 
-func (m Manager) AccountSearch(entryPath *string, target types.Address, passphrase string) (*Account, error) {
+func (m *Manager) AccountSearch(entryPath *string, target types.Address, passphrase string) (interfaces.Account, error) {
 	if entryPath == nil {
+		if acct := m.ExternalAccount(target); acct != nil {
+			return acct, nil
+		}
 		_, key, _, err := m.GlobalFindAddrWithPassphrase(target, passphrase)
 		if err != nil {
 			return nil, err
@@ -277,7 +313,7 @@ func (m *Manager) RemoveEntropyStore(entropyStore string) {
 }
 
 func (m *Manager) RecoverEntropyStoreFromMnemonic(mnemonic string, passphrase string) (em *entropystore.Manager, err error) {
-	sm, e := entropystore.StoreNewEntropy(m.config.DataDir, mnemonic, passphrase, entropystore.DefaultMaxIndex)
+	sm, e := entropystore.StoreNewEntropyWithParams(m.config.DataDir, mnemonic, passphrase, entropystore.DefaultMaxIndex, m.KDFParams())
 	if e != nil {
 		return nil, e
 	}
@@ -309,11 +345,40 @@ func (m *Manager) NewMnemonicAndEntropyStore(passphrase string) (mnemonic string
 	return mnemonic, em, nil
 }
 
+// KDFParams returns the kdf parameters of the new entropy store files
+func (m *Manager) KDFParams() *entropystore.KDFParams {
+	return &entropystore.KDFParams{
+		KDF:           m.config.KDF,
+		ScryptN:       m.config.ScryptN,
+		ScryptR:       m.config.ScryptR,
+		ScryptP:       m.config.ScryptP,
+		Argon2Time:    m.config.Argon2Time,
+		Argon2Memory:  m.config.Argon2Memory,
+		Argon2Threads: m.config.Argon2Threads,
+	}
+}
+
+// MigrateEntropyStore encrypts the entropy store file again by params, nil means the kdf parameters of the config
+func (m *Manager) MigrateEntropyStore(entropyStore, passphrase string, params *entropystore.KDFParams) error {
+	manager, e := m.GetEntropyStoreManager(entropyStore)
+	if e != nil {
+		return e
+	}
+	if params == nil {
+		params = m.KDFParams()
+	}
+	return manager.MigrateKDF(passphrase, params)
+}
+
 func (m Manager) GetDataDir() string {
 	return m.config.DataDir
 }
 
 func (m *Manager) Start() error {
+	if e := m.KDFParams().Validate(); e != nil {
+		m.log.Error("wallet start err", "err", e)
+		return e
+	}
 	m.entropyStoreManager = make(map[string]*entropystore.Manager)
 	files, e := m.ListEntropyFilesInStandardDir()
 	if e != nil {
@@ -326,6 +391,14 @@ func (m *Manager) Start() error {
 			return e
 		}
 	}
+	if e = m.loadWatchOnly(); e != nil {
+		m.log.Error("wallet start loadWatchOnly", "err", e)
+		return e
+	}
+	if e = m.loadAddressBook(); e != nil {
+		m.log.Error("wallet start loadAddressBook", "err", e)
+		return e
+	}
 	return nil
 }
 
@@ -335,6 +408,7 @@ func (m *Manager) Stop() {
 		em.RemoveUnlockChangeChannel()
 	}
 	m.entropyStoreManager = nil
+	m.closeExternalAccounts()
 }
 
 func (m Manager) AddLockEventListener(lis func(event entropystore.UnlockEvent)) int {
@@ -0,0 +1,253 @@
+package multisig
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/vm/abi"
+)
+
+// PayloadVersion is the version of the serialized payload
+const PayloadVersion = 1
+
+// jsonMultisig is the method of the multisig contract which executes a call approved by the signers.
+// signatures is the concatenation of public key(32) and signature(64) of each signer.
+const jsonMultisig = `
+[
+	{"type":"function","name":"execute","inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"},{"name":"nonce","type":"uint64"},{"name":"signatures","type":"bytes"}]}
+]`
+
+var (
+	// ABIMultisig is the abi of the multisig contract methods used by the payload
+	ABIMultisig, _ = abi.JSONToABIContract(strings.NewReader(jsonMultisig))
+
+	ErrNotSigner        = errors.New("address is not a signer of the payload")
+	ErrPayloadMismatch  = errors.New("payloads are not of the same call")
+	ErrInvalidSignature = errors.New("invalid signature of the payload")
+	ErrInvalidThreshold = errors.New("threshold must be in [1, number of signers]")
+)
+
+// Call is the call which the multisig contract executes
+type Call struct {
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    *big.Int          `json:"amount"`
+	Data      []byte            `json:"data,omitempty"`
+}
+
+// Signature is a signature of a signer on the payload hash
+type Signature struct {
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+	Signature []byte            `json:"signature"`
+}
+
+// Payload is a call of an account managed by a multisig contract, it is signed by the signers one by one
+// and sent to the contract when at least Threshold signers have signed.
+type Payload struct {
+	Version   int             `json:"version"`
+	Contract  types.Address   `json:"contract"`
+	Nonce     uint64          `json:"nonce"`
+	Threshold int             `json:"threshold"`
+	Signers   []types.Address `json:"signers"`
+	Call      Call            `json:"call"`
+
+	Signatures []*Signature `json:"signatures"`
+}
+
+// NewPayload creates an unsigned payload, nonce is the next nonce of the multisig contract
+func NewPayload(contract types.Address, nonce uint64, threshold int, signers []types.Address, call Call) (*Payload, error) {
+	if threshold < 1 || threshold > len(signers) {
+		return nil, ErrInvalidThreshold
+	}
+	if call.Amount == nil {
+		call.Amount = big.NewInt(0)
+	}
+	if call.Amount.Sign() < 0 {
+		return nil, errors.New("amount must not be negative")
+	}
+	return &Payload{
+		Version:   PayloadVersion,
+		Contract:  contract,
+		Nonce:     nonce,
+		Threshold: threshold,
+		Signers:   append([]types.Address(nil), signers...),
+		Call:      call,
+	}, nil
+}
+
+// Hash is the message signed by the signers, it binds the contract, the nonce and the call
+func (p *Payload) Hash() types.Hash {
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, p.Nonce)
+	hash, _ := types.BytesToHash(crypto.Hash256(
+		[]byte("vite multisig"),
+		p.Contract.Bytes(),
+		nonce,
+		p.Call.ToAddress.Bytes(),
+		p.Call.TokenId.Bytes(),
+		common.LeftPadBytes(p.Call.Amount.Bytes(), 32),
+		crypto.Hash256(p.Call.Data),
+	))
+	return hash
+}
+
+func (p *Payload) isSigner(addr types.Address) bool {
+	for _, signer := range p.Signers {
+		if signer == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// addSignature verifies the signature and adds it, a signature of the same signer is ignored
+func (p *Payload) addSignature(sig *Signature) error {
+	addr := types.PubkeyToAddress(sig.PublicKey)
+	if !p.isSigner(addr) {
+		return ErrNotSigner
+	}
+	hash := p.Hash()
+	if err := ed25519.VerifySig(sig.PublicKey, hash.Bytes(), sig.Signature); err != nil {
+		return ErrInvalidSignature
+	}
+	for _, s := range p.Signatures {
+		if bytes.Equal(s.PublicKey, sig.PublicKey) {
+			return nil
+		}
+	}
+	p.Signatures = append(p.Signatures, sig)
+	sort.Slice(p.Signatures, func(i, j int) bool {
+		return bytes.Compare(p.Signatures[i].PublicKey, p.Signatures[j].PublicKey) < 0
+	})
+	return nil
+}
+
+// Sign adds the signature of acct, acct must be a signer
+func (p *Payload) Sign(acct interfaces.Account) error {
+	if !p.isSigner(acct.Address()) {
+		return ErrNotSigner
+	}
+	hash := p.Hash()
+	signature, pub, err := acct.Sign(hash.Bytes())
+	if err != nil {
+		return err
+	}
+	return p.addSignature(&Signature{PublicKey: pub, Signature: signature})
+}
+
+// Merge adds the signatures of other, which must be a payload of the same call
+func (p *Payload) Merge(other *Payload) error {
+	if p.Hash() != other.Hash() || p.Threshold != other.Threshold || !sameSigners(p.Signers, other.Signers) {
+		return ErrPayloadMismatch
+	}
+	for _, sig := range other.Signatures {
+		if err := p.addSignature(sig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sameSigners(a, b []types.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[types.Address]bool, len(a))
+	for _, addr := range a {
+		set[addr] = true
+	}
+	for _, addr := range b {
+		if !set[addr] {
+			return false
+		}
+	}
+	return true
+}
+
+// Signed returns the signers which have signed
+func (p *Payload) Signed() []types.Address {
+	result := make([]types.Address, 0, len(p.Signatures))
+	for _, sig := range p.Signatures {
+		result = append(result, types.PubkeyToAddress(sig.PublicKey))
+	}
+	return result
+}
+
+// Missing returns the signers which have not signed
+func (p *Payload) Missing() []types.Address {
+	signed := make(map[types.Address]bool, len(p.Signatures))
+	for _, addr := range p.Signed() {
+		signed[addr] = true
+	}
+	var result []types.Address
+	for _, signer := range p.Signers {
+		if !signed[signer] {
+			result = append(result, signer)
+		}
+	}
+	return result
+}
+
+// Complete returns true if at least Threshold signers have signed
+func (p *Payload) Complete() bool {
+	return len(p.Signatures) >= p.Threshold
+}
+
+// ExecuteData returns the data of the send block to the multisig contract
+func (p *Payload) ExecuteData() ([]byte, error) {
+	if !p.Complete() {
+		return nil, fmt.Errorf("%d of %d signatures", len(p.Signatures), p.Threshold)
+	}
+	signatures := make([]byte, 0, len(p.Signatures)*(ed25519.PublicKeySize+ed25519.SignatureSize))
+	for _, sig := range p.Signatures {
+		signatures = append(signatures, sig.PublicKey...)
+		signatures = append(signatures, sig.Signature...)
+	}
+	return ABIMultisig.PackMethod("execute", p.Call.ToAddress, p.Call.TokenId, p.Call.Amount, p.Call.Data, p.Nonce, signatures)
+}
+
+// Encode serializes the payload into a string which can be exchanged offline
+func (p *Payload) Encode() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodePayload parses the string of Encode and verifies the signatures
+func DecodePayload(s string) (*Payload, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	decoded := &Payload{}
+	if err := json.Unmarshal(data, decoded); err != nil {
+		return nil, err
+	}
+	if decoded.Version != PayloadVersion {
+		return nil, fmt.Errorf("unsupported payload version %d", decoded.Version)
+	}
+	p, err := NewPayload(decoded.Contract, decoded.Nonce, decoded.Threshold, decoded.Signers, decoded.Call)
+	if err != nil {
+		return nil, err
+	}
+	for _, sig := range decoded.Signatures {
+		if err := p.addSignature(sig); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,90 @@
+package multisig
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+func TestPayload(t *testing.T) {
+	var accts []interfaces.Account
+	var signers []types.Address
+	for i := 0; i < 3; i++ {
+		acct, err := wallet.RandomAccount()
+		if err != nil {
+			t.Fatal(err)
+		}
+		accts = append(accts, acct)
+		signers = append(signers, acct.Address())
+	}
+	outsider, _ := wallet.RandomAccount()
+
+	if _, err := NewPayload(types.AddressGovernance, 1, 4, signers, Call{}); err != ErrInvalidThreshold {
+		t.Fatalf("expected invalid threshold, got %v", err)
+	}
+	p, err := NewPayload(types.AddressGovernance, 1, 2, signers, Call{
+		ToAddress: outsider.Address(),
+		Amount:    big.NewInt(100),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := p.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Sign(outsider); err != ErrNotSigner {
+		t.Fatalf("expected not signer, got %v", err)
+	}
+
+	// two signers sign the same unsigned payload offline
+	var signed []*Payload
+	for _, acct := range accts[:2] {
+		cp, err := DecodePayload(unsigned)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = cp.Sign(acct); err != nil {
+			t.Fatal(err)
+		}
+		s, err := cp.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cp, err = DecodePayload(s); err != nil {
+			t.Fatal(err)
+		}
+		signed = append(signed, cp)
+	}
+	if signed[0].Complete() {
+		t.Fatal("expected incomplete")
+	}
+	if _, err = signed[0].ExecuteData(); err == nil {
+		t.Fatal("expected not enough signatures")
+	}
+
+	if err = signed[0].Merge(signed[1]); err != nil {
+		t.Fatal(err)
+	}
+	if !signed[0].Complete() || len(signed[0].Missing()) != 1 || signed[0].Missing()[0] != signers[2] {
+		t.Fatalf("unexpected signed %v, missing %v", signed[0].Signed(), signed[0].Missing())
+	}
+	if _, err = signed[0].ExecuteData(); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewPayload(types.AddressGovernance, 2, 2, signers, Call{Amount: big.NewInt(100)})
+	if err = signed[0].Merge(other); err != ErrPayloadMismatch {
+		t.Fatalf("expected mismatch, got %v", err)
+	}
+
+	// a signature of another call is rejected
+	signed[1].Signatures[0].Signature[0] ^= 1
+	tampered, _ := signed[1].Encode()
+	if _, err = DecodePayload(tampered); err != ErrInvalidSignature {
+		t.Fatalf("expected invalid signature, got %v", err)
+	}
+}
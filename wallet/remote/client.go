@@ -0,0 +1,154 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// Client requests a signer daemon
+type Client struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewClient creates a client of the signer endpoint, e.g. unix:///var/run/vite-signer.sock or http://127.0.0.1:48140.
+// timeout should cover the manual approval, zero means no timeout.
+func NewClient(endpoint, token string, timeout time.Duration) (*Client, error) {
+	network, address, err := parseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client := newHTTPClient(network, address)
+	client.Timeout = timeout
+	return &Client{endpoint: endpoint, token: token, client: client}, nil
+}
+
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Accounts returns the addresses which the signer holds
+func (c *Client) Accounts() ([]types.Address, error) {
+	var resp AccountsResponse
+	if err := c.do(http.MethodGet, pathAccounts, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Accounts, nil
+}
+
+// Account returns the account of address, the signer must hold it
+func (c *Client) Account(address types.Address) (*Account, error) {
+	addrs, err := c.Accounts()
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if addr == address {
+			return &Account{client: c, address: address}, nil
+		}
+	}
+	return nil, ErrUnknownAccount
+}
+
+// Sign sends the request and verifies the signature of the response
+func (c *Client) Sign(req *SignRequest) ([]byte, ed25519.PublicKey, error) {
+	var resp SignResponse
+	if err := c.do(http.MethodPost, pathSign, req, &resp); err != nil {
+		return nil, nil, err
+	}
+	if len(resp.PublicKey) != ed25519.PublicKeySize || types.PubkeyToAddress(resp.PublicKey) != req.Address {
+		return nil, nil, errors.New("public key of signer does not match the address")
+	}
+	if err := ed25519.VerifySig(resp.PublicKey, req.Data, resp.Signature); err != nil {
+		return nil, nil, err
+	}
+	return resp.Signature, resp.PublicKey, nil
+}
+
+func (c *Client) do(method, path string, body interface{}, result interface{}) error {
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, err := http.NewRequest(method, "http://signer"+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&errResp)
+		if resp.StatusCode == http.StatusForbidden {
+			if errResp.Error != "" {
+				return fmt.Errorf("%w: %s", ErrRejected, errResp.Error)
+			}
+			return ErrRejected
+		}
+		return fmt.Errorf("signer returned %s: %s", resp.Status, errResp.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// Account is an address whose key is held by a signer daemon, it implements interfaces.Account and interfaces.BlockSigner
+type Account struct {
+	client  *Client
+	address types.Address
+}
+
+func (acct *Account) Address() types.Address {
+	return acct.address
+}
+
+func (acct *Account) Client() *Client {
+	return acct.client
+}
+
+// Sign signs a message without a block
+func (acct *Account) Sign(msg []byte) (signData []byte, pub ed25519.PublicKey, err error) {
+	return acct.client.Sign(&SignRequest{Kind: KindData, Address: acct.address, Data: msg})
+}
+
+func (acct *Account) SignAccountBlock(block *ledger.AccountBlock) (signData []byte, pub ed25519.PublicKey, err error) {
+	return acct.client.Sign(&SignRequest{
+		Kind:         KindAccountBlock,
+		Address:      acct.address,
+		AccountBlock: block,
+		Data:         block.Hash.Bytes(),
+	})
+}
+
+func (acct *Account) SignSnapshotBlock(block *ledger.SnapshotBlock) (signData []byte, pub ed25519.PublicKey, err error) {
+	return acct.client.Sign(&SignRequest{
+		Kind:          KindSnapshotBlock,
+		Address:       acct.address,
+		SnapshotBlock: block,
+		Data:          block.Hash.Bytes(),
+	})
+}
+
+func (acct *Account) Verify(pub ed25519.PublicKey, message, signdata []byte) error {
+	return ed25519.VerifySig(pub, message, signdata)
+}
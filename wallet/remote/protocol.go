@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/crypto/ed25519"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+)
+
+// The signer serves the api by http over a unix socket or a local tcp address,
+// every request must carry the token by the header "Authorization: Bearer <token>".
+//
+//	GET  /accounts  the addresses which the signer holds, AccountsResponse
+//	POST /sign      SignRequest, SignResponse, 403 if the request is rejected
+const (
+	pathAccounts = "/accounts"
+	pathSign     = "/sign"
+)
+
+// kinds of sign request
+const (
+	KindAccountBlock  = "accountBlock"
+	KindSnapshotBlock = "snapshotBlock"
+	KindData          = "data"
+)
+
+var (
+	ErrRejected        = errors.New("sign request is rejected by signer")
+	ErrUnknownAccount  = errors.New("signer does not hold the account")
+	ErrHashMismatch    = errors.New("hash does not match the block")
+	ErrDoubleSign      = errors.New("another snapshot block at the height or above has been signed")
+	ErrDataApproval    = errors.New("data requests must be approved by an approver")
+	ErrInvalidEndpoint = errors.New("signer endpoint must be unix://<path> or http://<host:port>")
+)
+
+// SignRequest is the payload of a sign request, it contains the full block for the approval
+type SignRequest struct {
+	Kind    string        `json:"kind"`
+	Address types.Address `json:"address"`
+
+	AccountBlock  *ledger.AccountBlock  `json:"accountBlock,omitempty"`
+	SnapshotBlock *ledger.SnapshotBlock `json:"snapshotBlock,omitempty"`
+
+	// Data is the message of KindData, or the hash of the block
+	Data []byte `json:"data"`
+}
+
+type SignResponse struct {
+	Signature []byte            `json:"signature"`
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+}
+
+type AccountsResponse struct {
+	Accounts []types.Address `json:"accounts"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// parseEndpoint returns the base url and the transport of the endpoint
+func parseEndpoint(endpoint string) (network, address string, err error) {
+	switch {
+	case strings.HasPrefix(endpoint, "unix://"):
+		return "unix", strings.TrimPrefix(endpoint, "unix://"), nil
+	case strings.HasPrefix(endpoint, "http://"):
+		return "tcp", strings.TrimPrefix(endpoint, "http://"), nil
+	}
+	return "", "", ErrInvalidEndpoint
+}
+
+func newHTTPClient(network, address string) *http.Client {
+	dialer := &net.Dialer{}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package remote_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/common/upgrade"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/wallet"
+	"github.com/vitelabs/go-vite/v2/wallet/remote"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := wallet.RandomAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reject := false
+	server, err := remote.NewServer("token", remote.ApproverFunc(func(req *remote.SignRequest) error {
+		if reject {
+			return errors.New("no")
+		}
+		return nil
+	}), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "unix://" + filepath.Join(dir, "signer.sock")
+	if err = server.Start(endpoint); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop()
+
+	bad, _ := remote.NewClient(endpoint, "wrong", time.Second)
+	if _, err = bad.Accounts(); err == nil {
+		t.Fatal("expected unauthorized")
+	}
+
+	client, err := remote.NewClient(endpoint, "token", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.Account(types.AddressGovernance); err != remote.ErrUnknownAccount {
+		t.Fatalf("expected unknown account, got %v", err)
+	}
+	acct, err := client.Account(key.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         1,
+		AccountAddress: key.Address(),
+		ToAddress:      types.AddressGovernance,
+		Amount:         big.NewInt(1),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            big.NewInt(0),
+	}
+	block.Hash = block.ComputeHash()
+	if err = interfaces.SignAccountBlock(acct, block); err != nil {
+		t.Fatal(err)
+	}
+	if !block.VerifySignature() {
+		t.Fatal("invalid signature")
+	}
+
+	// the hash must be the hash of the block
+	if _, _, err = client.Sign(&remote.SignRequest{
+		Kind:         remote.KindAccountBlock,
+		Address:      key.Address(),
+		AccountBlock: block,
+		Data:         types.DataHash([]byte("other")).Bytes(),
+	}); err == nil {
+		t.Fatal("expected hash mismatch")
+	}
+
+	reject = true
+	if _, _, err = acct.Sign([]byte("hello")); !errors.Is(err, remote.ErrRejected) {
+		t.Fatalf("expected rejected, got %v", err)
+	}
+}
+
+func TestRemoteSigner_DoubleSign(t *testing.T) {
+	upgrade.CleanupUpgradeBox(t)
+	upgrade.InitUpgradeBox(upgrade.NewLatestUpgradeBox())
+	defer upgrade.CleanupUpgradeBox(t)
+
+	dir, err := ioutil.TempDir("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "signed.json")
+
+	key, err := wallet.RandomAccount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := func(approver remote.Approver) (*remote.Server, *remote.Account) {
+		server, err := remote.NewServer("token", approver, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = server.SetStateFile(stateFile); err != nil {
+			t.Fatal(err)
+		}
+		endpoint := "unix://" + filepath.Join(dir, "signer.sock")
+		if err = server.Start(endpoint); err != nil {
+			t.Fatal(err)
+		}
+		client, err := remote.NewClient(endpoint, "token", time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		acct, err := client.Account(key.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return server, acct
+	}
+	newBlock := func(height uint64, ts int64) *ledger.SnapshotBlock {
+		timestamp := time.Unix(ts, 0)
+		block := &ledger.SnapshotBlock{Height: height, Timestamp: &timestamp}
+		block.Hash = block.ComputeHash()
+		return block
+	}
+
+	server, acct := start(nil)
+	// a data request without an approver
+	if _, _, err = acct.Sign([]byte("hello")); !errors.Is(err, remote.ErrRejected) {
+		t.Fatalf("expected rejected, got %v", err)
+	}
+
+	block := newBlock(10, 1600000000)
+	if err = interfaces.SignSnapshotBlock(acct, block); err != nil {
+		t.Fatal(err)
+	}
+	// the same block again
+	if err = interfaces.SignSnapshotBlock(acct, block); err != nil {
+		t.Fatal(err)
+	}
+	// another block at the height, or below
+	for _, other := range []*ledger.SnapshotBlock{newBlock(10, 1600000001), newBlock(9, 1600000002)} {
+		if err = interfaces.SignSnapshotBlock(acct, other); !errors.Is(err, remote.ErrRejected) {
+			t.Fatalf("expected double sign is rejected, got %v", err)
+		}
+	}
+	server.Stop()
+
+	// the signed blocks are kept across restarts
+	server, acct = start(nil)
+	defer server.Stop()
+	if err = interfaces.SignSnapshotBlock(acct, newBlock(10, 1600000003)); !errors.Is(err, remote.ErrRejected) {
+		t.Fatalf("expected double sign is rejected, got %v", err)
+	}
+	if err = interfaces.SignSnapshotBlock(acct, newBlock(11, 1600000004)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,306 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/interfaces"
+	ledger "github.com/vitelabs/go-vite/v2/interfaces/core"
+	"github.com/vitelabs/go-vite/v2/log15"
+)
+
+var ErrTokenRequired = errors.New("signer requires a token")
+
+// Approver decides whether a sign request is approved, a nil error approves it.
+// A data request may be the hash of any block, it must not be approved without a review.
+type Approver interface {
+	Approve(req *SignRequest) error
+}
+
+type ApproverFunc func(req *SignRequest) error
+
+func (fn ApproverFunc) Approve(req *SignRequest) error {
+	return fn(req)
+}
+
+// Server is the signer daemon, it holds the keys and signs the approved requests
+type Server struct {
+	token    string
+	approver Approver
+
+	accounts map[types.Address]interfaces.Account
+	// requests are approved one by one
+	mu sync.Mutex
+
+	// the latest snapshot block signed by each address, a snapshot block is signed only if it's above
+	signed    map[types.Address]*ledger.HashHeight
+	stateFile string
+
+	listener net.Listener
+	server   *http.Server
+	log      log15.Logger
+}
+
+// NewServer creates a signer of the accounts.
+// The hash of a block request is computed again from the block, so the upgrade box must be initialized for snapshot blocks.
+func NewServer(token string, approver Approver, accounts ...interfaces.Account) (*Server, error) {
+	if token == "" {
+		return nil, ErrTokenRequired
+	}
+	s := &Server{
+		token:    token,
+		approver: approver,
+		accounts: make(map[types.Address]interfaces.Account),
+		signed:   make(map[types.Address]*ledger.HashHeight),
+		log:      log15.New("module", "wallet/signer"),
+	}
+	for _, acct := range accounts {
+		s.accounts[acct.Address()] = acct
+	}
+	return s, nil
+}
+
+// SetStateFile loads the latest signed snapshot blocks from filename and saves them to it after every snapshot block
+// is signed, so a restarted signer doesn't sign a block conflicting with the ones before. A missing file is empty.
+func (s *Server) SetStateFile(filename string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	state := make(map[string]*ledger.HashHeight)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			return fmt.Errorf("invalid signer state file %s: %w", filename, err)
+		}
+	}
+	signed := make(map[types.Address]*ledger.HashHeight, len(state))
+	for str, hashHeight := range state {
+		addr, err := types.HexToAddress(str)
+		if err != nil {
+			return fmt.Errorf("invalid signer state file %s: %w", filename, err)
+		}
+		signed[addr] = hashHeight
+	}
+	s.signed = signed
+	s.stateFile = filename
+	return nil
+}
+
+func (s *Server) saveState() error {
+	if s.stateFile == "" {
+		return nil
+	}
+	state := make(map[string]*ledger.HashHeight, len(s.signed))
+	for addr, hashHeight := range s.signed {
+		state[addr.String()] = hashHeight
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := s.stateFile + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.stateFile)
+}
+
+// checkDoubleSign rejects a snapshot block at or below the latest signed one of the address, unless it's the same block.
+// After a rollback, the producer can't sign until the chain is above the latest signed block again.
+func (s *Server) checkDoubleSign(req *SignRequest) error {
+	if req.Kind != KindSnapshotBlock {
+		return nil
+	}
+	last := s.signed[req.Address]
+	if last == nil || req.SnapshotBlock.Height > last.Height {
+		return nil
+	}
+	if req.SnapshotBlock.Height == last.Height && req.SnapshotBlock.Hash == last.Hash {
+		return nil
+	}
+	return fmt.Errorf("%w: %s %d", ErrDoubleSign, last.Hash, last.Height)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathAccounts, s.serveAccounts)
+	mux.HandleFunc(pathSign, s.serveSign)
+	return s.authorize(mux)
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) serveAccounts(w http.ResponseWriter, r *http.Request) {
+	resp := &AccountsResponse{Accounts: make([]types.Address, 0, len(s.accounts))}
+	for addr := range s.accounts {
+		resp.Accounts = append(resp.Accounts, addr)
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) serveSign(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	req := &SignRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	resp, status, err := s.sign(req)
+	if err != nil {
+		s.log.Warn("sign request failed", "kind", req.Kind, "address", req.Address, "err", err)
+		writeError(w, status, err)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+func (s *Server) sign(req *SignRequest) (*SignResponse, int, error) {
+	acct, ok := s.accounts[req.Address]
+	if !ok {
+		return nil, http.StatusBadRequest, ErrUnknownAccount
+	}
+	if err := checkRequest(req); err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkDoubleSign(req); err != nil {
+		return nil, http.StatusForbidden, err
+	}
+	if s.approver != nil {
+		if err := s.approver.Approve(req); err != nil {
+			return nil, http.StatusForbidden, err
+		}
+	} else if req.Kind == KindData {
+		return nil, http.StatusForbidden, ErrDataApproval
+	}
+	if req.Kind == KindSnapshotBlock {
+		// recorded before signing, a signature lost by a failed save is never given out
+		prev := s.signed[req.Address]
+		s.signed[req.Address] = &ledger.HashHeight{Hash: req.SnapshotBlock.Hash, Height: req.SnapshotBlock.Height}
+		if err := s.saveState(); err != nil {
+			if prev != nil {
+				s.signed[req.Address] = prev
+			} else {
+				delete(s.signed, req.Address)
+			}
+			return nil, http.StatusInternalServerError, err
+		}
+	}
+	signature, pub, err := acct.Sign(req.Data)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	s.log.Info("sign request approved", "kind", req.Kind, "address", req.Address, "data", types.DataHash(req.Data))
+	return &SignResponse{Signature: signature, PublicKey: pub}, http.StatusOK, nil
+}
+
+// checkRequest makes sure the signed hash is the hash of the block which is approved
+func checkRequest(req *SignRequest) error {
+	switch req.Kind {
+	case KindAccountBlock:
+		if req.AccountBlock == nil {
+			return errors.New("account block is required")
+		}
+		hash := req.AccountBlock.ComputeHash()
+		if hash != req.AccountBlock.Hash || !bytes.Equal(hash.Bytes(), req.Data) {
+			return ErrHashMismatch
+		}
+	case KindSnapshotBlock:
+		if req.SnapshotBlock == nil || req.SnapshotBlock.Timestamp == nil {
+			return errors.New("snapshot block is required")
+		}
+		hash := req.SnapshotBlock.ComputeHash()
+		if hash != req.SnapshotBlock.Hash || !bytes.Equal(hash.Bytes(), req.Data) {
+			return ErrHashMismatch
+		}
+	case KindData:
+	default:
+		return errors.New("unknown kind of sign request")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(&errorResponse{Error: err.Error()})
+}
+
+// Start listens on the endpoint and serves in background, the unix socket is only accessible by the owner
+func (s *Server) Start(endpoint string) error {
+	network, address, err := parseEndpoint(endpoint)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		if err := os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	if network == "unix" {
+		if err := os.Chmod(address, 0600); err != nil {
+			listener.Close()
+			return err
+		}
+	}
+	s.listener = listener
+	s.server = &http.Server{Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go s.server.Serve(listener)
+	return nil
+}
+
+// Addr returns the listening address, nil if not started
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func (s *Server) Stop() {
+	if s.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = s.server.Shutdown(ctx)
+	s.server = nil
+}
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/vitelabs/go-vite/v2/common/types"
+)
+
+// watchOnlyFilename is the file of the watch-only addresses in the keystore dir,
+// it starts with "." so it is not taken as an entropy store file
+const watchOnlyFilename = ".watchonly.json"
+
+var ErrWatchOnlyNotFound = errors.New("watch-only address not found")
+
+// WatchOnlyAddress is an address without key, its balances, unreceived blocks and events are monitored by the wallet
+type WatchOnlyAddress struct {
+	Address   types.Address `json:"address"`
+	Label     string        `json:"label,omitempty"`
+	Timestamp int64         `json:"timestamp"`
+}
+
+func (m *Manager) watchOnlyFile() string {
+	return filepath.Join(m.config.DataDir, watchOnlyFilename)
+}
+
+// loadWatchOnly reads the watch-only addresses, a missing file means none
+func (m *Manager) loadWatchOnly() error {
+	m.watchOnlyMutex.Lock()
+	defer m.watchOnlyMutex.Unlock()
+
+	m.watchOnly = make(map[types.Address]*WatchOnlyAddress)
+	data, err := ioutil.ReadFile(m.watchOnlyFile())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var list []*WatchOnlyAddress
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.Wrap(err, "parse "+watchOnlyFilename)
+	}
+	for _, w := range list {
+		m.watchOnly[w.Address] = w
+	}
+	return nil
+}
+
+func (m *Manager) saveWatchOnly() error {
+	data, err := json.MarshalIndent(m.listWatchOnly(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.config.DataDir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(m.config.DataDir, watchOnlyFilename+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	f.Close()
+	return os.Rename(f.Name(), m.watchOnlyFile())
+}
+
+// AddWatchOnly adds or relabels a watch-only address, it is kept across restarts
+func (m *Manager) AddWatchOnly(addr types.Address, label string) error {
+	m.watchOnlyMutex.Lock()
+	defer m.watchOnlyMutex.Unlock()
+
+	if w, ok := m.watchOnly[addr]; ok {
+		w.Label = label
+	} else {
+		m.watchOnly[addr] = &WatchOnlyAddress{Address: addr, Label: label, Timestamp: time.Now().Unix()}
+	}
+	if err := m.saveWatchOnly(); err != nil {
+		return err
+	}
+	m.log.Info("add watch-only address", "address", addr, "label", label)
+	return nil
+}
+
+// RemoveWatchOnly stops watching addr
+func (m *Manager) RemoveWatchOnly(addr types.Address) error {
+	m.watchOnlyMutex.Lock()
+	defer m.watchOnlyMutex.Unlock()
+
+	if _, ok := m.watchOnly[addr]; !ok {
+		return ErrWatchOnlyNotFound
+	}
+	delete(m.watchOnly, addr)
+	if err := m.saveWatchOnly(); err != nil {
+		return err
+	}
+	m.log.Info("remove watch-only address", "address", addr)
+	return nil
+}
+
+// IsWatchOnly returns true if addr is a watch-only address
+func (m *Manager) IsWatchOnly(addr types.Address) bool {
+	m.watchOnlyMutex.RLock()
+	defer m.watchOnlyMutex.RUnlock()
+	_, ok := m.watchOnly[addr]
+	return ok
+}
+
+// ListWatchOnly returns the watch-only addresses in the order of adding
+func (m *Manager) ListWatchOnly() []*WatchOnlyAddress {
+	m.watchOnlyMutex.RLock()
+	defer m.watchOnlyMutex.RUnlock()
+	return m.listWatchOnly()
+}
+
+func (m *Manager) listWatchOnly() []*WatchOnlyAddress {
+	list := make([]*WatchOnlyAddress, 0, len(m.watchOnly))
+	for _, w := range m.watchOnly {
+		c := *w
+		list = append(list, &c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Timestamp != list[j].Timestamp {
+			return list[i].Timestamp < list[j].Timestamp
+		}
+		return list[i].Address.String() < list[j].Address.String()
+	})
+	return list
+}
+
+// WatchOnlyLabels returns the watch-only addresses and their labels
+func (m *Manager) WatchOnlyLabels() map[types.Address]string {
+	m.watchOnlyMutex.RLock()
+	defer m.watchOnlyMutex.RUnlock()
+	result := make(map[types.Address]string, len(m.watchOnly))
+	for addr, w := range m.watchOnly {
+		result[addr] = w.Label
+	}
+	return result
+}
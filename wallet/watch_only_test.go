@@ -0,0 +1,49 @@
+package wallet_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/vitelabs/go-vite/v2/common/config"
+	"github.com/vitelabs/go-vite/v2/common/types"
+	"github.com/vitelabs/go-vite/v2/wallet"
+)
+
+func TestManager_WatchOnly(t *testing.T) {
+	dir := tmpDir()
+	defer os.RemoveAll(dir)
+
+	manager := wallet.New(&config.Wallet{DataDir: dir})
+	if err := manager.Start(); err != nil {
+		t.Fatal(err)
+	}
+	addr1, _, _ := types.CreateAddress()
+	addr2, _, _ := types.CreateAddress()
+
+	assert.NoError(t, manager.AddWatchOnly(addr1, "cold"))
+	assert.NoError(t, manager.AddWatchOnly(addr2, ""))
+	assert.NoError(t, manager.AddWatchOnly(addr2, "vault"))
+	assert.True(t, manager.IsWatchOnly(addr1))
+	assert.Equal(t, map[types.Address]string{addr1: "cold", addr2: "vault"}, manager.WatchOnlyLabels())
+
+	// the watch-only file is not an entropy store
+	files, err := manager.ListEntropyFilesInStandardDir()
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	// the addresses are kept across restarts
+	restarted := wallet.New(&config.Wallet{DataDir: dir})
+	if err := restarted.Start(); err != nil {
+		t.Fatal(err)
+	}
+	list := restarted.ListWatchOnly()
+	assert.Equal(t, 2, len(list))
+	assert.Equal(t, manager.WatchOnlyLabels(), restarted.WatchOnlyLabels())
+
+	assert.NoError(t, restarted.RemoveWatchOnly(addr1))
+	assert.Equal(t, wallet.ErrWatchOnlyNotFound, restarted.RemoveWatchOnly(addr1))
+	assert.False(t, restarted.IsWatchOnly(addr1))
+	assert.True(t, restarted.IsWatchOnly(addr2))
+}